@@ -27,6 +27,7 @@ type InstrumentedClient struct {
 	Client         *http.Client
 	Retry          *RetryConfig
 	Underlying     *http.Transport // added
+	metrics        *clientMetrics  // nil when the prometheus component is not running
 }
 
 func (ic *InstrumentedClient) buildURL(path string, q map[string]string) (string, error) {
@@ -117,8 +118,14 @@ func (ic *InstrumentedClient) Do(ctx context.Context, method, path string, query
 	}
 
 	start := time.Now()
+	done := ic.metrics.begin(ctx, ic.Name, req.URL.Host, method)
 	resp, err := ic.doWithRetry(ctx, req)
 	latency := time.Since(start)
+	if resp != nil {
+		done(resp.StatusCode)
+	} else {
+		done(0)
+	}
 
 	// Prefer span from response request context (child span created by otelhttp transport)
 	spanCtx := ctx
@@ -213,6 +220,9 @@ func (ic *InstrumentedClient) doWithRetry(ctx context.Context, req *http.Request
 	backoff := ic.Retry.InitialBackoff
 	var lastErr error
 	for attempt := 1; attempt <= ic.Retry.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			ic.metrics.retry(ic.Name, req.URL.Host, req.Method)
+		}
 		// Reset body for each attempt
 		if bodyBytes != nil {
 			req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
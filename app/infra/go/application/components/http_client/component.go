@@ -11,6 +11,7 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/prometheus"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 )
@@ -45,6 +46,11 @@ func (hc *HTTPClientsComponent) Start(ctx context.Context) error {
 	hc.cfg.applyDefaults()
 	hc.defName = hc.cfg.Default
 
+	var metrics *clientMetrics
+	if pc := prometheus.C(); pc != nil {
+		metrics = newClientMetrics(pc)
+	}
+
 	for name, cCfg := range hc.cfg.Clients {
 		underlying := &http.Transport{
 			Proxy: http.ProxyFromEnvironment,
@@ -72,6 +78,7 @@ func (hc *HTTPClientsComponent) Start(ctx context.Context) error {
 			Client:         httpClient,
 			Retry:          cCfg.Retry,
 			Underlying:     underlying,
+			metrics:        metrics,
 		}
 		hc.clients[name] = ic
 	}
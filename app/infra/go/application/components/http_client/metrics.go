@@ -0,0 +1,73 @@
+package http_client
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/prometheus"
+)
+
+// statusTransportError labels requests that never produced an HTTP response.
+const statusTransportError = "error"
+
+// clientMetrics holds RED metrics shared by all InstrumentedClients, labelled by client name and target host.
+type clientMetrics struct {
+	requests *prom.CounterVec
+	errors   *prom.CounterVec
+	retries  *prom.CounterVec
+	duration *prom.HistogramVec
+	inFlight *prom.GaugeVec
+}
+
+func newClientMetrics(pc *prometheus.Component) *clientMetrics {
+	return &clientMetrics{
+		requests: pc.NewCounter("http_client_requests_total",
+			"Total outbound HTTP requests, by client, host, method and status code.",
+			[]string{"client", "host", "method", "status"}),
+		errors: pc.NewCounter("http_client_errors_total",
+			"Total outbound HTTP requests that failed with a transport error or a 5xx status.",
+			[]string{"client", "host", "method"}),
+		retries: pc.NewCounter("http_client_retries_total",
+			"Total retry attempts issued after a failed outbound HTTP request.",
+			[]string{"client", "host", "method"}),
+		duration: pc.NewHistogram("http_client_request_duration_seconds",
+			"Outbound HTTP request latency in seconds including retries, by client, host and method.",
+			[]string{"client", "host", "method"}, prom.DefBuckets),
+		inFlight: pc.NewGauge("http_client_in_flight_requests",
+			"Outbound HTTP requests currently in flight, by client and host.",
+			[]string{"client", "host"}),
+	}
+}
+
+// begin marks a request in flight and returns a func recording its outcome.
+// status is 0 when the request failed before a response was received.
+func (m *clientMetrics) begin(ctx context.Context, client, host, method string) func(status int) {
+	if m == nil {
+		return func(int) {}
+	}
+	gauge := m.inFlight.WithLabelValues(client, host)
+	gauge.Inc()
+	start := time.Now()
+	return func(status int) {
+		gauge.Dec()
+		label := statusTransportError
+		if status > 0 {
+			label = strconv.Itoa(status)
+		}
+		prometheus.Inc(ctx, m.requests.WithLabelValues(client, host, method, label))
+		if status == 0 || status >= 500 {
+			prometheus.Inc(ctx, m.errors.WithLabelValues(client, host, method))
+		}
+		prometheus.Observe(ctx, m.duration.WithLabelValues(client, host, method), time.Since(start).Seconds())
+	}
+}
+
+func (m *clientMetrics) retry(client, host, method string) {
+	if m == nil {
+		return
+	}
+	m.retries.WithLabelValues(client, host, method).Inc()
+}
@@ -0,0 +1,80 @@
+package http_client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/prometheus"
+)
+
+func TestClientMetrics_RequestLabels(t *testing.T) {
+	pc := prometheus.NewComponent(&prometheus.Config{Enabled: true, Path: "/metrics", ServeOnHTTPServer: true})
+	if err := pc.Start(context.Background()); err != nil {
+		t.Fatalf("start prometheus: %v", err)
+	}
+	defer pc.Stop(context.Background())
+
+	var flaky atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/boom":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/flaky":
+			if flaky.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	host := u.Host
+
+	m := newClientMetrics(pc)
+	plain := &InstrumentedClient{Name: "plain", BaseURL: srv.URL, Client: srv.Client(), metrics: m}
+	retrying := &InstrumentedClient{Name: "retrying", BaseURL: srv.URL, Client: srv.Client(), metrics: m,
+		Retry: &RetryConfig{Enabled: true, MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, BackoffMultiplier: 1}}
+
+	ctx := context.Background()
+	for _, p := range []string{"/ok", "/ok", "/boom", "/missing"} {
+		_, _ = plain.Get(ctx, p, nil, nil, nil)
+	}
+	if _, err := retrying.Post(ctx, "/flaky", map[string]string{"k": "v"}, nil, nil); err != nil {
+		t.Fatalf("retrying post: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	pc.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		fmt.Sprintf(`http_client_requests_total{client="plain",host=%q,method="GET",status="200"} 2`, host),
+		fmt.Sprintf(`http_client_requests_total{client="plain",host=%q,method="GET",status="503"} 1`, host),
+		fmt.Sprintf(`http_client_requests_total{client="plain",host=%q,method="GET",status="404"} 1`, host),
+		fmt.Sprintf(`http_client_errors_total{client="plain",host=%q,method="GET"} 1`, host),
+		fmt.Sprintf(`http_client_request_duration_seconds_count{client="plain",host=%q,method="GET"} 4`, host),
+		fmt.Sprintf(`http_client_requests_total{client="retrying",host=%q,method="POST",status="200"} 1`, host),
+		fmt.Sprintf(`http_client_retries_total{client="retrying",host=%q,method="POST"} 1`, host),
+		fmt.Sprintf(`http_client_request_duration_seconds_count{client="retrying",host=%q,method="POST"} 1`, host),
+		fmt.Sprintf(`http_client_in_flight_requests{client="plain",host=%q} 0`, host),
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+	if strings.Contains(body, `/ok`) {
+		t.Errorf("request path leaked into labels")
+	}
+}
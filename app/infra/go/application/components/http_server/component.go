@@ -16,6 +16,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/prometheus"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 )
@@ -71,6 +72,9 @@ func (hc *HTTPServerComponent) Start(ctx context.Context) error {
 	if hc.cfg.EnableHealth {
 		hc.router.Get("/healthz", hc.healthHandler)
 	}
+	if pc := prometheus.C(); pc != nil && pc.ServeOnHTTPServer() {
+		hc.router.Method(http.MethodGet, pc.Path(), pc.Handler())
+	}
 
 	if err := hc.registerAllRoutes(); err != nil {
 		return err
@@ -136,7 +140,7 @@ func (hc *HTTPServerComponent) setupMiddlewares() {
 	hc.router.Use(middleware.RedirectSlashes)
 	hc.router.Use(middleware.RealIP)
 	hc.router.Use(middleware.Recoverer)
-	hc.router.Use(middleware.Timeout(60 * time.Second))
+	hc.router.Use(Timeout(hc.cfg.RequestTimeout)) // streaming routes opt out with NoTimeout
 
 	// OTel middleware: extracts W3C traceparent / tracestate and starts a server span.
 	serviceName := hc.cfg.ServiceName
@@ -148,6 +152,11 @@ func (hc *HTTPServerComponent) setupMiddlewares() {
 	}
 	hc.router.Use(otelchi.Middleware(serviceName))
 
+	// RED metrics (only when the prometheus component is running); placed after otelchi so exemplars see the span.
+	if pc := prometheus.C(); pc != nil {
+		hc.router.Use(newServerMetrics(pc).middleware)
+	}
+
 	// Access log with status + trace metadata; always return standard traceparent header (W3C) when span present.
 	hc.router.Use(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -201,6 +210,16 @@ func (w *statusWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
+// Flush lets streaming handlers (SSE) flush through the access-log wrapper.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController (deadlines, hijack).
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
 func (hc *HTTPServerComponent) registerAllRoutes() error {
 	registrars := append(snapshot(), hc.extras...)
 	for _, fn := range registrars {
@@ -238,4 +257,7 @@ func (hc *HTTPServerComponent) applyDefaults() {
 	if hc.cfg.GracefulTimeout == 0 {
 		hc.cfg.GracefulTimeout = 10 * time.Second
 	}
+	if hc.cfg.RequestTimeout == 0 {
+		hc.cfg.RequestTimeout = 60 * time.Second
+	}
 }
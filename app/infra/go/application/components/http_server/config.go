@@ -10,6 +10,7 @@ type HTTPServerConfig struct {
 	WriteTimeout    time.Duration `yaml:"write_timeout" json:"write_timeout"`       //Max time to finish writing the response. Prevents handlers from hanging while client reads slowly.
 	IdleTimeout     time.Duration `yaml:"idle_timeout" json:"idle_timeout"`         // How long to keep idle keep-alive connections open (HTTP/1.1). Frees resources when clients go quiet.
 	GracefulTimeout time.Duration `yaml:"graceful_timeout" json:"graceful_timeout"` // Upper bound during shutdown for in-flight requests to finish before forcing close.
+	RequestTimeout  time.Duration `yaml:"request_timeout" json:"request_timeout"`   // Cancels the request context (504 if nothing written); routes wrapped with NoTimeout are exempt. Default 60s.
 	// Built-in endpoints
 	EnableHealth bool `yaml:"enable_health" json:"enable_health"`
 	EnablePprof  bool `yaml:"enable_pprof" json:"enable_pprof"`
@@ -0,0 +1,70 @@
+package http_server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/prometheus"
+)
+
+// unmatchedRoute labels requests that did not match any registered pattern (404/405),
+// so arbitrary client paths never become label values.
+const unmatchedRoute = "unmatched"
+
+// serverMetrics holds RED metrics for the HTTP server, labelled by chi route pattern.
+type serverMetrics struct {
+	requests *prom.CounterVec
+	errors   *prom.CounterVec
+	duration *prom.HistogramVec
+	inFlight *prom.GaugeVec
+}
+
+func newServerMetrics(pc *prometheus.Component) *serverMetrics {
+	return &serverMetrics{
+		requests: pc.NewCounter("http_server_requests_total",
+			"Total HTTP requests handled, by method, route pattern and status code.",
+			[]string{"method", "route", "status"}),
+		errors: pc.NewCounter("http_server_errors_total",
+			"Total HTTP requests answered with a 5xx status, by method and route pattern.",
+			[]string{"method", "route"}),
+		duration: pc.NewHistogram("http_server_request_duration_seconds",
+			"HTTP request latency in seconds, by method and route pattern.",
+			[]string{"method", "route"}, prom.DefBuckets),
+		inFlight: pc.NewGauge("http_server_in_flight_requests",
+			"HTTP requests currently being served, by method.",
+			[]string{"method"}),
+	}
+}
+
+// middleware records metrics after routing so the matched route pattern is known.
+func (m *serverMetrics) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gauge := m.inFlight.WithLabelValues(r.Method)
+		gauge.Inc()
+		defer gauge.Dec()
+
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		route := routePattern(r)
+		prometheus.Inc(r.Context(), m.requests.WithLabelValues(r.Method, route, strconv.Itoa(sw.status)))
+		if sw.status >= http.StatusInternalServerError {
+			prometheus.Inc(r.Context(), m.errors.WithLabelValues(r.Method, route))
+		}
+		prometheus.Observe(r.Context(), m.duration.WithLabelValues(r.Method, route), time.Since(start).Seconds())
+	})
+}
+
+func routePattern(r *http.Request) string {
+	if rc := chi.RouteContext(r.Context()); rc != nil {
+		if p := rc.RoutePattern(); p != "" {
+			return p
+		}
+	}
+	return unmatchedRoute
+}
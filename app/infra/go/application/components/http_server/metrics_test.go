@@ -0,0 +1,48 @@
+package http_server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/prometheus"
+)
+
+func TestServerMetrics_RoutePatternLabels(t *testing.T) {
+	pc := prometheus.NewComponent(&prometheus.Config{Enabled: true, Path: "/metrics", ServeOnHTTPServer: true})
+	if err := pc.Start(context.Background()); err != nil {
+		t.Fatalf("start prometheus: %v", err)
+	}
+	defer pc.Stop(context.Background())
+
+	r := chi.NewRouter()
+	r.Use(newServerMetrics(pc).middleware)
+	r.Get("/tasks/{id}", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	r.Get("/boom", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusBadGateway) })
+	r.Method(http.MethodGet, pc.Path(), pc.Handler())
+
+	for _, p := range []string{"/tasks/1", "/tasks/2", "/boom", "/no/such/path"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, p, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`http_server_requests_total{method="GET",route="/tasks/{id}",status="200"} 2`,
+		`http_server_errors_total{method="GET",route="/boom"} 1`,
+		`http_server_requests_total{method="GET",route="unmatched",status="404"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+	if strings.Contains(body, `route="/tasks/1"`) {
+		t.Errorf("raw path leaked into route label")
+	}
+}
@@ -0,0 +1,46 @@
+package http_server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+type timeoutTimerKey struct{}
+
+// Timeout cancels the request context after d and answers 504 if the handler has not
+// written a response, like chi's middleware.Timeout. Unlike a context deadline, the timer
+// can be stopped by NoTimeout on routes that stream for longer than d.
+//
+// On timeout ctx.Err() is context.Canceled; context.Cause(ctx) is context.DeadlineExceeded.
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithCancelCause(r.Context())
+			timer := time.AfterFunc(d, func() { cancel(context.DeadlineExceeded) })
+			defer func() {
+				timer.Stop()
+				if errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+					w.WriteHeader(http.StatusGatewayTimeout)
+				}
+				cancel(nil)
+			}()
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, timeoutTimerKey{}, timer)))
+		})
+	}
+}
+
+// NoTimeout exempts a route from the server-wide Timeout, e.g. Server-Sent Events:
+//
+//	r.With(http_server.NoTimeout).Get("/{id}/events", h)
+//
+// The request context is still cancelled when the client disconnects or the server shuts down.
+func NoTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if timer, ok := r.Context().Value(timeoutTimerKey{}).(*time.Timer); ok {
+			timer.Stop()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,36 @@
+package http_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestTimeout_NoTimeoutRouteOutlivesDeadline(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Timeout(50 * time.Millisecond))
+	slow := func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-time.After(200 * time.Millisecond):
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	r.Get("/slow", slow)
+	r.With(NoTimeout).Get("/stream", slow)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("slow route: expected 504, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("NoTimeout route: expected 200, got %d", rec.Code)
+	}
+}
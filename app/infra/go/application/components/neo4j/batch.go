@@ -0,0 +1,77 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	n4j "github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// BatchOptions tunes WriteBatched.
+type BatchOptions struct {
+	// ChunkSize is the number of rows per transaction (0 = config batch_size).
+	ChunkSize int
+	// Params are passed to every chunk alongside $rows.
+	Params map[string]any
+}
+
+// BatchResult summarises a WriteBatched call.
+type BatchResult struct {
+	Rows     int   `json:"rows"`
+	Batches  int   `json:"batches"`
+	Affected int64 `json:"affected"`
+}
+
+// WriteBatched writes rows in chunks with one managed write transaction per chunk.
+// cypher must consume the chunk through `UNWIND $rows AS row`, for example:
+//
+//	UNWIND $rows AS row MERGE (c:Company {normalized_name: row.key}) SET c += row.props
+//
+// Chunks are committed independently: on error, the returned result covers the
+// chunks committed so far and the remaining rows are not written.
+func (c *Neo4jComponent) WriteBatched(ctx context.Context, cypher string, rows []map[string]any, opts BatchOptions) (BatchResult, error) {
+	var res BatchResult
+	if !strings.Contains(cypher, "$rows") {
+		return res, fmt.Errorf("neo4j batched write requires a $rows parameter")
+	}
+	size := opts.ChunkSize
+	if size <= 0 {
+		size = c.cfg.BatchSize
+	}
+	for _, chunk := range chunkRows(rows, size) {
+		params := make(map[string]any, len(opts.Params)+1)
+		for k, v := range opts.Params {
+			params[k] = v
+		}
+		params["rows"] = chunk
+
+		var affected int64
+		err := c.execute(ctx, n4j.AccessModeWrite, opBatch, func(ctx context.Context, tx *Tx) error {
+			n, err := tx.Exec(ctx, cypher, params)
+			affected = n
+			return err
+		})
+		if err != nil {
+			return res, fmt.Errorf("neo4j batch %d (rows %d-%d): %w", res.Batches+1, res.Rows, res.Rows+len(chunk), err)
+		}
+		res.Rows += len(chunk)
+		res.Batches++
+		res.Affected += affected
+		c.metrics.addRows(c.cfg.Database, len(chunk))
+	}
+	return res, nil
+}
+
+// chunkRows splits rows into consecutive slices of at most size elements.
+func chunkRows(rows []map[string]any, size int) [][]map[string]any {
+	if size <= 0 || size > len(rows) {
+		size = max(len(rows), 1)
+	}
+	chunks := make([][]map[string]any, 0, (len(rows)+size-1)/size)
+	for start := 0; start < len(rows); start += size {
+		end := min(start+size, len(rows))
+		chunks = append(chunks, rows[start:end])
+	}
+	return chunks
+}
@@ -7,6 +7,7 @@ import (
 	n4j "github.com/neo4j/neo4j-go-driver/v5/neo4j"
 
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/prometheus"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 )
@@ -14,8 +15,9 @@ import (
 // Neo4jComponent manages a Neo4j driver and provides Cypher execution helpers.
 type Neo4jComponent struct {
 	*core.BaseComponent
-	cfg    *Config
-	driver n4j.DriverWithContext
+	cfg     *Config
+	driver  n4j.DriverWithContext
+	metrics *queryMetrics
 }
 
 func NewNeo4jComponent(cfg *Config) *Neo4jComponent {
@@ -38,6 +40,7 @@ func (c *Neo4jComponent) Start(ctx context.Context) error {
 	auth := n4j.BasicAuth(c.cfg.Username, c.cfg.Password, "")
 	driver, err := n4j.NewDriverWithContext(c.cfg.URI, auth, func(conf *n4j.Config) {
 		conf.MaxConnectionPoolSize = c.cfg.MaxConnectionPoolSize
+		conf.MaxTransactionRetryTime = c.cfg.MaxTransactionRetryTime
 		if c.cfg.Encrypted {
 			// Enable TLS without verification for simplicity; production should use custom TLS config.
 		}
@@ -52,7 +55,18 @@ func (c *Neo4jComponent) Start(ctx context.Context) error {
 	}
 
 	c.driver = driver
+	if pc := prometheus.C(); pc != nil {
+		c.metrics = newQueryMetrics(pc)
+	}
 	logging.Infof(ctx, "[neo4j] connected to %s database=%s pool=%d", c.cfg.URI, c.cfg.Database, c.cfg.MaxConnectionPoolSize)
+
+	if c.cfg.MigrateEnabled {
+		res, err := c.Migrate(ctx, c.cfg.MigrateDir)
+		if err != nil {
+			return fmt.Errorf("neo4j migration failed: %w", err)
+		}
+		logging.Infof(ctx, "[neo4j] migrations applied=%d skipped=%d elapsed=%s", len(res.Applied), len(res.Skipped), res.Elapsed)
+	}
 	return nil
 }
 
@@ -95,7 +109,10 @@ func (c *Neo4jComponent) Database() string {
 }
 
 // RunCypher executes a read-only Cypher query and returns results as []map[string]any.
-func (c *Neo4jComponent) RunCypher(ctx context.Context, cypher string, params map[string]any) ([]map[string]any, error) {
+func (c *Neo4jComponent) RunCypher(ctx context.Context, cypher string, params map[string]any) (rows []map[string]any, err error) {
+	done := c.metrics.begin(ctx, c.cfg.Database, opRead)
+	defer func() { done(err) }()
+
 	session := c.driver.NewSession(ctx, n4j.SessionConfig{
 		DatabaseName: c.cfg.Database,
 		AccessMode:   n4j.AccessModeRead,
@@ -106,7 +123,6 @@ func (c *Neo4jComponent) RunCypher(ctx context.Context, cypher string, params ma
 	if err != nil {
 		return nil, fmt.Errorf("neo4j read query failed: %w", err)
 	}
-	var rows []map[string]any
 	for result.Next(ctx) {
 		record := result.Record()
 		row := make(map[string]any, len(record.Keys))
@@ -122,7 +138,10 @@ func (c *Neo4jComponent) RunCypher(ctx context.Context, cypher string, params ma
 }
 
 // RunCypherWrite executes a write Cypher query inside an explicit write transaction.
-func (c *Neo4jComponent) RunCypherWrite(ctx context.Context, cypher string, params map[string]any) (int64, error) {
+func (c *Neo4jComponent) RunCypherWrite(ctx context.Context, cypher string, params map[string]any) (affected int64, err error) {
+	done := c.metrics.begin(ctx, c.cfg.Database, opWrite)
+	defer func() { done(err) }()
+
 	session := c.driver.NewSession(ctx, n4j.SessionConfig{
 		DatabaseName: c.cfg.Database,
 		AccessMode:   n4j.AccessModeWrite,
@@ -136,10 +155,7 @@ func (c *Neo4jComponent) RunCypherWrite(ctx context.Context, cypher string, para
 	if err != nil {
 		return 0, fmt.Errorf("neo4j write query failed: %w", err)
 	}
-	counters := summary.Summary.Counters()
-	affected := int64(counters.NodesCreated()) + int64(counters.RelationshipsCreated()) +
-		int64(counters.PropertiesSet()) + int64(counters.NodesDeleted()) + int64(counters.RelationshipsDeleted())
-	return affected, nil
+	return affectedCount(summary.Summary.Counters()), nil
 }
 
 // toSerializable recursively converts Neo4j-specific types (Node, Relationship, Path)
@@ -1,5 +1,7 @@
 package neo4j
 
+import "time"
+
 // Config holds the configuration for Neo4j driver.
 type Config struct {
 	Enabled               bool   `yaml:"enabled" json:"enabled"`
@@ -9,6 +11,11 @@ type Config struct {
 	Database              string `yaml:"database" json:"database"`                                 // target database (default "neo4j")
 	MaxConnectionPoolSize int    `yaml:"max_connection_pool_size" json:"max_connection_pool_size"` // connection pool cap
 	Encrypted             bool   `yaml:"encrypted" json:"encrypted"`                               // TLS on/off
+
+	MaxTransactionRetryTime time.Duration `yaml:"max_transaction_retry_time" json:"max_transaction_retry_time"` // managed tx retry budget for transient errors (default 30s)
+	BatchSize               int           `yaml:"batch_size" json:"batch_size"`                                 // default UNWIND chunk size for WriteBatched (default 1000)
+	MigrateEnabled          bool          `yaml:"migrate_enabled" json:"migrate_enabled"`                       // apply .cypher migrations on Start
+	MigrateDir              string        `yaml:"migrate_dir" json:"migrate_dir"`                               // directory of NNNN_description.cypher files
 }
 
 func setDefaults(c *Config) {
@@ -21,5 +28,11 @@ func setDefaults(c *Config) {
 	if c.MaxConnectionPoolSize <= 0 {
 		c.MaxConnectionPoolSize = 50
 	}
+	if c.MaxTransactionRetryTime <= 0 {
+		c.MaxTransactionRetryTime = 30 * time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 1000
+	}
 }
 
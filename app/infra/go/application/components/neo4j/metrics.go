@@ -0,0 +1,75 @@
+package neo4j
+
+import (
+	"context"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/prometheus"
+)
+
+// Operation labels for query metrics. Cypher text is never used as a label.
+const (
+	opRead      = "read"
+	opWrite     = "write"
+	opTxRead    = "tx_read"
+	opTxWrite   = "tx_write"
+	opBatch     = "batch"
+	opMigration = "migration"
+)
+
+// queryMetrics holds timing metrics for all statements issued through the component.
+type queryMetrics struct {
+	queries  *prom.CounterVec
+	duration *prom.HistogramVec
+	retries  *prom.CounterVec
+	rows     *prom.CounterVec
+}
+
+func newQueryMetrics(pc *prometheus.Component) *queryMetrics {
+	return &queryMetrics{
+		queries: pc.NewCounter("neo4j_queries_total",
+			"Total Neo4j queries and transactions, by database, operation and status.",
+			[]string{"database", "op", "status"}),
+		duration: pc.NewHistogram("neo4j_query_duration_seconds",
+			"Neo4j query and transaction latency in seconds including retries, by database and operation.",
+			[]string{"database", "op"}, prom.DefBuckets),
+		retries: pc.NewCounter("neo4j_transaction_retries_total",
+			"Total managed transaction retries after transient errors, by database and operation.",
+			[]string{"database", "op"}),
+		rows: pc.NewCounter("neo4j_batch_rows_total",
+			"Total rows written through batched UNWIND writes, by database.",
+			[]string{"database"}),
+	}
+}
+
+// begin starts timing an operation and returns a func recording its outcome.
+func (m *queryMetrics) begin(ctx context.Context, db, op string) func(err error) {
+	if m == nil {
+		return func(error) {}
+	}
+	start := time.Now()
+	return func(err error) {
+		status := "ok"
+		if err != nil {
+			status = "error"
+		}
+		prometheus.Inc(ctx, m.queries.WithLabelValues(db, op, status))
+		prometheus.Observe(ctx, m.duration.WithLabelValues(db, op), time.Since(start).Seconds())
+	}
+}
+
+func (m *queryMetrics) retry(db, op string) {
+	if m == nil {
+		return
+	}
+	m.retries.WithLabelValues(db, op).Inc()
+}
+
+func (m *queryMetrics) addRows(db string, n int) {
+	if m == nil {
+		return
+	}
+	m.rows.WithLabelValues(db).Add(float64(n))
+}
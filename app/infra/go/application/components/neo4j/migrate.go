@@ -0,0 +1,210 @@
+package neo4j
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	n4j "github.com/neo4j/neo4j-go-driver/v5/neo4j"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/migration"
+)
+
+// Cypher migrations mirror the SQL runner in components/migration:
+//   - files are named `NNNN_description.cypher` and applied in lexical order;
+//   - each applied file is recorded as (:_Migration {version, name, checksum, applied_at});
+//   - statements are separated by `;` and each runs in its own write transaction,
+//     because Neo4j does not allow schema and data changes in one transaction.
+//
+// Statements should be idempotent (CREATE CONSTRAINT ... IF NOT EXISTS) since a file
+// that fails halfway is retried from the top on the next run.
+
+const migrationConstraint = "CREATE CONSTRAINT _migration_version IF NOT EXISTS FOR (m:_Migration) REQUIRE m.version IS UNIQUE"
+
+// Migrate applies pending .cypher migrations from dir (default: config migrate_dir).
+func (c *Neo4jComponent) Migrate(ctx context.Context, dir string) (*migration.Result, error) {
+	start := time.Now()
+	res := &migration.Result{}
+	if dir == "" {
+		dir = c.cfg.MigrateDir
+	}
+	if strings.TrimSpace(dir) == "" {
+		return res, fmt.Errorf("neo4j migration dir is empty")
+	}
+	files, err := listCypherFiles(dir)
+	if err != nil {
+		return res, err
+	}
+
+	if err := c.runMigrationStatement(ctx, migrationConstraint, nil); err != nil {
+		return res, fmt.Errorf("create _Migration constraint: %w", err)
+	}
+	applied, err := c.appliedMigrations(ctx)
+	if err != nil {
+		return res, fmt.Errorf("list applied migrations: %w", err)
+	}
+
+	for _, f := range files {
+		name := filepath.Base(f)
+		version := migrationVersion(name)
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return res, fmt.Errorf("read %s: %w", f, err)
+		}
+		sum := sha256.Sum256(b)
+		checksum := hex.EncodeToString(sum[:])
+
+		if prev, ok := applied[version]; ok {
+			if prev != checksum {
+				logging.Warnf(ctx, "[neo4j] migration %s changed after it was applied (checksum mismatch); not re-applied", name)
+			}
+			res.Skipped = append(res.Skipped, name)
+			continue
+		}
+		for i, stmt := range SplitCypherStatements(string(b)) {
+			if err := c.runMigrationStatement(ctx, stmt, nil); err != nil {
+				return res, fmt.Errorf("migration %s statement %d failed: %w", name, i+1, err)
+			}
+		}
+		err = c.runMigrationStatement(ctx,
+			"MERGE (m:_Migration {version: $version}) SET m.name = $name, m.checksum = $checksum, m.applied_at = datetime()",
+			map[string]any{"version": version, "name": name, "checksum": checksum})
+		if err != nil {
+			return res, fmt.Errorf("record migration %s: %w", name, err)
+		}
+		res.Applied = append(res.Applied, name)
+	}
+	res.Elapsed = time.Since(start)
+	return res, nil
+}
+
+func (c *Neo4jComponent) runMigrationStatement(ctx context.Context, cypher string, params map[string]any) error {
+	return c.execute(ctx, n4j.AccessModeWrite, opMigration, func(ctx context.Context, tx *Tx) error {
+		_, err := tx.Exec(ctx, cypher, params)
+		return err
+	})
+}
+
+// appliedMigrations returns version -> checksum for recorded migrations.
+func (c *Neo4jComponent) appliedMigrations(ctx context.Context) (map[string]string, error) {
+	applied := make(map[string]string)
+	err := c.execute(ctx, n4j.AccessModeRead, opMigration, func(ctx context.Context, tx *Tx) error {
+		rows, err := tx.Query(ctx, "MATCH (m:_Migration) RETURN m.version AS version, m.checksum AS checksum", nil)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			version, _ := row["version"].(string)
+			checksum, _ := row["checksum"].(string)
+			applied[version] = checksum
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// listCypherFiles returns sorted NNNN_*.cypher file paths from dir. Two files sharing a
+// version are rejected: migrations are recorded by version, so the second would be skipped.
+func listCypherFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read dir %s: %w", dir, err)
+	}
+	var files []string
+	seen := make(map[string]string)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(strings.ToLower(e.Name()), ".cypher") {
+			continue
+		}
+		version := migrationVersion(e.Name())
+		if version == "" {
+			return nil, fmt.Errorf("migration file %s does not match NNNN_description.cypher", e.Name())
+		}
+		if prev, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migration files %s and %s share version %s", prev, e.Name(), version)
+		}
+		seen[version] = e.Name()
+		files = append(files, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// migrationVersion returns the numeric prefix of a migration filename ("0002_x.cypher" -> "0002").
+func migrationVersion(name string) string {
+	prefix, _, ok := strings.Cut(name, "_")
+	if !ok || prefix == "" {
+		return ""
+	}
+	for _, r := range prefix {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return prefix
+}
+
+// SplitCypherStatements splits a Cypher script on `;`, ignoring separators inside
+// string literals, backtick-quoted identifiers and `//` or `/* */` comments.
+// Comments are stripped and empty statements dropped.
+func SplitCypherStatements(script string) []string {
+	var (
+		stmts []string
+		cur   strings.Builder
+		quote rune // ', " or ` while inside a literal
+	)
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			stmts = append(stmts, s)
+		}
+		cur.Reset()
+	}
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		if quote != 0 {
+			cur.WriteRune(r)
+			if r == '\\' && quote != '`' && next != 0 {
+				cur.WriteRune(next)
+				i++
+			} else if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			cur.WriteRune(r)
+		case r == '/' && next == '/':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			cur.WriteRune('\n')
+		case r == '/' && next == '*':
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			i++
+			cur.WriteRune(' ')
+		case r == ';':
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return stmts
+}
@@ -0,0 +1,85 @@
+package neo4j
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitCypherStatements(t *testing.T) {
+	script := `
+// constraints
+CREATE CONSTRAINT IF NOT EXISTS FOR (c:Company) REQUIRE c.normalized_name IS UNIQUE;
+/* multi-line
+   comment; with separator */
+CREATE INDEX IF NOT EXISTS FOR (e:Event) ON (e.time);
+MERGE (n:Note {text: 'a;b \' c'}) SET n.` + "`odd;name`" + ` = "x;y";
+;
+`
+	got := SplitCypherStatements(script)
+	want := []string{
+		"CREATE CONSTRAINT IF NOT EXISTS FOR (c:Company) REQUIRE c.normalized_name IS UNIQUE",
+		"CREATE INDEX IF NOT EXISTS FOR (e:Event) ON (e.time)",
+		"MERGE (n:Note {text: 'a;b \\' c'}) SET n.`odd;name` = \"x;y\"",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("SplitCypherStatements:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestMigrationVersion(t *testing.T) {
+	cases := map[string]string{
+		"0001_kg_constraints.cypher": "0001",
+		"12_x.cypher":                "12",
+		"init.cypher":                "",
+		"v1_init.cypher":             "",
+	}
+	for name, want := range cases {
+		if got := migrationVersion(name); got != want {
+			t.Errorf("migrationVersion(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestListCypherFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"0002_b.cypher", "0001_a.cypher", "README.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := listCypherFiles(dir)
+	want := []string{filepath.Join(dir, "0001_a.cypher"), filepath.Join(dir, "0002_b.cypher")}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("listCypherFiles = %q, %v; want %q", got, err, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "0002_a.cypher"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listCypherFiles(dir); err == nil || !strings.Contains(err.Error(), "share version 0002") {
+		t.Fatalf("duplicate version: expected error, got %v", err)
+	}
+}
+
+func TestChunkRows(t *testing.T) {
+	rows := make([]map[string]any, 5)
+	sizes := func(chunks [][]map[string]any) []int {
+		out := make([]int, len(chunks))
+		for i, c := range chunks {
+			out[i] = len(c)
+		}
+		return out
+	}
+	if got := sizes(chunkRows(rows, 2)); !reflect.DeepEqual(got, []int{2, 2, 1}) {
+		t.Fatalf("chunk size 2: %v", got)
+	}
+	if got := sizes(chunkRows(rows, 0)); !reflect.DeepEqual(got, []int{5}) {
+		t.Fatalf("chunk size 0: %v", got)
+	}
+	if got := chunkRows(nil, 3); len(got) != 0 {
+		t.Fatalf("empty rows produced %d chunks", len(got))
+	}
+}
@@ -0,0 +1,99 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+
+	n4j "github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Tx is a managed transaction handed to ExecuteRead/ExecuteWrite work functions.
+// Statements run through it commit or roll back together.
+type Tx struct {
+	tx n4j.ManagedTransaction
+}
+
+// TxFunc is the unit of work of a managed transaction. It may be invoked more than once
+// when the transaction is retried, so it must not have side effects outside the database.
+type TxFunc func(ctx context.Context, tx *Tx) error
+
+// Query runs a statement and returns its records as []map[string]any.
+func (t *Tx) Query(ctx context.Context, cypher string, params map[string]any) ([]map[string]any, error) {
+	result, err := t.tx.Run(ctx, cypher, params)
+	if err != nil {
+		return nil, err
+	}
+	records, err := result.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]map[string]any, 0, len(records))
+	for _, record := range records {
+		row := make(map[string]any, len(record.Keys))
+		for i, key := range record.Keys {
+			row[key] = toSerializable(record.Values[i])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// Exec runs a write statement and returns the number of affected entities.
+func (t *Tx) Exec(ctx context.Context, cypher string, params map[string]any) (int64, error) {
+	result, err := t.tx.Run(ctx, cypher, params)
+	if err != nil {
+		return 0, err
+	}
+	summary, err := result.Consume(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return affectedCount(summary.Counters()), nil
+}
+
+// ExecuteWrite runs fn in a managed write transaction. Transient failures (deadlocks,
+// leader switches, lost connections) are retried by the driver within max_transaction_retry_time.
+func (c *Neo4jComponent) ExecuteWrite(ctx context.Context, fn TxFunc) error {
+	return c.execute(ctx, n4j.AccessModeWrite, opTxWrite, fn)
+}
+
+// ExecuteRead runs fn in a managed read transaction, retried like ExecuteWrite.
+func (c *Neo4jComponent) ExecuteRead(ctx context.Context, fn TxFunc) error {
+	return c.execute(ctx, n4j.AccessModeRead, opTxRead, fn)
+}
+
+func (c *Neo4jComponent) execute(ctx context.Context, mode n4j.AccessMode, op string, fn TxFunc) (err error) {
+	if c.driver == nil {
+		return fmt.Errorf("neo4j driver not initialized")
+	}
+	done := c.metrics.begin(ctx, c.cfg.Database, op)
+	defer func() { done(err) }()
+
+	session := c.driver.NewSession(ctx, n4j.SessionConfig{
+		DatabaseName: c.cfg.Database,
+		AccessMode:   mode,
+	})
+	defer func() { _ = session.Close(ctx) }()
+
+	attempts := 0
+	work := func(tx n4j.ManagedTransaction) (any, error) {
+		if attempts++; attempts > 1 {
+			c.metrics.retry(c.cfg.Database, op)
+		}
+		return nil, fn(ctx, &Tx{tx: tx})
+	}
+	if mode == n4j.AccessModeWrite {
+		_, err = session.ExecuteWrite(ctx, work)
+	} else {
+		_, err = session.ExecuteRead(ctx, work)
+	}
+	if err != nil {
+		return fmt.Errorf("neo4j %s transaction failed after %d attempt(s): %w", op, attempts, err)
+	}
+	return nil
+}
+
+func affectedCount(counters n4j.Counters) int64 {
+	return int64(counters.NodesCreated()) + int64(counters.RelationshipsCreated()) +
+		int64(counters.PropertiesSet()) + int64(counters.NodesDeleted()) + int64(counters.RelationshipsDeleted())
+}
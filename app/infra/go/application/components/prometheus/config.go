@@ -9,4 +9,6 @@ type Config struct {
 	Subsystem        string `yaml:"subsystem" json:"subsystem"`
 	CollectGoMetrics bool   `yaml:"collect_go_metrics" json:"collect_go_metrics"` // default true
 	CollectProcess   bool   `yaml:"collect_process" json:"collect_process"`       // default true
+	// ServeOnHTTPServer mounts Path on the main http_server router instead of listening on Address.
+	ServeOnHTTPServer bool `yaml:"serve_on_http_server" json:"serve_on_http_server"`
 }
@@ -0,0 +1,42 @@
+package prometheus
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const exemplarTraceIDLabel = "trace_id"
+
+// TraceExemplar returns exemplar labels for the sampled span carried by ctx.
+// Returns nil when telemetry is inactive (no-op tracer) or the span was not sampled.
+func TraceExemplar(ctx context.Context) prometheus.Labels {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() || !sc.IsSampled() {
+		return nil
+	}
+	return prometheus.Labels{exemplarTraceIDLabel: sc.TraceID().String()}
+}
+
+// Observe records v on o, attaching the trace ID from ctx as an exemplar when available.
+func Observe(ctx context.Context, o prometheus.Observer, v float64) {
+	if ex := TraceExemplar(ctx); ex != nil {
+		if eo, ok := o.(prometheus.ExemplarObserver); ok {
+			eo.ObserveWithExemplar(v, ex)
+			return
+		}
+	}
+	o.Observe(v)
+}
+
+// Inc increments c, attaching the trace ID from ctx as an exemplar when available.
+func Inc(ctx context.Context, c prometheus.Counter) {
+	if ex := TraceExemplar(ctx); ex != nil {
+		if ea, ok := c.(prometheus.ExemplarAdder); ok {
+			ea.AddWithExemplar(1, ex)
+			return
+		}
+	}
+	c.Inc()
+}
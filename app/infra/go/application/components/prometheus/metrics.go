@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"net/http"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -29,3 +30,11 @@ func Registry() *prometheus.Registry {
 	}
 	return nil
 }
+
+// Handler returns the global metrics handler, or nil when the component is not started.
+func Handler() http.Handler {
+	if c := C(); c != nil {
+		return c.handler
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ type Component struct {
 	cfg       *Config
 	server    *http.Server
 	registry  *prometheus.Registry
+	handler   http.Handler
 	started   bool
 	namespace string
 	subsystem string
@@ -54,8 +55,19 @@ func (c *Component) Start(ctx context.Context) error {
 	c.namespace = c.cfg.Namespace
 	c.subsystem = c.cfg.Subsystem
 
+	// OpenMetrics exposition is required for exemplars to be scraped.
+	c.handler = promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{EnableOpenMetrics: true})
+
+	registerGlobal(c)
+	c.started = true
+
+	if c.cfg.ServeOnHTTPServer {
+		logging.Infof(ctx, "prometheus metrics served by http_server on %s", c.cfg.Path)
+		return nil
+	}
+
 	mux := http.NewServeMux()
-	mux.Handle(c.cfg.Path, promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{}))
+	mux.Handle(c.cfg.Path, c.handler)
 
 	c.server = &http.Server{
 		Addr:              c.cfg.Address,
@@ -69,9 +81,6 @@ func (c *Component) Start(ctx context.Context) error {
 			logging.Errorf(ctx, "prometheus server error: %v", err)
 		}
 	}()
-
-	registerGlobal(c)
-	c.started = true
 	return nil
 }
 
@@ -99,6 +108,15 @@ func (c *Component) HealthCheck() error {
 	return nil
 }
 
+// Path returns the configured metrics path.
+func (c *Component) Path() string { return c.cfg.Path }
+
+// ServeOnHTTPServer reports whether the metrics endpoint should be mounted on the main http_server.
+func (c *Component) ServeOnHTTPServer() bool { return c.cfg.ServeOnHTTPServer }
+
+// Handler returns the metrics exposition handler (nil before Start).
+func (c *Component) Handler() http.Handler { return c.handler }
+
 // Helpers to build fully qualified name.
 func (c *Component) fqName(name string) string {
 	if c.namespace == "" && c.subsystem == "" {
@@ -132,3 +150,12 @@ func (c *Component) NewHistogram(name, help string, labels []string, buckets []f
 	_ = c.registry.Register(hv)
 	return hv
 }
+
+func (c *Component) NewGauge(name, help string, labels []string) *prometheus.GaugeVec {
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: c.fqName(name),
+		Help: help,
+	}, labels)
+	_ = c.registry.Register(gv)
+	return gv
+}
@@ -0,0 +1,221 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestClient(t *testing.T) (*miniredis.Miniredis, redis.UniversalClient) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{mr.Addr()}})
+	t.Cleanup(func() { _ = client.Close() })
+	return mr, client
+}
+
+func TestLock_ExclusiveWithIncreasingFencingToken(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newTestClient(t)
+
+	first, err := ObtainLock(ctx, client, "job", time.Second, LockOptions{})
+	if err != nil {
+		t.Fatalf("obtain: %v", err)
+	}
+	if _, err := ObtainLock(ctx, client, "job", time.Second, LockOptions{}); !errors.Is(err, ErrLockNotObtained) {
+		t.Fatalf("expected ErrLockNotObtained, got %v", err)
+	}
+
+	// Expire the first owner; a stale owner must not be able to release the new holder's lock.
+	mr.FastForward(2 * time.Second)
+	second, err := ObtainLock(ctx, client, "job", time.Second, LockOptions{})
+	if err != nil {
+		t.Fatalf("obtain after expiry: %v", err)
+	}
+	if second.FencingToken() <= first.FencingToken() {
+		t.Fatalf("fencing token did not increase: %d -> %d", first.FencingToken(), second.FencingToken())
+	}
+	if err := first.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("stale release: expected ErrLockNotHeld, got %v", err)
+	}
+	if err := first.Refresh(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("stale refresh: expected ErrLockNotHeld, got %v", err)
+	}
+	if err := second.Release(ctx); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if mr.Exists(lockKey("job")) {
+		t.Fatalf("lock key still present after release")
+	}
+}
+
+func TestIdempotencyStore_ReplaysStoredResponse(t *testing.T) {
+	_, client := newTestClient(t)
+	store := NewIdempotencyStore(client, IdempotencyOptions{Prefix: "test:idem"})
+
+	var calls atomic.Int32
+	h := store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"call":%d}`, n)
+	}))
+
+	do := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body))
+		req.Header.Set(IdempotencyKeyHeader, "abc")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := do(`{"a":1}`)
+	second := do(`{"a":1}`)
+	if calls.Load() != 1 {
+		t.Fatalf("handler executed %d times", calls.Load())
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("replay mismatch: %d %q vs %q", second.Code, second.Body.String(), first.Body.String())
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("replayed header not set")
+	}
+	if conflict := do(`{"a":2}`); conflict.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for key reuse with different body, got %d", conflict.Code)
+	}
+}
+
+func TestIdempotencyStore_StoresResultAfterClientDisconnect(t *testing.T) {
+	_, client := newTestClient(t)
+	store := NewIdempotencyStore(client, IdempotencyOptions{Prefix: "test:idem"})
+
+	var calls atomic.Int32
+	reqCtx, disconnect := context.WithCancel(context.Background())
+	h := store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		disconnect() // the client goes away while the handler is finishing
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(`{}`)).WithContext(reqCtx)
+	req.Header.Set(IdempotencyKeyHeader, "gone")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	retry := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(`{}`))
+	retry.Header.Set(IdempotencyKeyHeader, "gone")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, retry)
+	if rec.Code != http.StatusCreated || rec.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("retry after disconnect: expected replayed 201, got %d", rec.Code)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler executed %d times", calls.Load())
+	}
+}
+
+func TestIdempotencyStore_RefreshesInFlightMarker(t *testing.T) {
+	mr, client := newTestClient(t)
+	store := NewIdempotencyStore(client, IdempotencyOptions{Prefix: "test:idem", InFlightTTL: 300 * time.Millisecond})
+
+	var retryCode int
+	created := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusCreated) })
+	h := store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Run well past InFlightTTL; the marker must keep blocking retries.
+		for i := 0; i < 20; i++ {
+			time.Sleep(25 * time.Millisecond)
+			mr.FastForward(25 * time.Millisecond)
+		}
+		retry := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(`{}`))
+		retry.Header.Set(IdempotencyKeyHeader, "slow")
+		rec := httptest.NewRecorder()
+		store.Middleware(created).ServeHTTP(rec, retry)
+		retryCode = rec.Code
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(`{}`))
+	req.Header.Set(IdempotencyKeyHeader, "slow")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if retryCode != http.StatusConflict {
+		t.Fatalf("retry during a long request: expected 409, got %d", retryCode)
+	}
+}
+
+func TestIdempotencyStore_DoesNotClobberNewOwner(t *testing.T) {
+	mr, client := newTestClient(t)
+	store := NewIdempotencyStore(client, IdempotencyOptions{Prefix: "test:idem"})
+	newer := []byte(`{"state":"in_flight","fingerprint":"x","owner":"other"}`)
+
+	for _, status := range []int{http.StatusOK, http.StatusInternalServerError} {
+		h := store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Our marker expired and another request took the key over.
+			mr.Del("test:idem:lost")
+			if err := mr.Set("test:idem:lost", string(newer)); err != nil {
+				t.Fatalf("set: %v", err)
+			}
+			w.WriteHeader(status)
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "lost")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if got, err := mr.Get("test:idem:lost"); err != nil || got != string(newer) {
+			t.Fatalf("status %d: new owner's marker overwritten: %q %v", status, got, err)
+		}
+		mr.Del("test:idem:lost")
+	}
+}
+
+func TestLeaderElector_FailoverOnStop(t *testing.T) {
+	_, client := newTestClient(t)
+	cfg := LeaderElectionConfig{LeaseDuration: 300 * time.Millisecond, RetryInterval: 20 * time.Millisecond}
+
+	started := make(chan string, 2)
+	newElector := func(id string) *LeaderElector {
+		return NewLeaderElector(client, "scheduler", cfg, LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context, _ int64) { started <- id },
+		})
+	}
+	a, b := newElector("a"), newElector("b")
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	go a.Run(ctxA)
+	if got := waitLeader(t, started); got != "a" {
+		t.Fatalf("expected a to lead first, got %s", got)
+	}
+
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	go b.Run(ctxB)
+	time.Sleep(60 * time.Millisecond)
+	if b.IsLeader() {
+		t.Fatalf("b must not lead while a holds the lease")
+	}
+
+	cancelA()
+	if got := waitLeader(t, started); got != "b" {
+		t.Fatalf("expected b to take over, got %s", got)
+	}
+	if a.IsLeader() {
+		t.Fatalf("a still reports leadership after stop")
+	}
+}
+
+func waitLeader(t *testing.T, ch <-chan string) string {
+	t.Helper()
+	select {
+	case id := <-ch:
+		return id
+	case <-time.After(2 * time.Second):
+		t.Fatal("no leader elected")
+		return ""
+	}
+}
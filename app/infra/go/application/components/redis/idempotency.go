@@ -0,0 +1,251 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the client-chosen key.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from the store.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	idempotencyStateInFlight  = "in_flight"
+	idempotencyStateCompleted = "completed"
+)
+
+// IdempotencyOptions configures an IdempotencyStore.
+type IdempotencyOptions struct {
+	Prefix      string        // key namespace, e.g. "cronjob:idem"
+	TTL         time.Duration // how long completed responses are replayable
+	InFlightTTL time.Duration // in-flight marker TTL, refreshed while the handler runs; bounds blocking after a crash
+	MaxBodySize int64         // responses larger than this are not stored
+}
+
+func (o *IdempotencyOptions) applyDefaults() {
+	if o.Prefix == "" {
+		o.Prefix = "idem"
+	}
+	if o.TTL <= 0 {
+		o.TTL = 24 * time.Hour
+	}
+	if o.InFlightTTL <= 0 {
+		o.InFlightTTL = time.Minute
+	}
+	if o.MaxBodySize <= 0 {
+		o.MaxBodySize = 1 << 20
+	}
+}
+
+// IdempotencyStore records HTTP responses by Idempotency-Key so a retried request
+// gets the original response instead of executing twice.
+type IdempotencyStore struct {
+	client redis.UniversalClient
+	opts   IdempotencyOptions
+}
+
+// storeScript replaces the in-flight marker with the final record only if the marker is still ours.
+var storeScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+end
+return 0
+`)
+
+type idempotencyRecord struct {
+	State       string              `json:"state"`
+	Owner       string              `json:"owner,omitempty"` // per-request token of an in-flight marker
+	Fingerprint string              `json:"fingerprint"`
+	Status      int                 `json:"status,omitempty"`
+	Header      map[string][]string `json:"header,omitempty"`
+	Body        []byte              `json:"body,omitempty"`
+}
+
+func NewIdempotencyStore(client redis.UniversalClient, opts IdempotencyOptions) *IdempotencyStore {
+	opts.applyDefaults()
+	return &IdempotencyStore{client: client, opts: opts}
+}
+
+// Middleware wraps handlers: requests without the header pass through; the first request
+// for a key executes and its response (non-5xx) is stored; repeats replay it. A repeat while
+// the first is still running gets 409; reusing a key with a different method/path/body gets 422.
+func (s *IdempotencyStore) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || s.client == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "read body failed", http.StatusBadRequest)
+			return
+		}
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		redisKey := s.opts.Prefix + ":" + key
+		fingerprint := requestFingerprint(r.Method, r.URL.Path, body)
+
+		owner, err := randomToken()
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		marker, _ := json.Marshal(idempotencyRecord{State: idempotencyStateInFlight, Fingerprint: fingerprint, Owner: owner})
+		acquired, err := s.client.SetNX(ctx, redisKey, marker, s.opts.InFlightTTL).Result()
+		if err != nil {
+			// Store unavailable: fail open rather than blocking the request.
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !acquired {
+			s.replay(w, r, redisKey, fingerprint)
+			return
+		}
+
+		stopRefresh := s.keepInFlight(redisKey, marker)
+		rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK, limit: s.opts.MaxBodySize}
+		next.ServeHTTP(rec, r)
+		stopRefresh()
+
+		// The handler has run: record the outcome even if the client went away meanwhile,
+		// otherwise the in-flight marker would answer retries with 409 until InFlightTTL.
+		// Both writes only act on our own marker: if it expired and another request took
+		// the key over, that request's marker or record must not be overwritten.
+		ctx = context.WithoutCancel(ctx)
+		if rec.status >= http.StatusInternalServerError || rec.overflow {
+			// Let the client retry a failed (or unstorable) request.
+			releaseScript.Run(ctx, s.client, []string{redisKey}, marker)
+			return
+		}
+		payload, err := json.Marshal(idempotencyRecord{
+			State:       idempotencyStateCompleted,
+			Fingerprint: fingerprint,
+			Status:      rec.status,
+			Header:      w.Header().Clone(),
+			Body:        rec.buf.Bytes(),
+		})
+		if err == nil {
+			storeScript.Run(ctx, s.client, []string{redisKey}, marker, payload, s.opts.TTL.Milliseconds())
+		}
+	})
+}
+
+// keepInFlight extends the marker every InFlightTTL/3 until the returned stop is called,
+// so a handler running longer than InFlightTTL keeps blocking concurrent retries.
+func (s *IdempotencyStore) keepInFlight(redisKey string, marker []byte) (stop func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	interval := s.opts.InFlightTTL / 3
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				n, err := refreshScript.Run(ctx, s.client, []string{redisKey}, marker, s.opts.InFlightTTL.Milliseconds()).Int64()
+				cancel()
+				if err == nil && n == 0 {
+					return // marker expired or taken over; nothing left to extend
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+func (s *IdempotencyStore) replay(w http.ResponseWriter, r *http.Request, redisKey, fingerprint string) {
+	raw, err := s.client.Get(r.Context(), redisKey).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			http.Error(w, "idempotent request state expired; retry", http.StatusConflict)
+			return
+		}
+		http.Error(w, "idempotency store unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var record idempotencyRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		http.Error(w, "corrupt idempotency record", http.StatusInternalServerError)
+		return
+	}
+	if record.Fingerprint != fingerprint {
+		http.Error(w, "Idempotency-Key reused with a different request", http.StatusUnprocessableEntity)
+		return
+	}
+	if record.State != idempotencyStateCompleted {
+		http.Error(w, "request with this Idempotency-Key is still in progress", http.StatusConflict)
+		return
+	}
+	for k, vs := range record.Header {
+		if http.CanonicalHeaderKey(k) == "Traceparent" {
+			continue // keep the current request's trace context
+		}
+		w.Header()[k] = vs
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.Status)
+	_, _ = w.Write(record.Body)
+}
+
+func requestFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter tees the response into a bounded buffer.
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buf         bytes.Buffer
+	limit       int64
+	overflow    bool
+}
+
+func (w *recordingWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	if !w.overflow {
+		if int64(w.buf.Len()+len(p)) > w.limit {
+			w.overflow = true
+			w.buf.Reset()
+		} else {
+			w.buf.Write(p)
+		}
+	}
+	return w.ResponseWriter.Write(p)
+}
@@ -0,0 +1,145 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+)
+
+// LeaderElectionConfig tunes a LeaderElector.
+type LeaderElectionConfig struct {
+	// LeaseDuration is the lock TTL; a dead leader is replaced within roughly this long.
+	LeaseDuration time.Duration
+	// RenewInterval is how often the leader extends its lease (must be < LeaseDuration).
+	RenewInterval time.Duration
+	// RetryInterval is how often followers try to take the lease.
+	RetryInterval time.Duration
+}
+
+func (c *LeaderElectionConfig) applyDefaults() {
+	if c.LeaseDuration <= 0 {
+		c.LeaseDuration = 15 * time.Second
+	}
+	if c.RenewInterval <= 0 || c.RenewInterval >= c.LeaseDuration {
+		c.RenewInterval = c.LeaseDuration / 3
+	}
+	if c.RetryInterval <= 0 {
+		c.RetryInterval = c.LeaseDuration / 3
+	}
+}
+
+// LeaderCallbacks are invoked on leadership transitions.
+type LeaderCallbacks struct {
+	// OnStartedLeading runs in its own goroutine; leaderCtx is cancelled when leadership is lost.
+	OnStartedLeading func(leaderCtx context.Context, fencingToken int64)
+	// OnStoppedLeading runs after leaderCtx is cancelled (lease lost or elector stopped).
+	OnStoppedLeading func()
+}
+
+// LeaderElector runs a lease-based election on top of Lock. At most one elector per
+// name holds the lease at a time; the fencing token increases with every new term.
+type LeaderElector struct {
+	client   redis.UniversalClient
+	name     string
+	cfg      LeaderElectionConfig
+	cb       LeaderCallbacks
+	isLeader atomic.Bool
+	token    atomic.Int64
+}
+
+// NewLeaderElector creates an elector for name. Call Run to participate.
+func NewLeaderElector(client redis.UniversalClient, name string, cfg LeaderElectionConfig, cb LeaderCallbacks) *LeaderElector {
+	cfg.applyDefaults()
+	return &LeaderElector{client: client, name: name, cfg: cfg, cb: cb}
+}
+
+// IsLeader reports whether this elector currently holds the lease.
+func (le *LeaderElector) IsLeader() bool { return le.isLeader.Load() }
+
+// FencingToken returns the token of the current term (0 when not leading).
+func (le *LeaderElector) FencingToken() int64 { return le.token.Load() }
+
+// Run blocks until ctx is done, campaigning for and holding leadership.
+// On return the lease is released so another replica can take over immediately.
+func (le *LeaderElector) Run(ctx context.Context) {
+	for {
+		lock, err := ObtainLock(ctx, le.client, le.name, le.cfg.LeaseDuration, LockOptions{})
+		if err == nil {
+			le.lead(ctx, lock)
+		} else if !errors.Is(err, ErrLockNotObtained) {
+			logging.Warn(ctx, "leader election obtain failed", zap.String("name", le.name), zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(le.cfg.RetryInterval):
+		}
+	}
+}
+
+// lead holds the lease until renewal fails or ctx is done.
+func (le *LeaderElector) lead(ctx context.Context, lock *Lock) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	le.token.Store(lock.FencingToken())
+	le.isLeader.Store(true)
+	logging.Info(ctx, "became leader", zap.String("name", le.name), zap.Int64("fencing_token", lock.FencingToken()))
+
+	var wg sync.WaitGroup
+	if le.cb.OnStartedLeading != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			le.cb.OnStartedLeading(leaderCtx, lock.FencingToken())
+		}()
+	}
+
+	le.holdLease(ctx, lock)
+
+	le.isLeader.Store(false)
+	le.token.Store(0)
+	cancel()
+	wg.Wait()
+	releaseCtx, releaseCancel := context.WithTimeout(context.Background(), le.cfg.RenewInterval)
+	_ = lock.Release(releaseCtx)
+	releaseCancel()
+	logging.Info(ctx, "stopped leading", zap.String("name", le.name))
+	if le.cb.OnStoppedLeading != nil {
+		le.cb.OnStoppedLeading()
+	}
+}
+
+func (le *LeaderElector) holdLease(ctx context.Context, lock *Lock) {
+	ticker := time.NewTicker(le.cfg.RenewInterval)
+	defer ticker.Stop()
+	lastRenew := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			renewCtx, cancel := context.WithTimeout(ctx, le.cfg.RenewInterval)
+			err := lock.Refresh(renewCtx)
+			cancel()
+			switch {
+			case err == nil:
+				lastRenew = time.Now()
+			case errors.Is(err, ErrLockNotHeld):
+				logging.Warn(ctx, "leader lease lost", zap.String("name", le.name))
+				return
+			default:
+				// Step down before the lease could have expired elsewhere.
+				if time.Since(lastRenew)+le.cfg.RenewInterval >= le.cfg.LeaseDuration {
+					logging.Warn(ctx, "leader lease renewal failing; stepping down", zap.String("name", le.name), zap.Error(err))
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrLockNotObtained is returned when the lock is held by another owner after all retries.
+	ErrLockNotObtained = errors.New("redis lock not obtained")
+	// ErrLockNotHeld is returned by Refresh/Release when the lock expired or was taken over.
+	ErrLockNotHeld = errors.New("redis lock not held")
+)
+
+// Lock key layout: both keys share the {name} hash tag so the Lua scripts touch a single
+// slot and work unchanged in cluster mode.
+func lockKey(name string) string  { return "lock:{" + name + "}" }
+func fenceKey(name string) string { return "lock:{" + name + "}:fence" }
+
+// obtainScript sets the lock if free and returns a monotonically increasing fencing token, or 0.
+var obtainScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0
+`)
+
+// refreshScript extends the TTL only if the caller still owns the lock.
+var refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lock only if the caller still owns it.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// LockOptions tunes Obtain.
+type LockOptions struct {
+	// RetryInterval between acquisition attempts; 0 means try once.
+	RetryInterval time.Duration
+	// WaitTimeout bounds total acquisition time when RetryInterval > 0 (0 = until ctx is done).
+	WaitTimeout time.Duration
+	// AutoRenew keeps extending the TTL (every ttl/3) until Release; Lost() fires if renewal fails.
+	AutoRenew bool
+}
+
+// Lock is a single-instance Redis lock with an owner token and a fencing token.
+// Writers guarded by the lock should pass FencingToken() to the protected resource and
+// reject tokens lower than the last one seen, which makes a paused former owner harmless.
+type Lock struct {
+	client redis.UniversalClient
+	name   string
+	owner  string
+	fence  int64
+	ttl    time.Duration
+
+	mu       sync.Mutex
+	released bool
+	stop     chan struct{}
+	lost     chan struct{}
+	lostOnce sync.Once
+	renewWG  sync.WaitGroup
+}
+
+// ObtainLock tries to acquire the named lock for ttl.
+func ObtainLock(ctx context.Context, client redis.UniversalClient, name string, ttl time.Duration, opts LockOptions) (*Lock, error) {
+	if client == nil {
+		return nil, errors.New("redis client nil")
+	}
+	if name == "" || ttl <= 0 {
+		return nil, fmt.Errorf("invalid lock name/ttl: %q %s", name, ttl)
+	}
+	owner, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	if opts.WaitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.WaitTimeout)
+		defer cancel()
+	}
+
+	for {
+		fence, err := obtainScript.Run(ctx, client, []string{lockKey(name), fenceKey(name)}, owner, ttl.Milliseconds()).Int64()
+		if err != nil {
+			return nil, fmt.Errorf("obtain lock %s: %w", name, err)
+		}
+		if fence > 0 {
+			l := &Lock{
+				client: client,
+				name:   name,
+				owner:  owner,
+				fence:  fence,
+				ttl:    ttl,
+				stop:   make(chan struct{}),
+				lost:   make(chan struct{}),
+			}
+			if opts.AutoRenew {
+				l.renewWG.Add(1)
+				go l.renewLoop()
+			}
+			return l, nil
+		}
+		if opts.RetryInterval <= 0 {
+			return nil, ErrLockNotObtained
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ErrLockNotObtained
+		case <-time.After(opts.RetryInterval):
+		}
+	}
+}
+
+// Name returns the lock name.
+func (l *Lock) Name() string { return l.name }
+
+// FencingToken returns the token issued when this lock was obtained (strictly increasing per name).
+func (l *Lock) FencingToken() int64 { return l.fence }
+
+// Lost is closed when auto-renewal discovers the lock is no longer held.
+func (l *Lock) Lost() <-chan struct{} { return l.lost }
+
+// Refresh extends the lock TTL; returns ErrLockNotHeld if ownership was lost.
+func (l *Lock) Refresh(ctx context.Context) error {
+	n, err := refreshScript.Run(ctx, l.client, []string{lockKey(l.name)}, l.owner, l.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return fmt.Errorf("refresh lock %s: %w", l.name, err)
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Release deletes the lock if still owned. Safe to call more than once.
+func (l *Lock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	if l.released {
+		l.mu.Unlock()
+		return nil
+	}
+	l.released = true
+	close(l.stop)
+	l.mu.Unlock()
+	l.renewWG.Wait()
+
+	n, err := releaseScript.Run(ctx, l.client, []string{lockKey(l.name)}, l.owner).Int64()
+	if err != nil {
+		return fmt.Errorf("release lock %s: %w", l.name, err)
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+func (l *Lock) renewLoop() {
+	defer l.renewWG.Done()
+	interval := l.ttl / 3
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			err := l.Refresh(ctx)
+			cancel()
+			if errors.Is(err, ErrLockNotHeld) {
+				l.markLost()
+				return
+			}
+			// Transient errors: keep trying until the TTL runs out; the next
+			// successful Refresh either extends it or reports ErrLockNotHeld.
+		}
+	}
+}
+
+func (l *Lock) markLost() { l.lostOnce.Do(func() { close(l.lost) }) }
+
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate lock token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
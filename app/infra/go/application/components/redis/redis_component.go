@@ -114,3 +114,18 @@ func (rc *RedisComponent) ping(ctx context.Context) error {
 func (rc *RedisComponent) Client() redis.UniversalClient {
 	return rc.client
 }
+
+// ObtainLock acquires a fenced distributed lock (see Lock).
+func (rc *RedisComponent) ObtainLock(ctx context.Context, name string, ttl time.Duration, opts LockOptions) (*Lock, error) {
+	return ObtainLock(ctx, rc.client, name, ttl, opts)
+}
+
+// NewLeaderElector creates a lease-based leader elector for name.
+func (rc *RedisComponent) NewLeaderElector(name string, cfg LeaderElectionConfig, cb LeaderCallbacks) *LeaderElector {
+	return NewLeaderElector(rc.client, name, cfg, cb)
+}
+
+// NewIdempotencyStore creates an Idempotency-Key response store for HTTP handlers.
+func (rc *RedisComponent) NewIdempotencyStore(opts IdempotencyOptions) *IdempotencyStore {
+	return NewIdempotencyStore(rc.client, opts)
+}
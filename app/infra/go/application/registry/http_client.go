@@ -17,6 +17,10 @@ func init() {
 		if err != nil {
 			return true, nil, err
 		}
+		// Start after prometheus so per-client metrics can be registered.
+		if cfg.Prometheus != nil && cfg.Prometheus.Enabled {
+			comp.(*http_client.HTTPClientsComponent).AddDependencies(consts.COMPONENT_PROMETHEUS)
+		}
 		return true, comp, nil
 	})
 }
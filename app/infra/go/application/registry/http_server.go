@@ -21,6 +21,10 @@ func init() {
 		if err != nil {
 			return true, nil, err
 		}
+		// Start after prometheus so RED metrics and the optional /metrics route can be wired.
+		if cfg.Prometheus != nil && cfg.Prometheus.Enabled {
+			comp.(*http_server.HTTPServerComponent).AddDependencies(consts.COMPONENT_PROMETHEUS)
+		}
 		return true, comp, nil
 	})
 }
@@ -17,6 +17,10 @@ func init() {
 		if err != nil {
 			return true, nil, err
 		}
+		// Start after prometheus so query metrics can be registered.
+		if cfg.Prometheus != nil && cfg.Prometheus.Enabled {
+			comp.(*neo4jcomp.Neo4jComponent).AddDependencies(consts.COMPONENT_PROMETHEUS)
+		}
 		return true, comp, nil
 	})
 }
@@ -4,6 +4,13 @@ import "sync"
 
 // Merge Merge multi channels to a single channels
 func Merge(done <-chan struct{}, chans ...<-chan any) <-chan any {
+	return MergeUntil(done, done, chans...)
+}
+
+// MergeUntil merges chans like Merge, but separates stopping intake from aborting:
+// once stop is closed no more values are read, while a value already read is still
+// delivered unless abort is closed. out closes when every forwarder has exited.
+func MergeUntil(stop, abort <-chan struct{}, chans ...<-chan any) <-chan any {
 	out := make(chan any)
 
 	var wg sync.WaitGroup
@@ -13,13 +20,19 @@ func Merge(done <-chan struct{}, chans ...<-chan any) <-chan any {
 		defer wg.Done()
 		for {
 			select {
-			case <-done:
+			case <-stop:
+				return
+			case <-abort:
 				return
 			case v, ok := <-c:
 				if !ok {
 					return
 				}
-				out <- v
+				select {
+				case <-abort:
+					return
+				case out <- v:
+				}
 			}
 		}
 	}
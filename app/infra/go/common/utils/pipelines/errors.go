@@ -0,0 +1,59 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrDrainTimeout is returned by Drain when in-flight items did not finish before its context expired.
+var ErrDrainTimeout = errors.New("pipelines: drain timed out, pipeline aborted")
+
+// NodeError describes an item a node failed to process.
+type NodeError struct {
+	Pipeline string
+	Node     string
+	Item     any
+	Err      error
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("pipeline %s node %s: %v", e.Pipeline, e.Node, e.Err)
+}
+
+func (e *NodeError) Unwrap() error { return e.Err }
+
+// ErrorHandler is called for every failed item, from the failing worker goroutine.
+type ErrorHandler func(ctx context.Context, err *NodeError)
+
+// DeadLetterSink stores failed items for inspection or replay.
+type DeadLetterSink interface {
+	Put(ctx context.Context, err *NodeError) error
+}
+
+// DeadLetterFunc adapts a function to DeadLetterSink.
+type DeadLetterFunc func(ctx context.Context, err *NodeError) error
+
+func (f DeadLetterFunc) Put(ctx context.Context, err *NodeError) error { return f(ctx, err) }
+
+// ChannelDeadLetter is a bounded in-memory sink. When full, Put blocks until the
+// item is consumed or ctx is done, applying backpressure to the failing node.
+type ChannelDeadLetter struct {
+	ch chan *NodeError
+}
+
+func NewChannelDeadLetter(size int) *ChannelDeadLetter {
+	return &ChannelDeadLetter{ch: make(chan *NodeError, size)}
+}
+
+func (d *ChannelDeadLetter) Put(ctx context.Context, err *NodeError) error {
+	select {
+	case d.ch <- err:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// C returns the channel failed items are delivered on.
+func (d *ChannelDeadLetter) C() <-chan *NodeError { return d.ch }
@@ -0,0 +1,100 @@
+package pipelines
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds per-node counters shared by every pipeline configured WithMetrics.
+type Metrics struct {
+	items      *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	inFlight   *prometheus.GaugeVec
+	deadLetter *prometheus.CounterVec
+}
+
+// NewMetrics registers pipeline metrics on reg (prometheus.DefaultRegisterer when nil).
+// Registering twice on the same registry returns the already-registered collectors.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	m := &Metrics{
+		items: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pipeline_node_items_total",
+			Help: "Items processed by a pipeline node, by pipeline, node and status (ok|error).",
+		}, []string{"pipeline", "node", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "pipeline_node_process_duration_seconds",
+			Help:    "Time a pipeline node spent processing one item.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"pipeline", "node"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "pipeline_node_in_flight",
+			Help: "Items currently being processed by a pipeline node.",
+		}, []string{"pipeline", "node"}),
+		deadLetter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pipeline_dead_letter_total",
+			Help: "Failed items handed to the dead-letter sink, by pipeline, node and result (ok|error).",
+		}, []string{"pipeline", "node", "result"}),
+	}
+	var err error
+	if m.items, err = register(reg, m.items); err != nil {
+		return nil, err
+	}
+	if m.duration, err = register(reg, m.duration); err != nil {
+		return nil, err
+	}
+	if m.inFlight, err = register(reg, m.inFlight); err != nil {
+		return nil, err
+	}
+	if m.deadLetter, err = register(reg, m.deadLetter); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) (C, error) {
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(C); ok {
+				return existing, nil
+			}
+		}
+		return c, err
+	}
+	return c, nil
+}
+
+// begin marks an item in flight and returns a func recording its outcome.
+func (m *Metrics) begin(pipeline, node string) func(err error) {
+	if m == nil {
+		return func(error) {}
+	}
+	gauge := m.inFlight.WithLabelValues(pipeline, node)
+	gauge.Inc()
+	start := time.Now()
+	return func(err error) {
+		gauge.Dec()
+		status := "ok"
+		if err != nil {
+			status = "error"
+		}
+		m.items.WithLabelValues(pipeline, node, status).Inc()
+		m.duration.WithLabelValues(pipeline, node).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (m *Metrics) deadLettered(pipeline, node string, err error) {
+	if m == nil {
+		return
+	}
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	m.deadLetter.WithLabelValues(pipeline, node, result).Inc()
+}
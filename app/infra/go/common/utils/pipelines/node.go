@@ -1,54 +1,90 @@
 package pipelines
 
 import (
+	"context"
 	"sync"
 
 	"github.com/grand-thief-cash/chaos/app/infra/go/common/utils/channels"
 )
 
 type Node struct {
-	Name    string
-	Inputs  []<-chan any // multi inputs
-	Output  chan any     // 下游输出
-	Task    Task         // 加工任务
-	Workers int          // parallel workers (>=1)
+	Name       string
+	Inputs     []<-chan any // multi inputs
+	Output     chan any     // 下游输出
+	Task       Task         // 加工任务
+	Workers    int          // parallel workers (>=1)
+	BufferSize int          // Output capacity; bounds how far this node runs ahead of its consumers
 
+	source bool // reads external inputs; stops intake when the pipeline context is cancelled
 }
 
-func NewNode(name string, task Task) *Node {
-	return &Node{
-		Name:    name,
-		Output:  make(chan any),
-		Task:    task,
-		Workers: 1,
+// NodeOption configures a Node at construction time.
+type NodeOption func(*Node)
+
+// WithWorkers sets the number of parallel workers (>=1).
+func WithWorkers(n int) NodeOption {
+	return func(node *Node) {
+		if n > 0 {
+			node.Workers = n
+		}
 	}
 }
-func NewNodeWithSource(name string, task Task, input <-chan any) *Node {
-	return &Node{
-		Name:    name,
-		Inputs:  []<-chan any{input},
-		Output:  make(chan any),
-		Task:    task,
-		Workers: 1,
+
+// WithBuffer sets the Output channel capacity. 0 (default) is unbuffered: a slow consumer
+// immediately blocks the workers, which in turn stop reading their inputs (backpressure).
+func WithBuffer(n int) NodeOption {
+	return func(node *Node) {
+		if n > 0 {
+			node.BufferSize = n
+		}
 	}
 }
 
-func NewNodeWithSourceParallel(name string, task Task, input <-chan any, workers int) *Node {
-	if workers <= 0 {
-		workers = 1
-	}
-	return &Node{
+func NewNode(name string, task Task, opts ...NodeOption) *Node {
+	n := &Node{
 		Name:    name,
-		Inputs:  []<-chan any{input},
-		Output:  make(chan any),
 		Task:    task,
-		Workers: workers,
+		Workers: 1,
+	}
+	for _, opt := range opts {
+		opt(n)
 	}
+	n.Output = make(chan any, n.BufferSize)
+	return n
 }
 
+func NewNodeWithSource(name string, task Task, input <-chan any, opts ...NodeOption) *Node {
+	n := NewNode(name, task, opts...)
+	n.Inputs = []<-chan any{input}
+	return n
+}
+
+func NewNodeWithSourceParallel(name string, task Task, input <-chan any, workers int, opts ...NodeOption) *Node {
+	return NewNodeWithSource(name, task, input, append([]NodeOption{WithWorkers(workers)}, opts...)...)
+}
+
+// Run starts the node without a pipeline: failed items are dropped and done stops it immediately.
 func (n *Node) Run(done <-chan struct{}) {
-	// Merge inputs once; shared by all workers.
-	in := channels.Merge(done, n.Inputs...)
+	n.run(runEnv{ctx: context.Background(), intake: done, abort: done}, nil)
+}
+
+// runEnv is the per-run state shared by all nodes of a pipeline.
+type runEnv struct {
+	ctx    context.Context // passed to tasks; cancelled on abort
+	intake <-chan struct{} // closed to stop source nodes reading external inputs
+	abort  <-chan struct{} // closed to stop every node immediately
+	p      *Pipeline       // error routing and metrics; nil for a standalone node
+}
+
+func (n *Node) run(env runEnv, finished *sync.WaitGroup) {
+	// Merge inputs once; shared by all workers. Only source nodes stop reading on
+	// intake; downstream nodes keep going until their inputs close so items already
+	// in the pipeline are drained.
+	var intake <-chan struct{}
+	if n.source || env.p == nil {
+		intake = env.intake
+	}
+	in := channels.MergeUntil(intake, env.abort, n.Inputs...)
 
 	workers := n.Workers
 	if workers <= 0 {
@@ -63,18 +99,19 @@ func (n *Node) Run(done <-chan struct{}) {
 			defer wg.Done()
 			for {
 				select {
-				case <-done:
+				case <-env.abort:
 					return
 				case data, ok := <-in:
 					if !ok {
 						return
 					}
-					result, err := n.Task.Process(data)
+					result, err := n.process(env, data)
 					if err != nil {
+						env.p.handleError(env.ctx, n.Name, data, err)
 						continue
 					}
 					select {
-					case <-done:
+					case <-env.abort:
 						return
 					case n.Output <- result:
 					}
@@ -87,5 +124,19 @@ func (n *Node) Run(done <-chan struct{}) {
 	go func() {
 		wg.Wait()
 		close(n.Output)
+		if finished != nil {
+			finished.Done()
+		}
 	}()
 }
+
+func (n *Node) process(env runEnv, data any) (result any, err error) {
+	if env.p != nil {
+		done := env.p.metrics.begin(env.p.Name, n.Name)
+		defer func() { done(err) }()
+	}
+	if ct, ok := n.Task.(ContextTask); ok {
+		return ct.ProcessContext(env.ctx, data)
+	}
+	return n.Task.Process(data)
+}
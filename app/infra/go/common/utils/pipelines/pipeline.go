@@ -1,22 +1,72 @@
 package pipelines
 
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
 type Pipeline struct {
+	Name     string
 	Nodes    []*Node
 	Input    chan any // 入口
 	Output   chan any // 出口
 	NodesMap map[string]*Node
+
+	onError    ErrorHandler
+	deadLetter DeadLetterSink
+	metrics    *Metrics
+
+	running   sync.WaitGroup
+	abort     chan struct{}
+	abortOnce sync.Once
+	cancel    context.CancelFunc
+
+	errMu    sync.Mutex
+	firstErr error
+	errCount atomic.Int64
+}
+
+// PipelineOption configures a Pipeline.
+type PipelineOption func(*Pipeline)
+
+// WithName sets the pipeline name used in errors and metric labels.
+func WithName(name string) PipelineOption {
+	return func(p *Pipeline) { p.Name = name }
+}
+
+// WithErrorHandler registers a callback for every item a node fails to process.
+func WithErrorHandler(h ErrorHandler) PipelineOption {
+	return func(p *Pipeline) { p.onError = h }
 }
 
-func NewPipeline() *Pipeline {
-	return &Pipeline{
+// WithDeadLetter sends failed items to sink. Items the sink accepts are not reported by Err.
+func WithDeadLetter(sink DeadLetterSink) PipelineOption {
+	return func(p *Pipeline) { p.deadLetter = sink }
+}
+
+// WithMetrics records per-node throughput, latency, in-flight and error metrics.
+func WithMetrics(m *Metrics) PipelineOption {
+	return func(p *Pipeline) { p.metrics = m }
+}
+
+func NewPipeline(opts ...PipelineOption) *Pipeline {
+	p := &Pipeline{
+		Name:     "default",
 		Input:    make(chan any),
 		Output:   make(chan any),
 		NodesMap: make(map[string]*Node),
+		abort:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
 	}
+	return p
 }
 
 func (p *Pipeline) AddSource(node *Node, input chan any) {
 	node.Inputs = []<-chan any{input}
+	node.source = true
 	p.Nodes = append(p.Nodes, node)
 }
 
@@ -34,6 +84,9 @@ func (p *Pipeline) AddStage(node *Node, upstreams ...*Node) {
 	p.NodesMap[node.Name] = node
 
 	// connect upstreams
+	if len(upstreams) == 0 {
+		node.source = true
+	}
 	for _, up := range upstreams {
 		node.Inputs = append(node.Inputs, up.Output)
 	}
@@ -42,8 +95,98 @@ func (p *Pipeline) AddStage(node *Node, upstreams ...*Node) {
 	p.Output = node.Output // update pipeline output to the last added node's output
 }
 
+// Run starts all nodes; closing done stops them immediately.
 func (p *Pipeline) Run(done <-chan struct{}) {
+	env := runEnv{ctx: context.Background(), intake: done, abort: done, p: p}
 	for _, n := range p.Nodes {
-		n.Run(done)
+		p.running.Add(1)
+		n.run(env, &p.running)
+	}
+}
+
+// Start runs the pipeline until ctx is cancelled or all source inputs close.
+//
+// Cancelling ctx begins a graceful drain: source nodes stop reading external inputs,
+// while downstream nodes keep processing items already in the pipeline until their
+// inputs close. Tasks still see a live context during the drain; it is cancelled only
+// when the pipeline is aborted (Stop, or Drain timing out). Use Drain or Wait to block
+// until the pipeline has finished.
+func (p *Pipeline) Start(ctx context.Context) {
+	taskCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	p.cancel = cancel
+	env := runEnv{ctx: taskCtx, intake: ctx.Done(), abort: p.abort, p: p}
+	for _, n := range p.Nodes {
+		p.running.Add(1)
+		n.run(env, &p.running)
+	}
+}
+
+// Wait blocks until every node has finished and returns Err.
+func (p *Pipeline) Wait() error {
+	p.running.Wait()
+	p.Stop()
+	return p.Err()
+}
+
+// Drain waits for in-flight items to finish. If ctx expires first the pipeline is
+// aborted and ErrDrainTimeout is returned. The final Output must keep being consumed
+// for the drain to complete.
+func (p *Pipeline) Drain(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		p.running.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		p.Stop()
+		return p.Err()
+	case <-ctx.Done():
+		p.Stop()
+		<-finished
+		return ErrDrainTimeout
+	}
+}
+
+// Stop aborts the pipeline immediately; items in flight are discarded. Safe to call more than once.
+func (p *Pipeline) Stop() {
+	p.abortOnce.Do(func() {
+		close(p.abort)
+		if p.cancel != nil {
+			p.cancel()
+		}
+	})
+}
+
+// Err returns the first item error that was not accepted by a dead-letter sink.
+func (p *Pipeline) Err() error {
+	p.errMu.Lock()
+	defer p.errMu.Unlock()
+	return p.firstErr
+}
+
+// ErrorCount returns the number of items that failed processing.
+func (p *Pipeline) ErrorCount() int64 { return p.errCount.Load() }
+
+func (p *Pipeline) handleError(ctx context.Context, node string, item any, err error) {
+	if p == nil {
+		return
+	}
+	p.errCount.Add(1)
+	ne := &NodeError{Pipeline: p.Name, Node: node, Item: item, Err: err}
+	if p.onError != nil {
+		p.onError(ctx, ne)
+	}
+	if p.deadLetter != nil {
+		dlErr := p.deadLetter.Put(ctx, ne)
+		p.metrics.deadLettered(p.Name, node, dlErr)
+		if dlErr == nil {
+			return
+		}
+	}
+	p.errMu.Lock()
+	if p.firstErr == nil {
+		p.firstErr = ne
 	}
+	p.errMu.Unlock()
 }
@@ -0,0 +1,155 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var errOdd = errors.New("odd")
+
+func doubleEven() TypedTask[int, int] {
+	return TaskFunc[int, int](func(_ context.Context, v int) (int, error) {
+		if v%2 != 0 {
+			return 0, errOdd
+		}
+		return v * 2, nil
+	})
+}
+
+func TestPipelineDeadLettersFailedItemsAndRecordsMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics, err := NewMetrics(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dlq := NewChannelDeadLetter(16)
+	var handled int
+	p := NewPipeline(
+		WithName("test"),
+		WithDeadLetter(dlq),
+		WithMetrics(metrics),
+		WithErrorHandler(func(context.Context, *NodeError) { handled++ }),
+	)
+	src := make(chan any)
+	node := NewTypedNode("double", doubleEven(), WithWorkers(1), WithBuffer(4))
+	node.Inputs = []<-chan any{src}
+	p.AddStage(node)
+	p.Start(context.Background())
+
+	go func() {
+		for i := 1; i <= 6; i++ {
+			src <- i
+		}
+		close(src)
+	}()
+	var got []int
+	for v := range Results[int](nil, p.Output) {
+		got = append(got, v)
+	}
+	if err := p.Wait(); err != nil {
+		t.Fatalf("dead-lettered errors must not surface from Wait: %v", err)
+	}
+
+	if fmt.Sprint(got) != "[4 8 12]" {
+		t.Fatalf("outputs = %v", got)
+	}
+	if handled != 3 || p.ErrorCount() != 3 || len(dlq.C()) != 3 {
+		t.Fatalf("handled=%d count=%d dlq=%d, want 3", handled, p.ErrorCount(), len(dlq.C()))
+	}
+	ne := <-dlq.C()
+	if ne.Node != "double" || ne.Item != 1 || !errors.Is(ne, errOdd) {
+		t.Fatalf("unexpected dead letter: %+v", ne)
+	}
+	if v := testutil.ToFloat64(metrics.items.WithLabelValues("test", "double", "ok")); v != 3 {
+		t.Fatalf("ok items = %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.items.WithLabelValues("test", "double", "error")); v != 3 {
+		t.Fatalf("error items = %v", v)
+	}
+}
+
+func TestPipelineWaitReturnsUnhandledError(t *testing.T) {
+	p := NewPipeline()
+	src := make(chan any, 2)
+	src <- 1
+	src <- "not an int"
+	close(src)
+	p.AddStage(NewNodeWithSource("double", Adapt(doubleEven()), src))
+	p.Start(context.Background())
+	for range p.Output {
+	}
+	err := p.Wait()
+	var ne *NodeError
+	if !errors.As(err, &ne) || !errors.Is(err, errOdd) {
+		t.Fatalf("expected first NodeError wrapping errOdd, got %v", err)
+	}
+	if p.ErrorCount() != 2 {
+		t.Fatalf("error count = %d, want 2", p.ErrorCount())
+	}
+}
+
+func TestPipelineCancelDrainsInFlightItems(t *testing.T) {
+	p := NewPipeline()
+	src := make(chan any)
+	slow := TaskFunc[int, int](func(ctx context.Context, v int) (int, error) {
+		time.Sleep(20 * time.Millisecond)
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		return v, nil
+	})
+	first := NewNodeWithSource("first", Adapt(TaskFunc[int, int](func(_ context.Context, v int) (int, error) { return v, nil })), src, WithBuffer(8))
+	second := NewTypedNode("slow", slow, WithBuffer(8))
+	p.AddStage(first)
+	p.AddStage(second, first)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.Start(ctx)
+	for i := 0; i < 3; i++ {
+		src <- i
+	}
+	cancel()
+
+	var got []int
+	results := Results[int](nil, p.Output)
+	drained := make(chan error, 1)
+	go func() {
+		drainCtx, stop := context.WithTimeout(context.Background(), 2*time.Second)
+		defer stop()
+		drained <- p.Drain(drainCtx)
+	}()
+	for v := range results {
+		got = append(got, v)
+	}
+	if err := <-drained; err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	sort.Ints(got)
+	if fmt.Sprint(got) != "[0 1 2]" {
+		t.Fatalf("items accepted before cancel must be drained, got %v", got)
+	}
+}
+
+func TestPipelineDrainTimeoutAborts(t *testing.T) {
+	p := NewPipeline()
+	src := make(chan any, 1)
+	src <- 1
+	p.AddStage(NewNodeWithSource("pass", Adapt(TaskFunc[int, int](func(_ context.Context, v int) (int, error) { return v, nil })), src))
+	ctx, cancel := context.WithCancel(context.Background())
+	p.Start(ctx)
+	time.Sleep(10 * time.Millisecond) // item is blocked on the unconsumed Output
+	cancel()
+
+	drainCtx, stop := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer stop()
+	if err := p.Drain(drainCtx); !errors.Is(err, ErrDrainTimeout) {
+		t.Fatalf("expected ErrDrainTimeout, got %v", err)
+	}
+}
@@ -1,5 +1,74 @@
 package pipelines
 
+import (
+	"context"
+	"fmt"
+)
+
 type Task interface {
 	Process(data any) (any, error)
 }
+
+// ContextTask is implemented by tasks that want the pipeline context; Node prefers it over Process.
+type ContextTask interface {
+	ProcessContext(ctx context.Context, data any) (any, error)
+}
+
+// TypedTask is a Task with static input/output types.
+type TypedTask[In, Out any] interface {
+	Process(ctx context.Context, in In) (Out, error)
+}
+
+// TaskFunc adapts a plain function to TypedTask.
+type TaskFunc[In, Out any] func(ctx context.Context, in In) (Out, error)
+
+func (f TaskFunc[In, Out]) Process(ctx context.Context, in In) (Out, error) { return f(ctx, in) }
+
+// Adapt wraps a TypedTask so it can run in a Node. An item of the wrong type is
+// reported as an error (and dead-lettered) instead of panicking.
+func Adapt[In, Out any](t TypedTask[In, Out]) Task {
+	return typedTask[In, Out]{t: t}
+}
+
+type typedTask[In, Out any] struct {
+	t TypedTask[In, Out]
+}
+
+func (a typedTask[In, Out]) Process(data any) (any, error) {
+	return a.ProcessContext(context.Background(), data)
+}
+
+func (a typedTask[In, Out]) ProcessContext(ctx context.Context, data any) (any, error) {
+	in, ok := data.(In)
+	if !ok {
+		var zero In
+		return nil, fmt.Errorf("pipelines: unexpected input type %T, want %T", data, zero)
+	}
+	return a.t.Process(ctx, in)
+}
+
+// NewTypedNode creates a Node running a TypedTask.
+func NewTypedNode[In, Out any](name string, task TypedTask[In, Out], opts ...NodeOption) *Node {
+	return NewNode(name, Adapt(task), opts...)
+}
+
+// Results converts a pipeline output into a typed channel. Items that are not T are
+// skipped; the returned channel closes when out closes or done fires.
+func Results[T any](done <-chan struct{}, out <-chan any) <-chan T {
+	typed := make(chan T)
+	go func() {
+		defer close(typed)
+		for v := range out {
+			t, ok := v.(T)
+			if !ok {
+				continue
+			}
+			select {
+			case <-done:
+				return
+			case typed <- t:
+			}
+		}
+	}()
+	return typed
+}
@@ -1,5 +1,5 @@
 import time
-from typing import Optional, Dict, Any
+from typing import Optional, Dict, Any, List
 
 from artemis.core.clients.dept_clients import HTTPDeptServiceClient
 
@@ -11,8 +11,12 @@ class CronjobClient(HTTPDeptServiceClient):
     - Specialized for CronJob API:
         POST /api/v1/runs/{id}/progress
         POST /api/v1/runs/{id}/callback
+        POST /api/v1/runs/{id}/logs
+    - Echoes meta.callback_token in the X-Cronjob-Callback-Token header; CronJob rejects callbacks without it.
     """
 
+    CALLBACK_TOKEN_HEADER = 'X-Cronjob-Callback-Token'
+
     MAX_FINALIZE_ATTEMPTS = 3
 
     def __init__(
@@ -30,9 +34,15 @@ class CronjobClient(HTTPDeptServiceClient):
             return int(ctx_or_id.run_id)
         return int(ctx_or_id)
 
-    def _post_wrapper(self, path: str, payload: Dict[str, Any], run_id: int) -> bool:
+    def _get_token_headers(self, ctx_or_id: Any) -> Optional[Dict[str, str]]:
+        meta = getattr(ctx_or_id, 'task_meta', None)
+        token = getattr(meta, 'callback_token', None) if meta is not None else None
+        return {self.CALLBACK_TOKEN_HEADER: token} if token else None
+
+    def _post_wrapper(self, path: str, payload: Dict[str, Any], run_id: int,
+                      headers: Optional[Dict[str, str]] = None) -> bool:
         try:
-            resp = self.post(path, payload)
+            resp = self.post(path, payload, headers=headers)
             ok = 200 <= resp.status_code < 300
             if not ok and self.logger:
                 self.logger.warning({
@@ -57,28 +67,36 @@ class CronjobClient(HTTPDeptServiceClient):
         run_id = self._get_run_id(ctx)
         path = f"/api/v1/runs/{run_id}/progress"
         payload = {'current': current, 'total': total, 'message': message or ''}
-        ok = self._post_wrapper(path, payload, run_id)
+        ok = self._post_wrapper(path, payload, run_id, self._get_token_headers(ctx))
         if ok and self.logger:
             self.logger.info({'event': 'callback_progress_sent', 'run_id': run_id, 'current': current, 'total': total})
         return ok
 
+    def log(self, ctx: Any, lines: List[str] | str, level: str = 'INFO') -> bool:
+        """Append lines to the run log (streamed by CronJob on /runs/{id}/events). Best-effort, no retry."""
+        run_id = self._get_run_id(ctx)
+        path = f"/api/v1/runs/{run_id}/logs"
+        payload = {'level': level, 'lines': [lines] if isinstance(lines, str) else list(lines)}
+        return self._post_wrapper(path, payload, run_id, self._get_token_headers(ctx))
+
     def finalize_success(self, ctx: Any, code: int = 200, body: Optional[str] = None) -> bool:
         run_id = self._get_run_id(ctx)
         payload = {'result': 'success', 'code': code, 'body': body or 'success'}
-        return self._finalize_with_retry(run_id, payload)
+        return self._finalize_with_retry(run_id, payload, self._get_token_headers(ctx))
 
     def finalize_failed(self, ctx: Any, error_message: str) -> bool:
         run_id = self._get_run_id(ctx)
         payload = {'result': 'failed', 'error_message': error_message or 'failed'}
-        return self._finalize_with_retry(run_id, payload)
+        return self._finalize_with_retry(run_id, payload, self._get_token_headers(ctx))
 
-    def _finalize_with_retry(self, run_id: int, payload: Dict[str, Any]) -> bool:
+    def _finalize_with_retry(self, run_id: int, payload: Dict[str, Any],
+                             headers: Optional[Dict[str, str]] = None) -> bool:
         path = f"/api/v1/runs/{run_id}/callback"
         attempt = 0
         wait = 0.5
         while attempt < self.MAX_FINALIZE_ATTEMPTS:
             attempt += 1
-            if self._post_wrapper(path, payload, run_id):
+            if self._post_wrapper(path, payload, run_id, headers):
                 self._finalized_by_run[run_id] = True
                 if self.logger:
                     self.logger.info({'event': 'callback_finalize_sent', 'run_id': run_id, 'result': payload.get('success')})
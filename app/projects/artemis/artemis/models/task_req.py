@@ -20,6 +20,7 @@ class TaskMeta(BaseModel):
     exec_type: str = Field(..., description="SYNC|ASYNC")
     task_code: TaskCode | str | None = Field(None, description="Task code")
     callback_endpoints: CallbackEndpoints | None = None
+    callback_token: str | None = Field(None, description="Per-run token echoed back on progress/callback requests")
     @field_validator("exec_type")
     @classmethod
     def _normalize_exec_type(cls, v: str) -> str:
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+)
+
+const (
+	defaultServer         = "http://localhost:9999"
+	defaultRequestTimeout = 10 * time.Second
+)
+
+// client cronjob API 的 HTTP 客户端；每个请求带上操作人请求头，普通请求受 timeout 限制（0 不限），事件流不受限。
+type client struct {
+	base    string
+	user    string
+	timeout time.Duration
+	http    *http.Client
+}
+
+// apiError 服务端返回的错误（HTTP 状态码 >= 400），Message 取响应体中的 error 字段。
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.Status)
+}
+
+func newClient(server, user string, timeout time.Duration) (*client, error) {
+	u, err := url.Parse(server)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, usageErrorf("", "invalid server URL %q", server)
+	}
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &client{base: strings.TrimRight(server, "/"), user: user, timeout: timeout, http: &http.Client{}}, nil
+}
+
+// getJSON GET 并把响应解码到 out。
+func (c *client) getJSON(ctx context.Context, path string, q url.Values, out any) error {
+	return c.do(ctx, http.MethodGet, path, q, nil, "", out)
+}
+
+// sendJSON 以 JSON 请求体发送 method 请求，out 为 nil 时丢弃响应。
+func (c *client) sendJSON(ctx context.Context, method, path string, q url.Values, body, out any) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	return c.do(ctx, method, path, q, r, "application/json", out)
+}
+
+// do 发送请求；out 为 *[]byte 时返回原始响应体，否则按 JSON 解码。
+func (c *client) do(ctx context.Context, method, path string, q url.Values, body io.Reader, contentType string, out any) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	resp, err := c.send(ctx, method, path, q, body, contentType, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	switch o := out.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*o = data
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", method, path, err)
+	}
+	return nil
+}
+
+// send 发送请求并校验状态码；成功时由调用方关闭响应体。
+func (c *client) send(ctx context.Context, method, path string, q url.Values, body io.Reader, contentType string, header http.Header) (*http.Response, error) {
+	u := c.base + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	if contentType != "" && body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.user != "" {
+		req.Header.Set(bizConsts.ActorHeader, c.user)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, decodeAPIError(resp)
+	}
+	return resp, nil
+}
+
+func decodeAPIError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var body struct {
+		Error string `json:"error"`
+	}
+	msg := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		msg = body.Error
+	}
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+	return &apiError{Status: resp.StatusCode, Message: msg}
+}
+
+// connect 按 --server / 环境变量 / 配置文件上下文确定服务端与操作人，创建客户端。
+func (c *cli) connect() (*client, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	cfg, err := loadConfig(c.configPath())
+	if err != nil {
+		return nil, err
+	}
+	name := firstNonEmpty(c.g.context, cfg.CurrentContext)
+	var cur ctlContext
+	if name != "" {
+		p, ok := cfg.Contexts[name]
+		if !ok {
+			return nil, usageErrorf("", "context %q not found in %s (see cronctl config get-contexts)", name, c.configPath())
+		}
+		cur = *p
+	}
+	timeout := c.g.requestTimeout
+	if timeout <= 0 {
+		timeout = cur.Timeout
+	}
+	api, err := newClient(
+		firstNonEmpty(c.g.server, os.Getenv("CRONCTL_SERVER"), cur.Server, defaultServer),
+		firstNonEmpty(c.g.user, os.Getenv("CRONCTL_USER"), cur.User),
+		timeout)
+	if err != nil {
+		return nil, err
+	}
+	c.api = api
+	return api, nil
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 本地配置文件保存多个服务端上下文，例如：
+//
+//	current_context: prod
+//	contexts:
+//	  prod:
+//	    server: http://cronjob.prod:9999
+//	    user: ops@example.com
+//	  dev:
+//	    server: http://localhost:9999
+//	    timeout: 30s
+
+// ctlConfig 本地配置文件。
+type ctlConfig struct {
+	CurrentContext string                 `yaml:"current_context"`
+	Contexts       map[string]*ctlContext `yaml:"contexts"`
+}
+
+// ctlContext 一个服务端上下文。
+type ctlContext struct {
+	Server  string        `yaml:"server"`
+	User    string        `yaml:"user,omitempty"`    // 请求头 X-Cronjob-User
+	Timeout time.Duration `yaml:"timeout,omitempty"` // 单个请求的超时，默认 10s
+}
+
+// configPath --config > CRONCTL_CONFIG > ~/.cronctl.yaml
+func (c *cli) configPath() string {
+	if p := firstNonEmpty(c.g.config, os.Getenv("CRONCTL_CONFIG")); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".cronctl.yaml"
+	}
+	return filepath.Join(home, ".cronctl.yaml")
+}
+
+// loadConfig 读取配置文件；文件不存在时返回空配置。
+func loadConfig(path string) (*ctlConfig, error) {
+	cfg := &ctlConfig{Contexts: map[string]*ctlContext{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if cfg.Contexts == nil {
+		cfg.Contexts = map[string]*ctlContext{}
+	}
+	return cfg, nil
+}
+
+// saveConfig 写回配置文件（仅本人可读写）。
+func saveConfig(path string, cfg *ctlConfig) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func (c *cli) configGetContexts(args []string) error {
+	fs := c.flagSet("config get-contexts")
+	args, err := c.parse(fs, args, "config get-contexts")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 0, 0, ""); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(c.configPath())
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(cfg.Contexts))
+	for n := range cfg.Contexts {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	type item struct {
+		Name    string `json:"name"`
+		Current bool   `json:"current"`
+		Server  string `json:"server"`
+		User    string `json:"user"`
+		Timeout string `json:"timeout,omitempty"`
+	}
+	items := make([]item, 0, len(names))
+	tbl := &table{header: []string{"CURRENT", "NAME", "SERVER", "USER"}}
+	for _, n := range names {
+		ctx := cfg.Contexts[n]
+		it := item{Name: n, Current: n == cfg.CurrentContext, Server: ctx.Server, User: ctx.User}
+		if ctx.Timeout > 0 {
+			it.Timeout = ctx.Timeout.String()
+		}
+		items = append(items, it)
+		mark := ""
+		if it.Current {
+			mark = "*"
+		}
+		tbl.add(mark, n, ctx.Server, ctx.User)
+	}
+	return c.render(items, tbl)
+}
+
+func (c *cli) configCurrentContext(args []string) error {
+	fs := c.flagSet("config current-context")
+	args, err := c.parse(fs, args, "config current-context")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 0, 0, ""); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(c.configPath())
+	if err != nil {
+		return err
+	}
+	if cfg.CurrentContext == "" {
+		return &exitErr{code: exitNotFound, msg: "current context is not set"}
+	}
+	fmt.Fprintln(c.stdout, cfg.CurrentContext)
+	return nil
+}
+
+func (c *cli) configUseContext(args []string) error {
+	fs := c.flagSet("config use-context")
+	args, err := c.parse(fs, args, "config use-context NAME")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 1, 1, "context name"); err != nil {
+		return err
+	}
+	path := c.configPath()
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Contexts[args[0]]; !ok {
+		return &exitErr{code: exitNotFound, msg: fmt.Sprintf("context %q not found in %s", args[0], path)}
+	}
+	cfg.CurrentContext = args[0]
+	if err := saveConfig(path, cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "switched to context %q\n", args[0])
+	return nil
+}
+
+// configSetContext 以全局参数 --server / --user / --request-timeout 创建或更新上下文，未给出的字段保留原值。
+func (c *cli) configSetContext(args []string) error {
+	fs := c.flagSet("config set-context")
+	use := fs.Bool("use", false, "also make it the current context")
+	args, err := c.parse(fs, args, "config set-context NAME [--server URL] [--user NAME] [--request-timeout DUR] [--use]")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 1, 1, "context name"); err != nil {
+		return err
+	}
+	name := args[0]
+	path := c.configPath()
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	ctx, exists := cfg.Contexts[name]
+	if !exists {
+		ctx = &ctlContext{}
+		cfg.Contexts[name] = ctx
+	}
+	if c.g.server != "" {
+		if _, err := newClient(c.g.server, "", 0); err != nil {
+			return err
+		}
+		ctx.Server = c.g.server
+	}
+	if c.g.user != "" {
+		ctx.User = c.g.user
+	}
+	if c.g.requestTimeout > 0 {
+		ctx.Timeout = c.g.requestTimeout
+	}
+	if ctx.Server == "" {
+		return usageErrorf(fs.Name(), "--server is required for a new context")
+	}
+	if *use || cfg.CurrentContext == "" {
+		cfg.CurrentContext = name
+	}
+	if err := saveConfig(path, cfg); err != nil {
+		return err
+	}
+	verb := "created"
+	if exists {
+		verb = "updated"
+	}
+	fmt.Fprintf(c.stdout, "context %q %s\n", name, verb)
+	return nil
+}
+
+func (c *cli) configDeleteContext(args []string) error {
+	fs := c.flagSet("config delete-context")
+	args, err := c.parse(fs, args, "config delete-context NAME")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 1, 1, "context name"); err != nil {
+		return err
+	}
+	path := c.configPath()
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Contexts[args[0]]; !ok {
+		return &exitErr{code: exitNotFound, msg: fmt.Sprintf("context %q not found in %s", args[0], path)}
+	}
+	delete(cfg.Contexts, args[0])
+	if cfg.CurrentContext == args[0] {
+		cfg.CurrentContext = ""
+	}
+	if err := saveConfig(path, cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "context %q deleted\n", args[0])
+	return nil
+}
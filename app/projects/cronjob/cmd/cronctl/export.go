@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// importResult POST /tasks/import 的响应。
+type importResult struct {
+	SuccessCount int `json:"success_count"`
+	FailedCount  int `json:"failed_count"`
+	FailedTasks  []struct {
+		Name  string `json:"name"`
+		Error string `json:"error"`
+	} `json:"failed_tasks"`
+}
+
+// export 导出任务配置（GET /tasks/export）；默认输出 JSON，-o yaml 时转为 YAML，import 两种格式都接受。
+func (c *cli) export(args []string) error {
+	fs := c.flagSet("export")
+	file := fs.String("f", "", "write to FILE instead of stdout")
+	task := fs.String("task", "", "export a single task (id or name)")
+	namespace := fs.String("namespace", "", "only tasks in this namespace")
+	owner := fs.String("owner", "", "only tasks owned by this user or group")
+	selector := fs.String("selector", "", "label selector, e.g. team=data,tier!=dev")
+	fs.StringVar(selector, "l", "", "shorthand for --selector")
+	args, err := c.parse(fs, args, "export [--task TASK | --namespace NS --owner O -l SELECTOR] [-f FILE]")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 0, 0, ""); err != nil {
+		return err
+	}
+	q := url.Values{}
+	if *task != "" {
+		t, err := c.resolveTask(*task)
+		if err != nil {
+			return err
+		}
+		q.Set("id", strconv.FormatInt(t.ID, 10))
+	}
+	for k, v := range map[string]string{"namespace": *namespace, "owner": *owner, "selector": *selector} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	api, err := c.connect()
+	if err != nil {
+		return err
+	}
+	var data []byte
+	if err := api.getJSON(c.ctx, "/api/v1/tasks/export", q, &data); err != nil {
+		return err
+	}
+	if c.g.output == "yaml" {
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("decode export: %w", err)
+		}
+		var buf bytes.Buffer
+		if err := writeYAMLTo(&buf, v); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	} else {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, bytes.TrimSpace(data), "", "  "); err != nil {
+			return fmt.Errorf("decode export: %w", err)
+		}
+		buf.WriteByte('\n')
+		data = buf.Bytes()
+	}
+	if *file == "" || *file == "-" {
+		_, err := c.stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*file, data, 0o644); err != nil {
+		return err
+	}
+	var n struct {
+		Tasks []any `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(data, &n); err == nil {
+		fmt.Fprintf(c.stderr, "exported %d task(s) to %s\n", len(n.Tasks), *file)
+	}
+	return nil
+}
+
+// importTasks 导入 export 的结果（JSON 或 YAML）；有任务导入失败时退出码为 4。
+func (c *cli) importTasks(args []string) error {
+	fs := c.flagSet("import")
+	args, err := c.parse(fs, args, "import FILE")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 1, 1, "file (- for stdin)"); err != nil {
+		return err
+	}
+	data, err := readInput(args[0])
+	if err != nil {
+		return err
+	}
+	if !json.Valid(data) {
+		// 服务端只接受 JSON，YAML 在本地转换
+		var v any
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return usageErrorf(fs.Name(), "%s is neither JSON nor YAML: %v", args[0], err)
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return fmt.Errorf("convert %s to JSON: %w", args[0], err)
+		}
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", "tasks.json")
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(data); err != nil {
+		return err
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+	api, err := c.connect()
+	if err != nil {
+		return err
+	}
+	var res importResult
+	if err := api.do(c.ctx, http.MethodPost, "/api/v1/tasks/import", nil, &body, mw.FormDataContentType(), &res); err != nil {
+		return err
+	}
+	tbl := &table{header: []string{"FAILED TASK", "ERROR"}}
+	for _, f := range res.FailedTasks {
+		tbl.add(f.Name, f.Error)
+	}
+	if c.g.output == "" || c.g.output == "table" {
+		fmt.Fprintf(c.stdout, "imported %d task(s), %d failed\n", res.SuccessCount, res.FailedCount)
+		if len(tbl.rows) > 0 {
+			if err := tbl.write(c.stdout); err != nil {
+				return err
+			}
+		}
+	} else if err := c.render(&res, nil); err != nil {
+		return err
+	}
+	if res.FailedCount > 0 {
+		return &exitErr{code: exitRejected, msg: fmt.Sprintf("%d task(s) failed to import", res.FailedCount)}
+	}
+	return nil
+}
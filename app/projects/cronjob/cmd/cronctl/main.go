@@ -0,0 +1,305 @@
+// cronctl 是 cronjob HTTP API（/api/v1/tasks、/api/v1/runs）的命令行客户端：
+// 查看与编辑任务、手动触发并跟踪 Run、取消 Run、导入导出任务与预览调度时间。
+//
+// 输出格式由 -o table|json|yaml 指定；服务端地址与操作人取自 --server / --user、
+// 环境变量 CRONCTL_SERVER / CRONCTL_USER，或本地配置文件（默认 ~/.cronctl.yaml）中的上下文。
+//
+// 退出码（便于脚本判断）：
+//
+//	0 成功
+//	1 请求失败（网络错误、服务端 5xx 等）
+//	2 用法错误（未知命令、参数缺失或非法）
+//	3 任务或 Run 不存在（404）
+//	4 请求被拒绝（400 / 403 / 409，如托管任务、并发上限），或导入有失败项
+//	5 等待的 Run 以非 SUCCESS 状态结束
+//	6 等待 Run 结束超时
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	exitOK        = 0
+	exitError     = 1
+	exitUsage     = 2
+	exitNotFound  = 3
+	exitRejected  = 4
+	exitRunFailed = 5
+	exitTimeout   = 6
+)
+
+const usage = `cronctl - command-line client for the cronjob API
+
+Usage:
+  cronctl [global flags] <command> [flags] [args]
+
+Tasks:
+  tasks list                      list tasks (--status, --name, --namespace, --owner, -l selector, --all)
+  tasks get TASK                  show a task
+  tasks create -f FILE            create tasks from a YAML/JSON spec file (multi-document, "-" for stdin)
+  tasks edit TASK [-f FILE]       edit a task in $EDITOR (or apply a spec file)
+  tasks enable TASK...            enable tasks
+  tasks disable TASK...           disable tasks
+  tasks schedule TASK [-n N]      preview the next fire times
+
+Runs:
+  trigger TASK [--wait]           trigger a task; --wait streams status, progress and logs until it ends
+  runs list [TASK]                list runs of a task, or active runs
+  runs get RUN                    show a run
+  runs watch RUN                  stream an existing run until it ends
+  runs tail [TASK]                follow new runs and status changes (Ctrl-C to stop)
+  cancel RUN... [--wait]          cancel runs
+
+Import / export:
+  export [-f FILE]                export tasks (--namespace, --owner, -l selector, --task)
+  import FILE                     import tasks from an export file (JSON or YAML)
+
+Config:
+  config get-contexts             list server contexts
+  config current-context          print the current context
+  config use-context NAME         switch the current context
+  config set-context NAME         create or update a context from --server / --user / --request-timeout
+  config delete-context NAME      remove a context
+
+TASK is a task id or an exact task name; RUN is a run id.
+
+Global flags:
+  --context NAME          context from the config file (default: current_context)
+  --server URL            API base URL (env CRONCTL_SERVER, default ` + defaultServer + `)
+  --user NAME             operator sent as ` + "`X-Cronjob-User`" + ` (env CRONCTL_USER)
+  --config PATH           config file (env CRONCTL_CONFIG, default ~/.cronctl.yaml)
+  --request-timeout DUR   timeout of a single API request (default ` + "10s" + `)
+  -o, --output FORMAT     table|json|yaml (default table)
+
+Exit codes: 0 ok, 1 error, 2 usage, 3 not found, 4 rejected, 5 run failed, 6 wait timed out.
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run 执行一条命令并返回退出码；测试直接调用。
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	c := &cli{ctx: ctx, stdout: stdout, stderr: stderr}
+	err := c.dispatch(args)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	}
+	fmt.Fprintf(stderr, "error: %v\n", err)
+	var ue *usageError
+	if errors.As(err, &ue) && ue.hint != "" {
+		fmt.Fprintf(stderr, "run 'cronctl %s -h' for usage\n", ue.hint)
+	}
+	return exitCode(err)
+}
+
+// globals 全局参数，注册在根命令与每个子命令上，可写在命令前后任意位置。
+type globals struct {
+	context        string
+	server         string
+	user           string
+	config         string
+	output         string
+	requestTimeout time.Duration
+}
+
+func (g *globals) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.context, "context", g.context, "context from the config file")
+	fs.StringVar(&g.server, "server", g.server, "API base URL")
+	fs.StringVar(&g.user, "user", g.user, "operator sent as X-Cronjob-User")
+	fs.StringVar(&g.config, "config", g.config, "config file")
+	fs.StringVar(&g.output, "o", g.output, "output format: table|json|yaml")
+	fs.StringVar(&g.output, "output", g.output, "output format: table|json|yaml")
+	fs.DurationVar(&g.requestTimeout, "request-timeout", g.requestTimeout, "timeout of a single API request")
+}
+
+// cli 一次命令执行的上下文。
+type cli struct {
+	ctx    context.Context
+	stdout io.Writer
+	stderr io.Writer
+	g      globals
+	api    *client // 首次请求前由 connect 创建
+
+	taskNames map[int64]string // Run 表格中任务名的缓存
+}
+
+// command 子命令；group 非空时为命令组（如 tasks），按下一个参数分派。
+type command struct {
+	run   func(c *cli, args []string) error
+	group map[string]command
+}
+
+var commands = map[string]command{
+	"tasks": {group: map[string]command{
+		"list":     {run: (*cli).tasksList},
+		"ls":       {run: (*cli).tasksList},
+		"get":      {run: (*cli).tasksGet},
+		"create":   {run: (*cli).tasksCreate},
+		"edit":     {run: (*cli).tasksEdit},
+		"enable":   {run: func(c *cli, args []string) error { return c.tasksSetStatus(args, true) }},
+		"disable":  {run: func(c *cli, args []string) error { return c.tasksSetStatus(args, false) }},
+		"schedule": {run: (*cli).tasksSchedule},
+	}},
+	"trigger": {run: (*cli).trigger},
+	"runs": {group: map[string]command{
+		"list":  {run: (*cli).runsList},
+		"ls":    {run: (*cli).runsList},
+		"get":   {run: (*cli).runsGet},
+		"watch": {run: (*cli).runsWatch},
+		"tail":  {run: (*cli).runsTail},
+	}},
+	"cancel": {run: (*cli).cancel},
+	"export": {run: (*cli).export},
+	"import": {run: (*cli).importTasks},
+	"config": {group: map[string]command{
+		"get-contexts":    {run: (*cli).configGetContexts},
+		"current-context": {run: (*cli).configCurrentContext},
+		"use-context":     {run: (*cli).configUseContext},
+		"set-context":     {run: (*cli).configSetContext},
+		"delete-context":  {run: (*cli).configDeleteContext},
+	}},
+}
+
+func (c *cli) dispatch(args []string) error {
+	fs := c.flagSet("cronctl")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprint(c.stdout, usage)
+			return err
+		}
+		return usageErrorf("", "%v", err)
+	}
+	args = fs.Args()
+	if len(args) == 0 || args[0] == "help" {
+		fmt.Fprint(c.stdout, usage)
+		if len(args) == 0 {
+			return usageErrorf("", "no command given")
+		}
+		return nil
+	}
+	path := args[0]
+	cmd, ok := commands[path]
+	if !ok {
+		return usageErrorf("", "unknown command %q", path)
+	}
+	args = args[1:]
+	if cmd.group != nil {
+		if len(args) == 0 {
+			return usageErrorf(path, "%s requires a subcommand", path)
+		}
+		sub, ok := cmd.group[args[0]]
+		if !ok {
+			return usageErrorf(path, "unknown command %q", path+" "+args[0])
+		}
+		cmd, args = sub, args[1:]
+	}
+	return cmd.run(c, args)
+}
+
+// flagSet 新建注册了全局参数的 FlagSet；解析错误由调用方统一输出。
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	c.g.register(fs)
+	return fs
+}
+
+// parse 解析子命令参数，flag 与位置参数可交错出现（如 tasks get 12 -o json）；返回位置参数。
+// -h 时输出该命令的参数说明并返回 flag.ErrHelp。
+func (c *cli) parse(fs *flag.FlagSet, args []string, synopsis string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				fmt.Fprintf(c.stdout, "Usage: cronctl %s\n\nFlags:\n", synopsis)
+				fs.SetOutput(c.stdout)
+				fs.PrintDefaults()
+				return nil, err
+			}
+			return nil, usageErrorf(fs.Name(), "%v", err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+	switch c.g.output {
+	case "", "table", "json", "yaml":
+	default:
+		return nil, usageErrorf(fs.Name(), "unknown output format %q (table|json|yaml)", c.g.output)
+	}
+	return pos, nil
+}
+
+// usageError 参数或命令错误（退出码 2），hint 为提示查看帮助的命令。
+type usageError struct {
+	hint string
+	msg  string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+func usageErrorf(hint, format string, a ...any) error {
+	return &usageError{hint: hint, msg: fmt.Sprintf(format, a...)}
+}
+
+// exitErr 携带指定退出码的错误（如 Run 失败、等待超时）。
+type exitErr struct {
+	code int
+	msg  string
+}
+
+func (e *exitErr) Error() string { return e.msg }
+
+// exitCode 错误对应的退出码。
+func exitCode(err error) int {
+	var (
+		ue *usageError
+		ee *exitErr
+		ae *apiError
+	)
+	switch {
+	case errors.As(err, &ue):
+		return exitUsage
+	case errors.As(err, &ee):
+		return ee.code
+	case errors.As(err, &ae):
+		switch {
+		case ae.Status == 404:
+			return exitNotFound
+		case ae.Status >= 400 && ae.Status < 500:
+			return exitRejected
+		}
+	}
+	return exitError
+}
+
+// needArgs 校验位置参数个数（max < 0 表示不限）。
+func needArgs(fs *flag.FlagSet, args []string, min, max int, what string) error {
+	if len(args) < min {
+		return usageErrorf(fs.Name(), "%s required", what)
+	}
+	if max >= 0 && len(args) > max {
+		return usageErrorf(fs.Name(), "unexpected arguments: %s", strings.Join(args[max:], " "))
+	}
+	return nil
+}
@@ -0,0 +1,665 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/http_server"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/api"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/service"
+)
+
+// memTaskDao 内存中的任务表。
+type memTaskDao struct {
+	dao.TaskDao
+	mu    sync.Mutex
+	tasks map[int64]*model.Task
+	deps  map[int64][]int64
+	next  int64
+}
+
+func (d *memTaskDao) Create(_ context.Context, t *model.Task) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.next++
+	t.ID, t.CreatedAt, t.UpdatedAt = d.next, time.Now(), time.Now()
+	cp := *t
+	d.tasks[t.ID] = &cp
+	return nil
+}
+func (d *memTaskDao) Get(_ context.Context, id int64) (*model.Task, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, ok := d.tasks[id]
+	if !ok || t.Deleted == 1 {
+		return nil, fmt.Errorf("task %d not found", id)
+	}
+	cp := *t
+	return &cp, nil
+}
+func (d *memTaskDao) ListEnabled(ctx context.Context) ([]*model.Task, error) {
+	return d.ListFiltered(ctx, &model.TaskListFilters{Status: string(bizConsts.ENABLED)}, 0, 0)
+}
+func (d *memTaskDao) UpdateCronAndMeta(_ context.Context, t *model.Task) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t.Version++
+	cp := *t
+	d.tasks[t.ID] = &cp
+	return nil
+}
+func (d *memTaskDao) UpdateStatus(_ context.Context, id int64, status bizConsts.TaskStatus) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if t := d.tasks[id]; t != nil {
+		t.Status = status
+		t.Version++
+	}
+	return nil
+}
+func (d *memTaskDao) SoftDelete(_ context.Context, id int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if t := d.tasks[id]; t != nil {
+		t.Deleted = 1
+		t.Version++
+	}
+	// 与 TaskDaoImpl.SoftDelete 一致：移除该任务作为上下游的依赖边
+	delete(d.deps, id)
+	for tid, ups := range d.deps {
+		kept := ups[:0:0]
+		for _, u := range ups {
+			if u != id {
+				kept = append(kept, u)
+			}
+		}
+		d.deps[tid] = kept
+	}
+	return nil
+}
+func (d *memTaskDao) ExistsByName(_ context.Context, name string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, t := range d.tasks {
+		if t.Name == name && t.Deleted == 0 {
+			return true
+		}
+	}
+	return false
+}
+func (d *memTaskDao) ReactivateByName(context.Context, string, *model.Task) (int64, bool, error) {
+	return 0, false, nil
+}
+func (d *memTaskDao) ListFiltered(_ context.Context, f *model.TaskListFilters, limit, offset int) ([]*model.Task, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var out []*model.Task
+	for _, t := range d.tasks {
+		if t.Deleted == 1 || (f != nil && f.Status != "" && string(t.Status) != f.Status) ||
+			(f != nil && f.NameLike != "" && !strings.Contains(t.Name, f.NameLike)) {
+			continue
+		}
+		cp := *t
+		out = append(out, &cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if offset > len(out) {
+		offset = len(out)
+	}
+	out = out[offset:]
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+func (d *memTaskDao) CountFiltered(ctx context.Context, f *model.TaskListFilters) (int64, error) {
+	list, err := d.ListFiltered(ctx, f, 0, 0)
+	return int64(len(list)), err
+}
+func (d *memTaskDao) ListDependencies(context.Context) ([]*model.TaskDependency, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var out []*model.TaskDependency
+	for id, ups := range d.deps {
+		for _, u := range ups {
+			out = append(out, &model.TaskDependency{TaskID: id, UpstreamTaskID: u})
+		}
+	}
+	return out, nil
+}
+func (d *memTaskDao) ReplaceUpstreams(_ context.Context, id int64, ups []int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.deps[id] = ups
+	return nil
+}
+
+// memRunDao 内存中的 Run 与日志；onCreate 在 Run 创建后调用，用于模拟执行。
+type memRunDao struct {
+	dao.RunDao
+	mu       sync.Mutex
+	runs     map[int64]*model.TaskRun
+	logs     []*model.RunLog
+	next     int64
+	onCreate func(run *model.TaskRun)
+}
+
+func (d *memRunDao) CreateScheduled(_ context.Context, run *model.TaskRun) error {
+	d.mu.Lock()
+	d.next++
+	run.ID = d.next
+	cp := *run
+	d.runs[run.ID] = &cp
+	onCreate := d.onCreate
+	d.mu.Unlock()
+	if onCreate != nil {
+		onCreate(&cp)
+	}
+	return nil
+}
+func (d *memRunDao) setOnCreate(f func(run *model.TaskRun)) {
+	d.mu.Lock()
+	d.onCreate = f
+	d.mu.Unlock()
+}
+func (d *memRunDao) Get(_ context.Context, id int64) (*model.TaskRun, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r, ok := d.runs[id]
+	if !ok {
+		return nil, fmt.Errorf("run %d not found", id)
+	}
+	cp := *r
+	return &cp, nil
+}
+func (d *memRunDao) end(id int64, status bizConsts.RunStatus, update func(r *model.TaskRun)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if r := d.runs[id]; r != nil && !r.Status.Finished() {
+		now := time.Now()
+		if r.StartTime == nil {
+			r.StartTime = &now
+		}
+		r.Status, r.EndTime = status, &now
+		if update != nil {
+			update(r)
+		}
+	}
+}
+func (d *memRunDao) MarkSuccess(_ context.Context, id int64, code int, _ string) error {
+	d.end(id, bizConsts.Success, func(r *model.TaskRun) { r.ResponseCode = &code })
+	return nil
+}
+func (d *memRunDao) MarkFailed(_ context.Context, id int64, msg string) error {
+	d.end(id, bizConsts.Failed, func(r *model.TaskRun) { r.ErrorMessage = msg })
+	return nil
+}
+func (d *memRunDao) MarkCanceled(_ context.Context, id int64, reason bizConsts.CancelReason) error {
+	d.end(id, bizConsts.Canceled, func(r *model.TaskRun) { r.CancelReason = reason })
+	return nil
+}
+func (d *memRunDao) UpdateProgress(_ context.Context, id int64, current, total int64, msg string, at time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if r := d.runs[id]; r != nil {
+		r.ProgressCurrent, r.ProgressTotal, r.ProgressMessage, r.ProgressUpdatedAt = current, total, msg, &at
+	}
+	return nil
+}
+func (d *memRunDao) AppendLogs(_ context.Context, logs []*model.RunLog) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, l := range logs {
+		l.ID = int64(len(d.logs) + 1)
+		d.logs = append(d.logs, l)
+	}
+	return nil
+}
+func (d *memRunDao) ListLogs(_ context.Context, runID, afterID int64, limit int) ([]*model.RunLog, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var out []*model.RunLog
+	for _, l := range d.logs {
+		if l.TaskRunID == runID && l.ID > afterID && len(out) < limit {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+func (d *memRunDao) list(keep func(r *model.TaskRun) bool, limit int) []*model.TaskRun {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var out []*model.TaskRun
+	for _, r := range d.runs {
+		if keep(r) {
+			cp := *r
+			out = append(out, &cp)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+func (d *memRunDao) ListByTaskFiltered(_ context.Context, taskID int64, statuses []bizConsts.RunStatus, _, _ *time.Time, limit, _ int, _ string) ([]*model.TaskRun, error) {
+	return d.list(func(r *model.TaskRun) bool {
+		if r.TaskID != taskID {
+			return false
+		}
+		if len(statuses) == 0 {
+			return true
+		}
+		for _, s := range statuses {
+			if r.Status == s {
+				return true
+			}
+		}
+		return false
+	}, limit), nil
+}
+func (d *memRunDao) ListActiveFiltered(_ context.Context, _ []bizConsts.RunStatus, _, _ *time.Time, limit, _ int, _ string) ([]*model.TaskRun, error) {
+	return d.list(func(r *model.TaskRun) bool { return !r.Status.Finished() }, limit), nil
+}
+
+// memNamespaceDao 只有 default 空间，不限任务数。
+type memNamespaceDao struct{ dao.NamespaceDao }
+
+func (memNamespaceDao) List(context.Context) ([]*model.Namespace, error) {
+	return []*model.Namespace{{Name: bizConsts.DEFAULT_NAMESPACE}}, nil
+}
+
+// testEnv 以真实路由与控制器、内存 DAO 启动的 cronjob API。
+type testEnv struct {
+	t      *testing.T
+	url    string
+	config string
+	tasks  *memTaskDao
+	runs   *memRunDao
+	runSvc *service.RunService
+}
+
+func newTestEnv(t *testing.T, middlewares ...func(http.Handler) http.Handler) *testEnv {
+	t.Helper()
+	for _, k := range []string{"CRONCTL_SERVER", "CRONCTL_USER", "CRONCTL_CONFIG"} {
+		t.Setenv(k, "")
+	}
+	ctx := context.Background()
+	tasks := &memTaskDao{tasks: map[int64]*model.Task{}, deps: map[int64][]int64{}}
+	runs := &memRunDao{runs: map[int64]*model.TaskRun{}}
+
+	taskSvc := service.NewTaskService()
+	taskSvc.TaskDao = tasks
+	if err := taskSvc.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	runSvc := service.NewRunService()
+	runSvc.RunDao = runs
+	nsSvc := service.NewNamespaceService(config.NamespaceConfig{})
+	nsSvc.NamespaceDao = memNamespaceDao{}
+	exec := service.NewExecutor(config.ExecutorConfig{}) // 不启动：Run 由测试模拟执行
+	exec.TaskSvc, exec.RunSvc = taskSvc, runSvc
+
+	taskCtrl := api.NewTaskMgmtController()
+	taskCtrl.TaskSvc, taskCtrl.RunSvc, taskCtrl.Exec, taskCtrl.Namespaces = taskSvc, runSvc, exec, nsSvc
+	runCtrl := api.NewRunMgmtController()
+	runCtrl.RunSvc, runCtrl.Exec, runCtrl.TaskSvc, runCtrl.Namespaces = runSvc, exec, taskSvc, nsSvc
+
+	c := core.NewContainer()
+	for name, comp := range map[string]core.Component{
+		bizConsts.COMP_CTRL_TASK_MGMT: taskCtrl,
+		bizConsts.COMP_CTRL_RUN_MGMT:  runCtrl,
+		bizConsts.COMP_CTRL_META_MGMT: api.NewMetaController(),
+		bizConsts.COMP_CTRL_BACKFILL:  api.NewBackfillController(),
+		bizConsts.COMP_CTRL_CALENDAR:  api.NewCalendarController(),
+		bizConsts.COMP_CTRL_ALERT:     api.NewAlertController(),
+		bizConsts.COMP_CTRL_ANALYTICS: api.NewAnalyticsController(),
+		bizConsts.COMP_CTRL_NAMESPACE: api.NewNamespaceController(),
+	} {
+		if err := c.Register(name, comp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := chi.NewRouter()
+	r.Use(middlewares...)
+	if err := api.RegisterRoutes(r, c); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return &testEnv{t: t, url: srv.URL, config: filepath.Join(t.TempDir(), "cronctl.yaml"),
+		tasks: tasks, runs: runs, runSvc: runSvc}
+}
+
+// exec 以测试配置文件执行命令，返回退出码与输出。
+func (e *testEnv) exec(ctx context.Context, args ...string) (int, string, string) {
+	e.t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(ctx, append([]string{"--config", e.config}, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// run 指向测试服务端执行命令。
+func (e *testEnv) run(args ...string) (int, string, string) {
+	e.t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return e.exec(ctx, append([]string{"--server", e.url}, args...)...)
+}
+
+// mustRun 执行命令并要求退出码为 want。
+func (e *testEnv) mustRun(want int, args ...string) string {
+	e.t.Helper()
+	code, stdout, stderr := e.run(args...)
+	if code != want {
+		e.t.Fatalf("cronctl %s: exit %d, want %d\nstdout: %s\nstderr: %s", strings.Join(args, " "), code, want, stdout, stderr)
+	}
+	return stdout
+}
+
+func (e *testEnv) seedTask(name string, mutate func(t *model.Task)) *model.Task {
+	e.t.Helper()
+	t := &model.Task{Name: name, Namespace: bizConsts.DEFAULT_NAMESPACE, CronExpr: "0 0 2 * * *", Timezone: "UTC",
+		ExecType: bizConsts.ExecTypeSync, Executor: bizConsts.ExecutorHTTP, ExecutorConfig: bizConsts.DEFAULT_JSON_STR,
+		HTTPMethod: "POST", TargetService: "artemis", TargetPath: "/jobs/" + name, HeadersJSON: bizConsts.DEFAULT_JSON_STR,
+		RetryPolicyJSON: bizConsts.DEFAULT_JSON_STR, MaxConcurrency: 1, Status: bizConsts.DISABLED, Version: 1}
+	if mutate != nil {
+		mutate(t)
+	}
+	if err := e.tasks.Create(context.Background(), t); err != nil {
+		e.t.Fatal(err)
+	}
+	return t
+}
+
+func (e *testEnv) writeFile(name, content string) string {
+	e.t.Helper()
+	path := filepath.Join(e.t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		e.t.Fatal(err)
+	}
+	return path
+}
+
+func TestTasksCommands(t *testing.T) {
+	e := newTestEnv(t)
+	spec := e.writeFile("tasks.yaml", `name: daily-report
+cron_expr: "0 0 2 * * *"
+timezone: Asia/Shanghai
+method: POST
+target_service: artemis
+target_path: /reports/daily
+status: ENABLED
+---
+name: daily-report-mail
+cron_expr: "0 30 2 * * *"
+schedule_mode: BOTH
+target_service: artemis
+target_path: /reports/mail
+status: DISABLED
+upstream_tasks: [daily-report]
+`)
+	out := e.mustRun(exitOK, "tasks", "create", "-f", spec)
+	if !strings.Contains(out, "daily-report ") || !strings.Contains(out, "ENABLED") || !strings.Contains(out, "daily-report-mail") {
+		t.Fatalf("create output:\n%s", out)
+	}
+
+	var list []model.Task
+	if err := json.Unmarshal([]byte(e.mustRun(exitOK, "tasks", "list", "-o", "json")), &list); err != nil || len(list) != 2 {
+		t.Fatalf("list: %v %+v", err, list)
+	}
+	if list[0].Status != bizConsts.ENABLED || list[1].Status != bizConsts.DISABLED {
+		t.Fatalf("statuses after create: %s %s", list[0].Status, list[1].Status)
+	}
+	if out := e.mustRun(exitOK, "tasks", "get", "daily-report-mail", "-o", "yaml"); !strings.Contains(out, "upstream_task_ids:") || !strings.Contains(out, "- 1\n") {
+		t.Fatalf("get yaml:\n%s", out)
+	}
+	if out := e.mustRun(exitOK, "tasks", "get", "1"); !strings.Contains(out, "Asia/Shanghai") {
+		t.Fatalf("get table:\n%s", out)
+	}
+
+	e.mustRun(exitOK, "tasks", "disable", "daily-report")
+	if task, _ := e.tasks.Get(context.Background(), 1); task.Status != bizConsts.DISABLED {
+		t.Fatalf("status after disable: %s", task.Status)
+	}
+	// 部分失败时其余任务照常处理，退出码取失败原因
+	e.mustRun(exitNotFound, "tasks", "enable", "daily-report", "no-such-task")
+	if task, _ := e.tasks.Get(context.Background(), 1); task.Status != bizConsts.ENABLED {
+		t.Fatalf("status after enable: %s", task.Status)
+	}
+
+	var preview schedulePreview
+	if err := json.Unmarshal([]byte(e.mustRun(exitOK, "tasks", "schedule", "daily-report", "-n", "3", "-o", "json")), &preview); err != nil {
+		t.Fatal(err)
+	}
+	if len(preview.Next) != 3 || preview.Timezone != "Asia/Shanghai" || !strings.HasSuffix(preview.Next[0], "T02:00:00+08:00") {
+		t.Fatalf("schedule preview: %+v", preview)
+	}
+
+	e.mustRun(exitNotFound, "tasks", "get", "no-such-task")
+	e.mustRun(exitUsage, "tasks", "create")
+	e.mustRun(exitUsage, "tasks", "list", "-o", "xml")
+	e.mustRun(exitUsage, "tasks", "frobnicate")
+	e.mustRun(exitOK, "tasks", "get", "-h")
+}
+
+func TestTasksEdit(t *testing.T) {
+	e := newTestEnv(t)
+	e.seedTask("nightly-etl", func(t *model.Task) { t.Description = "old" })
+	e.seedTask("synced", func(t *model.Task) { t.Managed, t.SyncSource = true, "etl.yaml" })
+
+	t.Setenv("CRONCTL_EDITOR", `sed -i -e 's/^description:.*/description: edited/' -e 's/^status:.*/status: ENABLED/'`)
+	if out := e.mustRun(exitOK, "tasks", "edit", "nightly-etl"); !strings.Contains(out, "updated") {
+		t.Fatalf("edit output: %s", out)
+	}
+	task, _ := e.tasks.Get(context.Background(), 1)
+	if task.Description != "edited" || task.Status != bizConsts.ENABLED || task.TargetPath != "/jobs/nightly-etl" {
+		t.Fatalf("task after edit: %+v", task)
+	}
+
+	t.Setenv("CRONCTL_EDITOR", "true") // 不修改即取消
+	if _, _, stderr := e.run("tasks", "edit", "nightly-etl"); !strings.Contains(stderr, "no changes") {
+		t.Fatalf("unchanged edit: %s", stderr)
+	}
+
+	t.Setenv("CRONCTL_EDITOR", `sed -i 's/^cron_expr:.*/cron_expr: "not a cron"/'`)
+	code, _, stderr := e.run("tasks", "edit", "nightly-etl")
+	if code != exitRejected || !strings.Contains(stderr, "your changes were saved to") {
+		t.Fatalf("rejected edit: exit %d\n%s", code, stderr)
+	}
+
+	e.mustRun(exitRejected, "tasks", "edit", "synced")
+}
+
+func TestTriggerWait(t *testing.T) {
+	e := newTestEnv(t)
+	e.seedTask("ingest", nil)
+	ctx := context.Background()
+
+	// 模拟执行：上报日志与进度后按 outcome 结束
+	simulate := func(outcome bizConsts.RunStatus) func(run *model.TaskRun) {
+		return func(run *model.TaskRun) {
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				_ = e.runSvc.AppendLogs(ctx, run.ID, []*model.RunLog{{TaskRunID: run.ID, Ts: time.Now(), Level: "INFO", Line: "loading rows"}})
+				_ = e.runSvc.UpdateProgress(ctx, run.ID, 5, 10, "half way", time.Now())
+				if outcome == bizConsts.Success {
+					_ = e.runSvc.MarkSuccess(ctx, run.ID, 200, "ok")
+				} else {
+					_ = e.runSvc.MarkFailed(ctx, run.ID, "upstream exploded")
+				}
+			}()
+		}
+	}
+	e.runs.setOnCreate(simulate(bizConsts.Success))
+	out := e.mustRun(exitOK, "trigger", "ingest", "--wait")
+	for _, want := range []string{"run 1", "loading rows", "progress 5/10 (50%) half way", "finished: SUCCESS"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("trigger --wait output missing %q:\n%s", want, out)
+		}
+	}
+
+	e.runs.setOnCreate(simulate(bizConsts.Failed))
+	code, stdout, stderr := e.run("trigger", "ingest", "--wait", "-o", "json")
+	if code != exitRunFailed || !strings.Contains(stderr, "upstream exploded") {
+		t.Fatalf("failed run: exit %d\n%s", code, stderr)
+	}
+	var run model.TaskRun
+	if err := json.Unmarshal([]byte(stdout), &run); err != nil || run.Status != bizConsts.Failed {
+		t.Fatalf("final run on stdout: %v %q", err, stdout)
+	}
+
+	e.runs.setOnCreate(nil) // 不执行，等待超时
+	e.mustRun(exitTimeout, "trigger", "ingest", "--wait", "--timeout", "300ms")
+	e.mustRun(exitOK, "runs", "watch", "1")
+}
+
+// Test the SSE stream stays on one connection past http_server's request timeout until the run ends
+func TestRunEventsOutliveRequestTimeout(t *testing.T) {
+	e := newTestEnv(t, http_server.Timeout(200*time.Millisecond))
+	e.seedTask("ingest", nil)
+	e.mustRun(exitOK, "trigger", "ingest")
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		_ = e.runSvc.MarkSuccess(context.Background(), 1, 200, "ok")
+	}()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(e.url + "/api/v1/runs/1/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "event: end") {
+		t.Fatalf("stream cut before the run ended: %d %v\n%s", resp.StatusCode, err, body)
+	}
+}
+
+func TestRunsAndCancel(t *testing.T) {
+	e := newTestEnv(t)
+	e.seedTask("ingest", nil)
+	e.mustRun(exitOK, "trigger", "ingest")
+
+	var runs []model.TaskRun
+	if err := json.Unmarshal([]byte(e.mustRun(exitOK, "runs", "list", "ingest", "-o", "json")), &runs); err != nil ||
+		len(runs) != 1 || runs[0].Status != bizConsts.Scheduled || runs[0].TriggerType != bizConsts.TriggerManual {
+		t.Fatalf("runs list: %v %+v", err, runs)
+	}
+	if out := e.mustRun(exitOK, "runs", "list"); !strings.Contains(out, "ingest") {
+		t.Fatalf("active runs:\n%s", out)
+	}
+
+	// tail 输出已有的 Run，直到 ctx 结束
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if code, out, _ := e.exec(ctx, "--server", e.url, "runs", "tail", "--interval", "50ms"); code != exitOK || !strings.Contains(out, "SCHEDULED") {
+		t.Fatalf("tail: exit %d\n%s", code, out)
+	}
+
+	var res []cancelResult
+	if err := json.Unmarshal([]byte(e.mustRun(exitOK, "cancel", "1", "-o", "json")), &res); err != nil ||
+		len(res) != 1 || !res[0].Canceled || res[0].Status != bizConsts.Canceled {
+		t.Fatalf("cancel: %v %+v", err, res)
+	}
+	if out := e.mustRun(exitOK, "cancel", "1"); !strings.Contains(out, "already finished") {
+		t.Fatalf("second cancel:\n%s", out)
+	}
+	if out := e.mustRun(exitOK, "runs", "get", "1"); !strings.Contains(out, "CANCELED") {
+		t.Fatalf("runs get:\n%s", out)
+	}
+	e.mustRun(exitNotFound, "runs", "get", "42")
+	e.mustRun(exitNotFound, "cancel", "42")
+	e.mustRun(exitUsage, "cancel", "abc")
+}
+
+func TestExportImport(t *testing.T) {
+	e := newTestEnv(t)
+	e.seedTask("ingest", func(t *model.Task) { t.Labels = map[string]string{"team": "data"} })
+	e.seedTask("report", nil)
+	_ = e.tasks.ReplaceUpstreams(context.Background(), 2, []int64{1})
+
+	dir := t.TempDir()
+	jsonFile, yamlFile := filepath.Join(dir, "tasks.json"), filepath.Join(dir, "tasks.yaml")
+	e.mustRun(exitOK, "export", "-f", jsonFile)
+	e.mustRun(exitOK, "export", "-f", yamlFile, "-o", "yaml")
+	if out := e.mustRun(exitOK, "export", "--task", "report"); !strings.Contains(out, `"upstream_tasks": [`) || strings.Contains(out, `"name": "ingest"`) {
+		t.Fatalf("single task export:\n%s", out)
+	}
+
+	// 同名任务已存在：全部失败
+	code, out, _ := e.run("import", jsonFile)
+	if code != exitRejected || !strings.Contains(out, "Task name already exists") {
+		t.Fatalf("import duplicates: exit %d\n%s", code, out)
+	}
+
+	_ = e.tasks.SoftDelete(context.Background(), 1)
+	_ = e.tasks.SoftDelete(context.Background(), 2)
+	if out := e.mustRun(exitOK, "import", yamlFile); !strings.Contains(out, "imported 2 task(s), 0 failed") {
+		t.Fatalf("import yaml:\n%s", out)
+	}
+	list, _ := e.tasks.ListFiltered(context.Background(), &model.TaskListFilters{}, 0, 0)
+	deps, _ := e.tasks.ListDependencies(context.Background())
+	if len(list) != 2 || list[0].Labels["team"] != "data" || len(deps) != 1 || deps[0].TaskID != list[1].ID {
+		t.Fatalf("imported tasks: %+v deps %+v", list, deps)
+	}
+}
+
+func TestConfigContexts(t *testing.T) {
+	e := newTestEnv(t)
+	e.seedTask("ingest", nil)
+	ctx := context.Background()
+	mustExec := func(want int, args ...string) string {
+		t.Helper()
+		code, stdout, stderr := e.exec(ctx, args...)
+		if code != want {
+			t.Fatalf("cronctl %s: exit %d, want %d\n%s%s", strings.Join(args, " "), code, want, stdout, stderr)
+		}
+		return stdout
+	}
+
+	mustExec(exitNotFound, "config", "current-context")
+	mustExec(exitUsage, "config", "set-context", "prod")
+	mustExec(exitOK, "config", "set-context", "prod", "--server", e.url, "--user", "ops")
+	mustExec(exitOK, "config", "set-context", "dev", "--server", "http://127.0.0.1:1", "--request-timeout", "1s")
+	if out := mustExec(exitOK, "config", "current-context"); strings.TrimSpace(out) != "prod" {
+		t.Fatalf("current context: %q", out)
+	}
+	if out := mustExec(exitOK, "tasks", "list"); !strings.Contains(out, "ingest") {
+		t.Fatalf("list via context:\n%s", out)
+	}
+	mustExec(exitError, "--context", "dev", "tasks", "list")
+	mustExec(exitUsage, "--context", "staging", "tasks", "list")
+
+	mustExec(exitOK, "config", "use-context", "dev")
+	var contexts []struct {
+		Name    string `json:"name"`
+		Current bool   `json:"current"`
+		User    string `json:"user"`
+	}
+	if err := json.Unmarshal([]byte(mustExec(exitOK, "config", "get-contexts", "-o", "json")), &contexts); err != nil ||
+		len(contexts) != 2 || !contexts[0].Current || contexts[1].User != "ops" {
+		t.Fatalf("contexts: %v %+v", err, contexts)
+	}
+	mustExec(exitOK, "config", "delete-context", "dev")
+	mustExec(exitNotFound, "config", "current-context")
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// table 表格输出；header 为空时只输出行（如 get 的字段 / 值列表）。
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(cols ...string) { t.rows = append(t.rows, cols) }
+
+func (t *table) write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if len(t.header) > 0 {
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	}
+	for _, r := range t.rows {
+		fmt.Fprintln(tw, strings.Join(r, "\t"))
+	}
+	return tw.Flush()
+}
+
+// render 按 -o 输出 v：json / yaml 输出完整数据，table 输出 tbl。
+func (c *cli) render(v any, tbl *table) error {
+	switch c.g.output {
+	case "json":
+		return writeJSONTo(c.stdout, v)
+	case "yaml":
+		return writeYAMLTo(c.stdout, v)
+	}
+	return tbl.write(c.stdout)
+}
+
+func writeJSONTo(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeYAMLTo 先按 JSON 标签转为通用结构再输出，字段名与 JSON 输出一致。
+func writeYAMLTo(w io.Writer, v any) error {
+	generic, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(generic); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func toGeneric(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// fmtTime 表格中的时间（本地时区）；nil 或零值显示为 -。
+func fmtTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// fmtDuration Run 耗时；未开始显示 -，未结束按当前时间计算。
+func fmtDuration(start, end *time.Time) string {
+	if start == nil || start.IsZero() {
+		return "-"
+	}
+	stop := time.Now()
+	if end != nil && !end.IsZero() {
+		stop = *end
+	}
+	return stop.Sub(*start).Round(100 * time.Millisecond).String()
+}
+
+// orDash 空字符串显示为 -。
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// formatLabels 标签按键排序输出为 k=v,k2=v2。
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+labels[k])
+	}
+	return strings.Join(parts, ",")
+}
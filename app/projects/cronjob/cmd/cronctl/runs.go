@@ -0,0 +1,625 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+const (
+	// streamRetryDelay 事件流断开后重连的间隔；连续失败 streamMaxRetries 次后放弃。
+	streamRetryDelay = time.Second
+	streamMaxRetries = 5
+)
+
+// runPage 任务 Run 列表 / 活跃 Run 列表的响应。
+type runPage struct {
+	Items  []*model.TaskRun `json:"items"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+}
+
+func runPath(id int64, suffix string) string {
+	return "/api/v1/runs/" + strconv.FormatInt(id, 10) + suffix
+}
+
+func parseRunID(fs *flag.FlagSet, s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, usageErrorf(fs.Name(), "invalid run id %q", s)
+	}
+	return id, nil
+}
+
+// trigger 手动触发任务；--wait 时跟踪 Run 直到结束，Run 未成功时退出码为 5，超时为 6。
+func (c *cli) trigger(args []string) error {
+	fs := c.flagSet("trigger")
+	wait := fs.Bool("wait", false, "wait for the run to finish, streaming status, progress and logs")
+	timeout := fs.Duration("timeout", 0, "with --wait: give up after this long (0 waits forever)")
+	args, err := c.parse(fs, args, "trigger TASK [--wait [--timeout DUR]]")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 1, 1, "task id or name"); err != nil {
+		return err
+	}
+	t, err := c.resolveTask(args[0])
+	if err != nil {
+		return err
+	}
+	api, err := c.connect()
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		RunID  int64               `json:"run_id"`
+		Status bizConsts.RunStatus `json:"status"`
+	}
+	if err := api.sendJSON(c.ctx, http.MethodPost, taskPath(t.ID, "/trigger"), nil, nil, &resp); err != nil {
+		return err
+	}
+	if !*wait {
+		tbl := &table{header: []string{"RUN", "TASK", "STATUS"}}
+		tbl.add(strconv.FormatInt(resp.RunID, 10), t.Name, string(resp.Status))
+		return c.render(map[string]any{"run_id": resp.RunID, "task_id": t.ID, "status": resp.Status}, tbl)
+	}
+	fmt.Fprintf(c.progressOut(), "triggered task %q: run %d\n", t.Name, resp.RunID)
+	return c.waitRun(resp.RunID, *timeout)
+}
+
+// runsWatch 跟踪已有的 Run 直到结束，退出码同 trigger --wait。
+func (c *cli) runsWatch(args []string) error {
+	fs := c.flagSet("runs watch")
+	timeout := fs.Duration("timeout", 0, "give up after this long (0 waits forever)")
+	args, err := c.parse(fs, args, "runs watch RUN [--timeout DUR]")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 1, 1, "run id"); err != nil {
+		return err
+	}
+	id, err := parseRunID(fs, args[0])
+	if err != nil {
+		return err
+	}
+	return c.waitRun(id, *timeout)
+}
+
+// progressOut 跟踪过程的输出：table 时写 stdout，json / yaml 时写 stderr，stdout 只留最终结果。
+func (c *cli) progressOut() io.Writer {
+	if c.g.output == "json" || c.g.output == "yaml" {
+		return c.stderr
+	}
+	return c.stdout
+}
+
+// waitRun 跟踪 Run 事件直到结束；json / yaml 输出时最后输出结束后的 Run。
+func (c *cli) waitRun(id int64, timeout time.Duration) error {
+	ctx := c.ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	out := c.progressOut()
+	status, err := c.follow(ctx, id, out)
+	switch {
+	case err != nil && c.ctx.Err() != nil:
+		return fmt.Errorf("interrupted while waiting for run %d (last status %s)", id, orDash(string(status)))
+	case err != nil && errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil:
+		return &exitErr{code: exitTimeout, msg: fmt.Sprintf("timed out after %s waiting for run %d (last status %s)", timeout, id, orDash(string(status)))}
+	case err != nil:
+		return err
+	}
+	if c.g.output == "json" || c.g.output == "yaml" {
+		api, err := c.connect()
+		if err != nil {
+			return err
+		}
+		var run model.TaskRun
+		if err := api.getJSON(c.ctx, runPath(id, ""), nil, &run); err != nil {
+			return err
+		}
+		if err := c.render(&run, nil); err != nil {
+			return err
+		}
+	}
+	if status != bizConsts.Success {
+		return &exitErr{code: exitRunFailed, msg: fmt.Sprintf("run %d finished with status %s", id, status)}
+	}
+	return nil
+}
+
+// sseEvent 事件流中的一条事件。
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// follow 订阅 GET /runs/{id}/events 并把状态、进度与日志写到 out，返回 Run 的结束状态；
+// 连接断开时按最后的日志 ID 续传（Last-Event-ID），事件不会重复输出。
+func (c *cli) follow(ctx context.Context, id int64, out io.Writer) (bizConsts.RunStatus, error) {
+	api, err := c.connect()
+	if err != nil {
+		return "", err
+	}
+	var (
+		lastID   string
+		status   bizConsts.RunStatus
+		failures int
+	)
+	for {
+		header := http.Header{"Accept": {"text/event-stream"}}
+		if lastID != "" {
+			header.Set("Last-Event-ID", lastID)
+		}
+		resp, err := api.send(ctx, http.MethodGet, runPath(id, "/events"), nil, nil, "", header)
+		var ae *apiError
+		if errors.As(err, &ae) {
+			return status, err // 404 等不重试
+		}
+		if err == nil {
+			var end bool
+			end, err = readSSE(resp.Body, func(ev sseEvent) bool {
+				if ev.id != "" {
+					lastID = ev.id
+				}
+				failures = 0
+				if s, done := printRunEvent(out, id, ev); s != "" {
+					status = s
+					return done
+				}
+				return false
+			})
+			resp.Body.Close()
+			if end {
+				return status, nil
+			}
+		}
+		if ctx.Err() != nil {
+			return status, ctx.Err()
+		}
+		if failures++; failures > streamMaxRetries {
+			if err == nil {
+				err = io.ErrUnexpectedEOF
+			}
+			return status, fmt.Errorf("run %d event stream: %w", id, err)
+		}
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-time.After(streamRetryDelay):
+		}
+	}
+}
+
+// readSSE 逐条解析 text/event-stream，handle 返回 true 时停止读取并返回 true。
+func readSSE(r io.Reader, handle func(sseEvent) bool) (bool, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), 4<<20)
+	var (
+		ev   sseEvent
+		data []string
+	)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			if len(data) > 0 || ev.event != "" {
+				ev.data = strings.Join(data, "\n")
+				if ev.event == "" {
+					ev.event = "message"
+				}
+				if handle(ev) {
+					return true, nil
+				}
+			}
+			ev, data = sseEvent{}, nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") { // 注释 / 心跳
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			ev.id = value
+		case "event":
+			ev.event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return false, sc.Err()
+}
+
+// printRunEvent 输出一条 Run 事件；status / end 事件返回 Run 的状态，end 时 done 为 true。
+func printRunEvent(w io.Writer, runID int64, ev sseEvent) (status bizConsts.RunStatus, done bool) {
+	prefix := fmt.Sprintf("[run %d]", runID)
+	switch ev.event {
+	case "status":
+		var s struct {
+			Status       bizConsts.RunStatus `json:"status"`
+			Attempt      int                 `json:"attempt"`
+			ResponseCode *int                `json:"response_code"`
+			ErrorMessage string              `json:"error_message"`
+		}
+		if json.Unmarshal([]byte(ev.data), &s) != nil {
+			return "", false
+		}
+		line := fmt.Sprintf("%s %s (attempt %d)", prefix, s.Status, s.Attempt)
+		if s.ResponseCode != nil {
+			line += fmt.Sprintf(" code=%d", *s.ResponseCode)
+		}
+		if s.ErrorMessage != "" {
+			line += ": " + s.ErrorMessage
+		}
+		fmt.Fprintln(w, line)
+		return s.Status, false
+	case "progress":
+		var p struct {
+			Current int64  `json:"current"`
+			Total   int64  `json:"total"`
+			Percent int    `json:"percent"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal([]byte(ev.data), &p) != nil {
+			return "", false
+		}
+		line := fmt.Sprintf("%s progress %d", prefix, p.Current)
+		if p.Total > 0 {
+			line += fmt.Sprintf("/%d (%d%%)", p.Total, p.Percent)
+		}
+		if p.Message != "" {
+			line += " " + p.Message
+		}
+		fmt.Fprintln(w, line)
+	case "log":
+		var l model.RunLog
+		if json.Unmarshal([]byte(ev.data), &l) != nil {
+			return "", false
+		}
+		fmt.Fprintf(w, "%s %s %-5s %s\n", prefix, l.Ts.Local().Format("15:04:05"), l.Level, l.Line)
+	case "end":
+		var e struct {
+			Status bizConsts.RunStatus `json:"status"`
+		}
+		if json.Unmarshal([]byte(ev.data), &e) != nil {
+			return "", false
+		}
+		fmt.Fprintf(w, "%s finished: %s\n", prefix, e.Status)
+		return e.Status, true
+	}
+	return "", false
+}
+
+// runsTable Run 列表的表格。
+func (c *cli) runsTable(runs []*model.TaskRun) *table {
+	tbl := &table{header: []string{"ID", "TASK", "STATUS", "TRIGGER", "ATTEMPT", "SCHEDULED", "STARTED", "DURATION"}}
+	for _, r := range runs {
+		tbl.add(strconv.FormatInt(r.ID, 10), c.taskName(r.TaskID), string(r.Status), string(r.TriggerType),
+			strconv.Itoa(r.Attempt), fmtTime(&r.ScheduledTime), fmtTime(r.StartTime), fmtDuration(r.StartTime, r.EndTime))
+	}
+	return tbl
+}
+
+// taskName 表格中显示的任务名（按 ID 缓存）；查询失败时显示 ID。
+func (c *cli) taskName(id int64) string {
+	if name, ok := c.taskNames[id]; ok {
+		return name
+	}
+	name := strconv.FormatInt(id, 10)
+	if api, err := c.connect(); err == nil {
+		var t taskView
+		if api.getJSON(c.ctx, taskPath(id, ""), nil, &t) == nil {
+			name = t.Name
+		}
+	}
+	if c.taskNames == nil {
+		c.taskNames = map[int64]string{}
+	}
+	c.taskNames[id] = name
+	return name
+}
+
+// runFilters runs list / tail 共用的查询条件。
+func runFilters(status string, since time.Duration, limit int) url.Values {
+	q := url.Values{"limit": {strconv.Itoa(limit)}}
+	if status != "" {
+		q.Set("status", strings.ToUpper(status))
+	}
+	if since > 0 {
+		q.Set("from", time.Now().Add(-since).UTC().Format(time.RFC3339))
+	}
+	return q
+}
+
+// listRuns 指定任务时列出该任务的 Run，否则列出活跃（未结束）的 Run。
+func (c *cli) listRuns(taskID int64, q url.Values) ([]*model.TaskRun, error) {
+	api, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	path := "/api/v1/runs/active"
+	if taskID > 0 {
+		path = taskPath(taskID, "/runs")
+	}
+	var page runPage
+	if err := api.getJSON(c.ctx, path, q, &page); err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+func (c *cli) runsList(args []string) error {
+	fs := c.flagSet("runs list")
+	status := fs.String("status", "", "comma-separated run statuses, e.g. FAILED,TIMEOUT")
+	since := fs.Duration("since", 0, "only runs scheduled within this duration, e.g. 24h")
+	limit := fs.Int("limit", 20, "max runs (1-500)")
+	args, err := c.parse(fs, args, "runs list [TASK] [--status S] [--since DUR] [--limit N]")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 0, 1, ""); err != nil {
+		return err
+	}
+	var taskID int64
+	if len(args) == 1 {
+		t, err := c.resolveTask(args[0])
+		if err != nil {
+			return err
+		}
+		taskID = t.ID
+		c.taskNames = map[int64]string{t.ID: t.Name}
+	}
+	runs, err := c.listRuns(taskID, runFilters(*status, *since, *limit))
+	if err != nil {
+		return err
+	}
+	if runs == nil {
+		runs = []*model.TaskRun{}
+	}
+	var tbl *table
+	if c.g.output == "" || c.g.output == "table" {
+		tbl = c.runsTable(runs)
+	}
+	return c.render(runs, tbl)
+}
+
+func (c *cli) runsGet(args []string) error {
+	fs := c.flagSet("runs get")
+	args, err := c.parse(fs, args, "runs get RUN")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 1, 1, "run id"); err != nil {
+		return err
+	}
+	id, err := parseRunID(fs, args[0])
+	if err != nil {
+		return err
+	}
+	api, err := c.connect()
+	if err != nil {
+		return err
+	}
+	var r model.TaskRun
+	if err := api.getJSON(c.ctx, runPath(id, ""), nil, &r); err != nil {
+		return err
+	}
+	var tbl *table
+	if c.g.output == "" || c.g.output == "table" {
+		tbl = &table{}
+		add := func(k, v string) { tbl.add(k+":", v) }
+		add("ID", strconv.FormatInt(r.ID, 10))
+		add("Task", fmt.Sprintf("%s (id %d, version %d)", c.taskName(r.TaskID), r.TaskID, r.TaskVersion))
+		add("Status", string(r.Status))
+		if r.CancelReason != "" {
+			add("Cancel reason", string(r.CancelReason))
+		}
+		add("Trigger", string(r.TriggerType))
+		add("Attempt", strconv.Itoa(r.Attempt))
+		if r.LogicalDate != nil {
+			add("Logical date", r.LogicalDate.Format(time.DateOnly))
+		}
+		add("Scheduled", fmtTime(&r.ScheduledTime))
+		add("Started", fmtTime(r.StartTime))
+		add("Ended", fmtTime(r.EndTime))
+		add("Duration", fmtDuration(r.StartTime, r.EndTime))
+		if r.ProgressUpdatedAt != nil {
+			add("Progress", fmt.Sprintf("%d/%d %s", r.ProgressCurrent, r.ProgressTotal, r.ProgressMessage))
+		}
+		if r.ResponseCode != nil {
+			add("Response code", strconv.Itoa(*r.ResponseCode))
+		}
+		if r.ErrorMessage != "" {
+			add("Error", r.ErrorMessage)
+		}
+	}
+	return c.render(&r, tbl)
+}
+
+// runsTail 轮询 Run 列表，输出新出现的 Run 与状态变化，直到 Ctrl-C。
+// 未指定任务时跟踪活跃 Run：从列表中消失的 Run 再查询一次以输出其结束状态。
+// json / yaml 时每个变化输出一行 JSON / 一个 YAML 文档。
+func (c *cli) runsTail(args []string) error {
+	fs := c.flagSet("runs tail")
+	interval := fs.Duration("interval", 2*time.Second, "poll interval")
+	limit := fs.Int("limit", 20, "runs fetched per poll (task mode)")
+	args, err := c.parse(fs, args, "runs tail [TASK] [--interval DUR]")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 0, 1, ""); err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		return usageErrorf(fs.Name(), "--interval must be positive")
+	}
+	var taskID int64
+	if len(args) == 1 {
+		t, err := c.resolveTask(args[0])
+		if err != nil {
+			return err
+		}
+		taskID = t.ID
+		c.taskNames = map[int64]string{t.ID: t.Name}
+	}
+	api, err := c.connect()
+	if err != nil {
+		return err
+	}
+	seen := map[int64]bizConsts.RunStatus{}
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		runs, err := c.listRuns(taskID, url.Values{"limit": {strconv.Itoa(*limit)}})
+		if err != nil && c.ctx.Err() == nil {
+			fmt.Fprintf(c.stderr, "poll failed: %v\n", err)
+		}
+		if err == nil {
+			present := make(map[int64]bool, len(runs))
+			for _, r := range runs {
+				present[r.ID] = true
+			}
+			if taskID == 0 { // 已结束的活跃 Run
+				for id, st := range seen {
+					if present[id] || st.Finished() {
+						continue
+					}
+					var r model.TaskRun
+					if api.getJSON(c.ctx, runPath(id, ""), nil, &r) == nil {
+						runs = append(runs, &r)
+					}
+				}
+			}
+			sort.Slice(runs, func(i, j int) bool { return runs[i].ID < runs[j].ID })
+			for _, r := range runs {
+				if prev, ok := seen[r.ID]; ok && prev == r.Status {
+					continue
+				}
+				seen[r.ID] = r.Status
+				if err := c.printTailRun(r); err != nil {
+					return err
+				}
+			}
+			if taskID == 0 {
+				for id, st := range seen {
+					if st.Finished() {
+						delete(seen, id)
+					}
+				}
+			}
+		}
+		select {
+		case <-c.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *cli) printTailRun(r *model.TaskRun) error {
+	switch c.g.output {
+	case "json":
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(c.stdout, "%s\n", b)
+		return err
+	case "yaml":
+		fmt.Fprintln(c.stdout, "---")
+		return writeYAMLTo(c.stdout, r)
+	}
+	line := fmt.Sprintf("%s  run %-8d %-20s %-16s attempt %d", time.Now().Format("15:04:05"), r.ID, c.taskName(r.TaskID), r.Status, r.Attempt)
+	if r.Status.Finished() {
+		line += "  " + fmtDuration(r.StartTime, r.EndTime)
+		if r.ErrorMessage != "" {
+			line += "  " + r.ErrorMessage
+		}
+	}
+	_, err := fmt.Fprintln(c.stdout, line)
+	return err
+}
+
+// cancelResult 取消单个 Run 的结果。
+type cancelResult struct {
+	RunID               int64               `json:"run_id"`
+	Canceled            bool                `json:"canceled"` // false 表示 Run 已结束
+	Status              bizConsts.RunStatus `json:"status"`
+	PendingConfirmation bool                `json:"pending_confirmation"` // 等待下游确认取消
+}
+
+// cancel 取消一个或多个 Run；--wait 时等待 Run 结束（最多服务端 executor.kill_grace）。
+func (c *cli) cancel(args []string) error {
+	fs := c.flagSet("cancel")
+	wait := fs.Bool("wait", false, "wait until the runs have ended")
+	args, err := c.parse(fs, args, "cancel RUN... [--wait]")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 1, -1, "run id"); err != nil {
+		return err
+	}
+	ids := make([]int64, 0, len(args))
+	for _, a := range args {
+		id, err := parseRunID(fs, a)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	api, err := c.connect()
+	if err != nil {
+		return err
+	}
+	var q url.Values
+	if *wait {
+		q = url.Values{"wait": {"true"}}
+		// 服务端最多等待 kill_grace 后返回，不受单个请求超时限制
+		api.timeout = 0
+	}
+	var (
+		results []cancelResult
+		errs    []error
+	)
+	tbl := &table{header: []string{"RUN", "CANCELED", "STATUS", "NOTE"}}
+	for _, id := range ids {
+		res := cancelResult{RunID: id}
+		if err := api.sendJSON(c.ctx, http.MethodPost, runPath(id, "/cancel"), q, nil, &res); err != nil {
+			errs = append(errs, fmt.Errorf("cancel run %d: %w", id, err))
+			continue
+		}
+		res.RunID = id
+		results = append(results, res)
+		note := ""
+		switch {
+		case !res.Canceled:
+			note = "already finished"
+		case res.PendingConfirmation:
+			note = "waiting for the executor to confirm"
+		}
+		tbl.add(strconv.FormatInt(id, 10), strconv.FormatBool(res.Canceled), string(res.Status), note)
+	}
+	if len(results) > 0 {
+		if err := c.render(results, tbl); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
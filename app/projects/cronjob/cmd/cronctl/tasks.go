@@ -0,0 +1,554 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/tasksync"
+)
+
+// listPageMax 服务端单页上限，--all 时按此分页拉取。
+const listPageMax = 500
+
+// taskPage GET /api/v1/tasks 的响应。
+type taskPage struct {
+	Items  []*model.Task `json:"items"`
+	Total  int64         `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+// taskView GET /api/v1/tasks/{id} 的响应：任务与上游任务 ID。
+type taskView struct {
+	model.Task
+	UpstreamTaskIDs []int64 `json:"upstream_task_ids"`
+}
+
+// taskBody 创建 / 修改任务的请求体：任务字段（JSON 标签与 API 一致）与上游任务 ID，
+// UpstreamTaskIDs 为 nil 时不修改上游。
+type taskBody struct {
+	*model.Task
+	UpstreamTaskIDs *[]int64 `json:"upstream_task_ids,omitempty"`
+}
+
+// taskRef 命令输出中的任务及其状态（create / enable / disable）。
+type taskRef struct {
+	ID     int64                `json:"id"`
+	Name   string               `json:"name"`
+	Status bizConsts.TaskStatus `json:"status"`
+}
+
+// resolveTask 按 ID 或精确名称取任务；不存在时返回 404 的 apiError。
+func (c *cli) resolveTask(ref string) (*taskView, error) {
+	api, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseInt(ref, 10, 64)
+	if err != nil || id <= 0 {
+		id = 0
+		var page taskPage
+		q := url.Values{"name": {ref}, "limit": {strconv.Itoa(listPageMax)}}
+		if err := api.getJSON(c.ctx, "/api/v1/tasks", q, &page); err != nil {
+			return nil, err
+		}
+		for _, t := range page.Items {
+			if t.Name == ref {
+				id = t.ID
+				break
+			}
+		}
+		if id == 0 {
+			return nil, &apiError{Status: http.StatusNotFound, Message: fmt.Sprintf("task %q not found", ref)}
+		}
+	}
+	var v taskView
+	if err := api.getJSON(c.ctx, taskPath(id, ""), nil, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func taskPath(id int64, suffix string) string {
+	return "/api/v1/tasks/" + strconv.FormatInt(id, 10) + suffix
+}
+
+func (c *cli) tasksList(args []string) error {
+	fs := c.flagSet("tasks list")
+	status := fs.String("status", "", "ENABLED or DISABLED")
+	name := fs.String("name", "", "name contains")
+	namespace := fs.String("namespace", "", "namespace")
+	owner := fs.String("owner", "", "owned by")
+	selector := fs.String("l", "", "label selector, e.g. team=quant,env!=prod")
+	fs.StringVar(selector, "selector", "", "label selector")
+	limit := fs.Int("limit", 50, "page size (max 500)")
+	offset := fs.Int("offset", 0, "page offset")
+	all := fs.Bool("all", false, "fetch all pages")
+	args, err := c.parse(fs, args, "tasks list [flags]")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 0, 0, ""); err != nil {
+		return err
+	}
+	api, err := c.connect()
+	if err != nil {
+		return err
+	}
+	q := url.Values{}
+	for k, v := range map[string]string{"status": strings.ToUpper(*status), "name": *name, "namespace": *namespace, "owner": *owner, "selector": *selector} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	var (
+		items []*model.Task
+		total int64
+	)
+	if *all {
+		*limit, *offset = listPageMax, 0
+	}
+	for {
+		q.Set("limit", strconv.Itoa(*limit))
+		q.Set("offset", strconv.Itoa(*offset))
+		var page taskPage
+		if err := api.getJSON(c.ctx, "/api/v1/tasks", q, &page); err != nil {
+			return err
+		}
+		items, total = append(items, page.Items...), page.Total
+		*offset += len(page.Items)
+		if !*all || len(page.Items) < page.Limit || int64(len(items)) >= total {
+			break
+		}
+	}
+	if items == nil {
+		items = []*model.Task{}
+	}
+	tbl := &table{header: []string{"ID", "NAME", "NAMESPACE", "STATUS", "SCHEDULE", "EXECUTOR", "TARGET"}}
+	for _, t := range items {
+		tbl.add(strconv.FormatInt(t.ID, 10), t.Name, t.Namespace, string(t.Status), scheduleOf(t), string(t.Executor), targetOf(t))
+	}
+	if err := c.render(items, tbl); err != nil {
+		return err
+	}
+	if c.g.output == "table" || c.g.output == "" {
+		if int64(len(items)) < total {
+			fmt.Fprintf(c.stderr, "showing %d of %d tasks (use --offset, --limit or --all)\n", len(items), total)
+		}
+	}
+	return nil
+}
+
+func (c *cli) tasksGet(args []string) error {
+	fs := c.flagSet("tasks get")
+	args, err := c.parse(fs, args, "tasks get TASK")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 1, 1, "task id or name"); err != nil {
+		return err
+	}
+	t, err := c.resolveTask(args[0])
+	if err != nil {
+		return err
+	}
+	tbl := &table{}
+	add := func(k, v string) { tbl.add(k+":", v) }
+	add("ID", strconv.FormatInt(t.ID, 10))
+	add("Name", t.Name)
+	add("Description", orDash(t.Description))
+	add("Namespace", t.Namespace)
+	add("Owners", orDash(strings.Join(t.Owners, ",")))
+	add("Labels", orDash(formatLabels(t.Labels)))
+	add("Status", string(t.Status))
+	if t.Managed {
+		add("Managed", "yes ("+t.SyncSource+")")
+	}
+	add("Schedule", scheduleOf(&t.Task))
+	add("Timezone", t.Timezone)
+	if t.Calendar != "" {
+		add("Calendar", fmt.Sprintf("%s (%s)", t.Calendar, t.CalendarMode))
+	}
+	if len(t.UpstreamTaskIDs) > 0 {
+		ids := make([]string, 0, len(t.UpstreamTaskIDs))
+		for _, id := range t.UpstreamTaskIDs {
+			ids = append(ids, strconv.FormatInt(id, 10))
+		}
+		add("Upstreams", strings.Join(ids, ",")+" ("+string(t.TriggerRule)+")")
+	}
+	add("Executor", string(t.Executor)+" "+string(t.ExecType))
+	add("Target", targetOf(&t.Task))
+	add("Concurrency", fmt.Sprintf("max %d, %s", t.MaxConcurrency, orDash(string(t.ConcurrencyPolicy))))
+	if t.ExecutionTimeoutSec > 0 {
+		add("Execution timeout", fmt.Sprintf("%ds", t.ExecutionTimeoutSec))
+	}
+	add("Version", strconv.Itoa(t.Version))
+	add("Created", fmtTime(&t.CreatedAt))
+	add("Updated", fmtTime(&t.UpdatedAt))
+	return c.render(t, tbl)
+}
+
+// scheduleOf 表格中的调度方式：cron 表达式，依赖触发时注明上游。
+func scheduleOf(t *model.Task) string {
+	switch t.ScheduleMode {
+	case bizConsts.ScheduleModeDependency:
+		return "after upstreams"
+	case bizConsts.ScheduleModeBoth:
+		return t.CronExpr + " + upstreams"
+	}
+	return t.CronExpr
+}
+
+// targetOf 表格中的执行目标。
+func targetOf(t *model.Task) string {
+	if t.Executor == "" || t.Executor == bizConsts.ExecutorHTTP {
+		return strings.TrimSpace(t.HTTPMethod + " " + t.TargetService + t.TargetPath)
+	}
+	return t.TargetPath
+}
+
+// tasksCreate 按声明文件（与声明式同步相同的 YAML 格式，可含多个文档）逐个创建任务。
+// API 创建的任务为 DISABLED；--enable 或声明中显式写了 status: ENABLED 时创建后随即启用。
+// 上游按名称解析，可引用同一文件中排在前面的任务。
+func (c *cli) tasksCreate(args []string) error {
+	fs := c.flagSet("tasks create")
+	file := fs.String("f", "", "spec file (YAML or JSON, multi-document; - for stdin)")
+	enable := fs.Bool("enable", false, "enable the tasks after creating them")
+	args, err := c.parse(fs, args, "tasks create -f FILE [--enable]")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 0, 0, ""); err != nil {
+		return err
+	}
+	if *file == "" {
+		return usageErrorf(fs.Name(), "-f FILE required")
+	}
+	data, err := readInput(*file)
+	if err != nil {
+		return err
+	}
+	specs, err := tasksync.Parse(*file, data)
+	if err != nil {
+		return usageErrorf(fs.Name(), "%v", err)
+	}
+	if len(specs) == 0 {
+		return usageErrorf(fs.Name(), "%s declares no tasks", *file)
+	}
+	tasks := make([]*model.Task, len(specs))
+	for i, s := range specs {
+		if tasks[i], err = s.Task(); err != nil {
+			return usageErrorf(fs.Name(), "task %q: %v", s.Name, err)
+		}
+	}
+	api, err := c.connect()
+	if err != nil {
+		return err
+	}
+	created := make([]taskRef, 0, len(specs))
+	tbl := &table{header: []string{"ID", "NAME", "STATUS"}}
+	err = func() error {
+		for i, s := range specs {
+			ids, err := c.upstreamIDs(s.UpstreamTasks)
+			if err != nil {
+				return fmt.Errorf("task %q: %w", s.Name, err)
+			}
+			var resp struct {
+				ID int64 `json:"id"`
+			}
+			if err := api.sendJSON(c.ctx, http.MethodPost, "/api/v1/tasks", nil, taskBody{Task: tasks[i], UpstreamTaskIDs: &ids}, &resp); err != nil {
+				return fmt.Errorf("create task %q: %w", s.Name, err)
+			}
+			ref := taskRef{ID: resp.ID, Name: s.Name, Status: bizConsts.DISABLED}
+			if *enable || strings.EqualFold(strings.TrimSpace(s.Status), string(bizConsts.ENABLED)) {
+				if err := api.sendJSON(c.ctx, http.MethodPatch, taskPath(resp.ID, "/enable"), nil, nil, nil); err != nil {
+					created = append(created, ref)
+					return fmt.Errorf("enable task %q: %w", s.Name, err)
+				}
+				ref.Status = bizConsts.ENABLED
+			}
+			created = append(created, ref)
+		}
+		return nil
+	}()
+	for _, r := range created {
+		tbl.add(strconv.FormatInt(r.ID, 10), r.Name, string(r.Status))
+	}
+	if len(created) > 0 || err == nil {
+		if rerr := c.render(created, tbl); rerr != nil {
+			return rerr
+		}
+	}
+	return err
+}
+
+// upstreamIDs 把上游任务名称解析为 ID；返回非 nil 切片（空切片表示无上游）。
+func (c *cli) upstreamIDs(names []string) ([]int64, error) {
+	ids := make([]int64, 0, len(names))
+	for _, n := range names {
+		t, err := c.resolveTask(n)
+		if err != nil {
+			return nil, fmt.Errorf("upstream task %q: %w", n, err)
+		}
+		ids = append(ids, t.ID)
+	}
+	return ids, nil
+}
+
+// tasksEdit 把任务转为声明 YAML 交给编辑器（或直接使用 -f 指定的声明），保存后整体 PUT 回去。
+// 未修改或清空内容时取消；提交失败时编辑稿另存到临时文件，避免修改丢失。
+// 由声明式同步托管的任务只能修改声明文件，直接拒绝。
+func (c *cli) tasksEdit(args []string) error {
+	fs := c.flagSet("tasks edit")
+	file := fs.String("f", "", "apply this spec file instead of opening an editor")
+	args, err := c.parse(fs, args, "tasks edit TASK [-f FILE]")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 1, 1, "task id or name"); err != nil {
+		return err
+	}
+	t, err := c.resolveTask(args[0])
+	if err != nil {
+		return err
+	}
+	if t.Managed {
+		return &exitErr{code: exitRejected, msg: fmt.Sprintf("task %q is managed by declarative sync (%s); edit its spec file instead", t.Name, t.SyncSource)}
+	}
+	var data []byte
+	keep := func(err error) error { return err }
+	if *file != "" {
+		if data, err = readInput(*file); err != nil {
+			return err
+		}
+	} else {
+		names, err := c.upstreamNames(t.UpstreamTaskIDs)
+		if err != nil {
+			return err
+		}
+		spec, err := yaml.Marshal(tasksync.FromTask(&t.Task, names))
+		if err != nil {
+			return err
+		}
+		orig := append([]byte(fmt.Sprintf("# Editing task %q (id %d). Save and quit to apply; an unchanged or empty file cancels the edit.\n", t.Name, t.ID)), spec...)
+		if data, err = c.editInEditor(orig); err != nil {
+			return err
+		}
+		if bytes.Equal(data, orig) {
+			fmt.Fprintln(c.stderr, "edit canceled: no changes")
+			return nil
+		}
+		keep = func(err error) error {
+			if path, serr := saveDraft(data); serr == nil {
+				return fmt.Errorf("%w\nyour changes were saved to %s (apply with: cronctl tasks edit %d -f %s)", err, path, t.ID, path)
+			}
+			return err
+		}
+	}
+	specs, err := tasksync.Parse("edit", data)
+	if err != nil {
+		return keep(usageErrorf(fs.Name(), "%v", err))
+	}
+	switch len(specs) {
+	case 0:
+		fmt.Fprintln(c.stderr, "edit canceled: empty spec")
+		return nil
+	case 1:
+	default:
+		return keep(usageErrorf(fs.Name(), "expected one task, got %d", len(specs)))
+	}
+	nt, err := specs[0].Task()
+	if err != nil {
+		return keep(usageErrorf(fs.Name(), "%v", err))
+	}
+	if strings.TrimSpace(specs[0].Status) == "" {
+		nt.Status = t.Status // 未写 status 时不修改
+	}
+	ids, err := c.upstreamIDs(specs[0].UpstreamTasks)
+	if err != nil {
+		return keep(err)
+	}
+	api, err := c.connect()
+	if err != nil {
+		return err
+	}
+	if err := api.sendJSON(c.ctx, http.MethodPut, taskPath(t.ID, ""), nil, taskBody{Task: nt, UpstreamTaskIDs: &ids}, nil); err != nil {
+		return keep(err)
+	}
+	// PUT 不修改状态，状态变化经 enable / disable 接口
+	if nt.Status != t.Status {
+		verb := "/disable"
+		if nt.Status == bizConsts.ENABLED {
+			verb = "/enable"
+		}
+		if err := api.sendJSON(c.ctx, http.MethodPatch, taskPath(t.ID, verb), nil, nil, nil); err != nil {
+			return fmt.Errorf("task %q updated but changing its status failed: %w", nt.Name, err)
+		}
+	}
+	fmt.Fprintf(c.stdout, "task %q (id %d) updated\n", nt.Name, t.ID)
+	return nil
+}
+
+// upstreamNames 把上游任务 ID 解析为名称。
+func (c *cli) upstreamNames(ids []int64) ([]string, error) {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		t, err := c.resolveTask(strconv.FormatInt(id, 10))
+		if err != nil {
+			return nil, fmt.Errorf("upstream task %d: %w", id, err)
+		}
+		names = append(names, t.Name)
+	}
+	return names, nil
+}
+
+// editInEditor 在 $CRONCTL_EDITOR / $VISUAL / $EDITOR（默认 vi）中编辑 content，返回保存后的内容。
+func (c *cli) editInEditor(content []byte) ([]byte, error) {
+	f, err := os.CreateTemp("", "cronctl-edit-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	editor := firstNonEmpty(os.Getenv("CRONCTL_EDITOR"), os.Getenv("VISUAL"), os.Getenv("EDITOR"), "vi")
+	// 经 sh 执行，编辑器可带参数（如 "code --wait"）
+	cmd := exec.CommandContext(c.ctx, "sh", "-c", editor+` "$1"`, "cronctl-editor", path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, c.stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor %q: %w", editor, err)
+	}
+	return os.ReadFile(path)
+}
+
+// saveDraft 保存提交失败的编辑稿。
+func saveDraft(data []byte) (string, error) {
+	f, err := os.CreateTemp("", "cronctl-edit-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// readInput 读取文件，"-" 表示标准输入。
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// tasksSetStatus 启用 / 停用一个或多个任务；单个任务失败不影响其他任务，错误汇总返回。
+func (c *cli) tasksSetStatus(args []string, enable bool) error {
+	verb, status := "disable", bizConsts.DISABLED
+	if enable {
+		verb, status = "enable", bizConsts.ENABLED
+	}
+	fs := c.flagSet("tasks " + verb)
+	args, err := c.parse(fs, args, "tasks "+verb+" TASK...")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 1, -1, "task id or name"); err != nil {
+		return err
+	}
+	api, err := c.connect()
+	if err != nil {
+		return err
+	}
+	var (
+		done []taskRef
+		errs []error
+	)
+	tbl := &table{header: []string{"ID", "NAME", "STATUS"}}
+	for _, ref := range args {
+		t, err := c.resolveTask(ref)
+		if err == nil {
+			err = api.sendJSON(c.ctx, http.MethodPatch, taskPath(t.ID, "/"+verb), nil, nil, nil)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s task %s: %w", verb, ref, err))
+			continue
+		}
+		done = append(done, taskRef{ID: t.ID, Name: t.Name, Status: status})
+		tbl.add(strconv.FormatInt(t.ID, 10), t.Name, string(status))
+	}
+	if len(done) > 0 {
+		if err := c.render(done, tbl); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// schedulePreview GET /api/v1/tasks/{id}/schedule 的响应。
+type schedulePreview struct {
+	TaskID       int64    `json:"task_id"`
+	CronExpr     string   `json:"cron_expr"`
+	Timezone     string   `json:"timezone"`
+	Calendar     string   `json:"calendar"`
+	CalendarMode string   `json:"calendar_mode"`
+	Next         []string `json:"next"`
+	Blackout     []string `json:"blackout"` // 落在停摆窗口内、将被跳过的触发
+}
+
+func (c *cli) tasksSchedule(args []string) error {
+	fs := c.flagSet("tasks schedule")
+	n := fs.Int("n", 10, "number of fire times (1-100)")
+	args, err := c.parse(fs, args, "tasks schedule TASK [-n N]")
+	if err != nil {
+		return err
+	}
+	if err := needArgs(fs, args, 1, 1, "task id or name"); err != nil {
+		return err
+	}
+	t, err := c.resolveTask(args[0])
+	if err != nil {
+		return err
+	}
+	api, err := c.connect()
+	if err != nil {
+		return err
+	}
+	var p schedulePreview
+	if err := api.getJSON(c.ctx, taskPath(t.ID, "/schedule"), url.Values{"n": {strconv.Itoa(*n)}}, &p); err != nil {
+		return err
+	}
+	type fire struct{ at, note string }
+	fires := make([]fire, 0, len(p.Next)+len(p.Blackout))
+	for _, f := range p.Next {
+		fires = append(fires, fire{at: f})
+	}
+	for _, f := range p.Blackout {
+		fires = append(fires, fire{at: f, note: "skipped (blackout)"})
+	}
+	sort.Slice(fires, func(i, j int) bool { return fires[i].at < fires[j].at })
+	tbl := &table{header: []string{"FIRE TIME (" + p.Timezone + ")", "NOTE"}}
+	for _, f := range fires {
+		tbl.add(f.at, f.note)
+	}
+	return c.render(p, tbl)
+}
@@ -8,7 +8,7 @@ import (
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/hooks"
 	"github.com/grand-thief-cash/chaos/app/infra/go/common/utils/net"
 	_ "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/api"
-	_ "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
 	_ "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/registry_ext"
 )
@@ -19,6 +19,7 @@ var (
 
 func main() {
 	app := application.GetApp()
+	config.Register(app)
 
 	hooks.RegisterHook("Service Preparation", hooks.BeforeStart, func(ctx context.Context) error {
 		consts.LocalIP, _ = net.GetLocalIP()
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/service"
+)
+
+// AlertController 告警规则、通知渠道与告警历史接口。
+type AlertController struct {
+	*core.BaseComponent
+	TaskSvc  *service.TaskService  `infra:"dep:task_service"`
+	AlertSvc *service.AlertManager `infra:"dep:alert_manager"`
+}
+
+func NewAlertController() *AlertController {
+	return &AlertController{BaseComponent: core.NewBaseComponent(bizConsts.COMP_CTRL_ALERT)}
+}
+
+func (c *AlertController) Start(ctx context.Context) error { return c.BaseComponent.Start(ctx) }
+
+type alertRuleReq struct {
+	Kind        bizConsts.AlertKind `json:"kind"`
+	Threshold   int                 `json:"threshold"`
+	Factor      float64             `json:"factor"`
+	MinSamples  int                 `json:"min_samples"`
+	SLASec      int                 `json:"sla_sec"`
+	Channels    []string            `json:"channels"`
+	SuppressSec int                 `json:"suppress_sec"`
+	Enabled     *bool               `json:"enabled"` // 缺省为 true
+	Description string              `json:"description"`
+}
+
+func (c *AlertController) listTaskRules(w http.ResponseWriter, r *http.Request, taskID int64) {
+	list, err := c.AlertSvc.ListRules(r.Context(), taskID)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"items": list})
+}
+
+// createRule POST /api/v1/tasks/{id}/alert-rules
+func (c *AlertController) createRule(w http.ResponseWriter, r *http.Request, taskID int64) {
+	if t, err := c.TaskSvc.Get(r.Context(), taskID); err != nil || t == nil {
+		writeErr(w, 404, "task_not_found")
+		return
+	}
+	var req alertRuleReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	rule := &model.AlertRule{
+		TaskID:      taskID,
+		Kind:        bizConsts.AlertKind(strings.ToUpper(strings.TrimSpace(string(req.Kind)))),
+		Threshold:   req.Threshold,
+		Factor:      req.Factor,
+		MinSamples:  req.MinSamples,
+		SLASec:      req.SLASec,
+		Channels:    req.Channels,
+		SuppressSec: req.SuppressSec,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		Description: req.Description,
+	}
+	if err := c.AlertSvc.CreateRule(r.Context(), rule); err != nil {
+		writeAlertErr(w, r, err)
+		return
+	}
+	logging.Info(r.Context(), fmt.Sprintf("alert rule %d created task=%d kind=%s channels=%v", rule.ID, taskID, rule.Kind, rule.Channels))
+	writeJSON(w, rule)
+}
+
+// updateRule PUT /api/v1/alert-rules/{id}：只更新请求中出现的字段，task_id 与 kind 不可修改。
+func (c *AlertController) updateRule(w http.ResponseWriter, r *http.Request, id int64) {
+	rule, err := c.AlertSvc.GetRule(r.Context(), id)
+	if err != nil {
+		writeAlertErr(w, r, err)
+		return
+	}
+	taskID, kind := rule.TaskID, rule.Kind
+	if err := json.NewDecoder(r.Body).Decode(rule); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	rule.ID, rule.TaskID, rule.Kind = id, taskID, kind
+	if err := c.AlertSvc.UpdateRule(r.Context(), rule); err != nil {
+		writeAlertErr(w, r, err)
+		return
+	}
+	logging.Info(r.Context(), fmt.Sprintf("alert rule %d updated enabled=%v channels=%v", id, rule.Enabled, rule.Channels))
+	writeJSON(w, rule)
+}
+
+func (c *AlertController) getRule(w http.ResponseWriter, r *http.Request, id int64) {
+	rule, err := c.AlertSvc.GetRule(r.Context(), id)
+	if err != nil {
+		writeAlertErr(w, r, err)
+		return
+	}
+	writeJSON(w, rule)
+}
+
+func (c *AlertController) deleteRule(w http.ResponseWriter, r *http.Request, id int64) {
+	if err := c.AlertSvc.DeleteRule(r.Context(), id); err != nil {
+		writeAlertErr(w, r, err)
+		return
+	}
+	logging.Info(r.Context(), fmt.Sprintf("alert rule %d deleted", id))
+	writeJSON(w, map[string]any{"deleted": true})
+}
+
+// listAlerts GET /api/v1/alerts?task_id=&rule_id=&run_id=&kind=&status=&from=&to=&limit=&offset=
+func (c *AlertController) listAlerts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var taskID int64
+	if v := q.Get("task_id"); v != "" {
+		taskID, _ = strconv.ParseInt(v, 10, 64)
+	}
+	c.writeAlerts(w, r, taskID)
+}
+
+// listTaskAlerts GET /api/v1/tasks/{id}/alerts
+func (c *AlertController) listTaskAlerts(w http.ResponseWriter, r *http.Request, taskID int64) {
+	c.writeAlerts(w, r, taskID)
+}
+
+func (c *AlertController) writeAlerts(w http.ResponseWriter, r *http.Request, taskID int64) {
+	_, from, to, limit, offset, _ := parseRunFilters(r)
+	q := r.URL.Query()
+	f := model.AlertEventFilter{
+		TaskID: taskID,
+		Kind:   bizConsts.AlertKind(strings.ToUpper(strings.TrimSpace(q.Get("kind")))),
+		Status: bizConsts.AlertStatus(strings.ToUpper(strings.TrimSpace(q.Get("status")))),
+		From:   from,
+		To:     to,
+	}
+	if v := q.Get("rule_id"); v != "" {
+		f.RuleID, _ = strconv.ParseInt(v, 10, 64)
+	}
+	if v := q.Get("run_id"); v != "" {
+		f.RunID, _ = strconv.ParseInt(v, 10, 64)
+	}
+	list, err := c.AlertSvc.ListEvents(r.Context(), f, limit, offset)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"items": list, "limit": limit, "offset": offset})
+}
+
+func (c *AlertController) listChannels(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{"items": c.AlertSvc.Channels()})
+}
+
+// testChannel POST /api/v1/alerts/channels/{name}/test 发送一条测试消息，失败时返回 502 与错误信息。
+func (c *AlertController) testChannel(w http.ResponseWriter, r *http.Request, name string) {
+	if err := c.AlertSvc.TestChannel(r.Context(), name); err != nil {
+		if errors.Is(err, service.ErrUnknownChannel) {
+			writeErr(w, 404, err.Error())
+			return
+		}
+		logging.Warn(r.Context(), fmt.Sprintf("alert channel %s test failed: %v", name, err))
+		writeErr(w, 502, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"channel": name, "sent": true})
+}
+
+func writeAlertErr(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, dao.ErrAlertRuleNotFound):
+		writeErr(w, 404, "alert_rule_not_found")
+	case errors.Is(err, service.ErrInvalidAlertRule), errors.Is(err, service.ErrUnknownChannel):
+		writeErr(w, 400, err.Error())
+	default:
+		logging.Error(r.Context(), fmt.Sprintf("alert request failed: %v", err))
+		writeErr(w, 500, err.Error())
+	}
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/service"
+)
+
+// defaultAnalyticsWindow 未指定 from 时的统计窗口。
+const defaultAnalyticsWindow = 7 * 24 * time.Hour
+
+// AnalyticsController 运行分析与 SLA 报告接口。
+type AnalyticsController struct {
+	*core.BaseComponent
+	AnalyticsSvc *service.AnalyticsService `infra:"dep:analytics_service"`
+}
+
+func NewAnalyticsController() *AnalyticsController {
+	return &AnalyticsController{BaseComponent: core.NewBaseComponent(bizConsts.COMP_CTRL_ANALYTICS)}
+}
+
+func (c *AnalyticsController) Start(ctx context.Context) error { return c.BaseComponent.Start(ctx) }
+
+// parseAnalyticsFilter 解析 from / to（RFC3339，默认最近 7 天）与 task_id。
+func parseAnalyticsFilter(r *http.Request) (model.AnalyticsFilter, error) {
+	q := r.URL.Query()
+	f := model.AnalyticsFilter{To: time.Now().UTC()}
+	if v := strings.TrimSpace(q.Get("to")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, fmt.Errorf("invalid to: %w", err)
+		}
+		f.To = t
+	}
+	f.From = f.To.Add(-defaultAnalyticsWindow)
+	if v := strings.TrimSpace(q.Get("from")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, fmt.Errorf("invalid from: %w", err)
+		}
+		f.From = t
+	}
+	if !f.From.Before(f.To) {
+		return f, errors.New("from must be before to")
+	}
+	if v := q.Get("task_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			return f, fmt.Errorf("invalid task_id %q", v)
+		}
+		f.TaskID = id
+	}
+	return f, nil
+}
+
+func queryInt(r *http.Request, key string, def int) int {
+	if v := r.URL.Query().Get(key); v != "" {
+		if i, err := strconv.Atoi(v); err == nil && i >= 0 {
+			return i
+		}
+	}
+	return def
+}
+
+// taskStats GET /api/v1/analytics/tasks?from=&to=&task_id=
+// 各任务的耗时分位数（P50/P95/P99）、排队与执行时间、ASYNC 回调延迟与成功率。
+func (c *AnalyticsController) taskStats(w http.ResponseWriter, r *http.Request) {
+	f, err := parseAnalyticsFilter(r)
+	if err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	list, err := c.AnalyticsSvc.TaskStats(r.Context(), f)
+	if err != nil {
+		writeAnalyticsErr(w, r, err)
+		return
+	}
+	writeJSON(w, map[string]any{"from": f.From, "to": f.To, "items": list})
+}
+
+// daily GET /api/v1/analytics/daily?from=&to=&task_id=&tz=Asia/Shanghai 逐日成功率（按 tz 划分日期，默认 UTC）。
+func (c *AnalyticsController) daily(w http.ResponseWriter, r *http.Request) {
+	f, err := parseAnalyticsFilter(r)
+	if err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	tz := strings.TrimSpace(r.URL.Query().Get("tz"))
+	list, err := c.AnalyticsSvc.Daily(r.Context(), f, tz)
+	if err != nil {
+		writeAnalyticsErr(w, r, err)
+		return
+	}
+	writeJSON(w, map[string]any{"from": f.From, "to": f.To, "items": list})
+}
+
+// top GET /api/v1/analytics/top?by=slowest|failures&limit=10&min_runs=5 最慢 / 最易失败的任务。
+func (c *AnalyticsController) top(w http.ResponseWriter, r *http.Request) {
+	f, err := parseAnalyticsFilter(r)
+	if err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	by := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("by")))
+	if by == "" {
+		by = service.TopSlowest
+	}
+	limit := queryInt(r, "limit", 10)
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	list, err := c.AnalyticsSvc.Top(r.Context(), f, by, limit, queryInt(r, "min_runs", 5))
+	if err != nil {
+		writeAnalyticsErr(w, r, err)
+		return
+	}
+	writeJSON(w, map[string]any{"from": f.From, "to": f.To, "by": by, "items": list})
+}
+
+// sla GET /api/v1/analytics/sla?from=&to=&task_id=&sla_sec=
+// 默认按已启用的 SLA_MISSED 规则出报告；sla_sec 可临时指定（须带 task_id）。
+func (c *AnalyticsController) sla(w http.ResponseWriter, r *http.Request) {
+	f, err := parseAnalyticsFilter(r)
+	if err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	list, err := c.AnalyticsSvc.SLA(r.Context(), f, queryInt(r, "sla_sec", 0), time.Now())
+	if err != nil {
+		writeAnalyticsErr(w, r, err)
+		return
+	}
+	writeJSON(w, map[string]any{"from": f.From, "to": f.To, "items": list})
+}
+
+func writeAnalyticsErr(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, service.ErrInvalidAnalytics) {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	logging.Error(r.Context(), fmt.Sprintf("analytics request failed: %v", err))
+	writeErr(w, 500, err.Error())
+}
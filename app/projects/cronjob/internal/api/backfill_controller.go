@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/service"
+)
+
+// BackfillController 历史区间补跑：创建（含 dry-run）、查询与整体取消。
+type BackfillController struct {
+	*core.BaseComponent
+	TaskSvc  *service.TaskService     `infra:"dep:task_service"`
+	RunSvc   *service.RunService      `infra:"dep:run_service"`
+	Backfill *service.BackfillManager `infra:"dep:backfill_manager"`
+}
+
+func NewBackfillController() *BackfillController {
+	return &BackfillController{BaseComponent: core.NewBaseComponent(bizConsts.COMP_CTRL_BACKFILL)}
+}
+
+func (c *BackfillController) Start(ctx context.Context) error { return c.BaseComponent.Start(ctx) }
+
+// createBackfill POST /api/v1/tasks/{id}/backfill
+// start/end 为 RFC3339 时间或 YYYY-MM-DD（按任务时区，end 取当天结束），两端包含；dry_run 只返回将要执行的触发点。
+func (c *BackfillController) createBackfill(w http.ResponseWriter, r *http.Request, taskID int64) {
+	t, err := c.TaskSvc.Get(r.Context(), taskID)
+	if err != nil || t == nil {
+		writeErr(w, 404, "task_not_found")
+		return
+	}
+	var req struct {
+		Start         string `json:"start"`
+		End           string `json:"end"`
+		MaxParallel   int    `json:"max_parallel"`
+		SkipSucceeded bool   `json:"skip_succeeded"`
+		DryRun        bool   `json:"dry_run"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	loc, err := cron.LoadLocation(t.Timezone)
+	if err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	start, err := parseRangeBound(req.Start, loc, false)
+	if err != nil {
+		writeErr(w, 400, fmt.Sprintf("start: %v", err))
+		return
+	}
+	end, err := parseRangeBound(req.End, loc, true)
+	if err != nil {
+		writeErr(w, 400, fmt.Sprintf("end: %v", err))
+		return
+	}
+	if req.MaxParallel == 0 {
+		req.MaxParallel = 1
+	}
+	br := service.BackfillRequest{Start: start, End: end, MaxParallel: req.MaxParallel, SkipSucceeded: req.SkipSucceeded}
+	if req.DryRun {
+		fires, err := c.Backfill.Plan(r.Context(), t, br)
+		if err != nil {
+			writeBackfillErr(w, r, err)
+			return
+		}
+		skipped := 0
+		for _, f := range fires {
+			if f.Skip {
+				skipped++
+			}
+		}
+		writeJSON(w, map[string]any{"task_id": t.ID, "timezone": loc.String(), "dry_run": true,
+			"total": len(fires), "skipped": skipped, "fires": fires})
+		return
+	}
+	b, err := c.Backfill.Create(r.Context(), t, br)
+	if err != nil {
+		writeBackfillErr(w, r, err)
+		return
+	}
+	writeJSON(w, b)
+}
+
+func (c *BackfillController) listTaskBackfills(w http.ResponseWriter, r *http.Request, taskID int64) {
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil && i > 0 && i <= 500 {
+			limit = i
+		}
+	}
+	list, err := c.Backfill.BackfillDao.ListByTask(r.Context(), taskID, limit)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"items": list})
+}
+
+// getBackfill 返回批次及其 Run 的状态统计。
+func (c *BackfillController) getBackfill(w http.ResponseWriter, r *http.Request, id int64) {
+	b, err := c.Backfill.BackfillDao.Get(r.Context(), id)
+	if err != nil {
+		writeBackfillErr(w, r, err)
+		return
+	}
+	counts, err := c.RunSvc.CountStatusByBackfill(r.Context(), id)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, struct {
+		*model.Backfill
+		RunStatus map[bizConsts.RunStatus]int64 `json:"run_status"`
+	}{b, counts})
+}
+
+func (c *BackfillController) listBackfillRuns(w http.ResponseWriter, r *http.Request, id int64) {
+	_, _, _, limit, offset, _ := parseRunFilters(r)
+	list, err := c.RunSvc.ListByBackfill(r.Context(), id, limit, offset)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"items": list, "limit": limit, "offset": offset})
+}
+
+func (c *BackfillController) cancelBackfill(w http.ResponseWriter, r *http.Request, id int64) {
+	b, err := c.Backfill.Cancel(r.Context(), id)
+	if err != nil {
+		writeBackfillErr(w, r, err)
+		return
+	}
+	writeJSON(w, b)
+}
+
+func writeBackfillErr(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, dao.ErrBackfillNotFound):
+		writeErr(w, 404, "backfill_not_found")
+	case errors.Is(err, service.ErrBackfillFinished):
+		writeErr(w, 409, "BACKFILL_FINISHED")
+	case errors.Is(err, service.ErrInvalidBackfill), errors.Is(err, service.ErrBackfillNoCron), errors.Is(err, service.ErrBackfillEmpty):
+		writeErr(w, 400, err.Error())
+	default:
+		logging.Error(r.Context(), fmt.Sprintf("backfill request failed: %v", err))
+		writeErr(w, 500, err.Error())
+	}
+}
+
+// parseRangeBound 解析区间端点：RFC3339 原样使用；YYYY-MM-DD 按任务时区取当天开始（endOfDay 时取当天最后一秒）。
+func parseRangeBound(v string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, fmt.Errorf("required")
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), nil
+	}
+	d, err := time.ParseInLocation(time.DateOnly, v, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be RFC3339 or YYYY-MM-DD")
+	}
+	if endOfDay {
+		d = d.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return d.UTC(), nil
+}
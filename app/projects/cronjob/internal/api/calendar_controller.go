@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/service"
+)
+
+// maxCalendarDaysRange 营业日查询的最大区间（天）。
+const maxCalendarDaysRange = 366 * 5
+
+// CalendarController 营业日历与停摆窗口的维护接口。
+type CalendarController struct {
+	*core.BaseComponent
+	Calendars *service.CalendarService `infra:"dep:calendar_service"`
+}
+
+func NewCalendarController() *CalendarController {
+	return &CalendarController{BaseComponent: core.NewBaseComponent(bizConsts.COMP_CTRL_CALENDAR)}
+}
+
+func (c *CalendarController) Start(ctx context.Context) error { return c.BaseComponent.Start(ctx) }
+
+type calendarReq struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Kind        bizConsts.CalendarKind `json:"kind"`
+	Dates       []string               `json:"dates"`
+}
+
+func (c *CalendarController) listCalendars(w http.ResponseWriter, r *http.Request) {
+	list, err := c.Calendars.List(r.Context())
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"items": list})
+}
+
+// createCalendar POST /api/v1/calendars，同名日历已存在时返回 409（整体替换用 PUT）。
+func (c *CalendarController) createCalendar(w http.ResponseWriter, r *http.Request) {
+	var req calendarReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if _, err := c.Calendars.Get(r.Context(), name); err == nil {
+		writeErr(w, 409, "CALENDAR_EXISTS")
+		return
+	} else if !errors.Is(err, dao.ErrCalendarNotFound) {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	c.saveCalendar(w, r, name, req)
+}
+
+// putCalendar PUT /api/v1/calendars/{name} 整体替换（不存在则新建）。
+func (c *CalendarController) putCalendar(w http.ResponseWriter, r *http.Request, name string) {
+	var req calendarReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	c.saveCalendar(w, r, name, req)
+}
+
+func (c *CalendarController) saveCalendar(w http.ResponseWriter, r *http.Request, name string, req calendarReq) {
+	cal := &model.Calendar{Name: name, Description: req.Description, Kind: bizConsts.CalendarKind(strings.ToUpper(string(req.Kind))), Dates: req.Dates}
+	if err := c.Calendars.Save(r.Context(), cal); err != nil {
+		writeCalendarErr(w, r, err)
+		return
+	}
+	logging.Info(r.Context(), fmt.Sprintf("calendar %s saved version=%d dates=%d", cal.Name, cal.Version, len(cal.Dates)))
+	writeJSON(w, cal)
+}
+
+func (c *CalendarController) getCalendar(w http.ResponseWriter, r *http.Request, name string) {
+	cal, err := c.Calendars.Get(r.Context(), name)
+	if err != nil {
+		writeCalendarErr(w, r, err)
+		return
+	}
+	writeJSON(w, cal)
+}
+
+// patchCalendarDates PATCH /api/v1/calendars/{name}/dates {"add": [...], "remove": [...]}
+func (c *CalendarController) patchCalendarDates(w http.ResponseWriter, r *http.Request, name string) {
+	var req struct {
+		Add    []string `json:"add"`
+		Remove []string `json:"remove"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	cal, err := c.Calendars.UpdateDates(r.Context(), name, req.Add, req.Remove)
+	if err != nil {
+		writeCalendarErr(w, r, err)
+		return
+	}
+	logging.Info(r.Context(), fmt.Sprintf("calendar %s dates updated add=%d remove=%d version=%d", name, len(req.Add), len(req.Remove), cal.Version))
+	writeJSON(w, cal)
+}
+
+func (c *CalendarController) deleteCalendar(w http.ResponseWriter, r *http.Request, name string) {
+	if err := c.Calendars.Delete(r.Context(), name); err != nil {
+		writeCalendarErr(w, r, err)
+		return
+	}
+	writeJSON(w, map[string]any{"deleted": true})
+}
+
+// businessDays GET /api/v1/calendars/{name}/days?from=YYYY-MM-DD&to=YYYY-MM-DD 列出区间内的营业日（两端包含）。
+func (c *CalendarController) businessDays(w http.ResponseWriter, r *http.Request, name string) {
+	cal, ok := c.Calendars.Calendar(name)
+	if !ok {
+		writeErr(w, 404, "calendar_not_found")
+		return
+	}
+	q := r.URL.Query()
+	from, err := time.Parse(time.DateOnly, q.Get("from"))
+	if err != nil {
+		writeErr(w, 400, "from must be YYYY-MM-DD")
+		return
+	}
+	to, err := time.Parse(time.DateOnly, q.Get("to"))
+	if err != nil {
+		writeErr(w, 400, "to must be YYYY-MM-DD")
+		return
+	}
+	if to.Before(from) || to.Sub(from) > maxCalendarDaysRange*24*time.Hour {
+		writeErr(w, 400, fmt.Sprintf("to must be within %d days after from", maxCalendarDaysRange))
+		return
+	}
+	days := cal.BusinessDays(from, to)
+	writeJSON(w, map[string]any{"calendar": name, "from": q.Get("from"), "to": q.Get("to"), "count": len(days), "days": days})
+}
+
+func (c *CalendarController) listBlackouts(w http.ResponseWriter, r *http.Request) {
+	list, err := c.Calendars.ListBlackouts(r.Context())
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"items": list})
+}
+
+// createBlackout POST /api/v1/blackouts；start/end 为 RFC3339，或 YYYY-MM-DD 配合 timezone（end 取当天结束）。
+func (c *CalendarController) createBlackout(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name     string `json:"name"`
+		TaskID   *int64 `json:"task_id"`
+		Start    string `json:"start"`
+		End      string `json:"end"`
+		Timezone string `json:"timezone"`
+		Reason   string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	loc, err := cron.LoadLocation(defaultOr(req.Timezone, "UTC"))
+	if err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	start, err := parseRangeBound(req.Start, loc, false)
+	if err != nil {
+		writeErr(w, 400, fmt.Sprintf("start: %v", err))
+		return
+	}
+	end, err := parseRangeBound(req.End, loc, true)
+	if err != nil {
+		writeErr(w, 400, fmt.Sprintf("end: %v", err))
+		return
+	}
+	if len(req.End) == len(time.DateOnly) {
+		end = end.Add(time.Second) // 窗口为 [start, end)，日期形式的 end 覆盖当天整天
+	}
+	b := &model.BlackoutWindow{Name: strings.TrimSpace(req.Name), TaskID: req.TaskID, StartTime: start, EndTime: end, Reason: req.Reason}
+	if err := c.Calendars.CreateBlackout(r.Context(), b); err != nil {
+		writeCalendarErr(w, r, err)
+		return
+	}
+	logging.Info(r.Context(), fmt.Sprintf("blackout window %d(%s) created [%s, %s)", b.ID, b.Name, b.StartTime.Format(time.RFC3339), b.EndTime.Format(time.RFC3339)))
+	writeJSON(w, b)
+}
+
+func (c *CalendarController) deleteBlackout(w http.ResponseWriter, r *http.Request, id int64) {
+	if err := c.Calendars.DeleteBlackout(r.Context(), id); err != nil {
+		writeCalendarErr(w, r, err)
+		return
+	}
+	writeJSON(w, map[string]any{"deleted": true})
+}
+
+func writeCalendarErr(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, dao.ErrCalendarNotFound):
+		writeErr(w, 404, "calendar_not_found")
+	case errors.Is(err, dao.ErrBlackoutNotFound):
+		writeErr(w, 404, "blackout_not_found")
+	case errors.Is(err, service.ErrCalendarInUse):
+		writeErr(w, 409, err.Error())
+	case errors.Is(err, service.ErrInvalidCalendar), errors.Is(err, service.ErrInvalidBlackout):
+		writeErr(w, 400, err.Error())
+	default:
+		logging.Error(r.Context(), fmt.Sprintf("calendar request failed: %v", err))
+		writeErr(w, 500, err.Error())
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
 )
 
@@ -21,13 +22,13 @@ type RunDaoIface interface {
 	CreateScheduled(context.Context, *model.TaskRun) error
 	Get(context.Context, int64) (*model.TaskRun, error)
 	ListByTask(context.Context, int64, int) ([]*model.TaskRun, error)
-	MarkCanceled(context.Context, int64) error
+	MarkCanceled(context.Context, int64, bizConsts.CancelReason) error
 }
 
 type ExecutorIface interface {
 	Enqueue(*model.TaskRun)
 	ActiveCount(taskID int64) int
-	CancelRun(ctx context.Context, id int64)
+	Cancel(context.Context, *model.TaskRun, bizConsts.CancelReason) (bizConsts.RunStatus, error)
 }
 
 // Dependencies injected into handlers
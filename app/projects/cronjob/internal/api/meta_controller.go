@@ -3,16 +3,24 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/grand-thief-cash/chaos/app/infra/go/application"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/service"
 )
 
 type MetaController struct {
 	*core.BaseComponent
+	Leader     *service.LeaderElector         `infra:"dep:scheduler_leader"`
+	Pause      *service.SchedulerPauseService `infra:"dep:scheduler_pause"`
+	Namespaces *service.NamespaceService      `infra:"dep:namespace_service"`
 }
 
 func NewMetaController() *MetaController {
@@ -41,6 +49,92 @@ func (c *MetaController) ListClients(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// LeaderStatus 返回本副本的选主状态（是否 leader、任期、租约）以及数据库中当前的租约持有者。
+func (c *MetaController) LeaderStatus(w http.ResponseWriter, r *http.Request) {
+	st, err := c.Leader.Status(r.Context())
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"status": "success",
+		"data":   st,
+	})
+}
+
+// SchedulerPauseStatus GET /api/v1/scheduler/pause 返回调度器全局暂停状态。
+func (c *MetaController) SchedulerPauseStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"status": "success",
+		"data":   c.Pause.State(),
+	})
+}
+
+// PauseScheduler POST /api/v1/scheduler/pause {"until": RFC3339 | "duration": "2h", "reason": "..."}
+// 暂停调度器（不产生新的触发，执行中的 Run 照常结束）；until / duration 至多指定其一，到期自动恢复。
+func (c *MetaController) PauseScheduler(w http.ResponseWriter, r *http.Request) {
+	ctx := withActor(r)
+	if rejectForbidden(w, c.Namespaces.AuthorizeAdmin(ctx)) {
+		return
+	}
+	var req struct {
+		Until    *time.Time `json:"until"`
+		Duration string     `json:"duration"`
+		Reason   string     `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, 400, err.Error())
+			return
+		}
+	}
+	until := req.Until
+	if d := strings.TrimSpace(req.Duration); d != "" {
+		if until != nil {
+			writeErr(w, 400, "until and duration are mutually exclusive")
+			return
+		}
+		dur, err := time.ParseDuration(d)
+		if err != nil || dur <= 0 {
+			writeErr(w, 400, fmt.Sprintf("invalid duration %q", d))
+			return
+		}
+		t := time.Now().Add(dur)
+		until = &t
+	}
+	st, err := c.Pause.Pause(ctx, until, strings.TrimSpace(req.Reason))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPause) {
+			writeErr(w, 400, err.Error())
+			return
+		}
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"status": "success",
+		"data":   st,
+	})
+}
+
+// ResumeScheduler POST /api/v1/scheduler/resume 恢复调度器；未暂停时 resumed 为 false。
+func (c *MetaController) ResumeScheduler(w http.ResponseWriter, r *http.Request) {
+	ctx := withActor(r)
+	if rejectForbidden(w, c.Namespaces.AuthorizeAdmin(ctx)) {
+		return
+	}
+	st, resumed, err := c.Pause.Resume(ctx)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"resumed": resumed,
+		"data":    st,
+	})
+}
+
 // Start implements core.Component
 func (c *MetaController) Start(ctx context.Context) error { return c.BaseComponent.Start(ctx) }
 
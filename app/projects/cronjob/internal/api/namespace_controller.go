@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/service"
+)
+
+// NamespaceController 命名空间的维护接口。
+type NamespaceController struct {
+	*core.BaseComponent
+	Namespaces *service.NamespaceService `infra:"dep:namespace_service"`
+}
+
+func NewNamespaceController() *NamespaceController {
+	return &NamespaceController{BaseComponent: core.NewBaseComponent(bizConsts.COMP_CTRL_NAMESPACE)}
+}
+
+func (c *NamespaceController) Start(ctx context.Context) error { return c.BaseComponent.Start(ctx) }
+
+type namespaceReq struct {
+	Name                  string   `json:"name"`
+	Description           string   `json:"description"`
+	Owners                []string `json:"owners"`
+	MaxTasks              int      `json:"max_tasks"`
+	MaxConcurrentRuns     int      `json:"max_concurrent_runs"`
+	AllowedTargetServices []string `json:"allowed_target_services"`
+}
+
+func (req namespaceReq) toModel(name string) *model.Namespace {
+	return &model.Namespace{
+		Name:                  name,
+		Description:           req.Description,
+		Owners:                normalizeNames(req.Owners),
+		MaxTasks:              req.MaxTasks,
+		MaxConcurrentRuns:     req.MaxConcurrentRuns,
+		AllowedTargetServices: normalizeNames(req.AllowedTargetServices),
+	}
+}
+
+func (c *NamespaceController) listNamespaces(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{"items": c.Namespaces.List()})
+}
+
+// createNamespace POST /api/v1/namespaces，同名空间已存在时返回 409。
+func (c *NamespaceController) createNamespace(w http.ResponseWriter, r *http.Request) {
+	ctx := withActor(r)
+	var req namespaceReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	ns := req.toModel(strings.TrimSpace(req.Name))
+	if err := c.Namespaces.Create(ctx, ns); err != nil {
+		writeNamespaceCtrlErr(w, r, err)
+		return
+	}
+	logging.Info(ctx, fmt.Sprintf("namespace %s created by %s", ns.Name, service.ActorFrom(ctx)))
+	writeJSON(w, ns)
+}
+
+// getNamespace GET /api/v1/namespaces/{name}，附带当前任务数与执行中的 Run 数。
+func (c *NamespaceController) getNamespace(w http.ResponseWriter, r *http.Request, name string) {
+	ns, err := c.Namespaces.Get(r.Context(), name)
+	if err != nil {
+		writeNamespaceCtrlErr(w, r, err)
+		return
+	}
+	usage, err := c.Namespaces.Usage(r.Context(), name)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, struct {
+		*model.Namespace
+		Usage *model.NamespaceUsage `json:"usage"`
+	}{ns, usage})
+}
+
+// putNamespace PUT /api/v1/namespaces/{name} 整体替换描述、owners、配额与 target_service 白名单。
+// 调低配额不影响已有任务与执行中的 Run。
+func (c *NamespaceController) putNamespace(w http.ResponseWriter, r *http.Request, name string) {
+	ctx := withActor(r)
+	var req namespaceReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	ns := req.toModel(name)
+	if err := c.Namespaces.Update(ctx, ns); err != nil {
+		writeNamespaceCtrlErr(w, r, err)
+		return
+	}
+	logging.Info(ctx, fmt.Sprintf("namespace %s updated by %s", ns.Name, service.ActorFrom(ctx)))
+	writeJSON(w, ns)
+}
+
+func (c *NamespaceController) deleteNamespace(w http.ResponseWriter, r *http.Request, name string) {
+	ctx := withActor(r)
+	if err := c.Namespaces.Delete(ctx, name); err != nil {
+		writeNamespaceCtrlErr(w, r, err)
+		return
+	}
+	logging.Info(ctx, fmt.Sprintf("namespace %s deleted by %s", name, service.ActorFrom(ctx)))
+	writeJSON(w, map[string]any{"deleted": true})
+}
+
+func writeNamespaceCtrlErr(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, dao.ErrNamespaceNotFound):
+		writeErr(w, 404, "namespace_not_found")
+	case errors.Is(err, dao.ErrNamespaceExists):
+		writeErr(w, 409, "NAMESPACE_EXISTS")
+	case errors.Is(err, service.ErrNamespaceInUse):
+		writeErr(w, 409, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		writeErr(w, 403, "forbidden")
+	case errors.Is(err, service.ErrInvalidNamespace):
+		writeErr(w, 400, err.Error())
+	default:
+		logging.Error(r.Context(), fmt.Sprintf("namespace request failed: %v", err))
+		writeErr(w, 500, err.Error())
+	}
+}
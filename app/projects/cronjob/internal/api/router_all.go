@@ -10,89 +10,246 @@ import (
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
 )
 
-// Unified route registration combining task + run controllers.
 func init() {
-	http_server.RegisterRoutes(func(r chi.Router, c *core.Container) error {
-		// resolve controllers
-		compTask, err := c.Resolve(bizConsts.COMP_CTRL_TASK_MGMT)
-		if err != nil {
-			return err
-		}
-		taskCtrl, ok := compTask.(*TaskMgmtController)
-		if !ok {
-			return fmt.Errorf("task_mgmt_ctrl type assertion failed")
-		}
-		compRun, err := c.Resolve(bizConsts.COMP_CTRL_RUN_MGMT)
-		if err != nil {
-			return err
-		}
-		runCtrl, ok := compRun.(*RunMgmtController)
-		if !ok {
-			return fmt.Errorf("run_mgmt_ctrl type assertion failed")
-		}
-		compMeta, err := c.Resolve(bizConsts.COMP_CTRL_META_MGMT)
-		if err != nil {
-			return err
+	http_server.RegisterRoutes(RegisterRoutes)
+}
+
+// RegisterRoutes unified route registration combining all controllers resolved from c;
+// exported so tools and tests can mount the real router without the http_server component.
+func RegisterRoutes(r chi.Router, c *core.Container) error {
+	// resolve controllers
+	compTask, err := c.Resolve(bizConsts.COMP_CTRL_TASK_MGMT)
+	if err != nil {
+		return err
+	}
+	taskCtrl, ok := compTask.(*TaskMgmtController)
+	if !ok {
+		return fmt.Errorf("task_mgmt_ctrl type assertion failed")
+	}
+	compRun, err := c.Resolve(bizConsts.COMP_CTRL_RUN_MGMT)
+	if err != nil {
+		return err
+	}
+	runCtrl, ok := compRun.(*RunMgmtController)
+	if !ok {
+		return fmt.Errorf("run_mgmt_ctrl type assertion failed")
+	}
+	compMeta, err := c.Resolve(bizConsts.COMP_CTRL_META_MGMT)
+	if err != nil {
+		return err
+	}
+	metaCtrl, ok := compMeta.(*MetaController)
+	if !ok {
+		return fmt.Errorf("meta_mgmt_ctrl type assertion failed")
+	}
+	compBackfill, err := c.Resolve(bizConsts.COMP_CTRL_BACKFILL)
+	if err != nil {
+		return err
+	}
+	backfillCtrl, ok := compBackfill.(*BackfillController)
+	if !ok {
+		return fmt.Errorf("backfill_ctrl type assertion failed")
+	}
+	compCalendar, err := c.Resolve(bizConsts.COMP_CTRL_CALENDAR)
+	if err != nil {
+		return err
+	}
+	calendarCtrl, ok := compCalendar.(*CalendarController)
+	if !ok {
+		return fmt.Errorf("calendar_ctrl type assertion failed")
+	}
+	compAlert, err := c.Resolve(bizConsts.COMP_CTRL_ALERT)
+	if err != nil {
+		return err
+	}
+	alertCtrl, ok := compAlert.(*AlertController)
+	if !ok {
+		return fmt.Errorf("alert_ctrl type assertion failed")
+	}
+	compAnalytics, err := c.Resolve(bizConsts.COMP_CTRL_ANALYTICS)
+	if err != nil {
+		return err
+	}
+	analyticsCtrl, ok := compAnalytics.(*AnalyticsController)
+	if !ok {
+		return fmt.Errorf("analytics_ctrl type assertion failed")
+	}
+	compNamespace, err := c.Resolve(bizConsts.COMP_CTRL_NAMESPACE)
+	if err != nil {
+		return err
+	}
+	namespaceCtrl, ok := compNamespace.(*NamespaceController)
+	if !ok {
+		return fmt.Errorf("namespace_ctrl type assertion failed")
+	}
+
+	// Task routes
+	r.Route("/api/v1/tasks", func(r chi.Router) {
+		getTaskID := func(r *http.Request) int64 {
+			var id int64
+			_, _ = fmt.Sscanf(chi.URLParam(r, "id"), "%d", &id)
+			return id
 		}
-		metaCtrl, ok := compMeta.(*MetaController)
-		if !ok {
-			return fmt.Errorf("meta_mgmt_ctrl type assertion failed")
+		r.Get("/", taskCtrl.listTasks)
+		r.Post("/", taskCtrl.createTask)
+		r.Get("/graph", taskCtrl.taskGraph)
+		r.Post("/bulk/enable", func(w http.ResponseWriter, req *http.Request) { taskCtrl.bulkTasks(w, req, bulkEnable) })
+		r.Post("/bulk/disable", func(w http.ResponseWriter, req *http.Request) { taskCtrl.bulkTasks(w, req, bulkDisable) })
+		r.Post("/bulk/trigger", func(w http.ResponseWriter, req *http.Request) { taskCtrl.bulkTasks(w, req, bulkTrigger) })
+		r.Post("/bulk/delete", func(w http.ResponseWriter, req *http.Request) { taskCtrl.bulkTasks(w, req, bulkDelete) })
+		r.Get("/{id}", func(w http.ResponseWriter, req *http.Request) { taskCtrl.getTask(w, req, getTaskID(req)) })
+		r.Put("/{id}", func(w http.ResponseWriter, req *http.Request) { taskCtrl.updateTask(w, req, getTaskID(req)) })
+		r.Patch("/{id}", func(w http.ResponseWriter, req *http.Request) { taskCtrl.updateTask(w, req, getTaskID(req)) })
+		r.Delete("/{id}", func(w http.ResponseWriter, req *http.Request) { taskCtrl.deleteTask(w, req, getTaskID(req)) })
+		r.Patch("/{id}/enable", func(w http.ResponseWriter, req *http.Request) {
+			taskCtrl.updateStatus(w, req, getTaskID(req), bizConsts.ENABLED)
+		})
+		r.Patch("/{id}/disable", func(w http.ResponseWriter, req *http.Request) {
+			taskCtrl.updateStatus(w, req, getTaskID(req), bizConsts.DISABLED)
+		})
+		r.Post("/{id}/trigger", func(w http.ResponseWriter, req *http.Request) { taskCtrl.triggerTask(w, req, getTaskID(req)) })
+		r.Get("/{id}/history", func(w http.ResponseWriter, req *http.Request) { taskCtrl.taskHistory(w, req, getTaskID(req)) })
+		r.Post("/{id}/rollback/{version}", func(w http.ResponseWriter, req *http.Request) {
+			var version int
+			_, _ = fmt.Sscanf(chi.URLParam(req, "version"), "%d", &version)
+			taskCtrl.rollbackTask(w, req, getTaskID(req), version)
+		})
+		r.Get("/{id}/schedule", func(w http.ResponseWriter, req *http.Request) { taskCtrl.previewSchedule(w, req, getTaskID(req)) })
+		r.Post("/{id}/render", func(w http.ResponseWriter, req *http.Request) { taskCtrl.renderPreview(w, req, getTaskID(req)) })
+		r.Post("/{id}/rerun", func(w http.ResponseWriter, req *http.Request) { taskCtrl.rerunTask(w, req, getTaskID(req)) })
+		r.Post("/{id}/backfill", func(w http.ResponseWriter, req *http.Request) { backfillCtrl.createBackfill(w, req, getTaskID(req)) })
+		r.Get("/{id}/backfills", func(w http.ResponseWriter, req *http.Request) { backfillCtrl.listTaskBackfills(w, req, getTaskID(req)) })
+		r.Get("/{id}/alert-rules", func(w http.ResponseWriter, req *http.Request) { alertCtrl.listTaskRules(w, req, getTaskID(req)) })
+		r.Post("/{id}/alert-rules", func(w http.ResponseWriter, req *http.Request) { alertCtrl.createRule(w, req, getTaskID(req)) })
+		r.Get("/{id}/alerts", func(w http.ResponseWriter, req *http.Request) { alertCtrl.listTaskAlerts(w, req, getTaskID(req)) })
+		// migrated run listing
+		r.Get("/{id}/runs", func(w http.ResponseWriter, req *http.Request) { runCtrl.listRunsByTask(w, req, getTaskID(req)) })
+		r.Get("/{id}/runs/stats", func(w http.ResponseWriter, req *http.Request) { runCtrl.taskRunStats(w, req, getTaskID(req)) })
+	})
+
+	// Run routes
+	r.Route("/api/v1/runs", func(r chi.Router) {
+		getRunID := func(r *http.Request) int64 {
+			var id int64
+			_, _ = fmt.Sscanf(chi.URLParam(r, "id"), "%d", &id)
+			return id
 		}
+		// list all in-memory progress first
+		r.Get("/progress", runCtrl.listAllRunProgress)
+		r.Get("/active", runCtrl.listActiveRuns)
+		r.Post("/bulk/cancel", runCtrl.bulkCancelRuns)
+		r.Get("/summary", runCtrl.summaryRuns)
+		r.Post("/cleanup", runCtrl.cleanupRuns)
+		r.Get("/{id}", func(w http.ResponseWriter, req *http.Request) { runCtrl.getRun(w, req, getRunID(req)) })
+		r.Post("/{id}/cancel", func(w http.ResponseWriter, req *http.Request) { runCtrl.cancelRun(w, req, getRunID(req)) })
+		r.Get("/{id}/lineage", func(w http.ResponseWriter, req *http.Request) { runCtrl.runLineage(w, req, getRunID(req)) })
+		r.Post("/{id}/rerun", func(w http.ResponseWriter, req *http.Request) { runCtrl.rerunDownstream(w, req, getRunID(req)) })
+		r.Get("/{id}/progress", func(w http.ResponseWriter, req *http.Request) { runCtrl.getRunProgress(w, req, getRunID(req)) })
+		r.Post("/{id}/progress", func(w http.ResponseWriter, req *http.Request) { runCtrl.setRunProgress(w, req, getRunID(req)) })
+		r.Post("/{id}/callback", func(w http.ResponseWriter, req *http.Request) { runCtrl.finalizeCallback(w, req, getRunID(req)) })
+		r.Get("/{id}/callbacks", func(w http.ResponseWriter, req *http.Request) { runCtrl.listCallbacks(w, req, getRunID(req)) })
+		r.Get("/{id}/logs", func(w http.ResponseWriter, req *http.Request) { runCtrl.listRunLogs(w, req, getRunID(req)) })
+		r.Post("/{id}/logs", func(w http.ResponseWriter, req *http.Request) { runCtrl.appendRunLogs(w, req, getRunID(req)) })
+		// SSE 长连接不受 http_server 的请求超时限制
+		r.With(http_server.NoTimeout).Get("/{id}/events", func(w http.ResponseWriter, req *http.Request) { runCtrl.streamRunEvents(w, req, getRunID(req)) })
+	})
 
-		// Task routes
-		r.Route("/api/v1/tasks", func(r chi.Router) {
-			getTaskID := func(r *http.Request) int64 {
-				var id int64
-				_, _ = fmt.Sscanf(chi.URLParam(r, "id"), "%d", &id)
-				return id
-			}
-			r.Get("/", taskCtrl.listTasks)
-			r.Post("/", taskCtrl.createTask)
-			r.Get("/{id}", func(w http.ResponseWriter, req *http.Request) { taskCtrl.getTask(w, req, getTaskID(req)) })
-			r.Put("/{id}", func(w http.ResponseWriter, req *http.Request) { taskCtrl.updateTask(w, req, getTaskID(req)) })
-			r.Patch("/{id}", func(w http.ResponseWriter, req *http.Request) { taskCtrl.updateTask(w, req, getTaskID(req)) })
-			r.Delete("/{id}", func(w http.ResponseWriter, req *http.Request) { taskCtrl.deleteTask(w, req, getTaskID(req)) })
-			r.Patch("/{id}/enable", func(w http.ResponseWriter, req *http.Request) {
-				taskCtrl.updateStatus(w, req, getTaskID(req), bizConsts.ENABLED)
-			})
-			r.Patch("/{id}/disable", func(w http.ResponseWriter, req *http.Request) {
-				taskCtrl.updateStatus(w, req, getTaskID(req), bizConsts.DISABLED)
-			})
-			r.Post("/{id}/trigger", func(w http.ResponseWriter, req *http.Request) { taskCtrl.triggerTask(w, req, getTaskID(req)) })
-			// migrated run listing
-			r.Get("/{id}/runs", func(w http.ResponseWriter, req *http.Request) { runCtrl.listRunsByTask(w, req, getTaskID(req)) })
-			r.Get("/{id}/runs/stats", func(w http.ResponseWriter, req *http.Request) { runCtrl.taskRunStats(w, req, getTaskID(req)) })
+	// Backfill routes
+	r.Route("/api/v1/backfills", func(r chi.Router) {
+		getBackfillID := func(r *http.Request) int64 {
+			var id int64
+			_, _ = fmt.Sscanf(chi.URLParam(r, "id"), "%d", &id)
+			return id
+		}
+		r.Get("/{id}", func(w http.ResponseWriter, req *http.Request) { backfillCtrl.getBackfill(w, req, getBackfillID(req)) })
+		r.Get("/{id}/runs", func(w http.ResponseWriter, req *http.Request) {
+			backfillCtrl.listBackfillRuns(w, req, getBackfillID(req))
+		})
+		r.Post("/{id}/cancel", func(w http.ResponseWriter, req *http.Request) {
+			backfillCtrl.cancelBackfill(w, req, getBackfillID(req))
 		})
+	})
 
-		// Run routes
-		r.Route("/api/v1/runs", func(r chi.Router) {
-			getRunID := func(r *http.Request) int64 {
-				var id int64
-				_, _ = fmt.Sscanf(chi.URLParam(r, "id"), "%d", &id)
-				return id
-			}
-			// list all in-memory progress first
-			r.Get("/progress", runCtrl.listAllRunProgress)
-			r.Get("/active", runCtrl.listActiveRuns)
-			r.Get("/summary", runCtrl.summaryRuns)
-			r.Post("/cleanup", runCtrl.cleanupRuns)
-			r.Get("/{id}", func(w http.ResponseWriter, req *http.Request) { runCtrl.getRun(w, req, getRunID(req)) })
-			r.Post("/{id}/cancel", func(w http.ResponseWriter, req *http.Request) { runCtrl.cancelRun(w, req, getRunID(req)) })
-			r.Get("/{id}/progress", func(w http.ResponseWriter, req *http.Request) { runCtrl.getRunProgress(w, req, getRunID(req)) })
-			r.Post("/{id}/progress", func(w http.ResponseWriter, req *http.Request) { runCtrl.setRunProgress(w, req, getRunID(req)) })
-			r.Post("/{id}/callback", func(w http.ResponseWriter, req *http.Request) { runCtrl.finalizeCallback(w, req, getRunID(req)) })
+	// Calendar routes
+	r.Route("/api/v1/calendars", func(r chi.Router) {
+		getName := func(r *http.Request) string { return chi.URLParam(r, "name") }
+		r.Get("/", calendarCtrl.listCalendars)
+		r.Post("/", calendarCtrl.createCalendar)
+		r.Get("/{name}", func(w http.ResponseWriter, req *http.Request) { calendarCtrl.getCalendar(w, req, getName(req)) })
+		r.Put("/{name}", func(w http.ResponseWriter, req *http.Request) { calendarCtrl.putCalendar(w, req, getName(req)) })
+		r.Delete("/{name}", func(w http.ResponseWriter, req *http.Request) { calendarCtrl.deleteCalendar(w, req, getName(req)) })
+		r.Patch("/{name}/dates", func(w http.ResponseWriter, req *http.Request) {
+			calendarCtrl.patchCalendarDates(w, req, getName(req))
+		})
+		r.Get("/{name}/days", func(w http.ResponseWriter, req *http.Request) { calendarCtrl.businessDays(w, req, getName(req)) })
+	})
+	r.Route("/api/v1/blackouts", func(r chi.Router) {
+		r.Get("/", calendarCtrl.listBlackouts)
+		r.Post("/", calendarCtrl.createBlackout)
+		r.Delete("/{id}", func(w http.ResponseWriter, req *http.Request) {
+			var id int64
+			_, _ = fmt.Sscanf(chi.URLParam(req, "id"), "%d", &id)
+			calendarCtrl.deleteBlackout(w, req, id)
 		})
+	})
 
-		// Meta routes
-		r.Route("/api/v1/meta", func(r chi.Router) {
-			r.Get("/clients", metaCtrl.ListClients)
+	// Namespace routes
+	r.Route("/api/v1/namespaces", func(r chi.Router) {
+		getName := func(r *http.Request) string { return chi.URLParam(r, "name") }
+		r.Get("/", namespaceCtrl.listNamespaces)
+		r.Post("/", namespaceCtrl.createNamespace)
+		r.Get("/{name}", func(w http.ResponseWriter, req *http.Request) { namespaceCtrl.getNamespace(w, req, getName(req)) })
+		r.Put("/{name}", func(w http.ResponseWriter, req *http.Request) { namespaceCtrl.putNamespace(w, req, getName(req)) })
+		r.Delete("/{name}", func(w http.ResponseWriter, req *http.Request) { namespaceCtrl.deleteNamespace(w, req, getName(req)) })
+	})
+
+	// Alert routes
+	r.Route("/api/v1/alert-rules", func(r chi.Router) {
+		getRuleID := func(r *http.Request) int64 {
+			var id int64
+			_, _ = fmt.Sscanf(chi.URLParam(r, "id"), "%d", &id)
+			return id
+		}
+		r.Get("/{id}", func(w http.ResponseWriter, req *http.Request) { alertCtrl.getRule(w, req, getRuleID(req)) })
+		r.Put("/{id}", func(w http.ResponseWriter, req *http.Request) { alertCtrl.updateRule(w, req, getRuleID(req)) })
+		r.Delete("/{id}", func(w http.ResponseWriter, req *http.Request) { alertCtrl.deleteRule(w, req, getRuleID(req)) })
+	})
+	r.Route("/api/v1/alerts", func(r chi.Router) {
+		r.Get("/", alertCtrl.listAlerts)
+		r.Get("/channels", alertCtrl.listChannels)
+		r.Post("/channels/{name}/test", func(w http.ResponseWriter, req *http.Request) {
+			alertCtrl.testChannel(w, req, chi.URLParam(req, "name"))
 		})
+	})
+
+	// Analytics routes
+	r.Route("/api/v1/analytics", func(r chi.Router) {
+		r.Get("/tasks", analyticsCtrl.taskStats)
+		r.Get("/daily", analyticsCtrl.daily)
+		r.Get("/top", analyticsCtrl.top)
+		r.Get("/sla", analyticsCtrl.sla)
+	})
 
-		// management/cache ops
-		r.Post("/api/v1/tasks/cache/refresh", func(w http.ResponseWriter, req *http.Request) { taskCtrl.refreshCache(w, req) })
-		// export/import ops
-		r.Get("/api/v1/tasks/export", taskCtrl.ExportTasks)
-		r.Post("/api/v1/tasks/import", taskCtrl.ImportTasks)
-		return nil
+	// Meta routes
+	r.Route("/api/v1/meta", func(r chi.Router) {
+		r.Get("/clients", metaCtrl.ListClients)
+		r.Get("/leader", metaCtrl.LeaderStatus)
 	})
+
+	// Scheduler pause / resume
+	r.Route("/api/v1/scheduler", func(r chi.Router) {
+		r.Get("/pause", metaCtrl.SchedulerPauseStatus)
+		r.Post("/pause", metaCtrl.PauseScheduler)
+		r.Post("/resume", metaCtrl.ResumeScheduler)
+	})
+
+	// management/cache ops
+	r.Post("/api/v1/tasks/cache/refresh", func(w http.ResponseWriter, req *http.Request) { taskCtrl.refreshCache(w, req) })
+	// export/import ops
+	r.Get("/api/v1/tasks/export", taskCtrl.ExportTasks)
+	r.Post("/api/v1/tasks/import", taskCtrl.ImportTasks)
+	// declarative sync ops
+	r.Post("/api/v1/tasks/sync/plan", taskCtrl.syncPlan)
+	r.Post("/api/v1/tasks/sync/apply", taskCtrl.syncApply)
+	return nil
 }
@@ -1,9 +1,13 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -11,6 +15,8 @@ import (
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/render"
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/service"
 )
 
@@ -20,6 +26,10 @@ type RunMgmtController struct {
 	Exec     *service.Executor           `infra:"dep:executor"`
 	Progress *service.RunProgressManager `infra:"dep:run_progress_mgr"`
 	Cleanup  *service.RunCleanupService  `infra:"dep:run_cleanup"`
+	TaskSvc  *service.TaskService        `infra:"dep:task_service"`
+	Dag      *service.DagResolver        `infra:"dep:dag_resolver"`
+	// 批量取消按任务校验归属
+	Namespaces *service.NamespaceService `infra:"dep:namespace_service"`
 }
 
 func NewRunMgmtController() *RunMgmtController {
@@ -105,19 +115,199 @@ func (c *RunMgmtController) getRun(w http.ResponseWriter, r *http.Request, runID
 	writeJSON(w, run)
 }
 
+// runLineage 返回 Run 的上游（触发它的 Run）与下游（由它触发的 Run）。
+func (c *RunMgmtController) runLineage(w http.ResponseWriter, r *http.Request, runID int64) {
+	run, err := c.RunSvc.Get(r.Context(), runID)
+	if err != nil {
+		writeErr(w, 404, err.Error())
+		return
+	}
+	up, down, err := c.RunSvc.ListLineage(r.Context(), runID)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"run": run, "upstream": up, "downstream": down})
+}
+
+// rerunDownstream 以该 Run 的任务与业务日期为起点向下游重跑。
+func (c *RunMgmtController) rerunDownstream(w http.ResponseWriter, r *http.Request, runID int64) {
+	run, err := c.RunSvc.Get(r.Context(), runID)
+	if err != nil {
+		writeErr(w, 404, err.Error())
+		return
+	}
+	if run.LogicalDate == nil {
+		writeErr(w, 400, "run_has_no_logical_date")
+		return
+	}
+	t, err := c.TaskSvc.Get(r.Context(), run.TaskID)
+	if err != nil {
+		writeErr(w, 404, err.Error())
+		return
+	}
+	writeRerun(w, r, c.Dag, t, *run.LogicalDate)
+}
+
+// cancelRun POST /runs/{id}/cancel[?wait=true]
+// 未开始的 Run 直接取消；执行中的 Run 请求下游协作取消，宽限期内未确认的由扫描器标记 KILLED。
+// wait=true 时等待 Run 结束（最多 executor.kill_grace）后返回最终状态。
 func (c *RunMgmtController) cancelRun(w http.ResponseWriter, r *http.Request, runID int64) {
-	c.Exec.CancelRun(runID)
-	// Also mark as canceled in DB for tasks not actively running (e.g., CallbackPending)
-	_ = c.RunSvc.MarkCanceled(r.Context(), runID)
-	// progress cleanup deferred to scanner (removed Clear)
+	run, err := c.RunSvc.Get(r.Context(), runID)
+	if err != nil {
+		writeErr(w, 404, err.Error())
+		return
+	}
+	if run.Status.Finished() {
+		writeJSON(w, map[string]any{"canceled": false, "status": run.Status})
+		return
+	}
+	status := run.Status
+	if r.URL.Query().Get("wait") == "true" {
+		c.Exec.CancelAndWait(r.Context(), []*model.TaskRun{run}, bizConsts.CancelReasonUser)
+		if latest, err := c.RunSvc.Get(r.Context(), runID); err == nil {
+			status = latest.Status
+		}
+	} else if status, err = c.Exec.Cancel(r.Context(), run, bizConsts.CancelReasonUser); err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	// pending_confirmation: 已请求取消，等待下游确认或宽限期后标记 KILLED
+	writeJSON(w, map[string]any{"canceled": true, "status": status, "pending_confirmation": !status.Finished()})
+}
 
-	// Notify the target service (e.g. Artemis) so it can stop the running task.
-	// Best-effort, async — do not block the cancel response.
-	if run, err := c.RunSvc.Get(r.Context(), runID); err == nil && run.TargetService != "" {
-		go c.Exec.CancelRemote(run)
+// bulkCancelLimit 单次批量取消最多处理的 Run 数。
+const bulkCancelLimit = 1000
+
+// activeRunStatuses 可取消的（未结束的）Run 状态。
+var activeRunStatuses = []bizConsts.RunStatus{bizConsts.Queued, bizConsts.Scheduled, bizConsts.Running, bizConsts.CallbackPending}
+
+// bulkCancelResult 批量取消中单个 Run 的结果。
+type bulkCancelResult struct {
+	ID                  int64               `json:"id"`
+	TaskID              int64               `json:"task_id"`
+	OK                  bool                `json:"ok"`
+	Status              bizConsts.RunStatus `json:"status,omitempty"`
+	PendingConfirmation bool                `json:"pending_confirmation,omitempty"`
+	Error               string              `json:"error,omitempty"`
+}
+
+// bulkCancelRuns POST /runs/bulk/cancel
+// {"ids": [..], "task_ids": [..], "namespace": "..", "selector": "..", "statuses": ["RUNNING"], "all": false}
+// 取消满足全部条件的未结束 Run（statuses 默认全部未结束状态）；未给出任何条件时须显式 all=true。
+// 每个 Run 按单个取消的流程处理并返回结果，执行中的 Run 等待下游确认（pending_confirmation）。
+func (c *RunMgmtController) bulkCancelRuns(w http.ResponseWriter, r *http.Request) {
+	ctx := withActor(r)
+	var req struct {
+		IDs       []int64               `json:"ids"`
+		TaskIDs   []int64               `json:"task_ids"`
+		Namespace string                `json:"namespace"`
+		Selector  string                `json:"selector"`
+		Statuses  []bizConsts.RunStatus `json:"statuses"`
+		All       bool                  `json:"all"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	sel, err := model.ParseLabelSelector(req.Selector)
+	if err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	req.Namespace = strings.TrimSpace(req.Namespace)
+	if len(req.IDs) == 0 && len(req.TaskIDs) == 0 && req.Namespace == "" && len(sel) == 0 && !req.All {
+		writeErr(w, 400, "ids, task_ids, namespace or selector required (or all=true)")
+		return
+	}
+	if len(req.IDs) > bulkCancelLimit {
+		writeErr(w, 400, fmt.Sprintf("at most %d ids", bulkCancelLimit))
+		return
+	}
+	for _, st := range req.Statuses {
+		if !slices.Contains(activeRunStatuses, st) {
+			writeErr(w, 400, fmt.Sprintf("status %s is not cancelable", st))
+			return
+		}
+	}
+	statuses := req.Statuses
+	if len(statuses) == 0 {
+		statuses = activeRunStatuses
+	}
+	var runs []*model.TaskRun
+	if len(req.IDs) > 0 {
+		runs, err = c.RunSvc.ListByIDs(ctx, req.IDs)
+	} else {
+		runs, err = c.RunSvc.ListActiveFiltered(ctx, statuses, nil, nil, bulkCancelLimit, 0, "")
+	}
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	// 选择器按任务标签匹配：先解析出满足条件的任务
+	var selected map[int64]struct{}
+	if len(sel) > 0 {
+		tasks, err := c.TaskSvc.ListFiltered(ctx, &model.TaskListFilters{Namespace: req.Namespace, Selector: sel}, 0, 0)
+		if err != nil {
+			writeErr(w, 500, err.Error())
+			return
+		}
+		selected = make(map[int64]struct{}, len(tasks))
+		for _, t := range tasks {
+			selected[t.ID] = struct{}{}
+		}
 	}
+	found := make(map[int64]struct{}, len(runs))
+	items := make([]bulkCancelResult, 0, len(runs))
+	for _, run := range runs {
+		found[run.ID] = struct{}{}
+		if len(req.TaskIDs) > 0 && !slices.Contains(req.TaskIDs, run.TaskID) {
+			continue
+		}
+		if req.Namespace != "" && run.Namespace != req.Namespace {
+			continue
+		}
+		if _, ok := selected[run.TaskID]; selected != nil && !ok {
+			continue
+		}
+		items = append(items, c.cancelOne(ctx, run, statuses))
+	}
+	for _, id := range req.IDs {
+		if _, ok := found[id]; !ok {
+			items = append(items, bulkCancelResult{ID: id, Error: "not_found"})
+		}
+	}
+	canceled := 0
+	for _, it := range items {
+		if it.OK {
+			canceled++
+		}
+	}
+	logging.Info(ctx, fmt.Sprintf("bulk cancel by %s: ids=%d task_ids=%d namespace=%q selector=%q matched=%d canceled=%d",
+		service.ActorFrom(ctx), len(req.IDs), len(req.TaskIDs), req.Namespace, req.Selector, len(items), canceled))
+	writeJSON(w, map[string]any{"items": items, "matched": len(items), "canceled": canceled, "truncated": len(req.IDs) == 0 && len(runs) == bulkCancelLimit})
+}
 
-	writeJSON(w, map[string]any{"canceled": true})
+// cancelOne 批量取消中的单个 Run：已结束或不在 statuses 中的 Run 不取消，未通过任务归属校验的返回 forbidden。
+func (c *RunMgmtController) cancelOne(ctx context.Context, run *model.TaskRun, statuses []bizConsts.RunStatus) bulkCancelResult {
+	res := bulkCancelResult{ID: run.ID, TaskID: run.TaskID, Status: run.Status}
+	if !slices.Contains(statuses, run.Status) {
+		res.Error = "not_cancelable"
+		return res
+	}
+	if t, err := c.TaskSvc.Get(ctx, run.TaskID); err == nil && c.Namespaces.Authorize(ctx, t) != nil {
+		res.Error = "forbidden"
+		return res
+	}
+	st, err := c.Exec.Cancel(ctx, run, bizConsts.CancelReasonUser)
+	res.Status = st
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.OK = true
+	res.PendingConfirmation = !st.Finished()
+	return res
 }
 
 func (c *RunMgmtController) getRunProgress(w http.ResponseWriter, r *http.Request, runID int64) {
@@ -125,7 +315,11 @@ func (c *RunMgmtController) getRunProgress(w http.ResponseWriter, r *http.Reques
 		writeJSON(w, map[string]any{"run_id": runID, "percent": 0, "message": "progress_not_enabled"})
 		return
 	}
-	p := c.Progress.Get(runID)
+	p, err := c.Progress.Get(r.Context(), runID)
+	if err != nil {
+		writeErr(w, 404, "run_not_found")
+		return
+	}
 	if p == nil {
 		writeJSON(w, map[string]any{"run_id": runID, "percent": 0, "message": "no_progress"})
 		return
@@ -168,16 +362,29 @@ func (c *RunMgmtController) setRunProgress(w http.ResponseWriter, r *http.Reques
 		writeErr(w, 400, "run_not_active")
 		return
 	}
-	c.Progress.Set(runID, req.Current, req.Total, req.Message)
+	if _, err := c.Progress.Set(r.Context(), runID, req.Current, req.Total, req.Message); err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
 	writeJSON(w, map[string]any{"updated": true})
 }
 
-func (c *RunMgmtController) finalizeCallback(w http.ResponseWriter, r *http.Request, runID int64) {
+// 日志上报限制：单次行数与单行字节数（超出截断）。
+const (
+	maxLogLinesPerPost = 1000
+	maxLogLineBytes    = 8 << 10
+)
+
+var logLevels = map[string]bool{"DEBUG": true, "INFO": true, "WARN": true, "ERROR": true}
+
+// appendRunLogs POST /api/v1/runs/{id}/logs 追加 Run 日志，须携带 callback token（同回调）。
+// 请求体：{"level":"INFO","lines":["...","..."]}，单行也可写 {"line":"..."}。
+func (c *RunMgmtController) appendRunLogs(w http.ResponseWriter, r *http.Request, runID int64) {
 	var req struct {
-		Result string `json:"result"`
-		Code   int    `json:"code"`
-		Body   string `json:"body"`
-		Error  string `json:"error_message"`
+		Level string   `json:"level"`
+		Line  string   `json:"line"`
+		Lines []string `json:"lines"`
+		Token string   `json:"callback_token"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeErr(w, 400, err.Error())
@@ -188,33 +395,230 @@ func (c *RunMgmtController) finalizeCallback(w http.ResponseWriter, r *http.Requ
 		writeErr(w, 404, "run_not_found")
 		return
 	}
-	if run.Status != bizConsts.CallbackPending {
-		writeErr(w, 400, "run_not_in_callback_pending")
+	if !run.VerifyCallbackToken(defaultOr(r.Header.Get(bizConsts.CALLBACK_TOKEN_HEADER), req.Token)) {
+		writeErr(w, 403, "invalid_callback_token")
+		return
+	}
+	level := strings.ToUpper(defaultOr(strings.TrimSpace(req.Level), "INFO"))
+	if !logLevels[level] {
+		writeErr(w, 400, "invalid_level")
+		return
+	}
+	if req.Line != "" {
+		req.Lines = append(req.Lines, req.Line)
+	}
+	if len(req.Lines) == 0 {
+		writeErr(w, 400, "lines_required")
+		return
+	}
+	if len(req.Lines) > maxLogLinesPerPost {
+		writeErr(w, 400, fmt.Sprintf("too_many_lines: max %d", maxLogLinesPerPost))
 		return
 	}
-	// If the request payload is valid and accepted, we respond 200 even if internal processing fails.
-	var svcErr error
-	switch strings.ToLower(strings.TrimSpace(req.Result)) {
-	case "success":
-		if req.Code == 0 {
-			req.Code = 200
+	now := time.Now()
+	logs := make([]*model.RunLog, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		if len(line) > maxLogLineBytes {
+			line = strings.ToValidUTF8(line[:maxLogLineBytes], "") + "...(truncated)"
 		}
-		svcErr = c.RunSvc.MarkSuccess(r.Context(), runID, req.Code, req.Body)
-	case "failed_timeout":
-		svcErr = c.RunSvc.MarkFailedTimeout(r.Context(), runID, defaultOr(req.Error, "callback_deadline_exceeded"))
-	case "failed":
-		svcErr = c.RunSvc.MarkCallbackFailed(r.Context(), runID, defaultOr(req.Error, "callback_failed"))
-	default:
-		writeErr(w, 400, "invalid_result")
+		logs = append(logs, &model.RunLog{TaskRunID: runID, Ts: now, Level: level, Line: line})
+	}
+	if err := c.RunSvc.AppendLogs(r.Context(), runID, logs); err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"appended": len(logs), "last_id": logs[len(logs)-1].ID})
+}
+
+// listRunLogs GET /api/v1/runs/{id}/logs?after_id=0&limit=500 按 ID 升序分页读取日志。
+func (c *RunMgmtController) listRunLogs(w http.ResponseWriter, r *http.Request, runID int64) {
+	q := r.URL.Query()
+	afterID, _ := strconv.ParseInt(q.Get("after_id"), 10, 64)
+	limit := 500
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil && v > 0 && v <= 5000 {
+		limit = v
+	}
+	list, err := c.RunSvc.ListLogs(r.Context(), runID, afterID, limit)
+	if err != nil {
+		writeErr(w, 500, err.Error())
 		return
 	}
-	// progress cleanup deferred to scanner (removed Clear)
-	if svcErr != nil {
-		// Return 200 with an error payload to indicate the callback was accepted but internal processing failed
-		writeJSON(w, map[string]any{"updated": false, "error": svcErr.Error()})
+	next := afterID
+	if len(list) > 0 {
+		next = list[len(list)-1].ID
+	}
+	writeJSON(w, map[string]any{"items": list, "limit": limit, "next_after_id": next})
+}
+
+// SSE：本副本内的变化即时推送，其他副本的变化最迟 ssePollInterval 后送达；空闲时发送心跳注释防止代理断开。
+const (
+	ssePollInterval = time.Second
+	sseHeartbeat    = 15 * time.Second
+)
+
+// streamRunEvents GET /api/v1/runs/{id}/events 以 Server-Sent Events 推送 Run 的状态变化（status）、
+// 进度（progress）与日志（log，id 为日志 ID）；Run 结束后发送 end 并关闭。
+// 断线重连时浏览器自动带上 Last-Event-ID，从该日志之后继续；也可用 ?after_id= 指定。
+func (c *RunMgmtController) streamRunEvents(w http.ResponseWriter, r *http.Request, runID int64) {
+	ctx := r.Context()
+	if _, err := c.RunSvc.Get(ctx, runID); err != nil {
+		writeErr(w, 404, "run_not_found")
 		return
 	}
-	writeJSON(w, map[string]any{"updated": true})
+	afterID, _ := strconv.ParseInt(defaultOr(r.Header.Get("Last-Event-ID"), r.URL.Query().Get("after_id")), 10, 64)
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{}) // 长连接不受 http_server.write_timeout 限制
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+	_ = rc.Flush()
+
+	wake, unsubscribe := c.RunSvc.Subscribe(runID)
+	defer unsubscribe()
+	watcher := c.RunSvc.Watch(runID, afterID)
+	poll := time.NewTicker(ssePollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		events, err := watcher.Poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			logging.Warn(ctx, fmt.Sprintf("run %d events poll failed: %v", runID, err))
+		}
+		for _, ev := range events {
+			if err := writeSSE(w, ev); err != nil {
+				return
+			}
+		}
+		if len(events) > 0 {
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			heartbeat.Reset(sseHeartbeat)
+		}
+		if watcher.Done() {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-poll.C:
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, ev service.RunEvent) error {
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		return err
+	}
+	if ev.ID != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", ev.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+	return err
+}
+
+// finalizeCallback POST /api/v1/runs/{id}/callback 异步任务上报结果。
+// 须携带 Run 的 callback token（请求头 X-Cronjob-Callback-Token 或请求体 callback_token）；
+// 每次请求都记入 async_callbacks。重复回调返回 200 且不修改 Run，与已结束状态冲突的回调返回 409。
+func (c *RunMgmtController) finalizeCallback(w http.ResponseWriter, r *http.Request, runID int64) {
+	run, err := c.RunSvc.Get(r.Context(), runID)
+	if err != nil {
+		writeErr(w, 404, "run_not_found")
+		return
+	}
+	raw, _ := io.ReadAll(io.LimitReader(r.Body, maxCallbackBody))
+	var req struct {
+		Result string `json:"result"`
+		Code   int    `json:"code"`
+		Body   string `json:"body"`
+		Error  string `json:"error_message"`
+		Token  string `json:"callback_token"`
+	}
+	_ = json.Unmarshal(raw, &req) // 解析失败时 result 为空，按 invalid_payload 记录
+	token := r.Header.Get(bizConsts.CALLBACK_TOKEN_HEADER)
+	if token == "" {
+		token = req.Token
+	}
+	rec := &model.AsyncCallback{ReceivedAt: time.Now(), HeadersJSON: callbackHeaders(r), Body: string(raw), SourceIP: sourceIP(r)}
+	res := &service.CallbackResult{Result: req.Result, Code: req.Code, Body: req.Body, Error: req.Error}
+	outcome, svcErr := c.RunSvc.FinalizeCallback(r.Context(), run, token, res, rec)
+	if outcome != bizConsts.CallbackAccepted {
+		logging.Warn(r.Context(), fmt.Sprintf("callback for run %d from %s: %s (run status %s)", runID, rec.SourceIP, outcome, run.Status))
+	}
+	switch outcome {
+	case bizConsts.CallbackAccepted:
+		if done, err := c.RunSvc.Get(r.Context(), runID); err == nil {
+			c.Exec.ObserveAsyncResult(r.Context(), done, rec.ReceivedAt, true)
+		}
+		writeJSON(w, map[string]any{"updated": true})
+	case bizConsts.CallbackDuplicate:
+		writeJSON(w, map[string]any{"updated": false, "duplicate": true, "status": run.Status})
+	case bizConsts.CallbackInvalidToken:
+		writeErr(w, 403, "invalid_callback_token")
+	case bizConsts.CallbackInvalidPayload:
+		writeErr(w, 400, "invalid_result")
+	case bizConsts.CallbackConflict:
+		writeErr(w, 409, fmt.Sprintf("run_already_finalized: %s", run.Status))
+	case bizConsts.CallbackNotPending:
+		writeErr(w, 400, "run_not_in_callback_pending")
+	default:
+		// The callback was accepted but internal processing failed
+		writeJSON(w, map[string]any{"updated": false, "error": fmt.Sprint(svcErr)})
+	}
+}
+
+// maxCallbackBody 回调请求体上限（超出部分截断）。
+const maxCallbackBody = 1 << 20
+
+// callbackHeaders 记录用的请求头：去掉 token 与认证类头。
+func callbackHeaders(r *http.Request) string {
+	h := map[string]string{}
+	for k, v := range r.Header {
+		switch http.CanonicalHeaderKey(k) {
+		case bizConsts.CALLBACK_TOKEN_HEADER, "Authorization", "Cookie":
+			h[k] = render.Mask
+		default:
+			h[k] = strings.Join(v, ", ")
+		}
+	}
+	b, _ := json.Marshal(h)
+	return string(b)
+}
+
+// sourceIP 连接的对端地址；经代理时原始地址见记录中的 X-Forwarded-For。
+func sourceIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// listCallbacks GET /api/v1/runs/{id}/callbacks 回调记录（最新在前）。
+func (c *RunMgmtController) listCallbacks(w http.ResponseWriter, r *http.Request, runID int64) {
+	limit := 50
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 && v <= 500 {
+		limit = v
+	}
+	list, err := c.RunSvc.ListCallbacks(r.Context(), runID, limit)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"items": list, "limit": limit})
 }
 
 func (c *RunMgmtController) summaryRuns(w http.ResponseWriter, r *http.Request) {
@@ -225,7 +629,7 @@ func (c *RunMgmtController) summaryRuns(w http.ResponseWriter, r *http.Request)
 	}
 	// gather terminal stats per task
 	result := make(map[string]any)
-	terminal := []bizConsts.RunStatus{bizConsts.Success, bizConsts.Failed, bizConsts.FailedTimeout, bizConsts.Canceled}
+	terminal := []bizConsts.RunStatus{bizConsts.Success, bizConsts.Failed, bizConsts.FailedTimeout, bizConsts.Canceled, bizConsts.Killed}
 	// simple aggregate: total runs and terminal percentages per task
 	aggregates := make(map[int64]map[string]any)
 	for taskID, total := range counts {
@@ -293,7 +697,7 @@ func (c *RunMgmtController) cleanupRuns(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, map[string]any{"deleted": deleted})
 }
 
-var allowedStatuses = map[bizConsts.RunStatus]struct{}{bizConsts.Scheduled: {}, bizConsts.Running: {}, bizConsts.Success: {}, bizConsts.Failed: {}, bizConsts.Timeout: {}, bizConsts.Retrying: {}, bizConsts.CallbackPending: {}, bizConsts.CallbackFailed: {}, bizConsts.FailedTimeout: {}, bizConsts.Canceled: {}, bizConsts.Skipped: {}, bizConsts.FailureSkip: {}, bizConsts.ConcurrentSkip: {}, bizConsts.OverlapSkip: {}}
+var allowedStatuses = map[bizConsts.RunStatus]struct{}{bizConsts.Scheduled: {}, bizConsts.Queued: {}, bizConsts.Running: {}, bizConsts.Success: {}, bizConsts.Failed: {}, bizConsts.Timeout: {}, bizConsts.Retrying: {}, bizConsts.CallbackPending: {}, bizConsts.CallbackFailed: {}, bizConsts.FailedTimeout: {}, bizConsts.Canceled: {}, bizConsts.Killed: {}, bizConsts.Skipped: {}, bizConsts.FailureSkip: {}, bizConsts.ConcurrentSkip: {}, bizConsts.OverlapSkip: {}}
 
 // validate statuses; returns slice or error
 func validateStatuses(list []bizConsts.RunStatus) ([]bizConsts.RunStatus, error) {
@@ -351,6 +755,10 @@ func (c *RunMgmtController) listAllRunProgress(w http.ResponseWriter, r *http.Re
 		writeJSON(w, map[string]any{"items": []any{}, "enabled": false})
 		return
 	}
-	list := c.Progress.List()
+	list, err := c.Progress.List(r.Context(), 1000)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
 	writeJSON(w, map[string]any{"items": list, "enabled": true, "count": len(list)})
 }
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/service"
+)
+
+// 批量任务操作（如上游维护窗口前后停用 / 启用一批任务）：
+//   - 按 ids 或范围（namespace / selector）选择任务，至少指定其一；同时指定时 ids 中不在范围内的任务返回 not_matched
+//   - 逐个校验归属（forbidden）与托管（task_managed，仅启停与删除），单个任务失败不影响其他任务
+//   - 返回每个任务的结果 items，以及匹配数 matched、成功数 succeeded
+
+// bulkMaxIDs 单次请求最多指定的任务 ID 数。
+const bulkMaxIDs = 1000
+
+// bulkAction 批量任务操作。
+type bulkAction string
+
+const (
+	bulkEnable  bulkAction = "enable"
+	bulkDisable bulkAction = "disable"
+	bulkTrigger bulkAction = "trigger"
+	bulkDelete  bulkAction = "delete"
+)
+
+// bulkTaskReq 批量任务操作的选择条件。
+type bulkTaskReq struct {
+	IDs       []int64 `json:"ids"`
+	Namespace string  `json:"namespace"`
+	Selector  string  `json:"selector"` // 标签选择器，如 team=quant,env!=prod
+}
+
+// bulkResult 批量操作中单个任务的结果。
+type bulkResult struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name,omitempty"`
+	OK    bool   `json:"ok"`
+	RunID int64  `json:"run_id,omitempty"` // trigger 创建的 Run
+	Error string `json:"error,omitempty"`
+}
+
+// bulkTasks POST /api/v1/tasks/bulk/{enable|disable|trigger|delete}
+// {"ids": [1, 2]} 或 {"namespace": "...", "selector": "team=quant"}，逐个执行并返回每个任务的结果。
+func (tmc *TaskMgmtController) bulkTasks(w http.ResponseWriter, r *http.Request, action bulkAction) {
+	ctx := withActor(r)
+	var req bulkTaskReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	sel, err := model.ParseLabelSelector(req.Selector)
+	if err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	filters := &model.TaskListFilters{Namespace: strings.TrimSpace(req.Namespace), Selector: sel}
+	if len(req.IDs) == 0 && filters.Namespace == "" && len(sel) == 0 {
+		writeErr(w, 400, "ids, namespace or selector required")
+		return
+	}
+	if len(req.IDs) > bulkMaxIDs {
+		writeErr(w, 400, fmt.Sprintf("at most %d ids", bulkMaxIDs))
+		return
+	}
+	tasks, items, err := tmc.selectBulkTasks(ctx, req.IDs, filters)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	for _, t := range tasks {
+		items = append(items, tmc.applyBulk(ctx, t, action))
+	}
+	succeeded := 0
+	for _, it := range items {
+		if it.OK {
+			succeeded++
+		}
+	}
+	logging.Info(ctx, fmt.Sprintf("bulk %s by %s: ids=%d namespace=%q selector=%q matched=%d succeeded=%d",
+		action, service.ActorFrom(ctx), len(req.IDs), filters.Namespace, req.Selector, len(tasks), succeeded))
+	writeJSON(w, map[string]any{"action": action, "items": items, "matched": len(tasks), "succeeded": succeeded})
+}
+
+// selectBulkTasks 解析要操作的任务；指定 ids 时不存在或不在范围内的任务直接作为失败项返回。
+func (tmc *TaskMgmtController) selectBulkTasks(ctx context.Context, ids []int64, f *model.TaskListFilters) ([]*model.Task, []bulkResult, error) {
+	if len(ids) == 0 {
+		tasks, err := tmc.TaskSvc.ListFiltered(ctx, f, 0, 0)
+		return tasks, make([]bulkResult, 0, len(tasks)), err
+	}
+	var (
+		tasks  []*model.Task
+		failed []bulkResult
+		seen   = make(map[int64]struct{}, len(ids))
+	)
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		t, err := tmc.TaskSvc.Get(ctx, id)
+		switch {
+		case err != nil:
+			failed = append(failed, bulkResult{ID: id, Error: "not_found"})
+		case !inScope(t, f):
+			failed = append(failed, bulkResult{ID: id, Name: t.Name, Error: "not_matched"})
+		default:
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, failed, nil
+}
+
+// inScope 任务是否在 namespace / selector 范围内。
+func inScope(t *model.Task, f *model.TaskListFilters) bool {
+	ns := t.Namespace
+	if ns == "" {
+		ns = bizConsts.DEFAULT_NAMESPACE
+	}
+	return (f.Namespace == "" || f.Namespace == ns) && f.Selector.Matches(t.Labels)
+}
+
+// applyBulk 对单个任务执行批量操作。
+func (tmc *TaskMgmtController) applyBulk(ctx context.Context, t *model.Task, action bulkAction) bulkResult {
+	res := bulkResult{ID: t.ID, Name: t.Name}
+	switch {
+	case tmc.Namespaces.Authorize(ctx, t) != nil:
+		res.Error = "forbidden"
+	case t.Managed && action != bulkTrigger:
+		res.Error = "task_managed"
+	case action == bulkEnable || action == bulkDisable:
+		status := bizConsts.ENABLED
+		if action == bulkDisable {
+			status = bizConsts.DISABLED
+		}
+		if t.Status != status {
+			if err := tmc.TaskSvc.UpdateStatus(ctx, t.ID, status); err != nil {
+				res.Error = err.Error()
+			}
+		}
+	case action == bulkDelete:
+		if err := tmc.TaskSvc.SoftDelete(ctx, t.ID); err != nil {
+			res.Error = err.Error()
+		}
+	case action == bulkTrigger:
+		run, err := tmc.trigger(ctx, t)
+		var te *triggerError
+		switch {
+		case errors.As(err, &te):
+			res.Error = te.code
+		case err != nil:
+			res.Error = err.Error()
+		default:
+			res.RunID = run.ID
+		}
+	}
+	res.OK = res.Error == ""
+	return res
+}
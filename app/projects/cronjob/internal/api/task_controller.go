@@ -1,9 +1,13 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -14,17 +18,23 @@ import (
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/render"
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/service"
 )
 
 type TaskMgmtController struct {
 	*core.BaseComponent
-	TaskSvc  *service.TaskService        `infra:"dep:task_service"`
-	RunSvc   *service.RunService         `infra:"dep:run_service"`
-	Exec     *service.Executor           `infra:"dep:executor"`
-	Sched    *service.Engine             `infra:"dep:scheduler_engine"`
-	Progress *service.RunProgressManager `infra:"dep:run_progress_mgr"` // ephemeral progress store
+	TaskSvc    *service.TaskService        `infra:"dep:task_service"`
+	RunSvc     *service.RunService         `infra:"dep:run_service"`
+	Exec       *service.Executor           `infra:"dep:executor"`
+	Sched      *service.Engine             `infra:"dep:scheduler_engine"`
+	Progress   *service.RunProgressManager `infra:"dep:run_progress_mgr"` // ephemeral progress store
+	Dag        *service.DagResolver        `infra:"dep:dag_resolver"`
+	Calendars  *service.CalendarService    `infra:"dep:calendar_service"`
+	Namespaces *service.NamespaceService   `infra:"dep:namespace_service"`
 }
 
 func NewTaskMgmtController() *TaskMgmtController {
@@ -32,27 +42,42 @@ func NewTaskMgmtController() *TaskMgmtController {
 }
 
 func (tmc *TaskMgmtController) createTask(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx := withActor(r)
 	var req struct {
-		Name               string `json:"name"`
-		Description        string `json:"description"`
-		CronExpr           string `json:"cron_expr"`
-		Timezone           string `json:"timezone"`
-		ExecType           string `json:"exec_type"`
-		HTTPMethod         string `json:"method"` // 修改 JSON tag 为 "method"
-		TargetService      string `json:"target_service"`
-		TargetPath         string `json:"target_path"`
-		HeadersJSON        string `json:"headers_json"`
-		BodyTemplate       string `json:"body_template"`
-		RetryPolicyJSON    string `json:"retry_policy_json"`
-		MaxConcurrency     int    `json:"max_concurrency"`
-		ConcurrencyPolicy  string `json:"concurrency_policy"`
-		CallbackMethod     string `json:"callback_method"`
-		CallbackTimeoutSec int    `json:"callback_timeout_sec"`
-		OverlapAction      string `json:"overlap_action"`
-		FailureAction      string `json:"failure_action"`
-		Status             string `json:"status"`
-		Deleted            int    `json:"deleted"`
+		Name                string            `json:"name"`
+		Description         string            `json:"description"`
+		Namespace           string            `json:"namespace"`
+		Owners              []string          `json:"owners"`
+		Labels              map[string]string `json:"labels"`
+		CronExpr            string            `json:"cron_expr"`
+		Timezone            string            `json:"timezone"`
+		ExecType            string            `json:"exec_type"`
+		Executor            string            `json:"executor"`
+		ExecutorConfig      string            `json:"executor_config"`
+		HTTPMethod          string            `json:"method"` // 修改 JSON tag 为 "method"
+		TargetService       string            `json:"target_service"`
+		TargetPath          string            `json:"target_path"`
+		HeadersJSON         string            `json:"headers_json"`
+		BodyTemplate        string            `json:"body_template"`
+		RetryPolicyJSON     string            `json:"retry_policy_json"`
+		MaxConcurrency      int               `json:"max_concurrency"`
+		ConcurrencyPolicy   string            `json:"concurrency_policy"`
+		QueueMaxDepth       int               `json:"queue_max_depth"`
+		QueueOverflow       string            `json:"queue_overflow"`
+		CallbackMethod      string            `json:"callback_method"`
+		CallbackTimeoutSec  int               `json:"callback_timeout_sec"`
+		ExecutionTimeoutSec int               `json:"execution_timeout_sec"`
+		OverlapAction       string            `json:"overlap_action"`
+		FailureAction       string            `json:"failure_action"`
+		MisfirePolicy       string            `json:"misfire_policy"`
+		MisfireGraceSec     int               `json:"misfire_grace_sec"`
+		ScheduleMode        string            `json:"schedule_mode"`
+		TriggerRule         string            `json:"trigger_rule"`
+		Calendar            string            `json:"calendar"`
+		CalendarMode        string            `json:"calendar_mode"`
+		UpstreamTaskIDs     []int64           `json:"upstream_task_ids"`
+		Status              string            `json:"status"`
+		Deleted             int               `json:"deleted"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		logging.Error(ctx, fmt.Sprintf("Task creation json decode failed: %v", err))
@@ -61,37 +86,86 @@ func (tmc *TaskMgmtController) createTask(w http.ResponseWriter, r *http.Request
 	}
 
 	t := &model.Task{
-		Name:               strings.TrimSpace(req.Name),
-		Description:        req.Description,
-		CronExpr:           model.NormalizeCron(req.CronExpr),
-		Timezone:           defaultOr(req.Timezone, "UTC"),
-		ExecType:           bizConsts.ExecType(req.ExecType),
-		HTTPMethod:         strings.ToUpper(req.HTTPMethod),
-		TargetService:      defaultOr(req.TargetService, "artemis"),
-		TargetPath:         req.TargetPath,
-		HeadersJSON:        defaultOr(req.HeadersJSON, bizConsts.DEFAULT_JSON_STR),
-		BodyTemplate:       req.BodyTemplate,
-		RetryPolicyJSON:    defaultOr(req.RetryPolicyJSON, bizConsts.DEFAULT_JSON_STR),
-		MaxConcurrency:     defaultInt(req.MaxConcurrency, 1),
-		ConcurrencyPolicy:  bizConsts.ConcurrencyPolicy(req.ConcurrencyPolicy),
-		CallbackMethod:     defaultOr(req.CallbackMethod, "POST"),
-		CallbackTimeoutSec: defaultInt(req.CallbackTimeoutSec, 300),
-		OverlapAction:      bizConsts.OverlapAction(req.OverlapAction),
-		FailureAction:      bizConsts.FailureAction(req.FailureAction),
-		Status:             bizConsts.DISABLED,
-		Version:            1,
+		Name:                strings.TrimSpace(req.Name),
+		Description:         req.Description,
+		Namespace:           defaultOr(strings.TrimSpace(req.Namespace), bizConsts.DEFAULT_NAMESPACE),
+		Owners:              normalizeNames(req.Owners),
+		Labels:              req.Labels,
+		CronExpr:            model.NormalizeCron(req.CronExpr),
+		Timezone:            defaultOr(req.Timezone, "UTC"),
+		ExecType:            bizConsts.ExecType(req.ExecType),
+		Executor:            bizConsts.ExecutorKind(strings.ToUpper(req.Executor)),
+		ExecutorConfig:      req.ExecutorConfig,
+		HTTPMethod:          strings.ToUpper(req.HTTPMethod),
+		TargetService:       req.TargetService,
+		TargetPath:          req.TargetPath,
+		HeadersJSON:         defaultOr(req.HeadersJSON, bizConsts.DEFAULT_JSON_STR),
+		BodyTemplate:        req.BodyTemplate,
+		RetryPolicyJSON:     defaultOr(req.RetryPolicyJSON, bizConsts.DEFAULT_JSON_STR),
+		MaxConcurrency:      defaultInt(req.MaxConcurrency, 1),
+		ConcurrencyPolicy:   bizConsts.ConcurrencyPolicy(strings.ToUpper(req.ConcurrencyPolicy)),
+		QueueMaxDepth:       req.QueueMaxDepth,
+		QueueOverflow:       bizConsts.QueueOverflow(strings.ToUpper(req.QueueOverflow)),
+		CallbackMethod:      defaultOr(req.CallbackMethod, "POST"),
+		CallbackTimeoutSec:  defaultInt(req.CallbackTimeoutSec, 300),
+		ExecutionTimeoutSec: req.ExecutionTimeoutSec,
+		OverlapAction:       bizConsts.OverlapAction(req.OverlapAction),
+		FailureAction:       bizConsts.FailureAction(req.FailureAction),
+		MisfirePolicy:       bizConsts.MisfirePolicy(strings.ToUpper(req.MisfirePolicy)),
+		MisfireGraceSec:     req.MisfireGraceSec,
+		ScheduleMode:        bizConsts.ScheduleMode(strings.ToUpper(req.ScheduleMode)),
+		TriggerRule:         bizConsts.TriggerRule(strings.ToUpper(req.TriggerRule)),
+		Calendar:            strings.TrimSpace(req.Calendar),
+		CalendarMode:        bizConsts.CalendarMode(strings.ToUpper(req.CalendarMode)),
+		Status:              bizConsts.DISABLED,
+		Version:             1,
 		//CreatedAt:          time.Now().UTC(),
 		//UpdatedAt:          time.Now().UTC(),
 	}
-	if t.CronExpr == "" || t.Name == "" || t.TargetService == "" || t.TargetPath == "" {
-		writeErr(w, 400, "CronExpr/Name/TargetService/TargetPath cannot be empty")
+	applyExecutorDefaults(t)
+	if (t.CronExpr == "" && t.UsesCron()) || t.Name == "" || t.TargetPath == "" {
+		writeErr(w, 400, "CronExpr/Name/TargetPath cannot be empty")
+		return
+	}
+	if rejectForbidden(w, tmc.Namespaces.AuthorizeNamespace(ctx, t.Namespace)) {
+		return
+	}
+	if err := validateTask(t); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	if err := tmc.checkCalendar(t); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	if err := tmc.Exec.CheckBackend(t); err != nil {
+		writeErr(w, 400, err.Error())
 		return
 	}
-	if err := tmc.TaskSvc.Create(r.Context(), t); err != nil {
+	if err := tmc.checkTemplates(t); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	if err := tmc.checkDependencies(ctx, t, req.UpstreamTaskIDs); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	if err := tmc.checkNamespace(ctx, t, nil); err != nil {
+		writeNamespaceErr(w, err)
+		return
+	}
+	if err := tmc.TaskSvc.Create(ctx, t); err != nil {
 		logging.Error(ctx, fmt.Sprintf("Task creation failed: %v", err))
 		writeErr(w, 500, err.Error())
 		return
 	}
+	if len(req.UpstreamTaskIDs) > 0 {
+		if err := tmc.TaskSvc.SetUpstreams(ctx, t.ID, req.UpstreamTaskIDs); err != nil {
+			logging.Error(ctx, fmt.Sprintf("Task %d set upstreams failed: %v", t.ID, err))
+			writeErr(w, 500, err.Error())
+			return
+		}
+	}
 	writeJSON(w, map[string]any{"id": t.ID, "name": t.Name})
 }
 
@@ -126,6 +200,10 @@ func (tmc *TaskMgmtController) listTasks(w http.ResponseWriter, r *http.Request)
 	filters.CreatedTo = parseTime("created_to")
 	filters.UpdatedFrom = parseTime("updated_from")
 	filters.UpdatedTo = parseTime("updated_to")
+	if err := parseScopeFilters(q, filters); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
 	limit := 50
 	if v := strings.TrimSpace(q.Get("limit")); v != "" {
 		if i, err := strconv.Atoi(v); err == nil && i > 0 && i <= 500 {
@@ -154,30 +232,53 @@ func (tmc *TaskMgmtController) getTask(w http.ResponseWriter, r *http.Request, i
 		writeErr(w, 404, err.Error())
 		return
 	}
-	writeJSON(w, t)
+	ups, err := tmc.TaskSvc.Upstreams(r.Context(), id)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, struct {
+		*model.Task
+		UpstreamTaskIDs []int64 `json:"upstream_task_ids"`
+	}{t, ups})
 }
 
 func (tmc *TaskMgmtController) updateTask(w http.ResponseWriter, r *http.Request, id int64) {
-	ctx := r.Context()
+	ctx := withActor(r)
 	var req struct {
-		Name               string `json:"name"`
-		Description        string `json:"description"`
-		CronExpr           string `json:"cron_expr"`
-		Timezone           string `json:"timezone"`
-		ExecType           string `json:"exec_type"`
-		HTTPMethod         string `json:"method"` // 修改 JSON tag 为 "method"
-		TargetService      string `json:"target_service"`
-		TargetPath         string `json:"target_path"`
-		HeadersJSON        string `json:"headers_json"`
-		BodyTemplate       string `json:"body_template"`
-		RetryPolicyJSON    string `json:"retry_policy_json"`
-		MaxConcurrency     int    `json:"max_concurrency"`
-		ConcurrencyPolicy  string `json:"concurrency_policy"`
-		CallbackMethod     string `json:"callback_method"`
-		CallbackTimeoutSec int    `json:"callback_timeout_sec"`
-		OverlapAction      string `json:"overlap_action"`
-		FailureAction      string `json:"failure_action"`
-		Status             string `json:"status"`
+		Name                string             `json:"name"`
+		Description         string             `json:"description"`
+		Namespace           string             `json:"namespace"`
+		Owners              *[]string          `json:"owners"` // 为空表示不修改
+		Labels              *map[string]string `json:"labels"` // 为空表示不修改，{} 表示清空
+		CronExpr            string             `json:"cron_expr"`
+		Timezone            string             `json:"timezone"`
+		ExecType            string             `json:"exec_type"`
+		Executor            string             `json:"executor"`
+		ExecutorConfig      *string            `json:"executor_config"` // 为空表示不修改
+		HTTPMethod          string             `json:"method"`          // 修改 JSON tag 为 "method"
+		TargetService       string             `json:"target_service"`
+		TargetPath          string             `json:"target_path"`
+		HeadersJSON         string             `json:"headers_json"`
+		BodyTemplate        string             `json:"body_template"`
+		RetryPolicyJSON     string             `json:"retry_policy_json"`
+		MaxConcurrency      int                `json:"max_concurrency"`
+		ConcurrencyPolicy   string             `json:"concurrency_policy"`
+		QueueMaxDepth       int                `json:"queue_max_depth"`
+		QueueOverflow       string             `json:"queue_overflow"`
+		CallbackMethod      string             `json:"callback_method"`
+		CallbackTimeoutSec  int                `json:"callback_timeout_sec"`
+		ExecutionTimeoutSec *int               `json:"execution_timeout_sec"` // 为空表示不修改，0 表示不限制
+		OverlapAction       string             `json:"overlap_action"`
+		FailureAction       string             `json:"failure_action"`
+		MisfirePolicy       string             `json:"misfire_policy"`
+		MisfireGraceSec     int                `json:"misfire_grace_sec"`
+		ScheduleMode        string             `json:"schedule_mode"`
+		TriggerRule         string             `json:"trigger_rule"`
+		Calendar            *string            `json:"calendar"` // 为空表示不修改，"" 表示取消日历
+		CalendarMode        string             `json:"calendar_mode"`
+		UpstreamTaskIDs     *[]int64           `json:"upstream_task_ids"` // 为空表示不修改
+		Status              string             `json:"status"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		logging.Error(ctx, fmt.Sprintf("Task update json decode failed: %v", err))
@@ -191,12 +292,31 @@ func (tmc *TaskMgmtController) updateTask(w http.ResponseWriter, r *http.Request
 		writeErr(w, 404, err.Error())
 		return
 	}
+	if rejectManaged(w, t) || rejectForbidden(w, tmc.Namespaces.Authorize(ctx, t)) {
+		return
+	}
+	prev := *t
 	if req.Name != "" {
 		t.Name = strings.TrimSpace(req.Name)
 	}
+	if req.Namespace != "" {
+		t.Namespace = strings.TrimSpace(req.Namespace)
+	}
+	if req.Owners != nil {
+		t.Owners = normalizeNames(*req.Owners)
+	}
+	if req.Labels != nil {
+		t.Labels = *req.Labels
+	}
 	if req.ExecType != "" {
 		t.ExecType = bizConsts.ExecType(req.ExecType)
 	}
+	if req.Executor != "" {
+		t.Executor = bizConsts.ExecutorKind(strings.ToUpper(req.Executor))
+	}
+	if req.ExecutorConfig != nil {
+		t.ExecutorConfig = *req.ExecutorConfig
+	}
 	if req.TargetService != "" {
 		t.TargetService = req.TargetService
 	}
@@ -212,11 +332,20 @@ func (tmc *TaskMgmtController) updateTask(w http.ResponseWriter, r *http.Request
 	if req.CronExpr != "" {
 		t.CronExpr = model.NormalizeCron(req.CronExpr)
 	}
+	if req.Timezone != "" {
+		t.Timezone = strings.TrimSpace(req.Timezone)
+	}
 	if req.MaxConcurrency >= 0 {
 		t.MaxConcurrency = req.MaxConcurrency
 	}
 	if req.ConcurrencyPolicy != "" {
-		t.ConcurrencyPolicy = bizConsts.ConcurrencyPolicy(req.ConcurrencyPolicy)
+		t.ConcurrencyPolicy = bizConsts.ConcurrencyPolicy(strings.ToUpper(req.ConcurrencyPolicy))
+	}
+	if req.QueueMaxDepth > 0 {
+		t.QueueMaxDepth = req.QueueMaxDepth
+	}
+	if req.QueueOverflow != "" {
+		t.QueueOverflow = bizConsts.QueueOverflow(strings.ToUpper(req.QueueOverflow))
 	}
 	if req.OverlapAction != "" {
 		t.OverlapAction = bizConsts.OverlapAction(req.OverlapAction)
@@ -224,6 +353,12 @@ func (tmc *TaskMgmtController) updateTask(w http.ResponseWriter, r *http.Request
 	if req.FailureAction != "" {
 		t.FailureAction = bizConsts.FailureAction(req.FailureAction)
 	}
+	if req.MisfirePolicy != "" {
+		t.MisfirePolicy = bizConsts.MisfirePolicy(strings.ToUpper(req.MisfirePolicy))
+	}
+	if req.MisfireGraceSec > 0 {
+		t.MisfireGraceSec = req.MisfireGraceSec
+	}
 	if req.BodyTemplate != "" {
 		t.BodyTemplate = req.BodyTemplate
 	}
@@ -236,16 +371,80 @@ func (tmc *TaskMgmtController) updateTask(w http.ResponseWriter, r *http.Request
 	if req.CallbackTimeoutSec > 0 {
 		t.CallbackTimeoutSec = req.CallbackTimeoutSec
 	}
+	if req.ExecutionTimeoutSec != nil {
+		t.ExecutionTimeoutSec = *req.ExecutionTimeoutSec
+	}
+	if req.RetryPolicyJSON != "" {
+		t.RetryPolicyJSON = req.RetryPolicyJSON
+	}
+	if req.ScheduleMode != "" {
+		t.ScheduleMode = bizConsts.ScheduleMode(strings.ToUpper(req.ScheduleMode))
+	}
+	if req.TriggerRule != "" {
+		t.TriggerRule = bizConsts.TriggerRule(strings.ToUpper(req.TriggerRule))
+	}
+	if req.Calendar != nil {
+		t.Calendar = strings.TrimSpace(*req.Calendar)
+	}
+	if req.CalendarMode != "" {
+		t.CalendarMode = bizConsts.CalendarMode(strings.ToUpper(req.CalendarMode))
+	}
+	applyExecutorDefaults(t)
+	if err := validateTask(t); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	if err := tmc.checkCalendar(t); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	if err := tmc.Exec.CheckBackend(t); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	if err := tmc.checkTemplates(t); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	var ups []int64
+	if req.UpstreamTaskIDs != nil {
+		ups = *req.UpstreamTaskIDs
+	} else if ups, err = tmc.TaskSvc.Upstreams(ctx, id); err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	if err := tmc.checkDependencies(ctx, t, ups); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	if t.Namespace != prev.Namespace && rejectForbidden(w, tmc.Namespaces.AuthorizeNamespace(ctx, t.Namespace)) {
+		return
+	}
+	if err := tmc.checkNamespace(ctx, t, &prev); err != nil {
+		writeNamespaceErr(w, err)
+		return
+	}
 	if err := tmc.TaskSvc.UpdateCronAndMeta(ctx, t); err != nil {
 		logging.Error(ctx, fmt.Sprintf("Task update failed: %v", err))
 		writeErr(w, 500, err.Error())
 		return
 	}
+	if req.UpstreamTaskIDs != nil {
+		if err := tmc.TaskSvc.SetUpstreams(ctx, id, ups); err != nil {
+			logging.Error(ctx, fmt.Sprintf("Task %d set upstreams failed: %v", id, err))
+			writeErr(w, 500, err.Error())
+			return
+		}
+	}
 	writeJSON(w, map[string]any{"updated": true})
 }
 
 func (tmc *TaskMgmtController) deleteTask(w http.ResponseWriter, r *http.Request, id int64) {
-	_ = tmc.TaskSvc.SoftDelete(r.Context(), id)
+	ctx := withActor(r)
+	if t, _ := tmc.TaskSvc.Get(ctx, id); rejectManaged(w, t) || tmc.rejectUnauthorized(ctx, w, t) {
+		return
+	}
+	_ = tmc.TaskSvc.SoftDelete(ctx, id)
 	writeJSON(w, map[string]any{"deleted": true})
 }
 
@@ -257,39 +456,287 @@ func (tmc *TaskMgmtController) triggerTask(w http.ResponseWriter, r *http.Reques
 		writeErr(w, 404, err.Error())
 		return
 	}
+	if tmc.rejectUnauthorized(withActor(r), w, t) {
+		return
+	}
+	run, err := tmc.trigger(r.Context(), t)
+	if err != nil {
+		var te *triggerError
+		if errors.As(err, &te) {
+			writeErr(w, te.status, te.code)
+			return
+		}
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"run_id": run.ID, "status": run.Status})
+}
+
+// triggerError 手动触发被拒绝：status 为 HTTP 状态码，code 为返回的错误码。
+type triggerError struct {
+	status int
+	code   string
+}
+
+func (e *triggerError) Error() string { return e.code }
+
+// trigger 以当前时间手动触发任务：按任务定义快照创建 Run 并派发，QUEUE 策略进入持久化队列。
+// 单个触发与批量触发共用；被并发上限、队列或重复 Run 拒绝时返回 *triggerError。
+func (tmc *TaskMgmtController) trigger(ctx context.Context, t *model.Task) (*model.TaskRun, error) {
 	// concurrency skip policy enforcement for manual trigger
 	activeCount := tmc.Exec.ActiveCount(t.ID)
 	maxConcurrent := t.MaxConcurrency
 	if t.ConcurrencyPolicy == bizConsts.ConcurrencySkip && t.MaxConcurrency > 0 && activeCount >= maxConcurrent {
-		errMsg := fmt.Sprintf("Task trigger concurrency limit reached: task_id=%d active=%d max=%d", t.ID, activeCount, maxConcurrent)
-		logging.Error(r.Context(), errMsg)
-		writeErr(w, 409, "CONCURRENCY_LIMIT")
-		return
+		logging.Error(ctx, fmt.Sprintf("Task trigger concurrency limit reached: task_id=%d active=%d max=%d", t.ID, activeCount, maxConcurrent))
+		return nil, &triggerError{status: 409, code: "CONCURRENCY_LIMIT"}
 	}
 
 	// FIX: 手动触发时，也需要从 Task 把快照字段填充到 TaskRun，否则 Executor 执行时会拿到空的 target/body
 	// Use factory for consistency
 	run := tmc.TaskSvc.CreateTaskRun(t, time.Now().UTC().Truncate(time.Second), 1)
+	run.TriggerType = bizConsts.TriggerManual
 	if run.TargetService == "" {
-		logging.Error(r.Context(), fmt.Sprintf("triggerTask: target_service is empty for task_id=%d", t.ID))
-		writeErr(w, 400, "target_service_empty")
-		return
+		logging.Error(ctx, fmt.Sprintf("triggerTask: target_service is empty for task_id=%d", t.ID))
+		return nil, &triggerError{status: 400, code: "target_service_empty"}
 	}
 
 	// Capture TraceID for async propagation
-	span := trace.SpanFromContext(r.Context())
+	span := trace.SpanFromContext(ctx)
 	if span.SpanContext().IsValid() {
 		run.TraceID = span.SpanContext().TraceID().String()
 	}
 
-	if err := tmc.RunSvc.CreateScheduled(r.Context(), run); err != nil {
-		logging.Error(r.Context(), fmt.Sprintf("Task trigger failed: %v", err))
+	if service.UsesQueue(t) { // QUEUE 策略：手动触发同样进入持久化队列
+		if err := tmc.Exec.SubmitQueued(ctx, t, run); err != nil {
+			switch {
+			case errors.Is(err, service.ErrQueueFull):
+				return nil, &triggerError{status: 409, code: "QUEUE_FULL"}
+			case errors.Is(err, dao.ErrDuplicateRun):
+				return nil, &triggerError{status: 409, code: "RUN_ALREADY_EXISTS"}
+			default:
+				logging.Error(ctx, fmt.Sprintf("Task trigger failed: %v", err))
+				return nil, err
+			}
+		}
+		return run, nil
+	}
+
+	if err := tmc.RunSvc.CreateScheduled(ctx, run); err != nil {
+		if errors.Is(err, dao.ErrDuplicateRun) { // 同一秒内已有 Run（重复点击或调度器恰好触发）
+			return nil, &triggerError{status: 409, code: "RUN_ALREADY_EXISTS"}
+		}
+		logging.Error(ctx, fmt.Sprintf("Task trigger failed: %v", err))
+		return nil, err
+	}
+	logging.Info(ctx, fmt.Sprintf("Task trigger enqueueing: id=%d", t.ID))
+	tmc.Exec.Enqueue(run)
+	return run, nil
+}
+
+// taskGraph 返回任务依赖图；指定 logical_date（YYYY-MM-DD）时附带各节点在该日期下最新的 Run。
+func (tmc *TaskMgmtController) taskGraph(w http.ResponseWriter, r *http.Request) {
+	g, err := tmc.TaskSvc.Graph(r.Context())
+	if err != nil {
 		writeErr(w, 500, err.Error())
 		return
 	}
-	logging.Info(r.Context(), fmt.Sprintf("Task trigger enqueueing: id=%d", t.ID))
-	tmc.Exec.Enqueue(run)
-	writeJSON(w, map[string]any{"run_id": run.ID})
+	if v := strings.TrimSpace(r.URL.Query().Get("logical_date")); v != "" {
+		date, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			writeErr(w, 400, "logical_date must be YYYY-MM-DD")
+			return
+		}
+		ids := make([]int64, 0, len(g.Nodes))
+		for _, n := range g.Nodes {
+			ids = append(ids, n.ID)
+		}
+		latest, err := tmc.RunSvc.LatestByLogicalDate(r.Context(), ids, date)
+		if err != nil {
+			writeErr(w, 500, err.Error())
+			return
+		}
+		for _, n := range g.Nodes {
+			n.Run = latest[n.ID]
+		}
+		g.Date = &date
+	}
+	writeJSON(w, g)
+}
+
+// rerunTask 从该任务节点起向下游重跑指定业务日期（默认任务时区的今天）。
+func (tmc *TaskMgmtController) rerunTask(w http.ResponseWriter, r *http.Request, id int64) {
+	t, err := tmc.TaskSvc.Get(r.Context(), id)
+	if err != nil {
+		writeErr(w, 404, err.Error())
+		return
+	}
+	if tmc.rejectUnauthorized(withActor(r), w, t) {
+		return
+	}
+	var req struct {
+		LogicalDate string `json:"logical_date"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, 400, err.Error())
+			return
+		}
+	}
+	date := service.LogicalDate(t, time.Now())
+	if req.LogicalDate != "" {
+		if date, err = time.Parse(time.DateOnly, req.LogicalDate); err != nil {
+			writeErr(w, 400, "logical_date must be YYYY-MM-DD")
+			return
+		}
+	}
+	writeRerun(w, r, tmc.Dag, t, date)
+}
+
+// writeRerun 发起重跑并输出结果，任务与运行控制器共用。
+func writeRerun(w http.ResponseWriter, r *http.Request, dag *service.DagResolver, t *model.Task, date time.Time) {
+	run, err := dag.Rerun(r.Context(), t, date)
+	switch {
+	case errors.Is(err, service.ErrQueueFull):
+		writeErr(w, 409, "QUEUE_FULL")
+		return
+	case errors.Is(err, dao.ErrDuplicateRun):
+		writeErr(w, 409, "RUN_ALREADY_EXISTS")
+		return
+	case err != nil:
+		logging.Error(r.Context(), fmt.Sprintf("Task %d rerun failed: %v", t.ID, err))
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"run_id": run.ID, "status": run.Status, "logical_date": date.Format(time.DateOnly), "dag_generation": run.DagGeneration})
+}
+
+// previewSchedule 预览任务接下来的 n 次触发时间（按任务时区输出，已应用日历），n 默认 10，最大 100。
+// 落在停摆窗口内的触发点不计入 next，单独列在 blackout 中。
+func (tmc *TaskMgmtController) previewSchedule(w http.ResponseWriter, r *http.Request, id int64) {
+	t, err := tmc.TaskSvc.Get(r.Context(), id)
+	if err != nil {
+		writeErr(w, 404, err.Error())
+		return
+	}
+	n := 10
+	if v := strings.TrimSpace(r.URL.Query().Get("n")); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i <= 0 || i > 100 {
+			writeErr(w, 400, "n must be between 1 and 100")
+			return
+		}
+		n = i
+	}
+	loc, err := cron.LoadLocation(t.Timezone)
+	if err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	sched, err := cron.Parse(t.CronExpr)
+	if err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	if sched, err = tmc.Calendars.Schedule(t, sched); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	fires := cron.Upcoming(sched, time.Now(), loc, n)
+	next := make([]string, 0, len(fires))
+	blackout := make([]string, 0)
+	for _, f := range fires {
+		if tmc.Calendars.Blackout(t.ID, f) != nil {
+			blackout = append(blackout, f.Format(time.RFC3339))
+			continue
+		}
+		next = append(next, f.Format(time.RFC3339))
+	}
+	writeJSON(w, map[string]any{"task_id": t.ID, "cron_expr": t.CronExpr, "timezone": loc.String(),
+		"calendar": t.Calendar, "calendar_mode": t.CalendarMode, "next": next, "blackout": blackout})
+}
+
+// renderPreview POST /api/v1/tasks/{id}/render 预览渲染后的请求（密钥打码）。
+// 可选参数：scheduled_time（RFC3339，默认当前时间）、attempt（默认 1），
+// 以及 target_path / headers_json / body_template / executor_config（覆盖任务当前模板，用于保存前试渲染）。
+func (tmc *TaskMgmtController) renderPreview(w http.ResponseWriter, r *http.Request, id int64) {
+	t, err := tmc.TaskSvc.Get(r.Context(), id)
+	if err != nil {
+		writeErr(w, 404, err.Error())
+		return
+	}
+	var req struct {
+		ScheduledTime  string  `json:"scheduled_time"`
+		Attempt        int     `json:"attempt"`
+		TargetPath     *string `json:"target_path"`
+		HeadersJSON    *string `json:"headers_json"`
+		BodyTemplate   *string `json:"body_template"`
+		ExecutorConfig *string `json:"executor_config"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, 400, err.Error())
+			return
+		}
+	}
+	scheduled := time.Now().Truncate(time.Second).UTC()
+	if req.ScheduledTime != "" {
+		if scheduled, err = time.Parse(time.RFC3339, req.ScheduledTime); err != nil {
+			writeErr(w, 400, "scheduled_time must be RFC3339")
+			return
+		}
+	}
+	cp := *t
+	if req.TargetPath != nil {
+		cp.TargetPath = *req.TargetPath
+	}
+	if req.HeadersJSON != nil {
+		cp.HeadersJSON = *req.HeadersJSON
+	}
+	if req.BodyTemplate != nil {
+		cp.BodyTemplate = *req.BodyTemplate
+	}
+	if req.ExecutorConfig != nil {
+		cp.ExecutorConfig = *req.ExecutorConfig
+	}
+	if err := validateTemplates(&cp); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	run := tmc.TaskSvc.CreateTaskRun(&cp, scheduled.UTC(), defaultInt(req.Attempt, 1))
+	res, err := tmc.Exec.Render(&cp, run, time.Now())
+	if err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	out := map[string]any{
+		"task_id":        t.ID,
+		"scheduled_time": scheduled.UTC().Format(time.RFC3339),
+		"executor":       cp.Executor,
+		"target_path":    res.Redact(res.Path),
+		"headers":        jsonOrString(res.Redact(res.Headers)),
+		"body":           jsonOrString(res.Redact(res.Body)),
+	}
+	if cp.Executor == bizConsts.ExecutorCommand {
+		args := make([]string, len(res.Args))
+		for i, a := range res.Args {
+			args[i] = res.Redact(a)
+		}
+		env := make(map[string]string, len(res.Env))
+		for k, v := range res.Env {
+			env[k] = res.Redact(v)
+		}
+		out["args"], out["env"] = args, env
+	}
+	writeJSON(w, out)
+}
+
+// jsonOrString 合法 JSON 按结构输出，否则原样输出字符串。
+func jsonOrString(s string) any {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err == nil {
+		return v
+	}
+	return s
 }
 
 func (tmc *TaskMgmtController) listRuns(w http.ResponseWriter, r *http.Request, taskID int64) {
@@ -298,7 +745,11 @@ func (tmc *TaskMgmtController) listRuns(w http.ResponseWriter, r *http.Request,
 }
 
 func (tmc *TaskMgmtController) updateStatus(w http.ResponseWriter, r *http.Request, id int64, status bizConsts.TaskStatus) {
-	err := tmc.TaskSvc.UpdateStatus(r.Context(), id, status)
+	ctx := withActor(r)
+	if t, _ := tmc.TaskSvc.Get(ctx, id); rejectManaged(w, t) || tmc.rejectUnauthorized(ctx, w, t) {
+		return
+	}
+	err := tmc.TaskSvc.UpdateStatus(ctx, id, status)
 	if err != nil {
 		logging.Error(r.Context(), fmt.Sprintf("Task update status failed: %v", err))
 		writeErr(w, 500, err.Error())
@@ -307,6 +758,66 @@ func (tmc *TaskMgmtController) updateStatus(w http.ResponseWriter, r *http.Reque
 	writeJSON(w, map[string]any{"updated": true})
 }
 
+// taskHistory GET /api/v1/tasks/{id}/history?limit=&offset= 任务版本历史（最新在前），已删除的任务也可查询。
+func (tmc *TaskMgmtController) taskHistory(w http.ResponseWriter, r *http.Request, id int64) {
+	_, _, _, limit, offset, _ := parseRunFilters(r)
+	list, err := tmc.TaskSvc.History(r.Context(), id, limit, offset)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"items": list, "limit": limit, "offset": offset})
+}
+
+// rollbackTask POST /api/v1/tasks/{id}/rollback/{version} 以历史版本的定义生成新版本（名称、状态与依赖不变），
+// 与修改任务走相同的校验。
+func (tmc *TaskMgmtController) rollbackTask(w http.ResponseWriter, r *http.Request, id int64, version int) {
+	ctx := withActor(r)
+	t, rev, err := tmc.TaskSvc.RollbackTarget(ctx, id, version)
+	switch {
+	case errors.Is(err, dao.ErrRevisionNotFound):
+		writeErr(w, 404, "revision_not_found")
+		return
+	case errors.Is(err, service.ErrRollbackDeleted):
+		writeErr(w, 409, "task_deleted")
+		return
+	case err != nil:
+		writeErr(w, 500, err.Error())
+		return
+	}
+	if rejectManaged(w, t) || rejectForbidden(w, tmc.Namespaces.Authorize(ctx, t)) {
+		return
+	}
+	applyExecutorDefaults(t)
+	if err := validateTask(t); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	if err := tmc.checkCalendar(t); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	if err := tmc.Exec.CheckBackend(t); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	if err := tmc.checkTemplates(t); err != nil {
+		writeErr(w, 400, err.Error())
+		return
+	}
+	if err := tmc.TaskSvc.Rollback(ctx, t, rev.Version); err != nil {
+		if dao.IsNotFound(err) {
+			writeErr(w, 409, "version_conflict")
+			return
+		}
+		logging.Error(ctx, fmt.Sprintf("Task %d rollback to v%d failed: %v", id, version, err))
+		writeErr(w, 500, err.Error())
+		return
+	}
+	logging.Info(ctx, fmt.Sprintf("Task %d rolled back to v%d as v%d by %s", id, version, t.Version, service.ActorFrom(ctx)))
+	writeJSON(w, map[string]any{"rolled_back": true, "from_version": version, "version": t.Version})
+}
+
 func (tmc *TaskMgmtController) refreshCache(w http.ResponseWriter, r *http.Request) {
 	if err := tmc.TaskSvc.Refresh(r.Context()); err != nil {
 		writeErr(w, 500, err.Error())
@@ -332,7 +843,12 @@ func (tmc *TaskMgmtController) ExportTasks(w http.ResponseWriter, r *http.Reques
 		}
 		tasks = []*model.Task{t}
 	} else {
-		tasks, err = tmc.TaskSvc.ListFiltered(ctx, &model.TaskListFilters{}, 10000, 0)
+		filters := &model.TaskListFilters{}
+		if err := parseScopeFilters(r.URL.Query(), filters); err != nil {
+			writeErr(w, 400, err.Error())
+			return
+		}
+		tasks, err = tmc.TaskSvc.ListFiltered(ctx, filters, 10000, 0)
 		if err != nil {
 			logging.Error(ctx, fmt.Sprintf("Export tasks failed: %v", err))
 			writeErr(w, 500, err.Error())
@@ -340,28 +856,59 @@ func (tmc *TaskMgmtController) ExportTasks(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
+	// 上游以名称导出，导入时按名称解析
+	names, err := tmc.taskNames(ctx)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+	_, upIndex, err := tmc.TaskSvc.DependencyIndex(ctx)
+	if err != nil {
+		writeErr(w, 500, err.Error())
+		return
+	}
+
 	// 过滤掉不需要导出的字段
 	exportData := make([]map[string]any, 0, len(tasks))
 	for _, t := range tasks {
+		upstreams := make([]string, 0, len(upIndex[t.ID]))
+		for _, u := range upIndex[t.ID] {
+			upstreams = append(upstreams, names[u])
+		}
 		item := map[string]any{
-			"name":                 t.Name,
-			"description":          t.Description,
-			"cron_expr":            t.CronExpr,
-			"timezone":             t.Timezone,
-			"exec_type":            t.ExecType,
-			"method":               t.HTTPMethod,
-			"target_service":       t.TargetService,
-			"target_path":          t.TargetPath,
-			"headers_json":         t.HeadersJSON,
-			"body_template":        t.BodyTemplate,
-			"retry_policy_json":    t.RetryPolicyJSON,
-			"max_concurrency":      t.MaxConcurrency,
-			"concurrency_policy":   t.ConcurrencyPolicy,
-			"callback_method":      t.CallbackMethod,
-			"callback_timeout_sec": t.CallbackTimeoutSec,
-			"overlap_action":       t.OverlapAction,
-			"failure_action":       t.FailureAction,
-			"status":               t.Status,
+			"name":                  t.Name,
+			"description":           t.Description,
+			"namespace":             t.Namespace,
+			"owners":                t.Owners,
+			"labels":                t.Labels,
+			"cron_expr":             t.CronExpr,
+			"timezone":              t.Timezone,
+			"exec_type":             t.ExecType,
+			"executor":              t.Executor,
+			"executor_config":       t.ExecutorConfig,
+			"method":                t.HTTPMethod,
+			"target_service":        t.TargetService,
+			"target_path":           t.TargetPath,
+			"headers_json":          t.HeadersJSON,
+			"body_template":         t.BodyTemplate,
+			"retry_policy_json":     t.RetryPolicyJSON,
+			"max_concurrency":       t.MaxConcurrency,
+			"concurrency_policy":    t.ConcurrencyPolicy,
+			"queue_max_depth":       t.QueueMaxDepth,
+			"queue_overflow":        t.QueueOverflow,
+			"callback_method":       t.CallbackMethod,
+			"callback_timeout_sec":  t.CallbackTimeoutSec,
+			"overlap_action":        t.OverlapAction,
+			"failure_action":        t.FailureAction,
+			"misfire_policy":        t.MisfirePolicy,
+			"misfire_grace_sec":     t.MisfireGraceSec,
+			"execution_timeout_sec": t.ExecutionTimeoutSec,
+			"schedule_mode":         t.ScheduleMode,
+			"trigger_rule":          t.TriggerRule,
+			"calendar":              t.Calendar,
+			"calendar_mode":         t.CalendarMode,
+			"upstream_tasks":        upstreams,
+			"status":                t.Status,
 		}
 		exportData = append(exportData, item)
 	}
@@ -377,7 +924,7 @@ func (tmc *TaskMgmtController) ExportTasks(w http.ResponseWriter, r *http.Reques
 
 // ImportTasks 导入任务配置
 func (tmc *TaskMgmtController) ImportTasks(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	ctx := withActor(r)
 
 	// Parse multipart form (max 10MB)
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
@@ -397,24 +944,39 @@ func (tmc *TaskMgmtController) ImportTasks(w http.ResponseWriter, r *http.Reques
 	var payload struct {
 		Version string `json:"version"`
 		Tasks   []struct {
-			Name               string `json:"name"`
-			Description        string `json:"description"`
-			CronExpr           string `json:"cron_expr"`
-			Timezone           string `json:"timezone"`
-			ExecType           string `json:"exec_type"`
-			Method             string `json:"method"`
-			TargetService      string `json:"target_service"`
-			TargetPath         string `json:"target_path"`
-			HeadersJSON        string `json:"headers_json"`
-			BodyTemplate       string `json:"body_template"`
-			RetryPolicyJSON    string `json:"retry_policy_json"`
-			MaxConcurrency     int    `json:"max_concurrency"`
-			ConcurrencyPolicy  string `json:"concurrency_policy"`
-			CallbackMethod     string `json:"callback_method"`
-			CallbackTimeoutSec int    `json:"callback_timeout_sec"`
-			OverlapAction      string `json:"overlap_action"`
-			FailureAction      string `json:"failure_action"`
-			Status             string `json:"status"`
+			Name                string            `json:"name"`
+			Description         string            `json:"description"`
+			Namespace           string            `json:"namespace"`
+			Owners              []string          `json:"owners"`
+			Labels              map[string]string `json:"labels"`
+			CronExpr            string            `json:"cron_expr"`
+			Timezone            string            `json:"timezone"`
+			ExecType            string            `json:"exec_type"`
+			Executor            string            `json:"executor"`
+			ExecutorConfig      string            `json:"executor_config"`
+			Method              string            `json:"method"`
+			TargetService       string            `json:"target_service"`
+			TargetPath          string            `json:"target_path"`
+			HeadersJSON         string            `json:"headers_json"`
+			BodyTemplate        string            `json:"body_template"`
+			RetryPolicyJSON     string            `json:"retry_policy_json"`
+			MaxConcurrency      int               `json:"max_concurrency"`
+			ConcurrencyPolicy   string            `json:"concurrency_policy"`
+			QueueMaxDepth       int               `json:"queue_max_depth"`
+			QueueOverflow       string            `json:"queue_overflow"`
+			CallbackMethod      string            `json:"callback_method"`
+			CallbackTimeoutSec  int               `json:"callback_timeout_sec"`
+			OverlapAction       string            `json:"overlap_action"`
+			FailureAction       string            `json:"failure_action"`
+			MisfirePolicy       string            `json:"misfire_policy"`
+			MisfireGraceSec     int               `json:"misfire_grace_sec"`
+			ExecutionTimeoutSec int               `json:"execution_timeout_sec"`
+			ScheduleMode        string            `json:"schedule_mode"`
+			TriggerRule         string            `json:"trigger_rule"`
+			Calendar            string            `json:"calendar"`
+			CalendarMode        string            `json:"calendar_mode"`
+			UpstreamTasks       []string          `json:"upstream_tasks"` // 上游任务名称
+			Status              string            `json:"status"`
 		} `json:"tasks"`
 	}
 
@@ -426,34 +988,92 @@ func (tmc *TaskMgmtController) ImportTasks(w http.ResponseWriter, r *http.Reques
 
 	successCount := 0
 	failedTasks := make([]map[string]any, 0)
+	imported := make(map[int64][]string) // task id -> 上游任务名称
 
 	for _, taskData := range payload.Tasks {
 		t := &model.Task{
-			Name:               strings.TrimSpace(taskData.Name),
-			Description:        taskData.Description,
-			CronExpr:           model.NormalizeCron(taskData.CronExpr),
-			Timezone:           defaultOr(taskData.Timezone, "UTC"),
-			ExecType:           bizConsts.ExecType(taskData.ExecType),
-			HTTPMethod:         strings.ToUpper(taskData.Method),
-			TargetService:      defaultOr(taskData.TargetService, "artemis"),
-			TargetPath:         taskData.TargetPath,
-			HeadersJSON:        defaultOr(taskData.HeadersJSON, bizConsts.DEFAULT_JSON_STR),
-			BodyTemplate:       taskData.BodyTemplate,
-			RetryPolicyJSON:    defaultOr(taskData.RetryPolicyJSON, bizConsts.DEFAULT_JSON_STR),
-			MaxConcurrency:     defaultInt(taskData.MaxConcurrency, 1),
-			ConcurrencyPolicy:  bizConsts.ConcurrencyPolicy(taskData.ConcurrencyPolicy),
-			CallbackMethod:     defaultOr(taskData.CallbackMethod, "POST"),
-			CallbackTimeoutSec: defaultInt(taskData.CallbackTimeoutSec, 300),
-			OverlapAction:      bizConsts.OverlapAction(taskData.OverlapAction),
-			FailureAction:      bizConsts.FailureAction(taskData.FailureAction),
-			Status:             bizConsts.DISABLED, // 导入默认禁用
-			Version:            1,
-		}
-
-		if t.CronExpr == "" || t.Name == "" || t.TargetService == "" || t.TargetPath == "" {
+			Name:                strings.TrimSpace(taskData.Name),
+			Description:         taskData.Description,
+			Namespace:           defaultOr(strings.TrimSpace(taskData.Namespace), bizConsts.DEFAULT_NAMESPACE),
+			Owners:              normalizeNames(taskData.Owners),
+			Labels:              taskData.Labels,
+			CronExpr:            model.NormalizeCron(taskData.CronExpr),
+			Timezone:            defaultOr(taskData.Timezone, "UTC"),
+			ExecType:            bizConsts.ExecType(taskData.ExecType),
+			Executor:            bizConsts.ExecutorKind(strings.ToUpper(taskData.Executor)),
+			ExecutorConfig:      taskData.ExecutorConfig,
+			HTTPMethod:          strings.ToUpper(taskData.Method),
+			TargetService:       taskData.TargetService,
+			TargetPath:          taskData.TargetPath,
+			HeadersJSON:         defaultOr(taskData.HeadersJSON, bizConsts.DEFAULT_JSON_STR),
+			BodyTemplate:        taskData.BodyTemplate,
+			RetryPolicyJSON:     defaultOr(taskData.RetryPolicyJSON, bizConsts.DEFAULT_JSON_STR),
+			MaxConcurrency:      defaultInt(taskData.MaxConcurrency, 1),
+			ConcurrencyPolicy:   bizConsts.ConcurrencyPolicy(strings.ToUpper(taskData.ConcurrencyPolicy)),
+			QueueMaxDepth:       taskData.QueueMaxDepth,
+			QueueOverflow:       bizConsts.QueueOverflow(strings.ToUpper(taskData.QueueOverflow)),
+			CallbackMethod:      defaultOr(taskData.CallbackMethod, "POST"),
+			CallbackTimeoutSec:  defaultInt(taskData.CallbackTimeoutSec, 300),
+			OverlapAction:       bizConsts.OverlapAction(taskData.OverlapAction),
+			FailureAction:       bizConsts.FailureAction(taskData.FailureAction),
+			MisfirePolicy:       bizConsts.MisfirePolicy(strings.ToUpper(taskData.MisfirePolicy)),
+			MisfireGraceSec:     taskData.MisfireGraceSec,
+			ExecutionTimeoutSec: taskData.ExecutionTimeoutSec,
+			ScheduleMode:        bizConsts.ScheduleMode(strings.ToUpper(taskData.ScheduleMode)),
+			TriggerRule:         bizConsts.TriggerRule(strings.ToUpper(taskData.TriggerRule)),
+			Calendar:            strings.TrimSpace(taskData.Calendar),
+			CalendarMode:        bizConsts.CalendarMode(strings.ToUpper(taskData.CalendarMode)),
+			Status:              bizConsts.DISABLED, // 导入默认禁用
+			Version:             1,
+		}
+
+		applyExecutorDefaults(t)
+		if (t.CronExpr == "" && t.UsesCron()) || t.Name == "" || t.TargetPath == "" {
+			failedTasks = append(failedTasks, map[string]any{
+				"name":  taskData.Name,
+				"error": "CronExpr/Name/TargetPath cannot be empty",
+			})
+			continue
+		}
+		if err := validateTask(t); err != nil {
+			failedTasks = append(failedTasks, map[string]any{
+				"name":  taskData.Name,
+				"error": err.Error(),
+			})
+			continue
+		}
+		if err := tmc.checkCalendar(t); err != nil {
+			failedTasks = append(failedTasks, map[string]any{
+				"name":  taskData.Name,
+				"error": err.Error(),
+			})
+			continue
+		}
+		if err := tmc.Exec.CheckBackend(t); err != nil {
+			failedTasks = append(failedTasks, map[string]any{
+				"name":  taskData.Name,
+				"error": err.Error(),
+			})
+			continue
+		}
+		if err := tmc.checkTemplates(t); err != nil {
+			failedTasks = append(failedTasks, map[string]any{
+				"name":  taskData.Name,
+				"error": err.Error(),
+			})
+			continue
+		}
+		if err := tmc.Namespaces.AuthorizeNamespace(ctx, t.Namespace); err != nil {
+			failedTasks = append(failedTasks, map[string]any{
+				"name":  taskData.Name,
+				"error": err.Error(),
+			})
+			continue
+		}
+		if err := tmc.checkNamespace(ctx, t, nil); err != nil {
 			failedTasks = append(failedTasks, map[string]any{
 				"name":  taskData.Name,
-				"error": "CronExpr/Name/TargetService/TargetPath cannot be empty",
+				"error": err.Error(),
 			})
 			continue
 		}
@@ -468,7 +1088,7 @@ func (tmc *TaskMgmtController) ImportTasks(w http.ResponseWriter, r *http.Reques
 		}
 
 		// 尝试重新激活同名软删除任务
-		if _, reactivated, err := tmc.TaskSvc.TaskDaoImpl().ReactivateByName(ctx, t.Name, t); err != nil {
+		if reactivated, err := tmc.TaskSvc.Reactivate(ctx, t.Name, t); err != nil {
 			logging.Error(ctx, fmt.Sprintf("Import task %s reactivate failed: %v", t.Name, err))
 			failedTasks = append(failedTasks, map[string]any{
 				"name":  taskData.Name,
@@ -477,6 +1097,7 @@ func (tmc *TaskMgmtController) ImportTasks(w http.ResponseWriter, r *http.Reques
 			continue
 		} else if reactivated {
 			successCount++
+			imported[t.ID] = taskData.UpstreamTasks
 			continue
 		}
 
@@ -490,6 +1111,29 @@ func (tmc *TaskMgmtController) ImportTasks(w http.ResponseWriter, r *http.Reques
 		}
 
 		successCount++
+		imported[t.ID] = taskData.UpstreamTasks
+	}
+
+	// 全部任务导入后再按名称建立依赖（上游可能在同一文件中靠后的位置）
+	if names, err := tmc.taskNames(ctx); err == nil {
+		ids := make(map[string]int64, len(names))
+		for id, name := range names {
+			ids[name] = id
+		}
+		for id, upstreamNames := range imported {
+			ups := make([]int64, 0, len(upstreamNames))
+			for _, n := range upstreamNames {
+				if u, ok := ids[n]; ok {
+					ups = append(ups, u)
+				}
+			}
+			if err := tmc.TaskSvc.SetUpstreams(ctx, id, ups); err != nil {
+				failedTasks = append(failedTasks, map[string]any{
+					"name":  names[id],
+					"error": "dependencies: " + err.Error(),
+				})
+			}
+		}
 	}
 
 	writeJSON(w, map[string]any{
@@ -499,6 +1143,222 @@ func (tmc *TaskMgmtController) ImportTasks(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+// checkCalendar 任务引用的日历须已存在。
+func (tmc *TaskMgmtController) checkCalendar(t *model.Task) error {
+	if t.Calendar == "" {
+		return nil
+	}
+	if _, ok := tmc.Calendars.Calendar(t.Calendar); !ok {
+		return fmt.Errorf("calendar %q not found", t.Calendar)
+	}
+	return nil
+}
+
+// checkNamespace 校验标签，以及任务所在空间（存在、target_service 白名单、任务数配额）。prev 为修改前的任务，新建时为 nil。
+func (tmc *TaskMgmtController) checkNamespace(ctx context.Context, t, prev *model.Task) error {
+	if err := model.ValidateLabels(t.Labels); err != nil {
+		return fmt.Errorf("%w: %v", service.ErrInvalidNamespace, err)
+	}
+	return tmc.Namespaces.CheckTask(ctx, t, prev)
+}
+
+// rejectUnauthorized 当前操作人不能管理该任务时输出 403；任务不存在时不拦截（由后续逻辑处理）。
+func (tmc *TaskMgmtController) rejectUnauthorized(ctx context.Context, w http.ResponseWriter, t *model.Task) bool {
+	return t != nil && rejectForbidden(w, tmc.Namespaces.Authorize(ctx, t))
+}
+
+// rejectForbidden 归属校验未通过时输出 403。
+func rejectForbidden(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return false
+	}
+	writeErr(w, http.StatusForbidden, "forbidden")
+	return true
+}
+
+// writeNamespaceErr 任务数配额已满返回 409，其余空间校验错误返回 400。
+func writeNamespaceErr(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNamespaceQuota):
+		writeErr(w, 409, err.Error())
+	case errors.Is(err, service.ErrInvalidNamespace):
+		writeErr(w, 400, err.Error())
+	default:
+		writeErr(w, 500, err.Error())
+	}
+}
+
+// parseScopeFilters 解析 namespace / owner / selector 查询参数，列表、导出与批量操作共用。
+func parseScopeFilters(q url.Values, f *model.TaskListFilters) error {
+	f.Namespace = strings.TrimSpace(q.Get("namespace"))
+	f.Owner = strings.TrimSpace(q.Get("owner"))
+	sel, err := model.ParseLabelSelector(q.Get("selector"))
+	if err != nil {
+		return err
+	}
+	f.Selector = sel
+	return nil
+}
+
+// normalizeNames 去除空白与重复项（owners、target_service 白名单），保持原有顺序。
+func normalizeNames(owners []string) []string {
+	out := make([]string, 0, len(owners))
+	for _, o := range owners {
+		if o = strings.TrimSpace(o); o != "" && !slices.Contains(out, o) {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// checkTemplates 以当前时间为触发点试渲染请求模板，提前发现未知字段、日历或未配置的密钥。
+func (tmc *TaskMgmtController) checkTemplates(t *model.Task) error {
+	if !render.IsTemplate(t.TargetPath) && !render.IsTemplate(t.HeadersJSON) && !render.IsTemplate(t.BodyTemplate) {
+		return nil
+	}
+	run := tmc.TaskSvc.CreateTaskRun(t, time.Now().Truncate(time.Second).UTC(), 1)
+	_, err := tmc.Exec.Render(t, run, time.Now())
+	return err
+}
+
+// checkDependencies 依赖触发的任务必须声明上游；上游须存在且不成环。
+func (tmc *TaskMgmtController) checkDependencies(ctx context.Context, t *model.Task, upstreams []int64) error {
+	if t.UsesDependencies() && len(upstreams) == 0 {
+		return fmt.Errorf("schedule_mode %s requires upstream_task_ids", t.ScheduleMode)
+	}
+	return tmc.TaskSvc.CheckUpstreams(ctx, t.ID, upstreams)
+}
+
+// taskNames 返回未删除任务的 id -> name。
+func (tmc *TaskMgmtController) taskNames(ctx context.Context) (map[int64]string, error) {
+	list, err := tmc.TaskSvc.ListFiltered(ctx, &model.TaskListFilters{}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(list))
+	for _, t := range list {
+		names[t.ID] = t.Name
+	}
+	return names, nil
+}
+
+// validateTask 校验 cron 表达式、时区、日历用法、misfire 配置、重试策略与请求模板语法
+func validateTask(t *model.Task) error {
+	switch t.ScheduleMode {
+	case "", bizConsts.ScheduleModeCron, bizConsts.ScheduleModeDependency, bizConsts.ScheduleModeBoth:
+	default:
+		return fmt.Errorf("invalid schedule_mode %q", t.ScheduleMode)
+	}
+	switch t.TriggerRule {
+	case "", bizConsts.TriggerRuleAllSuccess, bizConsts.TriggerRuleAllDone, bizConsts.TriggerRuleOneSuccess:
+	default:
+		return fmt.Errorf("invalid trigger_rule %q", t.TriggerRule)
+	}
+	if t.UsesCron() || t.CronExpr != "" {
+		if err := cron.Validate(t.CronExpr, t.Timezone); err != nil {
+			return err
+		}
+	} else if _, err := cron.LoadLocation(t.Timezone); err != nil {
+		return err
+	}
+	switch t.CalendarMode {
+	case "", bizConsts.CalendarModeBusinessDays, bizConsts.CalendarModeNonBusinessDays, bizConsts.CalendarModeNextBusinessDay:
+	default:
+		return fmt.Errorf("invalid calendar_mode %q", t.CalendarMode)
+	}
+	if t.Calendar != "" && !t.UsesCron() {
+		return fmt.Errorf("calendar only applies to cron schedules (schedule_mode %s)", t.ScheduleMode)
+	}
+	switch t.MisfirePolicy {
+	case "", bizConsts.MisfireSkip, bizConsts.MisfireFireOnce, bizConsts.MisfireFireAll:
+	default:
+		return fmt.Errorf("invalid misfire_policy %q", t.MisfirePolicy)
+	}
+	if t.ExecutionTimeoutSec < 0 {
+		return fmt.Errorf("execution_timeout_sec must be >= 0")
+	}
+	if t.MisfireGraceSec < 0 {
+		return fmt.Errorf("misfire_grace_sec must be >= 0")
+	}
+	switch t.ConcurrencyPolicy {
+	case "", bizConsts.ConcurrencyQueue, bizConsts.ConcurrencySkip, bizConsts.ConcurrencyParallel:
+	default:
+		return fmt.Errorf("invalid concurrency_policy %q", t.ConcurrencyPolicy)
+	}
+	switch t.QueueOverflow {
+	case "", bizConsts.QueueOverflowDropNew, bizConsts.QueueOverflowDropOldest:
+	default:
+		return fmt.Errorf("invalid queue_overflow %q", t.QueueOverflow)
+	}
+	if t.QueueMaxDepth < 0 {
+		return fmt.Errorf("queue_max_depth must be >= 0")
+	}
+	if _, err := model.ParseRetryPolicy(t.RetryPolicyJSON); err != nil {
+		return err
+	}
+	switch t.Executor {
+	case "", bizConsts.ExecutorHTTP, bizConsts.ExecutorGRPC, bizConsts.ExecutorRedisStream:
+	case bizConsts.ExecutorCommand:
+		if t.ExecType == bizConsts.ExecTypeAsync {
+			return fmt.Errorf("executor %s does not support exec_type ASYNC", t.Executor)
+		}
+	default:
+		return fmt.Errorf("invalid executor %q", t.Executor)
+	}
+	return validateTemplates(t)
+}
+
+// applyExecutorDefaults 未指定执行器时为 HTTP；仅 HTTP 任务的 target_service 默认 artemis。
+func applyExecutorDefaults(t *model.Task) {
+	if t.Executor == "" {
+		t.Executor = bizConsts.DEFAULT_EXECUTOR
+	}
+	if t.Executor == bizConsts.ExecutorHTTP {
+		t.TargetService = defaultOr(t.TargetService, "artemis")
+	}
+	t.ExecutorConfig = defaultOr(t.ExecutorConfig, bizConsts.DEFAULT_JSON_STR)
+}
+
+// validateTemplates 校验请求路径、请求头、请求体与命令参数 / 环境变量的模板语法。
+func validateTemplates(t *model.Task) error {
+	if err := render.Validate("target_path", t.TargetPath); err != nil {
+		return err
+	}
+	if err := render.Validate("headers_json", t.HeadersJSON); err != nil {
+		return err
+	}
+	if err := render.Validate("body_template", t.BodyTemplate); err != nil {
+		return err
+	}
+	spec, err := model.ParseExecutorSpec(t.ExecutorConfig)
+	if err != nil {
+		return err
+	}
+	for _, a := range spec.Args {
+		if err := render.Validate("args", a); err != nil {
+			return err
+		}
+	}
+	for k, v := range spec.Env {
+		if err := render.Validate("env."+k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withActor 把请求方身份放入 ctx，供任务版本历史记录操作人：取网关注入的 X-Cronjob-User，缺失时为 anonymous@<来源 IP>。
+func withActor(r *http.Request) context.Context {
+	actor := strings.TrimSpace(r.Header.Get(bizConsts.ActorHeader))
+	if actor == "" {
+		actor = "anonymous@" + sourceIP(r)
+	}
+	if len(actor) > 128 {
+		actor = actor[:128]
+	}
+	return service.WithActor(r.Context(), actor)
+}
+
 // defaultOr returns s if not empty, otherwise def
 func defaultOr(s, def string) string {
 	if strings.TrimSpace(s) != "" {
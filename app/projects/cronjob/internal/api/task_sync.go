@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/service"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/tasksync"
+)
+
+// 声明式任务同步：
+//   - 任务以 YAML 声明在 task_sync.dir 下，按任务名与库中任务比对生成计划（CREATE / UPDATE / DELETE / UNCHANGED）
+//   - plan 只预览；apply 先整体校验，计划有任何错误时不做变更
+//   - 同步创建或接管的任务标记为 managed，API 修改、删除、启停与回滚返回 409 task_managed
+//   - 声明中不存在的托管任务删除；非托管任务仅在 prune=true 时删除
+//   - 启用归属校验（namespace.admins）时仅 admin 可 apply；声明的空间须存在且满足其配额与 target_service 白名单
+
+// syncPlan POST /api/v1/tasks/sync/plan?prune=true 预览同步计划（不落库）。
+// 请求体可携带 {"files": {"a.yaml": "..."}} 直接比对（如 CI 中比对 PR 的文件），否则读取 task_sync.dir。
+func (tmc *TaskMgmtController) syncPlan(w http.ResponseWriter, r *http.Request) {
+	plan, code, err := tmc.buildSyncPlan(r.Context(), r)
+	if err != nil {
+		writeErr(w, code, err.Error())
+		return
+	}
+	writeJSON(w, plan)
+}
+
+// syncApply POST /api/v1/tasks/sync/apply?prune=true 应用同步计划；请求体格式同 plan。
+// 计划无效时返回 400 与计划；单个任务落库失败不影响其他任务，失败项在 failed 中列出。
+func (tmc *TaskMgmtController) syncApply(w http.ResponseWriter, r *http.Request) {
+	ctx := withActor(r)
+	if rejectForbidden(w, tmc.Namespaces.AuthorizeAdmin(ctx)) {
+		return
+	}
+	plan, code, err := tmc.buildSyncPlan(ctx, r)
+	if err != nil {
+		writeErr(w, code, err.Error())
+		return
+	}
+	if !plan.Valid {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]any{"error": "invalid_plan", "plan": plan})
+		return
+	}
+	failed := tmc.applySyncPlan(ctx, plan)
+	logging.Info(ctx, fmt.Sprintf("task sync applied by %s: create=%d update=%d delete=%d failed=%d",
+		service.ActorFrom(ctx), plan.Summary[tasksync.ActionCreate], plan.Summary[tasksync.ActionUpdate],
+		plan.Summary[tasksync.ActionDelete], len(failed)))
+	writeJSON(w, map[string]any{"applied": len(failed) == 0, "plan": plan, "failed": failed})
+}
+
+// buildSyncPlan 读取声明、比对现有任务并逐项校验，返回计划或（HTTP 状态码, 错误）。
+func (tmc *TaskMgmtController) buildSyncPlan(ctx context.Context, r *http.Request) (*tasksync.Plan, int, error) {
+	var req struct {
+		Files map[string]string `json:"files"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		return nil, http.StatusBadRequest, err
+	}
+	var specs []*tasksync.Spec
+	var err error
+	if req.Files != nil {
+		files := make(map[string][]byte, len(req.Files))
+		for n, c := range req.Files {
+			files[n] = []byte(c)
+		}
+		specs, err = tasksync.Load(files)
+	} else if dir := config.GetBizConfig().TaskSync.Dir; dir != "" {
+		specs, err = tasksync.LoadDir(dir)
+	} else {
+		return nil, http.StatusBadRequest, errors.New("task_sync.dir not configured and no files in request")
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	current, err := tmc.TaskSvc.ListFiltered(ctx, &model.TaskListFilters{}, 0, 0)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	_, up, err := tmc.TaskSvc.DependencyIndex(ctx)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	names := make(map[int64]string, len(current))
+	for _, t := range current {
+		names[t.ID] = t.Name
+	}
+	upstreams := make(map[int64][]string, len(up))
+	for id, ups := range up {
+		for _, u := range ups {
+			if n, ok := names[u]; ok {
+				upstreams[id] = append(upstreams[id], n)
+			}
+		}
+	}
+
+	prune := r.URL.Query().Get("prune") == "true"
+	plan := tasksync.Compute(specs, current, upstreams, prune)
+	for _, it := range plan.Items {
+		if it.Error != "" || it.Desired == nil || it.Action == tasksync.ActionUnchanged {
+			continue
+		}
+		if err := tmc.checkSyncTask(ctx, it.Desired, it.Current); err != nil {
+			it.Error = err.Error()
+			plan.Valid = false
+		}
+	}
+	return plan, 0, nil
+}
+
+// checkSyncTask 与 API 创建任务相同的校验（依赖已由计划按名称校验）；cur 为库中的当前定义，新建时为 nil。
+func (tmc *TaskMgmtController) checkSyncTask(ctx context.Context, t, cur *model.Task) error {
+	if err := validateTask(t); err != nil {
+		return err
+	}
+	if err := tmc.checkCalendar(t); err != nil {
+		return err
+	}
+	if err := tmc.Exec.CheckBackend(t); err != nil {
+		return err
+	}
+	if err := tmc.checkTemplates(t); err != nil {
+		return err
+	}
+	return tmc.Namespaces.CheckTask(ctx, t, cur)
+}
+
+// applySyncPlan 依次创建 / 更新任务、删除任务，最后重建变化的依赖；返回失败项。
+func (tmc *TaskMgmtController) applySyncPlan(ctx context.Context, plan *tasksync.Plan) []map[string]any {
+	failed := make([]map[string]any, 0)
+	fail := func(it *tasksync.Item, err error) {
+		logging.Error(ctx, fmt.Sprintf("task sync %s %s failed: %v", it.Action, it.Name, err))
+		failed = append(failed, map[string]any{"name": it.Name, "action": it.Action, "error": err.Error()})
+	}
+	applied := make(map[string]bool)
+	for _, it := range plan.Items {
+		switch it.Action {
+		case tasksync.ActionCreate:
+			t := it.Desired
+			reactivated, err := tmc.TaskSvc.Reactivate(ctx, t.Name, t)
+			if err == nil && !reactivated {
+				err = tmc.TaskSvc.Create(ctx, t)
+			}
+			if err != nil {
+				fail(it, err)
+				continue
+			}
+			it.TaskID = t.ID
+		case tasksync.ActionUpdate:
+			t := it.Desired
+			if err := tmc.TaskSvc.UpdateCronAndMeta(ctx, t); err != nil {
+				if dao.IsNotFound(err) {
+					err = errors.New("version_conflict")
+				}
+				fail(it, err)
+				continue
+			}
+			if t.Status != it.Current.Status {
+				if err := tmc.TaskSvc.UpdateStatus(ctx, t.ID, t.Status); err != nil {
+					fail(it, err)
+					continue
+				}
+			}
+		default:
+			continue
+		}
+		applied[it.Name] = true
+	}
+
+	for _, it := range plan.Items {
+		if it.Action != tasksync.ActionDelete {
+			continue
+		}
+		if err := tmc.TaskSvc.SoftDelete(ctx, it.TaskID); err != nil {
+			fail(it, err)
+		}
+	}
+
+	// 全部任务落库后再按名称建立依赖（上游可能是本次新建的任务或保留的非托管任务）。
+	// 先把变化的依赖收缩为新旧交集、再设为目标集合，过程中的依赖图始终是目标图的子集，不会误判成环。
+	names, err := tmc.taskNames(ctx)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("task sync list tasks failed: %v", err))
+	}
+	ids := make(map[string]int64, len(names))
+	for id, name := range names {
+		ids[name] = id
+	}
+	targets := make(map[*tasksync.Item][]int64)
+	for _, it := range plan.Items {
+		if !applied[it.Name] || !it.UpstreamsChanged {
+			continue
+		}
+		ups := make([]int64, 0, len(it.Upstreams))
+		for _, n := range it.Upstreams {
+			id, ok := ids[n]
+			if !ok {
+				fail(it, fmt.Errorf("dependencies: upstream task %q was not applied", n))
+				break
+			}
+			ups = append(ups, id)
+		}
+		if len(ups) != len(it.Upstreams) {
+			continue
+		}
+		targets[it] = ups
+		if it.Action != tasksync.ActionUpdate {
+			continue
+		}
+		prev, err := tmc.TaskSvc.Upstreams(ctx, it.TaskID)
+		if err != nil {
+			fail(it, fmt.Errorf("dependencies: %w", err))
+			delete(targets, it)
+			continue
+		}
+		keep := make([]int64, 0, len(prev))
+		for _, u := range prev {
+			if slices.Contains(ups, u) {
+				keep = append(keep, u)
+			}
+		}
+		if len(keep) != len(prev) {
+			if err := tmc.TaskSvc.SetUpstreams(ctx, it.TaskID, keep); err != nil {
+				fail(it, fmt.Errorf("dependencies: %w", err))
+				delete(targets, it)
+			}
+		}
+	}
+	for _, it := range plan.Items {
+		ups, ok := targets[it]
+		if !ok {
+			continue
+		}
+		if err := tmc.TaskSvc.SetUpstreams(ctx, it.TaskID, ups); err != nil {
+			fail(it, fmt.Errorf("dependencies: %w", err))
+		}
+	}
+	return failed
+}
+
+// rejectManaged 托管任务只能经同步修改：返回 409 task_managed 并返回 true。
+func rejectManaged(w http.ResponseWriter, t *model.Task) bool {
+	if t == nil || !t.Managed {
+		return false
+	}
+	w.WriteHeader(http.StatusConflict)
+	writeJSON(w, map[string]string{"error": "task_managed", "sync_source": t.SyncSource})
+	return true
+}
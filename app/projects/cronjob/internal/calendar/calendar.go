@@ -0,0 +1,201 @@
+// Package calendar 营业日历：判断某日是否营业日，并把 cron 调度包装为按日历过滤或顺延的调度。
+//
+// 日期一律按传入时间所在时区的本地日期判断（调度中即任务时区）。
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
+)
+
+// maxScanDays 查找营业日/非营业日时最多向后搜索的天数，超过视为不再触发。
+const maxScanDays = 400
+
+// maxShiftIterations 顺延模式下单次 Next 最多检查的原始触发点数（顺延模式适用于日级等低频调度）。
+const maxShiftIterations = 20000
+
+// Calendar 不可变的日历快照。
+type Calendar struct {
+	Name  string
+	Kind  consts.CalendarKind
+	dates map[int]struct{} // yyyymmdd
+	last  int              // 最晚的日期；TRADING_DAYS 超出后不再有营业日
+}
+
+// New 由日期列表（YYYY-MM-DD）构造日历。
+func New(name string, kind consts.CalendarKind, dates []string) (*Calendar, error) {
+	if kind != consts.CalendarKindHolidays && kind != consts.CalendarKindTradingDays {
+		return nil, fmt.Errorf("invalid calendar kind %q", kind)
+	}
+	c := &Calendar{Name: name, Kind: kind, dates: make(map[int]struct{}, len(dates))}
+	for _, s := range dates {
+		d, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q: must be YYYY-MM-DD", s)
+		}
+		k := dayKey(d)
+		c.dates[k] = struct{}{}
+		if k > c.last {
+			c.last = k
+		}
+	}
+	return c, nil
+}
+
+// NormalizeDates 校验、去重并升序排列日期。
+func NormalizeDates(dates []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(dates))
+	out := make([]string, 0, len(dates))
+	for _, s := range dates {
+		d, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q: must be YYYY-MM-DD", s)
+		}
+		s = d.Format(time.DateOnly)
+		if _, ok := seen[s]; !ok {
+			seen[s] = struct{}{}
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func dayKey(t time.Time) int {
+	y, m, d := t.Date()
+	return y*10000 + int(m)*100 + d
+}
+
+// IsBusinessDay t 的本地日期是否为营业日。
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	_, listed := c.dates[dayKey(t)]
+	if c.Kind == consts.CalendarKindTradingDays {
+		return listed
+	}
+	wd := t.Weekday()
+	return wd != time.Saturday && wd != time.Sunday && !listed
+}
+
+// exhausted t 之后是否再无营业日（仅 TRADING_DAYS 日历在列表结束后成立）。
+func (c *Calendar) exhausted(t time.Time) bool {
+	return c.Kind == consts.CalendarKindTradingDays && dayKey(t) > c.last
+}
+
+// BusinessDays 返回 [from, to] 内的营业日（按 from 的时区）。
+func (c *Calendar) BusinessDays(from, to time.Time) []string {
+	var out []string
+	y, m, d := from.Date()
+	for day := time.Date(y, m, d, 0, 0, 0, 0, from.Location()); !day.After(to); day = day.AddDate(0, 0, 1) {
+		if c.IsBusinessDay(day) {
+			out = append(out, day.Format(time.DateOnly))
+		}
+	}
+	return out
+}
+
+// NextBusinessDay 严格晚于 t 所在日期的第一个营业日中与 t 相同挂钟时刻的时间；找不到时返回零值。
+func (c *Calendar) NextBusinessDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	for i := 1; i <= maxScanDays; i++ {
+		n := time.Date(y, m, d+i, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+		if c.exhausted(n) {
+			return time.Time{}
+		}
+		if c.IsBusinessDay(n) {
+			return n
+		}
+	}
+	return time.Time{}
+}
+
+// PrevBusinessDay 严格早于 t 所在日期的最后一个营业日中与 t 相同挂钟时刻的时间；找不到时返回零值。
+func (c *Calendar) PrevBusinessDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	for i := 1; i <= maxScanDays; i++ {
+		n := time.Date(y, m, d-i, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+		if c.IsBusinessDay(n) {
+			return n
+		}
+	}
+	return time.Time{}
+}
+
+// Wrap 按日历用法包装 cron 调度。
+func Wrap(base cron.Schedule, c *Calendar, mode consts.CalendarMode) cron.Schedule {
+	switch mode {
+	case consts.CalendarModeNonBusinessDays:
+		return &dayFilter{base: base, cal: c, want: false}
+	case consts.CalendarModeNextBusinessDay:
+		return &shiftSchedule{base: base, cal: c}
+	default:
+		return &dayFilter{base: base, cal: c, want: true}
+	}
+}
+
+// dayFilter 只保留落在营业日（want=true）或非营业日（want=false）的触发点。
+type dayFilter struct {
+	base cron.Schedule
+	cal  *Calendar
+	want bool
+}
+
+func (f *dayFilter) Next(after time.Time) time.Time {
+	t := after
+	for i := 0; i < maxScanDays; i++ {
+		n := f.base.Next(t)
+		if n.IsZero() || (f.want && f.cal.exhausted(n)) {
+			return time.Time{}
+		}
+		if f.cal.IsBusinessDay(n) == f.want {
+			return n
+		}
+		// 跳到当天最后一秒，避免高频表达式在非目标日逐个检查
+		y, m, d := n.Date()
+		t = time.Date(y, m, d, 23, 59, 59, 0, n.Location())
+		if !t.After(n) {
+			t = n
+		}
+	}
+	return time.Time{}
+}
+
+// shiftSchedule 非营业日的触发顺延到下一个营业日的同一时刻；顺延后与营业日自身的触发重合时只算一次。
+type shiftSchedule struct {
+	base cron.Schedule
+	cal  *Calendar
+}
+
+// Next 顺延后的触发可能来自 after 之前的非营业日，因此从 after 所在日之前连续非营业日的起点开始检查，
+// 取严格晚于 after 的最小有效时间。原始触发点晚于当前最优解时即可停止（有效时间不早于原始时间）。
+func (s *shiftSchedule) Next(after time.Time) time.Time {
+	y, m, d := after.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, after.Location())
+	for i := 0; i < maxScanDays; i++ {
+		prev := start.AddDate(0, 0, -1)
+		if s.cal.IsBusinessDay(prev) {
+			break
+		}
+		start = prev
+	}
+	var best time.Time
+	t := start.Add(-time.Second)
+	for i := 0; i < maxShiftIterations; i++ {
+		n := s.base.Next(t)
+		if n.IsZero() || (!best.IsZero() && !n.Before(best)) || s.cal.exhausted(n) {
+			break
+		}
+		eff := n
+		if !s.cal.IsBusinessDay(n) {
+			eff = s.cal.NextBusinessDay(n)
+		}
+		if !eff.IsZero() && eff.After(after) && (best.IsZero() || eff.Before(best)) {
+			best = eff
+		}
+		t = n
+	}
+	return best
+}
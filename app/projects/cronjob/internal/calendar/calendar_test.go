@@ -0,0 +1,80 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
+)
+
+func mustTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestWrapModes(t *testing.T) {
+	loc, _ := cron.LoadLocation("Asia/Shanghai")
+	// 2026-10-01 ~ 10-07 国庆休市（周四至下周三）
+	cal, err := New("SSE", consts.CalendarKindHolidays, []string{"2026-10-01", "2026-10-02", "2026-10-05", "2026-10-06", "2026-10-07"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, _ := cron.Parse("0 30 9 * * *")
+	cases := []struct {
+		mode       consts.CalendarMode
+		from, want string
+	}{
+		{consts.CalendarModeBusinessDays, "2026-09-30T10:00:00+08:00", "2026-10-08T09:30:00+08:00"},
+		{consts.CalendarModeBusinessDays, "2026-09-29T10:00:00+08:00", "2026-09-30T09:30:00+08:00"},
+		{consts.CalendarModeNonBusinessDays, "2026-09-29T10:00:00+08:00", "2026-10-01T09:30:00+08:00"},
+		{consts.CalendarModeNonBusinessDays, "2026-10-07T10:00:00+08:00", "2026-10-10T09:30:00+08:00"},
+		// 顺延：假期内的触发都落到 10-08 09:30，只算一次
+		{consts.CalendarModeNextBusinessDay, "2026-09-30T10:00:00+08:00", "2026-10-08T09:30:00+08:00"},
+		{consts.CalendarModeNextBusinessDay, "2026-10-03T10:00:00+08:00", "2026-10-08T09:30:00+08:00"},
+		{consts.CalendarModeNextBusinessDay, "2026-10-08T09:30:00+08:00", "2026-10-09T09:30:00+08:00"},
+	}
+	for _, c := range cases {
+		got := Wrap(base, cal, c.mode).Next(mustTime(t, c.from).In(loc))
+		if want := mustTime(t, c.want); !got.Equal(want) {
+			t.Errorf("%s from %s: got %s want %s", c.mode, c.from, got, want)
+		}
+	}
+}
+
+func TestShiftKeepsOwnEarlierFire(t *testing.T) {
+	// 周六 18:00、周日 09:00/18:00 顺延到周一同一时刻，与周一自身的触发重合时只算一次
+	cal, _ := New("X", consts.CalendarKindHolidays, nil)
+	base, _ := cron.Parse("0 0 9,18 * * *")
+	s := Wrap(base, cal, consts.CalendarModeNextBusinessDay)
+	want := []string{"2026-10-19T09:00:00Z", "2026-10-19T18:00:00Z", "2026-10-20T09:00:00Z"}
+	n := mustTime(t, "2026-10-17T10:00:00Z") // Sat
+	for _, w := range want {
+		if n = s.Next(n); !n.Equal(mustTime(t, w)) {
+			t.Fatalf("got %s want %s", n, w)
+		}
+	}
+}
+
+func TestTradingDaysExhausted(t *testing.T) {
+	cal, _ := New("T", consts.CalendarKindTradingDays, []string{"2026-10-19", "2026-10-21"})
+	base, _ := cron.Parse("@daily")
+	s := Wrap(base, cal, consts.CalendarModeBusinessDays)
+	n := s.Next(mustTime(t, "2026-10-19T12:00:00Z"))
+	if !n.Equal(mustTime(t, "2026-10-21T00:00:00Z")) {
+		t.Fatalf("unexpected next %s", n)
+	}
+	if n = s.Next(n); !n.IsZero() {
+		t.Fatalf("calendar exhausted, expected zero, got %s", n)
+	}
+	if days := cal.BusinessDays(mustTime(t, "2026-10-18T00:00:00Z"), mustTime(t, "2026-10-25T00:00:00Z")); len(days) != 2 {
+		t.Fatalf("unexpected business days %v", days)
+	}
+	if _, err := New("bad", "WEEKLY", nil); err == nil {
+		t.Fatal("invalid kind should fail")
+	}
+}
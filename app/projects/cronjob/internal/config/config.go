@@ -15,19 +15,47 @@ func GetBizConfig() *BizConfig {
 }
 
 type SchedulerConfig struct {
-	PollInterval time.Duration `yaml:"poll_interval"`
+	PollInterval        time.Duration `yaml:"poll_interval"`
+	MisfireMaxCatchup   int           `yaml:"misfire_max_catchup"`   // FIRE_ALL 单次最多补跑的触发数（保留最近的），默认 100
+	MisfireMaxLookback  time.Duration `yaml:"misfire_max_lookback"`  // 补偿回溯上限，早于 now-lookback 的触发不再考虑，默认 24h
+	CursorFlushInterval time.Duration `yaml:"cursor_flush_interval"` // 区间内无触发点的游标批量持久化间隔，默认 30s
 }
 
 type ExecutorConfig struct {
-	WorkerPoolSize int           `yaml:"worker_pool_size"`
-	RequestTimeout time.Duration `yaml:"request_timeout"`
+	WorkerPoolSize  int           `yaml:"worker_pool_size"`
+	RequestTimeout  time.Duration `yaml:"request_timeout"`
+	SecretEnvPrefix string        `yaml:"secret_env_prefix"` // 模板函数 secret "X" 读取环境变量 <prefix>X，默认 CRONJOB_SECRET_
+	KillGrace       time.Duration `yaml:"kill_grace"`        // 取消请求发出后等待下游确认的宽限期，超时标记 KILLED，默认 30s
+	CancelPath      string        `yaml:"cancel_path"`       // 目标服务的协作取消接口，默认 /tasks/cancel
+
+	GRPC        GRPCBackendConfig        `yaml:"grpc"`
+	Command     CommandBackendConfig     `yaml:"command"`
+	RedisStream RedisStreamBackendConfig `yaml:"redis_stream"`
+}
+
+// GRPCBackendConfig GRPC 执行器后端：按 descriptor set 动态编解码请求/响应
+type GRPCBackendConfig struct {
+	DescriptorSets []string `yaml:"descriptor_sets"` // protoc --include_imports --descriptor_set_out 生成的文件
+}
+
+// CommandBackendConfig COMMAND 执行器后端：只允许执行白名单中的命令；白名单为空时该后端不可用
+type CommandBackendConfig struct {
+	AllowedCommands []string      `yaml:"allowed_commands"` // 允许的命令（与任务 target_path 完全一致才可执行）
+	Dir             string        `yaml:"dir"`              // 默认工作目录
+	DefaultTimeout  time.Duration `yaml:"default_timeout"`  // 任务未配置 timeout 时的超时，默认 10m
+	MaxOutputBytes  int           `yaml:"max_output_bytes"` // stdout / stderr 各自保留的最大字节数（保留末尾），默认 64KiB
+}
+
+// RedisStreamBackendConfig REDIS_STREAM 执行器后端
+type RedisStreamBackendConfig struct {
+	MaxLen int64 `yaml:"maxlen"` // 任务未配置 maxlen 时 XADD 的 MAXLEN ~ 上限；0 不裁剪
 }
 
 type ScannerConfig struct {
 	Interval                    time.Duration `yaml:"interval"`
 	BatchLimit                  int           `yaml:"batch_limit"`
 	SyncRunStuckTimeoutSeconds  int           `yaml:"sync_run_stuck_timeout_seconds"` // RUNNING SYNC runs exceeding this age considered stuck; 0 disables
-	ProgressCleanupGraceSeconds int           `yaml:"progress_cleanup_grace_seconds"` // grace after terminal end_time before progress cleared; 0 immediate
+	ProgressCleanupGraceSeconds int           `yaml:"progress_cleanup_grace_seconds"` // 已结束 Run 的进度在 GET /runs/progress 中保留的秒数; 0 结束即不再列出
 }
 
 type CleanupConfig struct {
@@ -42,16 +70,104 @@ type CallbackEndpointsConfig struct {
 	CallbackPath string `yaml:"callback_path"` // e.g. /runs/{run_id}/callback
 }
 
+// HAConfig 多副本部署时的选主配置：基于 scheduler_locks 表的租约，只有 leader 执行调度 scan。
+// leader 异常退出后，其他副本最迟在 lease_duration + renew_interval 内接管。
+type HAConfig struct {
+	Enabled       bool          `yaml:"enabled"`        // 关闭时单实例模式，本副本始终视为 leader
+	LockName      string        `yaml:"lock_name"`      // 租约名，默认 scheduler_engine
+	OwnerID       string        `yaml:"owner_id"`       // 副本标识，默认 hostname-pid-随机后缀
+	LeaseDuration time.Duration `yaml:"lease_duration"` // 租约时长，默认 15s
+	RenewInterval time.Duration `yaml:"renew_interval"` // 续约/抢占间隔，默认 lease_duration/3
+}
+
+// DagConfig 任务依赖解析：周期性评估已结束 Run 的下游任务。
+type DagConfig struct {
+	Interval   time.Duration `yaml:"interval"`    // 解析周期，默认 5s；Run 结束到下游触发的延迟约为 1~2 个周期
+	BatchLimit int           `yaml:"batch_limit"` // 每周期最多处理的 Run 数，默认 200
+}
+
+// BackfillConfig 历史区间补跑：周期性按批次的并行度派发 Run。
+type BackfillConfig struct {
+	Interval       time.Duration `yaml:"interval"`         // 派发周期，默认 2s
+	MaxRuns        int           `yaml:"max_runs"`         // 单个批次最多的触发点数，默认 1000
+	MaxParallelCap int           `yaml:"max_parallel_cap"` // max_parallel 上限，默认 20
+}
+
+// CalendarConfig 营业日历：启动时从 dir 下的 YAML 文件导入日历，并周期性从数据库刷新缓存（多副本间保持一致）。
+type CalendarConfig struct {
+	Dir             string        `yaml:"dir"`              // 日历文件目录（*.yaml），为空则不导入；API 修改过的日历不会被文件覆盖
+	RefreshInterval time.Duration `yaml:"refresh_interval"` // 缓存刷新周期，默认 30s
+}
+
+// AlertConfig 告警：规则按任务配置（alert_rules 表），通知渠道在此配置并由规则按名称引用；多副本时仅 leader 评估。
+type AlertConfig struct {
+	Interval        time.Duration                 `yaml:"interval"`         // 评估周期，默认 10s；Run 结束到告警约 1~2 个周期
+	BatchLimit      int                           `yaml:"batch_limit"`      // 每周期最多评估的 Run 数，默认 200
+	DefaultSuppress time.Duration                 `yaml:"default_suppress"` // 规则未配置 suppress_sec 时的抑制窗口，默认 30m
+	SlowWindow      int                           `yaml:"slow_window"`      // SLOW_RUN 计算 P95 时取的最近成功 Run 数，默认 100
+	SLALookback     time.Duration                 `yaml:"sla_lookback"`     // SLA_MISSED 只检查截止时间在此时长内的触发，默认 24h
+	SendTimeout     time.Duration                 `yaml:"send_timeout"`     // 单个渠道单次发送超时，默认 10s
+	DefaultChannels []string                      `yaml:"default_channels"` // 规则未指定渠道时使用
+	Channels        map[string]AlertChannelConfig `yaml:"channels"`         // 渠道名 -> 配置
+}
+
+// AlertChannelConfig 通知渠道：type 为 webhook / smtp / file / log，其余字段按类型取用。
+// 模板为 text/template，`.` 为告警消息（.Kind / .TaskName / .RunID / .Title / .Text / .At ...）。
+type AlertChannelConfig struct {
+	Type string `yaml:"type"`
+	// webhook
+	URL          string            `yaml:"url"`
+	Method       string            `yaml:"method"` // 默认 POST
+	Headers      map[string]string `yaml:"headers"`
+	BodyTemplate string            `yaml:"body_template"` // webhook 为空时发送消息 JSON；smtp 为空时取 .Text
+	Timeout      time.Duration     `yaml:"timeout"`
+	// smtp
+	Host               string   `yaml:"host"`
+	Port               int      `yaml:"port"`
+	Username           string   `yaml:"username"`
+	Password           string   `yaml:"password"`
+	From               string   `yaml:"from"`
+	To                 []string `yaml:"to"`
+	TLS                bool     `yaml:"tls"` // 直接 TLS 连接（465）；否则服务器支持时 STARTTLS
+	InsecureSkipVerify bool     `yaml:"insecure_skip_verify"`
+	SubjectTemplate    string   `yaml:"subject_template"` // 默认 .Title
+	// file
+	Path string `yaml:"path"` // 每条告警追加一行 JSON
+}
+
+// TaskSyncConfig 声明式任务同步：dir 下的 YAML 文件声明任务，经 /api/v1/tasks/sync/plan 预览、/apply 应用；
+// 同步管理的任务在 API 中只读。
+type TaskSyncConfig struct {
+	Dir string `yaml:"dir"` // 任务声明目录（*.yaml / *.yml，含子目录）；为空时只能在请求体中提交文件
+}
+
+// NamespaceConfig 命名空间：空间定义存数据库并周期性刷新缓存；admins 可管理所有空间与任务，并负责创建 / 修改空间。
+type NamespaceConfig struct {
+	RefreshInterval time.Duration `yaml:"refresh_interval"` // 缓存刷新周期，默认 30s
+	Admins          []string      `yaml:"admins"`           // 管理员（X-Cronjob-User）；为空时不做归属校验，任何人可管理
+}
+
 type BizConfig struct {
 	Scheduler         SchedulerConfig         `yaml:"scheduler"`
 	Executor          ExecutorConfig          `yaml:"executor"`
 	Scanner           ScannerConfig           `yaml:"scanner"`
 	Cleanup           CleanupConfig           `yaml:"cleanup"`
 	CallbackEndpoints CallbackEndpointsConfig `yaml:"callback_endpoints"`
+	HA                HAConfig                `yaml:"ha"`
+	Dag               DagConfig               `yaml:"dag"`
+	Backfill          BackfillConfig          `yaml:"backfill"`
+	Calendar          CalendarConfig          `yaml:"calendar"`
+	Alert             AlertConfig             `yaml:"alert"`
+	TaskSync          TaskSyncConfig          `yaml:"task_sync"`
+	Namespace         NamespaceConfig         `yaml:"namespace"`
 }
 
 func init() {
 	bizConfig = &BizConfig{}
-	app := application.GetApp()
+}
+
+// Register 把业务配置挂到 application，需在 app.Run 之前调用（见 cmd/main.go）。
+// 不放在 init 中：GetApp 会解析命令行参数，导入本包的测试二进制会因 -test.* 参数退出。
+func Register(app *application.App) {
 	app.SetBizConfig(bizConfig)
 }
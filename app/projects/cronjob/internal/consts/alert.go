@@ -0,0 +1,47 @@
+package consts
+
+// AlertKind 告警规则类型
+// FAILURE: Run 最终失败（FAILED / TIMEOUT / CALLBACK_FAILED / FAILED_TIMEOUT / KILLED，且不再重试）
+// CONSECUTIVE_FAILURES: 最近 threshold 次有效执行全部最终失败
+// SLOW_RUN: 成功 Run 的耗时超过该任务近期成功耗时 P95 × factor
+// SLA_MISSED: 触发后 sla_sec 秒内（含重试）仍未成功
+// RECOVERY: 失败后的首次成功
+type AlertKind string
+
+const (
+	AlertFailure             AlertKind = "FAILURE"
+	AlertConsecutiveFailures AlertKind = "CONSECUTIVE_FAILURES"
+	AlertSlowRun             AlertKind = "SLOW_RUN"
+	AlertSLAMissed           AlertKind = "SLA_MISSED"
+	AlertRecovery            AlertKind = "RECOVERY"
+)
+
+// AlertStatus 告警记录（alert_history.status）：每条告警在每个渠道上记一条。
+type AlertStatus string
+
+const (
+	AlertSent       AlertStatus = "SENT"       // 已发送
+	AlertSuppressed AlertStatus = "SUPPRESSED" // 抑制窗口内已发送过同一告警，未发送
+	AlertFailed     AlertStatus = "FAILED"     // 发送失败（含渠道不存在）
+)
+
+// 通知渠道类型（biz_config.alert.channels.<name>.type）
+const (
+	AlertChannelWebhook = "webhook"
+	AlertChannelSMTP    = "smtp"
+	AlertChannelFile    = "file"
+	AlertChannelLog     = "log"
+)
+
+// FailedStatuses 触发失败类告警的 Run 状态。
+var FailedStatuses = []RunStatus{Failed, Timeout, CallbackFailed, FailedTimeout, Killed}
+
+// IsFailure 是否为失败状态（不含取消与各类跳过）。
+func (s RunStatus) IsFailure() bool {
+	for _, f := range FailedStatuses {
+		if s == f {
+			return true
+		}
+	}
+	return false
+}
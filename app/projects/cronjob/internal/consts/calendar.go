@@ -0,0 +1,31 @@
+package consts
+
+// CalendarKind 日历的日期列表含义
+// HOLIDAYS: 列出休市日，周一至周五中未列出的日期为营业日
+// TRADING_DAYS: 显式列出全部营业日，未列出的日期（含超出列表范围的日期）均为非营业日
+type CalendarKind string
+
+const (
+	CalendarKindHolidays    CalendarKind = "HOLIDAYS"
+	CalendarKindTradingDays CalendarKind = "TRADING_DAYS"
+)
+
+// CalendarMode 任务如何使用日历（日期按任务时区判断）
+// BUSINESS_DAYS: 仅在营业日触发（默认）
+// NON_BUSINESS_DAYS: 跳过营业日，仅在非营业日触发
+// NEXT_BUSINESS_DAY: 落在非营业日的触发顺延到下一个营业日的同一时刻
+type CalendarMode string
+
+const (
+	CalendarModeBusinessDays    CalendarMode = "BUSINESS_DAYS"
+	CalendarModeNonBusinessDays CalendarMode = "NON_BUSINESS_DAYS"
+	CalendarModeNextBusinessDay CalendarMode = "NEXT_BUSINESS_DAY"
+)
+
+// 日历来源：FILE 由本地文件导入，启动时随文件更新；经 API 修改后变为 API，不再被文件覆盖
+const (
+	CalendarSourceFile = "FILE"
+	CalendarSourceAPI  = "API"
+)
+
+const DEFAULT_CALENDAR_MODE CalendarMode = CalendarModeBusinessDays
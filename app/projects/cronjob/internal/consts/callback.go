@@ -0,0 +1,25 @@
+package consts
+
+// CallbackStatus 回调记录状态（async_callbacks.status）：RECEIVED 表示回调生效并结束了 Run，其余均为 IGNORED。
+type CallbackStatus string
+
+const (
+	CallbackReceived CallbackStatus = "RECEIVED"
+	CallbackIgnored  CallbackStatus = "IGNORED"
+)
+
+// CallbackOutcome 回调的处理结果（async_callbacks.outcome）。
+type CallbackOutcome string
+
+const (
+	CallbackAccepted       CallbackOutcome = "accepted"        // 结束了 CALLBACK_PENDING 的 Run
+	CallbackDuplicate      CallbackOutcome = "duplicate"       // Run 已按相同结果结束（重复回调）
+	CallbackConflict       CallbackOutcome = "conflict"        // Run 已以其他结果结束，不覆盖
+	CallbackNotPending     CallbackOutcome = "not_pending"     // Run 尚未进入 CALLBACK_PENDING
+	CallbackInvalidToken   CallbackOutcome = "invalid_token"   // 缺少或错误的 callback token
+	CallbackInvalidPayload CallbackOutcome = "invalid_payload" // 请求体不合法或 result 未知
+	CallbackError          CallbackOutcome = "error"           // 更新 Run 失败
+)
+
+// CALLBACK_TOKEN_HEADER 回调携带 token 的请求头（也可放在请求体 callback_token 字段）。
+const CALLBACK_TOKEN_HEADER = "X-Cronjob-Callback-Token"
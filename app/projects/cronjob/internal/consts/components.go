@@ -6,11 +6,32 @@ const (
 	COMP_SVC_EXECUTOR         = "executor"
 	COMP_DAO_RUN              = "run_dao"
 	COMP_DAO_TASK             = "task_dao"
+	COMP_DAO_LOCK             = "lock_dao"         // scheduler_locks lease
 	COMP_SVC_TASK             = "task_service"     // new: task service with in-memory cache
 	COMP_SVC_RUN              = "run_service"      // new: run service delegating to run dao
 	COMP_SVC_RUN_PROGRESS     = "run_progress_mgr" // ephemeral progress manager
 	COMP_SVC_CALLBACK_SCANNER = "callback_timeout_scanner"
 	COMP_CTRL_RUN_MGMT        = "run_mgmt_ctrl"
-	COMP_CTRL_META_MGMT       = "meta_mgmt_ctrl" // new: meta controller for frontend
-	COMP_SVC_RUN_CLEANUP      = "run_cleanup"    // background run cleanup
+	COMP_CTRL_META_MGMT       = "meta_mgmt_ctrl"   // new: meta controller for frontend
+	COMP_SVC_RUN_CLEANUP      = "run_cleanup"      // background run cleanup
+	COMP_SVC_LEADER           = "scheduler_leader" // HA leader election
+	COMP_SVC_DAG              = "dag_resolver"     // task dependency resolver
+	COMP_DAO_BACKFILL         = "backfill_dao"
+	COMP_SVC_BACKFILL         = "backfill_manager" // historical range backfill
+	COMP_CTRL_BACKFILL        = "backfill_ctrl"
+	COMP_DAO_CALENDAR         = "calendar_dao"
+	COMP_SVC_CALENDAR         = "calendar_service" // business calendars + blackout windows
+	COMP_CTRL_CALENDAR        = "calendar_ctrl"
+	COMP_DAO_ALERT            = "alert_dao"
+	COMP_SVC_ALERT            = "alert_manager" // run alert rules + notifiers
+	COMP_CTRL_ALERT           = "alert_ctrl"
+	COMP_DAO_TASK_REVISION    = "task_revision_dao" // immutable task revisions
+	COMP_DAO_ANALYTICS        = "analytics_dao"
+	COMP_SVC_ANALYTICS        = "analytics_service" // run analytics + SLA reports
+	COMP_CTRL_ANALYTICS       = "analytics_ctrl"
+	COMP_DAO_NAMESPACE        = "namespace_dao"
+	COMP_SVC_NAMESPACE        = "namespace_service" // namespaces: quotas, target restrictions, ownership
+	COMP_CTRL_NAMESPACE       = "namespace_ctrl"
+	COMP_DAO_PAUSE            = "pause_dao"
+	COMP_SVC_PAUSE            = "scheduler_pause" // global scheduler pause / resume
 )
@@ -0,0 +1,17 @@
+package consts
+
+// ExecutorKind 执行器后端：决定 Run 以何种方式发送到下游
+// HTTP: 经 http_clients 中 target_service 对应的客户端发送 JSON 请求（默认）
+// GRPC: 经 grpc_clients 中 target_service 对应的连接发起 unary 调用，按 descriptor set 动态编解码
+// COMMAND: 在本机执行 target_path 指定的命令（须在 executor.command.allowed_commands 中），捕获 stdout/stderr
+// REDIS_STREAM: 以 XADD 发布到 target_path 指定的 Redis Stream，发布成功即视为完成（fire-and-forget）
+type ExecutorKind string
+
+const (
+	ExecutorHTTP        ExecutorKind = "HTTP"
+	ExecutorGRPC        ExecutorKind = "GRPC"
+	ExecutorCommand     ExecutorKind = "COMMAND"
+	ExecutorRedisStream ExecutorKind = "REDIS_STREAM"
+)
+
+const DEFAULT_EXECUTOR ExecutorKind = ExecutorHTTP
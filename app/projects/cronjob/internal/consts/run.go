@@ -7,6 +7,7 @@ func (s RunStatus) String() string { return string(s) }
 
 const (
 	Scheduled       RunStatus = "SCHEDULED"        // 已调度，等待执行
+	Queued          RunStatus = "QUEUED"           // QUEUE 策略下排队，等待并发槽位
 	Running         RunStatus = "RUNNING"          // 正在执行
 	Success         RunStatus = "SUCCESS"          // 执行成功
 	Failed          RunStatus = "FAILED"           // 执行失败
@@ -20,6 +21,51 @@ const (
 	FailureSkip     RunStatus = "FAILURE_SKIP"     // 因失败跳过
 	ConcurrentSkip  RunStatus = "CONCURRENT_SKIP"  // 因并发限制跳过
 	OverlapSkip     RunStatus = "OVERLAP_SKIP"     // 因重叠限制跳过
+	Killed          RunStatus = "KILLED"           // 取消请求在宽限期内未获下游确认，强制结束（下游状态未知，不自动重试）
+)
+
+// FinishedStatuses Run 已结束的状态（不会再变化）；失败的 Run 仍可能由重试派生新的 Run。
+var FinishedStatuses = []RunStatus{Success, Failed, Timeout, CallbackFailed, FailedTimeout, Canceled, Killed, Skipped, FailureSkip, ConcurrentSkip, OverlapSkip}
+
+// Finished 是否为结束状态。
+func (s RunStatus) Finished() bool {
+	for _, f := range FinishedStatuses {
+		if s == f {
+			return true
+		}
+	}
+	return false
+}
+
+// CancelReason Run 被取消（或执行超时）的原因（task_runs.cancel_reason）
+type CancelReason string
+
+const (
+	CancelReasonUser             CancelReason = "user"                // 通过 API 取消
+	CancelReasonExecutionTimeout CancelReason = "execution_timeout"   // 超过任务的 execution_timeout_sec
+	CancelReasonOverlap          CancelReason = "overlap_cancel_prev" // overlap_action=CANCEL_PREV，被新一轮取代
+	CancelReasonBackfill         CancelReason = "backfill_canceled"   // 所属补跑批次被取消
+	CancelReasonShutdown         CancelReason = "shutdown"            // 服务停止
+)
+
+// TriggerType Run 的触发来源
+type TriggerType string
+
+const (
+	TriggerCron       TriggerType = "CRON"       // 调度器按 cron 触发
+	TriggerManual     TriggerType = "MANUAL"     // 手动触发
+	TriggerDependency TriggerType = "DEPENDENCY" // 上游完成后触发
+	TriggerRerun      TriggerType = "RERUN"      // 从该节点起向下游重跑
+	TriggerBackfill   TriggerType = "BACKFILL"   // 历史区间补跑
+)
+
+// BackfillStatus 补跑批次状态
+type BackfillStatus string
+
+const (
+	BackfillRunning   BackfillStatus = "RUNNING"   // 仍有触发点未派发或 Run 未结束
+	BackfillCompleted BackfillStatus = "COMPLETED" // 全部触发点已派发且 Run 均已结束
+	BackfillCanceled  BackfillStatus = "CANCELED"  // 被整体取消
 )
 
 const (
@@ -8,12 +8,13 @@ const (
 )
 
 // ConcurrencyPolicy 并发策略
-// QUEUE: 达到并发上限时排队（不再新建新的 scheduled，保留已有排队的 scheduled）
+// QUEUE: 达到并发上限时排队：Run 以 QUEUED 状态持久化，槽位空出后按 FIFO 启动（队列深度与溢出见 QueueOverflow）
 // SKIP: 达到并发上限时直接跳过该触发
 // PARALLEL: 忽略并发上限（仅使用全局 worker 池大小限制）
 type ConcurrencyPolicy string
 
 const (
+	ConcurrencyQueue    ConcurrencyPolicy = "QUEUE"
 	ConcurrencySkip     ConcurrencyPolicy = "SKIP"
 	ConcurrencyParallel ConcurrencyPolicy = "PARALLEL"
 )
@@ -44,6 +45,52 @@ const (
 	FailureActionRetry  FailureAction = "RETRY"
 )
 
+// MisfirePolicy 错过触发（服务停机 / tick 耗时超过 poll_interval 等）时的补偿策略
+// SKIP: 丢弃错过的触发，仅当最近一次触发仍在 misfire_grace_sec 内时执行一次（默认）
+// FIRE_ONCE: 补跑一次（取最近一次错过的触发时间）
+// FIRE_ALL: 逐个补跑所有错过的触发，数量受 scheduler.misfire_max_catchup 限制（保留最近的）
+type MisfirePolicy string
+
+const (
+	MisfireSkip     MisfirePolicy = "SKIP"
+	MisfireFireOnce MisfirePolicy = "FIRE_ONCE"
+	MisfireFireAll  MisfirePolicy = "FIRE_ALL"
+)
+
+// QueueOverflow QUEUE 策略下排队数达到 queue_max_depth 时的处理
+// DROP_NEW: 丢弃本次触发，记录 CONCURRENT_SKIP（默认）
+// DROP_OLDEST: 丢弃队首（最早排队）的 Run（标记 CONCURRENT_SKIP），本次触发入队
+type QueueOverflow string
+
+const (
+	QueueOverflowDropNew    QueueOverflow = "DROP_NEW"
+	QueueOverflowDropOldest QueueOverflow = "DROP_OLDEST"
+)
+
+// ScheduleMode 任务的触发来源
+// CRON: 仅按 cron 表达式触发（默认）
+// DEPENDENCY: 仅在上游任务完成后触发（cron_expr 可为空）
+// BOTH: 两者皆可
+type ScheduleMode string
+
+const (
+	ScheduleModeCron       ScheduleMode = "CRON"
+	ScheduleModeDependency ScheduleMode = "DEPENDENCY"
+	ScheduleModeBoth       ScheduleMode = "BOTH"
+)
+
+// TriggerRule 依赖触发规则：按同一 logical_date 上各上游最近一次 Run 的结果判断
+// ALL_SUCCESS: 全部上游成功（默认）；任一上游最终失败则本任务记为 SKIPPED
+// ALL_DONE: 全部上游结束即可，不论成败
+// ONE_SUCCESS: 任一上游成功即触发；全部结束仍无成功则记为 SKIPPED
+type TriggerRule string
+
+const (
+	TriggerRuleAllSuccess TriggerRule = "ALL_SUCCESS"
+	TriggerRuleAllDone    TriggerRule = "ALL_DONE"
+	TriggerRuleOneSuccess TriggerRule = "ONE_SUCCESS"
+)
+
 // ExecType 执行类型
 // SYNC: 同步执行
 // ASYNC: 异步执行（回调 Phase2）
@@ -54,8 +101,36 @@ const (
 	ExecTypeAsync ExecType = "ASYNC"
 )
 
+// TaskRevisionAction 任务版本历史中的变更类型
+// BASELINE: 升级后首次变更前补记的原始定义（操作人 system）
+// ROLLBACK: 回滚到历史版本的定义（生成新版本）
+type TaskRevisionAction string
+
+const (
+	RevisionBaseline TaskRevisionAction = "BASELINE"
+	RevisionCreate   TaskRevisionAction = "CREATE"
+	RevisionUpdate   TaskRevisionAction = "UPDATE"
+	RevisionEnable   TaskRevisionAction = "ENABLE"
+	RevisionDisable  TaskRevisionAction = "DISABLE"
+	RevisionDelete   TaskRevisionAction = "DELETE"
+	RevisionRollback TaskRevisionAction = "ROLLBACK"
+)
+
+// ActorHeader 请求方身份（由网关注入），记入任务版本历史的 actor；缺失时记为 anonymous@<来源 IP>
+const ActorHeader = "X-Cronjob-User"
+
 const (
 	DEFAULT_JSON_STR                     = "{}"
 	DEFAULT_OVERLAP_ACTION OverlapAction = OverlapActionAllow
 	DEFAULT_FAILURE_ACTION FailureAction = FailureActionRunNew
+	DEFAULT_MISFIRE_POLICY MisfirePolicy = MisfireSkip
+
+	DEFAULT_CONCURRENCY_POLICY ConcurrencyPolicy = ConcurrencyQueue // 与表默认值一致
+	DEFAULT_QUEUE_OVERFLOW     QueueOverflow     = QueueOverflowDropNew
+	DEFAULT_QUEUE_MAX_DEPTH                      = 100
+
+	DEFAULT_SCHEDULE_MODE ScheduleMode = ScheduleModeCron
+	DEFAULT_TRIGGER_RULE  TriggerRule  = TriggerRuleAllSuccess
+
+	DEFAULT_NAMESPACE = "default" // 未指定空间的任务归入 default（迁移中创建，不可删除）
 )
@@ -0,0 +1,106 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoc(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestNext(t *testing.T) {
+	sh := mustLoc(t, "Asia/Shanghai")
+	cases := []struct {
+		expr, from, want string
+	}{
+		{"0 30 9 * * MON-FRI", "2026-10-16T09:30:00+08:00", "2026-10-19T09:30:00+08:00"}, // Fri -> Mon
+		{"30 9 * * *", "2026-10-16T08:00:00+08:00", "2026-10-16T09:30:00+08:00"},         // 5 fields
+		{"0 0 12 L * ?", "2026-02-01T00:00:00+08:00", "2026-02-28T12:00:00+08:00"},
+		{"0 0 12 L-2 * ?", "2026-04-01T00:00:00+08:00", "2026-04-28T12:00:00+08:00"},
+		{"0 0 9 LW * ?", "2026-05-01T00:00:00+08:00", "2026-05-29T09:00:00+08:00"},  // 5/31 is Sun
+		{"0 0 9 15W * ?", "2026-08-01T00:00:00+08:00", "2026-08-14T09:00:00+08:00"}, // 8/15 is Sat
+		{"0 0 9 1W * ?", "2026-08-01T00:00:00+08:00", "2026-08-03T09:00:00+08:00"},  // 8/1 is Sat, stay in month
+		{"0 0 9 ? * FRI#3", "2026-10-01T00:00:00+08:00", "2026-10-16T09:00:00+08:00"},
+		{"0 0 9 ? * 5L", "2026-10-01T00:00:00+08:00", "2026-10-30T09:00:00+08:00"},
+		{"0 0 0 29 FEB ?", "2026-03-01T00:00:00+08:00", "2028-02-29T00:00:00+08:00"},
+		{"*/15 * * * * *", "2026-10-16T09:30:07+08:00", "2026-10-16T09:30:15+08:00"},
+		{"0 0 9 1,15 jan-mar,dec sun", "2026-10-16T00:00:00+08:00", "2026-12-01T09:00:00+08:00"}, // dom|dow OR, month restricted
+		{"@daily", "2026-10-16T09:30:00+08:00", "2026-10-17T00:00:00+08:00"},
+		{"@weekly", "2026-10-16T09:30:00+08:00", "2026-10-18T00:00:00+08:00"},
+		{"@every 5m", "2026-10-16T09:31:10+08:00", "2026-10-16T09:35:00+08:00"},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.expr)
+		if err != nil {
+			t.Fatalf("parse %q: %v", c.expr, err)
+		}
+		from, _ := time.Parse(time.RFC3339, c.from)
+		want, _ := time.Parse(time.RFC3339, c.want)
+		got := s.Next(from.In(sh))
+		if !got.Equal(want) {
+			t.Errorf("%q after %s = %s, want %s", c.expr, c.from, got.Format(time.RFC3339), c.want)
+		}
+	}
+}
+
+func TestNextNever(t *testing.T) {
+	s, err := Parse("0 0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Fatalf("Feb 30 should never fire, got %s", got)
+	}
+}
+
+func TestNextDST(t *testing.T) {
+	ny := mustLoc(t, "America/New_York")
+	// 2026-03-08 02:00 EST -> 03:00 EDT: 02:30 不存在，在跳变时刻触发一次
+	s, _ := Parse("0 30 2 * * *")
+	got := s.Next(time.Date(2026, 3, 8, 1, 0, 0, 0, ny))
+	if want := time.Date(2026, 3, 8, 3, 0, 0, 0, ny); !got.Equal(want) {
+		t.Fatalf("spring forward: got %s want %s", got, want)
+	}
+	if next := s.Next(got); !next.Equal(time.Date(2026, 3, 9, 2, 30, 0, 0, ny)) {
+		t.Fatalf("after spring forward: got %s", next)
+	}
+	// 空档内多个时间点只触发一次
+	q, _ := Parse("0 */15 2 * * *")
+	fires := Upcoming(q, time.Date(2026, 3, 8, 1, 59, 59, 0, ny), ny, 2)
+	if !fires[0].Equal(time.Date(2026, 3, 8, 3, 0, 0, 0, ny)) || !fires[1].Equal(time.Date(2026, 3, 9, 2, 0, 0, 0, ny)) {
+		t.Fatalf("gap collapse: %v", fires)
+	}
+
+	// 2026-11-01 02:00 EDT -> 01:00 EST: 01:30 出现两次，只触发一次
+	s, _ = Parse("0 30 1 * * *")
+	first := s.Next(time.Date(2026, 11, 1, 0, 0, 0, 0, ny))
+	if _, off := first.Zone(); off != -4*3600 || first.Hour() != 1 || first.Minute() != 30 {
+		t.Fatalf("fall back first: %s", first)
+	}
+	if next := s.Next(first); !next.Equal(time.Date(2026, 11, 2, 1, 30, 0, 0, ny)) {
+		t.Fatalf("repeated wall time fired twice: %s", next)
+	}
+	// 从第二次出现的 01:10 开始，也不会再触发当天的 01:30
+	second := first.Add(40 * time.Minute) // 01:10 EST
+	if next := s.Next(second); !next.Equal(time.Date(2026, 11, 2, 1, 30, 0, 0, ny)) {
+		t.Fatalf("from repeated hour: %s", next)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	bad := []string{"", "* * * *", "60 * * * * *", "* * * * 13 *", "* * * * * 8", "0 0 0 ? * 1#6", "0 0 0 L-40 * *", "@every 10ms", "@fortnightly", "5-1 * * * *"}
+	for _, expr := range bad {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("expected error for %q", expr)
+		}
+	}
+	if err := Validate("@hourly", "Mars/Olympus"); err == nil {
+		t.Error("expected invalid timezone error")
+	}
+}
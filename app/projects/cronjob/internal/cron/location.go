@@ -0,0 +1,40 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // 容器镜像可能不带 zoneinfo，内嵌 IANA 时区库
+)
+
+var locCache sync.Map // name -> *time.Location
+
+// LoadLocation 加载 IANA 时区（如 Asia/Shanghai），空字符串视为 UTC；结果缓存。
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "UTC") {
+		return time.UTC, nil
+	}
+	if v, ok := locCache.Load(name); ok {
+		return v.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	if loc == time.Local {
+		return nil, fmt.Errorf("invalid timezone %q: use an explicit IANA zone", name)
+	}
+	locCache.Store(name, loc)
+	return loc, nil
+}
+
+// Validate 校验 Cron 表达式与时区是否合法。
+func Validate(expr, timezone string) error {
+	if _, err := Parse(expr); err != nil {
+		return err
+	}
+	_, err := LoadLocation(timezone)
+	return err
+}
@@ -0,0 +1,298 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse 解析 Cron 表达式，返回可计算下一触发时间的 Schedule。
+//
+// 支持：
+//   - 5 字段（分 时 日 月 周）或 6 字段（秒 分 时 日 月 周）
+//   - *, ?, 数值, a-b, */n, a/n, a-b/n, 逗号列表
+//   - 月份 JAN-DEC，星期 SUN-SAT（大小写不敏感），星期 7 等同 0（周日）
+//   - 日字段：L（月末）、L-n（月末前 n 天）、LW（月末最后一个工作日）、nW（离 n 号最近的工作日，不跨月）
+//   - 周字段：nL（当月最后一个周 n）、n#k（当月第 k 个周 n）
+//   - 描述符：@yearly @annually @monthly @weekly @daily @midnight @hourly @every <duration>
+//
+// 日与周同时受限（均不是 * / ?）时按 OR 匹配，与 Vixie cron 一致。
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("empty cron expression")
+	}
+	if strings.HasPrefix(expr, "@") {
+		return parseDescriptor(expr)
+	}
+	fields := strings.Fields(expr)
+	if len(fields) == 5 {
+		fields = append([]string{"0"}, fields...)
+	}
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("expected 5 or 6 fields, got %d: %q", len(fields), expr)
+	}
+	s := &SpecSchedule{}
+	var err error
+	if s.second, err = parseField(fields[0], seconds); err != nil {
+		return nil, fmt.Errorf("second field: %w", err)
+	}
+	if s.minute, err = parseField(fields[1], minutes); err != nil {
+		return nil, fmt.Errorf("minute field: %w", err)
+	}
+	if s.hour, err = parseField(fields[2], hours); err != nil {
+		return nil, fmt.Errorf("hour field: %w", err)
+	}
+	if s.month, err = parseField(fields[4], months); err != nil {
+		return nil, fmt.Errorf("month field: %w", err)
+	}
+	if s.dom, err = parseDom(fields[3]); err != nil {
+		return nil, fmt.Errorf("day-of-month field: %w", err)
+	}
+	if s.dow, err = parseDow(fields[5]); err != nil {
+		return nil, fmt.Errorf("day-of-week field: %w", err)
+	}
+	return s, nil
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+func parseDescriptor(expr string) (Schedule, error) {
+	parts := strings.Fields(expr)
+	name := strings.ToLower(parts[0])
+	if name == "@every" {
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("@every requires a duration: %q", expr)
+		}
+		d, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("@every: %w", err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("@every duration must be at least 1s: %s", d)
+		}
+		return Every(d), nil
+	}
+	spec, ok := descriptors[name]
+	if !ok || len(parts) != 1 {
+		return nil, fmt.Errorf("unknown descriptor: %q", expr)
+	}
+	return Parse(spec)
+}
+
+// bounds 描述单个字段的取值范围与名称别名。
+type bounds struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	seconds = bounds{0, 59, nil}
+	minutes = bounds{0, 59, nil}
+	hours   = bounds{0, 23, nil}
+	doms    = bounds{1, 31, nil}
+	months  = bounds{1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 周字段允许 7 表示周日，解析后折叠为 0
+	dows = bounds{0, 7, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// field 以位图表示允许的取值；star 记录原始字段是否为 * 或 ?（用于日/周的 AND/OR 判定）。
+type field struct {
+	bits uint64
+	star bool
+}
+
+func (f field) has(v int) bool { return f.bits&(1<<uint(v)) != 0 }
+
+func parseField(expr string, b bounds) (field, error) {
+	if expr == "*" || expr == "?" {
+		return field{bits: rangeBits(b.min, b.max, 1), star: true}, nil
+	}
+	var f field
+	for _, seg := range strings.Split(expr, ",") {
+		bits, err := parseRange(seg, b)
+		if err != nil {
+			return field{}, err
+		}
+		f.bits |= bits
+	}
+	return f, nil
+}
+
+// parseRange 解析单个逗号分段：*, */n, a, a-b, a/n, a-b/n。
+func parseRange(seg string, b bounds) (uint64, error) {
+	if seg == "" {
+		return 0, fmt.Errorf("empty list item")
+	}
+	rangePart, stepPart, hasStep := strings.Cut(seg, "/")
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepPart)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid step %q", seg)
+		}
+		step = n
+	}
+	var start, end int
+	switch {
+	case rangePart == "*" || rangePart == "?":
+		start, end = b.min, b.max
+	case strings.Contains(rangePart, "-"):
+		lo, hi, _ := strings.Cut(rangePart, "-")
+		var err error
+		if start, err = parseValue(lo, b); err != nil {
+			return 0, err
+		}
+		if end, err = parseValue(hi, b); err != nil {
+			return 0, err
+		}
+		if start > end {
+			return 0, fmt.Errorf("range start > end in %q", seg)
+		}
+	default:
+		v, err := parseValue(rangePart, b)
+		if err != nil {
+			return 0, err
+		}
+		start, end = v, v
+		if hasStep { // a/n 表示从 a 开始到最大值
+			end = b.max
+		}
+	}
+	return rangeBits(start, end, step), nil
+}
+
+func parseValue(s string, b bounds) (int, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < b.min || v > b.max {
+		return 0, fmt.Errorf("value %d out of range [%d,%d]", v, b.min, b.max)
+	}
+	return v, nil
+}
+
+func rangeBits(start, end, step int) uint64 {
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << uint(i)
+	}
+	return bits
+}
+
+// domSpec 日字段：普通位图 + L / L-n / LW / nW 扩展。
+type domSpec struct {
+	field
+	lastOffsets []int // L, L-n：月末前 n 天（L 即 0）
+	lastWeekday bool  // LW
+	nearest     []int // nW
+}
+
+func parseDom(expr string) (domSpec, error) {
+	if expr == "*" || expr == "?" {
+		f, _ := parseField(expr, doms)
+		return domSpec{field: f}, nil
+	}
+	var d domSpec
+	for _, seg := range strings.Split(expr, ",") {
+		up := strings.ToUpper(seg)
+		switch {
+		case up == "L":
+			d.lastOffsets = append(d.lastOffsets, 0)
+		case up == "LW":
+			d.lastWeekday = true
+		case strings.HasPrefix(up, "L-"):
+			n, err := strconv.Atoi(up[2:])
+			if err != nil || n < 0 || n > 30 {
+				return domSpec{}, fmt.Errorf("invalid offset %q", seg)
+			}
+			d.lastOffsets = append(d.lastOffsets, n)
+		case strings.HasSuffix(up, "W"):
+			n, err := parseValue(up[:len(up)-1], doms)
+			if err != nil {
+				return domSpec{}, err
+			}
+			d.nearest = append(d.nearest, n)
+		default:
+			bits, err := parseRange(seg, doms)
+			if err != nil {
+				return domSpec{}, err
+			}
+			d.bits |= bits
+		}
+	}
+	return d, nil
+}
+
+// dowSpec 周字段：普通位图 + nL / n#k 扩展。
+type dowSpec struct {
+	field
+	last []int    // nL：当月最后一个周 n
+	nth  [][2]int // n#k：当月第 k 个周 n
+}
+
+func parseDow(expr string) (dowSpec, error) {
+	if expr == "*" || expr == "?" {
+		f, _ := parseField(expr, dows)
+		f.bits = foldSunday(f.bits)
+		return dowSpec{field: f}, nil
+	}
+	var d dowSpec
+	for _, seg := range strings.Split(expr, ",") {
+		up := strings.ToUpper(seg)
+		switch {
+		case up == "L": // 单独的 L 表示周六
+			d.bits |= 1 << 6
+		case strings.Contains(up, "#"):
+			day, k, _ := strings.Cut(up, "#")
+			wd, err := parseValue(day, dows)
+			if err != nil {
+				return dowSpec{}, err
+			}
+			n, err := strconv.Atoi(k)
+			if err != nil || n < 1 || n > 5 {
+				return dowSpec{}, fmt.Errorf("invalid nth weekday %q", seg)
+			}
+			d.nth = append(d.nth, [2]int{wd % 7, n})
+		case len(up) > 1 && strings.HasSuffix(up, "L"):
+			wd, err := parseValue(up[:len(up)-1], dows)
+			if err != nil {
+				return dowSpec{}, err
+			}
+			d.last = append(d.last, wd%7)
+		default:
+			bits, err := parseRange(seg, dows)
+			if err != nil {
+				return dowSpec{}, err
+			}
+			d.bits |= bits
+		}
+	}
+	d.bits = foldSunday(d.bits)
+	return d, nil
+}
+
+func foldSunday(bits uint64) uint64 {
+	if bits&(1<<7) != 0 {
+		bits = (bits &^ (1 << 7)) | 1
+	}
+	return bits
+}
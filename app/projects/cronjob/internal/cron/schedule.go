@@ -0,0 +1,205 @@
+package cron
+
+import (
+	"time"
+)
+
+// searchYears 向后搜索的最大年数；超过仍无匹配（如 2 月 30 日）视为永不触发。
+const searchYears = 5
+
+// Schedule 计算下一次触发时间。
+type Schedule interface {
+	// Next 返回严格晚于 after 的下一次触发时间，按 after 所在时区解释表达式；
+	// 永不触发时返回零值。
+	Next(after time.Time) time.Time
+}
+
+// SpecSchedule 由字段表达式解析而来的调度。
+//
+// DST 处理：匹配在本地挂钟时间上进行，再映射为绝对时间。
+//   - 因时钟前跳而不存在的本地时间，在跳变时刻触发一次（多个被跳过的时间点合并为一次）；
+//   - 因时钟回拨而重复出现的本地时间，只在第一次出现时触发。
+type SpecSchedule struct {
+	second, minute, hour, month field
+	dom                         domSpec
+	dow                         dowSpec
+}
+
+func (s *SpecSchedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	// 在 UTC 上模拟本地挂钟，避免迭代过程受 DST 干扰
+	// （先取挂钟再 +1s，否则 after 恰在前跳前一秒时会越过整个空档）
+	c := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute(), after.Second(), 0, time.UTC).Add(time.Second)
+	limit := c.Year() + searchYears
+	for {
+		var ok bool
+		if c, ok = s.nextWall(c, limit); !ok {
+			return time.Time{}
+		}
+		if t := resolve(c, loc); t.After(after) {
+			return t
+		}
+		// 回拨后的重复时段已在第一次出现时触发过，继续向后找
+		c = c.Add(time.Second)
+	}
+}
+
+// nextWall 返回不早于 c 的首个匹配挂钟时间（c 以 UTC 表示挂钟）。
+func (s *SpecSchedule) nextWall(c time.Time, limit int) (time.Time, bool) {
+wrap:
+	if c.Year() > limit {
+		return time.Time{}, false
+	}
+	for !s.month.has(int(c.Month())) {
+		c = time.Date(c.Year(), c.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		if c.Month() == time.January {
+			goto wrap
+		}
+	}
+	for !s.dayMatches(c) {
+		c = time.Date(c.Year(), c.Month(), c.Day()+1, 0, 0, 0, 0, time.UTC)
+		if c.Day() == 1 {
+			goto wrap
+		}
+	}
+	for !s.hour.has(c.Hour()) {
+		c = c.Truncate(time.Hour).Add(time.Hour)
+		if c.Hour() == 0 {
+			goto wrap
+		}
+	}
+	for !s.minute.has(c.Minute()) {
+		c = c.Truncate(time.Minute).Add(time.Minute)
+		if c.Minute() == 0 {
+			goto wrap
+		}
+	}
+	for !s.second.has(c.Second()) {
+		c = c.Add(time.Second)
+		if c.Second() == 0 {
+			goto wrap
+		}
+	}
+	return c, true
+}
+
+func (s *SpecSchedule) dayMatches(c time.Time) bool {
+	domOK := s.dom.matches(c)
+	dowOK := s.dow.matches(c)
+	if s.dom.star || s.dow.star {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+func (d domSpec) matches(c time.Time) bool {
+	day := c.Day()
+	if d.has(day) {
+		return true
+	}
+	last := daysIn(c.Year(), c.Month())
+	for _, off := range d.lastOffsets {
+		if day == last-off {
+			return true
+		}
+	}
+	if d.lastWeekday && day == nearestWeekday(c.Year(), c.Month(), last) {
+		return true
+	}
+	for _, n := range d.nearest {
+		if n <= last && day == nearestWeekday(c.Year(), c.Month(), n) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d dowSpec) matches(c time.Time) bool {
+	wd := int(c.Weekday())
+	if d.has(wd) {
+		return true
+	}
+	day := c.Day()
+	for _, l := range d.last {
+		if wd == l && day+7 > daysIn(c.Year(), c.Month()) {
+			return true
+		}
+	}
+	for _, nk := range d.nth {
+		if wd == nk[0] && (day-1)/7+1 == nk[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday 返回离 day 最近的工作日（周一至周五），不跨越月边界。
+func nearestWeekday(year int, month time.Month, day int) int {
+	last := daysIn(year, month)
+	switch time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return 3
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
+// resolve 把挂钟时间 c 映射到 loc 中的绝对时间。
+func resolve(c time.Time, loc *time.Location) time.Time {
+	t := time.Date(c.Year(), c.Month(), c.Day(), c.Hour(), c.Minute(), c.Second(), 0, loc)
+	if t.Day() == c.Day() && t.Hour() == c.Hour() && t.Minute() == c.Minute() && t.Second() == c.Second() {
+		return t // 存在；若重复出现，time.Date 取第一次
+	}
+	// 本地时间落在 DST 前跳的空档里：取时钟跳变的时刻
+	start, end := t.ZoneBounds()
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	if wall.Before(c) {
+		return end
+	}
+	return start
+}
+
+// EverySchedule 固定间隔调度（@every），按 Unix 纪元对齐，与时区无关，进程重启后触发点不漂移。
+type EverySchedule struct {
+	Interval time.Duration
+}
+
+// Every 返回间隔为 d 的调度，不足 1 秒按 1 秒计。
+func Every(d time.Duration) EverySchedule {
+	if d < time.Second {
+		d = time.Second
+	}
+	return EverySchedule{Interval: d.Truncate(time.Second)}
+}
+
+func (e EverySchedule) Next(after time.Time) time.Time {
+	sec := int64(e.Interval / time.Second)
+	unix := after.Unix()
+	next := (unix/sec + 1) * sec
+	return time.Unix(next, 0).In(after.Location())
+}
+
+// Upcoming 返回 from 之后的 n 个触发时间（按 loc 解释）。
+func Upcoming(s Schedule, from time.Time, loc *time.Location, n int) []time.Time {
+	out := make([]time.Time, 0, n)
+	t := from.In(loc)
+	for i := 0; i < n; i++ {
+		t = s.Next(t)
+		if t.IsZero() {
+			break
+		}
+		out = append(out, t)
+	}
+	return out
+}
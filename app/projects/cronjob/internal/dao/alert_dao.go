@@ -0,0 +1,251 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	mg "github.com/grand-thief-cash/chaos/app/infra/go/application/components/postgresgorm"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// ErrAlertRuleNotFound 告警规则不存在。
+var ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+// AlertDao 告警规则、告警历史，以及规则评估所需的 Run 查询。
+type AlertDao interface {
+	core.Component
+	CreateRule(ctx context.Context, rule *model.AlertRule) error
+	GetRule(ctx context.Context, id int64) (*model.AlertRule, error)
+	UpdateRule(ctx context.Context, rule *model.AlertRule) error
+	DeleteRule(ctx context.Context, id int64) error
+	// ListRules 列出任务的规则；taskID 为 0 时列出全部
+	ListRules(ctx context.Context, taskID int64) ([]*model.AlertRule, error)
+	ListEnabledRulesByKind(ctx context.Context, kind bizConsts.AlertKind) ([]*model.AlertRule, error)
+
+	CreateEvent(ctx context.Context, ev *model.AlertEvent) error
+	ListEvents(ctx context.Context, f model.AlertEventFilter, limit, offset int) ([]*model.AlertEvent, error)
+	// LastSent 规则下该 key 最近一次成功发送的记录；没有时返回 nil
+	LastSent(ctx context.Context, ruleID int64, key string) (*model.AlertEvent, error)
+	// HasEvent 规则下该 key 是否有过任何记录
+	HasEvent(ctx context.Context, ruleID int64, key string) (bool, error)
+
+	// ListPending 已结束但尚未经告警规则评估的 Run，按 id 升序
+	ListPending(ctx context.Context, limit int) ([]*model.TaskRun, error)
+	MarkEvaluated(ctx context.Context, ids []int64) error
+	// RecentEffective 任务 id <= uptoID 的最近有效执行（成功、最终失败或取消；同一重试链只取最后一次），按 id 降序
+	RecentEffective(ctx context.Context, taskID, uptoID int64, limit int) ([]*model.TaskRun, error)
+	// SuccessDurations 任务 id < beforeID 的最近成功 Run 的耗时（秒）
+	SuccessDurations(ctx context.Context, taskID, beforeID int64, limit int) ([]float64, error)
+	// ListSLAMissed 触发时间在 [since, until] 内、截止（scheduled_time + sla）前整条重试链都未成功的 Run（不含跳过、取消与补跑）
+	ListSLAMissed(ctx context.Context, taskID int64, sla time.Duration, since, until time.Time, limit int) ([]*model.TaskRun, error)
+}
+
+type alertDaoImpl struct {
+	db *gorm.DB
+	*core.BaseComponent
+	GormComp *mg.PostgresGormComponent `infra:"dep:postgres_gorm"`
+	dsName   string
+}
+
+func NewAlertDao(dsName string) AlertDao {
+	return &alertDaoImpl{
+		BaseComponent: core.NewBaseComponent(bizConsts.COMP_DAO_ALERT, consts.COMPONENT_LOGGING),
+		dsName:        dsName,
+	}
+}
+
+func (d *alertDaoImpl) Start(ctx context.Context) error {
+	if err := d.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	db, err := d.GormComp.GetDB(d.dsName)
+	if err != nil {
+		return fmt.Errorf("get gorm db %s failed: %w", d.dsName, err)
+	}
+	d.db = db
+	return nil
+}
+
+func (d *alertDaoImpl) Stop(ctx context.Context) error {
+	return d.BaseComponent.Stop(ctx)
+}
+
+func (d *alertDaoImpl) CreateRule(ctx context.Context, rule *model.AlertRule) error {
+	if rule.Channels == nil {
+		rule.Channels = []string{}
+	}
+	return d.db.WithContext(ctx).Create(rule).Error
+}
+
+func (d *alertDaoImpl) GetRule(ctx context.Context, id int64) (*model.AlertRule, error) {
+	var rule model.AlertRule
+	if err := d.db.WithContext(ctx).First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAlertRuleNotFound
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (d *alertDaoImpl) UpdateRule(ctx context.Context, rule *model.AlertRule) error {
+	if rule.Channels == nil {
+		rule.Channels = []string{}
+	}
+	channels, err := json.Marshal(rule.Channels)
+	if err != nil {
+		return err
+	}
+	res := d.db.WithContext(ctx).Model(&model.AlertRule{}).Where("id = ?", rule.ID).Updates(map[string]any{
+		"threshold":    rule.Threshold,
+		"factor":       rule.Factor,
+		"min_samples":  rule.MinSamples,
+		"sla_sec":      rule.SLASec,
+		"channels":     string(channels),
+		"suppress_sec": rule.SuppressSec,
+		"enabled":      rule.Enabled,
+		"description":  rule.Description,
+		"updated_at":   time.Now(),
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrAlertRuleNotFound
+	}
+	return nil
+}
+
+func (d *alertDaoImpl) DeleteRule(ctx context.Context, id int64) error {
+	res := d.db.WithContext(ctx).Delete(&model.AlertRule{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrAlertRuleNotFound
+	}
+	return nil
+}
+
+func (d *alertDaoImpl) ListRules(ctx context.Context, taskID int64) ([]*model.AlertRule, error) {
+	var list []*model.AlertRule
+	q := d.db.WithContext(ctx).Order("id")
+	if taskID > 0 {
+		q = q.Where("task_id = ?", taskID)
+	}
+	return list, q.Find(&list).Error
+}
+
+func (d *alertDaoImpl) ListEnabledRulesByKind(ctx context.Context, kind bizConsts.AlertKind) ([]*model.AlertRule, error) {
+	var list []*model.AlertRule
+	err := d.db.WithContext(ctx).Where("kind = ? AND enabled", kind).Order("id").Find(&list).Error
+	return list, err
+}
+
+func (d *alertDaoImpl) CreateEvent(ctx context.Context, ev *model.AlertEvent) error {
+	if ev.CreatedAt.IsZero() {
+		ev.CreatedAt = time.Now()
+	}
+	return d.db.WithContext(ctx).Create(ev).Error
+}
+
+func (d *alertDaoImpl) ListEvents(ctx context.Context, f model.AlertEventFilter, limit, offset int) ([]*model.AlertEvent, error) {
+	var list []*model.AlertEvent
+	q := d.db.WithContext(ctx).Order("id DESC")
+	if f.TaskID > 0 {
+		q = q.Where("task_id = ?", f.TaskID)
+	}
+	if f.RuleID > 0 {
+		q = q.Where("rule_id = ?", f.RuleID)
+	}
+	if f.RunID > 0 {
+		q = q.Where("run_id = ?", f.RunID)
+	}
+	if f.Kind != "" {
+		q = q.Where("kind = ?", f.Kind)
+	}
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.From != nil {
+		q = q.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("created_at <= ?", *f.To)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	return list, q.Find(&list).Error
+}
+
+func (d *alertDaoImpl) LastSent(ctx context.Context, ruleID int64, key string) (*model.AlertEvent, error) {
+	var list []*model.AlertEvent
+	err := d.db.WithContext(ctx).Where("rule_id = ? AND dedup_key = ? AND status = ?", ruleID, key, bizConsts.AlertSent).
+		Order("id DESC").Limit(1).Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+func (d *alertDaoImpl) HasEvent(ctx context.Context, ruleID int64, key string) (bool, error) {
+	var n int64
+	err := d.db.WithContext(ctx).Model(&model.AlertEvent{}).Where("rule_id = ? AND dedup_key = ?", ruleID, key).Count(&n).Error
+	return n > 0, err
+}
+
+func (d *alertDaoImpl) ListPending(ctx context.Context, limit int) ([]*model.TaskRun, error) {
+	var list []*model.TaskRun
+	err := d.db.WithContext(ctx).Where("NOT alert_evaluated AND status IN ?", bizConsts.FinishedStatuses).
+		Order("id").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (d *alertDaoImpl) MarkEvaluated(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return d.db.WithContext(ctx).Model(&model.TaskRun{}).Where("id IN ?", ids).UpdateColumn("alert_evaluated", true).Error
+}
+
+func (d *alertDaoImpl) RecentEffective(ctx context.Context, taskID, uptoID int64, limit int) ([]*model.TaskRun, error) {
+	statuses := append([]bizConsts.RunStatus{bizConsts.Success, bizConsts.Canceled}, bizConsts.FailedStatuses...)
+	var list []*model.TaskRun
+	err := d.db.WithContext(ctx).Raw(`SELECT r.* FROM task_runs r
+WHERE r.task_id = ? AND r.id <= ? AND r.status IN ? AND r.next_retry_time IS NULL
+  AND NOT EXISTS (SELECT 1 FROM task_runs c WHERE c.retry_of = COALESCE(r.retry_of, r.id) AND c.retry_index > r.retry_index)
+ORDER BY r.id DESC LIMIT ?`, taskID, uptoID, statuses, limit).Scan(&list).Error
+	return list, err
+}
+
+func (d *alertDaoImpl) SuccessDurations(ctx context.Context, taskID, beforeID int64, limit int) ([]float64, error) {
+	var secs []float64
+	err := d.db.WithContext(ctx).Raw(`SELECT EXTRACT(EPOCH FROM (end_time - start_time))::float8 FROM task_runs
+WHERE task_id = ? AND id < ? AND status = ? AND start_time IS NOT NULL AND end_time IS NOT NULL
+ORDER BY id DESC LIMIT ?`, taskID, beforeID, bizConsts.Success, limit).Scan(&secs).Error
+	return secs, err
+}
+
+func (d *alertDaoImpl) ListSLAMissed(ctx context.Context, taskID int64, sla time.Duration, since, until time.Time, limit int) ([]*model.TaskRun, error) {
+	excluded := []bizConsts.RunStatus{bizConsts.Skipped, bizConsts.FailureSkip, bizConsts.ConcurrentSkip, bizConsts.OverlapSkip, bizConsts.Canceled}
+	var list []*model.TaskRun
+	err := d.db.WithContext(ctx).Raw(`SELECT r.* FROM task_runs r
+WHERE r.task_id = ? AND r.retry_of IS NULL AND r.status NOT IN ? AND r.trigger_type <> ?
+  AND r.scheduled_time >= ? AND r.scheduled_time <= ?
+  AND NOT EXISTS (SELECT 1 FROM task_runs s WHERE (s.id = r.id OR s.retry_of = r.id) AND s.status = ?
+                  AND s.end_time <= r.scheduled_time + make_interval(secs => ?))
+ORDER BY r.id LIMIT ?`, taskID, excluded, bizConsts.TriggerBackfill, since, until, bizConsts.Success, sla.Seconds(), limit).Scan(&list).Error
+	return list, err
+}
@@ -0,0 +1,144 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	mg "github.com/grand-thief-cash/chaos/app/infra/go/application/components/postgresgorm"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// AnalyticsDao task_runs 上的聚合查询（耗时分位数、排队时间、回调延迟、按天成功率与 SLA 达成）。
+// 时间均以 UTC 存储；窗口按 scheduled_time 过滤。
+type AnalyticsDao interface {
+	core.Component
+	// TaskStats 按任务聚合窗口内已结束的 Run
+	TaskStats(ctx context.Context, f model.AnalyticsFilter) ([]*model.TaskRunAnalytics, error)
+	// DailyStats 按 tz 时区的日期聚合窗口内已结束的 Run，按日期升序；没有 Run 的日期不返回
+	DailyStats(ctx context.Context, f model.AnalyticsFilter, tz string) ([]*model.DailyRunStats, error)
+	// SLAStats 任务窗口内的 SLA 达成情况（口径同 SLA_MISSED 告警），截止时间晚于 now 的触发不计入
+	SLAStats(ctx context.Context, taskID int64, sla time.Duration, f model.AnalyticsFilter, now time.Time) (*model.SLAReport, error)
+}
+
+type analyticsDaoImpl struct {
+	db *gorm.DB
+	*core.BaseComponent
+	GormComp *mg.PostgresGormComponent `infra:"dep:postgres_gorm"`
+	dsName   string
+}
+
+func NewAnalyticsDao(dsName string) AnalyticsDao {
+	return &analyticsDaoImpl{
+		BaseComponent: core.NewBaseComponent(bizConsts.COMP_DAO_ANALYTICS, consts.COMPONENT_LOGGING),
+		dsName:        dsName,
+	}
+}
+
+func (d *analyticsDaoImpl) Start(ctx context.Context) error {
+	if err := d.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	db, err := d.GormComp.GetDB(d.dsName)
+	if err != nil {
+		return fmt.Errorf("get gorm db %s failed: %w", d.dsName, err)
+	}
+	d.db = db
+	return nil
+}
+
+func (d *analyticsDaoImpl) Stop(ctx context.Context) error {
+	return d.BaseComponent.Stop(ctx)
+}
+
+// skipStatuses 未实际执行的 Run。
+var skipStatuses = []bizConsts.RunStatus{bizConsts.Skipped, bizConsts.FailureSkip, bizConsts.ConcurrentSkip, bizConsts.OverlapSkip}
+
+// runWindow 已结束且触发时间在窗口内的 Run；返回 WHERE 子句与参数。
+func runWindow(f model.AnalyticsFilter) (string, []any) {
+	where := "r.status IN ? AND r.scheduled_time >= ? AND r.scheduled_time < ?"
+	args := []any{bizConsts.FinishedStatuses, f.From.UTC(), f.To.UTC()}
+	if f.TaskID > 0 {
+		where += " AND r.task_id = ?"
+		args = append(args, f.TaskID)
+	}
+	return where, args
+}
+
+const (
+	sqlDuration = "EXTRACT(EPOCH FROM (r.end_time - r.start_time))::float8"
+	sqlQueue    = "GREATEST(EXTRACT(EPOCH FROM (r.start_time - GREATEST(r.created_at, r.scheduled_time)))::float8, 0)"
+	sqlCallback = "EXTRACT(EPOCH FROM (c.received_at - r.start_time))::float8"
+	sqlExecuted = "r.start_time IS NOT NULL AND r.end_time IS NOT NULL"
+)
+
+func (d *analyticsDaoImpl) TaskStats(ctx context.Context, f model.AnalyticsFilter) ([]*model.TaskRunAnalytics, error) {
+	where, args := runWindow(f)
+	query := fmt.Sprintf(`SELECT r.task_id,
+  COUNT(*) FILTER (WHERE r.status NOT IN ?) AS runs,
+  COUNT(*) FILTER (WHERE r.status = ?) AS succeeded,
+  COUNT(*) FILTER (WHERE r.status IN ?) AS failed,
+  COUNT(*) FILTER (WHERE r.status = ?) AS canceled,
+  COUNT(*) FILTER (WHERE r.status IN ?) AS skipped,
+  COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY %[1]s) FILTER (WHERE %[4]s), 0) AS duration_p50,
+  COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY %[1]s) FILTER (WHERE %[4]s), 0) AS duration_p95,
+  COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY %[1]s) FILTER (WHERE %[4]s), 0) AS duration_p99,
+  COALESCE(AVG(%[2]s) FILTER (WHERE r.start_time IS NOT NULL), 0) AS queue_avg,
+  COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY %[2]s) FILTER (WHERE r.start_time IS NOT NULL), 0) AS queue_p95,
+  COALESCE(AVG(%[1]s) FILTER (WHERE %[4]s), 0) AS exec_avg,
+  COUNT(c.id) AS callback_samples,
+  percentile_cont(0.5) WITHIN GROUP (ORDER BY %[3]s) FILTER (WHERE c.id IS NOT NULL AND r.start_time IS NOT NULL) AS callback_p50,
+  percentile_cont(0.95) WITHIN GROUP (ORDER BY %[3]s) FILTER (WHERE c.id IS NOT NULL AND r.start_time IS NOT NULL) AS callback_p95
+FROM task_runs r
+LEFT JOIN async_callbacks c ON c.task_run_id = r.id AND c.outcome = ?
+WHERE %[5]s
+GROUP BY r.task_id
+ORDER BY r.task_id`, sqlDuration, sqlQueue, sqlCallback, sqlExecuted, where)
+	params := append([]any{skipStatuses, bizConsts.Success, bizConsts.FailedStatuses, bizConsts.Canceled, skipStatuses,
+		bizConsts.CallbackAccepted}, args...)
+	var list []*model.TaskRunAnalytics
+	err := d.db.WithContext(ctx).Raw(query, params...).Scan(&list).Error
+	return list, err
+}
+
+func (d *analyticsDaoImpl) DailyStats(ctx context.Context, f model.AnalyticsFilter, tz string) ([]*model.DailyRunStats, error) {
+	where, args := runWindow(f)
+	query := `SELECT to_char(date_trunc('day', (r.scheduled_time AT TIME ZONE 'UTC') AT TIME ZONE ?), 'YYYY-MM-DD') AS date,
+  COUNT(*) FILTER (WHERE r.status NOT IN ?) AS runs,
+  COUNT(*) FILTER (WHERE r.status = ?) AS succeeded,
+  COUNT(*) FILTER (WHERE r.status IN ?) AS failed
+FROM task_runs r
+WHERE ` + where + `
+GROUP BY 1
+ORDER BY 1`
+	params := append([]any{tz, skipStatuses, bizConsts.Success, bizConsts.FailedStatuses}, args...)
+	var list []*model.DailyRunStats
+	err := d.db.WithContext(ctx).Raw(query, params...).Scan(&list).Error
+	return list, err
+}
+
+func (d *analyticsDaoImpl) SLAStats(ctx context.Context, taskID int64, sla time.Duration, f model.AnalyticsFilter, now time.Time) (*model.SLAReport, error) {
+	excluded := append(append([]bizConsts.RunStatus{}, skipStatuses...), bizConsts.Canceled)
+	var row struct {
+		Fires int64
+		Met   int64
+	}
+	err := d.db.WithContext(ctx).Raw(`SELECT COUNT(*) AS fires,
+  COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM task_runs s WHERE (s.id = r.id OR s.retry_of = r.id) AND s.status = ?
+                                 AND s.end_time <= r.scheduled_time + make_interval(secs => ?))) AS met
+FROM task_runs r
+WHERE r.task_id = ? AND r.retry_of IS NULL AND r.status NOT IN ? AND r.trigger_type <> ?
+  AND r.scheduled_time >= ? AND r.scheduled_time < ?
+  AND r.scheduled_time + make_interval(secs => ?) <= ?`,
+		bizConsts.Success, sla.Seconds(), taskID, excluded, bizConsts.TriggerBackfill,
+		f.From.UTC(), f.To.UTC(), sla.Seconds(), now.UTC()).Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+	return &model.SLAReport{TaskID: taskID, SLASec: int(sla.Seconds()), Fires: row.Fires, Met: row.Met, Missed: row.Fires - row.Met}, nil
+}
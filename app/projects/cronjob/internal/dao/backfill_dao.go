@@ -0,0 +1,116 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	mg "github.com/grand-thief-cash/chaos/app/infra/go/application/components/postgresgorm"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// ErrBackfillNotFound 补跑批次不存在。
+var ErrBackfillNotFound = errors.New("backfill not found")
+
+// BackfillDao 补跑批次的持久化；批次内的 Run 通过 task_runs.backfill_id 归属。
+type BackfillDao interface {
+	core.Component
+	Create(ctx context.Context, b *model.Backfill) error
+	Get(ctx context.Context, id int64) (*model.Backfill, error)
+	ListByTask(ctx context.Context, taskID int64, limit int) ([]*model.Backfill, error)
+	ListRunning(ctx context.Context) ([]*model.Backfill, error)
+	// UpdateProgress 记录派发进度，仅对 RUNNING 批次生效（已取消的批次不再推进）。
+	UpdateProgress(ctx context.Context, b *model.Backfill) (bool, error)
+	// Finish 将 RUNNING 批次置为终态，返回是否由本次调用完成（并发取消/完成时只有一方成功）。
+	Finish(ctx context.Context, id int64, status bizConsts.BackfillStatus) (bool, error)
+}
+
+type backfillDaoImpl struct {
+	db *gorm.DB
+	*core.BaseComponent
+	GormComp *mg.PostgresGormComponent `infra:"dep:postgres_gorm"`
+	dsName   string
+}
+
+func NewBackfillDao(dsName string) BackfillDao {
+	return &backfillDaoImpl{
+		BaseComponent: core.NewBaseComponent(bizConsts.COMP_DAO_BACKFILL, consts.COMPONENT_LOGGING),
+		dsName:        dsName,
+	}
+}
+
+func (d *backfillDaoImpl) Start(ctx context.Context) error {
+	if err := d.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	db, err := d.GormComp.GetDB(d.dsName)
+	if err != nil {
+		return fmt.Errorf("get gorm db %s failed: %w", d.dsName, err)
+	}
+	d.db = db
+	return nil
+}
+
+func (d *backfillDaoImpl) Stop(ctx context.Context) error {
+	return d.BaseComponent.Stop(ctx)
+}
+
+func (d *backfillDaoImpl) Create(ctx context.Context, b *model.Backfill) error {
+	if b.Status == "" {
+		b.Status = bizConsts.BackfillRunning
+	}
+	return d.db.WithContext(ctx).Create(b).Error
+}
+
+func (d *backfillDaoImpl) Get(ctx context.Context, id int64) (*model.Backfill, error) {
+	var b model.Backfill
+	if err := d.db.WithContext(ctx).First(&b, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBackfillNotFound
+		}
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (d *backfillDaoImpl) ListByTask(ctx context.Context, taskID int64, limit int) ([]*model.Backfill, error) {
+	var list []*model.Backfill
+	q := d.db.WithContext(ctx).Where("task_id = ?", taskID).Order("id DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	err := q.Find(&list).Error
+	return list, err
+}
+
+func (d *backfillDaoImpl) ListRunning(ctx context.Context) ([]*model.Backfill, error) {
+	var list []*model.Backfill
+	err := d.db.WithContext(ctx).Where("status = ?", bizConsts.BackfillRunning).Order("id").Find(&list).Error
+	return list, err
+}
+
+func (d *backfillDaoImpl) UpdateProgress(ctx context.Context, b *model.Backfill) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&model.Backfill{}).
+		Where("id = ? AND status = ?", b.ID, bizConsts.BackfillRunning).
+		Updates(map[string]any{
+			"last_fire_time": b.LastFireTime,
+			"dispatched":     b.Dispatched,
+			"skipped":        b.Skipped,
+			"updated_at":     time.Now(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (d *backfillDaoImpl) Finish(ctx context.Context, id int64, status bizConsts.BackfillStatus) (bool, error) {
+	now := time.Now()
+	res := d.db.WithContext(ctx).Model(&model.Backfill{}).
+		Where("id = ? AND status = ?", id, bizConsts.BackfillRunning).
+		Updates(map[string]any{"status": status, "finished_at": now, "updated_at": now})
+	return res.RowsAffected > 0, res.Error
+}
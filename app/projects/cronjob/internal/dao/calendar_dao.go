@@ -0,0 +1,233 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	mg "github.com/grand-thief-cash/chaos/app/infra/go/application/components/postgresgorm"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+var (
+	// ErrCalendarNotFound 日历不存在。
+	ErrCalendarNotFound = errors.New("calendar not found")
+	// ErrBlackoutNotFound 停摆窗口不存在。
+	ErrBlackoutNotFound = errors.New("blackout window not found")
+)
+
+// CalendarDao 营业日历与停摆窗口的持久化。
+type CalendarDao interface {
+	core.Component
+	// List 返回全部日历（含日期列表）。
+	List(ctx context.Context) ([]*model.Calendar, error)
+	Get(ctx context.Context, name string) (*model.Calendar, error)
+	// Save 按名称新建或整体替换日历（含日期列表），已存在时版本 +1。
+	Save(ctx context.Context, c *model.Calendar) error
+	// UpdateDates 增删日期，版本 +1 并将来源置为 API。
+	UpdateDates(ctx context.Context, name string, add, remove []string) (*model.Calendar, error)
+	Delete(ctx context.Context, name string) error
+	// CountTasksUsing 统计引用该日历的未删除任务数。
+	CountTasksUsing(ctx context.Context, name string) (int64, error)
+	// ListBlackouts 返回结束时间晚于 after 的停摆窗口。
+	ListBlackouts(ctx context.Context, after time.Time) ([]*model.BlackoutWindow, error)
+	CreateBlackout(ctx context.Context, b *model.BlackoutWindow) error
+	DeleteBlackout(ctx context.Context, id int64) error
+}
+
+type calendarDaoImpl struct {
+	db *gorm.DB
+	*core.BaseComponent
+	GormComp *mg.PostgresGormComponent `infra:"dep:postgres_gorm"`
+	dsName   string
+}
+
+func NewCalendarDao(dsName string) CalendarDao {
+	return &calendarDaoImpl{
+		BaseComponent: core.NewBaseComponent(bizConsts.COMP_DAO_CALENDAR, consts.COMPONENT_LOGGING),
+		dsName:        dsName,
+	}
+}
+
+func (d *calendarDaoImpl) Start(ctx context.Context) error {
+	if err := d.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	db, err := d.GormComp.GetDB(d.dsName)
+	if err != nil {
+		return fmt.Errorf("get gorm db %s failed: %w", d.dsName, err)
+	}
+	d.db = db
+	return nil
+}
+
+func (d *calendarDaoImpl) Stop(ctx context.Context) error {
+	return d.BaseComponent.Stop(ctx)
+}
+
+func (d *calendarDaoImpl) List(ctx context.Context) ([]*model.Calendar, error) {
+	var list []*model.Calendar
+	if err := d.db.WithContext(ctx).Order("name").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	var dates []model.CalendarDate
+	if err := d.db.WithContext(ctx).Order("calendar_id, date").Find(&dates).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*model.Calendar, len(list))
+	for _, c := range list {
+		c.Dates = []string{}
+		byID[c.ID] = c
+	}
+	for _, cd := range dates {
+		if c, ok := byID[cd.CalendarID]; ok {
+			c.Dates = append(c.Dates, cd.Date.Format(time.DateOnly))
+		}
+	}
+	return list, nil
+}
+
+func (d *calendarDaoImpl) Get(ctx context.Context, name string) (*model.Calendar, error) {
+	return d.get(d.db.WithContext(ctx), name)
+}
+
+func (d *calendarDaoImpl) get(db *gorm.DB, name string) (*model.Calendar, error) {
+	var c model.Calendar
+	if err := db.Where("name = ?", name).First(&c).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCalendarNotFound
+		}
+		return nil, err
+	}
+	var dates []model.CalendarDate
+	if err := db.Where("calendar_id = ?", c.ID).Order("date").Find(&dates).Error; err != nil {
+		return nil, err
+	}
+	c.Dates = make([]string, 0, len(dates))
+	for _, cd := range dates {
+		c.Dates = append(c.Dates, cd.Date.Format(time.DateOnly))
+	}
+	return &c, nil
+}
+
+func (d *calendarDaoImpl) Save(ctx context.Context, c *model.Calendar) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		cur, err := d.get(tx, c.Name)
+		switch {
+		case errors.Is(err, ErrCalendarNotFound):
+			c.ID, c.Version, c.CreatedAt, c.UpdatedAt = 0, 1, now, now
+			if err := tx.Omit("Dates").Create(c).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			c.ID, c.Version, c.CreatedAt, c.UpdatedAt = cur.ID, cur.Version+1, cur.CreatedAt, now
+			if err := tx.Model(&model.Calendar{}).Where("id = ?", c.ID).Updates(map[string]any{
+				"description": c.Description,
+				"kind":        c.Kind,
+				"source":      c.Source,
+				"version":     c.Version,
+				"updated_at":  now,
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("calendar_id = ?", c.ID).Delete(&model.CalendarDate{}).Error; err != nil {
+				return err
+			}
+		}
+		return insertDates(tx, c.ID, c.Dates)
+	})
+}
+
+func (d *calendarDaoImpl) UpdateDates(ctx context.Context, name string, add, remove []string) (*model.Calendar, error) {
+	var out *model.Calendar
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		cur, err := d.get(tx, name)
+		if err != nil {
+			return err
+		}
+		if len(remove) > 0 {
+			if err := tx.Where("calendar_id = ? AND date IN ?", cur.ID, remove).Delete(&model.CalendarDate{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(add) > 0 {
+			if err := insertDates(tx, cur.ID, add); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&model.Calendar{}).Where("id = ?", cur.ID).Updates(map[string]any{
+			"version":    gorm.Expr("version + 1"),
+			"source":     bizConsts.CalendarSourceAPI,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		out, err = d.get(tx, name)
+		return err
+	})
+	return out, err
+}
+
+func (d *calendarDaoImpl) Delete(ctx context.Context, name string) error {
+	res := d.db.WithContext(ctx).Where("name = ?", name).Delete(&model.Calendar{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCalendarNotFound
+	}
+	return nil
+}
+
+func (d *calendarDaoImpl) CountTasksUsing(ctx context.Context, name string) (int64, error) {
+	var n int64
+	err := d.db.WithContext(ctx).Model(&model.Task{}).Where("calendar = ? AND deleted = 0", name).Count(&n).Error
+	return n, err
+}
+
+func (d *calendarDaoImpl) ListBlackouts(ctx context.Context, after time.Time) ([]*model.BlackoutWindow, error) {
+	var list []*model.BlackoutWindow
+	err := d.db.WithContext(ctx).Where("end_time > ?", after).Order("start_time, id").Find(&list).Error
+	return list, err
+}
+
+func (d *calendarDaoImpl) CreateBlackout(ctx context.Context, b *model.BlackoutWindow) error {
+	return d.db.WithContext(ctx).Create(b).Error
+}
+
+func (d *calendarDaoImpl) DeleteBlackout(ctx context.Context, id int64) error {
+	res := d.db.WithContext(ctx).Delete(&model.BlackoutWindow{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrBlackoutNotFound
+	}
+	return nil
+}
+
+// insertDates 批量写入日期（调用方已校验为 YYYY-MM-DD），已存在的日期忽略。
+func insertDates(tx *gorm.DB, calendarID int64, dates []string) error {
+	if len(dates) == 0 {
+		return nil
+	}
+	rows := make([]model.CalendarDate, 0, len(dates))
+	for _, s := range dates {
+		t, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			return fmt.Errorf("invalid date %q: %w", s, err)
+		}
+		rows = append(rows, model.CalendarDate{CalendarID: calendarID, Date: t})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(rows, 500).Error
+}
@@ -0,0 +1,99 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	mg "github.com/grand-thief-cash/chaos/app/infra/go/application/components/postgresgorm"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// LockDao 基于 scheduler_locks 表的租约锁。过期判断统一使用数据库时钟，避免副本间时钟漂移。
+type LockDao interface {
+	core.Component
+	// TryAcquire 获取或续约租约：锁不存在、已过期或本就属于 owner 时成功，返回当前任期；否则 ok=false。
+	TryAcquire(ctx context.Context, name, owner string, ttl time.Duration) (term int64, ok bool, err error)
+	// Release 主动让出租约（仅当仍由 owner 持有时），其他副本下一次尝试即可接管。
+	Release(ctx context.Context, name, owner string) error
+	// Get 返回锁当前状态，不存在时返回 nil。
+	Get(ctx context.Context, name string) (*model.SchedulerLock, error)
+}
+
+type lockDaoImpl struct {
+	db *gorm.DB
+	*core.BaseComponent
+	GormComp *mg.PostgresGormComponent `infra:"dep:postgres_gorm"`
+	dsName   string
+}
+
+func NewLockDao(dsName string) LockDao {
+	return &lockDaoImpl{
+		BaseComponent: core.NewBaseComponent(bizConsts.COMP_DAO_LOCK, consts.COMPONENT_LOGGING),
+		dsName:        dsName,
+	}
+}
+
+func (d *lockDaoImpl) Start(ctx context.Context) error {
+	if err := d.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	db, err := d.GormComp.GetDB(d.dsName)
+	if err != nil {
+		return fmt.Errorf("get gorm db %s failed: %w", d.dsName, err)
+	}
+	d.db = db
+	return nil
+}
+
+func (d *lockDaoImpl) Stop(ctx context.Context) error {
+	return d.BaseComponent.Stop(ctx)
+}
+
+// 列为 TIMESTAMP（无时区），统一按 UTC 存储
+const dbNowUTC = "(NOW() AT TIME ZONE 'UTC')"
+
+func (d *lockDaoImpl) TryAcquire(ctx context.Context, name, owner string, ttl time.Duration) (int64, bool, error) {
+	var rows []struct{ Term int64 }
+	err := d.db.WithContext(ctx).Raw(`
+INSERT INTO scheduler_locks (lock_name, owner_id, term, acquired_at, expires_at)
+VALUES (?, ?, 1, `+dbNowUTC+`, `+dbNowUTC+` + make_interval(secs => ?))
+ON CONFLICT (lock_name) DO UPDATE SET
+    owner_id    = EXCLUDED.owner_id,
+    expires_at  = EXCLUDED.expires_at,
+    term        = CASE WHEN scheduler_locks.owner_id = EXCLUDED.owner_id THEN scheduler_locks.term ELSE scheduler_locks.term + 1 END,
+    acquired_at = CASE WHEN scheduler_locks.owner_id = EXCLUDED.owner_id THEN scheduler_locks.acquired_at ELSE EXCLUDED.acquired_at END
+WHERE scheduler_locks.owner_id = EXCLUDED.owner_id OR scheduler_locks.expires_at < `+dbNowUTC+`
+RETURNING term`, name, owner, ttl.Seconds()).Scan(&rows).Error
+	if err != nil {
+		return 0, false, err
+	}
+	if len(rows) == 0 {
+		return 0, false, nil
+	}
+	return rows[0].Term, true, nil
+}
+
+func (d *lockDaoImpl) Release(ctx context.Context, name, owner string) error {
+	// 置为已过期而不是删除，保留任期单调递增
+	return d.db.WithContext(ctx).Model(&model.SchedulerLock{}).
+		Where("lock_name=? AND owner_id=?", name, owner).
+		UpdateColumn("expires_at", gorm.Expr(dbNowUTC+" - INTERVAL '1 second'")).Error
+}
+
+func (d *lockDaoImpl) Get(ctx context.Context, name string) (*model.SchedulerLock, error) {
+	var l model.SchedulerLock
+	if err := d.db.WithContext(ctx).Where("lock_name=?", name).First(&l).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &l, nil
+}
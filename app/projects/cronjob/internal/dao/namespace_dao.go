@@ -0,0 +1,147 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	mg "github.com/grand-thief-cash/chaos/app/infra/go/application/components/postgresgorm"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+var (
+	// ErrNamespaceNotFound 命名空间不存在。
+	ErrNamespaceNotFound = errors.New("namespace not found")
+	// ErrNamespaceExists 同名命名空间已存在。
+	ErrNamespaceExists = errors.New("namespace already exists")
+)
+
+// NamespaceDao 命名空间的持久化。
+type NamespaceDao interface {
+	core.Component
+	List(ctx context.Context) ([]*model.Namespace, error)
+	Get(ctx context.Context, name string) (*model.Namespace, error)
+	Create(ctx context.Context, ns *model.Namespace) error
+	// Update 覆盖描述、owners、配额与 target_service 白名单。
+	Update(ctx context.Context, ns *model.Namespace) error
+	Delete(ctx context.Context, name string) error
+	// CountTasks 统计空间内未删除的任务数。
+	CountTasks(ctx context.Context, name string) (int64, error)
+}
+
+type namespaceDaoImpl struct {
+	db *gorm.DB
+	*core.BaseComponent
+	GormComp *mg.PostgresGormComponent `infra:"dep:postgres_gorm"`
+	dsName   string
+}
+
+func NewNamespaceDao(dsName string) NamespaceDao {
+	return &namespaceDaoImpl{
+		BaseComponent: core.NewBaseComponent(bizConsts.COMP_DAO_NAMESPACE, consts.COMPONENT_LOGGING),
+		dsName:        dsName,
+	}
+}
+
+func (d *namespaceDaoImpl) Start(ctx context.Context) error {
+	if err := d.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	db, err := d.GormComp.GetDB(d.dsName)
+	if err != nil {
+		return fmt.Errorf("get gorm db %s failed: %w", d.dsName, err)
+	}
+	d.db = db
+	return nil
+}
+
+func (d *namespaceDaoImpl) Stop(ctx context.Context) error {
+	return d.BaseComponent.Stop(ctx)
+}
+
+func (d *namespaceDaoImpl) List(ctx context.Context) ([]*model.Namespace, error) {
+	var list []*model.Namespace
+	if err := d.db.WithContext(ctx).Order("name").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (d *namespaceDaoImpl) Get(ctx context.Context, name string) (*model.Namespace, error) {
+	var ns model.Namespace
+	if err := d.db.WithContext(ctx).Where("name = ?", name).First(&ns).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNamespaceNotFound
+		}
+		return nil, err
+	}
+	return &ns, nil
+}
+
+func (d *namespaceDaoImpl) Create(ctx context.Context, ns *model.Namespace) error {
+	now := time.Now()
+	ns.CreatedAt, ns.UpdatedAt = now, now
+	applyNamespaceDefaults(ns)
+	res := d.db.WithContext(ctx).Exec(`INSERT INTO namespaces (name, description, owners, max_tasks, max_concurrent_runs, allowed_target_services, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (name) DO NOTHING`,
+		ns.Name, ns.Description, jsonText(ns.Owners), ns.MaxTasks, ns.MaxConcurrentRuns, jsonText(ns.AllowedTargetServices), now, now)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNamespaceExists
+	}
+	return nil
+}
+
+func (d *namespaceDaoImpl) Update(ctx context.Context, ns *model.Namespace) error {
+	ns.UpdatedAt = time.Now()
+	applyNamespaceDefaults(ns)
+	res := d.db.WithContext(ctx).Model(&model.Namespace{}).Where("name = ?", ns.Name).Updates(map[string]any{
+		"description":             ns.Description,
+		"owners":                  jsonText(ns.Owners),
+		"max_tasks":               ns.MaxTasks,
+		"max_concurrent_runs":     ns.MaxConcurrentRuns,
+		"allowed_target_services": jsonText(ns.AllowedTargetServices),
+		"updated_at":              ns.UpdatedAt,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNamespaceNotFound
+	}
+	return nil
+}
+
+func (d *namespaceDaoImpl) Delete(ctx context.Context, name string) error {
+	res := d.db.WithContext(ctx).Where("name = ?", name).Delete(&model.Namespace{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNamespaceNotFound
+	}
+	return nil
+}
+
+func (d *namespaceDaoImpl) CountTasks(ctx context.Context, name string) (int64, error) {
+	var n int64
+	err := d.db.WithContext(ctx).Model(&model.Task{}).Where("namespace = ? AND deleted = 0", name).Count(&n).Error
+	return n, err
+}
+
+func applyNamespaceDefaults(ns *model.Namespace) {
+	if ns.Owners == nil {
+		ns.Owners = []string{}
+	}
+	if ns.AllowedTargetServices == nil {
+		ns.AllowedTargetServices = []string{}
+	}
+}
@@ -0,0 +1,106 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	mg "github.com/grand-thief-cash/chaos/app/infra/go/application/components/postgresgorm"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// pauseRowID scheduler_pause 表唯一一行的主键。
+const pauseRowID = 1
+
+// PauseDao 调度器全局暂停状态（scheduler_pause 表）。
+type PauseDao interface {
+	core.Component
+	// Get 返回当前状态；行不存在（迁移前）时视为未暂停。
+	Get(ctx context.Context) (*model.SchedulerPause, error)
+	// Pause 进入暂停（已暂停时覆盖恢复时间与原因），until 为 nil 表示需手动恢复。
+	Pause(ctx context.Context, until *time.Time, reason, actor string) error
+	// Resume 手动恢复，返回是否由暂停变为恢复。
+	Resume(ctx context.Context, actor string) (bool, error)
+	// ResumeExpired 恢复已到 paused_until 的暂停（按数据库时钟），返回是否恢复；多副本同时调用只有一个生效。
+	ResumeExpired(ctx context.Context) (bool, error)
+}
+
+type pauseDaoImpl struct {
+	db *gorm.DB
+	*core.BaseComponent
+	GormComp *mg.PostgresGormComponent `infra:"dep:postgres_gorm"`
+	dsName   string
+}
+
+func NewPauseDao(dsName string) PauseDao {
+	return &pauseDaoImpl{
+		BaseComponent: core.NewBaseComponent(bizConsts.COMP_DAO_PAUSE, consts.COMPONENT_LOGGING),
+		dsName:        dsName,
+	}
+}
+
+func (d *pauseDaoImpl) Start(ctx context.Context) error {
+	if err := d.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	db, err := d.GormComp.GetDB(d.dsName)
+	if err != nil {
+		return fmt.Errorf("get gorm db %s failed: %w", d.dsName, err)
+	}
+	d.db = db
+	return nil
+}
+
+func (d *pauseDaoImpl) Stop(ctx context.Context) error {
+	return d.BaseComponent.Stop(ctx)
+}
+
+func (d *pauseDaoImpl) Get(ctx context.Context) (*model.SchedulerPause, error) {
+	var p model.SchedulerPause
+	if err := d.db.WithContext(ctx).Where("id = ?", pauseRowID).First(&p).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.SchedulerPause{ID: pauseRowID}, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (d *pauseDaoImpl) Pause(ctx context.Context, until *time.Time, reason, actor string) error {
+	now := time.Now().UTC()
+	return d.db.WithContext(ctx).Exec(`
+INSERT INTO scheduler_pause (id, paused, paused_until, reason, paused_by, paused_at, updated_at)
+VALUES (?, TRUE, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    paused       = TRUE,
+    paused_until = EXCLUDED.paused_until,
+    reason       = EXCLUDED.reason,
+    paused_by    = EXCLUDED.paused_by,
+    paused_at    = CASE WHEN scheduler_pause.paused THEN scheduler_pause.paused_at ELSE EXCLUDED.paused_at END,
+    updated_at   = EXCLUDED.updated_at`,
+		pauseRowID, until, reason, actor, now, now).Error
+}
+
+func (d *pauseDaoImpl) Resume(ctx context.Context, actor string) (bool, error) {
+	now := time.Now().UTC()
+	res := d.db.WithContext(ctx).Model(&model.SchedulerPause{}).Where("id = ? AND paused", pauseRowID).Updates(map[string]any{
+		"paused":     false,
+		"resumed_by": actor,
+		"resumed_at": now,
+		"updated_at": now,
+	})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (d *pauseDaoImpl) ResumeExpired(ctx context.Context) (bool, error) {
+	res := d.db.WithContext(ctx).Exec(`
+UPDATE scheduler_pause SET paused = FALSE, resumed_by = 'auto', resumed_at = paused_until, updated_at = `+dbNowUTC+`
+WHERE id = ? AND paused AND paused_until IS NOT NULL AND paused_until <= `+dbNowUTC, pauseRowID)
+	return res.RowsAffected > 0, res.Error
+}
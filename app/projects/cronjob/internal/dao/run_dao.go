@@ -3,11 +3,14 @@ package dao
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	mg "github.com/grand-thief-cash/chaos/app/infra/go/application/components/postgresgorm"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
@@ -16,15 +19,37 @@ import (
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
 )
 
+// ErrDuplicateRun 同一任务同一 scheduled_time 的 Run 已存在（uniq_task_schedule），
+// 通常是另一个副本已为该触发点建过 Run，调用方应放弃本次创建而不是重试。
+var ErrDuplicateRun = errors.New("run already exists for task and scheduled_time")
+
+// ErrNamespaceQuotaExceeded 命名空间的并发 Run 配额已满，Run 保持 SCHEDULED，调用方稍后重试。
+var ErrNamespaceQuotaExceeded = errors.New("namespace concurrent run quota exceeded")
+
 type RunDao interface {
 	// Embed component so registry builders can return a RunDao where core.Component is required
 	core.Component
+	// CreateScheduled / CreateSkipped 对 (task_id, scheduled_time) 幂等，重复时返回 ErrDuplicateRun
 	CreateScheduled(ctx context.Context, run *model.TaskRun) error
 	CreateSkipped(ctx context.Context, run *model.TaskRun, skipType bizConsts.RunStatus) error // new helper to directly create a skipped run
 	TransitionToRunning(ctx context.Context, runID int64) (bool, error)
+	// TransitionToRunningWithin 同 TransitionToRunning，但要求空间内执行中的 Run 少于 maxActive；
+	// 配额已满且 Run 仍为 SCHEDULED 时返回 ErrNamespaceQuotaExceeded
+	TransitionToRunningWithin(ctx context.Context, runID int64, namespace string, maxActive int) (bool, error)
+	// CountActiveInNamespace 空间内 RUNNING / CALLBACK_PENDING 的 Run 数
+	CountActiveInNamespace(ctx context.Context, namespace string) (int64, error)
 	MarkSuccess(ctx context.Context, runID int64, code int, body string) error
 	MarkFailed(ctx context.Context, runID int64, errMsg string) error
-	MarkCanceled(ctx context.Context, runID int64) error
+	// MarkCanceled 结束未完成的 Run 为 CANCELED 并记录原因
+	MarkCanceled(ctx context.Context, runID int64, reason bizConsts.CancelReason) error
+	// RequestCancel 对执行中的 Run 记录取消原因与时间（已请求过的不覆盖），返回是否首次请求
+	RequestCancel(ctx context.Context, runID int64, reason bizConsts.CancelReason) (bool, error)
+	// EndCanceled 以 status（CANCELED / TIMEOUT / KILLED）结束已请求取消且仍未结束的 Run，返回是否更新
+	EndCanceled(ctx context.Context, runID int64, status bizConsts.RunStatus, errMsg string) (bool, error)
+	// ListCancelRequested 状态在 statuses 内、在 before 之前（含）请求了取消的 Run
+	ListCancelRequested(ctx context.Context, statuses []bizConsts.RunStatus, before time.Time, limit int) ([]*model.TaskRun, error)
+	// ListExecutionExpired 等待回调且已超过 execution_timeout_sec、尚未请求取消的 Run
+	ListExecutionExpired(ctx context.Context, now time.Time, limit int) ([]*model.TaskRun, error)
 	MarkSkipped(ctx context.Context, runID int64, skipType bizConsts.RunStatus) error
 	MarkTimeout(ctx context.Context, runID int64, errMsg string) error
 	MarkCallbackPending(ctx context.Context, runID int64) error
@@ -50,6 +75,37 @@ type RunDao interface {
 	UpdateRequestSnapshot(ctx context.Context, runID int64, headersJSON string, body string) error
 	// Persist downstream response snapshot (code/body/error) for observability.
 	UpdateResponseSnapshot(ctx context.Context, runID int64, code *int, body string, errMsg string) error
+	UpdateStderr(ctx context.Context, runID int64, stderr string) error
+	// 重试：失败 Run 上记录/清除 next_retry_time，到期后派发重试 Run
+	CreateRetry(ctx context.Context, run *model.TaskRun) error
+	SetNextRetryTime(ctx context.Context, runID int64, at *time.Time) error
+	ListRetryDue(ctx context.Context, now time.Time, limit int) ([]*model.TaskRun, error)
+	// QUEUE 并发策略：持久化排队，按 FIFO 提升为 SCHEDULED
+	CountByStatus(ctx context.Context, taskID int64, statuses []bizConsts.RunStatus) (int64, error)
+	PromoteQueued(ctx context.Context, taskID int64, maxActive int) ([]*model.TaskRun, error)
+	DropOldestQueued(ctx context.Context, taskID int64) (int64, error)
+	ListQueuedTaskIDs(ctx context.Context) ([]int64, error)
+	// 任务依赖（DAG）：已结束但尚未评估下游的 Run、同一业务日期的最新 Run、重跑轮次与 Run 级血缘
+	ListDagPending(ctx context.Context, limit int) ([]*model.TaskRun, error)
+	MarkDagResolved(ctx context.Context, ids []int64) error
+	LatestByLogicalDate(ctx context.Context, taskIDs []int64, date time.Time) (map[int64]*model.TaskRun, error)
+	MaxDagGeneration(ctx context.Context, date time.Time) (int, error)
+	AddLineage(ctx context.Context, runID int64, upstreamRunIDs []int64) error
+	ListLineage(ctx context.Context, runID int64) (upstream, downstream []*model.TaskRun, err error)
+	// 补跑：批次内未结束（含已计划重试）的 Run、状态统计与区间内已成功的触发点
+	ListBackfillActive(ctx context.Context, backfillID int64) ([]*model.TaskRun, error)
+	ListByBackfill(ctx context.Context, backfillID int64, limit, offset int) ([]*model.TaskRun, error)
+	CountStatusByBackfill(ctx context.Context, backfillID int64) (map[bizConsts.RunStatus]int64, error)
+	ListSucceededTimes(ctx context.Context, taskID int64, from, to time.Time) ([]time.Time, error)
+	// 异步回调：仅当 Run 仍为 CALLBACK_PENDING 时写入结果（返回是否生效），回调记录落 async_callbacks
+	FinalizeCallback(ctx context.Context, runID int64, status bizConsts.RunStatus, code int, body, errMsg string) (bool, error)
+	CreateCallback(ctx context.Context, cb *model.AsyncCallback) error
+	ListCallbacks(ctx context.Context, runID int64, limit int) ([]*model.AsyncCallback, error)
+	// 进度快照与 Run 日志
+	UpdateProgress(ctx context.Context, runID int64, current, total int64, msg string, at time.Time) error
+	ListProgress(ctx context.Context, endedAfter time.Time, limit int) ([]*model.TaskRun, error)
+	AppendLogs(ctx context.Context, logs []*model.RunLog) error
+	ListLogs(ctx context.Context, runID, afterID int64, limit int) ([]*model.RunLog, error)
 }
 
 type runDaoImpl struct {
@@ -89,10 +145,8 @@ func (r *runDaoImpl) CreateScheduled(ctx context.Context, run *model.TaskRun) er
 	if run.Attempt == 0 {
 		run.Attempt = 1
 	}
-	if strings.TrimSpace(run.RequestHeaders) == "" { // JSON column requires valid document
-		run.RequestHeaders = bizConsts.DEFAULT_JSON_STR
-	}
-	return r.db.WithContext(ctx).Create(run).Error
+	applyRunDefaults(run)
+	return r.createOnce(ctx, run)
 }
 
 // CreateSkipped creates a run directly in a skipped terminal state (e.g. CONCURRENT_SKIP/OVERLAP_SKIP/FAILURE_SKIP)
@@ -101,13 +155,85 @@ func (r *runDaoImpl) CreateSkipped(ctx context.Context, run *model.TaskRun, skip
 	if run.Attempt == 0 {
 		run.Attempt = 1
 	}
-	if strings.TrimSpace(run.RequestHeaders) == "" {
-		run.RequestHeaders = bizConsts.DEFAULT_JSON_STR
-	}
+	applyRunDefaults(run)
 	now := time.Now()
 	run.Status = skipType
 	run.EndTime = &now
-	return r.db.WithContext(ctx).Create(run).Error
+	return r.createOnce(ctx, run)
+}
+
+// createOnce 幂等插入，冲突时返回 ErrDuplicateRun：
+// 依赖触发/重跑的 Run 按 (task_id, logical_date, dag_generation) 去重（uniq_dag_run），
+// 补跑 Run 按 (backfill_id, scheduled_time) 去重（uniq_backfill_run），
+// 其余非重试 Run 按 (task_id, scheduled_time) 去重（uniq_task_schedule）。
+func (r *runDaoImpl) createOnce(ctx context.Context, run *model.TaskRun) error {
+	if run.TriggerType == "" {
+		run.TriggerType = bizConsts.TriggerCron
+	}
+	if isDagTrigger(run.TriggerType) {
+		return r.insertOnConflict(ctx, run, []clause.Column{{Name: "task_id"}, {Name: "logical_date"}, {Name: "dag_generation"}},
+			"retry_of IS NULL AND trigger_type IN ('DEPENDENCY', 'RERUN')")
+	}
+	if run.BackfillID != nil {
+		run.TriggerType = bizConsts.TriggerBackfill
+		return r.insertOnConflict(ctx, run, []clause.Column{{Name: "backfill_id"}, {Name: "scheduled_time"}},
+			"retry_of IS NULL AND backfill_id IS NOT NULL")
+	}
+	return r.insertOnConflict(ctx, run, []clause.Column{{Name: "task_id"}, {Name: "scheduled_time"}},
+		"retry_of IS NULL AND trigger_type NOT IN ('DEPENDENCY', 'RERUN', 'BACKFILL')")
+}
+
+func isDagTrigger(t bizConsts.TriggerType) bool {
+	return t == bizConsts.TriggerDependency || t == bizConsts.TriggerRerun
+}
+
+// CreateRetry 创建重试 Run（RetryOf/RetryIndex 已设置），(retry_of, retry_index) 冲突时返回 ErrDuplicateRun。
+func (r *runDaoImpl) CreateRetry(ctx context.Context, run *model.TaskRun) error {
+	if run.RetryOf == nil || run.RetryIndex <= 0 {
+		return fmt.Errorf("retry run requires retry_of and retry_index")
+	}
+	if run.Status == "" {
+		run.Status = bizConsts.Scheduled
+	}
+	if run.TriggerType == "" {
+		run.TriggerType = bizConsts.TriggerCron
+	}
+	applyRunDefaults(run)
+	return r.insertOnConflict(ctx, run, []clause.Column{{Name: "retry_of"}, {Name: "retry_index"}}, "retry_of IS NOT NULL")
+}
+
+// applyRunDefaults JSON / 枚举列不接受空串，补齐默认值；并为 Run 生成回调 token。
+func applyRunDefaults(run *model.TaskRun) {
+	if strings.TrimSpace(run.RequestHeaders) == "" {
+		run.RequestHeaders = bizConsts.DEFAULT_JSON_STR
+	}
+	if strings.TrimSpace(run.ExecutorConfig) == "" {
+		run.ExecutorConfig = bizConsts.DEFAULT_JSON_STR
+	}
+	if run.Executor == "" {
+		run.Executor = bizConsts.DEFAULT_EXECUTOR
+	}
+	if run.CallbackToken == "" {
+		run.CallbackToken = model.NewCallbackToken()
+	}
+	if run.Namespace == "" {
+		run.Namespace = bizConsts.DEFAULT_NAMESPACE
+	}
+}
+
+func (r *runDaoImpl) insertOnConflict(ctx context.Context, run *model.TaskRun, cols []clause.Column, where string) error {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:     cols,
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: where}}},
+		DoNothing:   true,
+	}).Create(run)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrDuplicateRun
+	}
+	return nil
 }
 
 func (r *runDaoImpl) TransitionToRunning(ctx context.Context, runID int64) (bool, error) {
@@ -116,6 +242,47 @@ func (r *runDaoImpl) TransitionToRunning(ctx context.Context, runID int64) (bool
 	return res.RowsAffected == 1 && res.Error == nil, res.Error
 }
 
+// TransitionToRunningWithin 在事务内按空间加 advisory 锁后计数，多副本并发调用不会超出配额。
+func (r *runDaoImpl) TransitionToRunningWithin(ctx context.Context, runID int64, namespace string, maxActive int) (bool, error) {
+	if maxActive <= 0 {
+		return r.TransitionToRunning(ctx, runID)
+	}
+	var ok bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('cronjob_namespace_quota'), hashtext(?))", namespace).Error; err != nil {
+			return err
+		}
+		var active int64
+		if err := tx.Model(&model.TaskRun{}).Where("namespace=? AND status IN ?", namespace, activeRunStatuses).Count(&active).Error; err != nil {
+			return err
+		}
+		if active >= int64(maxActive) {
+			var n int64
+			if err := tx.Model(&model.TaskRun{}).Where("id=? AND status=?", runID, bizConsts.Scheduled).Count(&n).Error; err != nil {
+				return err
+			}
+			if n > 0 {
+				return ErrNamespaceQuotaExceeded
+			}
+			return nil
+		}
+		now := time.Now()
+		res := tx.Model(&model.TaskRun{}).Where("id=? AND status=?", runID, bizConsts.Scheduled).Updates(map[string]any{"status": bizConsts.Running, "start_time": &now})
+		ok = res.RowsAffected == 1
+		return res.Error
+	})
+	return ok, err
+}
+
+// activeRunStatuses 计入空间并发配额的状态。
+var activeRunStatuses = []bizConsts.RunStatus{bizConsts.Running, bizConsts.CallbackPending}
+
+func (r *runDaoImpl) CountActiveInNamespace(ctx context.Context, namespace string) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("namespace=? AND status IN ?", namespace, activeRunStatuses).Count(&n).Error
+	return n, err
+}
+
 func (r *runDaoImpl) MarkSuccess(ctx context.Context, runID int64, code int, body string) error {
 	// Add status guard to avoid overwriting TIMEOUT/FAILED states set by scanner concurrently.
 	now := time.Now()
@@ -127,9 +294,42 @@ func (r *runDaoImpl) MarkFailed(ctx context.Context, runID int64, errMsg string)
 	return r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("id=? AND status IN ?", runID, []bizConsts.RunStatus{bizConsts.Running, bizConsts.Scheduled}).Updates(map[string]any{"status": bizConsts.Failed, "error_message": errMsg, "end_time": &now}).Error
 }
 
-func (r *runDaoImpl) MarkCanceled(ctx context.Context, runID int64) error {
+// activeStatuses 尚未结束、可以取消的 Run 状态
+var activeStatuses = []bizConsts.RunStatus{bizConsts.Queued, bizConsts.Scheduled, bizConsts.Running, bizConsts.CallbackPending}
+
+func (r *runDaoImpl) MarkCanceled(ctx context.Context, runID int64, reason bizConsts.CancelReason) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("id=? AND status IN ?", runID, activeStatuses).Updates(map[string]any{"status": bizConsts.Canceled, "cancel_reason": reason, "end_time": &now}).Error
+}
+
+func (r *runDaoImpl) RequestCancel(ctx context.Context, runID int64, reason bizConsts.CancelReason) (bool, error) {
 	now := time.Now()
-	return r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("id=? AND status IN ?", runID, []bizConsts.RunStatus{bizConsts.Scheduled, bizConsts.Running, bizConsts.CallbackPending}).Updates(map[string]any{"status": bizConsts.Canceled, "end_time": &now}).Error
+	res := r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("id=? AND status IN ? AND cancel_requested_at IS NULL", runID, activeStatuses).
+		Updates(map[string]any{"cancel_reason": reason, "cancel_requested_at": &now})
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *runDaoImpl) EndCanceled(ctx context.Context, runID int64, status bizConsts.RunStatus, errMsg string) (bool, error) {
+	now := time.Now()
+	res := r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("id=? AND status IN ? AND cancel_requested_at IS NOT NULL", runID, activeStatuses).
+		Updates(map[string]any{"status": status, "error_message": errMsg, "end_time": &now})
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *runDaoImpl) ListCancelRequested(ctx context.Context, statuses []bizConsts.RunStatus, before time.Time, limit int) ([]*model.TaskRun, error) {
+	var list []*model.TaskRun
+	err := r.db.WithContext(ctx).Where("status IN ? AND cancel_requested_at IS NOT NULL AND cancel_requested_at <= ?", statuses, before).
+		Order("cancel_requested_at ASC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *runDaoImpl) ListExecutionExpired(ctx context.Context, now time.Time, limit int) ([]*model.TaskRun, error) {
+	var list []*model.TaskRun
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND execution_timeout_sec > 0 AND cancel_requested_at IS NULL AND start_time IS NOT NULL", bizConsts.CallbackPending).
+		Where("start_time + make_interval(secs => execution_timeout_sec) <= ?", now).
+		Order("id ASC").Limit(limit).Find(&list).Error
+	return list, err
 }
 
 func (r *runDaoImpl) MarkSkipped(ctx context.Context, runID int64, skipType bizConsts.RunStatus) error {
@@ -182,7 +382,7 @@ func (r *runDaoImpl) ListByTask(ctx context.Context, taskID int64, limit int) ([
 
 func (r *runDaoImpl) ListActive(ctx context.Context, limit int) ([]*model.TaskRun, error) {
 	var list []*model.TaskRun
-	q := r.db.WithContext(ctx).Where("status IN ?", []bizConsts.RunStatus{bizConsts.Scheduled, bizConsts.Running, bizConsts.CallbackPending}).Order("id DESC")
+	q := r.db.WithContext(ctx).Where("status IN ?", []bizConsts.RunStatus{bizConsts.Queued, bizConsts.Scheduled, bizConsts.Running, bizConsts.CallbackPending}).Order("id DESC")
 	if limit > 0 {
 		q = q.Limit(limit)
 	}
@@ -252,6 +452,73 @@ func (r *runDaoImpl) UpdateRequestSnapshot(ctx context.Context, runID int64, hea
 		Updates(map[string]any{"request_headers": headersJSON, "request_body": body}).Error
 }
 
+// UpdateStderr 记录 COMMAND 后端捕获的 stderr。
+func (r *runDaoImpl) UpdateStderr(ctx context.Context, runID int64, stderr string) error {
+	return r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("id=?", runID).UpdateColumn("stderr", stderr).Error
+}
+
+// FinalizeCallback 条件更新：并发的重复回调只有一个生效，已结束的 Run 不会被改写。
+func (r *runDaoImpl) FinalizeCallback(ctx context.Context, runID int64, status bizConsts.RunStatus, code int, body, errMsg string) (bool, error) {
+	now := time.Now()
+	updates := map[string]any{"status": status, "end_time": &now}
+	if status == bizConsts.Success {
+		updates["response_code"], updates["response_body"] = code, body
+	} else {
+		updates["error_message"] = errMsg
+	}
+	res := r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("id=? AND status=?", runID, bizConsts.CallbackPending).Updates(updates)
+	return res.RowsAffected == 1 && res.Error == nil, res.Error
+}
+
+func (r *runDaoImpl) CreateCallback(ctx context.Context, cb *model.AsyncCallback) error {
+	if strings.TrimSpace(cb.HeadersJSON) == "" {
+		cb.HeadersJSON = bizConsts.DEFAULT_JSON_STR
+	}
+	return r.db.WithContext(ctx).Create(cb).Error
+}
+
+func (r *runDaoImpl) ListCallbacks(ctx context.Context, runID int64, limit int) ([]*model.AsyncCallback, error) {
+	var list []*model.AsyncCallback
+	q := r.db.WithContext(ctx).Where("task_run_id=?", runID).Order("id DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	return list, q.Find(&list).Error
+}
+
+func (r *runDaoImpl) UpdateProgress(ctx context.Context, runID int64, current, total int64, msg string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("id=?", runID).UpdateColumns(map[string]any{
+		"progress_current": current, "progress_total": total, "progress_message": msg, "progress_updated_at": at,
+	}).Error
+}
+
+// ListProgress 有进度的 Run：未结束的，以及 endedAfter 之后结束的；按进度更新时间倒序。
+func (r *runDaoImpl) ListProgress(ctx context.Context, endedAfter time.Time, limit int) ([]*model.TaskRun, error) {
+	var list []*model.TaskRun
+	q := r.db.WithContext(ctx).Where("progress_updated_at IS NOT NULL AND (end_time IS NULL OR end_time >= ?)", endedAfter).
+		Order("progress_updated_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	return list, q.Find(&list).Error
+}
+
+func (r *runDaoImpl) AppendLogs(ctx context.Context, logs []*model.RunLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(logs, 500).Error
+}
+
+func (r *runDaoImpl) ListLogs(ctx context.Context, runID, afterID int64, limit int) ([]*model.RunLog, error) {
+	var list []*model.RunLog
+	q := r.db.WithContext(ctx).Where("task_run_id=? AND id>?", runID, afterID).Order("id ASC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	return list, q.Find(&list).Error
+}
+
 func (r *runDaoImpl) UpdateResponseSnapshot(ctx context.Context, runID int64, code *int, body string, errMsg string) error {
 	updates := map[string]any{"response_body": body}
 	if code != nil {
@@ -265,6 +532,200 @@ func (r *runDaoImpl) UpdateResponseSnapshot(ctx context.Context, runID int64, co
 		Updates(updates).Error
 }
 
+// retryableStatuses 可以计划重试的终止状态
+var retryableStatuses = []bizConsts.RunStatus{bizConsts.Failed, bizConsts.Timeout, bizConsts.FailedTimeout, bizConsts.CallbackFailed}
+
+// SetNextRetryTime 设置（at 非空，仅对失败状态生效）或清除（at 为空）下次重试时间。
+func (r *runDaoImpl) SetNextRetryTime(ctx context.Context, runID int64, at *time.Time) error {
+	q := r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("id=?", runID)
+	if at != nil {
+		q = q.Where("status IN ?", retryableStatuses)
+	}
+	return q.UpdateColumn("next_retry_time", at).Error
+}
+
+func (r *runDaoImpl) ListRetryDue(ctx context.Context, now time.Time, limit int) ([]*model.TaskRun, error) {
+	var list []*model.TaskRun
+	q := r.db.WithContext(ctx).
+		Where("next_retry_time IS NOT NULL AND next_retry_time <= ? AND status IN ?", now, retryableStatuses).
+		Order("next_retry_time ASC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *runDaoImpl) CountByStatus(ctx context.Context, taskID int64, statuses []bizConsts.RunStatus) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("task_id=? AND status IN ?", taskID, statuses).Count(&n).Error
+	return n, err
+}
+
+// PromoteQueued 在事务内按任务加 advisory 锁，计算剩余槽位（maxActive - SCHEDULED/RUNNING 数，maxActive<=0 不限），
+// 按 (scheduled_time, id) FIFO 把 QUEUED 提升为 SCHEDULED 并返回。多副本并发调用不会超出槽位。
+func (r *runDaoImpl) PromoteQueued(ctx context.Context, taskID int64, maxActive int) ([]*model.TaskRun, error) {
+	var promoted []*model.TaskRun
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('cronjob_run_queue'), CAST(? AS INT))", taskID).Error; err != nil {
+			return err
+		}
+		slots := 1000
+		if maxActive > 0 {
+			var active int64
+			if err := tx.Model(&model.TaskRun{}).Where("task_id=? AND status IN ?", taskID, []bizConsts.RunStatus{bizConsts.Scheduled, bizConsts.Running}).Count(&active).Error; err != nil {
+				return err
+			}
+			slots = maxActive - int(active)
+		}
+		if slots <= 0 {
+			return nil
+		}
+		return tx.Raw(`UPDATE task_runs SET status = ? WHERE id IN (
+    SELECT id FROM task_runs WHERE task_id = ? AND status = ? ORDER BY scheduled_time, id LIMIT ?
+) RETURNING *`, bizConsts.Scheduled, taskID, bizConsts.Queued, slots).Scan(&promoted).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(promoted, func(i, j int) bool { // RETURNING 不保证顺序
+		if !promoted[i].ScheduledTime.Equal(promoted[j].ScheduledTime) {
+			return promoted[i].ScheduledTime.Before(promoted[j].ScheduledTime)
+		}
+		return promoted[i].ID < promoted[j].ID
+	})
+	return promoted, nil
+}
+
+// DropOldestQueued 将最早排队的 Run 标记为 CONCURRENT_SKIP，返回其 ID（队列为空时返回 0）。
+func (r *runDaoImpl) DropOldestQueued(ctx context.Context, taskID int64) (int64, error) {
+	var ids []int64
+	err := r.db.WithContext(ctx).Raw(`UPDATE task_runs SET status = ?, end_time = ? WHERE id = (
+    SELECT id FROM task_runs WHERE task_id = ? AND status = ? ORDER BY scheduled_time, id LIMIT 1 FOR UPDATE SKIP LOCKED
+) RETURNING id`, bizConsts.ConcurrentSkip, time.Now(), taskID, bizConsts.Queued).Scan(&ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+func (r *runDaoImpl) ListQueuedTaskIDs(ctx context.Context) ([]int64, error) {
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("status=?", bizConsts.Queued).Distinct().Pluck("task_id", &ids).Error
+	return ids, err
+}
+
+// ListDagPending 已结束但尚未评估下游的 Run，按 id 升序。
+func (r *runDaoImpl) ListDagPending(ctx context.Context, limit int) ([]*model.TaskRun, error) {
+	var list []*model.TaskRun
+	err := r.db.WithContext(ctx).Where("NOT dag_resolved AND status IN ?", bizConsts.FinishedStatuses).
+		Order("id").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *runDaoImpl) MarkDagResolved(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("id IN ?", ids).UpdateColumn("dag_resolved", true).Error
+}
+
+// LatestByLogicalDate 返回各任务在该业务日期下最新（id 最大，含重试）的 Run；没有 Run 的任务不在结果中。
+func (r *runDaoImpl) LatestByLogicalDate(ctx context.Context, taskIDs []int64, date time.Time) (map[int64]*model.TaskRun, error) {
+	out := make(map[int64]*model.TaskRun, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return out, nil
+	}
+	var list []*model.TaskRun
+	err := r.db.WithContext(ctx).Raw(`SELECT DISTINCT ON (task_id) * FROM task_runs
+WHERE task_id IN ? AND logical_date = ? ORDER BY task_id, id DESC`, taskIDs, date.Format(time.DateOnly)).Scan(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range list {
+		out[run.TaskID] = run
+	}
+	return out, nil
+}
+
+func (r *runDaoImpl) MaxDagGeneration(ctx context.Context, date time.Time) (int, error) {
+	var gen int
+	err := r.db.WithContext(ctx).Model(&model.TaskRun{}).Where("logical_date = ?", date.Format(time.DateOnly)).
+		Select("COALESCE(MAX(dag_generation), 0)").Scan(&gen).Error
+	return gen, err
+}
+
+func (r *runDaoImpl) AddLineage(ctx context.Context, runID int64, upstreamRunIDs []int64) error {
+	if len(upstreamRunIDs) == 0 {
+		return nil
+	}
+	rows := make([]*model.RunLineage, 0, len(upstreamRunIDs))
+	for _, up := range upstreamRunIDs {
+		rows = append(rows, &model.RunLineage{RunID: runID, UpstreamRunID: up})
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+// ListLineage 返回触发该 Run 的上游 Run 与由它触发的下游 Run。
+func (r *runDaoImpl) ListLineage(ctx context.Context, runID int64) ([]*model.TaskRun, []*model.TaskRun, error) {
+	var up, down []*model.TaskRun
+	if err := r.db.WithContext(ctx).Where("id IN (SELECT upstream_run_id FROM run_lineage WHERE run_id = ?)", runID).
+		Order("id").Find(&up).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := r.db.WithContext(ctx).Where("id IN (SELECT run_id FROM run_lineage WHERE upstream_run_id = ?)", runID).
+		Order("id").Find(&down).Error; err != nil {
+		return nil, nil, err
+	}
+	return up, down, nil
+}
+
+// ListBackfillActive 批次内尚未结束或已计划重试的 Run。
+func (r *runDaoImpl) ListBackfillActive(ctx context.Context, backfillID int64) ([]*model.TaskRun, error) {
+	var list []*model.TaskRun
+	err := r.db.WithContext(ctx).Where("backfill_id = ? AND (status NOT IN ? OR next_retry_time IS NOT NULL)", backfillID, bizConsts.FinishedStatuses).
+		Order("id").Find(&list).Error
+	return list, err
+}
+
+func (r *runDaoImpl) ListByBackfill(ctx context.Context, backfillID int64, limit, offset int) ([]*model.TaskRun, error) {
+	var list []*model.TaskRun
+	q := r.db.WithContext(ctx).Where("backfill_id = ?", backfillID).Order("scheduled_time, id")
+	if limit > 0 {
+		q = q.Limit(limit).Offset(offset)
+	}
+	err := q.Find(&list).Error
+	return list, err
+}
+
+func (r *runDaoImpl) CountStatusByBackfill(ctx context.Context, backfillID int64) (map[bizConsts.RunStatus]int64, error) {
+	var rows []struct {
+		Status bizConsts.RunStatus
+		Cnt    int64
+	}
+	err := r.db.WithContext(ctx).Model(&model.TaskRun{}).Select("status, COUNT(*) AS cnt").
+		Where("backfill_id = ?", backfillID).Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[bizConsts.RunStatus]int64, len(rows))
+	for _, row := range rows {
+		out[row.Status] = row.Cnt
+	}
+	return out, nil
+}
+
+// ListSucceededTimes 任务在 [from, to] 内已有成功 Run 的 scheduled_time（含此前的补跑与重试）。
+func (r *runDaoImpl) ListSucceededTimes(ctx context.Context, taskID int64, from, to time.Time) ([]time.Time, error) {
+	var times []time.Time
+	err := r.db.WithContext(ctx).Model(&model.TaskRun{}).
+		Where("task_id = ? AND status = ? AND scheduled_time BETWEEN ? AND ?", taskID, bizConsts.Success, from, to).
+		Distinct().Pluck("scheduled_time", &times).Error
+	return times, err
+}
+
 func (r *runDaoImpl) CountPerTask(ctx context.Context, limit int) (map[int64]int, error) {
 	rows, err := r.db.WithContext(ctx).Model(&model.TaskRun{}).Select("task_id, COUNT(*) as cnt").Group("task_id").Order("cnt DESC").Rows()
 	if err != nil {
@@ -396,7 +857,7 @@ func (r *runDaoImpl) ListByTaskFiltered(ctx context.Context, taskID int64, statu
 }
 
 func (r *runDaoImpl) ListActiveFiltered(ctx context.Context, statuses []bizConsts.RunStatus, from, to *time.Time, limit, offset int, timeField string) ([]*model.TaskRun, error) {
-	base := []bizConsts.RunStatus{bizConsts.Scheduled, bizConsts.Running, bizConsts.CallbackPending}
+	base := []bizConsts.RunStatus{bizConsts.Queued, bizConsts.Scheduled, bizConsts.Running, bizConsts.CallbackPending}
 	if len(statuses) > 0 {
 		base = statuses
 	}
@@ -2,8 +2,11 @@ package dao
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -27,6 +30,20 @@ type TaskDao interface {
 	// ListFiltered allows querying tasks by multiple optional filters.
 	ListFiltered(ctx context.Context, f *model.TaskListFilters, limit, offset int) ([]*model.Task, error)
 	CountFiltered(ctx context.Context, f *model.TaskListFilters) (int64, error)
+	// UpdateLastEvaluated persists the scheduler evaluation cursor without bumping version/updated_at.
+	UpdateLastEvaluated(ctx context.Context, ids []int64, at time.Time) error
+	// ChangeMarker returns a cheap fingerprint of the tasks table; every task write changes it, cursor writes do not.
+	ChangeMarker(ctx context.Context) (TaskMarker, error)
+	// ListDependencies returns all dependency edges between non-deleted tasks.
+	ListDependencies(ctx context.Context) ([]*model.TaskDependency, error)
+	// ReplaceUpstreams replaces the upstream set of a task atomically.
+	ReplaceUpstreams(ctx context.Context, taskID int64, upstreamIDs []int64) error
+}
+
+// TaskMarker 任务表的变更指纹：每次写任务都会 version+1 或新增行，因此任何变更都会改变 (行数, version 之和)。
+type TaskMarker struct {
+	RowCount   int64 `gorm:"column:row_count"`
+	VersionSum int64 `gorm:"column:version_sum"`
 }
 
 type TaskDaoImpl struct {
@@ -68,6 +85,12 @@ func (d *TaskDaoImpl) Create(ctx context.Context, t *model.Task) error {
 	if t.Version == 0 {
 		t.Version = 1
 	}
+	applyTaskDefaults(t)
+	return d.db.WithContext(ctx).Create(t).Error
+}
+
+// applyTaskDefaults 填充枚举/JSON 列的默认值（显式写入空串会违反枚举约束）。
+func applyTaskDefaults(t *model.Task) {
 	if strings.TrimSpace(t.HeadersJSON) == "" {
 		t.HeadersJSON = bizConsts.DEFAULT_JSON_STR
 	}
@@ -80,7 +103,42 @@ func (d *TaskDaoImpl) Create(ctx context.Context, t *model.Task) error {
 	if t.FailureAction == "" {
 		t.FailureAction = bizConsts.DEFAULT_FAILURE_ACTION
 	}
-	return d.db.WithContext(ctx).Create(t).Error
+	if t.MisfirePolicy == "" {
+		t.MisfirePolicy = bizConsts.DEFAULT_MISFIRE_POLICY
+	}
+	if t.ConcurrencyPolicy == "" {
+		t.ConcurrencyPolicy = bizConsts.DEFAULT_CONCURRENCY_POLICY
+	}
+	if t.QueueOverflow == "" {
+		t.QueueOverflow = bizConsts.DEFAULT_QUEUE_OVERFLOW
+	}
+	if t.QueueMaxDepth <= 0 {
+		t.QueueMaxDepth = bizConsts.DEFAULT_QUEUE_MAX_DEPTH
+	}
+	if t.ScheduleMode == "" {
+		t.ScheduleMode = bizConsts.DEFAULT_SCHEDULE_MODE
+	}
+	if t.TriggerRule == "" {
+		t.TriggerRule = bizConsts.DEFAULT_TRIGGER_RULE
+	}
+	if t.CalendarMode == "" {
+		t.CalendarMode = bizConsts.DEFAULT_CALENDAR_MODE
+	}
+	if t.Executor == "" {
+		t.Executor = bizConsts.DEFAULT_EXECUTOR
+	}
+	if strings.TrimSpace(t.ExecutorConfig) == "" {
+		t.ExecutorConfig = bizConsts.DEFAULT_JSON_STR
+	}
+	if t.Namespace == "" {
+		t.Namespace = bizConsts.DEFAULT_NAMESPACE
+	}
+	if t.Owners == nil {
+		t.Owners = []string{}
+	}
+	if t.Labels == nil {
+		t.Labels = map[string]string{}
+	}
 }
 
 func (d *TaskDaoImpl) Get(ctx context.Context, id int64) (*model.Task, error) {
@@ -91,6 +149,18 @@ func (d *TaskDaoImpl) Get(ctx context.Context, id int64) (*model.Task, error) {
 	return &t, nil
 }
 
+// jsonText 序列化 owners / labels（map 更新不经过 gorm 的 serializer）。
+func jsonText(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "null"
+	}
+	return string(b)
+}
+
+// IsNotFound 记录不存在（含已软删除的任务）。
+func IsNotFound(err error) bool { return errors.Is(err, gorm.ErrRecordNotFound) }
+
 func (d *TaskDaoImpl) ListEnabled(ctx context.Context) ([]*model.Task, error) {
 	var list []*model.Task
 	if err := d.db.WithContext(ctx).Where("status=? AND deleted=0", "ENABLED").Find(&list).Error; err != nil {
@@ -106,24 +176,46 @@ func (d *TaskDaoImpl) UpdateCronAndMeta(ctx context.Context, t *model.Task) erro
 	if strings.TrimSpace(t.RetryPolicyJSON) == "" {
 		t.RetryPolicyJSON = bizConsts.DEFAULT_JSON_STR
 	}
+	if strings.TrimSpace(t.ExecutorConfig) == "" {
+		t.ExecutorConfig = bizConsts.DEFAULT_JSON_STR
+	}
+	if t.Executor == "" {
+		t.Executor = bizConsts.DEFAULT_EXECUTOR
+	}
 	updates := map[string]interface{}{
-		"description":          t.Description,
-		"cron_expr":            t.CronExpr,
-		"timezone":             t.Timezone,
-		"exec_type":            t.ExecType,
-		"http_method":          t.HTTPMethod,
-		"target_service":       t.TargetService, // 修正：补充 target_service
-		"target_path":          t.TargetPath,    // 修正：补充 target_path
-		"headers_json":         t.HeadersJSON,
-		"body_template":        t.BodyTemplate,
-		"retry_policy_json":    t.RetryPolicyJSON,
-		"max_concurrency":      t.MaxConcurrency,
-		"concurrency_policy":   t.ConcurrencyPolicy,
-		"callback_method":      t.CallbackMethod,
-		"callback_timeout_sec": t.CallbackTimeoutSec,
-		"overlap_action":       t.OverlapAction,
-		"failure_action":       t.FailureAction,
-		"version":              gorm.Expr("version + 1"),
+		"description":           t.Description,
+		"namespace":             t.Namespace,
+		"owners":                jsonText(t.Owners),
+		"labels":                jsonText(t.Labels),
+		"cron_expr":             t.CronExpr,
+		"timezone":              t.Timezone,
+		"exec_type":             t.ExecType,
+		"executor":              t.Executor,
+		"executor_config":       t.ExecutorConfig,
+		"http_method":           t.HTTPMethod,
+		"target_service":        t.TargetService, // 修正：补充 target_service
+		"target_path":           t.TargetPath,    // 修正：补充 target_path
+		"headers_json":          t.HeadersJSON,
+		"body_template":         t.BodyTemplate,
+		"retry_policy_json":     t.RetryPolicyJSON,
+		"max_concurrency":       t.MaxConcurrency,
+		"concurrency_policy":    t.ConcurrencyPolicy,
+		"queue_max_depth":       t.QueueMaxDepth,
+		"queue_overflow":        t.QueueOverflow,
+		"callback_method":       t.CallbackMethod,
+		"callback_timeout_sec":  t.CallbackTimeoutSec,
+		"execution_timeout_sec": t.ExecutionTimeoutSec,
+		"overlap_action":        t.OverlapAction,
+		"failure_action":        t.FailureAction,
+		"misfire_policy":        t.MisfirePolicy,
+		"misfire_grace_sec":     t.MisfireGraceSec,
+		"schedule_mode":         t.ScheduleMode,
+		"trigger_rule":          t.TriggerRule,
+		"calendar":              t.Calendar,
+		"calendar_mode":         t.CalendarMode,
+		"managed":               t.Managed,
+		"sync_source":           t.SyncSource,
+		"version":               gorm.Expr("version + 1"),
 	}
 	// optimistic lock with version
 	res := d.db.WithContext(ctx).Model(&model.Task{}).
@@ -142,42 +234,69 @@ func (d *TaskDaoImpl) UpdateCronAndMeta(ctx context.Context, t *model.Task) erro
 }
 
 func (d *TaskDaoImpl) UpdateStatus(ctx context.Context, id int64, status bizConsts.TaskStatus) error {
-	res := d.db.WithContext(ctx).Model(&model.Task{}).Where("id=? AND deleted=0", id).Updates(map[string]any{"status": status, "version": gorm.Expr("version+1")})
+	updates := map[string]any{"status": status, "version": gorm.Expr("version+1")}
+	if status == bizConsts.ENABLED {
+		// 停用期间不算错过触发：重新启用时把评估游标移到当前时间
+		updates["last_evaluated_at"] = time.Now().UTC()
+	}
+	res := d.db.WithContext(ctx).Model(&model.Task{}).Where("id=? AND deleted=0", id).Updates(updates)
 	return res.Error
 }
 
-func (d *TaskDaoImpl) SoftDelete(ctx context.Context, id int64) error {
-	return d.db.WithContext(ctx).Model(&model.Task{}).Where("id=?", id).Update("deleted", 1).Error
+func (d *TaskDaoImpl) UpdateLastEvaluated(ctx context.Context, ids []int64, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return d.db.WithContext(ctx).Model(&model.Task{}).Where("id IN ?", ids).UpdateColumn("last_evaluated_at", at.UTC()).Error
 }
 
-func (d *TaskDaoImpl) ListFiltered(ctx context.Context, f *model.TaskListFilters, limit, offset int) ([]*model.Task, error) {
-	var list []*model.Task
-	db := d.db.WithContext(ctx).Model(&model.Task{}).Where("deleted=0")
-	if f != nil {
-		if f.Status != "" {
-			db = db.Where("status=?", f.Status)
-		}
-		if f.NameLike != "" {
-			like := fmt.Sprintf("%%%s%%", f.NameLike)
-			db = db.Where("name LIKE ?", like)
-		}
-		if f.DescriptionLike != "" {
-			like := fmt.Sprintf("%%%s%%", f.DescriptionLike)
-			db = db.Where("description LIKE ?", like)
-		}
-		if f.CreatedFrom != nil {
-			db = db.Where("created_at >= ?", f.CreatedFrom)
+func (d *TaskDaoImpl) ChangeMarker(ctx context.Context) (TaskMarker, error) {
+	var m TaskMarker
+	err := d.db.WithContext(ctx).Model(&model.Task{}).
+		Select("COUNT(*) AS row_count, COALESCE(SUM(version), 0) AS version_sum").
+		Scan(&m).Error
+	return m, err
+}
+
+// SoftDelete 软删除任务，并移除其作为上下游的依赖边。
+func (d *TaskDaoImpl) SoftDelete(ctx context.Context, id int64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Task{}).Where("id=?", id).Updates(map[string]any{"deleted": 1, "version": gorm.Expr("version+1")}).Error; err != nil {
+			return err
 		}
-		if f.CreatedTo != nil {
-			db = db.Where("created_at <= ?", f.CreatedTo)
+		return tx.Where("task_id=? OR upstream_task_id=?", id, id).Delete(&model.TaskDependency{}).Error
+	})
+}
+
+func (d *TaskDaoImpl) ListDependencies(ctx context.Context) ([]*model.TaskDependency, error) {
+	var list []*model.TaskDependency
+	err := d.db.WithContext(ctx).Model(&model.TaskDependency{}).
+		Joins("JOIN tasks t ON t.id = task_dependencies.task_id AND t.deleted = 0").
+		Joins("JOIN tasks u ON u.id = task_dependencies.upstream_task_id AND u.deleted = 0").
+		Order("task_dependencies.task_id, task_dependencies.upstream_task_id").
+		Find(&list).Error
+	return list, err
+}
+
+func (d *TaskDaoImpl) ReplaceUpstreams(ctx context.Context, taskID int64, upstreamIDs []int64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id=?", taskID).Delete(&model.TaskDependency{}).Error; err != nil {
+			return err
 		}
-		if f.UpdatedFrom != nil {
-			db = db.Where("updated_at >= ?", f.UpdatedFrom)
+		if len(upstreamIDs) == 0 {
+			return nil
 		}
-		if f.UpdatedTo != nil {
-			db = db.Where("updated_at <= ?", f.UpdatedTo)
+		edges := make([]*model.TaskDependency, 0, len(upstreamIDs))
+		for _, up := range upstreamIDs {
+			edges = append(edges, &model.TaskDependency{TaskID: taskID, UpstreamTaskID: up})
 		}
-	}
+		return tx.Omit("created_at").Create(&edges).Error
+	})
+}
+
+func (d *TaskDaoImpl) ListFiltered(ctx context.Context, f *model.TaskListFilters, limit, offset int) ([]*model.Task, error) {
+	var list []*model.Task
+	db := applyTaskFilters(d.db.WithContext(ctx).Model(&model.Task{}).Where("deleted=0"), f)
 	if limit > 0 {
 		db = db.Limit(limit)
 	}
@@ -192,38 +311,71 @@ func (d *TaskDaoImpl) ListFiltered(ctx context.Context, f *model.TaskListFilters
 
 func (d *TaskDaoImpl) CountFiltered(ctx context.Context, f *model.TaskListFilters) (int64, error) {
 	var cnt int64
-	db := d.db.WithContext(ctx).Model(&model.Task{}).Where("deleted=0")
-	if f != nil {
-		if f.Status != "" {
-			db = db.Where("status=?", f.Status)
-		}
-		if f.NameLike != "" {
-			like := fmt.Sprintf("%%%s%%", f.NameLike)
-			db = db.Where("name LIKE ?", like)
-		}
-		if f.DescriptionLike != "" {
-			like := fmt.Sprintf("%%%s%%", f.DescriptionLike)
-			db = db.Where("description LIKE ?", like)
-		}
-		if f.CreatedFrom != nil {
-			db = db.Where("created_at >= ?", f.CreatedFrom)
-		}
-		if f.CreatedTo != nil {
-			db = db.Where("created_at <= ?", f.CreatedTo)
-		}
-		if f.UpdatedFrom != nil {
-			db = db.Where("updated_at >= ?", f.UpdatedFrom)
-		}
-		if f.UpdatedTo != nil {
-			db = db.Where("updated_at <= ?", f.UpdatedTo)
-		}
-	}
+	db := applyTaskFilters(d.db.WithContext(ctx).Model(&model.Task{}).Where("deleted=0"), f)
 	if err := db.Count(&cnt).Error; err != nil {
 		return 0, err
 	}
 	return cnt, nil
 }
 
+func applyTaskFilters(db *gorm.DB, f *model.TaskListFilters) *gorm.DB {
+	if f == nil {
+		return db
+	}
+	if f.Status != "" {
+		db = db.Where("status=?", f.Status)
+	}
+	if f.NameLike != "" {
+		like := fmt.Sprintf("%%%s%%", f.NameLike)
+		db = db.Where("name LIKE ?", like)
+	}
+	if f.DescriptionLike != "" {
+		like := fmt.Sprintf("%%%s%%", f.DescriptionLike)
+		db = db.Where("description LIKE ?", like)
+	}
+	if f.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		db = db.Where("created_at <= ?", f.CreatedTo)
+	}
+	if f.UpdatedFrom != nil {
+		db = db.Where("updated_at >= ?", f.UpdatedFrom)
+	}
+	if f.UpdatedTo != nil {
+		db = db.Where("updated_at <= ?", f.UpdatedTo)
+	}
+	if f.Namespace != "" {
+		db = db.Where("namespace=?", f.Namespace)
+	}
+	if f.Owner != "" {
+		owner, _ := json.Marshal([]string{f.Owner})
+		db = db.Where("owners @> CAST(? AS JSONB)", string(owner))
+	}
+	return applyLabelSelector(db, f.Selector)
+}
+
+// applyLabelSelector 把标签选择器转换为 labels（JSONB）上的条件，语义与 LabelSelector.Matches 一致。
+func applyLabelSelector(db *gorm.DB, sel model.LabelSelector) *gorm.DB {
+	for _, req := range sel {
+		switch req.Op {
+		case model.LabelEquals:
+			db = db.Where("labels ->> ? = ?", req.Key, req.Values[0])
+		case model.LabelNotEquals:
+			db = db.Where("(labels ->> ?) IS DISTINCT FROM ?", req.Key, req.Values[0])
+		case model.LabelIn:
+			db = db.Where("labels ->> ? IN ?", req.Key, req.Values)
+		case model.LabelNotIn:
+			db = db.Where("(labels ->> ? IS NULL OR labels ->> ? NOT IN ?)", req.Key, req.Key, req.Values)
+		case model.LabelExists:
+			db = db.Where("labels ->> ? IS NOT NULL", req.Key)
+		case model.LabelNotExists:
+			db = db.Where("labels ->> ? IS NULL", req.Key)
+		}
+	}
+	return db
+}
+
 func (d *TaskDaoImpl) ExistsByName(ctx context.Context, name string) bool {
 	var count int64
 	d.db.WithContext(ctx).Model(&model.Task{}).Where("name=? AND deleted=0", name).Count(&count)
@@ -241,26 +393,43 @@ func (d *TaskDaoImpl) ReactivateByName(ctx context.Context, name string, t *mode
 		return 0, false, err
 	}
 
+	applyTaskDefaults(t)
 	updates := map[string]interface{}{
-		"description":          t.Description,
-		"cron_expr":            t.CronExpr,
-		"timezone":             t.Timezone,
-		"exec_type":            t.ExecType,
-		"http_method":          t.HTTPMethod,
-		"target_service":       t.TargetService,
-		"target_path":          t.TargetPath,
-		"headers_json":         t.HeadersJSON,
-		"body_template":        t.BodyTemplate,
-		"retry_policy_json":    t.RetryPolicyJSON,
-		"max_concurrency":      t.MaxConcurrency,
-		"concurrency_policy":   t.ConcurrencyPolicy,
-		"callback_method":      t.CallbackMethod,
-		"callback_timeout_sec": t.CallbackTimeoutSec,
-		"overlap_action":       t.OverlapAction,
-		"failure_action":       t.FailureAction,
-		"status":               t.Status,
-		"deleted":              0,
-		"version":              gorm.Expr("version + 1"),
+		"description":           t.Description,
+		"namespace":             t.Namespace,
+		"owners":                jsonText(t.Owners),
+		"labels":                jsonText(t.Labels),
+		"cron_expr":             t.CronExpr,
+		"timezone":              t.Timezone,
+		"exec_type":             t.ExecType,
+		"executor":              t.Executor,
+		"executor_config":       t.ExecutorConfig,
+		"http_method":           t.HTTPMethod,
+		"target_service":        t.TargetService,
+		"target_path":           t.TargetPath,
+		"headers_json":          t.HeadersJSON,
+		"body_template":         t.BodyTemplate,
+		"retry_policy_json":     t.RetryPolicyJSON,
+		"max_concurrency":       t.MaxConcurrency,
+		"concurrency_policy":    t.ConcurrencyPolicy,
+		"queue_max_depth":       t.QueueMaxDepth,
+		"queue_overflow":        t.QueueOverflow,
+		"callback_method":       t.CallbackMethod,
+		"callback_timeout_sec":  t.CallbackTimeoutSec,
+		"execution_timeout_sec": t.ExecutionTimeoutSec,
+		"overlap_action":        t.OverlapAction,
+		"failure_action":        t.FailureAction,
+		"misfire_policy":        t.MisfirePolicy,
+		"misfire_grace_sec":     t.MisfireGraceSec,
+		"schedule_mode":         t.ScheduleMode,
+		"trigger_rule":          t.TriggerRule,
+		"calendar":              t.Calendar,
+		"calendar_mode":         t.CalendarMode,
+		"managed":               t.Managed,
+		"sync_source":           t.SyncSource,
+		"status":                t.Status,
+		"deleted":               0,
+		"version":               gorm.Expr("version + 1"),
 	}
 	res := d.db.WithContext(ctx).Model(&model.Task{}).Where("id=? AND deleted=1", existing.ID).Updates(updates)
 	if res.Error != nil {
@@ -0,0 +1,101 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	mg "github.com/grand-thief-cash/chaos/app/infra/go/application/components/postgresgorm"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// ErrRevisionNotFound 任务不存在该版本的历史记录。
+var ErrRevisionNotFound = errors.New("task revision not found")
+
+// TaskRevisionDao 任务版本历史（只追加）。
+type TaskRevisionDao interface {
+	core.Component
+	Create(ctx context.Context, rev *model.TaskRevision) error
+	// List 按 id 降序（最新在前）
+	List(ctx context.Context, taskID int64, limit, offset int) ([]*model.TaskRevision, error)
+	// Get 任务某版本的记录；同一版本有多条时取最新
+	Get(ctx context.Context, taskID int64, version int) (*model.TaskRevision, error)
+	// HasAny 任务是否已有任何版本记录
+	HasAny(ctx context.Context, taskID int64) (bool, error)
+}
+
+type taskRevisionDaoImpl struct {
+	db *gorm.DB
+	*core.BaseComponent
+	GormComp *mg.PostgresGormComponent `infra:"dep:postgres_gorm"`
+	dsName   string
+}
+
+func NewTaskRevisionDao(dsName string) TaskRevisionDao {
+	return &taskRevisionDaoImpl{
+		BaseComponent: core.NewBaseComponent(bizConsts.COMP_DAO_TASK_REVISION, consts.COMPONENT_LOGGING),
+		dsName:        dsName,
+	}
+}
+
+func (d *taskRevisionDaoImpl) Start(ctx context.Context) error {
+	if err := d.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	db, err := d.GormComp.GetDB(d.dsName)
+	if err != nil {
+		return fmt.Errorf("get gorm db %s failed: %w", d.dsName, err)
+	}
+	d.db = db
+	return nil
+}
+
+func (d *taskRevisionDaoImpl) Stop(ctx context.Context) error {
+	return d.BaseComponent.Stop(ctx)
+}
+
+func (d *taskRevisionDaoImpl) Create(ctx context.Context, rev *model.TaskRevision) error {
+	if rev.CreatedAt.IsZero() {
+		rev.CreatedAt = time.Now()
+	}
+	if rev.Diff == nil {
+		rev.Diff = []model.FieldChange{}
+	}
+	return d.db.WithContext(ctx).Create(rev).Error
+}
+
+func (d *taskRevisionDaoImpl) List(ctx context.Context, taskID int64, limit, offset int) ([]*model.TaskRevision, error) {
+	var list []*model.TaskRevision
+	q := d.db.WithContext(ctx).Where("task_id = ?", taskID).Order("id DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	return list, q.Find(&list).Error
+}
+
+func (d *taskRevisionDaoImpl) Get(ctx context.Context, taskID int64, version int) (*model.TaskRevision, error) {
+	var list []*model.TaskRevision
+	err := d.db.WithContext(ctx).Where("task_id = ? AND version = ?", taskID, version).Order("id DESC").Limit(1).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrRevisionNotFound
+	}
+	return list[0], nil
+}
+
+func (d *taskRevisionDaoImpl) HasAny(ctx context.Context, taskID int64) (bool, error) {
+	var n int64
+	err := d.db.WithContext(ctx).Model(&model.TaskRevision{}).Where("task_id = ?", taskID).Count(&n).Error
+	return n > 0, err
+}
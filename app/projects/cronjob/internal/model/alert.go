@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+)
+
+// AlertRule 任务级告警规则；参数按 kind 取用。
+type AlertRule struct {
+	ID          int64            `json:"id"`
+	TaskID      int64            `json:"task_id"`
+	Kind        consts.AlertKind `json:"kind"`
+	Threshold   int              `json:"threshold"`                       // CONSECUTIVE_FAILURES：连续失败次数（>=2）
+	Factor      float64          `json:"factor"`                          // SLOW_RUN：耗时超过 P95 × factor 告警（默认 1.5）
+	MinSamples  int              `json:"min_samples"`                     // SLOW_RUN：成功样本不足时不判断（默认 10）
+	SLASec      int              `json:"sla_sec"`                         // SLA_MISSED：触发后多少秒内须成功
+	Channels    []string         `json:"channels" gorm:"serializer:json"` // 通知渠道名；为空取 alert.default_channels
+	SuppressSec int              `json:"suppress_sec"`                    // 同一告警的抑制窗口；<=0 取 alert.default_suppress
+	Enabled     bool             `json:"enabled"`                         // 停用的规则不评估
+	Description string           `json:"description"`                     // 备注，随通知发送
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+func (AlertRule) TableName() string { return "alert_rules" }
+
+// AlertEvent 告警历史：一条告警在一个渠道上的发送结果（含被抑制的）。
+type AlertEvent struct {
+	ID        int64              `json:"id"`
+	RuleID    int64              `json:"rule_id"`
+	TaskID    int64              `json:"task_id"`
+	RunID     *int64             `json:"run_id"`
+	Kind      consts.AlertKind   `json:"kind"`
+	DedupKey  string             `json:"dedup_key"` // 同一规则下相同 key 视为同一告警，抑制窗口内只发送一次
+	Channel   string             `json:"channel"`
+	Status    consts.AlertStatus `json:"status"`
+	Title     string             `json:"title"`
+	Message   string             `json:"message"`
+	Error     string             `json:"error"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+func (AlertEvent) TableName() string { return "alert_history" }
+
+// AlertEventFilter 告警历史查询条件，零值表示不过滤。
+type AlertEventFilter struct {
+	TaskID int64
+	RuleID int64
+	RunID  int64
+	Kind   consts.AlertKind
+	Status consts.AlertStatus
+	From   *time.Time
+	To     *time.Time
+}
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// AnalyticsFilter 运行分析的范围：scheduled_time 落在 [From, To) 内的 Run；TaskID 为 0 表示全部任务。
+type AnalyticsFilter struct {
+	From   time.Time
+	To     time.Time
+	TaskID int64
+}
+
+// TaskRunAnalytics 一个任务在窗口内的执行统计；每次尝试（含重试）计为一个 Run。耗时单位为秒。
+type TaskRunAnalytics struct {
+	TaskID      int64   `json:"task_id"`
+	TaskName    string  `json:"task_name"`
+	Runs        int64   `json:"runs"`         // 已结束且实际执行过的 Run（不含跳过）
+	Succeeded   int64   `json:"succeeded"`    // SUCCESS
+	Failed      int64   `json:"failed"`       // FAILED / TIMEOUT / CALLBACK_FAILED / FAILED_TIMEOUT / KILLED
+	Canceled    int64   `json:"canceled"`     // CANCELED
+	Skipped     int64   `json:"skipped"`      // 各类 *_SKIP / SKIPPED
+	SuccessRate float64 `json:"success_rate"` // succeeded / (succeeded + failed)，取消不计入
+	DurationP50 float64 `json:"duration_p50"` // end_time - start_time
+	DurationP95 float64 `json:"duration_p95"`
+	DurationP99 float64 `json:"duration_p99"`
+	QueueAvg    float64 `json:"queue_avg"` // 开始执行前的等待：start_time - max(created_at, scheduled_time)
+	QueueP95    float64 `json:"queue_p95"`
+	ExecAvg     float64 `json:"exec_avg"` // end_time - start_time 的平均值（ASYNC 含等待回调的时间）
+	// ASYNC：从开始执行到生效回调（async_callbacks.status = RECEIVED）的时长；没有回调样本时为空
+	CallbackSamples int64    `json:"callback_samples"`
+	CallbackP50     *float64 `json:"callback_p50"`
+	CallbackP95     *float64 `json:"callback_p95"`
+}
+
+// DailyRunStats 某一天（按请求时区的日期）的执行结果。
+type DailyRunStats struct {
+	Date        string  `json:"date"` // YYYY-MM-DD
+	Runs        int64   `json:"runs"`
+	Succeeded   int64   `json:"succeeded"`
+	Failed      int64   `json:"failed"`
+	SuccessRate float64 `json:"success_rate"` // 当天无成功或失败的 Run 时为 0
+}
+
+// SLAReport 任务在窗口内的 SLA 达成情况：以首次触发（不含重试、跳过、取消与补跑）为单位，
+// 截止时间 scheduled_time + sla_sec 前整条重试链有成功即为达成；截止时间未到的触发不计入。
+type SLAReport struct {
+	TaskID     int64   `json:"task_id"`
+	TaskName   string  `json:"task_name"`
+	SLASec     int     `json:"sla_sec"`
+	Fires      int64   `json:"fires"`
+	Met        int64   `json:"met"`
+	Missed     int64   `json:"missed"`
+	Compliance float64 `json:"compliance"` // met / fires；fires 为 0 时为 1
+}
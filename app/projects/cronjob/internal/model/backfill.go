@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+)
+
+// Backfill 一次历史区间补跑：对 [RangeStart, RangeEnd] 内的每个触发点按并行度派发一个 Run。
+type Backfill struct {
+	ID            int64                 `json:"id"`             // 批次 ID
+	TaskID        int64                 `json:"task_id"`        // 补跑的任务
+	RangeStart    time.Time             `json:"range_start"`    // 区间起点（含，UTC）
+	RangeEnd      time.Time             `json:"range_end"`      // 区间终点（含，UTC）
+	MaxParallel   int                   `json:"max_parallel"`   // 同时未结束的 Run 上限
+	SkipSucceeded bool                  `json:"skip_succeeded"` // 跳过该触发点已有成功 Run 的时间
+	Status        consts.BackfillStatus `json:"status"`         // RUNNING/COMPLETED/CANCELED
+	LastFireTime  *time.Time            `json:"last_fire_time"` // 已处理到的触发点，下次从其后继续
+	Total         int                   `json:"total"`          // 区间内的触发点数
+	Dispatched    int                   `json:"dispatched"`     // 已创建的 Run 数
+	Skipped       int                   `json:"skipped"`        // 因已成功而跳过的触发点数
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+	FinishedAt    *time.Time            `json:"finished_at"`
+}
+
+func (Backfill) TableName() string { return "backfills" }
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+)
+
+// Calendar 营业日历（如交易所交易日历），任务通过名称引用。
+type Calendar struct {
+	ID          int64               `json:"id"`
+	Name        string              `json:"name"`        // 唯一名称（任务的 calendar 字段）
+	Description string              `json:"description"` // 说明
+	Kind        consts.CalendarKind `json:"kind"`        // HOLIDAYS / TRADING_DAYS，决定 Dates 的含义
+	Source      string              `json:"source"`      // FILE / API
+	Version     int                 `json:"version"`     // 每次修改 +1
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	Dates       []string            `json:"dates" gorm:"-"` // YYYY-MM-DD，升序
+}
+
+func (Calendar) TableName() string { return "calendars" }
+
+// CalendarDate 日历中的一个日期（休市日或交易日，取决于 Kind）。
+type CalendarDate struct {
+	CalendarID int64     `json:"calendar_id"`
+	Date       time.Time `json:"date" gorm:"type:date"`
+}
+
+func (CalendarDate) TableName() string { return "calendar_dates" }
+
+// BlackoutWindow 停摆窗口：[StartTime, EndTime) 内 cron 触发被跳过；TaskID 为空时作用于所有任务。
+type BlackoutWindow struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	TaskID    *int64    `json:"task_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (BlackoutWindow) TableName() string { return "blackout_windows" }
+
+// Covers 窗口是否作用于任务在 t 时刻的触发。
+func (b *BlackoutWindow) Covers(taskID int64, t time.Time) bool {
+	if b.TaskID != nil && *b.TaskID != taskID {
+		return false
+	}
+	return !t.Before(b.StartTime) && t.Before(b.EndTime)
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+)
+
+// AsyncCallback 一次回调请求的审计记录（含被拒绝与重复的回调）。
+type AsyncCallback struct {
+	ID          int64                  `json:"id"`
+	TaskRunID   int64                  `json:"task_run_id"`
+	ReceivedAt  time.Time              `json:"received_at"`
+	HeadersJSON string                 `json:"headers_json"` // 请求头（token 与认证头已打码）
+	Body        string                 `json:"body"`         // 原始请求体（token 已打码）
+	Status      consts.CallbackStatus  `json:"status"`
+	SourceIP    string                 `json:"source_ip"`
+	Result      string                 `json:"result"` // 上报的 result：success / failed / failed_timeout
+	Outcome     consts.CallbackOutcome `json:"outcome"`
+}
+
+func (AsyncCallback) TableName() string { return "async_callbacks" }
+
+// NewCallbackToken 生成 128 位随机 token（32 位十六进制，对应 task_runs.callback_token CHAR(32)）。
+func NewCallbackToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// VerifyCallbackToken 常量时间比较回调携带的 token。
+// 升级前创建的 Run 没有 token，此时不校验。
+func (r *TaskRun) VerifyCallbackToken(token string) bool {
+	if r.CallbackToken == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(r.CallbackToken), []byte(token)) == 1
+}
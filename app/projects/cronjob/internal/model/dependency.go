@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// TaskDependency 任务依赖边：TaskID 依赖 UpstreamTaskID。
+type TaskDependency struct {
+	TaskID         int64     `json:"task_id"`
+	UpstreamTaskID int64     `json:"upstream_task_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (TaskDependency) TableName() string { return "task_dependencies" }
+
+// RunLineage Run 级血缘：RunID 由 UpstreamRunID 触发。
+type RunLineage struct {
+	RunID         int64 `json:"run_id"`
+	UpstreamRunID int64 `json:"upstream_run_id"`
+}
+
+func (RunLineage) TableName() string { return "run_lineage" }
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ExecutorSpec 执行器后端的专属参数（tasks.executor_config）。各字段仅对对应后端生效：
+//
+//	COMMAND:      {"args":["--date","{{ .LogicalDate | ymd }}"],"env":{"MODE":"full"},"dir":"/opt/scripts","timeout":"10m"}
+//	REDIS_STREAM: {"maxlen":10000}
+//
+// args 与 env 的值支持请求模板（见 render 包）。
+type ExecutorSpec struct {
+	Args    []string          `json:"args,omitempty"`    // COMMAND：命令参数，不经过 shell
+	Env     map[string]string `json:"env,omitempty"`     // COMMAND：追加的环境变量
+	Dir     string            `json:"dir,omitempty"`     // COMMAND：工作目录，默认 executor.command.dir
+	Timeout Duration          `json:"timeout,omitempty"` // COMMAND：单次执行超时，默认 executor.command.default_timeout
+	MaxLen  int64             `json:"maxlen,omitempty"`  // REDIS_STREAM：XADD MAXLEN ~ 近似上限，默认 executor.redis_stream.maxlen
+}
+
+// ParseExecutorSpec 解析后端参数；空串或 {} 返回零值。
+func ParseExecutorSpec(raw string) (*ExecutorSpec, error) {
+	s := &ExecutorSpec{}
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == "null" {
+		return s, nil
+	}
+	if err := json.Unmarshal([]byte(raw), s); err != nil {
+		return nil, fmt.Errorf("invalid executor_config: %w", err)
+	}
+	if s.Timeout < 0 || s.MaxLen < 0 {
+		return nil, fmt.Errorf("invalid executor_config: negative value")
+	}
+	for k := range s.Env {
+		if k == "" || strings.ContainsAny(k, "=\x00") {
+			return nil, fmt.Errorf("invalid executor_config: bad env name %q", k)
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// 标签：键最长 63 个字符，字母数字开头和结尾，中间可含 . _ - /；值为空或同样规则（不含 /）。
+var (
+	labelKeyRe   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+	labelValueRe = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?)?$`)
+	setTermRe    = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+)
+
+// MaxTaskLabels 单个任务最多的标签数。
+const MaxTaskLabels = 32
+
+// ValidateLabels 校验任务标签。
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > MaxTaskLabels {
+		return fmt.Errorf("at most %d labels", MaxTaskLabels)
+	}
+	for k, v := range labels {
+		if !labelKeyRe.MatchString(k) {
+			return fmt.Errorf("invalid label key %q", k)
+		}
+		if !labelValueRe.MatchString(v) {
+			return fmt.Errorf("invalid value %q for label %q", v, k)
+		}
+	}
+	return nil
+}
+
+// LabelOp 标签选择器的匹配方式。
+type LabelOp string
+
+const (
+	LabelEquals    LabelOp = "="
+	LabelNotEquals LabelOp = "!="
+	LabelIn        LabelOp = "in"
+	LabelNotIn     LabelOp = "notin"
+	LabelExists    LabelOp = "exists"
+	LabelNotExists LabelOp = "!exists"
+)
+
+// LabelRequirement 选择器中的一个条件。
+type LabelRequirement struct {
+	Key    string
+	Op     LabelOp
+	Values []string // = / != 为一个值，in / notin 为集合
+}
+
+// LabelSelector 标签选择器，各条件同时满足才匹配；空选择器匹配全部任务。
+type LabelSelector []LabelRequirement
+
+// ParseLabelSelector 解析逗号分隔的选择器：
+// team=quant、env!=prod、tier in (a,b)、tier notin (c)、gpu（存在该标签）、!legacy（不存在该标签）。
+func ParseLabelSelector(s string) (LabelSelector, error) {
+	var sel LabelSelector
+	for _, term := range splitSelector(s) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			if strings.TrimSpace(s) == "" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("empty term in selector %q", s)
+		}
+		req, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, req)
+	}
+	return sel, nil
+}
+
+// splitSelector 按括号外的逗号拆分。
+func splitSelector(s string) []string {
+	var terms []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, s[start:])
+}
+
+func parseRequirement(term string) (LabelRequirement, error) {
+	var req LabelRequirement
+	switch {
+	case setTermRe.MatchString(term):
+		m := setTermRe.FindStringSubmatch(term)
+		req.Key, req.Op = m[1], LabelOp(m[2])
+		for _, v := range strings.Split(m[3], ",") {
+			req.Values = append(req.Values, strings.TrimSpace(v))
+		}
+	case strings.HasPrefix(term, "!") && !strings.Contains(term, "="):
+		req.Key, req.Op = strings.TrimSpace(term[1:]), LabelNotExists
+	case strings.Contains(term, "!="):
+		k, v, _ := strings.Cut(term, "!=")
+		req.Key, req.Op, req.Values = strings.TrimSpace(k), LabelNotEquals, []string{strings.TrimSpace(v)}
+	case strings.Contains(term, "="):
+		k, v, _ := strings.Cut(term, "=")
+		req.Key, req.Op, req.Values = strings.TrimSpace(k), LabelEquals, []string{strings.TrimSpace(strings.TrimPrefix(v, "="))}
+	default:
+		req.Key, req.Op = term, LabelExists
+	}
+	if !labelKeyRe.MatchString(req.Key) {
+		return req, fmt.Errorf("invalid label key in selector term %q", term)
+	}
+	for _, v := range req.Values {
+		if !labelValueRe.MatchString(v) {
+			return req, fmt.Errorf("invalid label value in selector term %q", term)
+		}
+	}
+	return req, nil
+}
+
+// Matches 标签是否满足选择器。
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, req := range s {
+		v, ok := labels[req.Key]
+		var match bool
+		switch req.Op {
+		case LabelEquals:
+			match = ok && v == req.Values[0]
+		case LabelNotEquals:
+			match = !ok || v != req.Values[0]
+		case LabelIn:
+			match = ok && slices.Contains(req.Values, v)
+		case LabelNotIn:
+			match = !ok || !slices.Contains(req.Values, v)
+		case LabelExists:
+			match = ok
+		case LabelNotExists:
+			match = !ok
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
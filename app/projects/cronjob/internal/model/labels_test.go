@@ -0,0 +1,49 @@
+package model
+
+import "testing"
+
+func TestParseLabelSelector(t *testing.T) {
+	sel, err := ParseLabelSelector("team=quant, env!=prod,tier in (gold, silver),region notin (us),gpu,!legacy,owner==data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sel) != 7 || sel[2].Op != LabelIn || len(sel[2].Values) != 2 || sel[6].Op != LabelEquals || sel[6].Values[0] != "data" {
+		t.Fatalf("unexpected selector: %+v", sel)
+	}
+	cases := []struct {
+		labels map[string]string
+		want   bool
+	}{
+		{map[string]string{"team": "quant", "tier": "gold", "gpu": "", "owner": "data"}, true},
+		{map[string]string{"team": "quant", "tier": "gold", "gpu": "", "owner": "data", "env": "prod"}, false},
+		{map[string]string{"team": "quant", "tier": "bronze", "gpu": "", "owner": "data"}, false},
+		{map[string]string{"team": "quant", "tier": "gold", "gpu": "", "owner": "data", "region": "us"}, false},
+		{map[string]string{"team": "quant", "tier": "gold", "owner": "data"}, false},
+		{map[string]string{"team": "quant", "tier": "gold", "gpu": "", "owner": "data", "legacy": "1"}, false},
+		{nil, false},
+	}
+	for i, c := range cases {
+		if got := sel.Matches(c.labels); got != c.want {
+			t.Fatalf("case %d: got %v, want %v", i, got, c.want)
+		}
+	}
+	if sel, err := ParseLabelSelector("  "); err != nil || sel != nil || !sel.Matches(nil) {
+		t.Fatalf("empty selector: %v %v", sel, err)
+	}
+	for _, bad := range []string{"team=quant,", "=x", "team=a b", "tier in (a,", "-x"} {
+		if _, err := ParseLabelSelector(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	if err := ValidateLabels(map[string]string{"team": "quant", "app.kubernetes.io/name": "etl", "gpu": ""}); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []map[string]string{{"": "x"}, {"team": "a/b"}, {"-team": "x"}, {"team": "quant!"}} {
+		if err := ValidateLabels(bad); err == nil {
+			t.Fatalf("expected error for %v", bad)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// SchedulerLock 调度器租约（scheduler_locks 表），用于多副本选主。
+type SchedulerLock struct {
+	LockName   string     `json:"lock_name" gorm:"primaryKey"` // 锁名称
+	OwnerID    string     `json:"owner_id"`                    // 当前持有者（副本标识）
+	Term       int64      `json:"term"`                        // 任期，持有者变化时 +1，可用作 fencing token
+	AcquiredAt *time.Time `json:"acquired_at"`                 // 当前持有者取得租约的时间（UTC）
+	ExpiresAt  time.Time  `json:"expires_at"`                  // 租约到期时间（UTC，数据库时钟）
+	UpdatedAt  time.Time  `json:"updated_at"`                  // 最近续约时间
+}
+
+func (SchedulerLock) TableName() string { return "scheduler_locks" }
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Namespace 任务的归属空间（团队 / 项目）：限定任务数、并发 Run 数与可调用的 target_service，
+// 并由 owners 管理其中的任务。
+type Namespace struct {
+	Name                  string    `json:"name" gorm:"primaryKey"`
+	Description           string    `json:"description"`
+	Owners                []string  `json:"owners" gorm:"serializer:json"`                  // 可管理空间内全部任务的操作人
+	MaxTasks              int       `json:"max_tasks"`                                      // 未删除任务数上限，0 不限制
+	MaxConcurrentRuns     int       `json:"max_concurrent_runs"`                            // 同时执行（RUNNING / CALLBACK_PENDING）的 Run 数上限，0 不限制
+	AllowedTargetServices []string  `json:"allowed_target_services" gorm:"serializer:json"` // 允许调用的 target_service，为空不限制
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+func (Namespace) TableName() string { return "namespaces" }
+
+// AllowsTargetService 空间是否允许调用该服务；未指定 target_service（如 COMMAND）的任务不受限制。
+func (n *Namespace) AllowsTargetService(service string) bool {
+	return service == "" || len(n.AllowedTargetServices) == 0 || slices.Contains(n.AllowedTargetServices, service)
+}
+
+// IsOwner actor 是否为空间的 owner。
+func (n *Namespace) IsOwner(actor string) bool {
+	return slices.Contains(n.Owners, actor)
+}
+
+// NamespaceUsage 空间当前的占用。
+type NamespaceUsage struct {
+	Tasks      int64 `json:"tasks"`       // 未删除的任务数
+	ActiveRuns int64 `json:"active_runs"` // RUNNING / CALLBACK_PENDING 的 Run 数
+}
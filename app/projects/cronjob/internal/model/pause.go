@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// SchedulerPause 调度器全局暂停状态（scheduler_pause 表，仅一行）。
+// 暂停期间不产生新的触发，已创建的 Run 照常执行；设置了 PausedUntil 时到期自动恢复。
+type SchedulerPause struct {
+	ID          int        `json:"-" gorm:"primaryKey"`  // 恒为 1
+	Paused      bool       `json:"paused"`               // 是否处于暂停
+	PausedUntil *time.Time `json:"paused_until"`         // 自动恢复时间（UTC），空表示需手动恢复
+	Reason      string     `json:"reason"`               // 暂停原因（如上游维护窗口）
+	PausedBy    string     `json:"paused_by"`            // 最近一次暂停的操作人
+	PausedAt    *time.Time `json:"paused_at"`            // 最近一次暂停的时间
+	ResumedBy   string     `json:"resumed_by,omitempty"` // 最近一次恢复的操作人，到期自动恢复为 auto
+	ResumedAt   *time.Time `json:"resumed_at,omitempty"` // 最近一次恢复的时间
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (SchedulerPause) TableName() string { return "scheduler_pause" }
+
+// Active 在 now 时刻是否仍处于暂停（已过 PausedUntil 视为已恢复，即使尚未落库）。
+func (p *SchedulerPause) Active(now time.Time) bool {
+	return p != nil && p.Paused && (p.PausedUntil == nil || now.Before(*p.PausedUntil))
+}
@@ -0,0 +1,198 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy 任务级重试策略（tasks.retry_policy_json）。
+// 空对象 {} 或 max_retries<=0 表示不重试。失败的 Run 会被设置 next_retry_time，
+// 到期后由扫描器派发一条新的 Run（retry_of 指向链路中的首个 Run，attempt +1）。
+//
+// 示例：
+//
+//	{"max_retries":3,"initial_backoff":"10s","max_backoff":"5m","multiplier":2,"jitter":0.2,
+//	 "retry_on":{"http_status":["5xx","429"],"timeout":true,"network":true,"biz_status":["RETRY"]},
+//	 "count_concurrency":true}
+type RetryPolicy struct {
+	MaxRetries       int      `json:"max_retries"`                 // 最多重试次数（不含首次执行）
+	InitialBackoff   Duration `json:"initial_backoff"`             // 首次重试等待，默认 10s
+	MaxBackoff       Duration `json:"max_backoff"`                 // 等待上限，默认 10m
+	Multiplier       float64  `json:"multiplier"`                  // 每次重试等待倍数，默认 2
+	Jitter           float64  `json:"jitter"`                      // 0~1，等待时间随机缩短的最大比例，避免同时重试
+	RetryOn          *RetryOn `json:"retry_on,omitempty"`          // 哪些失败可重试；缺省为 5xx/429/超时/网络错误
+	CountConcurrency *bool    `json:"count_concurrency,omitempty"` // 重试是否受 max_concurrency 约束，默认 true
+}
+
+// RetryOn 可重试的失败类型。
+type RetryOn struct {
+	HTTPStatus []string `json:"http_status"` // 状态码或状态类：500、429、5xx、4xx
+	Timeout    bool     `json:"timeout"`     // 请求超时 / 异步回调超时
+	Network    bool     `json:"network"`     // 连接拒绝、不可达等传输层错误
+	BizStatus  []string `json:"biz_status"`  // 同步响应体 status 字段取值（大小写不敏感），命中即视为失败并重试
+	ExitCodes  []int    `json:"exit_codes"`  // COMMAND 后端的非零退出码
+}
+
+// RetryOutcome 一次失败的分类，用于匹配 RetryOn。
+type RetryOutcome struct {
+	Kind       string // 见 Outcome* 常量
+	HTTPStatus int    // Kind=http 时的状态码
+	BizStatus  string // Kind=biz 时响应体的 status
+	ExitCode   int    // Kind=exit 时进程的退出码
+}
+
+const (
+	OutcomeHTTP    = "http"
+	OutcomeTimeout = "timeout"
+	OutcomeNetwork = "network"
+	OutcomeBiz     = "biz"
+	OutcomeExit    = "exit"
+)
+
+var defaultRetryOn = RetryOn{HTTPStatus: []string{"5xx", "429"}, Timeout: true, Network: true}
+
+// ParseRetryPolicy 解析并校验重试策略，补齐默认值；空串或 {} 返回不重试的策略。
+func ParseRetryPolicy(raw string) (*RetryPolicy, error) {
+	p := &RetryPolicy{}
+	raw = strings.TrimSpace(raw)
+	if raw != "" && raw != "null" {
+		if err := json.Unmarshal([]byte(raw), p); err != nil {
+			return nil, fmt.Errorf("invalid retry_policy_json: %w", err)
+		}
+	}
+	if p.MaxRetries < 0 || p.InitialBackoff < 0 || p.MaxBackoff < 0 || p.Multiplier < 0 {
+		return nil, fmt.Errorf("invalid retry_policy_json: negative value")
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return nil, fmt.Errorf("invalid retry_policy_json: jitter must be within [0,1]")
+	}
+	if p.InitialBackoff == 0 {
+		p.InitialBackoff = Duration(10 * time.Second)
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = Duration(10 * time.Minute)
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		p.MaxBackoff = p.InitialBackoff
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = 2
+	}
+	if p.Multiplier < 1 {
+		return nil, fmt.Errorf("invalid retry_policy_json: multiplier must be >= 1")
+	}
+	if p.RetryOn == nil {
+		on := defaultRetryOn
+		p.RetryOn = &on
+	}
+	for _, s := range p.RetryOn.HTTPStatus {
+		if _, _, err := parseStatusMatcher(s); err != nil {
+			return nil, fmt.Errorf("invalid retry_policy_json: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// Enabled 是否配置了重试。
+func (p *RetryPolicy) Enabled() bool { return p != nil && p.MaxRetries > 0 }
+
+// CountsConcurrency 重试是否占用 max_concurrency 名额。
+func (p *RetryPolicy) CountsConcurrency() bool {
+	return p.CountConcurrency == nil || *p.CountConcurrency
+}
+
+// Backoff 第 n 次重试（从 1 开始）前的等待时间；rnd 取 [0,1) 随机数，用于抖动。
+func (p *RetryPolicy) Backoff(n int, rnd float64) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(n-1))
+	if d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	d -= d * p.Jitter * rnd
+	return time.Duration(d).Truncate(time.Millisecond)
+}
+
+// ShouldRetry 失败类型是否命中 retry_on。
+func (p *RetryPolicy) ShouldRetry(o RetryOutcome) bool {
+	on := p.RetryOn
+	switch o.Kind {
+	case OutcomeTimeout:
+		return on.Timeout
+	case OutcomeNetwork:
+		return on.Network
+	case OutcomeHTTP:
+		for _, s := range on.HTTPStatus {
+			if lo, hi, err := parseStatusMatcher(s); err == nil && o.HTTPStatus >= lo && o.HTTPStatus <= hi {
+				return true
+			}
+		}
+	case OutcomeBiz:
+		return p.IsRetryableBizStatus(o.BizStatus)
+	case OutcomeExit:
+		for _, c := range on.ExitCodes {
+			if c == o.ExitCode {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsRetryableBizStatus 响应体 status 是否在 biz_status 列表中。
+func (p *RetryPolicy) IsRetryableBizStatus(status string) bool {
+	if p == nil || p.RetryOn == nil || status == "" {
+		return false
+	}
+	for _, s := range p.RetryOn.BizStatus {
+		if strings.EqualFold(strings.TrimSpace(s), status) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStatusMatcher 解析 "503" 或 "5xx"，返回闭区间。
+func parseStatusMatcher(s string) (int, int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) == 3 && strings.HasSuffix(s, "xx") && s[0] >= '1' && s[0] <= '5' {
+		base := int(s[0]-'0') * 100
+		return base, base + 99, nil
+	}
+	code, err := strconv.Atoi(s)
+	if err != nil || code < 100 || code > 599 {
+		return 0, 0, fmt.Errorf("invalid http_status %q", s)
+	}
+	return code, code, nil
+}
+
+// Duration 支持 JSON 中以 "10s" 形式或数字秒表示的时长。
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch x := v.(type) {
+	case float64:
+		*d = Duration(time.Duration(x * float64(time.Second)))
+	case string:
+		dd, err := time.ParseDuration(x)
+		if err != nil {
+			return err
+		}
+		*d = Duration(dd)
+	case nil:
+		*d = 0
+	default:
+		return fmt.Errorf("invalid duration %s", string(b))
+	}
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRetryPolicy(t *testing.T) {
+	p, err := ParseRetryPolicy("{}")
+	if err != nil || p.Enabled() {
+		t.Fatalf("empty policy should parse and be disabled: %v", err)
+	}
+	p, err = ParseRetryPolicy(`{"max_retries":3,"initial_backoff":"10s","max_backoff":60,"multiplier":3}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Duration{10 * time.Second, 30 * time.Second, time.Minute, time.Minute}
+	for i, w := range want {
+		if got := p.Backoff(i+1, 0); got != w {
+			t.Errorf("backoff(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+	if !p.CountsConcurrency() {
+		t.Error("count_concurrency should default to true")
+	}
+	for _, bad := range []string{`{"jitter":2}`, `{"multiplier":0.5}`, `{"retry_on":{"http_status":["6xx"]}}`, `{"initial_backoff":"soon"}`, `[`} {
+		if _, err := ParseRetryPolicy(bad); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+}
+
+func TestRetryPolicyJitter(t *testing.T) {
+	p, _ := ParseRetryPolicy(`{"max_retries":1,"initial_backoff":"10s","jitter":0.5}`)
+	if got := p.Backoff(1, 0.999); got < 5*time.Second || got > 10*time.Second {
+		t.Fatalf("jittered backoff out of range: %s", got)
+	}
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	def, _ := ParseRetryPolicy(`{"max_retries":2}`)
+	custom, _ := ParseRetryPolicy(`{"max_retries":2,"retry_on":{"http_status":["503","4xx"],"biz_status":["retry"],"exit_codes":[75]}}`)
+	cases := []struct {
+		p    *RetryPolicy
+		o    RetryOutcome
+		want bool
+	}{
+		{def, RetryOutcome{Kind: OutcomeHTTP, HTTPStatus: 502}, true},
+		{def, RetryOutcome{Kind: OutcomeHTTP, HTTPStatus: 429}, true},
+		{def, RetryOutcome{Kind: OutcomeHTTP, HTTPStatus: 400}, false},
+		{def, RetryOutcome{Kind: OutcomeTimeout}, true},
+		{def, RetryOutcome{Kind: OutcomeNetwork}, true},
+		{def, RetryOutcome{Kind: OutcomeBiz, BizStatus: "FAILED"}, false},
+		{custom, RetryOutcome{Kind: OutcomeHTTP, HTTPStatus: 502}, false},
+		{custom, RetryOutcome{Kind: OutcomeHTTP, HTTPStatus: 404}, true},
+		{custom, RetryOutcome{Kind: OutcomeTimeout}, false},
+		{custom, RetryOutcome{Kind: OutcomeBiz, BizStatus: "RETRY"}, true},
+		{def, RetryOutcome{Kind: OutcomeExit, ExitCode: 1}, false},
+		{custom, RetryOutcome{Kind: OutcomeExit, ExitCode: 75}, true},
+	}
+	for i, c := range cases {
+		if got := c.p.ShouldRetry(c.o); got != c.want {
+			t.Errorf("case %d %+v: got %v want %v", i, c.o, got, c.want)
+		}
+	}
+}
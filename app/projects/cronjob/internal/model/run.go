@@ -9,29 +9,50 @@ import (
 // TaskRun 代表一次定时任务的实际运行实例。
 // 字段详细说明如下：
 type TaskRun struct {
-	ID                 int64            `json:"id"`                            // 主键 ID，唯一标识一次运行
-	TaskID             int64            `json:"task_id"`                       // 关联的 Task ID，指向所属的定时任务
-	ScheduledTime      time.Time        `json:"scheduled_time"`                // 计划执行时间（UTC），由调度器分配
-	StartTime          *time.Time       `json:"start_time"`                    // 实际开始时间，任务开始时记录
-	EndTime            *time.Time       `json:"end_time"`                      // 实际结束时间，任务完成时记录
-	Status             consts.RunStatus `json:"status"`                        // 运行状态，见 RunStatus 枚举
-	Attempt            int              `json:"attempt"`                       // 当前尝试次数（含重试）
-	TargetService      string           `json:"target_service"`                // 目标服务标识 (e.g. "artemis")
-	TargetPath         string           `json:"target_path"`                   // 目标路径 (e.g. "/api/v1/trigger")
-	Method             string           `json:"method"`                        // HTTP 方法 (GET/POST)
-	ExecType           consts.ExecType  `json:"exec_type"`                     // 执行类型：SYNC/ASYNC
-	CallbackTimeoutSec int              `json:"callback_timeout_sec" gorm:"-"` // 异步回调超时时间(秒) - 从Task快照，不入库
-	RequestHeaders     string           `json:"request_headers"`               // 发送 HTTP 请求时的请求头（JSON 字符串）
-	RequestBody        string           `json:"request_body"`                  // 发送 HTTP 请求时的请求体内容
-	ResponseCode       *int             `json:"response_code"`                 // HTTP 响应码（如有）
-	ResponseBody       string           `json:"response_body"`                 // HTTP 响应体内容（如有）
-	ErrorMessage       string           `json:"error_message"`                 // 错误信息（如有）
-	NextRetryTime      *time.Time       `json:"next_retry_time"`               // 下次重试时间（如有重试计划）
-	CallbackToken      string           `json:"callback_token"`                // 回调 token，用于异步任务回调识别
-	CallbackDeadline   *time.Time       `json:"callback_deadline"`             // 回调超时时间（异步任务专用）
-	TraceID            string           `json:"trace_id"`                      // 链路追踪 ID（如有）
-	CreatedAt          time.Time        `json:"created_at"`                    // 创建时间
-	UpdatedAt          time.Time        `json:"updated_at"`                    // 最近更新时间
+	ID                  int64               `json:"id"`                            // 主键 ID，唯一标识一次运行
+	TaskID              int64               `json:"task_id"`                       // 关联的 Task ID，指向所属的定时任务
+	TaskVersion         int                 `json:"task_version"`                  // 创建时任务的版本，对应 task_revisions.version；升级前的 Run 为 0
+	Namespace           string              `json:"namespace"`                     // 创建时任务所属的空间，空间的并发 Run 上限按此统计
+	ScheduledTime       time.Time           `json:"scheduled_time"`                // 计划执行时间（UTC），由调度器分配
+	StartTime           *time.Time          `json:"start_time"`                    // 实际开始时间，任务开始时记录
+	EndTime             *time.Time          `json:"end_time"`                      // 实际结束时间，任务完成时记录
+	Status              consts.RunStatus    `json:"status"`                        // 运行状态，见 RunStatus 枚举
+	Attempt             int                 `json:"attempt"`                       // 当前尝试次数（含重试）
+	TargetService       string              `json:"target_service"`                // 目标服务标识 (e.g. "artemis")
+	TargetPath          string              `json:"target_path"`                   // 目标路径 (e.g. "/api/v1/trigger")
+	Method              string              `json:"method"`                        // HTTP 方法 (GET/POST)
+	ExecType            consts.ExecType     `json:"exec_type"`                     // 执行类型：SYNC/ASYNC
+	Executor            consts.ExecutorKind `json:"executor"`                      // 执行器后端（从 Task 快照）
+	ExecutorConfig      string              `json:"executor_config"`               // 后端专属参数 JSON（从 Task 快照）
+	CallbackTimeoutSec  int                 `json:"callback_timeout_sec" gorm:"-"` // 异步回调超时时间(秒) - 从Task快照，不入库
+	ExecutionTimeoutSec int                 `json:"execution_timeout_sec"`         // 执行超时(秒) - 从Task快照；0 不限制
+	QuotaDeferred       bool                `json:"-" gorm:"-"`                    // 执行器因空间并发配额延后过（仅首次记录日志），不入库
+	RequestHeaders      string              `json:"request_headers"`               // 发送 HTTP 请求时的请求头（JSON 字符串）
+	RequestBody         string              `json:"request_body"`                  // 发送 HTTP 请求时的请求体内容
+	ResponseCode        *int                `json:"response_code"`                 // HTTP 响应码（如有）
+	ResponseBody        string              `json:"response_body"`                 // HTTP 响应体内容（如有）
+	ErrorMessage        string              `json:"error_message"`                 // 错误信息（如有）
+	Stderr              string              `json:"stderr"`                        // COMMAND 后端的 stderr（stdout 记入 response_body）
+	NextRetryTime       *time.Time          `json:"next_retry_time"`               // 下次重试时间（按 retry_policy 计划，派发后清空）
+	RetryOf             *int64              `json:"retry_of"`                      // 重试链路中首个 Run 的 ID；非重试 Run 为空
+	RetryIndex          int                 `json:"retry_index"`                   // 第几次重试（首次执行为 0）
+	LogicalDate         *time.Time          `json:"logical_date" gorm:"type:date"` // 业务日期（UTC 零点表示）；依赖触发的下游沿用上游的日期
+	TriggerType         consts.TriggerType  `json:"trigger_type"`                  // 触发来源：CRON/MANUAL/DEPENDENCY/RERUN
+	DagGeneration       int                 `json:"dag_generation"`                // 同一业务日期的重跑轮次，下游按轮次去重
+	DagResolved         bool                `json:"-"`                             // 结束后是否已由依赖解析器评估下游
+	AlertEvaluated      bool                `json:"-"`                             // 结束后是否已由告警规则评估
+	BackfillID          *int64              `json:"backfill_id"`                   // 所属补跑批次；非补跑 Run 为空
+	CallbackToken       string              `json:"-"`                             // 回调 token：随 meta 下发，回调时校验；不对外输出
+	ProgressCurrent     int64               `json:"progress_current"`              // 最近一次上报的进度
+	ProgressTotal       int64               `json:"progress_total"`
+	ProgressMessage     string              `json:"progress_message"`
+	ProgressUpdatedAt   *time.Time          `json:"progress_updated_at"` // 为空表示从未上报进度
+	CallbackDeadline    *time.Time          `json:"callback_deadline"`   // 回调超时时间（异步任务专用）
+	CancelReason        consts.CancelReason `json:"cancel_reason"`       // 取消 / 执行超时的原因；未取消为空
+	CancelRequestedAt   *time.Time          `json:"cancel_requested_at"` // 发起取消的时间；宽限期内未结束则标记 KILLED
+	TraceID             string              `json:"trace_id"`            // 链路追踪 ID（如有）
+	CreatedAt           time.Time           `json:"created_at"`          // 创建时间
+	UpdatedAt           time.Time           `json:"updated_at"`          // 最近更新时间
 }
 
 func (TaskRun) TableName() string { return "task_runs" }
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// RunLog 执行方上报的一行 Run 日志（只追加）。
+type RunLog struct {
+	ID        int64     `json:"id"`
+	TaskRunID int64     `json:"run_id"`
+	Ts        time.Time `json:"ts"`
+	Level     string    `json:"level"` // DEBUG / INFO / WARN / ERROR
+	Line      string    `json:"line"`
+}
+
+func (RunLog) TableName() string { return "run_logs" }
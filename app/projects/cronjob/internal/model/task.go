@@ -9,29 +9,46 @@ import (
 
 // Task 描述一个可调度的定时任务配置。
 type Task struct {
-	ID                 int64                    `json:"id"`                   // 主键 ID
-	Name               string                   `json:"name"`                 // 任务唯一名称（业务可读标识）
-	Description        string                   `json:"description"`          // 任务说明文字
-	CronExpr           string                   `json:"cron_expr"`            // 规范化后的 6 字段 Cron 表达式（秒 分 时 日 月 周）
-	Timezone           string                   `json:"timezone"`             // 时区（未来用于按配置时区解析 Cron；当前默认 UTC）
-	ExecType           consts.ExecType          `json:"exec_type"`            // 执行类型：SYNC/ASYNC（异步回调暂未实现）
-	HTTPMethod         string                   `json:"method"`               // 修改 JSON tag 为 `method`
-	TargetService      string                   `json:"target_service"`       // 下游服务标识 (default "artemis")
-	TargetPath         string                   `json:"target_path"`          // 下游请求路径
-	HeadersJSON        string                   `json:"headers_json"`         // 以 JSON 字符串格式存储的额外请求头
-	BodyTemplate       string                   `json:"body_template"`        // 请求体模板
-	RetryPolicyJSON    string                   `json:"retry_policy_json"`    // 重试策略 JSON（占位，未来使用）
-	MaxConcurrency     int                      `json:"max_concurrency"`      // 单任务允许运行的最大并发数（<=0 视为不限制）
-	ConcurrencyPolicy  consts.ConcurrencyPolicy `json:"concurrency_policy"`   // 并发策略：QUEUE/SKIP/PARALLEL
-	CallbackMethod     string                   `json:"callback_method"`      // 异步任务回调使用的 HTTP 方法（预留）
-	CallbackTimeoutSec int                      `json:"callback_timeout_sec"` // 异步回调等待超时时间
-	OverlapAction      consts.OverlapAction     `json:"overlap_action"`       // 上一轮仍未完成时的处理策略
-	FailureAction      consts.FailureAction     `json:"failure_action"`       // 上一轮失败/超时/取消后的处理策略
-	Status             consts.TaskStatus        `json:"status"`               // 任务状态：ENABLED / DISABLED
-	Version            int                      `json:"version"`              // 乐观锁版本（更新时 +1）
-	CreatedAt          time.Time                `json:"created_at"`           // 创建时间
-	UpdatedAt          time.Time                `json:"updated_at"`           // 最近更新时间
-	Deleted            int                      `json:"deleted"`              // 软删除标志位：0 未删除，1 已删除
+	ID                  int64                    `json:"id"`                            // 主键 ID
+	Name                string                   `json:"name"`                          // 任务唯一名称（业务可读标识）
+	Description         string                   `json:"description"`                   // 任务说明文字
+	Namespace           string                   `json:"namespace"`                     // 所属空间（团队 / 项目），默认 default
+	Owners              []string                 `json:"owners" gorm:"serializer:json"` // 可修改该任务的操作人（X-Cronjob-User）；为空时由空间 owners 管理
+	Labels              map[string]string        `json:"labels" gorm:"serializer:json"` // 标签，可按标签选择器筛选、导出与批量启停
+	CronExpr            string                   `json:"cron_expr"`                     // 规范化后的 6 字段 Cron 表达式（秒 分 时 日 月 周）或描述符（@daily / @every 5m）
+	Timezone            string                   `json:"timezone"`                      // IANA 时区（如 Asia/Shanghai），Cron 按该时区的挂钟时间解释；默认 UTC
+	ExecType            consts.ExecType          `json:"exec_type"`                     // 执行类型：SYNC/ASYNC（异步回调暂未实现）
+	Executor            consts.ExecutorKind      `json:"executor"`                      // 执行器后端：HTTP/GRPC/COMMAND/REDIS_STREAM
+	ExecutorConfig      string                   `json:"executor_config"`               // 后端专属参数 JSON，见 ExecutorSpec；{} 表示无
+	HTTPMethod          string                   `json:"method"`                        // 修改 JSON tag 为 `method`
+	TargetService       string                   `json:"target_service"`                // 下游服务标识 (default "artemis")
+	TargetPath          string                   `json:"target_path"`                   // 下游请求路径
+	HeadersJSON         string                   `json:"headers_json"`                  // 以 JSON 字符串格式存储的额外请求头
+	BodyTemplate        string                   `json:"body_template"`                 // 请求体模板
+	RetryPolicyJSON     string                   `json:"retry_policy_json"`             // 重试策略 JSON，见 RetryPolicy；{} 表示不重试
+	MaxConcurrency      int                      `json:"max_concurrency"`               // 单任务允许运行的最大并发数（<=0 视为不限制）
+	ConcurrencyPolicy   consts.ConcurrencyPolicy `json:"concurrency_policy"`            // 并发策略：QUEUE/SKIP/PARALLEL
+	QueueMaxDepth       int                      `json:"queue_max_depth"`               // QUEUE 策略下最多排队的 Run 数（<=0 取默认 100）
+	QueueOverflow       consts.QueueOverflow     `json:"queue_overflow"`                // 队列满时的处理：DROP_NEW/DROP_OLDEST
+	CallbackMethod      string                   `json:"callback_method"`               // 异步任务回调使用的 HTTP 方法（预留）
+	CallbackTimeoutSec  int                      `json:"callback_timeout_sec"`          // 异步回调等待超时时间
+	ExecutionTimeoutSec int                      `json:"execution_timeout_sec"`         // 单次执行超时：从开始执行计，超时即取消（ASYNC 含等待回调）；0 不限制
+	OverlapAction       consts.OverlapAction     `json:"overlap_action"`                // 上一轮仍未完成时的处理策略
+	FailureAction       consts.FailureAction     `json:"failure_action"`                // 上一轮失败/超时/取消后的处理策略
+	MisfirePolicy       consts.MisfirePolicy     `json:"misfire_policy"`                // 错过触发的补偿策略：SKIP/FIRE_ONCE/FIRE_ALL
+	MisfireGraceSec     int                      `json:"misfire_grace_sec"`             // 触发延迟在该秒数内不算错过（<=0 取 poll_interval）
+	LastEvaluatedAt     *time.Time               `json:"last_evaluated_at"`             // 调度器已评估到的时间点（UTC），重启后从此处补偿
+	ScheduleMode        consts.ScheduleMode      `json:"schedule_mode"`                 // 触发来源：CRON/DEPENDENCY/BOTH
+	TriggerRule         consts.TriggerRule       `json:"trigger_rule"`                  // 依赖触发规则：ALL_SUCCESS/ALL_DONE/ONE_SUCCESS
+	Calendar            string                   `json:"calendar"`                      // 营业日历名称，空表示不使用日历
+	CalendarMode        consts.CalendarMode      `json:"calendar_mode"`                 // 日历用法：BUSINESS_DAYS/NON_BUSINESS_DAYS/NEXT_BUSINESS_DAY
+	Status              consts.TaskStatus        `json:"status"`                        // 任务状态：ENABLED / DISABLED
+	Managed             bool                     `json:"managed"`                       // 由 YAML 声明同步管理（只读，修改须经 sync）
+	SyncSource          string                   `json:"sync_source"`                   // 声明该任务的文件（相对 task_sync.dir），非托管任务为空
+	Version             int                      `json:"version"`                       // 乐观锁版本（更新时 +1）
+	CreatedAt           time.Time                `json:"created_at"`                    // 创建时间
+	UpdatedAt           time.Time                `json:"updated_at"`                    // 最近更新时间
+	Deleted             int                      `json:"deleted"`                       // 软删除标志位：0 未删除，1 已删除
 }
 
 // NormalizeCron 规范化 Cron 表达式：如果是 5 字段则自动补前导秒 0
@@ -47,3 +64,11 @@ func NormalizeCron(expr string) string {
 }
 
 func (Task) TableName() string { return "tasks" }
+
+// UsesCron 是否按 cron 表达式触发。
+func (t *Task) UsesCron() bool { return t.ScheduleMode != consts.ScheduleModeDependency }
+
+// UsesDependencies 是否在上游完成后触发。
+func (t *Task) UsesDependencies() bool {
+	return t.ScheduleMode == consts.ScheduleModeDependency || t.ScheduleMode == consts.ScheduleModeBoth
+}
@@ -18,4 +18,7 @@ type TaskListFilters struct {
 	CreatedTo       *time.Time
 	UpdatedFrom     *time.Time
 	UpdatedTo       *time.Time
+	Namespace       string        // 精确匹配
+	Owner           string        // owners 中包含该操作人
+	Selector        LabelSelector // 标签选择器，为空不过滤
 }
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+)
+
+// TaskRevision 任务定义的一个不可变版本：创建、修改、启停、删除与回滚各记录一条，只追加不修改。
+type TaskRevision struct {
+	ID        int64                     `json:"id"`
+	TaskID    int64                     `json:"task_id"`
+	Version   int                       `json:"version"` // 变更后的任务版本（tasks.version）
+	Action    consts.TaskRevisionAction `json:"action"`
+	Actor     string                    `json:"actor"`                           // 发起变更的主体，见 consts.ActorHeader
+	Note      string                    `json:"note"`                            // 附加说明（如回滚来源版本）
+	Snapshot  *Task                     `json:"snapshot" gorm:"serializer:json"` // 变更后的完整定义
+	Diff      []FieldChange             `json:"diff" gorm:"serializer:json"`     // 相对上一版本的字段变化
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+func (TaskRevision) TableName() string { return "task_revisions" }
+
+// FieldChange 一个字段的变化，Field 为任务 JSON 字段名。
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// revisionIgnoredFields 不属于任务定义、不参与 diff 的字段（JSON 字段名）。
+var revisionIgnoredFields = map[string]bool{
+	"id": true, "version": true, "created_at": true, "updated_at": true, "last_evaluated_at": true,
+}
+
+// DiffTasks 比较两个任务定义，按字段声明顺序返回变化；prev 为 nil 时列出 next 的全部非零字段。
+// ignore 为额外跳过的字段（JSON 字段名）。
+func DiffTasks(prev, next *Task, ignore ...string) []FieldChange {
+	changes := []FieldChange{}
+	nv := reflect.ValueOf(next).Elem()
+	var ov reflect.Value
+	if prev != nil {
+		ov = reflect.ValueOf(prev).Elem()
+	}
+	typ := nv.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || revisionIgnoredFields[name] || contains(ignore, name) {
+			continue
+		}
+		nf := nv.Field(i).Interface()
+		if !ov.IsValid() {
+			if f := nv.Field(i); !f.IsZero() && !isEmptyCollection(f) {
+				changes = append(changes, FieldChange{Field: name, New: nf})
+			}
+			continue
+		}
+		if of := ov.Field(i).Interface(); !reflect.DeepEqual(of, nf) {
+			changes = append(changes, FieldChange{Field: name, Old: of, New: nf})
+		}
+	}
+	return changes
+}
+
+// isEmptyCollection 空的 slice / map（如新建任务的 owners / labels）不计入新建时的 diff。
+func isEmptyCollection(v reflect.Value) bool {
+	return (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// File 把每条告警以一行 JSON 追加到本地文件，用于本地调试与测试。
+type File struct {
+	name string
+	path string
+	mu   sync.Mutex
+}
+
+func NewFile(name, path string) (*File, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file notifier %s: path required", name)
+	}
+	return &File{name: name, path: path}, nil
+}
+
+func (f *File) Name() string { return f.name }
+func (f *File) Type() string { return "file" }
+
+func (f *File) Send(_ context.Context, msg *Message) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if dir := filepath.Dir(f.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	fh, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := fh.Write(append(b, '\n')); err != nil {
+		_ = fh.Close()
+		return err
+	}
+	return fh.Close()
+}
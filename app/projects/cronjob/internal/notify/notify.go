@@ -0,0 +1,86 @@
+// Package notify 告警通知渠道：通用 webhook（模板化请求体）、SMTP 邮件与本地文件。
+//
+// 渠道只负责把一条 Message 投递出去；规则评估、去重与抑制由调用方负责。
+// 模板使用 text/template，`.` 为 Message，可用函数见 funcs。
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Message 一条告警通知。
+type Message struct {
+	Kind          string     `json:"kind"`     // 规则类型：FAILURE / CONSECUTIVE_FAILURES / SLOW_RUN / SLA_MISSED / RECOVERY / TEST
+	Severity      string     `json:"severity"` // CRITICAL / WARNING / INFO
+	RuleID        int64      `json:"rule_id"`
+	TaskID        int64      `json:"task_id"`
+	TaskName      string     `json:"task_name"`
+	RunID         int64      `json:"run_id,omitempty"`
+	RunStatus     string     `json:"run_status,omitempty"`
+	ScheduledTime *time.Time `json:"scheduled_time,omitempty"`
+	Title         string     `json:"title"`
+	Text          string     `json:"text"`
+	At            time.Time  `json:"at"`
+}
+
+// Notifier 一个通知渠道。
+type Notifier interface {
+	Name() string
+	Type() string
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Validate 校验模板语法与函数名。
+func Validate(field, text string) error {
+	if text == "" {
+		return nil
+	}
+	if _, err := template.New(field).Funcs(funcs()).Parse(text); err != nil {
+		return fmt.Errorf("invalid %s template: %w", field, err)
+	}
+	return nil
+}
+
+// execute 渲染模板；text 为空时返回 def。
+func execute(field, text, def string, msg *Message) (string, error) {
+	if text == "" {
+		return def, nil
+	}
+	t, err := template.New(field).Funcs(funcs()).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", field, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, msg); err != nil {
+		return "", fmt.Errorf("render %s: %w", field, err)
+	}
+	return buf.String(), nil
+}
+
+func funcs() template.FuncMap {
+	return template.FuncMap{
+		// json 输出 JSON 字面量（字符串带引号并转义），用于在 JSON 模板中安全嵌入任意值
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"rfc3339": func(t time.Time) string { return t.Format(time.RFC3339) },
+		"format":  func(layout string, t time.Time) string { return t.Format(layout) },
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+	}
+}
+
+// truncate 截断过长的响应体，用于错误信息。
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,200 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testMessage() *Message {
+	return &Message{Kind: "FAILURE", Severity: "CRITICAL", RuleID: 3, TaskID: 7, TaskName: `daily "bars"`, RunID: 42,
+		RunStatus: "FAILED", Title: "任务 daily_bars 失败", Text: "run 42 FAILED\nerror: boom", At: time.Date(2026, 10, 8, 9, 30, 0, 0, time.UTC)}
+}
+
+func TestWebhookTemplatedBody(t *testing.T) {
+	var gotBody, gotAuth, gotCT string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody, gotAuth, gotCT = string(b), r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	wh, err := NewWebhook("ops", WebhookOptions{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer x"},
+		BodyTemplate: `{"msgtype":"text","text":{"content":{{ json .Title }}},"run":{{ .RunID }},"at":"{{ rfc3339 .At }}"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wh.Send(context.Background(), testMessage()); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	want := `{"msgtype":"text","text":{"content":"任务 daily_bars 失败"},"run":42,"at":"2026-10-08T09:30:00Z"}`
+	if gotBody != want || gotAuth != "Bearer x" || gotCT != "application/json" {
+		t.Fatalf("unexpected request body=%s auth=%q ct=%q", gotBody, gotAuth, gotCT)
+	}
+
+	// 未配置模板时发送 Message 的 JSON；非 2xx 视为失败
+	wh, _ = NewWebhook("raw", WebhookOptions{URL: srv.URL})
+	if err := wh.Send(context.Background(), testMessage()); err != nil {
+		t.Fatal(err)
+	}
+	var m Message
+	if err := json.Unmarshal([]byte(gotBody), &m); err != nil || m.RunID != 42 || m.TaskName != `daily "bars"` {
+		t.Fatalf("default body should be the message json: %s (%v)", gotBody, err)
+	}
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer bad.Close()
+	wh, _ = NewWebhook("bad", WebhookOptions{URL: bad.URL})
+	if err := wh.Send(context.Background(), testMessage()); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+	if _, err := NewWebhook("x", WebhookOptions{URL: srv.URL, BodyTemplate: "{{ nope }}"}); err == nil {
+		t.Fatal("unknown template function should be rejected")
+	}
+}
+
+// fakeSMTP 进程内的最小 SMTP 服务器：接受一封邮件后记录信封与内容。
+type fakeSMTP struct {
+	ln   net.Listener
+	from string
+	rcpt []string
+	data string
+	done chan struct{}
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeSMTP{ln: ln, done: make(chan struct{})}
+	go f.serve()
+	t.Cleanup(func() { _ = ln.Close() })
+	return f
+}
+
+func (f *fakeSMTP) port() int { return f.ln.Addr().(*net.TCPAddr).Port }
+
+func (f *fakeSMTP) serve() {
+	defer close(f.done)
+	conn, err := f.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		upper := strings.ToUpper(cmd)
+		switch {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 fake")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			f.from = strings.Trim(cmd[len("MAIL FROM:"):], "<> ")
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			f.rcpt = append(f.rcpt, strings.Trim(cmd[len("RCPT TO:"):], "<> "))
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 go ahead")
+			var sb strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				sb.WriteString(l)
+			}
+			f.data = sb.String()
+			reply("250 queued")
+		case upper == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPSendsMail(t *testing.T) {
+	srv := newFakeSMTP(t)
+	s, err := NewSMTP("mail", SMTPOptions{Host: "127.0.0.1", Port: srv.port(), From: "cronjob@example.com",
+		To: []string{"ops@example.com", "dev@example.com"}, SubjectTemplate: "[cronjob] {{ .Kind }} {{ .TaskName }}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(context.Background(), testMessage()); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	<-srv.done
+	if srv.from != "cronjob@example.com" || strings.Join(srv.rcpt, ",") != "ops@example.com,dev@example.com" {
+		t.Fatalf("unexpected envelope from=%s rcpt=%v", srv.from, srv.rcpt)
+	}
+	head, body, ok := strings.Cut(srv.data, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("malformed message: %q", srv.data)
+	}
+	if !strings.Contains(head, `Subject: [cronjob] FAILURE daily "bars"`) || !strings.Contains(head, "To: ops@example.com, dev@example.com") {
+		t.Fatalf("unexpected headers: %s", head)
+	}
+	text, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(strings.ReplaceAll(string(text), "\r\n", "\n")); got != "run 42 FAILED\nerror: boom" {
+		t.Fatalf("unexpected body: %q", got)
+	}
+}
+
+func TestSMTPDialError(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	port := ln.Addr().(*net.TCPAddr).Port
+	_ = ln.Close()
+	s, _ := NewSMTP("mail", SMTPOptions{Host: "127.0.0.1", Port: port, From: "a@b", To: []string{"c@d"}, Timeout: time.Second})
+	if err := s.Send(context.Background(), testMessage()); err == nil {
+		t.Fatal("expected dial error")
+	}
+}
+
+func TestFileAppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts", "alerts.log")
+	f, err := NewFile("local", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := f.Send(context.Background(), testMessage()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	var m Message
+	if len(lines) != 2 || json.Unmarshal([]byte(lines[1]), &m) != nil || m.Kind != "FAILURE" {
+		t.Fatalf("unexpected file content: %s", b)
+	}
+}
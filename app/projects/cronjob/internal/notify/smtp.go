@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPOptions SMTP 邮件：服务器支持 STARTTLS 时自动升级；TLS 为 true 时直接以 TLS 连接（465 端口）。
+type SMTPOptions struct {
+	Host               string
+	Port               int // 默认 25（TLS 时 465）
+	Username           string
+	Password           string
+	From               string
+	To                 []string
+	TLS                bool
+	InsecureSkipVerify bool
+	SubjectTemplate    string        // 默认 {{ .Title }}
+	BodyTemplate       string        // 默认 Message.Text
+	Timeout            time.Duration // 连接与整个会话的超时，默认 10s
+}
+
+type SMTP struct {
+	name string
+	opts SMTPOptions
+}
+
+func NewSMTP(name string, opts SMTPOptions) (*SMTP, error) {
+	if opts.Host == "" || opts.From == "" || len(opts.To) == 0 {
+		return nil, fmt.Errorf("smtp %s: host, from and to required", name)
+	}
+	if opts.Port <= 0 {
+		opts.Port = 25
+		if opts.TLS {
+			opts.Port = 465
+		}
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if err := Validate("subject_template", opts.SubjectTemplate); err != nil {
+		return nil, fmt.Errorf("smtp %s: %w", name, err)
+	}
+	if err := Validate("body_template", opts.BodyTemplate); err != nil {
+		return nil, fmt.Errorf("smtp %s: %w", name, err)
+	}
+	return &SMTP{name: name, opts: opts}, nil
+}
+
+func (s *SMTP) Name() string { return s.name }
+func (s *SMTP) Type() string { return "smtp" }
+
+func (s *SMTP) Send(ctx context.Context, msg *Message) error {
+	subject, err := execute("subject_template", s.opts.SubjectTemplate, msg.Title, msg)
+	if err != nil {
+		return err
+	}
+	body, err := execute("body_template", s.opts.BodyTemplate, msg.Text, msg)
+	if err != nil {
+		return err
+	}
+	data, err := s.compose(subject, body, msg.At)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+	addr := net.JoinHostPort(s.opts.Host, strconv.Itoa(s.opts.Port))
+	tlsCfg := &tls.Config{ServerName: s.opts.Host, InsecureSkipVerify: s.opts.InsecureSkipVerify}
+	var conn net.Conn
+	if s.opts.TLS {
+		conn, err = (&tls.Dialer{Config: tlsCfg}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("smtp %s: dial: %w", s.name, err)
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(dl)
+	}
+	c, err := smtp.NewClient(conn, s.opts.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("smtp %s: %w", s.name, err)
+	}
+	defer c.Close()
+	if !s.opts.TLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsCfg); err != nil {
+				return fmt.Errorf("smtp %s: starttls: %w", s.name, err)
+			}
+		}
+	}
+	if s.opts.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.opts.Username, s.opts.Password, s.opts.Host)); err != nil {
+			return fmt.Errorf("smtp %s: auth: %w", s.name, err)
+		}
+	}
+	if err := c.Mail(s.opts.From); err != nil {
+		return fmt.Errorf("smtp %s: mail from: %w", s.name, err)
+	}
+	for _, to := range s.opts.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("smtp %s: rcpt %s: %w", s.name, to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp %s: data: %w", s.name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("smtp %s: data: %w", s.name, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp %s: data: %w", s.name, err)
+	}
+	return c.Quit()
+}
+
+// compose 生成邮件：主题按 RFC 2047 编码，正文为 UTF-8 quoted-printable 纯文本。
+func (s *SMTP) compose(subject, body string, at time.Time) ([]byte, error) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", s.opts.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(s.opts.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", at.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\r\n")
+	return buf.Bytes(), nil
+}
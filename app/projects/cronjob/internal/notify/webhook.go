@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WebhookOptions 通用 webhook：向 URL 发送渲染后的请求体，2xx 视为成功。
+type WebhookOptions struct {
+	URL          string
+	Method       string            // 默认 POST
+	Headers      map[string]string // 未设置 Content-Type 时为 application/json
+	BodyTemplate string            // 为空时发送 Message 的 JSON
+	Timeout      time.Duration     // 默认 10s
+}
+
+type Webhook struct {
+	name   string
+	opts   WebhookOptions
+	client *http.Client
+}
+
+func NewWebhook(name string, opts WebhookOptions) (*Webhook, error) {
+	if opts.URL == "" {
+		return nil, fmt.Errorf("webhook %s: url required", name)
+	}
+	if opts.Method == "" {
+		opts.Method = http.MethodPost
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if err := Validate("body_template", opts.BodyTemplate); err != nil {
+		return nil, fmt.Errorf("webhook %s: %w", name, err)
+	}
+	return &Webhook{name: name, opts: opts, client: &http.Client{Timeout: opts.Timeout}}, nil
+}
+
+func (w *Webhook) Name() string { return w.name }
+func (w *Webhook) Type() string { return "webhook" }
+
+func (w *Webhook) Send(ctx context.Context, msg *Message) error {
+	def, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	body, err := execute("body_template", w.opts.BodyTemplate, string(def), msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, w.opts.Method, w.opts.URL, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.opts.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook %s: status %d: %s", w.name, resp.StatusCode, truncate(string(b), 256))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
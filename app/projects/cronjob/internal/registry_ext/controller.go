@@ -13,6 +13,11 @@ func init() {
 	registry.ExtendRuntimeDependencies(appconsts.COMPONENT_HTTP_SERVER, api.NewTaskMgmtController().Name())
 	registry.ExtendRuntimeDependencies(appconsts.COMPONENT_HTTP_SERVER, api.NewRunMgmtController().Name())
 	registry.ExtendRuntimeDependencies(appconsts.COMPONENT_HTTP_SERVER, api.NewMetaController().Name())
+	registry.ExtendRuntimeDependencies(appconsts.COMPONENT_HTTP_SERVER, api.NewBackfillController().Name())
+	registry.ExtendRuntimeDependencies(appconsts.COMPONENT_HTTP_SERVER, api.NewCalendarController().Name())
+	registry.ExtendRuntimeDependencies(appconsts.COMPONENT_HTTP_SERVER, api.NewAlertController().Name())
+	registry.ExtendRuntimeDependencies(appconsts.COMPONENT_HTTP_SERVER, api.NewAnalyticsController().Name())
+	registry.ExtendRuntimeDependencies(appconsts.COMPONENT_HTTP_SERVER, api.NewNamespaceController().Name())
 
 	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
 		return true, api.NewTaskMgmtController(), nil
@@ -23,4 +28,19 @@ func init() {
 	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
 		return true, api.NewMetaController(), nil
 	})
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		return true, api.NewBackfillController(), nil
+	})
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		return true, api.NewCalendarController(), nil
+	})
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		return true, api.NewAlertController(), nil
+	})
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		return true, api.NewAnalyticsController(), nil
+	})
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		return true, api.NewNamespaceController(), nil
+	})
 }
@@ -1,7 +1,10 @@
 package registry_ext
 
 import (
+	"time"
+
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/config"
+	appconsts "github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/registry"
 	bizConfig "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
@@ -18,13 +21,42 @@ func init() {
 		return true, service.NewRunService(), nil
 	})
 	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
-		return true, service.NewExecutor(cronjobCfg.Executor), nil
+		return true, service.NewCalendarService(cronjobCfg.Calendar), nil
+	})
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		return true, service.NewNamespaceService(cronjobCfg.Namespace), nil
+	})
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		exec := service.NewExecutor(cronjobCfg.Executor)
+		// Start after prometheus so per-task run metrics can be registered.
+		if cfg.Prometheus != nil && cfg.Prometheus.Enabled {
+			exec.AddDependencies(appconsts.COMPONENT_PROMETHEUS)
+		}
+		return true, exec, nil
+	})
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		return true, service.NewLeaderElector(cronjobCfg.HA), nil
+	})
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		return true, service.NewSchedulerPauseService(), nil
 	})
 	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
 		return true, service.NewEngine(cronjobCfg.Scheduler), nil
 	})
 	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
-		return true, service.NewRunProgressManager(), nil
+		return true, service.NewDagResolver(cronjobCfg.Dag), nil
+	})
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		return true, service.NewBackfillManager(cronjobCfg.Backfill), nil
+	})
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		return true, service.NewAlertManager(cronjobCfg.Alert), nil
+	})
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		return true, service.NewAnalyticsService(), nil
+	})
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		return true, service.NewRunProgressManager(time.Duration(cronjobCfg.Scanner.ProgressCleanupGraceSeconds) * time.Second), nil
 	})
 	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
 		return true, service.NewRunScanner(cronjobCfg.Scanner), nil
@@ -0,0 +1,217 @@
+// Package render 用 text/template 渲染任务的请求路径、请求头与请求体。
+//
+// 模板只能使用本包提供的函数：时间格式化与日期运算、按日历取前后营业日、JSON 转义与
+// 从环境变量读取密钥（仅限配置的前缀）。渲染出的密钥值会被记录，落库前用 Redact 打码。
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/calendar"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
+)
+
+// Mask 密钥在快照与预览中的替代文本。
+const Mask = "******"
+
+// Data 模板中的 `.`；时间均为任务时区。
+type Data struct {
+	RunID         int64
+	TaskID        int64
+	TaskName      string
+	Attempt       int
+	RetryIndex    int
+	TriggerType   string
+	BackfillID    int64
+	Timezone      string
+	ScheduledTime time.Time // 逻辑触发时间（补跑/重试时为原触发点）
+	LogicalDate   time.Time // 业务日期当天零点
+	Now           time.Time
+}
+
+// Env 渲染时可访问的外部资源。
+type Env struct {
+	Calendar func(name string) (*calendar.Calendar, bool) // 按名称取日历
+	Secret   func(name string) (string, bool)             // 按名称取密钥
+}
+
+// Result 一次渲染的结果；Secrets 为渲染中用到的密钥值，用于打码。
+type Result struct {
+	Path    string
+	Headers string
+	Body    string
+	Args    []string          // COMMAND 后端的命令参数
+	Env     map[string]string // COMMAND 后端追加的环境变量
+	Secrets []string
+}
+
+// Redact 把 s 中出现的密钥值替换为 Mask。
+func (r *Result) Redact(s string) string {
+	for _, v := range r.Secrets {
+		if v != "" {
+			s = strings.ReplaceAll(s, v, Mask)
+		}
+	}
+	return s
+}
+
+// IsTemplate 是否包含模板动作；不含时原样使用，无需渲染。
+func IsTemplate(s string) bool { return strings.Contains(s, "{{") }
+
+// Validate 校验模板语法与函数名。
+func Validate(field, text string) error {
+	if !IsTemplate(text) {
+		return nil
+	}
+	if _, err := template.New(field).Funcs(funcs(nil, &Env{})).Parse(text); err != nil {
+		return fmt.Errorf("invalid %s template: %w", field, err)
+	}
+	return nil
+}
+
+// Render 依次渲染路径、请求头与请求体。
+func Render(path, headers, body string, data Data, env Env) (*Result, error) {
+	res := &Result{}
+	fm := funcs(res, &env)
+	var err error
+	if res.Path, err = execute("target_path", path, data, fm); err != nil {
+		return nil, err
+	}
+	if res.Headers, err = execute("headers_json", headers, data, fm); err != nil {
+		return nil, err
+	}
+	if res.Body, err = execute("body_template", body, data, fm); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RenderStrings 渲染一组模板（如命令参数），用到的密钥记入 res 以便打码。
+func RenderStrings(res *Result, field string, items []string, data Data, env Env) ([]string, error) {
+	fm := funcs(res, &env)
+	out := make([]string, len(items))
+	for i, item := range items {
+		v, err := execute(fmt.Sprintf("%s[%d]", field, i), item, data, fm)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func execute(field, text string, data Data, fm template.FuncMap) (string, error) {
+	if !IsTemplate(text) {
+		return text, nil
+	}
+	t, err := template.New(field).Funcs(fm).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", field, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render %s: %w", field, err)
+	}
+	return buf.String(), nil
+}
+
+// funcs 模板函数；时间参数放在最后，便于管道写法：{{ .ScheduledTime | addDays -1 | ymd }}
+func funcs(res *Result, env *Env) template.FuncMap {
+	cal := func(name string) (*calendar.Calendar, error) {
+		if env.Calendar != nil {
+			if c, ok := env.Calendar(name); ok {
+				return c, nil
+			}
+		}
+		return nil, fmt.Errorf("calendar %q not found", name)
+	}
+	return template.FuncMap{
+		"format":      func(layout string, t time.Time) string { return t.Format(layout) },
+		"ymd":         func(t time.Time) string { return t.Format(time.DateOnly) },
+		"ymdCompact":  func(t time.Time) string { return t.Format("20060102") },
+		"rfc3339":     func(t time.Time) string { return t.Format(time.RFC3339) },
+		"unix":        func(t time.Time) int64 { return t.Unix() },
+		"addDays":     func(n int, t time.Time) time.Time { return t.AddDate(0, 0, n) },
+		"addMonths":   func(n int, t time.Time) time.Time { return t.AddDate(0, n, 0) },
+		"addDuration": addDuration,
+		"startOfDay": func(t time.Time) time.Time {
+			y, m, d := t.Date()
+			return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+		},
+		"startOfMonth": func(t time.Time) time.Time {
+			y, m, _ := t.Date()
+			return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+		},
+		"endOfMonth": func(t time.Time) time.Time {
+			y, m, _ := t.Date()
+			return time.Date(y, m+1, 0, 0, 0, 0, 0, t.Location())
+		},
+		"inTZ": func(name string, t time.Time) (time.Time, error) {
+			loc, err := cron.LoadLocation(name)
+			if err != nil {
+				return t, err
+			}
+			return t.In(loc), nil
+		},
+		"isBusinessDay": func(name string, t time.Time) (bool, error) {
+			c, err := cal(name)
+			if err != nil {
+				return false, err
+			}
+			return c.IsBusinessDay(t), nil
+		},
+		"prevBusinessDay": func(name string, t time.Time) (time.Time, error) {
+			c, err := cal(name)
+			if err != nil {
+				return t, err
+			}
+			if p := c.PrevBusinessDay(t); !p.IsZero() {
+				return p, nil
+			}
+			return t, fmt.Errorf("calendar %q has no business day before %s", name, t.Format(time.DateOnly))
+		},
+		"nextBusinessDay": func(name string, t time.Time) (time.Time, error) {
+			c, err := cal(name)
+			if err != nil {
+				return t, err
+			}
+			if n := c.NextBusinessDay(t); !n.IsZero() {
+				return n, nil
+			}
+			return t, fmt.Errorf("calendar %q has no business day after %s", name, t.Format(time.DateOnly))
+		},
+		"secret": func(name string) (string, error) {
+			if env.Secret == nil {
+				return "", fmt.Errorf("secret %q not available", name)
+			}
+			v, ok := env.Secret(name)
+			if !ok {
+				return "", fmt.Errorf("secret %q not set", name)
+			}
+			if res != nil {
+				res.Secrets = append(res.Secrets, v)
+			}
+			return v, nil
+		},
+		// json 输出 JSON 字面量（字符串带引号并转义），用于在 JSON 模板中安全嵌入任意值
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+func addDuration(d string, t time.Time) (time.Time, error) {
+	dur, err := time.ParseDuration(d)
+	if err != nil {
+		return t, err
+	}
+	return t.Add(dur), nil
+}
@@ -0,0 +1,77 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/calendar"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
+)
+
+func TestRender(t *testing.T) {
+	loc, _ := cron.LoadLocation("Asia/Shanghai")
+	sse, _ := calendar.New("SSE", consts.CalendarKindHolidays, []string{"2026-10-01", "2026-10-02", "2026-10-05", "2026-10-06", "2026-10-07"})
+	env := Env{
+		Calendar: func(name string) (*calendar.Calendar, bool) { return sse, name == "SSE" },
+		Secret: func(name string) (string, bool) {
+			if name == "TOKEN" {
+				return "s3cr3t", true
+			}
+			return "", false
+		},
+	}
+	data := Data{RunID: 42, TaskID: 7, TaskName: `daily "stats"`, Attempt: 2, Timezone: "Asia/Shanghai",
+		ScheduledTime: time.Date(2026, 10, 8, 9, 30, 0, 0, loc), LogicalDate: time.Date(2026, 10, 8, 0, 0, 0, 0, loc)}
+
+	cases := []struct{ tmpl, want string }{
+		{`{{ .ScheduledTime | ymd }}`, "2026-10-08"},
+		{`{{ .ScheduledTime | addDays -1 | ymdCompact }}`, "20261007"},
+		{`{{ .ScheduledTime | prevBusinessDay "SSE" | ymd }}`, "2026-09-30"},
+		{`{{ .LogicalDate | nextBusinessDay "SSE" | ymd }}`, "2026-10-09"},
+		{`{{ isBusinessDay "SSE" .ScheduledTime }}`, "true"},
+		{`{{ .ScheduledTime | startOfMonth | addMonths -1 | endOfMonth | ymd }}`, "2026-09-30"},
+		{`{{ .ScheduledTime | addDuration "-90m" | format "15:04" }}`, "08:00"},
+		{`{{ .ScheduledTime | inTZ "UTC" | rfc3339 }}`, "2026-10-08T01:30:00Z"},
+		{`{"run":{{ .RunID }},"attempt":{{ .Attempt }},"name":{{ json .TaskName }}}`, `{"run":42,"attempt":2,"name":"daily \"stats\""}`},
+		{`no template`, "no template"},
+	}
+	for _, c := range cases {
+		res, err := Render("/p", "{}", c.tmpl, data, env)
+		if err != nil {
+			t.Fatalf("%s: %v", c.tmpl, err)
+		}
+		if res.Body != c.want {
+			t.Errorf("%s: got %q want %q", c.tmpl, res.Body, c.want)
+		}
+	}
+
+	res, err := Render(`/trade/{{ .LogicalDate | ymd }}`, `{"Authorization":"Bearer {{ secret "TOKEN" }}"}`, `{}`, data, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Path != "/trade/2026-10-08" || !strings.Contains(res.Headers, "s3cr3t") {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if red := res.Redact(res.Headers); strings.Contains(red, "s3cr3t") || !strings.Contains(red, Mask) {
+		t.Fatalf("secret not redacted: %s", red)
+	}
+
+	for _, bad := range []string{`{{ secret "MISSING" }}`, `{{ .ScheduledTime | prevBusinessDay "NYSE" }}`, `{{ .Nope }}`} {
+		if _, err := Render("/p", "{}", bad, data, env); err == nil {
+			t.Errorf("%s: expected render error", bad)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate("body_template", `{{ .ScheduledTime | ymd }}`); err != nil {
+		t.Fatalf("valid template rejected: %v", err)
+	}
+	for _, bad := range []string{`{{ .ScheduledTime | ymd `, `{{ env "HOME" }}`, `{{ run_id }}`} {
+		if err := Validate("body_template", bad); err == nil {
+			t.Errorf("%s: expected validation error", bad)
+		}
+	}
+}
@@ -0,0 +1,509 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/notify"
+)
+
+// Run 告警：
+//   - 规则按任务配置（alert_rules），引用 biz_config.alert.channels 中的通知渠道
+//   - AlertManager 周期性评估已结束的 Run（task_runs.alert_evaluated），与 DagResolver 一样延后一个周期，让重试计划先落库
+//   - 失败类规则只看最终失败：已计划或已派发重试的 Run 不告警，由重试链的最后一次决定
+//   - SLA_MISSED 按触发点检查：scheduled_time + sla_sec 时整条重试链仍未成功即告警，每个触发点只告警一次
+//   - 同一规则下相同去重 key 的告警在抑制窗口内只发送一次，其余记为 SUPPRESSED；每个渠道的结果都记入 alert_history
+
+var (
+	ErrInvalidAlertRule = errors.New("invalid alert rule")
+	ErrUnknownChannel   = errors.New("unknown alert channel")
+)
+
+const (
+	severityCritical = "CRITICAL"
+	severityWarning  = "WARNING"
+	severityInfo     = "INFO"
+
+	defaultSlowFactor     = 1.5
+	defaultSlowMinSamples = 10
+)
+
+// AlertChannel 已配置的通知渠道。
+type AlertChannel struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// AlertManager 评估告警规则并发送通知；多副本时仅 leader 评估。
+type AlertManager struct {
+	*core.BaseComponent
+	cfg      config.AlertConfig
+	AlertDao dao.AlertDao   `infra:"dep:alert_dao"`
+	TaskSvc  *TaskService   `infra:"dep:task_service"`
+	Leader   *LeaderElector `infra:"dep:scheduler_leader"`
+
+	notifiers map[string]notify.Notifier
+	settling  map[int64]struct{} // 上一周期首次看到的已结束 Run（仅评估协程访问）
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func NewAlertManager(cfg config.AlertConfig) *AlertManager {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+	if cfg.BatchLimit <= 0 {
+		cfg.BatchLimit = 200
+	}
+	if cfg.DefaultSuppress <= 0 {
+		cfg.DefaultSuppress = 30 * time.Minute
+	}
+	if cfg.SlowWindow <= 0 {
+		cfg.SlowWindow = 100
+	}
+	if cfg.SLALookback <= 0 {
+		cfg.SLALookback = 24 * time.Hour
+	}
+	if cfg.SendTimeout <= 0 {
+		cfg.SendTimeout = 10 * time.Second
+	}
+	return &AlertManager{BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_ALERT), cfg: cfg, settling: make(map[int64]struct{})}
+}
+
+// Start 构建通知渠道（配置错误时启动失败）并启动评估循环。
+func (m *AlertManager) Start(ctx context.Context) error {
+	if m.IsActive() {
+		return nil
+	}
+	notifiers, err := buildNotifiers(m.cfg.Channels)
+	if err != nil {
+		return err
+	}
+	for _, name := range m.cfg.DefaultChannels {
+		if _, ok := notifiers[name]; !ok {
+			return fmt.Errorf("alert.default_channels: %w %q", ErrUnknownChannel, name)
+		}
+	}
+	m.notifiers = notifiers
+	if err := m.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	loopCtx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+				if m.Leader.IsLeader() {
+					m.evaluate(loopCtx)
+					m.checkSLA(loopCtx)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (m *AlertManager) Stop(ctx context.Context) error {
+	if !m.IsActive() {
+		return nil
+	}
+	if m.cancel != nil {
+		m.cancel()
+		<-m.done
+	}
+	return m.BaseComponent.Stop(ctx)
+}
+
+// buildNotifiers 按配置构建通知渠道。
+func buildNotifiers(channels map[string]config.AlertChannelConfig) (map[string]notify.Notifier, error) {
+	out := make(map[string]notify.Notifier, len(channels))
+	for name, c := range channels {
+		var (
+			n   notify.Notifier
+			err error
+		)
+		switch strings.ToLower(c.Type) {
+		case bizConsts.AlertChannelWebhook:
+			n, err = notify.NewWebhook(name, notify.WebhookOptions{URL: c.URL, Method: c.Method, Headers: c.Headers,
+				BodyTemplate: c.BodyTemplate, Timeout: c.Timeout})
+		case bizConsts.AlertChannelSMTP:
+			n, err = notify.NewSMTP(name, notify.SMTPOptions{Host: c.Host, Port: c.Port, Username: c.Username, Password: c.Password,
+				From: c.From, To: c.To, TLS: c.TLS, InsecureSkipVerify: c.InsecureSkipVerify,
+				SubjectTemplate: c.SubjectTemplate, BodyTemplate: c.BodyTemplate, Timeout: c.Timeout})
+		case bizConsts.AlertChannelFile:
+			n, err = notify.NewFile(name, c.Path)
+		case bizConsts.AlertChannelLog:
+			n = &logNotifier{name: name}
+		default:
+			err = fmt.Errorf("alert channel %s: unknown type %q", name, c.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out[name] = n
+	}
+	return out, nil
+}
+
+// logNotifier 把告警写入服务日志。
+type logNotifier struct{ name string }
+
+func (l *logNotifier) Name() string { return l.name }
+func (l *logNotifier) Type() string { return bizConsts.AlertChannelLog }
+func (l *logNotifier) Send(ctx context.Context, msg *notify.Message) error {
+	logging.Warn(ctx, fmt.Sprintf("alert channel=%s kind=%s task=%d run=%d: %s\n%s", l.name, msg.Kind, msg.TaskID, msg.RunID, msg.Title, msg.Text))
+	return nil
+}
+
+// Channels 已配置的通知渠道，按名称排序。
+func (m *AlertManager) Channels() []AlertChannel {
+	out := make([]AlertChannel, 0, len(m.notifiers))
+	for name, n := range m.notifiers {
+		out = append(out, AlertChannel{Name: name, Type: n.Type()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ValidateRule 校验规则参数与渠道，并补全默认值。
+func (m *AlertManager) ValidateRule(rule *model.AlertRule) error {
+	switch rule.Kind {
+	case bizConsts.AlertFailure, bizConsts.AlertRecovery:
+	case bizConsts.AlertConsecutiveFailures:
+		if rule.Threshold < 2 {
+			return fmt.Errorf("%w: threshold must be >= 2", ErrInvalidAlertRule)
+		}
+	case bizConsts.AlertSlowRun:
+		if rule.Factor == 0 {
+			rule.Factor = defaultSlowFactor
+		}
+		if rule.MinSamples == 0 {
+			rule.MinSamples = defaultSlowMinSamples
+		}
+		if rule.Factor <= 0 || rule.MinSamples < 1 {
+			return fmt.Errorf("%w: factor and min_samples must be positive", ErrInvalidAlertRule)
+		}
+	case bizConsts.AlertSLAMissed:
+		if rule.SLASec <= 0 {
+			return fmt.Errorf("%w: sla_sec must be > 0", ErrInvalidAlertRule)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidAlertRule, rule.Kind)
+	}
+	if rule.SuppressSec < 0 {
+		return fmt.Errorf("%w: suppress_sec must be >= 0", ErrInvalidAlertRule)
+	}
+	for _, ch := range rule.Channels {
+		if _, ok := m.notifiers[ch]; !ok {
+			return fmt.Errorf("%w: %w %q", ErrInvalidAlertRule, ErrUnknownChannel, ch)
+		}
+	}
+	if len(rule.Channels) == 0 && len(m.cfg.DefaultChannels) == 0 {
+		return fmt.Errorf("%w: channels required (no alert.default_channels configured)", ErrInvalidAlertRule)
+	}
+	return nil
+}
+
+func (m *AlertManager) CreateRule(ctx context.Context, rule *model.AlertRule) error {
+	if err := m.ValidateRule(rule); err != nil {
+		return err
+	}
+	return m.AlertDao.CreateRule(ctx, rule)
+}
+
+func (m *AlertManager) UpdateRule(ctx context.Context, rule *model.AlertRule) error {
+	if err := m.ValidateRule(rule); err != nil {
+		return err
+	}
+	return m.AlertDao.UpdateRule(ctx, rule)
+}
+
+func (m *AlertManager) GetRule(ctx context.Context, id int64) (*model.AlertRule, error) {
+	return m.AlertDao.GetRule(ctx, id)
+}
+
+func (m *AlertManager) DeleteRule(ctx context.Context, id int64) error {
+	return m.AlertDao.DeleteRule(ctx, id)
+}
+
+func (m *AlertManager) ListRules(ctx context.Context, taskID int64) ([]*model.AlertRule, error) {
+	return m.AlertDao.ListRules(ctx, taskID)
+}
+
+func (m *AlertManager) ListEvents(ctx context.Context, f model.AlertEventFilter, limit, offset int) ([]*model.AlertEvent, error) {
+	return m.AlertDao.ListEvents(ctx, f, limit, offset)
+}
+
+// TestChannel 向渠道发送一条测试消息（不记入历史）。
+func (m *AlertManager) TestChannel(ctx context.Context, name string) error {
+	n, ok := m.notifiers[name]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownChannel, name)
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.SendTimeout)
+	defer cancel()
+	return n.Send(ctx, &notify.Message{Kind: "TEST", Severity: severityInfo, Title: "[cronjob] 告警渠道测试",
+		Text: fmt.Sprintf("这是一条来自 cronjob 的测试消息（渠道 %s）。", name), At: time.Now().UTC()})
+}
+
+// evaluate 评估一批已结束的 Run 并标记 alert_evaluated。
+func (m *AlertManager) evaluate(ctx context.Context) {
+	runs, err := m.AlertDao.ListPending(ctx, m.cfg.BatchLimit)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("alert list pending runs failed: %v", err))
+		return
+	}
+	settling := make(map[int64]struct{}, len(runs))
+	var ready []*model.TaskRun
+	for _, run := range runs {
+		if _, ok := m.settling[run.ID]; ok {
+			ready = append(ready, run)
+		} else {
+			settling[run.ID] = struct{}{}
+		}
+	}
+	m.settling = settling
+	if len(ready) == 0 {
+		return
+	}
+	rulesByTask := make(map[int64][]*model.AlertRule)
+	evaluated := make([]int64, 0, len(ready))
+	for _, run := range ready {
+		rules, ok := rulesByTask[run.TaskID]
+		if !ok {
+			if rules, err = m.AlertDao.ListRules(ctx, run.TaskID); err != nil {
+				logging.Error(ctx, fmt.Sprintf("alert list rules of task %d failed: %v", run.TaskID, err))
+				break // 未标记的 Run 下一周期重试
+			}
+			rulesByTask[run.TaskID] = rules
+		}
+		task, _ := m.TaskSvc.Get(ctx, run.TaskID)
+		failed := false
+		for _, rule := range rules {
+			if !rule.Enabled || task == nil {
+				continue
+			}
+			if err := m.evaluateRun(ctx, rule, task, run); err != nil {
+				logging.Error(ctx, fmt.Sprintf("alert rule %d on run %d failed: %v", rule.ID, run.ID, err))
+				failed = true
+				break
+			}
+		}
+		if failed {
+			break
+		}
+		evaluated = append(evaluated, run.ID)
+	}
+	if err := m.AlertDao.MarkEvaluated(ctx, evaluated); err != nil {
+		logging.Error(ctx, fmt.Sprintf("alert mark evaluated failed: %v", err))
+	}
+}
+
+// evaluateRun 按规则评估一个已结束的 Run。
+func (m *AlertManager) evaluateRun(ctx context.Context, rule *model.AlertRule, task *model.Task, run *model.TaskRun) error {
+	switch rule.Kind {
+	case bizConsts.AlertFailure:
+		if !run.Status.IsFailure() || run.NextRetryTime != nil {
+			return nil
+		}
+		recent, err := m.AlertDao.RecentEffective(ctx, task.ID, run.ID, 1)
+		if err != nil || len(recent) == 0 || recent[0].ID != run.ID {
+			return err // 已被重试取代，不是最终失败
+		}
+		return m.fire(ctx, rule, task, run, "failure", severityCritical,
+			fmt.Sprintf("任务 %s 执行失败（%s）", task.Name, run.Status), "")
+	case bizConsts.AlertConsecutiveFailures:
+		if !run.Status.IsFailure() || run.NextRetryTime != nil {
+			return nil
+		}
+		recent, err := m.AlertDao.RecentEffective(ctx, task.ID, run.ID, rule.Threshold)
+		if err != nil || len(recent) < rule.Threshold || recent[0].ID != run.ID {
+			return err
+		}
+		for _, r := range recent {
+			if !r.Status.IsFailure() {
+				return nil
+			}
+		}
+		return m.fire(ctx, rule, task, run, "consecutive", severityCritical,
+			fmt.Sprintf("任务 %s 连续 %d 次执行失败", task.Name, rule.Threshold), "")
+	case bizConsts.AlertSlowRun:
+		if run.Status != bizConsts.Success || run.StartTime == nil || run.EndTime == nil {
+			return nil
+		}
+		durs, err := m.AlertDao.SuccessDurations(ctx, task.ID, run.ID, m.cfg.SlowWindow)
+		if err != nil || len(durs) < rule.MinSamples {
+			return err
+		}
+		p95 := percentile(durs, 0.95)
+		dur := run.EndTime.Sub(*run.StartTime).Seconds()
+		if dur <= p95*rule.Factor {
+			return nil
+		}
+		return m.fire(ctx, rule, task, run, "slow", severityWarning,
+			fmt.Sprintf("任务 %s 执行耗时异常", task.Name),
+			fmt.Sprintf("耗时: %.1fs，近 %d 次成功的 P95 为 %.1fs（阈值 %.1fs = P95 × %g）", dur, len(durs), p95, p95*rule.Factor, rule.Factor))
+	case bizConsts.AlertRecovery:
+		if run.Status != bizConsts.Success {
+			return nil
+		}
+		recent, err := m.AlertDao.RecentEffective(ctx, task.ID, run.ID, 2)
+		if err != nil || len(recent) < 2 || recent[0].ID != run.ID || !recent[1].Status.IsFailure() {
+			return err
+		}
+		return m.fire(ctx, rule, task, run, "recovery", severityInfo,
+			fmt.Sprintf("任务 %s 已恢复", task.Name), fmt.Sprintf("上一次有效执行: run %d %s", recent[1].ID, recent[1].Status))
+	}
+	return nil
+}
+
+// checkSLA 检查各 SLA_MISSED 规则：截止时间已过、整条重试链仍未成功的触发点各告警一次。
+func (m *AlertManager) checkSLA(ctx context.Context) {
+	rules, err := m.AlertDao.ListEnabledRulesByKind(ctx, bizConsts.AlertSLAMissed)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("alert list sla rules failed: %v", err))
+		return
+	}
+	now := time.Now().UTC()
+	for _, rule := range rules {
+		task, _ := m.TaskSvc.Get(ctx, rule.TaskID)
+		if task == nil || rule.SLASec <= 0 {
+			continue
+		}
+		sla := time.Duration(rule.SLASec) * time.Second
+		runs, err := m.AlertDao.ListSLAMissed(ctx, task.ID, sla, now.Add(-sla-m.cfg.SLALookback), now.Add(-sla), m.cfg.BatchLimit)
+		if err != nil {
+			logging.Error(ctx, fmt.Sprintf("alert list sla missed runs of task %d failed: %v", task.ID, err))
+			continue
+		}
+		for _, run := range runs {
+			key := fmt.Sprintf("sla:%d", run.ID)
+			if seen, err := m.AlertDao.HasEvent(ctx, rule.ID, key); err != nil || seen {
+				continue
+			}
+			deadline := run.ScheduledTime.Add(sla)
+			if err := m.fire(ctx, rule, task, run, key, severityCritical,
+				fmt.Sprintf("任务 %s 未在 SLA 内成功", task.Name),
+				fmt.Sprintf("SLA: 触发后 %s 内成功，截止 %s", sla, formatInTaskTZ(task, deadline))); err != nil {
+				logging.Error(ctx, fmt.Sprintf("alert sla rule %d on run %d failed: %v", rule.ID, run.ID, err))
+			}
+		}
+	}
+}
+
+// fire 发送一条告警：抑制窗口内已发送过同一 key 时只记录 SUPPRESSED。
+func (m *AlertManager) fire(ctx context.Context, rule *model.AlertRule, task *model.Task, run *model.TaskRun, key, severity, title, detail string) error {
+	now := time.Now().UTC()
+	channels := rule.Channels
+	if len(channels) == 0 {
+		channels = m.cfg.DefaultChannels
+	}
+	msg := &notify.Message{Kind: string(rule.Kind), Severity: severity, RuleID: rule.ID, TaskID: task.ID, TaskName: task.Name,
+		RunID: run.ID, RunStatus: string(run.Status), Title: "[cronjob] " + title, Text: alertText(rule, task, run, detail), At: now}
+	if !run.ScheduledTime.IsZero() {
+		st := run.ScheduledTime
+		msg.ScheduledTime = &st
+	}
+	window := m.cfg.DefaultSuppress
+	if rule.SuppressSec > 0 {
+		window = time.Duration(rule.SuppressSec) * time.Second
+	}
+	last, err := m.AlertDao.LastSent(ctx, rule.ID, key)
+	if err != nil {
+		return err
+	}
+	suppressed := last != nil && now.Sub(last.CreatedAt) < window
+	runID := run.ID
+	for _, ch := range channels {
+		ev := &model.AlertEvent{RuleID: rule.ID, TaskID: task.ID, RunID: &runID, Kind: rule.Kind, DedupKey: key, Channel: ch,
+			Title: msg.Title, Message: msg.Text, CreatedAt: now}
+		switch n, ok := m.notifiers[ch]; {
+		case suppressed:
+			ev.Status = bizConsts.AlertSuppressed
+		case !ok:
+			ev.Status, ev.Error = bizConsts.AlertFailed, fmt.Sprintf("%v %q", ErrUnknownChannel, ch)
+		default:
+			sendCtx, cancel := context.WithTimeout(ctx, m.cfg.SendTimeout)
+			err := n.Send(sendCtx, msg)
+			cancel()
+			ev.Status = bizConsts.AlertSent
+			if err != nil {
+				ev.Status, ev.Error = bizConsts.AlertFailed, err.Error()
+				logging.Warn(ctx, fmt.Sprintf("alert rule %d send to %s failed: %v", rule.ID, ch, err))
+			}
+		}
+		if err := m.AlertDao.CreateEvent(ctx, ev); err != nil {
+			return err
+		}
+	}
+	if !suppressed {
+		logging.Info(ctx, fmt.Sprintf("alert rule=%d kind=%s task=%d run=%d key=%s channels=%v", rule.ID, rule.Kind, task.ID, run.ID, key, channels))
+	}
+	return nil
+}
+
+// alertText 通知正文：任务、Run、计划时间与错误信息。
+func alertText(rule *model.AlertRule, task *model.Task, run *model.TaskRun, detail string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "任务: %s (id=%d)\n", task.Name, task.ID)
+	fmt.Fprintf(&b, "Run: %d 状态 %s，第 %d 次尝试\n", run.ID, run.Status, run.Attempt)
+	if !run.ScheduledTime.IsZero() {
+		fmt.Fprintf(&b, "计划时间: %s\n", formatInTaskTZ(task, run.ScheduledTime))
+	}
+	if run.ErrorMessage != "" {
+		msg := run.ErrorMessage
+		if len(msg) > 500 {
+			msg = msg[:500] + "..."
+		}
+		fmt.Fprintf(&b, "错误: %s\n", msg)
+	}
+	if detail != "" {
+		b.WriteString(detail + "\n")
+	}
+	fmt.Fprintf(&b, "规则: %s #%d", rule.Kind, rule.ID)
+	if rule.Description != "" {
+		b.WriteString(" " + rule.Description)
+	}
+	return b.String()
+}
+
+func formatInTaskTZ(task *model.Task, t time.Time) string {
+	loc, err := cron.LoadLocation(task.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format("2006-01-02 15:04:05 MST")
+}
+
+// percentile 最近秩法（nearest-rank）计算分位数，p 取 (0, 1]。
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/notify"
+)
+
+// stubAlertDao 内存版 AlertDao；runs 为该任务的全部 Run（按 id 升序）。
+type stubAlertDao struct {
+	dao.AlertDao
+	rules     []*model.AlertRule
+	events    []*model.AlertEvent
+	runs      []*model.TaskRun
+	evaluated map[int64]bool
+}
+
+func (d *stubAlertDao) ListRules(_ context.Context, taskID int64) ([]*model.AlertRule, error) {
+	var out []*model.AlertRule
+	for _, r := range d.rules {
+		if taskID == 0 || r.TaskID == taskID {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+func (d *stubAlertDao) ListEnabledRulesByKind(_ context.Context, kind bizConsts.AlertKind) ([]*model.AlertRule, error) {
+	var out []*model.AlertRule
+	for _, r := range d.rules {
+		if r.Kind == kind && r.Enabled {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+func (d *stubAlertDao) CreateEvent(_ context.Context, ev *model.AlertEvent) error {
+	ev.ID = int64(len(d.events) + 1)
+	d.events = append(d.events, ev)
+	return nil
+}
+func (d *stubAlertDao) LastSent(_ context.Context, ruleID int64, key string) (*model.AlertEvent, error) {
+	for i := len(d.events) - 1; i >= 0; i-- {
+		if ev := d.events[i]; ev.RuleID == ruleID && ev.DedupKey == key && ev.Status == bizConsts.AlertSent {
+			return ev, nil
+		}
+	}
+	return nil, nil
+}
+func (d *stubAlertDao) HasEvent(_ context.Context, ruleID int64, key string) (bool, error) {
+	for _, ev := range d.events {
+		if ev.RuleID == ruleID && ev.DedupKey == key {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+func (d *stubAlertDao) ListPending(_ context.Context, limit int) ([]*model.TaskRun, error) {
+	var out []*model.TaskRun
+	for _, r := range d.runs {
+		if r.Status.Finished() && !d.evaluated[r.ID] {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+func (d *stubAlertDao) MarkEvaluated(_ context.Context, ids []int64) error {
+	for _, id := range ids {
+		d.evaluated[id] = true
+	}
+	return nil
+}
+func (d *stubAlertDao) RecentEffective(_ context.Context, taskID, uptoID int64, limit int) ([]*model.TaskRun, error) {
+	superseded := func(r *model.TaskRun) bool {
+		root := r.ID
+		if r.RetryOf != nil {
+			root = *r.RetryOf
+		}
+		for _, c := range d.runs {
+			if c.RetryOf != nil && *c.RetryOf == root && c.RetryIndex > r.RetryIndex {
+				return true
+			}
+		}
+		return false
+	}
+	var out []*model.TaskRun
+	for i := len(d.runs) - 1; i >= 0 && len(out) < limit; i-- {
+		r := d.runs[i]
+		if r.TaskID != taskID || r.ID > uptoID || r.NextRetryTime != nil || superseded(r) {
+			continue
+		}
+		if r.Status == bizConsts.Success || r.Status == bizConsts.Canceled || r.Status.IsFailure() {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+func (d *stubAlertDao) SuccessDurations(_ context.Context, taskID, beforeID int64, limit int) ([]float64, error) {
+	var out []float64
+	for i := len(d.runs) - 1; i >= 0 && len(out) < limit; i-- {
+		r := d.runs[i]
+		if r.TaskID == taskID && r.ID < beforeID && r.Status == bizConsts.Success && r.StartTime != nil && r.EndTime != nil {
+			out = append(out, r.EndTime.Sub(*r.StartTime).Seconds())
+		}
+	}
+	return out, nil
+}
+func (d *stubAlertDao) ListSLAMissed(_ context.Context, taskID int64, sla time.Duration, since, until time.Time, limit int) ([]*model.TaskRun, error) {
+	var out []*model.TaskRun
+	for _, r := range d.runs {
+		if r.TaskID != taskID || r.RetryOf != nil || r.ScheduledTime.Before(since) || r.ScheduledTime.After(until) {
+			continue
+		}
+		met := false
+		for _, s := range d.runs {
+			if (s.ID == r.ID || (s.RetryOf != nil && *s.RetryOf == r.ID)) && s.Status == bizConsts.Success &&
+				s.EndTime != nil && !s.EndTime.After(r.ScheduledTime.Add(sla)) {
+				met = true
+			}
+		}
+		if !met {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (d *stubAlertDao) add(r *model.TaskRun) *model.TaskRun {
+	r.ID = int64(len(d.runs) + 1)
+	if r.TaskID == 0 {
+		r.TaskID = 1
+	}
+	d.runs = append(d.runs, r)
+	return r
+}
+
+func (d *stubAlertDao) statuses(kind bizConsts.AlertKind) []bizConsts.AlertStatus {
+	var out []bizConsts.AlertStatus
+	for _, ev := range d.events {
+		if ev.Kind == kind {
+			out = append(out, ev.Status)
+		}
+	}
+	return out
+}
+
+type recordingNotifier struct {
+	msgs []*notify.Message
+	err  error
+}
+
+func (n *recordingNotifier) Name() string { return "rec" }
+func (n *recordingNotifier) Type() string { return bizConsts.AlertChannelFile }
+func (n *recordingNotifier) Send(_ context.Context, msg *notify.Message) error {
+	n.msgs = append(n.msgs, msg)
+	return n.err
+}
+
+func newTestAlertManager(t *testing.T, d *stubAlertDao) (*AlertManager, *recordingNotifier) {
+	ts := NewTaskService()
+	ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{1: {ID: 1, Name: "daily_bars", Status: bizConsts.ENABLED, Timezone: "Asia/Shanghai"}}}
+	if err := ts.Start(context.Background()); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	rec := &recordingNotifier{}
+	m := NewAlertManager(config.AlertConfig{})
+	m.AlertDao, m.TaskSvc = d, ts
+	m.notifiers = map[string]notify.Notifier{"rec": rec}
+	return m, rec
+}
+
+// settle 评估两轮：第一轮只记下新结束的 Run，第二轮才评估。
+func settle(ctx context.Context, m *AlertManager) {
+	m.evaluate(ctx)
+	m.evaluate(ctx)
+}
+
+func TestAlertFailureFinalOnlyAndSuppressed(t *testing.T) {
+	ctx := context.Background()
+	d := &stubAlertDao{evaluated: map[int64]bool{}, rules: []*model.AlertRule{
+		{ID: 1, TaskID: 1, Kind: bizConsts.AlertFailure, Channels: []string{"rec", "missing"}, Enabled: true},
+		{ID: 2, TaskID: 1, Kind: bizConsts.AlertRecovery, Channels: []string{"rec"}, Enabled: true},
+	}}
+	m, rec := newTestAlertManager(t, d)
+
+	// 第一次失败已被重试取代，只有重试链最后一次失败告警
+	first := d.add(&model.TaskRun{Status: bizConsts.Failed, ErrorMessage: "boom"})
+	d.add(&model.TaskRun{Status: bizConsts.Timeout, RetryOf: &first.ID, RetryIndex: 1})
+	settle(ctx, m)
+	if len(rec.msgs) != 1 || rec.msgs[0].RunID != 2 || rec.msgs[0].Severity != severityCritical {
+		t.Fatalf("expected one failure alert for run 2, got %+v", rec.msgs)
+	}
+	if got := d.statuses(bizConsts.AlertFailure); len(got) != 2 || got[0] != bizConsts.AlertSent || got[1] != bizConsts.AlertFailed {
+		t.Fatalf("expected SENT on rec and FAILED on unknown channel, got %v", got)
+	}
+
+	// 抑制窗口内再次失败：不发送，记 SUPPRESSED；计划重试的失败不评估为最终失败
+	d.add(&model.TaskRun{Status: bizConsts.CallbackFailed})
+	d.add(&model.TaskRun{Status: bizConsts.Failed, NextRetryTime: &time.Time{}})
+	settle(ctx, m)
+	if len(rec.msgs) != 1 {
+		t.Fatalf("second failure should be suppressed, got %d messages", len(rec.msgs))
+	}
+	if got := d.statuses(bizConsts.AlertFailure); len(got) != 4 || got[2] != bizConsts.AlertSuppressed {
+		t.Fatalf("expected suppressed records, got %v", got)
+	}
+
+	// 成功后恢复告警
+	d.runs[3].NextRetryTime = nil
+	d.runs[3].Status = bizConsts.Canceled
+	d.add(&model.TaskRun{Status: bizConsts.Failed})
+	d.add(&model.TaskRun{Status: bizConsts.Success})
+	settle(ctx, m)
+	last := rec.msgs[len(rec.msgs)-1]
+	if last.Kind != string(bizConsts.AlertRecovery) || last.RunID != 6 {
+		t.Fatalf("expected recovery alert for run 6, got %+v", last)
+	}
+	for id := int64(1); id <= 6; id++ {
+		if !d.evaluated[id] {
+			t.Fatalf("run %d should be marked evaluated", id)
+		}
+	}
+}
+
+func TestAlertConsecutiveFailuresAndSlowRun(t *testing.T) {
+	ctx := context.Background()
+	d := &stubAlertDao{evaluated: map[int64]bool{}, rules: []*model.AlertRule{
+		{ID: 1, TaskID: 1, Kind: bizConsts.AlertConsecutiveFailures, Threshold: 3, Channels: []string{"rec"}, Enabled: true},
+		{ID: 2, TaskID: 1, Kind: bizConsts.AlertSlowRun, Factor: 2, MinSamples: 5, Channels: []string{"rec"}, Enabled: true},
+	}}
+	m, rec := newTestAlertManager(t, d)
+	base := time.Date(2026, 10, 8, 1, 0, 0, 0, time.UTC)
+	ok := func(sec int) {
+		st, et := base, base.Add(time.Duration(sec)*time.Second)
+		d.add(&model.TaskRun{Status: bizConsts.Success, StartTime: &st, EndTime: &et})
+	}
+	for i := 0; i < 20; i++ {
+		ok(9 + i%4)
+	}
+	ok(21) // P95=12，阈值 24
+	ok(30)
+	settle(ctx, m)
+	if len(rec.msgs) != 1 || rec.msgs[0].Kind != string(bizConsts.AlertSlowRun) || rec.msgs[0].RunID != 22 {
+		t.Fatalf("expected slow alert for run 22, got %+v", rec.msgs)
+	}
+
+	d.add(&model.TaskRun{Status: bizConsts.Failed})
+	d.add(&model.TaskRun{Status: bizConsts.Failed})
+	settle(ctx, m)
+	if len(rec.msgs) != 1 {
+		t.Fatalf("two failures should not reach threshold 3")
+	}
+	d.add(&model.TaskRun{Status: bizConsts.Skipped}) // 跳过不打断也不计入
+	d.add(&model.TaskRun{Status: bizConsts.FailedTimeout})
+	settle(ctx, m)
+	if len(rec.msgs) != 2 || rec.msgs[1].Kind != string(bizConsts.AlertConsecutiveFailures) || rec.msgs[1].RunID != 26 {
+		t.Fatalf("expected consecutive failures alert for run 26, got %+v", rec.msgs)
+	}
+}
+
+func TestAlertSLAMissedOncePerFire(t *testing.T) {
+	ctx := context.Background()
+	d := &stubAlertDao{evaluated: map[int64]bool{}, rules: []*model.AlertRule{
+		{ID: 1, TaskID: 1, Kind: bizConsts.AlertSLAMissed, SLASec: 600, Channels: []string{"rec"}, Enabled: true},
+	}}
+	m, rec := newTestAlertManager(t, d)
+	now := time.Now().UTC()
+	late := now.Add(-5 * time.Minute)
+	d.add(&model.TaskRun{ScheduledTime: now.Add(-time.Hour), Status: bizConsts.Success, EndTime: &late}) // 成功但超过截止
+	inTime := now.Add(-50 * time.Minute)
+	d.add(&model.TaskRun{ScheduledTime: now.Add(-time.Hour), Status: bizConsts.Success, EndTime: &inTime})
+	d.add(&model.TaskRun{ScheduledTime: now.Add(-30 * time.Minute), Status: bizConsts.Running})
+	d.add(&model.TaskRun{ScheduledTime: now.Add(-time.Minute), Status: bizConsts.Running}) // 未到截止
+
+	m.checkSLA(ctx)
+	m.checkSLA(ctx)
+	var ids []int64
+	for _, msg := range rec.msgs {
+		ids = append(ids, msg.RunID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Fatalf("expected SLA alerts for runs 1 and 3 once each, got %v", ids)
+	}
+}
+
+func TestAlertValidateRule(t *testing.T) {
+	m, _ := newTestAlertManager(t, &stubAlertDao{})
+	slow := &model.AlertRule{Kind: bizConsts.AlertSlowRun, Channels: []string{"rec"}}
+	if err := m.ValidateRule(slow); err != nil || slow.Factor != defaultSlowFactor || slow.MinSamples != defaultSlowMinSamples {
+		t.Fatalf("slow defaults not applied: %v %+v", err, slow)
+	}
+	bad := []*model.AlertRule{
+		{Kind: "NOPE", Channels: []string{"rec"}},
+		{Kind: bizConsts.AlertConsecutiveFailures, Threshold: 1, Channels: []string{"rec"}},
+		{Kind: bizConsts.AlertSLAMissed, Channels: []string{"rec"}},
+		{Kind: bizConsts.AlertFailure, Channels: []string{"missing"}},
+		{Kind: bizConsts.AlertFailure}, // 无渠道且无默认渠道
+	}
+	for i, r := range bad {
+		if err := m.ValidateRule(r); !errors.Is(err, ErrInvalidAlertRule) {
+			t.Fatalf("case %d: expected invalid rule, got %v", i, err)
+		}
+	}
+	if _, err := buildNotifiers(map[string]config.AlertChannelConfig{"x": {Type: "pager"}}); err == nil {
+		t.Fatal("unknown channel type should be rejected")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	vals := []float64{5, 1, 4, 2, 3, 10, 9, 8, 7, 6}
+	if p := percentile(vals, 0.95); p != 10 {
+		t.Fatalf("p95 got %v", p)
+	}
+	if p := percentile(vals, 0.5); p != 5 {
+		t.Fatalf("p50 got %v", p)
+	}
+}
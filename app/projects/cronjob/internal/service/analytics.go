@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// 运行分析：
+//   - 在 task_runs 上按时间窗口（scheduled_time）聚合，不维护额外的统计表；每次尝试（含重试）计为一个 Run
+//   - 成功率 = 成功 / (成功 + 失败)，取消与跳过不计入
+//   - SLA 报告默认取任务已启用的 SLA_MISSED 告警规则的 sla_sec，口径与告警一致
+
+// ErrInvalidAnalytics 分析参数无效。
+var ErrInvalidAnalytics = errors.New("invalid analytics request")
+
+// 排行方式
+const (
+	TopSlowest  = "slowest"  // 按耗时 P95 降序
+	TopFailures = "failures" // 按失败率降序，失败率相同按失败次数
+)
+
+// maxAnalyticsDays 按天统计的最大窗口，避免补全出过长的序列。
+const maxAnalyticsDays = 366
+
+// AnalyticsService 运行分析与 SLA 报告。
+type AnalyticsService struct {
+	*core.BaseComponent
+	AnalyticsDao dao.AnalyticsDao `infra:"dep:analytics_dao"`
+	AlertDao     dao.AlertDao     `infra:"dep:alert_dao"`
+	TaskSvc      *TaskService     `infra:"dep:task_service"`
+}
+
+func NewAnalyticsService() *AnalyticsService {
+	return &AnalyticsService{BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_ANALYTICS)}
+}
+
+// TaskStats 各任务在窗口内的执行统计，按任务 ID 升序。
+func (s *AnalyticsService) TaskStats(ctx context.Context, f model.AnalyticsFilter) ([]*model.TaskRunAnalytics, error) {
+	list, err := s.AnalyticsDao.TaskStats(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.taskNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range list {
+		a.TaskName = names[a.TaskID]
+		a.SuccessRate = successRate(a.Succeeded, a.Failed)
+	}
+	return list, nil
+}
+
+// Daily 按 tz 时区逐日的成功率；窗口内没有 Run 的日期补 0。
+func (s *AnalyticsService) Daily(ctx context.Context, f model.AnalyticsFilter, tz string) ([]*model.DailyRunStats, error) {
+	if tz == "" {
+		tz = "UTC"
+	}
+	loc, err := cron.LoadLocation(tz)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidAnalytics, err)
+	}
+	if f.To.Sub(f.From) > maxAnalyticsDays*24*time.Hour {
+		return nil, errors.Join(ErrInvalidAnalytics, errors.New("window exceeds 366 days"))
+	}
+	list, err := s.AnalyticsDao.DailyStats(ctx, f, loc.String())
+	if err != nil {
+		return nil, err
+	}
+	return fillDays(list, f.From, f.To, loc), nil
+}
+
+// Top 最慢或最易失败的任务；Run 数少于 minRuns 的任务不参与排行。
+func (s *AnalyticsService) Top(ctx context.Context, f model.AnalyticsFilter, by string, limit, minRuns int) ([]*model.TaskRunAnalytics, error) {
+	if by != TopSlowest && by != TopFailures {
+		return nil, errors.Join(ErrInvalidAnalytics, errors.New("by must be slowest or failures"))
+	}
+	list, err := s.TaskStats(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	return rankTasks(list, by, limit, minRuns), nil
+}
+
+// SLA 窗口内各任务的 SLA 达成情况。slaSec > 0 时以其覆盖规则（须指定任务）；
+// 否则取已启用的 SLA_MISSED 规则，同一任务多条规则时各出一份报告。
+func (s *AnalyticsService) SLA(ctx context.Context, f model.AnalyticsFilter, slaSec int, now time.Time) ([]*model.SLAReport, error) {
+	type target struct {
+		taskID int64
+		sla    int
+	}
+	var targets []target
+	if slaSec > 0 {
+		if f.TaskID <= 0 {
+			return nil, errors.Join(ErrInvalidAnalytics, errors.New("sla_sec requires task_id"))
+		}
+		targets = append(targets, target{f.TaskID, slaSec})
+	} else {
+		rules, err := s.AlertDao.ListEnabledRulesByKind(ctx, bizConsts.AlertSLAMissed)
+		if err != nil {
+			return nil, err
+		}
+		seen := map[target]bool{}
+		for _, r := range rules {
+			t := target{r.TaskID, r.SLASec}
+			if r.SLASec <= 0 || (f.TaskID > 0 && r.TaskID != f.TaskID) || seen[t] {
+				continue
+			}
+			seen[t] = true
+			targets = append(targets, t)
+		}
+		sort.Slice(targets, func(i, j int) bool {
+			if targets[i].taskID != targets[j].taskID {
+				return targets[i].taskID < targets[j].taskID
+			}
+			return targets[i].sla < targets[j].sla
+		})
+	}
+	names, err := s.taskNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.SLAReport, 0, len(targets))
+	for _, t := range targets {
+		rep, err := s.AnalyticsDao.SLAStats(ctx, t.taskID, time.Duration(t.sla)*time.Second, f, now)
+		if err != nil {
+			return nil, err
+		}
+		rep.TaskName = names[t.taskID]
+		rep.Compliance = 1
+		if rep.Fires > 0 {
+			rep.Compliance = float64(rep.Met) / float64(rep.Fires)
+		}
+		out = append(out, rep)
+	}
+	return out, nil
+}
+
+func (s *AnalyticsService) taskNames(ctx context.Context) (map[int64]string, error) {
+	list, err := s.TaskSvc.ListFiltered(ctx, &model.TaskListFilters{}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(list))
+	for _, t := range list {
+		names[t.ID] = t.Name
+	}
+	return names, nil
+}
+
+func successRate(succeeded, failed int64) float64 {
+	if succeeded+failed == 0 {
+		return 0
+	}
+	return float64(succeeded) / float64(succeeded+failed)
+}
+
+// fillDays 补全 [from, to) 在 loc 时区覆盖的每一天，并计算成功率。
+func fillDays(list []*model.DailyRunStats, from, to time.Time, loc *time.Location) []*model.DailyRunStats {
+	byDate := make(map[string]*model.DailyRunStats, len(list))
+	for _, d := range list {
+		byDate[d.Date] = d
+	}
+	out := make([]*model.DailyRunStats, 0, len(list))
+	start := from.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format(time.DateOnly)
+		d := byDate[key]
+		if d == nil {
+			d = &model.DailyRunStats{Date: key}
+		}
+		d.SuccessRate = successRate(d.Succeeded, d.Failed)
+		out = append(out, d)
+	}
+	return out
+}
+
+// rankTasks 按 by 排序并截取前 limit 个；排序稳定（并列时按任务 ID）。
+func rankTasks(list []*model.TaskRunAnalytics, by string, limit, minRuns int) []*model.TaskRunAnalytics {
+	out := make([]*model.TaskRunAnalytics, 0, len(list))
+	for _, a := range list {
+		if a.Runs >= int64(minRuns) {
+			out = append(out, a)
+		}
+	}
+	failureRate := func(a *model.TaskRunAnalytics) float64 {
+		if a.Succeeded+a.Failed == 0 {
+			return 0
+		}
+		return 1 - a.SuccessRate
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		switch by {
+		case TopSlowest:
+			if a.DurationP95 != b.DurationP95 {
+				return a.DurationP95 > b.DurationP95
+			}
+		default:
+			if fa, fb := failureRate(a), failureRate(b); fa != fb {
+				return fa > fb
+			}
+			if a.Failed != b.Failed {
+				return a.Failed > b.Failed
+			}
+		}
+		return a.TaskID < b.TaskID
+	})
+	if by == TopFailures {
+		n := 0
+		for _, a := range out {
+			if a.Failed > 0 {
+				out[n] = a
+				n++
+			}
+		}
+		out = out[:n]
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+func TestFillDays(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip("tzdata unavailable")
+	}
+	// [2024-03-01 20:00 UTC, 2024-03-03 20:00 UTC) 在上海为 03-02 04:00 ~ 03-04 04:00
+	from := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+	to := from.Add(48 * time.Hour)
+	list := []*model.DailyRunStats{{Date: "2024-03-03", Runs: 4, Succeeded: 3, Failed: 1}}
+	out := fillDays(list, from, to, loc)
+	if len(out) != 3 || out[0].Date != "2024-03-02" || out[2].Date != "2024-03-04" {
+		t.Fatalf("unexpected days: %+v", out)
+	}
+	if out[0].Runs != 0 || out[0].SuccessRate != 0 || out[1].SuccessRate != 0.75 {
+		t.Fatalf("unexpected stats: %+v %+v", out[0], out[1])
+	}
+}
+
+func TestRankTasks(t *testing.T) {
+	list := []*model.TaskRunAnalytics{
+		{TaskID: 1, Runs: 10, Succeeded: 10, DurationP95: 3},
+		{TaskID: 2, Runs: 10, Succeeded: 5, Failed: 5, DurationP95: 30},
+		{TaskID: 3, Runs: 2, Failed: 2, DurationP95: 300}, // 样本不足
+		{TaskID: 4, Runs: 20, Succeeded: 10, Failed: 10, DurationP95: 8},
+	}
+	for _, a := range list {
+		a.SuccessRate = successRate(a.Succeeded, a.Failed)
+	}
+	slow := rankTasks(list, TopSlowest, 2, 5)
+	if len(slow) != 2 || slow[0].TaskID != 2 || slow[1].TaskID != 4 {
+		t.Fatalf("unexpected slowest: %+v", slow)
+	}
+	fail := rankTasks(list, TopFailures, 0, 5)
+	if len(fail) != 2 || fail[0].TaskID != 4 || fail[1].TaskID != 2 {
+		t.Fatalf("unexpected failures: %+v", fail)
+	}
+	if all := rankTasks(list, TopSlowest, 0, 0); len(all) != 4 || all[0].TaskID != 3 {
+		t.Fatalf("unexpected ranking without min_runs: %+v", all)
+	}
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// 历史区间补跑：
+//   - 按任务当前的 cron、时区与日历列出 [start, end] 内的触发点，每个触发点派发一个 Run（scheduled_time 即该触发点）
+//   - Run 归属批次（backfill_id），同一批次同一触发点只建一条；批次内未结束的 Run 不超过 max_parallel
+//   - Run 按需逐步创建（游标 last_fire_time），大区间不会一次性落库；多副本时仅 leader 派发，调度器暂停期间不派发
+//   - skip_succeeded 时跳过该触发点已有成功 Run 的时间（派发时再查一次，期间成功的也会跳过）
+
+var (
+	ErrInvalidBackfill  = errors.New("invalid backfill")
+	ErrBackfillNoCron   = errors.New("task has no cron schedule to backfill")
+	ErrBackfillEmpty    = errors.New("no fire times in range")
+	ErrBackfillFinished = errors.New("backfill already finished")
+)
+
+// BackfillRequest 补跑请求；区间两端均包含。
+type BackfillRequest struct {
+	Start         time.Time
+	End           time.Time
+	MaxParallel   int
+	SkipSucceeded bool
+}
+
+// BackfillFire 预览中的一个触发点。
+type BackfillFire struct {
+	ScheduledTime time.Time `json:"scheduled_time"`
+	Skip          bool      `json:"skip,omitempty"` // skip_succeeded 时该触发点已有成功 Run
+}
+
+// BackfillManager 创建、推进与取消补跑批次。
+type BackfillManager struct {
+	*core.BaseComponent
+	cfg         config.BackfillConfig
+	BackfillDao dao.BackfillDao        `infra:"dep:backfill_dao"`
+	TaskSvc     *TaskService           `infra:"dep:task_service"`
+	RunSvc      *RunService            `infra:"dep:run_service"`
+	Exec        *Executor              `infra:"dep:executor"`
+	Leader      *LeaderElector         `infra:"dep:scheduler_leader"`
+	Calendars   *CalendarService       `infra:"dep:calendar_service"`
+	Pause       *SchedulerPauseService `infra:"dep:scheduler_pause"`
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewBackfillManager(cfg config.BackfillConfig) *BackfillManager {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 2 * time.Second
+	}
+	if cfg.MaxRuns <= 0 {
+		cfg.MaxRuns = 1000
+	}
+	if cfg.MaxParallelCap <= 0 {
+		cfg.MaxParallelCap = 20
+	}
+	return &BackfillManager{BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_BACKFILL), cfg: cfg}
+}
+
+func (m *BackfillManager) Start(ctx context.Context) error {
+	if m.IsActive() {
+		return nil
+	}
+	if err := m.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	loopCtx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+				if m.Leader.IsLeader() && !m.Pause.Paused() { // 暂停期间不派发，恢复后从游标继续
+					m.tick(loopCtx)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (m *BackfillManager) Stop(ctx context.Context) error {
+	if !m.IsActive() {
+		return nil
+	}
+	if m.cancel != nil {
+		m.cancel()
+		<-m.done
+	}
+	return m.BaseComponent.Stop(ctx)
+}
+
+// Plan 列出区间内的触发点（dry-run 与创建共用），超过 max_runs 时报错。
+func (m *BackfillManager) Plan(ctx context.Context, task *model.Task, req BackfillRequest) ([]BackfillFire, error) {
+	if req.End.Before(req.Start) {
+		return nil, fmt.Errorf("%w: end must not be before start", ErrInvalidBackfill)
+	}
+	if req.MaxParallel < 1 || req.MaxParallel > m.cfg.MaxParallelCap {
+		return nil, fmt.Errorf("%w: max_parallel must be between 1 and %d", ErrInvalidBackfill, m.cfg.MaxParallelCap)
+	}
+	sched, loc, err := backfillSchedule(task, m.Calendars)
+	if err != nil {
+		return nil, err
+	}
+	var fires []BackfillFire
+	for t := nextFire(sched, loc, req.Start.Add(-time.Second)); !t.IsZero() && !t.After(req.End); t = nextFire(sched, loc, t) {
+		if len(fires) >= m.cfg.MaxRuns {
+			return nil, fmt.Errorf("%w: range has more than %d fire times", ErrInvalidBackfill, m.cfg.MaxRuns)
+		}
+		fires = append(fires, BackfillFire{ScheduledTime: t})
+	}
+	if len(fires) == 0 {
+		return nil, ErrBackfillEmpty
+	}
+	if req.SkipSucceeded {
+		done, err := m.succeededSet(ctx, task.ID, req.Start, req.End)
+		if err != nil {
+			return nil, err
+		}
+		for i := range fires {
+			fires[i].Skip = done[fires[i].ScheduledTime.Unix()]
+		}
+	}
+	return fires, nil
+}
+
+// Create 校验区间并创建批次，Run 由 leader 在后续周期中按并行度派发。
+func (m *BackfillManager) Create(ctx context.Context, task *model.Task, req BackfillRequest) (*model.Backfill, error) {
+	fires, err := m.Plan(ctx, task, req)
+	if err != nil {
+		return nil, err
+	}
+	b := &model.Backfill{
+		TaskID:        task.ID,
+		RangeStart:    req.Start.UTC(),
+		RangeEnd:      req.End.UTC(),
+		MaxParallel:   req.MaxParallel,
+		SkipSucceeded: req.SkipSucceeded,
+		Status:        bizConsts.BackfillRunning,
+		Total:         len(fires),
+	}
+	if err := m.BackfillDao.Create(ctx, b); err != nil {
+		return nil, err
+	}
+	logging.Info(ctx, fmt.Sprintf("backfill %d created task=%d range=[%s, %s] fires=%d max_parallel=%d", b.ID, task.ID,
+		b.RangeStart.Format(time.RFC3339), b.RangeEnd.Format(time.RFC3339), b.Total, b.MaxParallel))
+	return b, nil
+}
+
+// Cancel 整体取消批次：停止派发，取消未结束的 Run 并放弃已计划的重试。
+func (m *BackfillManager) Cancel(ctx context.Context, id int64) (*model.Backfill, error) {
+	b, err := m.BackfillDao.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := m.BackfillDao.Finish(ctx, id, bizConsts.BackfillCanceled)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return b, ErrBackfillFinished
+	}
+	runs, err := m.RunSvc.ListBackfillActive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		if run.NextRetryTime != nil {
+			_ = m.RunSvc.SetNextRetryTime(ctx, run.ID, nil)
+		}
+		if run.Status.Finished() {
+			continue
+		}
+		if _, err := m.Exec.Cancel(ctx, run, bizConsts.CancelReasonBackfill); err != nil {
+			logging.Error(ctx, fmt.Sprintf("backfill %d cancel run %d failed: %v", id, run.ID, err))
+		}
+	}
+	logging.Info(ctx, fmt.Sprintf("backfill %d canceled, %d runs stopped", id, len(runs)))
+	return m.BackfillDao.Get(ctx, id)
+}
+
+func (m *BackfillManager) tick(ctx context.Context) {
+	list, err := m.BackfillDao.ListRunning(ctx)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("backfill list running failed: %v", err))
+		return
+	}
+	for _, b := range list {
+		if err := m.advance(ctx, b); err != nil {
+			logging.Error(ctx, fmt.Sprintf("backfill %d advance failed: %v", b.ID, err))
+		}
+	}
+}
+
+// advance 按空闲并行度派发后续触发点；全部派发且 Run 均结束后置为 COMPLETED。
+func (m *BackfillManager) advance(ctx context.Context, b *model.Backfill) error {
+	task, err := m.TaskSvc.Get(ctx, b.TaskID)
+	if err != nil && !dao.IsNotFound(err) {
+		return err
+	}
+	if task == nil || task.Deleted != 0 { // 任务已删除：批次随之取消
+		_, ferr := m.BackfillDao.Finish(ctx, b.ID, bizConsts.BackfillCanceled)
+		logging.Warn(ctx, fmt.Sprintf("backfill %d canceled: task %d deleted", b.ID, b.TaskID))
+		return ferr
+	}
+	sched, loc, err := backfillSchedule(task, m.Calendars)
+	if err != nil {
+		return err
+	}
+	active, err := m.RunSvc.ListBackfillActive(ctx, b.ID)
+	if err != nil {
+		return err
+	}
+	after := b.RangeStart.Add(-time.Second)
+	if b.LastFireTime != nil {
+		after = *b.LastFireTime
+	}
+	var done map[int64]bool
+	if b.SkipSucceeded {
+		if done, err = m.succeededSet(ctx, task.ID, after, b.RangeEnd); err != nil {
+			return err
+		}
+	}
+	slots := b.MaxParallel - len(active)
+	dispatched := 0
+	next := nextFire(sched, loc, after)
+	for ; slots > 0 && m.pending(b, next); next = nextFire(sched, loc, next) {
+		if done[next.Unix()] {
+			b.Skipped++
+			b.LastFireTime = timePtr(next)
+			continue
+		}
+		id := b.ID
+		run := m.TaskSvc.CreateTaskRun(task, next, 1)
+		run.BackfillID, run.TriggerType = &id, bizConsts.TriggerBackfill
+		err := m.RunSvc.CreateScheduled(ctx, run)
+		if err != nil && !errors.Is(err, dao.ErrDuplicateRun) {
+			return err
+		}
+		b.Dispatched++
+		b.LastFireTime = timePtr(next)
+		if err == nil { // 重复说明上一轮已建但进度未落库
+			m.Exec.Enqueue(run)
+			dispatched++
+			slots--
+		}
+	}
+	ok, err := m.BackfillDao.UpdateProgress(ctx, b)
+	if err != nil || !ok { // 已被取消
+		return err
+	}
+	if dispatched > 0 {
+		logging.Info(ctx, fmt.Sprintf("backfill %d dispatched %d runs (%d/%d)", b.ID, dispatched, b.Dispatched+b.Skipped, b.Total))
+	}
+	if dispatched == 0 && len(active) == 0 && !m.pending(b, next) {
+		if ok, err := m.BackfillDao.Finish(ctx, b.ID, bizConsts.BackfillCompleted); err != nil || !ok {
+			return err
+		}
+		logging.Info(ctx, fmt.Sprintf("backfill %d completed dispatched=%d skipped=%d", b.ID, b.Dispatched, b.Skipped))
+	}
+	return nil
+}
+
+// pending 触发点 t 是否仍需处理：在区间内且未超过 max_runs（任务 cron 在补跑期间被修改时的保护）。
+func (m *BackfillManager) pending(b *model.Backfill, t time.Time) bool {
+	return !t.IsZero() && !t.After(b.RangeEnd) && b.Dispatched+b.Skipped < m.cfg.MaxRuns
+}
+
+func (m *BackfillManager) succeededSet(ctx context.Context, taskID int64, from, to time.Time) (map[int64]bool, error) {
+	times, err := m.RunSvc.ListSucceededTimes(ctx, taskID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[int64]bool, len(times))
+	for _, t := range times {
+		set[t.Unix()] = true
+	}
+	return set, nil
+}
+
+func backfillSchedule(task *model.Task, cals *CalendarService) (cron.Schedule, *time.Location, error) {
+	if task.CronExpr == "" {
+		return nil, nil, ErrBackfillNoCron
+	}
+	loc, err := cron.LoadLocation(task.Timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidBackfill, err)
+	}
+	sched, err := cron.Parse(task.CronExpr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidBackfill, err)
+	}
+	if sched, err = cals.Schedule(task, sched); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidBackfill, err)
+	}
+	return sched, loc, nil
+}
+
+// nextFire 严格晚于 after 的下一个触发点（UTC），永不触发时返回零值。
+func nextFire(sched cron.Schedule, loc *time.Location, after time.Time) time.Time {
+	t := sched.Next(after.In(loc))
+	if t.IsZero() {
+		return t
+	}
+	return t.UTC()
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
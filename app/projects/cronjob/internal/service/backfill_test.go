@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+func (r *stubRunDao) ListBackfillActive(_ context.Context, backfillID int64) ([]*model.TaskRun, error) {
+	var out []*model.TaskRun
+	for _, ru := range r.runs {
+		if ru.BackfillID != nil && *ru.BackfillID == backfillID && (!ru.Status.Finished() || ru.NextRetryTime != nil) {
+			out = append(out, ru)
+		}
+	}
+	return out, nil
+}
+func (r *stubRunDao) ListSucceededTimes(_ context.Context, taskID int64, from, to time.Time) ([]time.Time, error) {
+	var out []time.Time
+	for _, ru := range r.runs {
+		if ru.TaskID == taskID && ru.Status == bizConsts.Success && !ru.ScheduledTime.Before(from) && !ru.ScheduledTime.After(to) {
+			out = append(out, ru.ScheduledTime)
+		}
+	}
+	return out, nil
+}
+
+// stubBackfillDao 内存版 BackfillDao
+type stubBackfillDao struct {
+	dao.BackfillDao
+	items map[int64]*model.Backfill
+}
+
+func (d *stubBackfillDao) Create(_ context.Context, b *model.Backfill) error {
+	b.ID = int64(len(d.items) + 1)
+	d.items[b.ID] = b
+	return nil
+}
+func (d *stubBackfillDao) Get(_ context.Context, id int64) (*model.Backfill, error) {
+	if b, ok := d.items[id]; ok {
+		cp := *b
+		return &cp, nil
+	}
+	return nil, dao.ErrBackfillNotFound
+}
+func (d *stubBackfillDao) UpdateProgress(_ context.Context, b *model.Backfill) (bool, error) {
+	cur := d.items[b.ID]
+	if cur.Status != bizConsts.BackfillRunning {
+		return false, nil
+	}
+	cur.LastFireTime, cur.Dispatched, cur.Skipped = b.LastFireTime, b.Dispatched, b.Skipped
+	return true, nil
+}
+func (d *stubBackfillDao) Finish(_ context.Context, id int64, status bizConsts.BackfillStatus) (bool, error) {
+	cur := d.items[id]
+	if cur.Status != bizConsts.BackfillRunning {
+		return false, nil
+	}
+	cur.Status = status
+	return true, nil
+}
+func (d *stubBackfillDao) ListRunning(_ context.Context) ([]*model.Backfill, error) {
+	var out []*model.Backfill
+	for _, b := range d.items {
+		if b.Status == bizConsts.BackfillRunning {
+			cp := *b
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+func TestBackfillDispatchesWithinParallelism(t *testing.T) {
+	ctx := context.Background()
+	task := &model.Task{ID: 11, TargetService: "artemis", Status: bizConsts.ENABLED, CronExpr: "0 0 * * * *"}
+	ts := NewTaskService()
+	ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{11: task}}
+	if err := ts.Start(ctx); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	runDao := &stubRunDao{}
+	bfDao := &stubBackfillDao{items: map[int64]*model.Backfill{}}
+	m := NewBackfillManager(config.BackfillConfig{})
+	m.BackfillDao, m.TaskSvc, m.RunSvc, m.Exec = bfDao, ts, &RunService{RunDao: runDao}, NewExecutor(config.ExecutorConfig{})
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	done := &model.TaskRun{TaskID: 11, ScheduledTime: start.Add(2 * time.Hour), Status: bizConsts.Success}
+	_ = runDao.CreateScheduled(ctx, done)
+
+	req := BackfillRequest{Start: start, End: start.Add(5 * time.Hour), MaxParallel: 2, SkipSucceeded: true}
+	fires, err := m.Plan(ctx, task, req)
+	if err != nil || len(fires) != 6 || !fires[2].Skip || fires[3].Skip {
+		t.Fatalf("unexpected plan %v %+v", err, fires)
+	}
+	if _, err := m.Plan(ctx, task, BackfillRequest{Start: start, End: start, MaxParallel: 0}); !errors.Is(err, ErrInvalidBackfill) {
+		t.Fatalf("expected invalid max_parallel, got %v", err)
+	}
+	b, err := m.Create(ctx, task, req)
+	if err != nil || b.Total != 6 {
+		t.Fatalf("create failed: %v %+v", err, b)
+	}
+
+	step := func() *model.Backfill {
+		cur, _ := bfDao.Get(ctx, b.ID)
+		if err := m.advance(ctx, cur); err != nil {
+			t.Fatalf("advance failed: %v", err)
+		}
+		cur, _ = bfDao.Get(ctx, b.ID)
+		return cur
+	}
+	finishAll := func() {
+		for _, ru := range runDao.runs {
+			if ru.BackfillID != nil && ru.Status == bizConsts.Scheduled {
+				ru.Status = bizConsts.Success
+			}
+		}
+	}
+	backfillTimes := func() []time.Time {
+		var out []time.Time
+		for _, ru := range runDao.runs {
+			if ru.BackfillID != nil {
+				out = append(out, ru.ScheduledTime)
+			}
+		}
+		return out
+	}
+
+	step()
+	step() // 并行度已满，不再派发
+	if got := backfillTimes(); len(got) != 2 || !got[0].Equal(start) || !got[1].Equal(start.Add(time.Hour)) {
+		t.Fatalf("expected first two fire times, got %v", got)
+	}
+	if run := runDao.runs[1]; run.TriggerType != bizConsts.TriggerBackfill || *run.BackfillID != b.ID {
+		t.Fatalf("unexpected backfill run %+v", run)
+	}
+	finishAll()
+	cur := step() // 02:00 已成功被跳过
+	if got := backfillTimes(); len(got) != 4 || !got[2].Equal(start.Add(3*time.Hour)) || cur.Skipped != 1 {
+		t.Fatalf("expected 03:00 and 04:00 after skip, got %v skipped=%d", got, cur.Skipped)
+	}
+	finishAll()
+	step()
+	finishAll()
+	cur = step()
+	if cur.Status != bizConsts.BackfillCompleted || cur.Dispatched != 5 || cur.Skipped != 1 {
+		t.Fatalf("expected completed backfill, got %+v", cur)
+	}
+}
+
+func TestBackfillCancel(t *testing.T) {
+	ctx := context.Background()
+	task := &model.Task{ID: 12, TargetService: "artemis", Status: bizConsts.ENABLED, CronExpr: "@every 1m"}
+	ts := NewTaskService()
+	ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{12: task}}
+	if err := ts.Start(ctx); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	runDao := &stubRunDao{}
+	bfDao := &stubBackfillDao{items: map[int64]*model.Backfill{}}
+	m := NewBackfillManager(config.BackfillConfig{MaxRuns: 100})
+	m.BackfillDao, m.TaskSvc, m.RunSvc, m.Exec = bfDao, ts, &RunService{RunDao: runDao}, NewExecutor(config.ExecutorConfig{})
+	m.Exec.RunSvc = m.RunSvc // Cancel 经 Executor.Cancel 把排队中的 Run 标记为取消
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := m.Plan(ctx, task, BackfillRequest{Start: start, End: start.Add(3 * time.Hour), MaxParallel: 1}); !errors.Is(err, ErrInvalidBackfill) {
+		t.Fatalf("expected max_runs error, got %v", err)
+	}
+	b, err := m.Create(ctx, task, BackfillRequest{Start: start, End: start.Add(10 * time.Minute), MaxParallel: 3})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	cur, _ := bfDao.Get(ctx, b.ID)
+	_ = m.advance(ctx, cur)
+	if len(runDao.runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(runDao.runs))
+	}
+	canceled, err := m.Cancel(ctx, b.ID)
+	if err != nil || canceled.Status != bizConsts.BackfillCanceled {
+		t.Fatalf("cancel failed: %v %+v", err, canceled)
+	}
+	for _, ru := range runDao.runs {
+		if ru.Status != bizConsts.Canceled || ru.CancelReason != bizConsts.CancelReasonBackfill {
+			t.Fatalf("run %d not canceled: %s %s", ru.ID, ru.Status, ru.CancelReason)
+		}
+	}
+	if _, err := m.Cancel(ctx, b.ID); !errors.Is(err, ErrBackfillFinished) {
+		t.Fatalf("expected finished error, got %v", err)
+	}
+	m.tick(ctx)
+	if len(runDao.runs) != 3 {
+		t.Fatalf("canceled backfill must not dispatch, got %d runs", len(runDao.runs))
+	}
+}
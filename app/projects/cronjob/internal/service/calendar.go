@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/calendar"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// 营业日历与停摆窗口：
+//   - 日历存于数据库，启动时从 calendar.dir 下的 YAML 文件导入（仅新建或更新来源仍为 FILE 的日历），
+//     经 API 修改后来源变为 API，不再被文件覆盖
+//   - 每个副本在内存中缓存日历与未结束的停摆窗口，写操作后立即重载，并周期性刷新以获取其他副本的修改
+//   - 任务引用的日历不存在时不做 cron 触发（记录错误），避免在休市日误跑
+
+var (
+	ErrInvalidCalendar = errors.New("invalid calendar")
+	ErrCalendarInUse   = errors.New("calendar is referenced by tasks")
+	ErrInvalidBlackout = errors.New("invalid blackout window")
+)
+
+// blackoutRetention 已结束的停摆窗口仍保留在缓存中的时长，覆盖 misfire 补偿回溯的触发点。
+const blackoutRetention = 24 * time.Hour
+
+// calendarFile 日历文件格式。
+type calendarFile struct {
+	Name        string                 `yaml:"name"`
+	Description string                 `yaml:"description"`
+	Kind        bizConsts.CalendarKind `yaml:"kind"`
+	Dates       []string               `yaml:"dates"`
+}
+
+// CalendarService 日历与停摆窗口的缓存与维护。
+type CalendarService struct {
+	*core.BaseComponent
+	cfg         config.CalendarConfig
+	CalendarDao dao.CalendarDao `infra:"dep:calendar_dao"`
+
+	mu        sync.RWMutex
+	cals      map[string]*calendar.Calendar
+	versions  map[string]int
+	blackouts []*model.BlackoutWindow
+	gen       int64 // 日历内容每变化一次 +1，调度计划据此判断是否需要重新包装
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewCalendarService(cfg config.CalendarConfig) *CalendarService {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = 30 * time.Second
+	}
+	return &CalendarService{BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_CALENDAR), cfg: cfg}
+}
+
+func (s *CalendarService) Start(ctx context.Context) error {
+	if s.IsActive() {
+		return nil
+	}
+	if err := s.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	if err := s.importDir(ctx); err != nil {
+		return err
+	}
+	if err := s.Reload(ctx); err != nil {
+		return fmt.Errorf("load calendars failed: %w", err)
+	}
+	loopCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.cfg.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Reload(loopCtx); err != nil {
+					logging.Error(loopCtx, fmt.Sprintf("refresh calendars failed: %v", err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *CalendarService) Stop(ctx context.Context) error {
+	if !s.IsActive() {
+		return nil
+	}
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+	return s.BaseComponent.Stop(ctx)
+}
+
+// importDir 导入日历文件；目录不存在时忽略，文件格式错误时启动失败。
+func (s *CalendarService) importDir(ctx context.Context) error {
+	if s.cfg.Dir == "" {
+		return nil
+	}
+	files, err := filepath.Glob(filepath.Join(s.cfg.Dir, "*.yaml"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("read calendar file %s: %w", f, err)
+		}
+		var cf calendarFile
+		if err := yaml.Unmarshal(raw, &cf); err != nil {
+			return fmt.Errorf("parse calendar file %s: %w", f, err)
+		}
+		c := &model.Calendar{Name: cf.Name, Description: cf.Description, Kind: cf.Kind, Dates: cf.Dates, Source: bizConsts.CalendarSourceFile}
+		if err := normalizeCalendar(c); err != nil {
+			return fmt.Errorf("calendar file %s: %w", f, err)
+		}
+		cur, err := s.CalendarDao.Get(ctx, c.Name)
+		switch {
+		case errors.Is(err, dao.ErrCalendarNotFound):
+		case err != nil:
+			return err
+		case cur.Source != bizConsts.CalendarSourceFile:
+			logging.Info(ctx, fmt.Sprintf("calendar %s maintained via API, file %s ignored", c.Name, f))
+			continue
+		case cur.Kind == c.Kind && cur.Description == c.Description && slices.Equal(cur.Dates, c.Dates):
+			continue
+		}
+		if err := s.CalendarDao.Save(ctx, c); err != nil {
+			return fmt.Errorf("import calendar %s: %w", c.Name, err)
+		}
+		logging.Info(ctx, fmt.Sprintf("imported calendar %s from %s version=%d dates=%d", c.Name, f, c.Version, len(c.Dates)))
+	}
+	return nil
+}
+
+// Reload 从数据库重载日历与停摆窗口。
+func (s *CalendarService) Reload(ctx context.Context) error {
+	list, err := s.CalendarDao.List(ctx)
+	if err != nil {
+		return err
+	}
+	blackouts, err := s.CalendarDao.ListBlackouts(ctx, time.Now().Add(-blackoutRetention))
+	if err != nil {
+		return err
+	}
+	cals := make(map[string]*calendar.Calendar, len(list))
+	versions := make(map[string]int, len(list))
+	for _, c := range list {
+		cal, err := calendar.New(c.Name, c.Kind, c.Dates)
+		if err != nil {
+			logging.Error(ctx, fmt.Sprintf("calendar %s invalid: %v", c.Name, err))
+			continue
+		}
+		cals[c.Name] = cal
+		versions[c.Name] = c.Version
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !maps.Equal(versions, s.versions) {
+		s.gen++
+	}
+	s.cals, s.versions, s.blackouts = cals, versions, blackouts
+	return nil
+}
+
+// Generation 日历内容的代数；s 为 nil（未装配日历服务）时恒为 0。
+func (s *CalendarService) Generation() int64 {
+	if s == nil {
+		return 0
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.gen
+}
+
+// Calendar 按名称取缓存中的日历。
+func (s *CalendarService) Calendar(name string) (*calendar.Calendar, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.cals[name]
+	return c, ok
+}
+
+// Schedule 按任务的 calendar / calendar_mode 包装 cron 调度；任务未引用日历时原样返回。
+func (s *CalendarService) Schedule(task *model.Task, base cron.Schedule) (cron.Schedule, error) {
+	if task.Calendar == "" {
+		return base, nil
+	}
+	cal, ok := s.Calendar(task.Calendar)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", dao.ErrCalendarNotFound, task.Calendar)
+	}
+	mode := task.CalendarMode
+	if mode == "" {
+		mode = bizConsts.DEFAULT_CALENDAR_MODE
+	}
+	return calendar.Wrap(base, cal, mode), nil
+}
+
+// Blackout 返回覆盖任务在 t 时刻触发的停摆窗口，没有则返回 nil。
+func (s *CalendarService) Blackout(taskID int64, t time.Time) *model.BlackoutWindow {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, b := range s.blackouts {
+		if b.Covers(taskID, t) {
+			return b
+		}
+	}
+	return nil
+}
+
+func (s *CalendarService) List(ctx context.Context) ([]*model.Calendar, error) {
+	return s.CalendarDao.List(ctx)
+}
+
+func (s *CalendarService) Get(ctx context.Context, name string) (*model.Calendar, error) {
+	return s.CalendarDao.Get(ctx, name)
+}
+
+// Save 经 API 新建或整体替换日历。
+func (s *CalendarService) Save(ctx context.Context, c *model.Calendar) error {
+	c.Source = bizConsts.CalendarSourceAPI
+	if err := normalizeCalendar(c); err != nil {
+		return err
+	}
+	if err := s.CalendarDao.Save(ctx, c); err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+// UpdateDates 增删日历中的日期（如追加来年节假日）。
+func (s *CalendarService) UpdateDates(ctx context.Context, name string, add, remove []string) (*model.Calendar, error) {
+	add, err := calendar.NormalizeDates(add)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+	remove, err = calendar.NormalizeDates(remove)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+	c, err := s.CalendarDao.UpdateDates(ctx, name, add, remove)
+	if err != nil {
+		return nil, err
+	}
+	return c, s.Reload(ctx)
+}
+
+// Delete 删除未被任务引用的日历。
+func (s *CalendarService) Delete(ctx context.Context, name string) error {
+	n, err := s.CalendarDao.CountTasksUsing(ctx, name)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("%w: %d task(s)", ErrCalendarInUse, n)
+	}
+	if err := s.CalendarDao.Delete(ctx, name); err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+// ListBlackouts 返回未结束的停摆窗口。
+func (s *CalendarService) ListBlackouts(ctx context.Context) ([]*model.BlackoutWindow, error) {
+	return s.CalendarDao.ListBlackouts(ctx, time.Now())
+}
+
+func (s *CalendarService) CreateBlackout(ctx context.Context, b *model.BlackoutWindow) error {
+	if b.Name == "" {
+		return fmt.Errorf("%w: name required", ErrInvalidBlackout)
+	}
+	if !b.StartTime.Before(b.EndTime) {
+		return fmt.Errorf("%w: start_time must be before end_time", ErrInvalidBlackout)
+	}
+	b.StartTime, b.EndTime = b.StartTime.UTC(), b.EndTime.UTC()
+	if err := s.CalendarDao.CreateBlackout(ctx, b); err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+func (s *CalendarService) DeleteBlackout(ctx context.Context, id int64) error {
+	if err := s.CalendarDao.DeleteBlackout(ctx, id); err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+// normalizeCalendar 校验名称与类型，日期去重排序。
+func normalizeCalendar(c *model.Calendar) error {
+	if c.Name == "" || len(c.Name) > 64 {
+		return fmt.Errorf("%w: name required (max 64 chars)", ErrInvalidCalendar)
+	}
+	if c.Kind == "" {
+		c.Kind = bizConsts.CalendarKindHolidays
+	}
+	if c.Kind != bizConsts.CalendarKindHolidays && c.Kind != bizConsts.CalendarKindTradingDays {
+		return fmt.Errorf("%w: kind must be HOLIDAYS or TRADING_DAYS", ErrInvalidCalendar)
+	}
+	dates, err := calendar.NormalizeDates(c.Dates)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+	c.Dates = dates
+	return nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// stubCalendarDao 内存版 CalendarDao
+type stubCalendarDao struct {
+	dao.CalendarDao
+	cals      map[string]*model.Calendar
+	blackouts []*model.BlackoutWindow
+	inUse     int64
+}
+
+func (d *stubCalendarDao) List(_ context.Context) ([]*model.Calendar, error) {
+	var out []*model.Calendar
+	for _, c := range d.cals {
+		cp := *c
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+func (d *stubCalendarDao) Get(_ context.Context, name string) (*model.Calendar, error) {
+	if c, ok := d.cals[name]; ok {
+		cp := *c
+		return &cp, nil
+	}
+	return nil, dao.ErrCalendarNotFound
+}
+func (d *stubCalendarDao) Save(_ context.Context, c *model.Calendar) error {
+	c.Version = 1
+	if cur, ok := d.cals[c.Name]; ok {
+		c.Version = cur.Version + 1
+	}
+	cp := *c
+	d.cals[c.Name] = &cp
+	return nil
+}
+func (d *stubCalendarDao) Delete(_ context.Context, name string) error {
+	delete(d.cals, name)
+	return nil
+}
+func (d *stubCalendarDao) CountTasksUsing(_ context.Context, _ string) (int64, error) {
+	return d.inUse, nil
+}
+func (d *stubCalendarDao) ListBlackouts(_ context.Context, after time.Time) ([]*model.BlackoutWindow, error) {
+	var out []*model.BlackoutWindow
+	for _, b := range d.blackouts {
+		if b.EndTime.After(after) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func TestEngineCalendarAndBlackout(t *testing.T) {
+	ctx := context.Background()
+	cursor := time.Date(2026, 9, 30, 2, 0, 0, 0, time.UTC) // 09-30 10:00 CST
+	now := time.Date(2026, 10, 9, 2, 0, 0, 0, time.UTC)    // 10-09 10:00 CST
+	calDao := &stubCalendarDao{cals: map[string]*model.Calendar{
+		"SSE": {Name: "SSE", Kind: bizConsts.CalendarKindHolidays, Version: 1,
+			Dates: []string{"2026-10-01", "2026-10-02", "2026-10-05", "2026-10-06", "2026-10-07"}},
+	}}
+	// 自 10-09 00:00 UTC 起全局停摆（持续到当前时间之后，避免被缓存的保留期过滤）
+	calDao.blackouts = []*model.BlackoutWindow{{ID: 1, Name: "release", StartTime: now.Add(-2 * time.Hour), EndTime: time.Now().Add(time.Hour)}}
+	cals := NewCalendarService(config.CalendarConfig{})
+	cals.CalendarDao = calDao
+	if err := cals.Reload(ctx); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	newTask := func(id int64, calendar string, mode bizConsts.CalendarMode) *model.Task {
+		return &model.Task{ID: id, CronExpr: "0 30 9 * * *", Timezone: "Asia/Shanghai", TargetService: "artemis", Status: bizConsts.ENABLED,
+			OverlapAction: bizConsts.OverlapActionAllow, FailureAction: bizConsts.FailureActionRunNew,
+			MisfirePolicy: bizConsts.MisfireFireAll, LastEvaluatedAt: &cursor, Calendar: calendar, CalendarMode: mode}
+	}
+	cases := []struct {
+		task *model.Task
+		want []string // scheduled times (UTC MM-DD)
+	}{
+		{newTask(21, "SSE", bizConsts.CalendarModeBusinessDays), []string{"10-08"}},
+		{newTask(22, "SSE", bizConsts.CalendarModeNonBusinessDays), []string{"10-01", "10-02", "10-03", "10-04", "10-05", "10-06", "10-07"}},
+		{newTask(23, "SSE", bizConsts.CalendarModeNextBusinessDay), []string{"10-08"}},
+		{newTask(24, "MISSING", bizConsts.CalendarModeBusinessDays), nil},
+	}
+	for _, c := range cases {
+		ts := NewTaskService()
+		ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{c.task.ID: c.task}}
+		if err := ts.Start(ctx); err != nil {
+			t.Fatalf("start failed: %v", err)
+		}
+		runDao := &stubRunDao{}
+		e := NewEngine(config.SchedulerConfig{PollInterval: time.Minute, MisfireMaxLookback: 10 * 24 * time.Hour})
+		e.TaskSvc, e.RunDao, e.Exec, e.Calendars = ts, runDao, NewExecutor(config.ExecutorConfig{}), cals
+		if err := e.scan(ctx, now); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		var got []string
+		for _, r := range runDao.runs {
+			got = append(got, r.ScheduledTime.Format("01-02"))
+		}
+		if len(got) != len(c.want) {
+			t.Fatalf("task %d %s: runs %v, want %v", c.task.ID, c.task.CalendarMode, got, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("task %d %s: runs %v, want %v", c.task.ID, c.task.CalendarMode, got, c.want)
+			}
+		}
+	}
+}
+
+func TestCalendarServiceImportAndDelete(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	file := "name: SSE\nkind: HOLIDAYS\ndates:\n  - 2027-01-01\n  - 2026-10-01\n  - 2026-10-01\n"
+	if err := os.WriteFile(filepath.Join(dir, "sse.yaml"), []byte(file), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	calDao := &stubCalendarDao{cals: map[string]*model.Calendar{
+		"CFFEX": {Name: "CFFEX", Kind: bizConsts.CalendarKindHolidays, Source: bizConsts.CalendarSourceAPI, Version: 3},
+	}}
+	if err := os.WriteFile(filepath.Join(dir, "cffex.yaml"), []byte("name: CFFEX\ndates: [2026-10-01]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := NewCalendarService(config.CalendarConfig{Dir: dir})
+	s.CalendarDao = calDao
+	if err := s.importDir(ctx); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if c := calDao.cals["SSE"]; c == nil || c.Source != bizConsts.CalendarSourceFile || len(c.Dates) != 2 || c.Dates[0] != "2026-10-01" {
+		t.Fatalf("unexpected imported calendar %+v", c)
+	}
+	if c := calDao.cals["CFFEX"]; c.Version != 3 || len(c.Dates) != 0 {
+		t.Fatalf("API-maintained calendar must not be overwritten by file: %+v", c)
+	}
+	// 内容未变化时重复导入不升版本
+	if err := s.importDir(ctx); err != nil || calDao.cals["SSE"].Version != 1 {
+		t.Fatalf("re-import should be a no-op: %v version=%d", err, calDao.cals["SSE"].Version)
+	}
+
+	if err := s.Save(ctx, &model.Calendar{Name: "BAD", Kind: "WEEKLY"}); !errors.Is(err, ErrInvalidCalendar) {
+		t.Fatalf("expected invalid calendar, got %v", err)
+	}
+	gen := s.Generation()
+	if err := s.Save(ctx, &model.Calendar{Name: "SSE", Dates: []string{"2026-10-01"}}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if c := calDao.cals["SSE"]; c.Source != bizConsts.CalendarSourceAPI || c.Version != 2 || s.Generation() == gen {
+		t.Fatalf("API save should bump version and generation: %+v gen=%d", c, s.Generation())
+	}
+	calDao.inUse = 2
+	if err := s.Delete(ctx, "SSE"); !errors.Is(err, ErrCalendarInUse) {
+		t.Fatalf("expected in-use error, got %v", err)
+	}
+}
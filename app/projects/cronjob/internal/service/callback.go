@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/render"
+)
+
+// CallbackResult 下游上报的异步执行结果。
+type CallbackResult struct {
+	Result string // success / failed / failed_timeout / canceled
+	Code   int
+	Body   string
+	Error  string
+}
+
+// status 结果对应的 Run 终态；result 未知时返回 false。
+func (c *CallbackResult) status() (bizConsts.RunStatus, bool) {
+	switch c.Result {
+	case "success":
+		return bizConsts.Success, true
+	case "failed":
+		return bizConsts.CallbackFailed, true
+	case "failed_timeout":
+		return bizConsts.FailedTimeout, true
+	case "canceled":
+		return bizConsts.Canceled, true
+	}
+	return "", false
+}
+
+// FinalizeCallback 校验回调 token 并按结果结束 Run，幂等：
+//   - token 不匹配直接拒绝；
+//   - Run 已按相同结果结束视为重复回调，不做修改；已以其他结果结束（或被取消、超时）不覆盖；
+//   - 并发的回调由条件更新保证只有一个生效，落后的一方按重复 / 冲突处理。
+//
+// 无论结果如何，rec 都会写入 async_callbacks（写入失败只记日志）。
+func (s *RunService) FinalizeCallback(ctx context.Context, run *model.TaskRun, token string, res *CallbackResult, rec *model.AsyncCallback) (bizConsts.CallbackOutcome, error) {
+	res.Result = strings.ToLower(strings.TrimSpace(res.Result))
+	outcome, err := s.finalizeCallback(ctx, run, token, res)
+	rec.TaskRunID, rec.Result, rec.Outcome = run.ID, res.Result, outcome
+	rec.Status = bizConsts.CallbackIgnored
+	if outcome == bizConsts.CallbackAccepted {
+		rec.Status = bizConsts.CallbackReceived
+	}
+	if rec.ReceivedAt.IsZero() {
+		rec.ReceivedAt = time.Now()
+	}
+	if token != "" {
+		rec.Body = strings.ReplaceAll(rec.Body, token, render.Mask)
+	}
+	s.RecordCallback(ctx, rec)
+	return outcome, err
+}
+
+func (s *RunService) finalizeCallback(ctx context.Context, run *model.TaskRun, token string, res *CallbackResult) (bizConsts.CallbackOutcome, error) {
+	if !run.VerifyCallbackToken(token) {
+		return bizConsts.CallbackInvalidToken, nil
+	}
+	status, ok := res.status()
+	if !ok {
+		return bizConsts.CallbackInvalidPayload, nil
+	}
+	if status == bizConsts.Canceled && run.CancelReason == bizConsts.CancelReasonExecutionTimeout {
+		status = bizConsts.Timeout // 下游确认因执行超时发起的取消
+	}
+	if run.Status == bizConsts.CallbackPending {
+		code, errMsg := res.Code, res.Error
+		switch status {
+		case bizConsts.Success:
+			if code == 0 {
+				code = 200
+			}
+		case bizConsts.CallbackFailed:
+			errMsg = defaultStr(errMsg, "callback_failed")
+		case bizConsts.FailedTimeout:
+			errMsg = defaultStr(errMsg, "callback_deadline_exceeded")
+		case bizConsts.Canceled:
+			errMsg = defaultStr(errMsg, "canceled_by_target")
+		case bizConsts.Timeout:
+			errMsg = defaultStr(errMsg, executionTimeoutMsg(run))
+		}
+		updated, err := s.RunDao.FinalizeCallback(ctx, run.ID, status, code, res.Body, errMsg)
+		if err != nil {
+			return bizConsts.CallbackError, err
+		}
+		if updated {
+			s.events.notify(run.ID)
+			run.Status = status
+			return bizConsts.CallbackAccepted, nil
+		}
+		// 条件更新未命中：Run 已被并发的回调、扫描器或取消结束，按最新状态判断
+		latest, err := s.RunDao.Get(ctx, run.ID)
+		if err != nil {
+			return bizConsts.CallbackError, err
+		}
+		run.Status = latest.Status
+	}
+	switch {
+	case run.Status == status:
+		return bizConsts.CallbackDuplicate, nil
+	case run.Status.Finished():
+		return bizConsts.CallbackConflict, nil
+	}
+	return bizConsts.CallbackNotPending, nil
+}
+
+// RecordCallback 写入一条回调记录；失败不影响回调处理。
+func (s *RunService) RecordCallback(ctx context.Context, rec *model.AsyncCallback) {
+	if err := s.RunDao.CreateCallback(ctx, rec); err != nil {
+		logging.Warn(ctx, fmt.Sprintf("record callback for run %d failed: %v", rec.TaskRunID, err))
+	}
+}
+
+func (s *RunService) ListCallbacks(ctx context.Context, runID int64, limit int) ([]*model.AsyncCallback, error) {
+	return s.RunDao.ListCallbacks(ctx, runID, limit)
+}
+
+func defaultStr(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// callbackRunDao 只保存一个 Run，模拟 FinalizeCallback 的条件更新。
+type callbackRunDao struct {
+	dao.RunDao
+	run  model.TaskRun
+	recs []*model.AsyncCallback
+}
+
+func (d *callbackRunDao) Get(_ context.Context, _ int64) (*model.TaskRun, error) {
+	cp := d.run
+	return &cp, nil
+}
+func (d *callbackRunDao) FinalizeCallback(_ context.Context, _ int64, status bizConsts.RunStatus, code int, body, errMsg string) (bool, error) {
+	if d.run.Status != bizConsts.CallbackPending {
+		return false, nil
+	}
+	d.run.Status, d.run.ResponseCode, d.run.ResponseBody, d.run.ErrorMessage = status, &code, body, errMsg
+	return true, nil
+}
+func (d *callbackRunDao) CreateCallback(_ context.Context, cb *model.AsyncCallback) error {
+	d.recs = append(d.recs, cb)
+	return nil
+}
+
+func TestFinalizeCallback(t *testing.T) {
+	ctx := context.Background()
+	token := model.NewCallbackToken()
+	d := &callbackRunDao{run: model.TaskRun{ID: 5, Status: bizConsts.CallbackPending, CallbackToken: token}}
+	rs := &RunService{RunDao: d}
+	finalize := func(tok, result string) bizConsts.CallbackOutcome {
+		run, _ := d.Get(ctx, 5)
+		rec := &model.AsyncCallback{Body: `{"callback_token":"` + tok + `"}`, SourceIP: "10.0.0.8"}
+		outcome, err := rs.FinalizeCallback(ctx, run, tok, &CallbackResult{Result: result}, rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return outcome
+	}
+
+	if got := finalize("", "failed"); got != bizConsts.CallbackInvalidToken {
+		t.Fatalf("missing token: got %s", got)
+	}
+	if got := finalize(strings.Repeat("0", 32), "failed"); got != bizConsts.CallbackInvalidToken {
+		t.Fatalf("wrong token: got %s", got)
+	}
+	if got := finalize(token, "done"); got != bizConsts.CallbackInvalidPayload {
+		t.Fatalf("unknown result: got %s", got)
+	}
+	if got := finalize(token, " SUCCESS "); got != bizConsts.CallbackAccepted || d.run.Status != bizConsts.Success || *d.run.ResponseCode != 200 {
+		t.Fatalf("success: got %s, run %+v", got, d.run)
+	}
+	if got := finalize(token, "success"); got != bizConsts.CallbackDuplicate {
+		t.Fatalf("retried callback: got %s", got)
+	}
+	if got := finalize(token, "failed"); got != bizConsts.CallbackConflict || d.run.Status != bizConsts.Success {
+		t.Fatalf("late failure must not flip SUCCESS: got %s, status %s", got, d.run.Status)
+	}
+	// 并发回调：读到的仍是 CALLBACK_PENDING，但条件更新已被另一请求抢先
+	stale := &model.TaskRun{ID: 5, Status: bizConsts.CallbackPending, CallbackToken: token}
+	if got, _ := rs.FinalizeCallback(ctx, stale, token, &CallbackResult{Result: "failed"}, &model.AsyncCallback{SourceIP: "10.0.0.8"}); got != bizConsts.CallbackConflict {
+		t.Fatalf("racing callback: got %s", got)
+	}
+
+	if len(d.recs) != 7 {
+		t.Fatalf("expected every attempt recorded, got %d", len(d.recs))
+	}
+	for i, rec := range d.recs {
+		if rec.TaskRunID != 5 || rec.SourceIP != "10.0.0.8" || strings.Contains(rec.Body, token) {
+			t.Fatalf("record %d: %+v", i, rec)
+		}
+		if want := bizConsts.CallbackIgnored; i != 3 && rec.Status != want {
+			t.Fatalf("record %d: status %s", i, rec.Status)
+		}
+	}
+	if d.recs[3].Status != bizConsts.CallbackReceived || d.recs[3].Result != "success" {
+		t.Fatalf("accepted record: %+v", d.recs[3])
+	}
+}
+
+func TestFinalizeCallbackCanceled(t *testing.T) {
+	ctx := context.Background()
+	token := model.NewCallbackToken()
+	for _, tc := range []struct {
+		reason bizConsts.CancelReason
+		want   bizConsts.RunStatus
+		errMsg string
+	}{
+		{"", bizConsts.Canceled, "canceled_by_target"},
+		{bizConsts.CancelReasonUser, bizConsts.Canceled, "canceled_by_target"},
+		{bizConsts.CancelReasonExecutionTimeout, bizConsts.Timeout, "execution_timeout: exceeded 60s"},
+	} {
+		d := &callbackRunDao{run: model.TaskRun{ID: 6, Status: bizConsts.CallbackPending, CallbackToken: token,
+			ExecutionTimeoutSec: 60, CancelReason: tc.reason}}
+		rs := &RunService{RunDao: d}
+		run, _ := d.Get(ctx, 6)
+		got, err := rs.FinalizeCallback(ctx, run, token, &CallbackResult{Result: "canceled"}, &model.AsyncCallback{})
+		if err != nil || got != bizConsts.CallbackAccepted {
+			t.Fatalf("reason %q: outcome %s err %v", tc.reason, got, err)
+		}
+		if d.run.Status != tc.want || d.run.ErrorMessage != tc.errMsg {
+			t.Fatalf("reason %q: status %s error %q", tc.reason, d.run.Status, d.run.ErrorMessage)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// 任务依赖（DAG）：
+//   - 任务声明上游（task_dependencies）与触发规则（trigger_rule），schedule_mode 为 DEPENDENCY/BOTH 时生效
+//   - 每个 Run 带业务日期 logical_date（cron/手动触发取触发时间在任务时区下的日期）
+//   - Run 结束后，DagResolver 评估其下游：取各上游在同一 logical_date 下最新的 Run，按规则决定触发、等待或跳过
+//   - 下游 Run 沿用上游的 logical_date，按 (task_id, logical_date, dag_generation) 去重，并记录 Run 级血缘（run_lineage）
+//   - "从该节点向下游重跑" 以新的 dag_generation 重跑节点，其下游按新轮次重新触发
+
+// ErrDependencyCycle 依赖关系成环。
+var ErrDependencyCycle = errors.New("task dependency cycle")
+
+// TaskGraph 任务依赖图，边由上游指向下游。
+type TaskGraph struct {
+	Nodes []*GraphNode `json:"nodes"`
+	Edges []GraphEdge  `json:"edges"`
+	Date  *time.Time   `json:"logical_date,omitempty"`
+}
+
+type GraphNode struct {
+	ID           int64                  `json:"id"`
+	Name         string                 `json:"name"`
+	Status       bizConsts.TaskStatus   `json:"status"`
+	ScheduleMode bizConsts.ScheduleMode `json:"schedule_mode"`
+	TriggerRule  bizConsts.TriggerRule  `json:"trigger_rule"`
+	CronExpr     string                 `json:"cron_expr"`
+	Run          *model.TaskRun         `json:"run,omitempty"` // 指定 logical_date 时该日期下最新的 Run
+}
+
+type GraphEdge struct {
+	From int64 `json:"from"` // 上游
+	To   int64 `json:"to"`   // 下游
+}
+
+// LogicalDate 触发时间在任务时区下的日期（以 UTC 零点表示）。
+func LogicalDate(task *model.Task, at time.Time) time.Time {
+	loc, err := cron.LoadLocation(task.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	y, m, d := at.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// DependencyIndex 返回 上游 -> 下游 与 下游 -> 上游 的邻接表。
+func (s *TaskService) DependencyIndex(ctx context.Context) (map[int64][]int64, map[int64][]int64, error) {
+	edges, err := s.TaskDao.ListDependencies(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	down := make(map[int64][]int64)
+	up := make(map[int64][]int64)
+	for _, e := range edges {
+		down[e.UpstreamTaskID] = append(down[e.UpstreamTaskID], e.TaskID)
+		up[e.TaskID] = append(up[e.TaskID], e.UpstreamTaskID)
+	}
+	return down, up, nil
+}
+
+// Upstreams 返回任务的上游任务 ID。
+func (s *TaskService) Upstreams(ctx context.Context, id int64) ([]int64, error) {
+	_, up, err := s.DependencyIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return up[id], nil
+}
+
+// CheckUpstreams 校验上游：存在、不含自身、替换后不成环。id 为 0 表示新建任务（不可能成环）。
+func (s *TaskService) CheckUpstreams(ctx context.Context, id int64, upstreams []int64) error {
+	for _, u := range upstreams {
+		if u == id {
+			return fmt.Errorf("task cannot depend on itself")
+		}
+		if t, err := s.TaskDao.Get(ctx, u); err != nil || t == nil {
+			return fmt.Errorf("upstream task %d not found", u)
+		}
+	}
+	if id == 0 || len(upstreams) == 0 {
+		return nil
+	}
+	_, up, err := s.DependencyIndex(ctx)
+	if err != nil {
+		return err
+	}
+	up[id] = upstreams
+	// 从 id 沿上游方向搜索，能回到 id 即成环
+	visited := make(map[int64]bool)
+	var walk func(n int64) bool
+	walk = func(n int64) bool {
+		for _, u := range up[n] {
+			if u == id {
+				return true
+			}
+			if !visited[u] {
+				visited[u] = true
+				if walk(u) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if walk(id) {
+		return ErrDependencyCycle
+	}
+	return nil
+}
+
+// SetUpstreams 校验并替换任务的上游集合。
+func (s *TaskService) SetUpstreams(ctx context.Context, id int64, upstreams []int64) error {
+	upstreams = uniqueIDs(upstreams)
+	if err := s.CheckUpstreams(ctx, id, upstreams); err != nil {
+		return err
+	}
+	return s.TaskDao.ReplaceUpstreams(ctx, id, upstreams)
+}
+
+// Graph 返回所有未删除任务及依赖边。
+func (s *TaskService) Graph(ctx context.Context) (*TaskGraph, error) {
+	tasks, err := s.TaskDao.ListFiltered(ctx, nil, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := s.TaskDao.ListDependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	g := &TaskGraph{Nodes: make([]*GraphNode, 0, len(tasks)), Edges: make([]GraphEdge, 0, len(edges))}
+	for _, t := range tasks {
+		g.Nodes = append(g.Nodes, &GraphNode{ID: t.ID, Name: t.Name, Status: t.Status, ScheduleMode: t.ScheduleMode, TriggerRule: t.TriggerRule, CronExpr: t.CronExpr})
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	for _, e := range edges {
+		g.Edges = append(g.Edges, GraphEdge{From: e.UpstreamTaskID, To: e.TaskID})
+	}
+	return g, nil
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id > 0 && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+type dagDecision int
+
+const (
+	dagWait dagDecision = iota // 仍有上游未结束
+	dagFire                    // 规则满足，触发下游
+	dagSkip                    // 规则不可能满足，下游记为 SKIPPED
+)
+
+// decideTrigger 按触发规则评估各上游在同一 logical_date 下最新的 Run。
+// 没有 Run、未结束或已计划重试（next_retry_time 非空）的上游视为未结束。
+func decideTrigger(rule bizConsts.TriggerRule, upstreams []int64, latest map[int64]*model.TaskRun) dagDecision {
+	var pending, success, failed int
+	for _, u := range upstreams {
+		r := latest[u]
+		switch {
+		case r == nil || !r.Status.Finished() || r.NextRetryTime != nil:
+			pending++
+		case r.Status == bizConsts.Success:
+			success++
+		default:
+			failed++
+		}
+	}
+	switch rule {
+	case bizConsts.TriggerRuleAllDone:
+		if pending > 0 {
+			return dagWait
+		}
+		return dagFire
+	case bizConsts.TriggerRuleOneSuccess:
+		if success > 0 {
+			return dagFire
+		}
+		if pending > 0 {
+			return dagWait
+		}
+		return dagSkip
+	default: // ALL_SUCCESS
+		if failed > 0 {
+			return dagSkip
+		}
+		if pending > 0 {
+			return dagWait
+		}
+		return dagFire
+	}
+}
+
+// DagResolver 周期性处理已结束的 Run，触发满足依赖规则的下游任务；多副本时仅 leader 执行。
+// Run 结束后至少经过一个周期才评估，让失败 Run 的重试计划（next_retry_time）先落库，避免误判为最终失败。
+type DagResolver struct {
+	*core.BaseComponent
+	cfg     config.DagConfig
+	TaskSvc *TaskService           `infra:"dep:task_service"`
+	RunSvc  *RunService            `infra:"dep:run_service"`
+	Exec    *Executor              `infra:"dep:executor"`
+	Leader  *LeaderElector         `infra:"dep:scheduler_leader"`
+	Pause   *SchedulerPauseService `infra:"dep:scheduler_pause"`
+
+	settling map[int64]struct{} // 上一周期首次看到的已结束 Run（仅解析协程访问）
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func NewDagResolver(cfg config.DagConfig) *DagResolver {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.BatchLimit <= 0 {
+		cfg.BatchLimit = 200
+	}
+	return &DagResolver{BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_DAG), cfg: cfg, settling: make(map[int64]struct{})}
+}
+
+func (d *DagResolver) Start(ctx context.Context) error {
+	if d.IsActive() {
+		return nil
+	}
+	if err := d.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	loopCtx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(d.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+				if d.Leader.IsLeader() && !d.Pause.Paused() { // 暂停期间不触发下游，恢复后继续评估
+					d.resolve(loopCtx)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (d *DagResolver) Stop(ctx context.Context) error {
+	if !d.IsActive() {
+		return nil
+	}
+	if d.cancel != nil {
+		d.cancel()
+		<-d.done
+	}
+	return d.BaseComponent.Stop(ctx)
+}
+
+// resolve 处理一批已结束的 Run：评估其下游并标记 dag_resolved。
+func (d *DagResolver) resolve(ctx context.Context) {
+	runs, err := d.RunSvc.ListDagPending(ctx, d.cfg.BatchLimit)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("dag list pending runs failed: %v", err))
+		return
+	}
+	settling := make(map[int64]struct{}, len(runs))
+	var ready []*model.TaskRun
+	for _, run := range runs {
+		if _, ok := d.settling[run.ID]; ok {
+			ready = append(ready, run)
+		} else {
+			settling[run.ID] = struct{}{}
+		}
+	}
+	d.settling = settling
+	if len(ready) == 0 {
+		return
+	}
+	down, up, err := d.TaskSvc.DependencyIndex(ctx)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("dag load dependencies failed: %v", err))
+		return
+	}
+	resolved := make([]int64, 0, len(ready))
+	for _, run := range ready {
+		if run.LogicalDate != nil {
+			if err := d.evaluateDownstream(ctx, down[run.TaskID], up, *run.LogicalDate); err != nil {
+				logging.Error(ctx, fmt.Sprintf("dag evaluate downstream of run %d failed: %v", run.ID, err))
+				break // 未标记的 Run 下一周期重试
+			}
+		}
+		resolved = append(resolved, run.ID)
+	}
+	if err := d.RunSvc.MarkDagResolved(ctx, resolved); err != nil {
+		logging.Error(ctx, fmt.Sprintf("dag mark resolved failed: %v", err))
+	}
+}
+
+func (d *DagResolver) evaluateDownstream(ctx context.Context, downstream []int64, up map[int64][]int64, date time.Time) error {
+	for _, id := range downstream {
+		task, err := d.TaskSvc.Get(ctx, id)
+		if err != nil || task == nil || task.Status != bizConsts.ENABLED || !task.UsesDependencies() {
+			continue
+		}
+		latest, err := d.RunSvc.LatestByLogicalDate(ctx, up[id], date)
+		if err != nil {
+			return err
+		}
+		decision := decideTrigger(task.TriggerRule, up[id], latest)
+		if decision == dagWait {
+			continue
+		}
+		// 下游轮次取上游最新 Run 的最大轮次：上游被重跑后，下游随之进入新一轮
+		gen := 0
+		lineage := make([]int64, 0, len(latest))
+		for _, r := range latest {
+			if r.DagGeneration > gen {
+				gen = r.DagGeneration
+			}
+			lineage = append(lineage, r.ID)
+		}
+		sort.Slice(lineage, func(i, j int) bool { return lineage[i] < lineage[j] })
+		run := d.newRun(task, bizConsts.TriggerDependency, date, gen)
+		if decision == dagSkip {
+			run.ErrorMessage = fmt.Sprintf("upstream_not_satisfied: trigger_rule=%s", task.TriggerRule)
+			err = d.RunSvc.CreateSkipped(ctx, run, bizConsts.Skipped)
+		} else {
+			err = d.submit(ctx, task, run)
+		}
+		if errors.Is(err, dao.ErrDuplicateRun) {
+			continue // 本轮已触发过
+		}
+		if err != nil && !errors.Is(err, ErrQueueFull) {
+			return err
+		}
+		if err := d.RunSvc.AddLineage(ctx, run.ID, lineage); err != nil {
+			logging.Error(ctx, fmt.Sprintf("dag record lineage for run %d failed: %v", run.ID, err))
+		}
+		logging.Info(ctx, fmt.Sprintf("dag task %d logical_date=%s gen=%d run=%d status=%s", task.ID, date.Format(time.DateOnly), gen, run.ID, run.Status))
+	}
+	return nil
+}
+
+// Rerun 以新的 dag_generation 重跑任务在 logical_date 的运行，完成后其下游按新轮次重新触发。
+func (d *DagResolver) Rerun(ctx context.Context, task *model.Task, date time.Time) (*model.TaskRun, error) {
+	gen, err := d.RunSvc.MaxDagGeneration(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	run := d.newRun(task, bizConsts.TriggerRerun, date, gen+1)
+	if err := d.submit(ctx, task, run); err != nil {
+		return run, err
+	}
+	logging.Info(ctx, fmt.Sprintf("dag rerun task %d logical_date=%s gen=%d run=%d", task.ID, date.Format(time.DateOnly), run.DagGeneration, run.ID))
+	return run, nil
+}
+
+func (d *DagResolver) newRun(task *model.Task, trigger bizConsts.TriggerType, date time.Time, gen int) *model.TaskRun {
+	run := d.TaskSvc.CreateTaskRun(task, time.Now().UTC().Truncate(time.Second), 1)
+	run.TriggerType, run.LogicalDate, run.DagGeneration = trigger, &date, gen
+	return run
+}
+
+// submit 按任务的并发策略提交 Run（与手动触发一致）。
+func (d *DagResolver) submit(ctx context.Context, task *model.Task, run *model.TaskRun) error {
+	if UsesQueue(task) {
+		return d.Exec.SubmitQueued(ctx, task, run)
+	}
+	if task.ConcurrencyPolicy == bizConsts.ConcurrencySkip && task.MaxConcurrency > 0 && d.Exec.ActiveCount(task.ID) >= task.MaxConcurrency {
+		return d.RunSvc.CreateSkipped(ctx, run, bizConsts.ConcurrentSkip)
+	}
+	if err := d.RunSvc.CreateScheduled(ctx, run); err != nil {
+		return err
+	}
+	d.Exec.Enqueue(run)
+	return nil
+}
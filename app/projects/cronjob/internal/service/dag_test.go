@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+func (r *stubRunDao) ListDagPending(_ context.Context, limit int) ([]*model.TaskRun, error) {
+	var out []*model.TaskRun
+	for _, ru := range r.runs {
+		if !ru.DagResolved && ru.Status.Finished() {
+			out = append(out, ru)
+		}
+	}
+	return out, nil
+}
+func (r *stubRunDao) MarkDagResolved(_ context.Context, ids []int64) error {
+	for _, id := range ids {
+		for _, ru := range r.runs {
+			if ru.ID == id {
+				ru.DagResolved = true
+			}
+		}
+	}
+	return nil
+}
+func (r *stubRunDao) LatestByLogicalDate(_ context.Context, taskIDs []int64, date time.Time) (map[int64]*model.TaskRun, error) {
+	out := make(map[int64]*model.TaskRun)
+	for _, id := range taskIDs {
+		for _, ru := range r.runs {
+			if ru.TaskID == id && ru.LogicalDate != nil && ru.LogicalDate.Equal(date) {
+				out[id] = ru
+			}
+		}
+	}
+	return out, nil
+}
+func (r *stubRunDao) MaxDagGeneration(_ context.Context, date time.Time) (int, error) {
+	gen := 0
+	for _, ru := range r.runs {
+		if ru.LogicalDate != nil && ru.LogicalDate.Equal(date) && ru.DagGeneration > gen {
+			gen = ru.DagGeneration
+		}
+	}
+	return gen, nil
+}
+func (r *stubRunDao) AddLineage(_ context.Context, runID int64, upstreamRunIDs []int64) error {
+	if r.lineage == nil {
+		r.lineage = make(map[int64][]int64)
+	}
+	r.lineage[runID] = append(r.lineage[runID], upstreamRunIDs...)
+	return nil
+}
+
+func TestDecideTrigger(t *testing.T) {
+	ok := &model.TaskRun{Status: bizConsts.Success}
+	bad := &model.TaskRun{Status: bizConsts.Failed}
+	retrying := &model.TaskRun{Status: bizConsts.Failed, NextRetryTime: &time.Time{}}
+	running := &model.TaskRun{Status: bizConsts.Running}
+	ups := []int64{1, 2}
+	cases := []struct {
+		rule   bizConsts.TriggerRule
+		latest map[int64]*model.TaskRun
+		want   dagDecision
+	}{
+		{bizConsts.TriggerRuleAllSuccess, map[int64]*model.TaskRun{1: ok, 2: ok}, dagFire},
+		{bizConsts.TriggerRuleAllSuccess, map[int64]*model.TaskRun{1: ok}, dagWait},
+		{bizConsts.TriggerRuleAllSuccess, map[int64]*model.TaskRun{1: ok, 2: retrying}, dagWait},
+		{bizConsts.TriggerRuleAllSuccess, map[int64]*model.TaskRun{1: running, 2: bad}, dagSkip},
+		{bizConsts.TriggerRuleAllDone, map[int64]*model.TaskRun{1: ok, 2: bad}, dagFire},
+		{bizConsts.TriggerRuleAllDone, map[int64]*model.TaskRun{1: ok, 2: running}, dagWait},
+		{bizConsts.TriggerRuleOneSuccess, map[int64]*model.TaskRun{1: ok, 2: running}, dagFire},
+		{bizConsts.TriggerRuleOneSuccess, map[int64]*model.TaskRun{1: bad, 2: running}, dagWait},
+		{bizConsts.TriggerRuleOneSuccess, map[int64]*model.TaskRun{1: bad, 2: bad}, dagSkip},
+	}
+	for i, c := range cases {
+		if got := decideTrigger(c.rule, ups, c.latest); got != c.want {
+			t.Fatalf("case %d: rule %s got %d want %d", i, c.rule, got, c.want)
+		}
+	}
+}
+
+func TestCheckUpstreamsCycle(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTaskService()
+	ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{1: {ID: 1}, 2: {ID: 2}, 3: {ID: 3}}}
+	if err := ts.SetUpstreams(ctx, 2, []int64{1}); err != nil {
+		t.Fatalf("set 1->2 failed: %v", err)
+	}
+	if err := ts.SetUpstreams(ctx, 3, []int64{2, 2}); err != nil {
+		t.Fatalf("set 2->3 failed: %v", err)
+	}
+	if ups, _ := ts.Upstreams(ctx, 3); len(ups) != 1 {
+		t.Fatalf("duplicate upstreams should be collapsed, got %v", ups)
+	}
+	if err := ts.SetUpstreams(ctx, 1, []int64{3}); !errors.Is(err, ErrDependencyCycle) {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	if err := ts.CheckUpstreams(ctx, 1, []int64{1}); err == nil {
+		t.Fatal("self dependency should be rejected")
+	}
+	if err := ts.CheckUpstreams(ctx, 1, []int64{99}); err == nil {
+		t.Fatal("missing upstream should be rejected")
+	}
+}
+
+func TestDagResolverFiresDownstreamAndRerun(t *testing.T) {
+	ctx := context.Background()
+	a := &model.Task{ID: 1, Name: "a", TargetService: "artemis", Status: bizConsts.ENABLED, ScheduleMode: bizConsts.ScheduleModeCron}
+	b := &model.Task{ID: 2, Name: "b", TargetService: "artemis", Status: bizConsts.ENABLED, ScheduleMode: bizConsts.ScheduleModeCron}
+	c := &model.Task{ID: 3, Name: "c", TargetService: "artemis", Status: bizConsts.ENABLED,
+		ScheduleMode: bizConsts.ScheduleModeDependency, TriggerRule: bizConsts.TriggerRuleAllSuccess}
+	ts := NewTaskService()
+	ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{1: a, 2: b, 3: c}}
+	if err := ts.Start(ctx); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if err := ts.SetUpstreams(ctx, 3, []int64{1, 2}); err != nil {
+		t.Fatalf("set upstreams failed: %v", err)
+	}
+	runDao := &stubRunDao{}
+	d := NewDagResolver(config.DagConfig{})
+	d.TaskSvc, d.RunSvc, d.Exec = ts, &RunService{RunDao: runDao}, NewExecutor(config.ExecutorConfig{})
+
+	date := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	runA := ts.CreateTaskRun(a, date.Add(time.Hour), 1)
+	runA.Status = bizConsts.Success
+	_ = runDao.CreateScheduled(ctx, runA)
+
+	// 第一次扫描只记录，第二次才评估；b 尚无 Run，c 等待
+	d.resolve(ctx)
+	d.resolve(ctx)
+	if len(runDao.runs) != 1 || !runA.DagResolved {
+		t.Fatalf("downstream should wait for b, runs=%d resolved=%v", len(runDao.runs), runA.DagResolved)
+	}
+
+	runB := ts.CreateTaskRun(b, date.Add(2*time.Hour), 1)
+	runB.Status = bizConsts.Success
+	_ = runDao.CreateScheduled(ctx, runB)
+	d.resolve(ctx)
+	d.resolve(ctx)
+	if len(runDao.runs) != 3 {
+		t.Fatalf("expected downstream run, got %d runs", len(runDao.runs))
+	}
+	runC := runDao.runs[2]
+	if runC.TaskID != 3 || runC.TriggerType != bizConsts.TriggerDependency || !runC.LogicalDate.Equal(date) || runC.DagGeneration != 0 {
+		t.Fatalf("unexpected downstream run %+v", runC)
+	}
+	if got := runDao.lineage[runC.ID]; len(got) != 2 || got[0] != runA.ID || got[1] != runB.ID {
+		t.Fatalf("unexpected lineage %v", got)
+	}
+
+	// 从 a 向下游重跑：新轮次，c 随之进入同一轮次
+	rerun, err := d.Rerun(ctx, a, date)
+	if err != nil || rerun.DagGeneration != 1 || rerun.TriggerType != bizConsts.TriggerRerun {
+		t.Fatalf("rerun failed: %v %+v", err, rerun)
+	}
+	runC.Status = bizConsts.Success
+	rerun.Status = bizConsts.Success
+	d.resolve(ctx)
+	d.resolve(ctx)
+	last := runDao.runs[len(runDao.runs)-1]
+	if last.TaskID != 3 || last.DagGeneration != 1 {
+		t.Fatalf("expected downstream rerun at generation 1, got %+v", last)
+	}
+}
@@ -2,9 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"strings"
 	"sync"
 	"time"
 
@@ -12,30 +12,72 @@ import (
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
 )
 
 // Engine 负责基于定时表达式调度任务。
-// 去除补偿：每个 poll tick 仅检查当前时间是否触发，不再回溯补偿漏掉的时间点。
-// Cron 六字段解析保留（兼容秒）。
-// Overlap/并发/失败策略沿用原语义。
+// 每个任务维护一个评估游标（已评估到的时间点，持久化为 tasks.last_evaluated_at）；
+// 每次 tick 计算 (游标, now] 内应触发的时间点，按任务的 MisfirePolicy 决定执行哪些，
+// 再推进游标。服务重启后从持久化的游标继续，因此停机期间错过的触发可按策略补偿。
+// 区间内有触发点的游标当次即持久化；其余游标只在内存中前移，按 cursor_flush_interval 批量落库
+// （重启后从较早的游标重新评估也只会得到同样的空区间，不会重复触发）。
+// Overlap/并发/失败策略沿用原语义，对每个要执行的触发点逐个应用。
+//
+// 多副本部署时只有 leader 执行 scan（见 LeaderElector）。接管时丢弃内存中的计划并从数据库重载
+// 任务与游标，同时把前任遗留在内存队列里的 SCHEDULED Run 重新入队（TransitionToRunning 的 CAS
+// 保证不会重复执行）；即使发生脑裂，(task_id, scheduled_time) 唯一约束也保证同一触发点只落一条 Run。
+//
+// 调度器全局暂停期间（见 SchedulerPauseService）跳过 scan，游标不前移，恢复后按 misfire 策略处理错过的触发。
 
 type Engine struct {
-	cfg     config.SchedulerConfig
-	TaskSvc *TaskService `infra:"dep:task_service"`
-	RunDao  dao.RunDao   `infra:"dep:run_dao"`
-	Exec    *Executor    `infra:"dep:executor"`
+	cfg       config.SchedulerConfig
+	TaskSvc   *TaskService           `infra:"dep:task_service"`
+	RunDao    dao.RunDao             `infra:"dep:run_dao"`
+	Exec      *Executor              `infra:"dep:executor"`
+	Leader    *LeaderElector         `infra:"dep:scheduler_leader"`
+	Calendars *CalendarService       `infra:"dep:calendar_service"`
+	Pause     *SchedulerPauseService `infra:"dep:scheduler_pause"`
 	*core.BaseComponent
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
+
+	plans     map[int64]*firePlan // taskID -> 调度计划（仅 scan 协程访问）
+	stale     map[int64]struct{}  // 游标已前移但尚未持久化的任务（仅 scan 协程访问）
+	flushedAt time.Time           // 最近一次批量持久化游标的时间
+	term      int64               // 最近一次执行 scan 时的 leader 任期
+	paused    bool                // 上一个 tick 是否处于全局暂停（仅用于记录状态切换）
+}
+
+// firePlan 缓存任务的解析结果与评估游标；表达式、时区或日历变化时重新解析，游标保留。
+type firePlan struct {
+	cronExpr string
+	timezone string
+	calendar string
+	calMode  bizConsts.CalendarMode
+	calGen   int64
+	parsed   bool
+	sched    cron.Schedule
+	loc      *time.Location
+	cursor   time.Time // 已评估到的时间点（含）
 }
 
 func NewEngine(cfg config.SchedulerConfig) *Engine {
 	if cfg.PollInterval <= 0 {
 		cfg.PollInterval = time.Second
 	}
-	return &Engine{cfg: cfg, BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_SCHEDULER)}
+	if cfg.MisfireMaxCatchup <= 0 {
+		cfg.MisfireMaxCatchup = 100
+	}
+	if cfg.MisfireMaxLookback <= 0 {
+		cfg.MisfireMaxLookback = 24 * time.Hour
+	}
+	if cfg.CursorFlushInterval <= 0 {
+		cfg.CursorFlushInterval = 30 * time.Second
+	}
+	return &Engine{cfg: cfg, BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_SCHEDULER),
+		plans: make(map[int64]*firePlan), stale: make(map[int64]struct{})}
 }
 
 func (e *Engine) Start(ctx context.Context) error {
@@ -57,7 +99,7 @@ func (e *Engine) Start(ctx context.Context) error {
 			case <-loopCtx.Done():
 				return
 			case now := <-ticker.C:
-				if err := e.scan(loopCtx, now); err != nil {
+				if err := e.tick(loopCtx, now); err != nil {
 					log.Printf("scheduler scan err: %v", err)
 				}
 			}
@@ -77,7 +119,90 @@ func (e *Engine) Stop(ctx context.Context) error {
 	return e.BaseComponent.Stop(ctx)
 }
 
-// scan：仅检测当前 tick 时间是否需要触发。
+// tick 仅在本副本为 leader 且调度器未暂停时执行 scan；任期变化说明刚接管，先恢复前任的状态。
+func (e *Engine) tick(ctx context.Context, now time.Time) error {
+	if !e.Leader.IsLeader() {
+		return nil
+	}
+	if term := e.Leader.Term(); term != e.term {
+		e.term = term
+		e.takeOver(ctx)
+	} else if e.Leader.Enabled() {
+		// 任务可能在其他副本上被修改：每个 tick 比对任务表变更指纹，有变化才重载缓存
+		if _, err := e.TaskSvc.RefreshIfChanged(ctx); err != nil {
+			return err
+		}
+	}
+	if paused := e.Pause.Paused(); paused != e.paused {
+		e.paused = paused
+		if paused {
+			logging.Info(ctx, "scheduler paused, cron fires suspended")
+		} else {
+			logging.Info(ctx, "scheduler resumed, cron fires continue per misfire policy")
+		}
+	}
+	if e.paused {
+		return nil
+	}
+	return e.scan(ctx, now)
+}
+
+// takeOver 成为 leader 后：重载任务（含前任持久化的游标）、清空计划，并请求执行器恢复遗留的 SCHEDULED Run。
+// 恢复在执行器的 dispatchLoop 中分页进行，不阻塞调度 tick。
+func (e *Engine) takeOver(ctx context.Context) {
+	logging.Info(ctx, fmt.Sprintf("scheduler taking over as leader term=%d", e.term))
+	if err := e.TaskSvc.Refresh(ctx); err != nil {
+		logging.Error(ctx, fmt.Sprintf("reload tasks on takeover failed: %v", err))
+	}
+	e.plans = make(map[int64]*firePlan)
+	e.stale = make(map[int64]struct{})
+	e.Exec.RestoreQueue()
+}
+
+// plan 返回任务的调度计划。首次见到任务时游标取持久化的 last_evaluated_at，
+// 从未评估过则取 now 前一秒（恰好落在 now 的触发点不被漏掉）；任务重新启用后
+// last_evaluated_at 被重置到更晚的时间，此时游标跟随前移。
+// 表达式或时区非法、引用的日历不存在时 sched 为 nil，仅在变更时记录一次错误。
+func (e *Engine) plan(ctx context.Context, task *model.Task, now time.Time) *firePlan {
+	p, ok := e.plans[task.ID]
+	if !ok {
+		p = &firePlan{cursor: now.Add(-time.Second)}
+		if task.LastEvaluatedAt != nil {
+			p.cursor = *task.LastEvaluatedAt
+		}
+		e.plans[task.ID] = p
+	} else if task.LastEvaluatedAt != nil && task.LastEvaluatedAt.After(p.cursor) {
+		p.cursor = *task.LastEvaluatedAt
+	}
+	gen := e.Calendars.Generation()
+	if p.parsed && p.cronExpr == task.CronExpr && p.timezone == task.Timezone &&
+		p.calendar == task.Calendar && p.calMode == task.CalendarMode && p.calGen == gen {
+		return p
+	}
+	p.cronExpr, p.timezone, p.parsed = task.CronExpr, task.Timezone, true
+	p.calendar, p.calMode, p.calGen = task.Calendar, task.CalendarMode, gen
+	p.sched, p.loc = nil, nil
+	loc, err := cron.LoadLocation(task.Timezone)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("task %d invalid timezone: %v", task.ID, err))
+		return p
+	}
+	sched, err := cron.Parse(task.CronExpr)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("task %d invalid cron_expr %q: %v", task.ID, task.CronExpr, err))
+		return p
+	}
+	if sched, err = e.Calendars.Schedule(task, sched); err != nil {
+		logging.Error(ctx, fmt.Sprintf("task %d calendar unavailable, cron fires suspended: %v", task.ID, err))
+		return p
+	}
+	p.sched, p.loc = sched, loc
+	return p
+}
+
+// scan：对每个任务计算 (游标, now] 内的触发点，按 misfire 策略执行并推进游标。
+// 区间内有触发点的任务立即持久化游标，其余的由 flushCursors 批量持久化。
+// 落在停摆窗口内的触发点直接跳过，不创建 Run。
 func (e *Engine) scan(ctx context.Context, now time.Time) error {
 	now = now.Truncate(time.Second)
 	tasks, err := e.TaskSvc.ListEnabled(ctx)
@@ -85,288 +210,301 @@ func (e *Engine) scan(ctx context.Context, now time.Time) error {
 		return err
 	}
 	logging.Info(ctx, fmt.Sprintf("scheduler tick %s tasks=%d", now.Format(time.RFC3339), len(tasks)))
+	seen := make(map[int64]struct{}, len(tasks))
+	var passed []int64
 	for _, task := range tasks {
-		// 是否触发
-		if !shouldFire(now, task.CronExpr) {
+		seen[task.ID] = struct{}{}
+		if !task.UsesCron() { // 仅依赖触发（见 DagResolver），游标跟随 now，切回 cron 时不补偿
+			e.stale[task.ID] = struct{}{}
 			continue
 		}
-		// 最近运行记录（用于 overlap / failure / dedup）
-		recentRuns, _ := e.RunDao.ListByTask(ctx, task.ID, 50)
-		existing := make(map[int64]*model.TaskRun)
-		var lastEffective *model.TaskRun
-		for _, r := range recentRuns {
-			sec := r.ScheduledTime.Unix()
-			if _, ok := existing[sec]; !ok {
-				existing[sec] = r
-			}
-			if r.Status != bizConsts.Scheduled && r.Status != bizConsts.FailureSkip && r.Status != bizConsts.ConcurrentSkip && r.Status != bizConsts.OverlapSkip && lastEffective == nil {
-				lastEffective = r
-			}
-		}
-		// 已存在当前秒调度则跳过
-		if _, ok := existing[now.Unix()]; ok {
+		p := e.plan(ctx, task, now)
+		if !p.cursor.Before(now) {
 			continue
 		}
-		// overlap 检查（是否有之前的 pending）
-		var hasPending bool
-		var pendingRunningIDs []int64
-		for _, r := range recentRuns {
-			if r.ScheduledTime.After(now) { // 仅关注过去或当前
-				continue
-			}
-			if r.Status == bizConsts.Running || r.Status == bizConsts.Scheduled {
-				hasPending = true
-				if r.Status == bizConsts.Running {
-					pendingRunningIDs = append(pendingRunningIDs, r.ID)
-				}
-			}
+		from := p.cursor
+		if lb := now.Add(-e.cfg.MisfireMaxLookback); from.Before(lb) {
+			from = lb
 		}
-		ignoreConcurrency := false
-		if hasPending {
-			switch task.OverlapAction {
-			case bizConsts.OverlapActionSkip:
-				run := &model.TaskRun{TaskID: task.ID, ScheduledTime: now, Attempt: nextAttempt(lastEffective, false)}
-				if err := e.RunDao.CreateSkipped(ctx, run, bizConsts.OverlapSkip); err == nil {
-					logging.Info(ctx, fmt.Sprintf("task %d overlap skip", task.ID))
-				}
-				continue
-			case bizConsts.OverlapActionCancelPrev:
-				for _, rid := range pendingRunningIDs {
-					e.Exec.CancelRun(rid)
-				}
-			case bizConsts.OverlapActionParallel:
-				ignoreConcurrency = true
-			case bizConsts.OverlapActionAllow:
-				// fallthrough
-			}
+		p.cursor = now
+		if p.sched == nil {
+			e.stale[task.ID] = struct{}{}
+			continue
 		}
-		failedPrev := lastEffective != nil && isFailureStatus(lastEffective.Status)
-		attempt := 1
-		if failedPrev {
-			var alreadySkipped bool
-			if lastEffective != nil {
-				for _, r := range recentRuns {
-					if (r.Status == bizConsts.FailureSkip || r.Status == bizConsts.ConcurrentSkip || r.Status == bizConsts.OverlapSkip) &&
-						r.Attempt == lastEffective.Attempt+1 &&
-						r.ScheduledTime.After(lastEffective.ScheduledTime) {
-						alreadySkipped = true
-						break
-					}
-				}
-			}
-			switch task.FailureAction {
-			case bizConsts.FailureActionSkip:
-				if lastEffective == nil {
-					attempt = 1
-				} else if !alreadySkipped {
-					run := &model.TaskRun{TaskID: task.ID, ScheduledTime: now, Attempt: lastEffective.Attempt + 1}
-					if err := e.RunDao.CreateSkipped(ctx, run, bizConsts.FailureSkip); err == nil {
-						logging.Info(ctx, fmt.Sprintf("task %d failure skip attempt=%d", task.ID, run.Attempt))
-					}
-					continue
-				} else {
-					attempt = lastEffective.Attempt + 2
-				}
-			case bizConsts.FailureActionRetry:
-				if lastEffective != nil {
-					attempt = lastEffective.Attempt + 1
-				}
-			case bizConsts.FailureActionRunNew:
-				attempt = 1
-			}
+		due, total := dueFires(p.sched, from.In(p.loc), now, e.cfg.MisfireMaxCatchup)
+		if total == 0 {
+			e.stale[task.ID] = struct{}{}
+			continue
 		}
-		// concurrency
-		if !ignoreConcurrency && task.MaxConcurrency > 0 && e.Exec.ActiveCount(task.ID) >= task.MaxConcurrency {
-			switch task.ConcurrencyPolicy {
-			case bizConsts.ConcurrencySkip:
-				run := &model.TaskRun{TaskID: task.ID, ScheduledTime: now, Attempt: attempt}
-				if err := e.RunDao.CreateSkipped(ctx, run, bizConsts.ConcurrentSkip); err == nil {
-					logging.Info(ctx, fmt.Sprintf("task %d concurrency skip", task.ID))
-				}
+		delete(e.stale, task.ID)
+		passed = append(passed, task.ID)
+		fires := e.selectFires(task, due, now)
+		if missed := total - len(fires); missed > 0 {
+			logging.Warn(ctx, fmt.Sprintf("task %d misfire policy=%s due=%d firing=%d dropped=%d", task.ID, misfirePolicy(task), total, len(fires), missed))
+		}
+		for _, ft := range fires {
+			if b := e.Calendars.Blackout(task.ID, ft); b != nil {
+				logging.Info(ctx, fmt.Sprintf("task %d fire %s skipped by blackout window %d(%s)", task.ID, ft.UTC().Format(time.RFC3339), b.ID, b.Name))
 				continue
-			case bizConsts.ConcurrencyParallel:
-				logging.Info(ctx, fmt.Sprintf("task %d concurrency parallel policy ignore limit", task.ID))
 			}
+			e.fire(ctx, task, ft.UTC())
 		}
-		// schedule normal
-		// 使用 TaskService.CreateTaskRun 工厂方法
-		run := e.TaskSvc.CreateTaskRun(task, now, attempt)
-		if run.TargetService == "" {
-			logging.Error(ctx, fmt.Sprintf("task %d target_service is empty, skipping", task.ID))
-			continue
+	}
+	for id := range e.plans {
+		if _, ok := seen[id]; !ok {
+			delete(e.plans, id)
 		}
-
-		if err := e.RunDao.CreateScheduled(ctx, run); err != nil {
-			logging.Info(ctx, fmt.Sprintf("task %d create scheduled failed err=%v", task.ID, err))
-			continue
+	}
+	for id := range e.stale {
+		if _, ok := seen[id]; !ok {
+			delete(e.stale, id)
+		}
+	}
+	if len(passed) > 0 {
+		if err := e.TaskSvc.MarkEvaluated(ctx, passed, now); err != nil {
+			logging.Error(ctx, fmt.Sprintf("persist scheduler cursor failed: %v", err))
 		}
-		logging.Info(ctx, fmt.Sprintf("task %d scheduled run=%d attempt=%d", task.ID, run.ID, attempt))
-		e.Exec.Enqueue(run)
 	}
+	e.flushCursors(ctx, now)
 	return nil
 }
 
-func isFailureStatus(s bizConsts.RunStatus) bool {
-	switch s {
-	case bizConsts.Failed, bizConsts.Timeout, bizConsts.FailedTimeout, bizConsts.Canceled:
-		return true
-	default:
-		return false
+// flushCursors 每隔 cursor_flush_interval 把区间内无触发点的游标批量写回数据库。
+// 每次 scan 都把所有任务的游标推进到 now，因此这里统一写 now；写失败时保留，下个周期重试。
+func (e *Engine) flushCursors(ctx context.Context, now time.Time) {
+	if len(e.stale) == 0 || now.Sub(e.flushedAt) < e.cfg.CursorFlushInterval {
+		return
+	}
+	ids := make([]int64, 0, len(e.stale))
+	for id := range e.stale {
+		ids = append(ids, id)
+	}
+	if err := e.TaskSvc.MarkEvaluated(ctx, ids, now); err != nil {
+		logging.Error(ctx, fmt.Sprintf("flush scheduler cursors failed: %v", err))
+		return
 	}
+	e.stale = make(map[int64]struct{})
+	e.flushedAt = now
 }
 
-func nextAttempt(prev *model.TaskRun, failedPrev bool) int {
-	if prev == nil || !failedPrev {
-		return 1
+// dueFires 返回 (after, now] 内的触发时间（升序），最多保留最近 limit 个；total 为区间内的总数。
+func dueFires(s cron.Schedule, after, now time.Time, limit int) ([]time.Time, int) {
+	var fires []time.Time
+	total := 0
+	for t := s.Next(after); !t.IsZero() && !t.After(now); t = s.Next(t) {
+		total++
+		fires = append(fires, t)
+		if len(fires) > limit {
+			fires = fires[1:]
+		}
 	}
-	return prev.Attempt + 1
+	return fires, total
 }
 
-// shouldFire 高级 Cron 匹配：支持 *, 数值, a-b, a-b/n, */n, a/n (起始+步长), 逗号列表。
-func shouldFire(t time.Time, expr string) bool {
-	parts := strings.Fields(strings.TrimSpace(expr))
-	if len(parts) == 5 { // 兼容 5 字段：补秒=0
-		parts = append([]string{"0"}, parts...)
-	}
-	if len(parts) != 6 {
-		return false
+// selectFires 按任务的 MisfirePolicy 从到期触发点中选出要执行的部分。
+// 触发时间距 now 不超过 grace（misfire_grace_sec，<=0 取 poll_interval）视为准点，不算错过。
+func (e *Engine) selectFires(task *model.Task, due []time.Time, now time.Time) []time.Time {
+	if len(due) == 0 {
+		return nil
 	}
-	vals := []int{t.Second(), t.Minute(), t.Hour(), t.Day(), int(t.Month()), int(t.Weekday())}
-	ranges := [][2]int{{0, 59}, {0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
-	for i, field := range parts {
-		if !matchField(vals[i], field, ranges[i][0], ranges[i][1]) {
-			return false
+	latest := due[len(due)-1]
+	switch misfirePolicy(task) {
+	case bizConsts.MisfireFireAll:
+		return due
+	case bizConsts.MisfireFireOnce:
+		return []time.Time{latest}
+	default:
+		grace := time.Duration(task.MisfireGraceSec) * time.Second
+		if grace <= 0 {
+			grace = e.cfg.PollInterval
+		}
+		if now.Sub(latest) <= grace {
+			return []time.Time{latest}
 		}
+		return nil
 	}
-	return true
 }
 
-func matchField(val int, expr string, min, max int) bool {
-	expr = strings.TrimSpace(expr)
-	if expr == "*" || expr == "?" { // 支持 ?
-		return true
+func misfirePolicy(task *model.Task) bizConsts.MisfirePolicy {
+	if task.MisfirePolicy == "" {
+		return bizConsts.DEFAULT_MISFIRE_POLICY
 	}
-	// 逗号列表
-	for _, seg := range splitComma(expr) {
-		seg = strings.TrimSpace(seg)
-		if seg == "" {
-			continue
+	return task.MisfirePolicy
+}
+
+// fire 按 overlap / failure / concurrency 策略处理一次触发。
+func (e *Engine) fire(ctx context.Context, task *model.Task, now time.Time) {
+	// 最近运行记录（用于 overlap / failure / dedup）；补跑 Run 针对历史触发点，不参与
+	recentRuns, _ := e.RunDao.ListByTask(ctx, task.ID, 50)
+	recentRuns = withoutBackfill(recentRuns)
+	existing := make(map[int64]*model.TaskRun)
+	var lastEffective *model.TaskRun
+	for _, r := range recentRuns {
+		sec := r.ScheduledTime.Unix()
+		if _, ok := existing[sec]; !ok && r.TriggerType != bizConsts.TriggerDependency && r.TriggerType != bizConsts.TriggerRerun {
+			existing[sec] = r
 		}
-		if matchSegment(val, seg, min, max) {
-			return true
+		if r.Status != bizConsts.Scheduled && r.Status != bizConsts.Queued && r.Status != bizConsts.FailureSkip && r.Status != bizConsts.ConcurrentSkip && r.Status != bizConsts.OverlapSkip && lastEffective == nil {
+			lastEffective = r
 		}
 	}
-	return false
-}
-
-func matchSegment(val int, seg string, min, max int) bool {
-	// */n
-	if strings.HasPrefix(seg, "*/") {
-		step := toInt(seg[2:])
-		if step <= 0 {
-			return false
+	// 已存在当前秒调度则跳过
+	if _, ok := existing[now.Unix()]; ok {
+		return
+	}
+	// overlap 检查（是否有之前的 pending）
+	var hasPending bool
+	var notStarted int              // 已创建未开始的 Run（含本轮补跑刚创建的），执行器的 ActiveCount 尚未计入
+	var prevActive []*model.TaskRun // 执行中（含等待回调）的上一轮
+	for _, r := range recentRuns {
+		if r.ScheduledTime.After(now) { // 仅关注过去或当前
+			continue
 		}
-		return (val-min)%step == 0
-	}
-	// a/b (起始+步长)
-	if strings.Contains(seg, "/") && !strings.HasPrefix(seg, "*/") {
-		parts := strings.Split(seg, "/")
-		if len(parts) == 2 {
-			start := toInt(parts[0])
-			step := toInt(parts[1])
-			if start < min || start > max || step <= 0 {
-				return false
-			}
-			if val < start {
-				return false
+		if r.Status == bizConsts.Running || r.Status == bizConsts.Scheduled || r.Status == bizConsts.Queued {
+			hasPending = true
+		}
+		if r.Status == bizConsts.Scheduled {
+			notStarted++
+		}
+		if r.Status == bizConsts.Running || r.Status == bizConsts.CallbackPending {
+			prevActive = append(prevActive, r)
+		}
+	}
+	ignoreConcurrency := false
+	// CANCEL_PREV：取消执行中的上一轮，新 Run 在其结束（下游确认或宽限期后 KILLED）后再开始
+	var cancelPrev []*model.TaskRun
+	if task.OverlapAction == bizConsts.OverlapActionCancelPrev {
+		cancelPrev = prevActive
+	}
+	if hasPending {
+		switch task.OverlapAction {
+		case bizConsts.OverlapActionSkip:
+			run := &model.TaskRun{TaskID: task.ID, TaskVersion: task.Version, Namespace: task.Namespace, ScheduledTime: now, Attempt: nextAttempt(lastEffective, false)}
+			if err := e.RunDao.CreateSkipped(ctx, run, bizConsts.OverlapSkip); err == nil {
+				logging.Info(ctx, fmt.Sprintf("task %d overlap skip", task.ID))
 			}
-			return (val-start)%step == 0
+			return
+		case bizConsts.OverlapActionCancelPrev:
+			// 见上方 cancelPrev
+		case bizConsts.OverlapActionParallel:
+			ignoreConcurrency = true
+		case bizConsts.OverlapActionAllow:
+			// fallthrough
 		}
 	}
-	// a-b 或 a-b/n
-	if strings.Contains(seg, "-") {
-		var rangePart, stepPart string
-		if strings.Contains(seg, "/") { // a-b/n
-			ps := strings.Split(seg, "/")
-			if len(ps) != 2 {
-				return false
+	failedPrev := lastEffective != nil && isFailureStatus(lastEffective.Status)
+	attempt := 1
+	if failedPrev {
+		var alreadySkipped bool
+		if lastEffective != nil {
+			for _, r := range recentRuns {
+				if (r.Status == bizConsts.FailureSkip || r.Status == bizConsts.ConcurrentSkip || r.Status == bizConsts.OverlapSkip) &&
+					r.Attempt == lastEffective.Attempt+1 &&
+					r.ScheduledTime.After(lastEffective.ScheduledTime) {
+					alreadySkipped = true
+					break
+				}
 			}
-			rangePart = ps[0]
-			stepPart = ps[1]
-		} else {
-			rangePart = seg
 		}
-		rg := strings.Split(rangePart, "-")
-		if len(rg) != 2 {
-			return false
+		switch task.FailureAction {
+		case bizConsts.FailureActionSkip:
+			if lastEffective == nil {
+				attempt = 1
+			} else if !alreadySkipped {
+				run := &model.TaskRun{TaskID: task.ID, TaskVersion: task.Version, Namespace: task.Namespace, ScheduledTime: now, Attempt: lastEffective.Attempt + 1}
+				if err := e.RunDao.CreateSkipped(ctx, run, bizConsts.FailureSkip); err == nil {
+					logging.Info(ctx, fmt.Sprintf("task %d failure skip attempt=%d", task.ID, run.Attempt))
+				}
+				return
+			} else {
+				attempt = lastEffective.Attempt + 2
+			}
+		case bizConsts.FailureActionRetry:
+			if lastEffective != nil {
+				attempt = lastEffective.Attempt + 1
+			}
+		case bizConsts.FailureActionRunNew:
+			attempt = 1
 		}
-		start := toInt(rg[0])
-		end := toInt(rg[1])
-		if start > end || start < min || end > max {
-			return false
+	}
+	// concurrency；QUEUE 策略由持久化队列按数据库中的占用数控制
+	queue := !ignoreConcurrency && UsesQueue(task)
+	if !ignoreConcurrency && !queue && len(cancelPrev) == 0 && task.MaxConcurrency > 0 && e.Exec.ActiveCount(task.ID)+notStarted >= task.MaxConcurrency {
+		switch task.ConcurrencyPolicy {
+		case bizConsts.ConcurrencySkip:
+			run := &model.TaskRun{TaskID: task.ID, TaskVersion: task.Version, Namespace: task.Namespace, ScheduledTime: now, Attempt: attempt}
+			if err := e.RunDao.CreateSkipped(ctx, run, bizConsts.ConcurrentSkip); err == nil {
+				logging.Info(ctx, fmt.Sprintf("task %d concurrency skip", task.ID))
+			}
+			return
+		case bizConsts.ConcurrencyParallel:
+			logging.Info(ctx, fmt.Sprintf("task %d concurrency parallel policy ignore limit", task.ID))
 		}
-		if stepPart == "" {
-			return val >= start && val <= end
+	}
+	// schedule normal
+	// 使用 TaskService.CreateTaskRun 工厂方法
+	run := e.TaskSvc.CreateTaskRun(task, now, attempt)
+	if run.TargetService == "" {
+		logging.Error(ctx, fmt.Sprintf("task %d target_service is empty, skipping", task.ID))
+		return
+	}
+	if queue {
+		if len(cancelPrev) > 0 { // 排队的 Run 在上一轮结束、腾出占用后自然出队
+			go e.Exec.CancelAndWait(context.WithoutCancel(ctx), cancelPrev, bizConsts.CancelReasonOverlap)
 		}
-		step := toInt(stepPart)
-		if step <= 0 {
-			return false
+		if err := e.Exec.SubmitQueued(ctx, task, run); err != nil {
+			if errors.Is(err, dao.ErrDuplicateRun) {
+				logging.Info(ctx, fmt.Sprintf("task %d run for %s already exists, skip", task.ID, now.Format(time.RFC3339)))
+			} else if !errors.Is(err, ErrQueueFull) {
+				logging.Info(ctx, fmt.Sprintf("task %d queue run failed err=%v", task.ID, err))
+			}
+			return
 		}
-		if val < start || val > end {
-			return false
+		logging.Info(ctx, fmt.Sprintf("task %d queued run=%d attempt=%d", task.ID, run.ID, attempt))
+		return
+	}
+
+	if err := e.RunDao.CreateScheduled(ctx, run); err != nil {
+		if errors.Is(err, dao.ErrDuplicateRun) { // 其他副本已为该触发点建过 Run
+			logging.Info(ctx, fmt.Sprintf("task %d run for %s already exists, skip", task.ID, now.Format(time.RFC3339)))
+			return
 		}
-		return (val-start)%step == 0
+		logging.Info(ctx, fmt.Sprintf("task %d create scheduled failed err=%v", task.ID, err))
+		return
 	}
-	// 单值
-	v := toInt(seg)
-	if v < min || v > max {
-		return false
+	logging.Info(ctx, fmt.Sprintf("task %d scheduled run=%d attempt=%d", task.ID, run.ID, attempt))
+	if len(cancelPrev) > 0 {
+		go func(ctx context.Context) {
+			e.Exec.CancelAndWait(ctx, cancelPrev, bizConsts.CancelReasonOverlap)
+			e.Exec.Enqueue(run)
+		}(context.WithoutCancel(ctx))
+		return
 	}
-	return v == val
+	e.Exec.Enqueue(run)
 }
 
-func splitComma(s string) []string {
-	var r []string
-	cur := ""
-	for _, c := range s {
-		if c == ',' {
-			if cur != "" {
-				r = append(r, cur)
-				cur = ""
-			}
-			continue
-		}
-		cur += string(c)
+func isFailureStatus(s bizConsts.RunStatus) bool {
+	switch s {
+	case bizConsts.Failed, bizConsts.Timeout, bizConsts.FailedTimeout, bizConsts.Canceled, bizConsts.Killed:
+		return true
+	default:
+		return false
 	}
-	if cur != "" {
-		r = append(r, cur)
+}
+
+func nextAttempt(prev *model.TaskRun, failedPrev bool) int {
+	if prev == nil || !failedPrev {
+		return 1
 	}
-	return r
+	return prev.Attempt + 1
 }
 
-func toInt(s string) int {
-	n := 0
-	if s == "" {
-		return -1
-	}
-	neg := false
-	if s[0] == '+' {
-		s = s[1:]
-	} else if s[0] == '-' {
-		neg = true
-		s = s[1:]
-	}
-	for _, c := range s {
-		if c >= '0' && c <= '9' {
-			n = n*10 + int(c-'0')
-		} else {
-			return -1
+func withoutBackfill(runs []*model.TaskRun) []*model.TaskRun {
+	out := make([]*model.TaskRun, 0, len(runs))
+	for _, r := range runs {
+		if r.BackfillID == nil {
+			out = append(out, r)
 		}
 	}
-	if neg {
-		return -1
-	}
-	return n
+	return out
 }
-
-// Debug helper (not used currently)
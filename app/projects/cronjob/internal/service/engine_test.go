@@ -5,14 +5,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
 )
 
-// stubRunDao captures created runs
+// stubRunDao captures created runs; methods the engine does not use fall through to the nil embedded RunDao.
 type stubRunDao struct {
-	runs   []*model.TaskRun
-	nextID int64
+	dao.RunDao
+	runs    []*model.TaskRun
+	nextID  int64
+	lineage map[int64][]int64
 }
 
 func (r *stubRunDao) Start(_ context.Context) error { return nil }
@@ -35,7 +39,16 @@ func (r *stubRunDao) CreateSkipped(_ context.Context, run *model.TaskRun, skipTy
 func (r *stubRunDao) TransitionToRunning(_ context.Context, _ int64) (bool, error)  { return false, nil }
 func (r *stubRunDao) MarkSuccess(_ context.Context, _ int64, _ int, _ string) error { return nil }
 func (r *stubRunDao) MarkFailed(_ context.Context, _ int64, _ string) error         { return nil }
-func (r *stubRunDao) MarkCanceled(_ context.Context, _ int64) error                 { return nil }
+func (r *stubRunDao) MarkCanceled(_ context.Context, runID int64, reason bizConsts.CancelReason) error {
+	for _, ru := range r.runs {
+		if ru.ID == runID && !ru.Status.Finished() {
+			ru.Status, ru.CancelReason = bizConsts.Canceled, reason
+			now := time.Now()
+			ru.EndTime = &now
+		}
+	}
+	return nil
+}
 func (r *stubRunDao) MarkSkipped(_ context.Context, runID int64, skipType bizConsts.RunStatus) error {
 	for _, ru := range r.runs {
 		if ru.ID == runID {
@@ -47,7 +60,14 @@ func (r *stubRunDao) MarkSkipped(_ context.Context, runID int64, skipType bizCon
 	return nil
 }
 func (r *stubRunDao) MarkTimeout(_ context.Context, _ int64, _ string) error { return nil }
-func (r *stubRunDao) Get(_ context.Context, _ int64) (*model.TaskRun, error) { return nil, nil }
+func (r *stubRunDao) Get(_ context.Context, runID int64) (*model.TaskRun, error) {
+	for _, ru := range r.runs {
+		if ru.ID == runID {
+			return ru, nil
+		}
+	}
+	return nil, nil
+}
 func (r *stubRunDao) ListByTask(_ context.Context, taskID int64, limit int) ([]*model.TaskRun, error) {
 	var out []*model.TaskRun
 	for _, ru := range r.runs {
@@ -65,6 +85,26 @@ func (r *stubRunDao) ListByTask(_ context.Context, taskID int64, limit int) ([]*
 	return out, nil
 }
 
+// ListActiveFiltered 与 DAO 一致按 id 倒序分页。
+func (r *stubRunDao) ListActiveFiltered(_ context.Context, statuses []bizConsts.RunStatus, _, _ *time.Time, limit, offset int, _ string) ([]*model.TaskRun, error) {
+	var out []*model.TaskRun
+	for i := len(r.runs) - 1; i >= 0; i-- {
+		for _, st := range statuses {
+			if r.runs[i].Status == st {
+				out = append(out, r.runs[i])
+			}
+		}
+	}
+	if offset > len(out) {
+		offset = len(out)
+	}
+	out = out[offset:]
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
 // stubExecutor only tracks enqueued runs & active count
 type stubExecutor struct {
 	enqueued []*model.TaskRun
@@ -111,3 +151,174 @@ func TestFailureRetryIncrement(t *testing.T) {
 		t.Fatalf("expected attempt=3, got %d", run.Attempt)
 	}
 }
+
+// Test scan fires on the task's own timezone regardless of poll alignment, once per fire time
+func TestEngineScanTimezone(t *testing.T) {
+	task := &model.Task{ID: 3, CronExpr: "0 30 9 * * *", Timezone: "Asia/Shanghai", TargetService: "artemis", Status: bizConsts.ENABLED, OverlapAction: bizConsts.OverlapActionAllow, FailureAction: bizConsts.FailureActionRunNew}
+	ts := NewTaskService()
+	ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{3: task}}
+	if err := ts.Start(context.Background()); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	runDao := &stubRunDao{}
+	e := NewEngine(config.SchedulerConfig{PollInterval: time.Minute})
+	e.TaskSvc, e.RunDao, e.Exec = ts, runDao, NewExecutor(config.ExecutorConfig{})
+
+	// 01:29:40 UTC = 09:29:40 CST; poll ticks every 60s never land on :00
+	start := time.Date(2026, 10, 16, 1, 29, 40, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if err := e.scan(context.Background(), start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+	}
+	if len(runDao.runs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(runDao.runs))
+	}
+	if want := time.Date(2026, 10, 16, 1, 30, 0, 0, time.UTC); !runDao.runs[0].ScheduledTime.Equal(want) {
+		t.Fatalf("scheduled_time = %s, want %s", runDao.runs[0].ScheduledTime, want)
+	}
+}
+
+// Test restart catch-up from the persisted cursor for each misfire policy
+func TestEngineMisfirePolicies(t *testing.T) {
+	cursor := time.Date(2026, 10, 16, 1, 0, 0, 0, time.UTC) // 09:00 CST, last evaluated before a deploy
+	now := time.Date(2026, 10, 16, 1, 35, 20, 0, time.UTC)  // first tick after restart
+	cases := []struct {
+		policy bizConsts.MisfirePolicy
+		grace  int
+		want   []string // scheduled times (UTC HH:MM)
+	}{
+		{bizConsts.MisfireSkip, 0, nil},
+		{bizConsts.MisfireSkip, 600, []string{"01:30"}},
+		{bizConsts.MisfireFireOnce, 0, []string{"01:30"}},
+		{bizConsts.MisfireFireAll, 0, []string{"01:10", "01:20", "01:30"}},
+	}
+	for _, c := range cases {
+		task := &model.Task{ID: 4, CronExpr: "0 */10 9 * * *", Timezone: "Asia/Shanghai", TargetService: "artemis", Status: bizConsts.ENABLED,
+			OverlapAction: bizConsts.OverlapActionAllow, FailureAction: bizConsts.FailureActionRunNew,
+			MisfirePolicy: c.policy, MisfireGraceSec: c.grace, LastEvaluatedAt: &cursor}
+		taskDao := &stubDao{tasks: map[int64]*model.Task{4: task}}
+		ts := NewTaskService()
+		ts.TaskDao = taskDao
+		if err := ts.Start(context.Background()); err != nil {
+			t.Fatalf("start failed: %v", err)
+		}
+		runDao := &stubRunDao{}
+		e := NewEngine(config.SchedulerConfig{PollInterval: time.Minute})
+		e.TaskSvc, e.RunDao, e.Exec = ts, runDao, NewExecutor(config.ExecutorConfig{})
+		if err := e.scan(context.Background(), now); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		var got []string
+		for _, r := range runDao.runs {
+			got = append(got, r.ScheduledTime.Format("15:04"))
+		}
+		if len(got) != len(c.want) {
+			t.Fatalf("%s grace=%d: runs %v, want %v", c.policy, c.grace, got, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("%s grace=%d: runs %v, want %v", c.policy, c.grace, got, c.want)
+			}
+		}
+		if le := taskDao.tasks[4].LastEvaluatedAt; le == nil || !le.Equal(now) {
+			t.Fatalf("cursor not persisted: %v", le)
+		}
+	}
+}
+
+// Test cursors without a due fire are not written every tick, only batched per cursor_flush_interval
+func TestEngineCursorPersistence(t *testing.T) {
+	cursor := time.Date(2026, 10, 16, 1, 0, 0, 0, time.UTC)
+	task := &model.Task{ID: 5, CronExpr: "0 30 9 * * *", Timezone: "Asia/Shanghai", TargetService: "artemis", Status: bizConsts.ENABLED,
+		OverlapAction: bizConsts.OverlapActionAllow, FailureAction: bizConsts.FailureActionRunNew, LastEvaluatedAt: &cursor}
+	taskDao := &stubDao{tasks: map[int64]*model.Task{5: task}}
+	ts := NewTaskService()
+	ts.TaskDao = taskDao
+	if err := ts.Start(context.Background()); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	e := NewEngine(config.SchedulerConfig{CursorFlushInterval: 5 * time.Minute})
+	e.TaskSvc, e.RunDao, e.Exec = ts, &stubRunDao{}, NewExecutor(config.ExecutorConfig{})
+	start := time.Date(2026, 10, 16, 1, 29, 10, 0, time.UTC)
+	e.flushedAt = start
+	persisted := func() time.Time { return *taskDao.tasks[5].LastEvaluatedAt }
+
+	// 09:29:10-09:29:40 CST: no fire time passes, cursor stays in memory
+	for i := 0; i < 4; i++ {
+		if err := e.scan(context.Background(), start.Add(time.Duration(i)*10*time.Second)); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+	}
+	if !persisted().Equal(cursor) {
+		t.Fatalf("cursor written without a due fire: %v", persisted())
+	}
+	// 09:30:00 passes: persisted on the same tick
+	fire := time.Date(2026, 10, 16, 1, 30, 5, 0, time.UTC)
+	if err := e.scan(context.Background(), fire); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if !persisted().Equal(fire) {
+		t.Fatalf("cursor after fire = %v, want %v", persisted(), fire)
+	}
+	// quiet again: batched once the flush interval elapses
+	later := fire.Add(10 * time.Second)
+	if err := e.scan(context.Background(), later); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if !persisted().Equal(fire) {
+		t.Fatalf("cursor flushed before interval: %v", persisted())
+	}
+	flush := start.Add(5 * time.Minute)
+	if err := e.scan(context.Background(), flush); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if !persisted().Equal(flush) {
+		t.Fatalf("cursor after flush interval = %v, want %v", persisted(), flush)
+	}
+}
+
+// Test FIRE_ALL catch-up respects overlap SKIP and the concurrency limit for runs created in the same tick
+func TestEngineFireAllCatchUpLimits(t *testing.T) {
+	cursor := time.Date(2026, 10, 16, 1, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 10, 16, 1, 35, 20, 0, time.UTC)
+	cases := []struct {
+		name    string
+		overlap bizConsts.OverlapAction
+		maxConc int
+		policy  bizConsts.ConcurrencyPolicy
+		skip    bizConsts.RunStatus
+	}{
+		{"overlap skip", bizConsts.OverlapActionSkip, 0, "", bizConsts.OverlapSkip},
+		{"concurrency skip", bizConsts.OverlapActionAllow, 1, bizConsts.ConcurrencySkip, bizConsts.ConcurrentSkip},
+	}
+	for _, c := range cases {
+		task := &model.Task{ID: 7, CronExpr: "0 */10 9 * * *", Timezone: "Asia/Shanghai", TargetService: "artemis", Status: bizConsts.ENABLED,
+			OverlapAction: c.overlap, FailureAction: bizConsts.FailureActionRunNew, MaxConcurrency: c.maxConc, ConcurrencyPolicy: c.policy,
+			MisfirePolicy: bizConsts.MisfireFireAll, LastEvaluatedAt: &cursor}
+		ts := NewTaskService()
+		ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{7: task}}
+		if err := ts.Start(context.Background()); err != nil {
+			t.Fatalf("start failed: %v", err)
+		}
+		runDao := &stubRunDao{}
+		e := NewEngine(config.SchedulerConfig{PollInterval: time.Minute})
+		e.TaskSvc, e.RunDao, e.Exec = ts, runDao, NewExecutor(config.ExecutorConfig{})
+		if err := e.scan(context.Background(), now); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		var got []string
+		for _, r := range runDao.runs {
+			got = append(got, r.ScheduledTime.Format("15:04")+" "+string(r.Status))
+		}
+		want := []string{"01:10 " + string(bizConsts.Scheduled), "01:20 " + string(c.skip), "01:30 " + string(c.skip)}
+		if len(got) != len(want) {
+			t.Fatalf("%s: runs %v, want %v", c.name, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s: runs %v, want %v", c.name, got, want)
+			}
+		}
+	}
+}
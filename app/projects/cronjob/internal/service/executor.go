@@ -19,13 +19,18 @@ import (
 	"github.com/tidwall/gjson"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/grpc_client"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/http_client"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/prometheus"
+	infraRedis "github.com/grand-thief-cash/chaos/app/infra/go/application/components/redis"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/render"
 )
 
 // Config holds executor runtime parameters
@@ -37,18 +42,32 @@ import (
 type Executor struct {
 	*core.BaseComponent
 
-	cfg     config.ExecutorConfig
-	TaskSvc *TaskService `infra:"dep:task_service"`
-	RunSvc  *RunService  `infra:"dep:run_service"`
+	cfg        config.ExecutorConfig
+	TaskSvc    *TaskService      `infra:"dep:task_service"`
+	RunSvc     *RunService       `infra:"dep:run_service"`
+	Calendars  *CalendarService  `infra:"dep:calendar_service"`  // 模板函数 prevBusinessDay 等使用
+	Namespaces *NamespaceService `infra:"dep:namespace_service"` // 空间的并发配额与 target_service 白名单
 	// Injected HTTP client with OTEL support
 	HTTPCli       *http_client.HTTPClientsComponent `infra:"dep:http_clients"`
+	GRPCCli       *grpc_client.GRPCClientComponent  `infra:"dep:grpc_clients?"` // GRPC 后端，未启用时为空
+	Redis         *infraRedis.RedisComponent        `infra:"dep:redis?"`        // REDIS_STREAM 后端，未启用时为空
+	backends      map[bizConsts.ExecutorKind]Backend
+	grpc          *grpcBackend
 	ch            chan *model.TaskRun
 	wg            sync.WaitGroup
 	mu            sync.Mutex
 	cancel        context.CancelFunc
-	cancelMap     map[int64]context.CancelFunc // runID -> cancel func 	// In execute we create timeout contexts per run; cancelMap stores per-run cancel funcs.
-	activePerTask map[int64]int                // taskID -> running count
-	Progress      *RunProgressManager          `infra:"dep:run_progress_mgr"`
+	cancelMap     map[int64]context.CancelCauseFunc // runID -> cancel func，取消原因见 cancelCause
+	activePerTask map[int64]int                     // taskID -> running count
+	Progress      *RunProgressManager               `infra:"dep:run_progress_mgr"`
+	metrics       *executorMetrics                  // 未启用 prometheus 时为 nil
+	wake          chan struct{}                     // 唤醒 dispatchLoop（容量 1，多次唤醒合并）
+	promote       map[int64]struct{}                // 待提升排队 Run 的任务（由 dispatchLoop 处理）
+	restore       bool                              // 待执行的遗留 SCHEDULED Run 恢复（由 dispatchLoop 处理）
+	queued        map[int64]struct{}                // 已在内存队列中、尚未被 worker 取走的 Run，避免重复入队
+	quit          chan struct{}                     // Stop 时关闭，解除阻塞中的 Enqueue
+	deferred      map[string][]*model.TaskRun       // 空间并发配额已满而暂缓的 Run（按 id 升序，仍为 SCHEDULED）
+	deferHead     map[string]int64                  // 空间正在重新准入的队首 Run
 }
 
 func NewExecutor(cfg config.ExecutorConfig) *Executor {
@@ -58,13 +77,40 @@ func NewExecutor(cfg config.ExecutorConfig) *Executor {
 	if cfg.RequestTimeout <= 0 {
 		cfg.RequestTimeout = 15 * time.Second
 	}
-	return &Executor{
+	if cfg.SecretEnvPrefix == "" {
+		cfg.SecretEnvPrefix = "CRONJOB_SECRET_"
+	}
+	if cfg.Command.DefaultTimeout <= 0 {
+		cfg.Command.DefaultTimeout = 10 * time.Minute
+	}
+	if cfg.Command.MaxOutputBytes <= 0 {
+		cfg.Command.MaxOutputBytes = 64 << 10
+	}
+	if cfg.KillGrace <= 0 {
+		cfg.KillGrace = 30 * time.Second
+	}
+	if cfg.CancelPath == "" {
+		cfg.CancelPath = "/tasks/cancel"
+	}
+	e := &Executor{
 		BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_EXECUTOR, consts.COMPONENT_LOGGING),
 		cfg:           cfg,
 		ch:            make(chan *model.TaskRun, 1024),
-		cancelMap:     make(map[int64]context.CancelFunc),
+		cancelMap:     make(map[int64]context.CancelCauseFunc),
 		activePerTask: make(map[int64]int),
-	}
+		wake:          make(chan struct{}, 1),
+		promote:       make(map[int64]struct{}),
+		queued:        make(map[int64]struct{}),
+		quit:          make(chan struct{}),
+		deferred:      make(map[string][]*model.TaskRun),
+		deferHead:     make(map[string]int64),
+	}
+	e.grpc = &grpcBackend{e: e}
+	e.backends = map[bizConsts.ExecutorKind]Backend{}
+	for _, b := range []Backend{&httpBackend{e: e}, e.grpc, &commandBackend{e: e}, &redisStreamBackend{e: e}} {
+		e.backends[b.Kind()] = b
+	}
+	return e
 }
 
 // Start implements core.Component
@@ -79,12 +125,23 @@ func (e *Executor) Start(ctx context.Context) error {
 	// Derive a new background context for long-lived workers so they don't exit immediately.
 	loopCtx, cancel := context.WithCancel(context.Background())
 	e.cancel = cancel
+	if pc := prometheus.C(); pc != nil {
+		e.metrics = newExecutorMetrics(pc)
+	}
+	if len(e.cfg.GRPC.DescriptorSets) > 0 {
+		if err := e.grpc.load(e.cfg.GRPC.DescriptorSets); err != nil {
+			logging.Error(loopCtx, fmt.Sprintf("load grpc descriptor sets failed; GRPC executor unavailable: %v", err))
+		}
+	}
 	// start workers
 	for i := 0; i < e.cfg.WorkerPoolSize; i++ {
 		e.wg.Add(1)
 		logging.Info(loopCtx, fmt.Sprintf("Starting worker: %d", i))
-		go e.worker(loopCtx)
+		go e.worker(loopCtx, e.ch)
 	}
+	go e.dispatchLoop(loopCtx)
+	// 重建内存队列：上次进程遗留的 SCHEDULED Run 与数据库中排队的 QUEUED Run
+	e.RestoreQueue()
 	return nil
 }
 
@@ -101,15 +158,16 @@ func (e *Executor) Stop(ctx context.Context) error {
 	}
 	e.mu.Unlock()
 	for _, rid := range activeIDs {
-		e.CancelRun(rid)
+		e.cancelLocal(rid, bizConsts.CancelReasonShutdown)
 	}
 	if e.cancel != nil {
 		e.cancel()
 	}
-	// drain channel by closing (workers exit on ctx cancel anyway). Avoid panic on double close.
+	// 不关闭 e.ch（workers 随 ctx 退出）：关闭 quit 让阻塞中的 Enqueue 返回，之后的 Enqueue 见 e.ch 为 nil 直接丢弃；
+	// 未执行的 Run 仍为 SCHEDULED，下次启动时恢复。
 	e.mu.Lock()
 	if e.ch != nil {
-		close(e.ch)
+		close(e.quit)
 		e.ch = nil
 	}
 	e.mu.Unlock()
@@ -117,14 +175,30 @@ func (e *Executor) Stop(ctx context.Context) error {
 	return e.BaseComponent.Stop(ctx)
 }
 
+// Enqueue 把 Run 放入内存队列；已在队列中（尚未被 worker 取走）的 Run 不重复入队。
 func (e *Executor) Enqueue(run *model.TaskRun) { // exposed API
 	e.mu.Lock()
-	if e.ch == nil { // stopped
+	ch, quit := e.ch, e.quit
+	if ch == nil { // stopped
 		e.mu.Unlock()
 		return
 	}
+	if run.ID != 0 {
+		if _, dup := e.queued[run.ID]; dup {
+			e.mu.Unlock()
+			return
+		}
+		e.queued[run.ID] = struct{}{}
+	}
 	e.mu.Unlock()
-	e.ch <- run
+	select {
+	case ch <- run:
+	case <-quit:
+		e.mu.Lock()
+		delete(e.queued, run.ID)
+		e.mu.Unlock()
+		return
+	}
 	// Log with trace ID
 	logCtx := e.ensureTraceContext(context.Background(), run.TraceID)
 	logging.Info(logCtx, fmt.Sprintf("task: %d has enqueued", run.ID))
@@ -136,14 +210,14 @@ func (e *Executor) ActiveCount(taskID int64) int {
 	return e.activePerTask[taskID]
 }
 
-func (e *Executor) worker(ctx context.Context) {
+func (e *Executor) worker(ctx context.Context, ch <-chan *model.TaskRun) {
 	defer e.wg.Done()
 	for {
 		select {
 		case <-ctx.Done():
 			logging.Info(context.Background(), "worker context canceled; exiting")
 			return
-		case run, ok := <-e.ch:
+		case run, ok := <-ch:
 			if !ok { // channel closed
 				logging.Info(context.Background(), "channel closed; worker exiting")
 				return
@@ -151,131 +225,169 @@ func (e *Executor) worker(ctx context.Context) {
 			if run == nil {
 				continue
 			}
+			e.mu.Lock()
+			delete(e.queued, run.ID)
+			e.mu.Unlock()
 			// Reconstruct trace context for the worker execution scope
 			traceCtx := e.ensureTraceContext(context.Background(), run.TraceID)
 
 			logging.Info(traceCtx, fmt.Sprintf("task: %d grabbed in worker ok=%v", run.ID, ok))
-			ok2, err := e.RunSvc.TransitionToRunning(traceCtx, run.ID)
+			ok2, err := e.admit(traceCtx, run)
 			if err != nil || !ok2 {
 				if err != nil {
 					logging.Info(traceCtx, fmt.Sprintf("transition to running failed for run %d: %v", run.ID, err))
 				}
 				continue
 			}
-			e.execute(traceCtx, run)
+			done := e.metrics.begin(traceCtx, e.metricTaskName(traceCtx, run.TaskID), run)
+			done(e.execute(traceCtx, run))
+			e.afterRun(traceCtx, run)
 		}
 	}
 }
 
-func (e *Executor) execute(ctx context.Context, run *model.TaskRun) {
+// admit 把 Run 转为 RUNNING。空间不允许其 target_service 时直接失败；
+// 空间并发配额已满时 Run 保持 SCHEDULED 并暂缓（见 deferRun），不占用 worker；
+// 同空间已有暂缓的 Run 时新 Run 直接排到其后，保证按 id 顺序准入。
+func (e *Executor) admit(ctx context.Context, run *model.TaskRun) (bool, error) {
+	if e.Namespaces == nil {
+		return e.RunSvc.TransitionToRunning(ctx, run.ID)
+	}
+	behind, head := e.takeDeferTurn(run)
+	if behind {
+		return false, nil
+	}
+	ok, err := e.Namespaces.AdmitRun(ctx, e.RunSvc, run)
+	if errors.Is(err, dao.ErrNamespaceQuotaExceeded) {
+		e.deferRun(ctx, run)
+		return false, nil
+	}
+	if head { // 队首已准入（或已结束），让下一个暂缓的 Run 继续
+		e.kick()
+	}
+	if errors.Is(err, ErrTargetNotAllowed) {
+		logging.Error(ctx, fmt.Sprintf("run %d rejected: %v", run.ID, err))
+		_ = e.RunSvc.MarkFailed(ctx, run.ID, fmt.Sprintf("target_service_not_allowed: %v", err))
+		e.afterRun(ctx, run)
+		return false, nil
+	}
+	return ok, err
+}
+
+// execute 执行一次 Run 并返回本次执行落库的状态（ASYNC 第一阶段成功时为 CALLBACK_PENDING）。
+func (e *Executor) execute(ctx context.Context, run *model.TaskRun) bizConsts.RunStatus {
 	// 2. 准备 per-run 上下文 & 资源清理逻辑
 	runCtx, cleanup := e.startRunContext(ctx, run)
 	defer cleanup()
 
-	// 2.5 获取客户端并解析完整 URL
-	targetService := run.TargetService
-	if targetService == "" {
-		errMsg := "target_service is empty"
-		logging.Error(ctx, fmt.Sprintf("run %d failed: %s", run.ID, errMsg))
-		_ = e.RunSvc.MarkFailed(ctx, run.ID, errMsg)
-		return
+	// 2.5 选择执行器后端
+	backend, err := e.backend(run.Executor)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("run %d failed: %v", run.ID, err))
+		_ = e.RunSvc.MarkFailed(ctx, run.ID, fmt.Sprintf("client_config_error: %v", err))
+		return bizConsts.Failed
 	}
-
-	var client *http_client.InstrumentedClient
-	var errCli error
-	if e.HTTPCli != nil {
-		client, errCli = e.HTTPCli.Client(targetService)
-	} else {
-		// Fallback (mostly for tests or incomplete initialization)
-		client = &http_client.InstrumentedClient{Client: &http.Client{Timeout: 15 * time.Second}}
+	spec, err := model.ParseExecutorSpec(run.ExecutorConfig)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("run %d failed: %v", run.ID, err))
+		_ = e.RunSvc.MarkFailed(ctx, run.ID, fmt.Sprintf("client_config_error: %v", err))
+		return bizConsts.Failed
 	}
 
-	if errCli != nil {
-		logging.Error(ctx, fmt.Sprintf("http client for service %s not found: %v", targetService, errCli))
-		_ = e.RunSvc.MarkFailed(ctx, run.ID, fmt.Sprintf("client_config_error: %v", errCli))
-		return
+	// 2.6 渲染路径 / 请求头 / 请求体模板（run 上保存的是任务模板的快照）
+	rendered, err := e.renderRun(ctx, run)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("run %d render templates failed: %v", run.ID, err))
+		_ = e.RunSvc.MarkFailed(ctx, run.ID, fmt.Sprintf("render_failed: %v", err))
+		return bizConsts.Failed
 	}
 
-	// Resolve full URL
-	fullURL := run.TargetPath
-	if !strings.HasPrefix(fullURL, "http://") && !strings.HasPrefix(fullURL, "https://") {
-		baseURL := client.BaseURL
-		// simple joining, assuming valid segments. Ideally use url.JoinPath but we do string concat for now
-		if !strings.HasSuffix(baseURL, "/") && !strings.HasPrefix(fullURL, "/") {
-			fullURL = baseURL + "/" + fullURL
-		} else if strings.HasSuffix(baseURL, "/") && strings.HasPrefix(fullURL, "/") {
-			fullURL = baseURL + strings.TrimPrefix(fullURL, "/")
-		} else {
-			fullURL = baseURL + fullURL
-		}
+	if run.CallbackToken != "" { // 请求快照中的回调 token 与密钥一样打码
+		rendered.Secrets = append(rendered.Secrets, run.CallbackToken)
 	}
 
-	// 3. 构建 HTTP 请求
-	req, err := e.buildRequest(runCtx, run, fullURL)
-	if err != nil {
-		logging.Error(ctx, fmt.Sprintf("create request for task failed %d (run %d): %v", run.TaskID, run.ID, err))
-		_ = e.RunSvc.MarkFailed(ctx, run.ID, "build request failed")
-		return
+	// 3. 调用下游（请求快照由后端在发出前记录）
+	res, err := backend.Invoke(runCtx, &BackendCall{Run: run, Spec: spec, Rendered: rendered})
+	if res != nil && res.Stderr != "" {
+		_ = e.RunSvc.UpdateStderr(ctx, run.ID, res.Stderr)
 	}
-
-	// 3.1 记录本次实际发送的 request headers/body
-	e.persistOutboundSnapshot(ctx, run.ID, req)
-
-	// 4. 执行 HTTP 调用 (含分类错误)
-	resp, body, classify, err := e.doHTTP(runCtx, client.Client, req)
-	if err != nil { // 传输层或上下文异常
-		// 没有 HTTP 响应，按分类更新状态
+	if err != nil {
+		if isInvalidCall(err) { // 配置或请求内容错误，重试无意义
+			logging.Error(ctx, fmt.Sprintf("run %d (%s) invalid call: %v", run.ID, backend.Kind(), err))
+			_ = e.RunSvc.MarkFailed(ctx, run.ID, fmt.Sprintf("client_config_error: %v", err))
+			return bizConsts.Failed
+		}
+		if res != nil {
+			e.persistInboundSnapshot(ctx, run.ID, res.Code, res.Body, "")
+		}
+		// 执行上下文被取消（API / CANCEL_PREV / 停止）或超过 execution_timeout_sec
+		if reason, ok := cancelReasonOf(runCtx); ok {
+			return e.endLocalCanceled(ctx, run, reason)
+		}
+		// 没有下游结果，按分类更新状态
+		classify := e.classifyNetError(runCtx, err)
 		switch classify {
 		case "canceled":
-			_ = e.RunSvc.MarkCanceled(ctx, run.ID)
+			_ = e.RunSvc.MarkCanceled(ctx, run.ID, bizConsts.CancelReasonShutdown)
+			return bizConsts.Canceled
 		case "request_timeout":
 			_ = e.RunSvc.MarkTimeout(ctx, run.ID, classify)
+			e.retryOnFailure(ctx, run, model.RetryOutcome{Kind: model.OutcomeTimeout})
+			return bizConsts.Timeout
 		default:
 			_ = e.RunSvc.MarkFailed(ctx, run.ID, classify)
+			e.retryOnFailure(ctx, run, model.RetryOutcome{Kind: model.OutcomeNetwork})
+			return bizConsts.Failed
 		}
-		return
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	// 5. 统一处理业务响应（同步/异步），并落库响应快照
-	e.persistInboundSnapshot(ctx, run.ID, resp.StatusCode, string(body), "")
+	// 4. 统一处理业务响应（同步/异步），并落库响应快照
+	e.persistInboundSnapshot(ctx, run.ID, res.Code, res.Body, "")
 
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		if run.ExecType == bizConsts.ExecTypeAsync { // 异步第一阶段成功
-			// Calculate deadline from current time (when downstream accepted the task)
-			timeoutSec := run.CallbackTimeoutSec
-			if timeoutSec <= 0 {
-				timeoutSec = 300 // Default 5 minutes if not configured
-			}
-			deadline := time.Now().Add(time.Duration(timeoutSec) * time.Second)
-			logging.Info(ctx, fmt.Sprintf("run %d async phase1 succeeded; transitioning to CALLBACK_PENDING until deadline %s", run.ID, deadline.Format(time.RFC3339)))
-			_ = e.RunSvc.MarkCallbackPendingWithDeadline(ctx, run.ID, deadline)
-			return
+	if res.Failure != "" { // 非 2xx / 非 OK / 非零退出：记录错误详情并标记失败
+		_ = e.RunSvc.MarkFailed(ctx, run.ID, res.Failure)
+		e.persistInboundSnapshot(ctx, run.ID, res.Code, res.Body, res.Failure)
+		e.retryOnFailure(ctx, run, res.Outcome)
+		return bizConsts.Failed
+	}
+
+	if run.ExecType == bizConsts.ExecTypeAsync { // 异步第一阶段成功
+		// Calculate deadline from current time (when downstream accepted the task)
+		timeoutSec := run.CallbackTimeoutSec
+		if timeoutSec <= 0 {
+			timeoutSec = 300 // Default 5 minutes if not configured
 		}
-		// 同步：尝试识别业务失败
-		status := gjson.GetBytes(body, "status").String()
-		errMsg := gjson.GetBytes(body, "error").String()
-		if strings.EqualFold(status, bizConsts.Failed.String()) || strings.TrimSpace(errMsg) != "" {
-			_ = e.RunSvc.MarkFailed(ctx, run.ID, fmt.Sprintf("biz_failed: status=%s error=%s", status, errMsg))
-			e.persistInboundSnapshot(ctx, run.ID, resp.StatusCode, string(body), fmt.Sprintf("biz_failed: status=%s error=%s", status, errMsg))
-		} else {
-			_ = e.RunSvc.MarkSuccess(ctx, run.ID, resp.StatusCode, string(body))
+		deadline := time.Now().Add(time.Duration(timeoutSec) * time.Second)
+		logging.Info(ctx, fmt.Sprintf("run %d async phase1 succeeded; transitioning to CALLBACK_PENDING until deadline %s", run.ID, deadline.Format(time.RFC3339)))
+		_ = e.RunSvc.MarkCallbackPendingWithDeadline(ctx, run.ID, deadline)
+		return bizConsts.CallbackPending
+	}
+	// 同步：尝试识别业务失败（retry_on.biz_status 中的取值也视为失败）
+	status := gjson.Get(res.Body, "status").String()
+	errMsg := gjson.Get(res.Body, "error").String()
+	_, policy := e.TaskSvc.RetryPolicy(ctx, run.TaskID)
+	if strings.EqualFold(status, bizConsts.Failed.String()) || strings.TrimSpace(errMsg) != "" || policy.IsRetryableBizStatus(status) {
+		_ = e.RunSvc.MarkFailed(ctx, run.ID, fmt.Sprintf("biz_failed: status=%s error=%s", status, errMsg))
+		e.persistInboundSnapshot(ctx, run.ID, res.Code, res.Body, fmt.Sprintf("biz_failed: status=%s error=%s", status, errMsg))
+		if policy != nil {
+			e.RunSvc.PlanRetry(ctx, run, policy, model.RetryOutcome{Kind: model.OutcomeBiz, BizStatus: status})
 		}
-		return
+		return bizConsts.Failed
 	}
+	_ = e.RunSvc.MarkSuccess(ctx, run.ID, res.Code, res.Body)
+	return bizConsts.Success
+}
 
-	// 非 2xx：记录错误详情并标记失败
-	msg := resp.Status
-	if len(body) > 0 {
-		msg = fmt.Sprintf("%s; body=%s", msg, string(body))
+// retryOnFailure 按任务的重试策略为失败的 run 计划重试。
+func (e *Executor) retryOnFailure(ctx context.Context, run *model.TaskRun, o model.RetryOutcome) {
+	if _, policy := e.TaskSvc.RetryPolicy(ctx, run.TaskID); policy != nil {
+		e.RunSvc.PlanRetry(ctx, run, policy, o)
 	}
-	_ = e.RunSvc.MarkFailed(ctx, run.ID, msg)
-	e.persistInboundSnapshot(ctx, run.ID, resp.StatusCode, string(body), msg)
 }
 
 // persistOutboundSnapshot captures the effective request headers/body and stores them into task_runs.
 // It also masks obviously sensitive headers.
-func (e *Executor) persistOutboundSnapshot(ctx context.Context, runID int64, req *http.Request) {
+func (e *Executor) persistOutboundSnapshot(ctx context.Context, runID int64, req *http.Request, rendered *render.Result) {
 	if req == nil || e.RunSvc == nil {
 		return
 	}
@@ -306,7 +418,7 @@ func (e *Executor) persistOutboundSnapshot(ctx context.Context, runID int64, req
 	}
 	headersJSON := bizConsts.DEFAULT_JSON_STR
 	if b, err := json.Marshal(ordered); err == nil {
-		headersJSON = string(b)
+		headersJSON = rendered.Redact(string(b))
 	}
 
 	// request body: best-effort. buildRequest always uses bytes.NewReader, so GetBody may be nil.
@@ -315,7 +427,7 @@ func (e *Executor) persistOutboundSnapshot(ctx context.Context, runID int64, req
 		if rc, err := req.GetBody(); err == nil {
 			bb, _ := io.ReadAll(rc)
 			_ = rc.Close()
-			bodyStr = string(bb)
+			bodyStr = rendered.Redact(string(bb))
 		}
 	}
 
@@ -328,14 +440,21 @@ func (e *Executor) persistInboundSnapshot(ctx context.Context, runID int64, code
 	_ = e.RunSvc.UpdateResponseSnapshot(ctx, runID, &c, body, errMsg)
 }
 
-// startRunContext 创建带超时的上下文（默认1小时兜底），并登记取消函数 + 运行中计数。
+// startRunContext 创建带超时的上下文（任务的 execution_timeout_sec，未配置时 1 小时兜底），并登记取消函数 + 运行中计数。
 func (e *Executor) startRunContext(parent context.Context, run *model.TaskRun) (context.Context, func()) {
 	// The parent context passed here (from worker) should already have trace info via ensureTraceContext.
 	baseCtx := e.ensureTraceContext(parent, run.TraceID)
 
-	// Hard limit of 1 hour to prevent stuck goroutines if HTTP client timeout fails
-	to := 3600
-	runCtx, cancel := context.WithTimeout(baseCtx, time.Duration(to)*time.Second)
+	var timeoutCtx context.Context
+	var stop context.CancelFunc
+	if run.ExecutionTimeoutSec > 0 {
+		timeoutCtx, stop = context.WithTimeoutCause(baseCtx, time.Duration(run.ExecutionTimeoutSec)*time.Second,
+			&cancelCause{reason: bizConsts.CancelReasonExecutionTimeout})
+	} else {
+		// Hard limit of 1 hour to prevent stuck goroutines if HTTP client timeout fails
+		timeoutCtx, stop = context.WithTimeout(baseCtx, time.Hour)
+	}
+	runCtx, cancel := context.WithCancelCause(timeoutCtx)
 
 	e.mu.Lock()
 	e.cancelMap[run.ID] = cancel
@@ -343,7 +462,8 @@ func (e *Executor) startRunContext(parent context.Context, run *model.TaskRun) (
 	e.mu.Unlock()
 
 	cleanup := func() {
-		cancel()
+		cancel(nil)
+		stop()
 		e.mu.Lock()
 		delete(e.cancelMap, run.ID)
 		e.activePerTask[run.TaskID]--
@@ -382,9 +502,9 @@ func (e *Executor) ensureTraceContext(parent context.Context, traceID string) co
 	return trace.ContextWithSpanContext(parent, sc)
 }
 
-// buildRequest 根据 TaskRun 快照构建 HTTP 请求
-func (e *Executor) buildRequest(ctx context.Context, run *model.TaskRun, fullURL string) (*http.Request, error) {
-	// A: meta 信息 (run 相关) - 依然构造，保持 contract 兼容
+// runMeta Run 的元信息（run_id、逻辑触发时间、回调地址等）：HTTP 放在请求体的 meta 中，
+// REDIS_STREAM 作为消息的 meta 字段，GRPC 以 x-cronjob-* metadata 发送。
+func runMeta(run *model.TaskRun) map[string]any {
 	ce := config.GetBizConfig().CallbackEndpoints
 	progressPath := ce.ProgressPath
 	callbackPath := ce.CallbackPath
@@ -400,11 +520,31 @@ func (e *Executor) buildRequest(ctx context.Context, run *model.TaskRun, fullURL
 		"run_id":    run.ID,
 		"task_id":   run.TaskID,
 		"exec_type": run.ExecType,
+		// 逻辑触发时间：补跑/重试时为原触发点而非实际执行时间
+		"scheduled_time": run.ScheduledTime.UTC().Format(time.RFC3339),
+		"trigger_type":   run.TriggerType,
 		"callback_endpoints": map[string]any{
 			"progress": progressPath,
 			"callback": callbackPath,
 		},
 	}
+	if run.LogicalDate != nil {
+		meta["logical_date"] = run.LogicalDate.Format(time.DateOnly)
+	}
+	if run.BackfillID != nil {
+		meta["backfill_id"] = *run.BackfillID
+	}
+	if run.CallbackToken != "" {
+		// 回调时原样带回（请求头 X-Cronjob-Callback-Token 或请求体 callback_token）
+		meta["callback_token"] = run.CallbackToken
+	}
+	return meta
+}
+
+// buildRequest 根据 TaskRun 快照构建 HTTP 请求
+func (e *Executor) buildRequest(ctx context.Context, run *model.TaskRun, fullURL string) (*http.Request, error) {
+	// A: meta 信息 (run 相关) - 依然构造，保持 contract 兼容
+	meta := runMeta(run)
 	// B: 业务 body, 来自 run.RequestBody (snapshot)
 	var bodyVal any = nil
 	if run.RequestBody != "" {
@@ -481,6 +621,10 @@ func (e *Executor) classifyNetError(ctx context.Context, err error) string {
 	if errors.Is(ctx.Err(), context.Canceled) {
 		return "canceled"
 	}
+	// 后端自身的超时（如 COMMAND 的 timeout、GRPC 的 request_timeout）
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "request_timeout"
+	}
 	// 尝试按底层网络错误分类
 	msg := err.Error()
 	var opErr *net.OpError
@@ -500,48 +644,3 @@ func (e *Executor) classifyNetError(ctx context.Context, err error) string {
 	}
 	return msg
 }
-
-func (e *Executor) CancelRun(id int64) {
-	e.mu.Lock()
-	cancel, ok := e.cancelMap[id]
-	e.mu.Unlock()
-	if ok {
-		cancel()
-	}
-}
-
-// CancelRemote sends a best-effort cancel notification to the target service.
-// This runs in a goroutine; errors are logged but not propagated.
-func (e *Executor) CancelRemote(run *model.TaskRun) {
-	if e.HTTPCli == nil || run.TargetService == "" {
-		return
-	}
-	client, errCli := e.HTTPCli.Client(run.TargetService)
-	if errCli != nil {
-		return
-	}
-
-	cancelURL := strings.TrimRight(client.BaseURL, "/") + "/tasks/cancel"
-	body := map[string]any{"run_id": run.ID}
-	buf, err := json.Marshal(body)
-	if err != nil {
-		return
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	req, err := http.NewRequestWithContext(ctx, "POST", cancelURL, bytes.NewReader(buf))
-	if err != nil {
-		return
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Client.Do(req)
-	if err != nil {
-		logging.Warn(ctx, fmt.Sprintf("cancel-remote request failed for run %d: %v", run.ID, err))
-		return
-	}
-	_ = resp.Body.Close()
-	logging.Info(ctx, fmt.Sprintf("cancel-remote notified for run %d, status: %d", run.ID, resp.StatusCode))
-}
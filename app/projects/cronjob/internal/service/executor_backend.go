@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/render"
+)
+
+// Backend 执行器后端：把一次（已渲染的）Run 发送到下游并返回结果。
+// 执行器负责渲染模板、状态流转、回调等待与重试；后端只关心如何发出请求、如何解读结果。
+type Backend interface {
+	Kind() bizConsts.ExecutorKind
+	// Check 校验任务的后端配置（客户端 / 方法 / 命令是否可用），创建、更新与导入任务时调用。
+	Check(task *model.Task, spec *model.ExecutorSpec) error
+	// Invoke 执行一次调用。返回 error 表示没有拿到下游的结果（连接失败、超时、取消等），
+	// 由执行器按传输层错误归类；此时 BackendResult 可为空，也可携带已捕获的输出。
+	Invoke(ctx context.Context, call *BackendCall) (*BackendResult, error)
+}
+
+// BackendCall 一次调用的输入；Run 上的 TargetPath / RequestHeaders / RequestBody 已渲染。
+type BackendCall struct {
+	Run      *model.TaskRun
+	Spec     *model.ExecutorSpec
+	Rendered *render.Result // 用于快照打码；COMMAND 的参数与环境变量也在其中
+}
+
+// BackendResult 下游的结果。
+type BackendResult struct {
+	Code    int                // HTTP 状态码 / gRPC 状态码 / 进程退出码
+	Body    string             // 响应体：gRPC 响应转 JSON；COMMAND 为 stdout；REDIS_STREAM 为消息 ID
+	Stderr  string             // COMMAND 的 stderr
+	Failure string             // 非空表示下游明确失败（非 2xx、非 OK、非零退出）
+	Outcome model.RetryOutcome // Failure 非空时的失败分类，用于匹配 retry_on
+}
+
+// invalidCallError 调用无法发出（后端未启用、客户端或方法不存在、请求体不合法等）：直接失败，不重试。
+type invalidCallError struct{ msg string }
+
+func (e *invalidCallError) Error() string { return e.msg }
+
+func invalidCall(format string, args ...any) error {
+	return &invalidCallError{msg: fmt.Sprintf(format, args...)}
+}
+
+func isInvalidCall(err error) bool {
+	var ic *invalidCallError
+	return errors.As(err, &ic)
+}
+
+// backend 按 Run 快照中的执行器类型选择后端（空值视为 HTTP）。
+func (e *Executor) backend(kind bizConsts.ExecutorKind) (Backend, error) {
+	if kind == "" {
+		kind = bizConsts.DEFAULT_EXECUTOR
+	}
+	if b, ok := e.backends[kind]; ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("unknown executor %q", kind)
+}
+
+// CheckBackend 校验任务的执行器配置在当前部署下可用。
+func (e *Executor) CheckBackend(task *model.Task) error {
+	b, err := e.backend(task.Executor)
+	if err != nil {
+		return err
+	}
+	spec, err := model.ParseExecutorSpec(task.ExecutorConfig)
+	if err != nil {
+		return err
+	}
+	return b.Check(task, spec)
+}
+
+// persistRequest 记录非 HTTP 后端实际发出的请求（密钥打码）。
+func (e *Executor) persistRequest(ctx context.Context, call *BackendCall, headers any, body string) {
+	headersJSON := bizConsts.DEFAULT_JSON_STR
+	if b, err := json.Marshal(headers); err == nil {
+		headersJSON = call.Rendered.Redact(string(b))
+	}
+	_ = e.RunSvc.UpdateRequestSnapshot(ctx, call.Run.ID, headersJSON, call.Rendered.Redact(body))
+}
+
+// parseHeaders 解析 headers_json：{"k":"v"} 或 {"k":["v1","v2"]}，键按字母序返回。
+func parseHeaders(raw string) ([]string, map[string][]string) {
+	out := map[string][]string{}
+	if strings.TrimSpace(raw) != "" {
+		var simple map[string]string
+		if err := json.Unmarshal([]byte(raw), &simple); err == nil {
+			for k, v := range simple {
+				out[k] = []string{v}
+			}
+		} else {
+			_ = json.Unmarshal([]byte(raw), &out)
+		}
+	}
+	keys := make([]string, 0, len(out))
+	for k := range out {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, out
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/render"
+)
+
+func (r *stubRunDao) UpdateRequestSnapshot(_ context.Context, _ int64, _, _ string) error { return nil }
+
+func TestCommandBackend(t *testing.T) {
+	const sh = "/bin/sh"
+	if _, err := os.Stat(sh); err != nil {
+		t.Skip("/bin/sh not available")
+	}
+	exec := NewExecutor(config.ExecutorConfig{Command: config.CommandBackendConfig{
+		AllowedCommands: []string{sh}, DefaultTimeout: 5 * time.Second, MaxOutputBytes: 16}})
+	exec.RunSvc = &RunService{RunDao: &stubRunDao{}}
+	b := exec.backends[bizConsts.ExecutorCommand]
+
+	if err := b.Check(&model.Task{TargetPath: "/bin/rm"}, &model.ExecutorSpec{}); err == nil {
+		t.Fatal("command outside allowlist should be rejected")
+	}
+	invoke := func(script string, spec *model.ExecutorSpec) (*BackendResult, error) {
+		run := &model.TaskRun{ID: 9, TaskID: 3, Attempt: 2, TargetPath: sh, RequestBody: "in"}
+		return b.Invoke(context.Background(), &BackendCall{Run: run, Spec: spec,
+			Rendered: &render.Result{Args: []string{"-c", script}, Env: map[string]string{"GREETING": "hi"}}})
+	}
+
+	res, err := invoke(`read x; echo "$GREETING $x $CRONJOB_RUN_ID/$CRONJOB_ATTEMPT"`, &model.ExecutorSpec{})
+	if err != nil || res.Failure != "" || strings.TrimSpace(res.Body) != "hi in 9/2" {
+		t.Fatalf("unexpected result %+v err=%v", res, err)
+	}
+	// 输出只保留末尾 MaxOutputBytes 字节
+	res, _ = invoke(`echo 0123456789abcdefXYZ`, &model.ExecutorSpec{})
+	if !strings.HasPrefix(res.Body, "...(truncated)") || !strings.HasSuffix(res.Body, "abcdefXYZ\n") {
+		t.Fatalf("output not truncated: %q", res.Body)
+	}
+	res, err = invoke(`echo boom >&2; exit 75`, &model.ExecutorSpec{})
+	if err != nil || res.Code != 75 || res.Outcome.Kind != model.OutcomeExit || !strings.Contains(res.Failure, "boom") {
+		t.Fatalf("unexpected exit result %+v err=%v", res, err)
+	}
+	res, err = invoke(`exec sleep 5`, &model.ExecutorSpec{Timeout: model.Duration(100 * time.Millisecond)})
+	if !errors.Is(err, context.DeadlineExceeded) || res.Code != -1 {
+		t.Fatalf("expected timeout, got %+v err=%v", res, err)
+	}
+}
+
+func TestGRPCMethodResolution(t *testing.T) {
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+	opt := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	fd := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("demo.proto"),
+		Package: proto.String("demo.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name:  proto.String("SyncRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{{Name: proto.String("date"), Number: proto.Int32(1), Type: str, Label: opt, JsonName: proto.String("date")}},
+		}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("DemoService"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{Name: proto.String("Sync"), InputType: proto.String(".demo.v1.SyncRequest"), OutputType: proto.String(".demo.v1.SyncRequest")},
+				{Name: proto.String("Watch"), InputType: proto.String(".demo.v1.SyncRequest"), OutputType: proto.String(".demo.v1.SyncRequest"), ServerStreaming: proto.Bool(true)},
+			},
+		}},
+	}
+	raw, _ := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{fd}})
+	path := filepath.Join(t.TempDir(), "demo.pb")
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	exec := NewExecutor(config.ExecutorConfig{})
+	if err := exec.grpc.load([]string{path, path}); err != nil {
+		t.Fatalf("load descriptor set: %v", err)
+	}
+	md, err := exec.grpc.method("/demo.v1.DemoService/Sync")
+	if err != nil || md.Input().FullName() != "demo.v1.SyncRequest" {
+		t.Fatalf("resolve method: %v", err)
+	}
+	for _, bad := range []string{"demo.v1.DemoService/Watch", "/demo.v1.DemoService/Nope", "/demo.v1.Other/Sync", "Sync"} {
+		if _, err := exec.grpc.method(bad); err == nil {
+			t.Errorf("%s: expected error", bad)
+		}
+	}
+	if grpcHTTPStatus(codes.ResourceExhausted) != 429 || grpcHTTPStatus(codes.Internal) != 500 {
+		t.Fatal("unexpected grpc status mapping")
+	}
+}
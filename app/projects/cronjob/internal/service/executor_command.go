@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// commandBackend 在本机执行 target_path 指定的命令（不经过 shell），参数与环境变量来自 executor_config，
+// body_template 非空时作为 stdin。stdout 记入 response_body，stderr 记入 stderr，退出码记入 response_code。
+// 只允许执行 executor.command.allowed_commands 中的命令。
+type commandBackend struct{ e *Executor }
+
+func (b *commandBackend) Kind() bizConsts.ExecutorKind { return bizConsts.ExecutorCommand }
+
+func (b *commandBackend) allowed(cmd string) bool {
+	return cmd != "" && slices.Contains(b.e.cfg.Command.AllowedCommands, cmd)
+}
+
+func (b *commandBackend) Check(task *model.Task, _ *model.ExecutorSpec) error {
+	if !b.allowed(task.TargetPath) {
+		return fmt.Errorf("command %q is not in executor.command.allowed_commands", task.TargetPath)
+	}
+	return nil
+}
+
+func (b *commandBackend) Invoke(ctx context.Context, call *BackendCall) (*BackendResult, error) {
+	run, cfg := call.Run, b.e.cfg.Command
+	if !b.allowed(run.TargetPath) {
+		return nil, invalidCall("command %q is not in executor.command.allowed_commands", run.TargetPath)
+	}
+	timeout := time.Duration(call.Spec.Timeout)
+	if timeout <= 0 {
+		timeout = cfg.DefaultTimeout
+	}
+	dir := call.Spec.Dir
+	if dir == "" {
+		dir = cfg.Dir
+	}
+	b.e.persistRequest(ctx, call, call.Rendered.Env, commandLine(run.TargetPath, call.Rendered.Args, run.RequestBody))
+
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, run.TargetPath, call.Rendered.Args...)
+	cmd.Dir = dir
+	cmd.Env = b.environ(run, call.Rendered.Env)
+	cmd.WaitDelay = 5 * time.Second // 被杀后等待输出管道关闭的上限
+	if run.RequestBody != "" {
+		cmd.Stdin = strings.NewReader(run.RequestBody)
+	}
+	stdout, stderr := &tailBuffer{max: cfg.MaxOutputBytes}, &tailBuffer{max: cfg.MaxOutputBytes}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	err := cmd.Run()
+	res := &BackendResult{Body: stdout.String(), Stderr: stderr.String()}
+	if err == nil {
+		return res, nil
+	}
+	if ctx.Err() != nil { // Run 被取消或执行器整体超时
+		return res, ctx.Err()
+	}
+	if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+		res.Code = -1
+		return res, fmt.Errorf("%w: command timed out after %s", context.DeadlineExceeded, timeout)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) { // 未能启动：文件不存在、无执行权限等
+		return res, invalidCall("start command %s: %v", run.TargetPath, err)
+	}
+	res.Code = exitErr.ExitCode()
+	res.Failure = fmt.Sprintf("%s; stderr=%s", exitErr, lastLine(res.Stderr))
+	res.Outcome = model.RetryOutcome{Kind: model.OutcomeExit, ExitCode: res.Code}
+	return res, nil
+}
+
+// environ 继承进程环境（去掉密钥前缀的变量，密钥只能经模板 secret 显式传入），
+// 追加 Run 元信息 CRONJOB_* 与任务配置的变量。
+func (b *commandBackend) environ(run *model.TaskRun, extra map[string]string) []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, b.e.cfg.SecretEnvPrefix) {
+			env = append(env, kv)
+		}
+	}
+	env = append(env,
+		fmt.Sprintf("CRONJOB_RUN_ID=%d", run.ID),
+		fmt.Sprintf("CRONJOB_TASK_ID=%d", run.TaskID),
+		fmt.Sprintf("CRONJOB_ATTEMPT=%d", run.Attempt),
+		"CRONJOB_SCHEDULED_TIME="+run.ScheduledTime.UTC().Format(time.RFC3339),
+		"CRONJOB_TRIGGER_TYPE="+string(run.TriggerType),
+	)
+	if run.LogicalDate != nil {
+		env = append(env, "CRONJOB_LOGICAL_DATE="+run.LogicalDate.Format(time.DateOnly))
+	}
+	names, values := sortedEnv(extra)
+	for i, k := range names {
+		env = append(env, k+"="+values[i])
+	}
+	return env
+}
+
+// commandLine 请求快照中展示的命令行（参数按 Go 字符串字面量引用）。
+func commandLine(path string, args []string, stdin string) string {
+	parts := []string{path}
+	for _, a := range args {
+		parts = append(parts, fmt.Sprintf("%q", a))
+	}
+	line := strings.Join(parts, " ")
+	if stdin != "" {
+		line += "\n" + stdin
+	}
+	return line
+}
+
+// lastLine stderr 的最后一个非空行，用于错误信息。
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	return lines[len(lines)-1]
+}
+
+// tailBuffer 只保留最后 max 字节的输出。
+type tailBuffer struct {
+	max       int
+	buf       []byte
+	truncated bool
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if t.max > 0 && len(t.buf) > t.max {
+		t.buf = append(t.buf[:0], t.buf[len(t.buf)-t.max:]...)
+		t.truncated = true
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	if t.truncated {
+		return "...(truncated)\n" + string(t.buf)
+	}
+	return string(t.buf)
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// grpcBackend 经 grpc_clients 中 target_service 对应的连接发起 unary 调用。
+// target_path 为完整方法名（/pylon.stock_zh.v1.StockZhService/SyncDaily），请求与响应按
+// executor.grpc.descriptor_sets 中的描述动态编解码：body_template 为请求消息的 JSON（protojson），
+// headers_json 与 Run 元信息（x-cronjob-run-id 等）作为 metadata 发送。
+type grpcBackend struct {
+	e     *Executor
+	mu    sync.RWMutex
+	files *protoregistry.Files
+}
+
+func (b *grpcBackend) Kind() bizConsts.ExecutorKind { return bizConsts.ExecutorGRPC }
+
+// load 读取并合并 descriptor set（须带 --include_imports）。
+func (b *grpcBackend) load(paths []string) error {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := map[string]bool{}
+	for _, p := range paths {
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		var one descriptorpb.FileDescriptorSet
+		if err := proto.Unmarshal(raw, &one); err != nil {
+			return fmt.Errorf("parse descriptor set %s: %w", p, err)
+		}
+		for _, fd := range one.File {
+			if !seen[fd.GetName()] {
+				seen[fd.GetName()] = true
+				set.File = append(set.File, fd)
+			}
+		}
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return fmt.Errorf("build descriptors: %w", err)
+	}
+	b.mu.Lock()
+	b.files = files
+	b.mu.Unlock()
+	return nil
+}
+
+// method 解析 /pkg.Service/Method（前导 / 可省略），仅支持 unary 方法。
+func (b *grpcBackend) method(fullName string) (protoreflect.MethodDescriptor, error) {
+	name := strings.TrimPrefix(strings.TrimSpace(fullName), "/")
+	i := strings.LastIndex(name, "/")
+	if i <= 0 || i == len(name)-1 {
+		return nil, fmt.Errorf("grpc method %q must look like /package.Service/Method", fullName)
+	}
+	b.mu.RLock()
+	files := b.files
+	b.mu.RUnlock()
+	if files == nil {
+		return nil, fmt.Errorf("no grpc descriptor sets loaded (executor.grpc.descriptor_sets)")
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(name[:i]))
+	if err != nil {
+		return nil, fmt.Errorf("grpc service %s not found in descriptor sets", name[:i])
+	}
+	svc, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a grpc service", name[:i])
+	}
+	md := svc.Methods().ByName(protoreflect.Name(name[i+1:]))
+	if md == nil {
+		return nil, fmt.Errorf("grpc method %s not found in service %s", name[i+1:], name[:i])
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, fmt.Errorf("grpc method %s is streaming; only unary calls are supported", md.FullName())
+	}
+	return md, nil
+}
+
+func (b *grpcBackend) Check(task *model.Task, _ *model.ExecutorSpec) error {
+	if task.TargetService == "" {
+		return fmt.Errorf("target_service is required for %s executor", b.Kind())
+	}
+	if b.e.GRPCCli == nil {
+		return fmt.Errorf("grpc_clients component is not enabled")
+	}
+	md, err := b.method(task.TargetPath)
+	if err != nil {
+		return err
+	}
+	// 请求体不含模板时直接按消息类型校验
+	if body := strings.TrimSpace(task.BodyTemplate); body != "" && !strings.Contains(body, "{{") {
+		if err := protojson.Unmarshal([]byte(body), dynamicpb.NewMessage(md.Input())); err != nil {
+			return fmt.Errorf("body_template is not a valid %s: %w", md.Input().FullName(), err)
+		}
+	}
+	return nil
+}
+
+func (b *grpcBackend) Invoke(ctx context.Context, call *BackendCall) (*BackendResult, error) {
+	run := call.Run
+	if b.e.GRPCCli == nil {
+		return nil, invalidCall("grpc_clients component is not enabled")
+	}
+	md, err := b.method(run.TargetPath)
+	if err != nil {
+		return nil, invalidCall("%v", err)
+	}
+	req := dynamicpb.NewMessage(md.Input())
+	if strings.TrimSpace(run.RequestBody) != "" {
+		if err := protojson.Unmarshal([]byte(run.RequestBody), req); err != nil {
+			return nil, invalidCall("request body is not a valid %s: %v", md.Input().FullName(), err)
+		}
+	}
+	conn, err := b.e.GRPCCli.GetClient(run.TargetService)
+	if err != nil {
+		if strings.Contains(err.Error(), "config not found") { // grpc_clients 中没有该名称
+			return nil, invalidCall("grpc client for service %s not found: %v", run.TargetService, err)
+		}
+		return nil, err // 连接不可用，按网络错误处理
+	}
+
+	outgoing := grpcMetadata(run)
+	keys, headers := parseHeaders(run.RequestHeaders)
+	for _, k := range keys {
+		outgoing.Append(strings.ToLower(k), headers[k]...)
+	}
+	b.e.persistRequest(ctx, call, outgoing, run.RequestBody)
+
+	callCtx, cancel := context.WithTimeout(metadata.NewOutgoingContext(ctx, outgoing), b.e.cfg.RequestTimeout)
+	defer cancel()
+	resp := dynamicpb.NewMessage(md.Output())
+	fullMethod := fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name())
+	if err := conn.Invoke(callCtx, fullMethod, req, resp); err != nil {
+		st := status.Convert(err)
+		switch st.Code() {
+		case codes.DeadlineExceeded:
+			return nil, fmt.Errorf("%w: %s", context.DeadlineExceeded, st.Message())
+		case codes.Canceled:
+			return nil, fmt.Errorf("%w: %s", context.Canceled, st.Message())
+		case codes.Unavailable:
+			return nil, err
+		}
+		return &BackendResult{
+			Code:    int(st.Code()),
+			Failure: fmt.Sprintf("grpc %s: %s", st.Code(), st.Message()),
+			Outcome: model.RetryOutcome{Kind: model.OutcomeHTTP, HTTPStatus: grpcHTTPStatus(st.Code())},
+		}, nil
+	}
+	body, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(resp)
+	if err != nil {
+		return nil, invalidCall("marshal %s: %v", md.Output().FullName(), err)
+	}
+	return &BackendResult{Code: int(codes.OK), Body: string(body)}, nil
+}
+
+// grpcMetadata Run 元信息以 x-cronjob-* 发送（对应 HTTP 请求体中的 meta）。
+func grpcMetadata(run *model.TaskRun) metadata.MD {
+	md := metadata.Pairs(
+		"x-cronjob-run-id", fmt.Sprintf("%d", run.ID),
+		"x-cronjob-task-id", fmt.Sprintf("%d", run.TaskID),
+		"x-cronjob-exec-type", string(run.ExecType),
+		"x-cronjob-scheduled-time", run.ScheduledTime.UTC().Format(time.RFC3339),
+		"x-cronjob-trigger-type", string(run.TriggerType),
+		"x-cronjob-attempt", fmt.Sprintf("%d", run.Attempt),
+	)
+	if run.LogicalDate != nil {
+		md.Set("x-cronjob-logical-date", run.LogicalDate.Format(time.DateOnly))
+	}
+	if run.BackfillID != nil {
+		md.Set("x-cronjob-backfill-id", fmt.Sprintf("%d", *run.BackfillID))
+	}
+	if run.CallbackToken != "" {
+		md.Set("x-cronjob-callback-token", run.CallbackToken)
+	}
+	return md
+}
+
+// grpcHTTPStatus 按 gRPC 官方映射把状态码转为 HTTP 状态码，使 retry_on.http_status 同样适用。
+func grpcHTTPStatus(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
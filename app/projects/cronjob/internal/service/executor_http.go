@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/http_client"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// httpBackend 经 http_clients 中 target_service 对应的客户端发送 {"meta":{...},"body":<body_template>}。
+type httpBackend struct{ e *Executor }
+
+func (b *httpBackend) Kind() bizConsts.ExecutorKind { return bizConsts.ExecutorHTTP }
+
+func (b *httpBackend) Check(task *model.Task, _ *model.ExecutorSpec) error {
+	if task.TargetService == "" {
+		return fmt.Errorf("target_service is required for %s executor", b.Kind())
+	}
+	return nil
+}
+
+func (b *httpBackend) Invoke(ctx context.Context, call *BackendCall) (*BackendResult, error) {
+	run := call.Run
+	if run.TargetService == "" {
+		return nil, invalidCall("target_service is empty")
+	}
+	var client *http_client.InstrumentedClient
+	if b.e.HTTPCli != nil {
+		var err error
+		if client, err = b.e.HTTPCli.Client(run.TargetService); err != nil {
+			return nil, invalidCall("http client for service %s not found: %v", run.TargetService, err)
+		}
+	} else {
+		// Fallback (mostly for tests or incomplete initialization)
+		client = &http_client.InstrumentedClient{Client: &http.Client{Timeout: 15 * time.Second}}
+	}
+
+	// Resolve full URL
+	fullURL := run.TargetPath
+	if !strings.HasPrefix(fullURL, "http://") && !strings.HasPrefix(fullURL, "https://") {
+		baseURL := client.BaseURL
+		// simple joining, assuming valid segments. Ideally use url.JoinPath but we do string concat for now
+		if !strings.HasSuffix(baseURL, "/") && !strings.HasPrefix(fullURL, "/") {
+			fullURL = baseURL + "/" + fullURL
+		} else if strings.HasSuffix(baseURL, "/") && strings.HasPrefix(fullURL, "/") {
+			fullURL = baseURL + strings.TrimPrefix(fullURL, "/")
+		} else {
+			fullURL = baseURL + fullURL
+		}
+	}
+
+	req, err := b.e.buildRequest(ctx, run, fullURL)
+	if err != nil {
+		return nil, invalidCall("build request failed: %v", err)
+	}
+	// 记录本次实际发送的 request headers/body（模板中的密钥打码）
+	b.e.persistOutboundSnapshot(ctx, run.ID, req, call.Rendered)
+
+	resp, body, _, err := b.e.doHTTP(ctx, client.Client, req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+
+	res := &BackendResult{Code: resp.StatusCode, Body: string(body)}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		res.Failure = resp.Status
+		if len(body) > 0 {
+			res.Failure = fmt.Sprintf("%s; body=%s", resp.Status, string(body))
+		}
+		res.Outcome = model.RetryOutcome{Kind: model.OutcomeHTTP, HTTPStatus: resp.StatusCode}
+	}
+	return res, nil
+}
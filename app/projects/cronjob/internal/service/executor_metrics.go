@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/prometheus"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// runDurationBuckets Run 耗时分桶（秒），覆盖秒级同步调用到小时级异步任务。
+var runDurationBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800, 3600}
+
+// executorMetrics 按任务统计的执行指标；未启用 prometheus 组件时为 nil，所有方法均可安全调用。
+// ASYNC Run 的结果在回调生效或回调超时时记录，耗时为开始执行到结束的总时长。
+type executorMetrics struct {
+	runs     *prom.CounterVec
+	duration *prom.HistogramVec
+	inFlight *prom.GaugeVec
+	callback *prom.HistogramVec
+}
+
+func newExecutorMetrics(pc *prometheus.Component) *executorMetrics {
+	return &executorMetrics{
+		runs: pc.NewCounter("cronjob_runs_total",
+			"Total finished task runs, by task, executor, target service and final status.",
+			[]string{"task", "executor", "target_service", "status"}),
+		duration: pc.NewHistogram("cronjob_run_duration_seconds",
+			"Task run duration in seconds from start to final status (ASYNC includes waiting for the callback).",
+			[]string{"task", "executor", "target_service", "status"}, runDurationBuckets),
+		inFlight: pc.NewGauge("cronjob_runs_in_flight",
+			"Task runs currently being executed by a worker, by task and executor.",
+			[]string{"task", "executor"}),
+		callback: pc.NewHistogram("cronjob_callback_latency_seconds",
+			"Latency in seconds from the start of an ASYNC run to its accepted callback.",
+			[]string{"task", "target_service"}, runDurationBuckets),
+	}
+}
+
+// begin 标记 Run 开始执行，返回记录执行结果的函数；CALLBACK_PENDING 不记录结果，留待回调。
+func (m *executorMetrics) begin(ctx context.Context, task string, run *model.TaskRun) func(status bizConsts.RunStatus) {
+	if m == nil {
+		return func(bizConsts.RunStatus) {}
+	}
+	gauge := m.inFlight.WithLabelValues(task, string(run.Executor))
+	gauge.Inc()
+	start := time.Now()
+	return func(status bizConsts.RunStatus) {
+		gauge.Dec()
+		if status == bizConsts.CallbackPending {
+			return
+		}
+		m.finish(ctx, task, run, status, time.Since(start))
+	}
+}
+
+func (m *executorMetrics) finish(ctx context.Context, task string, run *model.TaskRun, status bizConsts.RunStatus, d time.Duration) {
+	if m == nil {
+		return
+	}
+	labels := []string{task, string(run.Executor), run.TargetService, string(status)}
+	prometheus.Inc(ctx, m.runs.WithLabelValues(labels...))
+	prometheus.Observe(ctx, m.duration.WithLabelValues(labels...), d.Seconds())
+}
+
+func (m *executorMetrics) observeCallback(ctx context.Context, task string, run *model.TaskRun, d time.Duration) {
+	if m == nil {
+		return
+	}
+	prometheus.Observe(ctx, m.callback.WithLabelValues(task, run.TargetService), d.Seconds())
+}
+
+// metricTaskName 指标中的任务标签：任务名，取不到时为任务 ID。
+func (e *Executor) metricTaskName(ctx context.Context, taskID int64) string {
+	if e.metrics == nil {
+		return ""
+	}
+	if t, err := e.TaskSvc.Get(ctx, taskID); err == nil && t != nil {
+		return t.Name
+	}
+	return strconv.FormatInt(taskID, 10)
+}
+
+// ObserveAsyncResult 记录 ASYNC Run 的最终结果：回调生效（callback=true，同时记录回调延迟）或回调超时。
+// run 为结束后的 Run，耗时取 start_time 到 at。
+func (e *Executor) ObserveAsyncResult(ctx context.Context, run *model.TaskRun, at time.Time, callback bool) {
+	if e.metrics == nil || run == nil || run.StartTime == nil {
+		return
+	}
+	task := e.metricTaskName(ctx, run.TaskID)
+	d := at.Sub(*run.StartTime)
+	e.metrics.finish(ctx, task, run, run.Status, d)
+	if callback {
+		e.metrics.observeCallback(ctx, task, run, d)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// redisStreamBackend 以 XADD 把 Run 发布到 target_path 指定的 Stream：字段 meta 为 Run 元信息 JSON，
+// body 为渲染后的 body_template，headers_json 中的键值作为附加字段。发布成功即视为完成（SYNC），
+// ASYNC 任务则等待消费者回调。
+type redisStreamBackend struct{ e *Executor }
+
+func (b *redisStreamBackend) Kind() bizConsts.ExecutorKind { return bizConsts.ExecutorRedisStream }
+
+func (b *redisStreamBackend) Check(_ *model.Task, _ *model.ExecutorSpec) error {
+	if b.e.Redis == nil {
+		return fmt.Errorf("redis component is not enabled")
+	}
+	return nil
+}
+
+func (b *redisStreamBackend) Invoke(ctx context.Context, call *BackendCall) (*BackendResult, error) {
+	run := call.Run
+	if b.e.Redis == nil || b.e.Redis.Client() == nil {
+		return nil, invalidCall("redis component is not enabled")
+	}
+	meta, err := json.Marshal(runMeta(run))
+	if err != nil {
+		return nil, invalidCall("marshal meta: %v", err)
+	}
+	fields := map[string]string{"meta": string(meta), "body": run.RequestBody}
+	values := []any{"meta", string(meta), "body", run.RequestBody}
+	keys, headers := parseHeaders(run.RequestHeaders)
+	for _, k := range keys {
+		if _, reserved := fields[k]; reserved || len(headers[k]) == 0 {
+			continue
+		}
+		fields[k] = headers[k][0]
+		values = append(values, k, headers[k][0])
+	}
+	b.e.persistRequest(ctx, call, fields, run.RequestBody)
+
+	maxLen := call.Spec.MaxLen
+	if maxLen <= 0 {
+		maxLen = b.e.cfg.RedisStream.MaxLen
+	}
+	id, err := b.e.Redis.Client().XAdd(ctx, &goredis.XAddArgs{
+		Stream: run.TargetPath,
+		MaxLen: maxLen,
+		Approx: maxLen > 0,
+		Values: values,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	out, _ := json.Marshal(map[string]string{"stream": run.TargetPath, "id": id})
+	return &BackendResult{Body: string(out)}, nil
+}
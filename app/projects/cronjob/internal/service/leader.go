@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// LeaderElector 基于 scheduler_locks 租约的选主。
+// 每 renew_interval 尝试获取/续约一次；本地认为自己是 leader 的期限从发起续约前的时刻算起，
+// 因此总不晚于数据库中的租约到期时间，其他副本只能在租约过期后接管。
+// 续约失败（DB 不可用）时保持 leader 直到本地期限耗尽；发现租约已被他人持有时立即让位。
+// 未开启 HA 时始终视为 leader，Term 为 0。
+type LeaderElector struct {
+	*core.BaseComponent
+	cfg     config.HAConfig
+	LockDao dao.LockDao `infra:"dep:lock_dao"`
+
+	mu         sync.RWMutex
+	leader     bool
+	term       int64
+	leaseUntil time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// LeaderStatus 供 meta API 展示的选主状态。
+type LeaderStatus struct {
+	Enabled    bool                 `json:"enabled"`
+	IsLeader   bool                 `json:"is_leader"`
+	OwnerID    string               `json:"owner_id"`
+	LockName   string               `json:"lock_name"`
+	Term       int64                `json:"term"`
+	LeaseUntil *time.Time           `json:"lease_until,omitempty"`
+	Holder     *model.SchedulerLock `json:"holder,omitempty"` // 数据库中的当前持有者
+}
+
+func NewLeaderElector(cfg config.HAConfig) *LeaderElector {
+	if cfg.LockName == "" {
+		cfg.LockName = bizConsts.COMP_SVC_SCHEDULER
+	}
+	if cfg.LeaseDuration <= 0 {
+		cfg.LeaseDuration = 15 * time.Second
+	}
+	if cfg.RenewInterval <= 0 || cfg.RenewInterval > cfg.LeaseDuration/2 {
+		cfg.RenewInterval = cfg.LeaseDuration / 3
+	}
+	if cfg.OwnerID == "" {
+		cfg.OwnerID = defaultOwnerID()
+	}
+	return &LeaderElector{cfg: cfg, BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_LEADER)}
+}
+
+// defaultOwnerID hostname-pid-随机后缀：同一主机上重启的进程不会误认为是上一任 leader。
+func defaultOwnerID() string {
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "cronjob"
+	}
+	var b [3]byte
+	_, _ = rand.Read(b[:])
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(b[:]))
+}
+
+func (l *LeaderElector) Start(ctx context.Context) error {
+	if l.IsActive() {
+		return nil
+	}
+	if err := l.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	if !l.cfg.Enabled {
+		logging.Info(ctx, "scheduler HA disabled; this instance always acts as leader")
+		return nil
+	}
+	loopCtx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.campaign(loopCtx) // 启动时立即尝试一次，尽快确定角色
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		ticker := time.NewTicker(l.cfg.RenewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+				l.campaign(loopCtx)
+			}
+		}
+	}()
+	return nil
+}
+
+func (l *LeaderElector) Stop(ctx context.Context) error {
+	if !l.IsActive() {
+		return nil
+	}
+	if l.cancel != nil {
+		l.cancel()
+	}
+	l.wg.Wait()
+	if l.cfg.Enabled && l.IsLeader() {
+		// 主动让出，避免其他副本等待整个租约过期
+		relCtx, cancel := context.WithTimeout(context.Background(), l.cfg.RenewInterval)
+		if err := l.LockDao.Release(relCtx, l.cfg.LockName, l.cfg.OwnerID); err != nil {
+			logging.Error(ctx, fmt.Sprintf("release scheduler lease failed: %v", err))
+		}
+		cancel()
+		l.stepDown(ctx, "shutdown")
+	}
+	return l.BaseComponent.Stop(ctx)
+}
+
+// campaign 获取或续约一次租约。
+func (l *LeaderElector) campaign(ctx context.Context) {
+	start := time.Now()
+	reqCtx, cancel := context.WithTimeout(ctx, l.cfg.RenewInterval)
+	term, ok, err := l.LockDao.TryAcquire(reqCtx, l.cfg.LockName, l.cfg.OwnerID, l.cfg.LeaseDuration)
+	cancel()
+	switch {
+	case err != nil:
+		logging.Error(ctx, fmt.Sprintf("scheduler lease renew failed owner=%s: %v", l.cfg.OwnerID, err))
+		if l.IsLeader() {
+			return // 本地期限内继续担任 leader
+		}
+		l.stepDown(ctx, "lease expired while renew failing")
+	case !ok:
+		l.stepDown(ctx, "lease held by another instance")
+	default:
+		l.mu.Lock()
+		was, prevTerm := l.leader, l.term
+		l.leader, l.term, l.leaseUntil = true, term, start.Add(l.cfg.LeaseDuration)
+		l.mu.Unlock()
+		if !was || prevTerm != term {
+			logging.Info(ctx, fmt.Sprintf("scheduler leadership acquired owner=%s term=%d", l.cfg.OwnerID, term))
+		}
+	}
+}
+
+func (l *LeaderElector) stepDown(ctx context.Context, reason string) {
+	l.mu.Lock()
+	was := l.leader
+	l.leader = false
+	l.mu.Unlock()
+	if was {
+		logging.Warn(ctx, fmt.Sprintf("scheduler leadership lost owner=%s: %s", l.cfg.OwnerID, reason))
+	}
+}
+
+// Enabled 是否开启多副本选主。
+func (l *LeaderElector) Enabled() bool { return l.cfg.Enabled }
+
+// IsLeader 本副本当前是否应执行调度。
+func (l *LeaderElector) IsLeader() bool {
+	if !l.cfg.Enabled {
+		return true
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.leader && time.Now().Before(l.leaseUntil)
+}
+
+// Term 当前任期；每次换主递增，调度器据此判断是否刚接管。
+func (l *LeaderElector) Term() int64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.term
+}
+
+// Status 返回本副本的选主状态及数据库中的当前持有者。
+func (l *LeaderElector) Status(ctx context.Context) (*LeaderStatus, error) {
+	st := &LeaderStatus{Enabled: l.cfg.Enabled, IsLeader: l.IsLeader(), OwnerID: l.cfg.OwnerID, LockName: l.cfg.LockName}
+	if !l.cfg.Enabled {
+		return st, nil
+	}
+	l.mu.RLock()
+	st.Term = l.term
+	if st.IsLeader {
+		until := l.leaseUntil.UTC()
+		st.LeaseUntil = &until
+	}
+	l.mu.RUnlock()
+	holder, err := l.LockDao.Get(ctx, l.cfg.LockName)
+	if err != nil {
+		return st, err
+	}
+	st.Holder = holder
+	return st, nil
+}
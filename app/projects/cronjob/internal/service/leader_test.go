@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// stubLockDao mimics the scheduler_locks upsert in memory.
+type stubLockDao struct {
+	dao.LockDao
+	mu   sync.Mutex
+	lock *model.SchedulerLock
+}
+
+func (s *stubLockDao) TryAcquire(_ context.Context, name, owner string, ttl time.Duration) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	switch {
+	case s.lock == nil:
+		s.lock = &model.SchedulerLock{LockName: name, OwnerID: owner, Term: 1}
+	case s.lock.OwnerID == owner:
+	case s.lock.ExpiresAt.Before(now):
+		s.lock.OwnerID = owner
+		s.lock.Term++
+	default:
+		return 0, false, nil
+	}
+	s.lock.ExpiresAt = now.Add(ttl)
+	return s.lock.Term, true, nil
+}
+
+func (s *stubLockDao) Release(_ context.Context, _, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lock != nil && s.lock.OwnerID == owner {
+		s.lock.ExpiresAt = time.Now().Add(-time.Second)
+	}
+	return nil
+}
+
+func (s *stubLockDao) Get(_ context.Context, _ string) (*model.SchedulerLock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lock, nil
+}
+
+func newTestElector(lock dao.LockDao, owner string, lease time.Duration) *LeaderElector {
+	l := NewLeaderElector(config.HAConfig{Enabled: true, OwnerID: owner, LeaseDuration: lease})
+	l.LockDao = lock
+	return l
+}
+
+func TestLeaderElectionFailover(t *testing.T) {
+	ctx := context.Background()
+	lock := &stubLockDao{}
+	a := newTestElector(lock, "a", 60*time.Millisecond)
+	b := newTestElector(lock, "b", 60*time.Millisecond)
+
+	a.campaign(ctx)
+	b.campaign(ctx)
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("expected a leader only: a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+	// a 停止续约（进程失联）：本地期限耗尽后不再自认 leader，租约过期后 b 接管
+	time.Sleep(80 * time.Millisecond)
+	if a.IsLeader() {
+		t.Fatal("a should not act as leader after its lease elapsed")
+	}
+	b.campaign(ctx)
+	if !b.IsLeader() || b.Term() != 2 {
+		t.Fatalf("b should take over with term 2: leader=%v term=%d", b.IsLeader(), b.Term())
+	}
+	// a 恢复后发现租约已被 b 持有
+	a.campaign(ctx)
+	if a.IsLeader() {
+		t.Fatal("a regained leadership while b holds the lease")
+	}
+	st, err := b.Status(ctx)
+	if err != nil || !st.IsLeader || st.Holder == nil || st.Holder.OwnerID != "b" {
+		t.Fatalf("unexpected status %+v err=%v", st, err)
+	}
+}
+
+func TestLeaderDisabledAlwaysLeads(t *testing.T) {
+	l := NewLeaderElector(config.HAConfig{})
+	if !l.IsLeader() || l.Term() != 0 {
+		t.Fatal("disabled HA should always act as leader with term 0")
+	}
+}
+
+// Followers never scan; a new leader re-enqueues SCHEDULED runs orphaned by the previous one
+func TestEngineTickFollowerAndTakeover(t *testing.T) {
+	ctx := context.Background()
+	task := &model.Task{ID: 5, CronExpr: "* * * * * *", TargetService: "artemis", Status: bizConsts.ENABLED,
+		OverlapAction: bizConsts.OverlapActionParallel, FailureAction: bizConsts.FailureActionRunNew}
+	ts := NewTaskService()
+	ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{5: task}}
+	if err := ts.Start(ctx); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	runDao := &stubRunDao{}
+	runDao.runs = append(runDao.runs, &model.TaskRun{ID: 99, TaskID: 5, ScheduledTime: time.Now().Add(-time.Minute), Status: bizConsts.Scheduled, Attempt: 1})
+	runDao.nextID = 99
+	exec := NewExecutor(config.ExecutorConfig{})
+	exec.RunSvc, exec.TaskSvc = &RunService{RunDao: runDao}, ts
+	lock := &stubLockDao{}
+	lock.lock = &model.SchedulerLock{LockName: bizConsts.COMP_SVC_SCHEDULER, OwnerID: "other", Term: 3, ExpiresAt: time.Now().Add(time.Hour)}
+	leader := newTestElector(lock, "me", time.Minute)
+
+	e := NewEngine(config.SchedulerConfig{PollInterval: time.Second})
+	e.TaskSvc, e.RunDao, e.Exec, e.Leader = ts, runDao, exec, leader
+
+	leader.campaign(ctx)
+	if err := e.tick(ctx, time.Now()); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	if len(runDao.runs) != 1 || len(exec.ch) != 0 {
+		t.Fatalf("follower must not schedule: runs=%d enqueued=%d", len(runDao.runs), len(exec.ch))
+	}
+
+	lock.lock.ExpiresAt = time.Now().Add(-time.Second) // 前任失联
+	leader.campaign(ctx)
+	if !leader.IsLeader() || leader.Term() != 4 {
+		t.Fatalf("expected takeover term 4, leader=%v term=%d", leader.IsLeader(), leader.Term())
+	}
+	if err := e.tick(ctx, time.Now().Add(2*time.Second)); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	if len(runDao.runs) < 2 {
+		t.Fatalf("leader should schedule new runs, got %d", len(runDao.runs))
+	}
+	countOrphan := func() int {
+		n := 0
+		for _, r := range drain(exec.ch) {
+			if r.ID == 99 {
+				n++
+			}
+		}
+		return n
+	}
+	if n := countOrphan(); n != 0 {
+		t.Fatalf("takeover tick must leave recovery to the executor, orphan enqueued %d times", n)
+	}
+	exec.RestoreQueue() // 启动时的恢复请求与接管请求合并
+	exec.dispatch(ctx)
+	exec.RestoreQueue()
+	exec.dispatch(ctx) // 仍在内存队列中的 Run 不重复入队
+	if n := countOrphan(); n != 1 {
+		t.Fatalf("expected orphaned run 99 re-enqueued once, got %d", n)
+	}
+}
+
+// drain 取出内存队列中当前的全部 Run。
+func drain(ch chan *model.TaskRun) []*model.TaskRun {
+	var out []*model.TaskRun
+	for {
+		select {
+		case r := <-ch:
+			out = append(out, r)
+		default:
+			return out
+		}
+	}
+}
+
+// Test the HA leader reloads the task cache only when the tasks table changed
+func TestEngineTickRefreshOnChange(t *testing.T) {
+	ctx := context.Background()
+	task := &model.Task{ID: 6, CronExpr: "0 0 0 1 1 *", TargetService: "artemis", Status: bizConsts.ENABLED, Version: 1}
+	taskDao := &stubDao{tasks: map[int64]*model.Task{6: task}}
+	ts := NewTaskService()
+	ts.TaskDao = taskDao
+	if err := ts.Start(ctx); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	leader := newTestElector(&stubLockDao{}, "me", time.Minute)
+	leader.campaign(ctx)
+	e := NewEngine(config.SchedulerConfig{PollInterval: time.Second})
+	e.TaskSvc, e.RunDao, e.Exec, e.Leader = ts, &stubRunDao{}, NewExecutor(config.ExecutorConfig{}), leader
+
+	now := time.Now()
+	for i := 0; i < 3; i++ { // takeover reloads once, later ticks see the same marker
+		if err := e.tick(ctx, now.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("tick: %v", err)
+		}
+	}
+	base := taskDao.lists
+	if err := e.tick(ctx, now.Add(3*time.Second)); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	if taskDao.lists != base {
+		t.Fatalf("unchanged table reloaded: lists %d -> %d", base, taskDao.lists)
+	}
+	_ = taskDao.UpdateStatus(ctx, 6, bizConsts.DISABLED) // written by another replica
+	if err := e.tick(ctx, now.Add(4*time.Second)); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	if taskDao.lists != base+1 {
+		t.Fatalf("changed table not reloaded: lists %d -> %d", base, taskDao.lists)
+	}
+	if list, _ := ts.ListEnabled(ctx); len(list) != 0 {
+		t.Fatalf("disabled task still cached: %d", len(list))
+	}
+}
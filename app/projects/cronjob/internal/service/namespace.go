@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// 命名空间与任务归属：
+//   - 任务归属于一个命名空间（默认 default），Run 创建时快照空间名
+//   - 空间可限定未删除任务数（创建 / 移入时校验）、同时执行的 Run 数（执行器取 Run 时校验，超出时延后重试，不占 worker）
+//     以及可调用的 target_service（保存任务与执行时均校验）
+//   - 配置了 namespace.admins 时启用归属校验：admin、空间 owner 或任务 owner 才能修改任务；
+//     空间与任务均未设置 owner 时任何人可修改。空间的创建与删除仅 admin 可操作
+
+var (
+	ErrInvalidNamespace = errors.New("invalid namespace")
+	ErrNamespaceInUse   = errors.New("namespace has tasks")
+	ErrNamespaceQuota   = errors.New("namespace task quota exceeded")
+	ErrForbidden        = errors.New("forbidden")
+	ErrTargetNotAllowed = errors.New("target_service not allowed in namespace")
+)
+
+var namespaceNameRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,62}[a-z0-9])?$`)
+
+// NamespaceService 命名空间的缓存、配额与归属校验。
+type NamespaceService struct {
+	*core.BaseComponent
+	cfg          config.NamespaceConfig
+	NamespaceDao dao.NamespaceDao `infra:"dep:namespace_dao"`
+	RunDao       dao.RunDao       `infra:"dep:run_dao"`
+
+	mu  sync.RWMutex
+	nss map[string]*model.Namespace
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewNamespaceService(cfg config.NamespaceConfig) *NamespaceService {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = 30 * time.Second
+	}
+	return &NamespaceService{BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_NAMESPACE), cfg: cfg}
+}
+
+func (s *NamespaceService) Start(ctx context.Context) error {
+	if s.IsActive() {
+		return nil
+	}
+	if err := s.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	if err := s.Reload(ctx); err != nil {
+		return fmt.Errorf("load namespaces failed: %w", err)
+	}
+	loopCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.cfg.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Reload(loopCtx); err != nil {
+					logging.Error(loopCtx, fmt.Sprintf("refresh namespaces failed: %v", err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *NamespaceService) Stop(ctx context.Context) error {
+	if !s.IsActive() {
+		return nil
+	}
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+	return s.BaseComponent.Stop(ctx)
+}
+
+// Reload 从数据库重载命名空间。
+func (s *NamespaceService) Reload(ctx context.Context) error {
+	list, err := s.NamespaceDao.List(ctx)
+	if err != nil {
+		return err
+	}
+	nss := make(map[string]*model.Namespace, len(list))
+	for _, ns := range list {
+		nss[ns.Name] = ns
+	}
+	s.mu.Lock()
+	s.nss = nss
+	s.mu.Unlock()
+	return nil
+}
+
+// Lookup 按名称取缓存中的命名空间；空名称视为 default。
+func (s *NamespaceService) Lookup(name string) (*model.Namespace, bool) {
+	if name == "" {
+		name = bizConsts.DEFAULT_NAMESPACE
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ns, ok := s.nss[name]
+	return ns, ok
+}
+
+// lookupFresh 缓存未命中时重载一次（可能由其他副本刚创建）。
+func (s *NamespaceService) lookupFresh(ctx context.Context, name string) (*model.Namespace, bool) {
+	if ns, ok := s.Lookup(name); ok {
+		return ns, true
+	}
+	if err := s.Reload(ctx); err != nil {
+		logging.Error(ctx, fmt.Sprintf("reload namespaces failed: %v", err))
+	}
+	return s.Lookup(name)
+}
+
+func (s *NamespaceService) List() []*model.Namespace {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]*model.Namespace, 0, len(s.nss))
+	for _, ns := range s.nss {
+		out = append(out, ns)
+	}
+	slices.SortFunc(out, func(a, b *model.Namespace) int {
+		switch {
+		case a.Name < b.Name:
+			return -1
+		case a.Name > b.Name:
+			return 1
+		}
+		return 0
+	})
+	return out
+}
+
+func (s *NamespaceService) Get(ctx context.Context, name string) (*model.Namespace, error) {
+	return s.NamespaceDao.Get(ctx, name)
+}
+
+// Usage 空间当前的任务数与执行中的 Run 数。
+func (s *NamespaceService) Usage(ctx context.Context, name string) (*model.NamespaceUsage, error) {
+	tasks, err := s.NamespaceDao.CountTasks(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	active, err := s.RunDao.CountActiveInNamespace(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &model.NamespaceUsage{Tasks: tasks, ActiveRuns: active}, nil
+}
+
+func (s *NamespaceService) Create(ctx context.Context, ns *model.Namespace) error {
+	if err := s.AuthorizeAdmin(ctx); err != nil {
+		return err
+	}
+	if err := validateNamespace(ns); err != nil {
+		return err
+	}
+	if err := s.NamespaceDao.Create(ctx, ns); err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+// Update 修改空间定义；admin 或空间 owner 可操作。
+func (s *NamespaceService) Update(ctx context.Context, ns *model.Namespace) error {
+	cur, err := s.NamespaceDao.Get(ctx, ns.Name)
+	if err != nil {
+		return err
+	}
+	if s.AuthorizeAdmin(ctx) != nil && !cur.IsOwner(ActorFrom(ctx)) {
+		return ErrForbidden
+	}
+	if err := validateNamespace(ns); err != nil {
+		return err
+	}
+	if err := s.NamespaceDao.Update(ctx, ns); err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+// Delete 删除空间；default 与仍有任务的空间不可删除。
+func (s *NamespaceService) Delete(ctx context.Context, name string) error {
+	if err := s.AuthorizeAdmin(ctx); err != nil {
+		return err
+	}
+	if name == bizConsts.DEFAULT_NAMESPACE {
+		return fmt.Errorf("%w: default namespace cannot be deleted", ErrInvalidNamespace)
+	}
+	n, err := s.NamespaceDao.CountTasks(ctx, name)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("%w: %d tasks", ErrNamespaceInUse, n)
+	}
+	if err := s.NamespaceDao.Delete(ctx, name); err != nil {
+		return err
+	}
+	return s.Reload(ctx)
+}
+
+func validateNamespace(ns *model.Namespace) error {
+	if !namespaceNameRe.MatchString(ns.Name) {
+		return fmt.Errorf("%w: name must be lowercase alphanumeric or '-', at most 64 chars", ErrInvalidNamespace)
+	}
+	if ns.MaxTasks < 0 || ns.MaxConcurrentRuns < 0 {
+		return fmt.Errorf("%w: quotas must be >= 0", ErrInvalidNamespace)
+	}
+	return nil
+}
+
+// CheckTask 校验任务保存到其空间：空间存在、target_service 在白名单内，以及新建 / 移入时的任务数配额。
+// prev 为修改前的任务，新建时为 nil。
+func (s *NamespaceService) CheckTask(ctx context.Context, t, prev *model.Task) error {
+	if t.Namespace == "" {
+		t.Namespace = bizConsts.DEFAULT_NAMESPACE
+	}
+	ns, ok := s.lookupFresh(ctx, t.Namespace)
+	if !ok {
+		return fmt.Errorf("%w: namespace %q not found", ErrInvalidNamespace, t.Namespace)
+	}
+	if !ns.AllowsTargetService(t.TargetService) {
+		return fmt.Errorf("%w: target_service %q not allowed in namespace %s", ErrInvalidNamespace, t.TargetService, ns.Name)
+	}
+	if ns.MaxTasks > 0 && (prev == nil || prev.Namespace != t.Namespace) {
+		n, err := s.NamespaceDao.CountTasks(ctx, ns.Name)
+		if err != nil {
+			return err
+		}
+		if n >= int64(ns.MaxTasks) {
+			return fmt.Errorf("%w: namespace %s allows %d tasks", ErrNamespaceQuota, ns.Name, ns.MaxTasks)
+		}
+	}
+	return nil
+}
+
+// enforced 是否启用归属校验（配置了 admins）。
+func (s *NamespaceService) enforced() bool { return len(s.cfg.Admins) > 0 }
+
+// AuthorizeAdmin 要求当前操作人为 admin；未启用归属校验时放行。
+func (s *NamespaceService) AuthorizeAdmin(ctx context.Context) error {
+	if !s.enforced() || slices.Contains(s.cfg.Admins, ActorFrom(ctx)) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// AuthorizeNamespace 当前操作人能否在空间内创建任务：admin、空间 owner，或空间未设置 owner。
+func (s *NamespaceService) AuthorizeNamespace(ctx context.Context, name string) error {
+	if s.AuthorizeAdmin(ctx) == nil {
+		return nil
+	}
+	if ns, ok := s.Lookup(name); !ok || len(ns.Owners) == 0 || ns.IsOwner(ActorFrom(ctx)) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// Authorize 当前操作人能否管理任务：admin、任务所在空间的 owner、任务 owner；空间与任务均无 owner 时任何人可管理。
+func (s *NamespaceService) Authorize(ctx context.Context, t *model.Task) error {
+	if s.AuthorizeAdmin(ctx) == nil || slices.Contains(t.Owners, ActorFrom(ctx)) {
+		return nil
+	}
+	if len(t.Owners) > 0 {
+		if ns, ok := s.Lookup(t.Namespace); ok && ns.IsOwner(ActorFrom(ctx)) {
+			return nil
+		}
+		return ErrForbidden
+	}
+	return s.AuthorizeNamespace(ctx, t.Namespace)
+}
+
+// AdmitRun 执行器取到 Run 后的空间校验：target_service 不在白名单时返回错误；
+// 并发配额已满时返回 dao.ErrNamespaceQuotaExceeded（Run 仍为 SCHEDULED）。ok 表示已转为 RUNNING。
+func (s *NamespaceService) AdmitRun(ctx context.Context, runs *RunService, run *model.TaskRun) (ok bool, err error) {
+	ns, found := s.Lookup(run.Namespace)
+	if !found {
+		return runs.TransitionToRunning(ctx, run.ID)
+	}
+	if !ns.AllowsTargetService(run.TargetService) {
+		return false, fmt.Errorf("%w: %q in %s", ErrTargetNotAllowed, run.TargetService, ns.Name)
+	}
+	return runs.TransitionToRunningWithin(ctx, run.ID, ns.Name, ns.MaxConcurrentRuns)
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// stubNamespaceDao 内存版 NamespaceDao
+type stubNamespaceDao struct {
+	dao.NamespaceDao
+	nss   map[string]*model.Namespace
+	tasks map[string]int64
+}
+
+func (d *stubNamespaceDao) List(_ context.Context) ([]*model.Namespace, error) {
+	var out []*model.Namespace
+	for _, ns := range d.nss {
+		out = append(out, ns)
+	}
+	return out, nil
+}
+func (d *stubNamespaceDao) CountTasks(_ context.Context, name string) (int64, error) {
+	return d.tasks[name], nil
+}
+
+// quotaRunDao 记录 TransitionToRunningWithin 的调用，配额满时返回 ErrNamespaceQuotaExceeded。
+type quotaRunDao struct {
+	stubRunDao
+	active map[string]int
+}
+
+func (r *quotaRunDao) TransitionToRunningWithin(_ context.Context, _ int64, namespace string, maxActive int) (bool, error) {
+	if maxActive > 0 && r.active[namespace] >= maxActive {
+		return false, dao.ErrNamespaceQuotaExceeded
+	}
+	r.active[namespace]++
+	return true, nil
+}
+
+func newTestNamespaces(t *testing.T, admins ...string) *NamespaceService {
+	s := NewNamespaceService(config.NamespaceConfig{Admins: admins})
+	s.NamespaceDao = &stubNamespaceDao{
+		nss: map[string]*model.Namespace{
+			"default": {Name: "default"},
+			"quant":   {Name: "quant", Owners: []string{"alice"}, MaxTasks: 2, MaxConcurrentRuns: 1, AllowedTargetServices: []string{"artemis"}},
+		},
+		tasks: map[string]int64{"quant": 2},
+	}
+	if err := s.Reload(context.Background()); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	return s
+}
+
+func TestNamespaceAuthorize(t *testing.T) {
+	s := newTestNamespaces(t, "root")
+	as := func(actor string) context.Context { return WithActor(context.Background(), actor) }
+	quantTask := &model.Task{Namespace: "quant", Owners: []string{"bob"}}
+	cases := []struct {
+		name  string
+		actor string
+		task  *model.Task
+		ok    bool
+	}{
+		{"admin", "root", quantTask, true},
+		{"namespace owner", "alice", quantTask, true},
+		{"task owner", "bob", quantTask, true},
+		{"other", "carol", quantTask, false},
+		{"unowned task in owned namespace", "carol", &model.Task{Namespace: "quant"}, false},
+		{"unowned task in open namespace", "carol", &model.Task{Namespace: "default"}, true},
+		{"owned task in open namespace", "carol", &model.Task{Namespace: "default", Owners: []string{"bob"}}, false},
+	}
+	for _, c := range cases {
+		err := s.Authorize(as(c.actor), c.task)
+		if (err == nil) != c.ok {
+			t.Fatalf("%s: err=%v, want ok=%v", c.name, err, c.ok)
+		}
+	}
+	if s.AuthorizeNamespace(as("carol"), "quant") == nil || s.AuthorizeNamespace(as("carol"), "default") != nil {
+		t.Fatal("unexpected namespace authorization")
+	}
+	// 未配置 admins 时不校验归属
+	if err := newTestNamespaces(t).Authorize(as("carol"), quantTask); err != nil {
+		t.Fatalf("open mode: %v", err)
+	}
+}
+
+func TestNamespaceCheckTask(t *testing.T) {
+	ctx := context.Background()
+	s := newTestNamespaces(t)
+	if err := s.CheckTask(ctx, &model.Task{Namespace: "missing"}, nil); !errors.Is(err, ErrInvalidNamespace) {
+		t.Fatalf("missing namespace: %v", err)
+	}
+	if err := s.CheckTask(ctx, &model.Task{Namespace: "quant", TargetService: "hermes"}, nil); !errors.Is(err, ErrInvalidNamespace) {
+		t.Fatalf("target not allowed: %v", err)
+	}
+	if err := s.CheckTask(ctx, &model.Task{Namespace: "quant", TargetService: "artemis"}, nil); !errors.Is(err, ErrNamespaceQuota) {
+		t.Fatalf("task quota: %v", err)
+	}
+	// 空间内已有任务的修改不受任务数配额限制
+	prev := &model.Task{Namespace: "quant", TargetService: "artemis"}
+	if err := s.CheckTask(ctx, &model.Task{Namespace: "quant", TargetService: "artemis"}, prev); err != nil {
+		t.Fatalf("update within namespace: %v", err)
+	}
+	task := &model.Task{}
+	if err := s.CheckTask(ctx, task, nil); err != nil || task.Namespace != "default" {
+		t.Fatalf("default namespace: %v %q", err, task.Namespace)
+	}
+}
+
+func TestNamespaceAdmitRun(t *testing.T) {
+	ctx := context.Background()
+	s := newTestNamespaces(t)
+	runSvc := NewRunService()
+	runSvc.RunDao = &quotaRunDao{active: map[string]int{}}
+	if ok, err := s.AdmitRun(ctx, runSvc, &model.TaskRun{ID: 1, Namespace: "quant", TargetService: "artemis"}); !ok || err != nil {
+		t.Fatalf("first run: ok=%v err=%v", ok, err)
+	}
+	if _, err := s.AdmitRun(ctx, runSvc, &model.TaskRun{ID: 2, Namespace: "quant", TargetService: "artemis"}); !errors.Is(err, dao.ErrNamespaceQuotaExceeded) {
+		t.Fatalf("second run: %v", err)
+	}
+	if _, err := s.AdmitRun(ctx, runSvc, &model.TaskRun{ID: 3, Namespace: "quant", TargetService: "hermes"}); !errors.Is(err, ErrTargetNotAllowed) {
+		t.Fatalf("target not allowed: %v", err)
+	}
+	if ok, err := s.AdmitRun(ctx, runSvc, &model.TaskRun{ID: 4, Namespace: "default", TargetService: "hermes"}); !ok || err != nil {
+		t.Fatalf("default namespace: ok=%v err=%v", ok, err)
+	}
+}
+
+// Test quota-deferred runs stay SCHEDULED and are retried one at a time per namespace, in id order
+func TestExecutorDefersRunsInOrder(t *testing.T) {
+	ctx := context.Background()
+	runDao := &quotaRunDao{active: map[string]int{}}
+	exec := NewExecutor(config.ExecutorConfig{})
+	exec.Namespaces, exec.RunSvc = newTestNamespaces(t), &RunService{RunDao: runDao}
+	run := func(id int64) *model.TaskRun {
+		return &model.TaskRun{ID: id, Namespace: "quant", TargetService: "artemis", Status: bizConsts.Scheduled}
+	}
+	if ok, err := exec.admit(ctx, run(1)); !ok || err != nil {
+		t.Fatalf("first run: ok=%v err=%v", ok, err)
+	}
+	take := func() []*model.TaskRun { // 与 worker 一样取走 Run
+		got := drain(exec.ch)
+		exec.mu.Lock()
+		for _, r := range got {
+			delete(exec.queued, r.ID)
+		}
+		exec.mu.Unlock()
+		return got
+	}
+	r2, r3, r4 := run(2), run(3), run(4)
+	for _, r := range []*model.TaskRun{r3, r2, r4} {
+		if ok, err := exec.admit(ctx, r); ok || err != nil {
+			t.Fatalf("run %d should be deferred: ok=%v err=%v", r.ID, ok, err)
+		}
+	}
+	exec.dispatch(ctx)
+	exec.dispatch(ctx) // 队首仍在途，不放出下一个
+	if got := take(); len(got) != 1 || got[0] != r2 {
+		t.Fatalf("expected only the oldest deferred run retried, got %v", got)
+	}
+	if ok, _ := exec.admit(ctx, r2); ok { // 配额仍满：回到暂缓列表
+		t.Fatal("run 2 admitted over quota")
+	}
+	runDao.active["quant"] = 0
+	exec.dispatch(ctx)
+	if got := take(); len(got) != 1 || got[0] != r2 {
+		t.Fatalf("expected run 2 retried again, got %v", got)
+	}
+	if ok, err := exec.admit(ctx, r2); !ok || err != nil {
+		t.Fatalf("run 2 after slot freed: ok=%v err=%v", ok, err)
+	}
+	exec.dispatch(ctx)
+	if got := take(); len(got) != 1 || got[0] != r3 {
+		t.Fatalf("expected run 3 next, got %v", got)
+	}
+}
+
+// Test Enqueue blocked on a full queue returns on Stop, and later Enqueue calls are dropped
+func TestExecutorEnqueueDuringStop(t *testing.T) {
+	ctx := context.Background()
+	exec := NewExecutor(config.ExecutorConfig{})
+	if err := exec.BaseComponent.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	for len(exec.ch) < cap(exec.ch) {
+		exec.ch <- &model.TaskRun{}
+	}
+	returned := make(chan struct{})
+	go func() {
+		exec.Enqueue(&model.TaskRun{ID: 1})
+		close(returned)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	if err := exec.Stop(ctx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("Enqueue still blocked after Stop")
+	}
+	exec.Enqueue(&model.TaskRun{ID: 2}) // 停止后直接丢弃，不能 panic 或阻塞
+}
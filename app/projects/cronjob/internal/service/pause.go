@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// 调度器全局暂停（如上游维护窗口）：
+//   - 状态持久化在 scheduler_pause 表，各副本每 pauseRefreshInterval 重载一次，因此在任一副本上暂停 / 恢复，
+//     leader 最迟在一个刷新周期后生效（本副本立即生效）
+//   - 暂停期间 leader 不执行 cron scan，也不评估依赖下游、派发补跑与到期重试；这些触发在恢复后继续，
+//     cron 游标不前移，错过的触发按任务的 misfire 策略处理（与服务停机相同）
+//   - 已创建的 Run（含排队中的）照常执行；手动触发、重跑不受暂停限制
+//   - 指定 until 时到期自动恢复：Paused 按本地时钟立即判定为已恢复，刷新时再落库（resumed_by = auto）
+
+// ErrInvalidPause 暂停参数非法（恢复时间不在未来）。
+var ErrInvalidPause = errors.New("invalid pause")
+
+// pauseRefreshInterval 重载暂停状态的间隔。
+const pauseRefreshInterval = 5 * time.Second
+
+// SchedulerPauseService 调度器全局暂停状态的缓存与变更。
+type SchedulerPauseService struct {
+	*core.BaseComponent
+	PauseDao dao.PauseDao `infra:"dep:pause_dao"`
+
+	mu    sync.RWMutex
+	state *model.SchedulerPause
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewSchedulerPauseService() *SchedulerPauseService {
+	return &SchedulerPauseService{BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_PAUSE)}
+}
+
+func (s *SchedulerPauseService) Start(ctx context.Context) error {
+	if s.IsActive() {
+		return nil
+	}
+	if err := s.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	if err := s.Reload(ctx); err != nil {
+		return fmt.Errorf("load scheduler pause failed: %w", err)
+	}
+	loopCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(pauseRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+				s.refresh(loopCtx)
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *SchedulerPauseService) Stop(ctx context.Context) error {
+	if !s.IsActive() {
+		return nil
+	}
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+	return s.BaseComponent.Stop(ctx)
+}
+
+// Reload 从数据库重载暂停状态。
+func (s *SchedulerPauseService) Reload(ctx context.Context) error {
+	st, err := s.PauseDao.Get(ctx)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.state = st
+	s.mu.Unlock()
+	return nil
+}
+
+// refresh 到期的暂停先落库恢复，再重载状态。
+// 需读取原始状态：State() 已按本地时钟把到期的暂停修正为未暂停。
+func (s *SchedulerPauseService) refresh(ctx context.Context) {
+	s.mu.RLock()
+	st := s.state
+	s.mu.RUnlock()
+	if st != nil && st.Paused && !st.Active(time.Now()) {
+		if ok, err := s.PauseDao.ResumeExpired(ctx); err != nil {
+			logging.Error(ctx, fmt.Sprintf("auto resume scheduler failed: %v", err))
+		} else if ok {
+			logging.Info(ctx, fmt.Sprintf("scheduler resumed automatically (paused until %s)", st.PausedUntil.Format(time.RFC3339)))
+		}
+	}
+	if err := s.Reload(ctx); err != nil {
+		logging.Error(ctx, fmt.Sprintf("refresh scheduler pause failed: %v", err))
+	}
+}
+
+// Paused 调度器当前是否暂停；s 为 nil（未装配暂停服务）时恒为 false。
+func (s *SchedulerPauseService) Paused() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state.Active(time.Now())
+}
+
+// State 返回当前状态的副本；Paused 字段按本地时钟修正（已过 paused_until 视为已恢复）。
+func (s *SchedulerPauseService) State() model.SchedulerPause {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.state == nil {
+		return model.SchedulerPause{}
+	}
+	st := *s.state
+	st.Paused = s.state.Active(time.Now())
+	return st
+}
+
+// Pause 暂停调度器，until 为 nil 表示需手动恢复；已暂停时覆盖恢复时间与原因。
+func (s *SchedulerPauseService) Pause(ctx context.Context, until *time.Time, reason string) (model.SchedulerPause, error) {
+	if until != nil {
+		if !until.After(time.Now()) {
+			return model.SchedulerPause{}, fmt.Errorf("%w: until %s is not in the future", ErrInvalidPause, until.Format(time.RFC3339))
+		}
+		u := until.UTC()
+		until = &u
+	}
+	actor := ActorFrom(ctx)
+	if err := s.PauseDao.Pause(ctx, until, reason, actor); err != nil {
+		return model.SchedulerPause{}, err
+	}
+	if err := s.Reload(ctx); err != nil {
+		return model.SchedulerPause{}, err
+	}
+	msg := fmt.Sprintf("scheduler paused by %s reason=%q", actor, reason)
+	if until != nil {
+		msg += " until " + until.Format(time.RFC3339)
+	}
+	logging.Info(ctx, msg)
+	return s.State(), nil
+}
+
+// Resume 恢复调度器，返回恢复后的状态与是否由暂停变为恢复。
+func (s *SchedulerPauseService) Resume(ctx context.Context) (model.SchedulerPause, bool, error) {
+	actor := ActorFrom(ctx)
+	resumed, err := s.PauseDao.Resume(ctx, actor)
+	if err != nil {
+		return model.SchedulerPause{}, false, err
+	}
+	if err := s.Reload(ctx); err != nil {
+		return model.SchedulerPause{}, false, err
+	}
+	if resumed {
+		logging.Info(ctx, fmt.Sprintf("scheduler resumed by %s", actor))
+	}
+	return s.State(), resumed, nil
+}
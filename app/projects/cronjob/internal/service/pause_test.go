@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// stubPauseDao keeps the single scheduler_pause row in memory.
+type stubPauseDao struct {
+	dao.PauseDao
+	row model.SchedulerPause
+}
+
+func (s *stubPauseDao) Get(_ context.Context) (*model.SchedulerPause, error) {
+	cp := s.row
+	return &cp, nil
+}
+
+func (s *stubPauseDao) Pause(_ context.Context, until *time.Time, reason, actor string) error {
+	now := time.Now()
+	s.row.Paused, s.row.PausedUntil, s.row.Reason, s.row.PausedBy, s.row.PausedAt = true, until, reason, actor, &now
+	return nil
+}
+
+func (s *stubPauseDao) Resume(_ context.Context, actor string) (bool, error) {
+	if !s.row.Paused {
+		return false, nil
+	}
+	now := time.Now()
+	s.row.Paused, s.row.ResumedBy, s.row.ResumedAt = false, actor, &now
+	return true, nil
+}
+
+func (s *stubPauseDao) ResumeExpired(_ context.Context) (bool, error) {
+	if !s.row.Paused || s.row.PausedUntil == nil || s.row.PausedUntil.After(time.Now()) {
+		return false, nil
+	}
+	s.row.Paused, s.row.ResumedBy, s.row.ResumedAt = false, "auto", s.row.PausedUntil
+	return true, nil
+}
+
+func newTestPause(t *testing.T) (*SchedulerPauseService, *stubPauseDao) {
+	stub := &stubPauseDao{}
+	p := NewSchedulerPauseService()
+	p.PauseDao = stub
+	if err := p.Reload(context.Background()); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	return p, stub
+}
+
+func TestSchedulerPauseUntil(t *testing.T) {
+	ctx := WithActor(context.Background(), "ops@example.com")
+	p, stub := newTestPause(t)
+	if p.Paused() {
+		t.Fatal("fresh scheduler must not be paused")
+	}
+	past := time.Now().Add(-time.Minute)
+	if _, err := p.Pause(ctx, &past, "late"); !errors.Is(err, ErrInvalidPause) {
+		t.Fatalf("pause until the past: err=%v, want ErrInvalidPause", err)
+	}
+
+	until := time.Now().Add(50 * time.Millisecond)
+	st, err := p.Pause(ctx, &until, "artemis maintenance")
+	if err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if !st.Paused || !p.Paused() || st.PausedBy != "ops@example.com" || st.Reason != "artemis maintenance" {
+		t.Fatalf("unexpected state after pause: %+v", st)
+	}
+	p.refresh(ctx) // not yet expired: nothing persisted
+	if !stub.row.Paused {
+		t.Fatal("pause must not be resumed before until")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if p.Paused() {
+		t.Fatal("pause must end at until even before the refresh persists it")
+	}
+	p.refresh(ctx)
+	if stub.row.Paused || stub.row.ResumedBy != "auto" {
+		t.Fatalf("expired pause should be resumed automatically, row=%+v", stub.row)
+	}
+	if _, resumed, err := p.Resume(ctx); err != nil || resumed {
+		t.Fatalf("resume after auto resume: resumed=%v err=%v", resumed, err)
+	}
+}
+
+func TestEngineTickPaused(t *testing.T) {
+	ctx := context.Background()
+	task := &model.Task{ID: 7, CronExpr: "* * * * * *", TargetService: "artemis", Status: bizConsts.ENABLED,
+		OverlapAction: bizConsts.OverlapActionParallel, FailureAction: bizConsts.FailureActionRunNew}
+	ts := NewTaskService()
+	ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{7: task}}
+	if err := ts.Start(ctx); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	runDao := &stubRunDao{}
+	pause, _ := newTestPause(t)
+	e := NewEngine(config.SchedulerConfig{PollInterval: time.Second})
+	e.TaskSvc, e.RunDao, e.Exec, e.Leader, e.Pause = ts, runDao, NewExecutor(config.ExecutorConfig{}), NewLeaderElector(config.HAConfig{}), pause
+
+	if _, err := pause.Pause(ctx, nil, "maintenance"); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	now := time.Now().Truncate(time.Second)
+	for i := 0; i < 3; i++ {
+		if err := e.tick(ctx, now.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("tick: %v", err)
+		}
+	}
+	if len(runDao.runs) != 0 {
+		t.Fatalf("paused scheduler must not fire, got %d runs", len(runDao.runs))
+	}
+
+	if _, resumed, err := pause.Resume(ctx); err != nil || !resumed {
+		t.Fatalf("resume: resumed=%v err=%v", resumed, err)
+	}
+	if err := e.tick(ctx, now.Add(3*time.Second)); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	if len(runDao.runs) == 0 {
+		t.Fatal("resumed scheduler should fire again")
+	}
+}
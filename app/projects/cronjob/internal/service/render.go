@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/cron"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/render"
+)
+
+// 请求模板：Run 创建时保存任务 target_path / headers_json / body_template 的原始模板，
+// 执行前按 Run 上下文（触发时间、业务日期、attempt 等，时间取任务时区）渲染；
+// 密钥只能通过 secret 函数读取带 executor.secret_env_prefix 前缀的环境变量，落库快照中打码。
+
+// Render 按任务与 Run 渲染模板（run 上的 TargetPath / RequestHeaders / RequestBody 视为模板）。
+func (e *Executor) Render(task *model.Task, run *model.TaskRun, now time.Time) (*render.Result, error) {
+	loc, err := cron.LoadLocation(task.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	scheduled := run.ScheduledTime.In(loc)
+	logical := LogicalDate(task, run.ScheduledTime)
+	if run.LogicalDate != nil {
+		logical = *run.LogicalDate
+	}
+	data := render.Data{
+		RunID:         run.ID,
+		TaskID:        task.ID,
+		TaskName:      task.Name,
+		Attempt:       run.Attempt,
+		RetryIndex:    run.RetryIndex,
+		TriggerType:   string(run.TriggerType),
+		Timezone:      loc.String(),
+		ScheduledTime: scheduled,
+		LogicalDate:   time.Date(logical.Year(), logical.Month(), logical.Day(), 0, 0, 0, 0, loc),
+		Now:           now.In(loc),
+	}
+	if run.BackfillID != nil {
+		data.BackfillID = *run.BackfillID
+	}
+	env := render.Env{Calendar: e.Calendars.Calendar, Secret: e.secret}
+	res, err := render.Render(run.TargetPath, run.RequestHeaders, run.RequestBody, data, env)
+	if err != nil {
+		return nil, err
+	}
+	if run.Executor != bizConsts.ExecutorCommand {
+		return res, nil
+	}
+	spec, err := model.ParseExecutorSpec(run.ExecutorConfig)
+	if err != nil {
+		return nil, err
+	}
+	if res.Args, err = render.RenderStrings(res, "args", spec.Args, data, env); err != nil {
+		return nil, err
+	}
+	names, values := sortedEnv(spec.Env)
+	if values, err = render.RenderStrings(res, "env", values, data, env); err != nil {
+		return nil, err
+	}
+	res.Env = make(map[string]string, len(names))
+	for i, k := range names {
+		res.Env[k] = values[i]
+	}
+	return res, nil
+}
+
+// sortedEnv 按名称排序拆分环境变量，保证渲染顺序稳定。
+func sortedEnv(env map[string]string) ([]string, []string) {
+	names := make([]string, 0, len(env))
+	for k := range env {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	values := make([]string, len(names))
+	for i, k := range names {
+		values[i] = env[k]
+	}
+	return names, values
+}
+
+// renderRun 渲染 run 的模板并写回（仅内存，用于构建请求）；不含模板时无需查询任务。
+func (e *Executor) renderRun(ctx context.Context, run *model.TaskRun) (*render.Result, error) {
+	if !render.IsTemplate(run.TargetPath) && !render.IsTemplate(run.RequestHeaders) && !render.IsTemplate(run.RequestBody) &&
+		(run.Executor != bizConsts.ExecutorCommand || !render.IsTemplate(run.ExecutorConfig)) {
+		res := &render.Result{Path: run.TargetPath, Headers: run.RequestHeaders, Body: run.RequestBody}
+		if run.Executor == bizConsts.ExecutorCommand {
+			spec, err := model.ParseExecutorSpec(run.ExecutorConfig)
+			if err != nil {
+				return nil, err
+			}
+			res.Args, res.Env = spec.Args, spec.Env
+		}
+		return res, nil
+	}
+	task, err := e.TaskSvc.Get(ctx, run.TaskID)
+	if err != nil {
+		return nil, fmt.Errorf("load task %d: %w", run.TaskID, err)
+	}
+	res, err := e.Render(task, run, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	run.TargetPath, run.RequestHeaders, run.RequestBody = res.Path, res.Headers, res.Body
+	return res, nil
+}
+
+// secret 读取 <secret_env_prefix><name> 环境变量。
+func (e *Executor) secret(name string) (string, bool) {
+	return os.LookupEnv(e.cfg.SecretEnvPrefix + name)
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// RetryPolicy 返回任务的重试策略；任务不存在或策略非法时返回 nil（不重试）。
+func (s *TaskService) RetryPolicy(ctx context.Context, taskID int64) (*model.Task, *model.RetryPolicy) {
+	task, err := s.Get(ctx, taskID)
+	if err != nil || task == nil {
+		return nil, nil
+	}
+	p, err := model.ParseRetryPolicy(task.RetryPolicyJSON)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("task %d %v", taskID, err))
+		return task, nil
+	}
+	return task, p
+}
+
+// PlanRetry 失败后按策略计划下一次重试（写入 next_retry_time），返回是否已计划。
+// 重试次数按链路计算：run.RetryIndex 达到 max_retries 后不再重试。
+func (s *RunService) PlanRetry(ctx context.Context, run *model.TaskRun, p *model.RetryPolicy, o model.RetryOutcome) bool {
+	if !p.Enabled() || run.RetryIndex >= p.MaxRetries || !p.ShouldRetry(o) {
+		return false
+	}
+	n := run.RetryIndex + 1
+	next := time.Now().Add(p.Backoff(n, rand.Float64())).UTC()
+	if err := s.RunDao.SetNextRetryTime(ctx, run.ID, &next); err != nil {
+		logging.Error(ctx, fmt.Sprintf("plan retry for run %d failed: %v", run.ID, err))
+		return false
+	}
+	logging.Info(ctx, fmt.Sprintf("run %d failed (%s); retry %d/%d at %s", run.ID, o.Kind, n, p.MaxRetries, next.Format(time.RFC3339)))
+	return true
+}
+
+// dispatchRetry 为到期的失败 Run 创建并入队重试 Run。
+// 重试 Run 沿用原触发点的 scheduled_time，请求快照取任务当前配置；
+// 先建 Run 再清除父 Run 的 next_retry_time，(retry_of, retry_index) 唯一保证多副本并发派发只落一条。
+// 返回 false 表示本轮暂缓（并发已满），保留 next_retry_time 等下一轮。
+func dispatchRetry(ctx context.Context, taskSvc *TaskService, runSvc *RunService, exec *Executor, parent *model.TaskRun) bool {
+	task, p := taskSvc.RetryPolicy(ctx, parent.TaskID)
+	if task == nil || task.Status != bizConsts.ENABLED || task.Deleted != 0 || !p.Enabled() {
+		// 任务已停用/删除或策略已移除：放弃重试
+		_ = runSvc.SetNextRetryTime(ctx, parent.ID, nil)
+		return true
+	}
+	if p.CountsConcurrency() && task.MaxConcurrency > 0 && exec.ActiveCount(task.ID) >= task.MaxConcurrency {
+		return false
+	}
+	root := parent.ID
+	if parent.RetryOf != nil {
+		root = *parent.RetryOf
+	}
+	run := taskSvc.CreateTaskRun(task, parent.ScheduledTime, parent.Attempt+1)
+	run.RetryOf, run.RetryIndex, run.TraceID = &root, parent.RetryIndex+1, parent.TraceID
+	run.LogicalDate, run.TriggerType, run.DagGeneration = parent.LogicalDate, parent.TriggerType, parent.DagGeneration
+	run.BackfillID = parent.BackfillID
+	err := runSvc.CreateRetry(ctx, run)
+	if err != nil && !errors.Is(err, dao.ErrDuplicateRun) {
+		logging.Error(ctx, fmt.Sprintf("create retry for run %d failed: %v", parent.ID, err))
+		return false
+	}
+	_ = runSvc.SetNextRetryTime(ctx, parent.ID, nil)
+	if err != nil { // 其他副本已派发
+		return true
+	}
+	logging.Info(ctx, fmt.Sprintf("retry dispatched run=%d retry_of=%d retry_index=%d attempt=%d", run.ID, root, run.RetryIndex, run.Attempt))
+	exec.Enqueue(run)
+	return true
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+func (r *stubRunDao) CreateRetry(_ context.Context, run *model.TaskRun) error {
+	for _, ru := range r.runs {
+		if ru.RetryOf != nil && *ru.RetryOf == *run.RetryOf && ru.RetryIndex == run.RetryIndex {
+			return dao.ErrDuplicateRun
+		}
+	}
+	return r.CreateScheduled(context.Background(), run)
+}
+func (r *stubRunDao) SetNextRetryTime(_ context.Context, runID int64, at *time.Time) error {
+	for _, ru := range r.runs {
+		if ru.ID == runID {
+			ru.NextRetryTime = at
+		}
+	}
+	return nil
+}
+
+func TestRetryPlanAndDispatch(t *testing.T) {
+	ctx := context.Background()
+	task := &model.Task{ID: 7, TargetService: "artemis", Status: bizConsts.ENABLED, MaxConcurrency: 1,
+		RetryPolicyJSON: `{"max_retries":2,"initial_backoff":"30s"}`}
+	ts := NewTaskService()
+	ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{7: task}}
+	if err := ts.Start(ctx); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	runDao := &stubRunDao{}
+	rs := &RunService{RunDao: runDao}
+	exec := NewExecutor(config.ExecutorConfig{})
+
+	fire := time.Date(2026, 10, 16, 7, 5, 0, 0, time.UTC)
+	parent := &model.TaskRun{TaskID: 7, ScheduledTime: fire, Status: bizConsts.Failed, Attempt: 1}
+	_ = runDao.CreateScheduled(ctx, parent)
+
+	_, policy := ts.RetryPolicy(ctx, 7)
+	if rs.PlanRetry(ctx, parent, policy, model.RetryOutcome{Kind: model.OutcomeHTTP, HTTPStatus: 400}) {
+		t.Fatal("4xx should not be retried by default")
+	}
+	if !rs.PlanRetry(ctx, parent, policy, model.RetryOutcome{Kind: model.OutcomeHTTP, HTTPStatus: 502}) {
+		t.Fatal("502 should be retried")
+	}
+	if d := time.Until(*parent.NextRetryTime); d < 25*time.Second || d > 30*time.Second {
+		t.Fatalf("unexpected retry delay %s", d)
+	}
+
+	// 并发已满：暂缓，保留 next_retry_time
+	exec.activePerTask[7] = 1
+	if dispatchRetry(ctx, ts, rs, exec, parent) || parent.NextRetryTime == nil {
+		t.Fatal("retry should be postponed while at max_concurrency")
+	}
+	delete(exec.activePerTask, 7)
+	if !dispatchRetry(ctx, ts, rs, exec, parent) {
+		t.Fatal("dispatch failed")
+	}
+	if len(runDao.runs) != 2 || parent.NextRetryTime != nil {
+		t.Fatalf("expected one retry run and cleared parent, runs=%d", len(runDao.runs))
+	}
+	child := runDao.runs[1]
+	if *child.RetryOf != parent.ID || child.RetryIndex != 1 || child.Attempt != 2 || !child.ScheduledTime.Equal(fire) {
+		t.Fatalf("unexpected retry run %+v", child)
+	}
+	if got := <-exec.ch; got != child {
+		t.Fatal("retry run not enqueued")
+	}
+	// 重复派发（另一副本）不会再建
+	dispatchRetry(ctx, ts, rs, exec, parent)
+	if len(runDao.runs) != 2 {
+		t.Fatalf("duplicate dispatch created run, runs=%d", len(runDao.runs))
+	}
+
+	// 链路用尽后不再重试
+	last := &model.TaskRun{ID: 50, TaskID: 7, RetryOf: child.RetryOf, RetryIndex: 2, Status: bizConsts.Failed}
+	if rs.PlanRetry(ctx, last, policy, model.RetryOutcome{Kind: model.OutcomeTimeout}) {
+		t.Fatal("retry budget exhausted but retry planned")
+	}
+}
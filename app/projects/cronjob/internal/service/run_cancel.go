@@ -0,0 +1,269 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// 取消与执行超时：
+//   - 所有取消（API、补跑取消、CANCEL_PREV、执行超时、服务停止）走 Executor.Cancel：先在 Run 上记录原因与时间，
+//     再取消本副本的执行上下文，并向目标服务发送协作取消请求（POST executor.cancel_path）
+//   - 执行中的 Run 在其他副本时，由各副本的扫描器发现已请求取消的 RUNNING Run 并取消本地上下文
+//   - 下游确认（取消接口返回 {"canceled": true} 或回调 result=canceled）后结束 Run：执行超时记为 TIMEOUT，其余为 CANCELED；
+//     宽限期（executor.kill_grace）内仍未结束的由扫描器标记 KILLED，下游状态未知，不自动重试
+//   - SYNC 与 ASYNC 第一阶段受执行上下文的超时约束；ASYNC 等待回调阶段由扫描器按 start_time + execution_timeout_sec 发起取消
+
+// cancelCause 执行上下文被取消的原因，经 context.Cause 取回。
+type cancelCause struct {
+	reason bizConsts.CancelReason
+}
+
+func (c *cancelCause) Error() string { return "run canceled: " + string(c.reason) }
+
+// cancelReasonOf 执行上下文是否因取消或执行超时结束，返回原因。
+func cancelReasonOf(ctx context.Context) (bizConsts.CancelReason, bool) {
+	var c *cancelCause
+	if errors.As(context.Cause(ctx), &c) {
+		return c.reason, true
+	}
+	return "", false
+}
+
+// Cancel 以 reason 取消 Run，返回取消后 Run 的状态：
+// 未开始的 Run 直接结束为 CANCELED；执行中的 Run 返回原状态（RUNNING / CALLBACK_PENDING），表示已请求取消、等待下游确认。
+func (e *Executor) Cancel(ctx context.Context, run *model.TaskRun, reason bizConsts.CancelReason) (bizConsts.RunStatus, error) {
+	if run.Status == bizConsts.Scheduled || run.Status == bizConsts.Queued {
+		if err := e.RunSvc.MarkCanceled(ctx, run.ID, reason); err != nil {
+			return run.Status, err
+		}
+		latest, err := e.RunSvc.Get(ctx, run.ID)
+		if err != nil {
+			return run.Status, err
+		}
+		run = latest // 条件更新未命中时 Run 可能刚开始执行，按最新状态继续
+	}
+	if run.Status != bizConsts.Running && run.Status != bizConsts.CallbackPending {
+		return run.Status, nil
+	}
+	first, err := e.RunSvc.RequestCancel(ctx, run.ID, reason)
+	if err != nil {
+		return run.Status, err
+	}
+	e.cancelLocal(run.ID, reason)
+	if first {
+		logging.Info(ctx, fmt.Sprintf("run %d cancel requested (%s)", run.ID, reason))
+		go e.cancelRemote(context.WithoutCancel(ctx), run, reason)
+	}
+	return run.Status, nil
+}
+
+// CancelAndWait 取消一组 Run 并等待它们结束；宽限期内未结束的直接标记 KILLED。
+func (e *Executor) CancelAndWait(ctx context.Context, runs []*model.TaskRun, reason bizConsts.CancelReason) {
+	pending := make(map[int64]*model.TaskRun, len(runs))
+	for _, run := range runs {
+		st, err := e.Cancel(ctx, run, reason)
+		if err != nil {
+			logging.Error(ctx, fmt.Sprintf("cancel run %d failed: %v", run.ID, err))
+		}
+		if !st.Finished() {
+			pending[run.ID] = run
+		}
+	}
+	deadline := time.Now().Add(e.cfg.KillGrace)
+	ticker := time.NewTicker(cancelPollInterval)
+	defer ticker.Stop()
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for id := range pending {
+			if latest, err := e.RunSvc.Get(ctx, id); err == nil && latest.Status.Finished() {
+				delete(pending, id)
+			}
+		}
+		if len(pending) > 0 && time.Now().After(deadline) {
+			for _, run := range pending {
+				e.kill(ctx, run, reason)
+			}
+			return
+		}
+	}
+}
+
+// cancelPollInterval CancelAndWait 检查 Run 是否结束的间隔。
+const cancelPollInterval = 500 * time.Millisecond
+
+// cancelLocal 取消本副本上该 Run 的执行上下文，返回 Run 是否在本副本执行。
+func (e *Executor) cancelLocal(runID int64, reason bizConsts.CancelReason) bool {
+	e.mu.Lock()
+	cancel, ok := e.cancelMap[runID]
+	e.mu.Unlock()
+	if ok {
+		cancel(&cancelCause{reason: reason})
+	}
+	return ok
+}
+
+// cancelRemote 发送协作取消请求；下游确认时结束仍在执行的 Run。
+func (e *Executor) cancelRemote(ctx context.Context, run *model.TaskRun, reason bizConsts.CancelReason) {
+	confirmed, err := e.CancelRemote(ctx, run, reason)
+	if err != nil {
+		logging.Warn(ctx, fmt.Sprintf("cancel-remote request failed for run %d: %v", run.ID, err))
+		return
+	}
+	if confirmed {
+		e.endConfirmed(ctx, run, reason)
+	}
+}
+
+// endConfirmed 下游确认取消：执行超时记为 TIMEOUT（可按重试策略重试），其余为 CANCELED。
+func (e *Executor) endConfirmed(ctx context.Context, run *model.TaskRun, reason bizConsts.CancelReason) {
+	status, msg := bizConsts.Canceled, "canceled: "+string(reason)
+	if reason == bizConsts.CancelReasonExecutionTimeout {
+		status, msg = bizConsts.Timeout, executionTimeoutMsg(run)
+	}
+	updated, err := e.RunSvc.EndCanceled(ctx, run.ID, status, msg)
+	if err != nil || !updated {
+		return
+	}
+	logging.Info(ctx, fmt.Sprintf("run %d cancel confirmed by target (%s)", run.ID, reason))
+	if status == bizConsts.Timeout {
+		e.retryOnFailure(ctx, run, model.RetryOutcome{Kind: model.OutcomeTimeout})
+	}
+	if run.ExecType == bizConsts.ExecTypeAsync {
+		done := *run
+		done.Status = status
+		e.ObserveAsyncResult(ctx, &done, time.Now(), false)
+	}
+}
+
+// kill 宽限期内未获确认：标记 KILLED 并取消本地执行上下文。
+func (e *Executor) kill(ctx context.Context, run *model.TaskRun, reason bizConsts.CancelReason) {
+	msg := fmt.Sprintf("cancel_not_confirmed: reason=%s grace=%s", reason, e.cfg.KillGrace)
+	updated, err := e.RunSvc.EndCanceled(ctx, run.ID, bizConsts.Killed, msg)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("mark run %d killed failed: %v", run.ID, err))
+		return
+	}
+	e.cancelLocal(run.ID, reason)
+	if !updated {
+		return
+	}
+	logging.Warn(ctx, fmt.Sprintf("run %d killed: %s", run.ID, msg))
+	if run.Status == bizConsts.CallbackPending {
+		done := *run
+		done.Status = bizConsts.Killed
+		e.ObserveAsyncResult(ctx, &done, time.Now(), false)
+	}
+}
+
+// PropagateCancels 取消本副本上已被（其他副本）请求取消的 RUNNING Run 的执行上下文。
+func (e *Executor) PropagateCancels(ctx context.Context, limit int) {
+	list, err := e.RunSvc.ListCancelRequested(ctx, []bizConsts.RunStatus{bizConsts.Running}, time.Now(), limit)
+	if err != nil {
+		logging.Error(ctx, "list cancel requested runs failed: "+err.Error())
+		return
+	}
+	for _, run := range list {
+		if e.cancelLocal(run.ID, run.CancelReason) {
+			logging.Info(ctx, fmt.Sprintf("run %d canceled on this replica (%s)", run.ID, run.CancelReason))
+		}
+	}
+}
+
+// KillUnconfirmed 将请求取消已超过宽限期仍未结束的 Run 标记为 KILLED。
+func (e *Executor) KillUnconfirmed(ctx context.Context, limit int) {
+	list, err := e.RunSvc.ListCancelRequested(ctx, []bizConsts.RunStatus{bizConsts.Running, bizConsts.CallbackPending},
+		time.Now().Add(-e.cfg.KillGrace), limit)
+	if err != nil {
+		logging.Error(ctx, "list unconfirmed cancels failed: "+err.Error())
+		return
+	}
+	for _, run := range list {
+		e.kill(ctx, run, run.CancelReason)
+	}
+}
+
+// CancelExpiredExecutions 对等待回调且已超过 execution_timeout_sec 的 Run 发起取消。
+func (e *Executor) CancelExpiredExecutions(ctx context.Context, limit int) {
+	list, err := e.RunSvc.ListExecutionExpired(ctx, time.Now(), limit)
+	if err != nil {
+		logging.Error(ctx, "list execution timeout runs failed: "+err.Error())
+		return
+	}
+	for _, run := range list {
+		logging.Warn(ctx, fmt.Sprintf("run %d exceeded execution_timeout_sec=%d; canceling", run.ID, run.ExecutionTimeoutSec))
+		if _, err := e.Cancel(ctx, run, bizConsts.CancelReasonExecutionTimeout); err != nil {
+			logging.Error(ctx, fmt.Sprintf("cancel run %d on execution timeout failed: %v", run.ID, err))
+		}
+	}
+}
+
+// endLocalCanceled 执行上下文因取消或执行超时结束时落库，返回 Run 状态。
+func (e *Executor) endLocalCanceled(ctx context.Context, run *model.TaskRun, reason bizConsts.CancelReason) bizConsts.RunStatus {
+	if reason != bizConsts.CancelReasonExecutionTimeout {
+		_ = e.RunSvc.MarkCanceled(ctx, run.ID, reason)
+		return bizConsts.Canceled
+	}
+	// 执行超时由本地上下文触发：补记原因并通知下游停止（请求已断开，下游可能仍在处理）
+	if first, _ := e.RunSvc.RequestCancel(ctx, run.ID, reason); first {
+		go func() { _, _ = e.CancelRemote(context.WithoutCancel(ctx), run, reason) }()
+	}
+	_ = e.RunSvc.MarkTimeout(ctx, run.ID, executionTimeoutMsg(run))
+	e.retryOnFailure(ctx, run, model.RetryOutcome{Kind: model.OutcomeTimeout})
+	return bizConsts.Timeout
+}
+
+func executionTimeoutMsg(run *model.TaskRun) string {
+	return fmt.Sprintf("execution_timeout: exceeded %ds", run.ExecutionTimeoutSec)
+}
+
+// CancelRemote 向目标服务发送协作取消请求 {"run_id": .., "reason": ..}；
+// 返回 2xx 且响应为 {"canceled": true} 或 {"status": "canceled"} 时视为已确认。
+func (e *Executor) CancelRemote(ctx context.Context, run *model.TaskRun, reason bizConsts.CancelReason) (bool, error) {
+	if e.HTTPCli == nil || run.TargetService == "" {
+		return false, nil
+	}
+	client, err := e.HTTPCli.Client(run.TargetService)
+	if err != nil {
+		return false, nil // 非 HTTP 目标，无协作取消接口
+	}
+	buf, err := json.Marshal(map[string]any{"run_id": run.ID, "reason": reason})
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	cancelURL := strings.TrimRight(client.BaseURL, "/") + e.cfg.CancelPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cancelURL, bytes.NewReader(buf))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	logging.Info(ctx, fmt.Sprintf("cancel-remote notified for run %d, status: %d", run.ID, resp.StatusCode))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, nil
+	}
+	return gjson.GetBytes(body, "canceled").Bool() || strings.EqualFold(gjson.GetBytes(body, "status").String(), "canceled"), nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// Run 事件流：状态、进度与日志都已持久化，订阅方（SSE）以数据库为准轮询差量；
+// 本副本内的变化通过 runEvents 即时唤醒订阅方，其他副本的变化在下一次轮询时送达。
+
+// runEvents 按 Run 唤醒订阅方；只传递“有变化”的信号，不携带数据。
+type runEvents struct {
+	mu   sync.Mutex
+	subs map[int64]map[chan struct{}]struct{}
+}
+
+func newRunEvents() *runEvents {
+	return &runEvents{subs: make(map[int64]map[chan struct{}]struct{})}
+}
+
+// subscribe 返回唤醒通道与取消函数；nil 接收者（测试中未初始化的 RunService）返回永不触发的通道。
+func (h *runEvents) subscribe(runID int64) (<-chan struct{}, func()) {
+	if h == nil {
+		return nil, func() {}
+	}
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	if h.subs[runID] == nil {
+		h.subs[runID] = make(map[chan struct{}]struct{})
+	}
+	h.subs[runID][ch] = struct{}{}
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs[runID], ch)
+		if len(h.subs[runID]) == 0 {
+			delete(h.subs, runID)
+		}
+		h.mu.Unlock()
+	}
+}
+
+func (h *runEvents) notify(runID int64) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[runID] {
+		select {
+		case ch <- struct{}{}:
+		default: // 已有未处理的唤醒
+		}
+	}
+}
+
+// RunEvent 推送给订阅方的一条事件：status / progress / log / end。
+type RunEvent struct {
+	ID   string // 仅 log 事件带 ID（日志 ID），用于断线重连时从 Last-Event-ID 续传
+	Type string
+	Data any
+}
+
+// RunWatcher 记录已推送的位置，Poll 返回自上次以来的新事件。
+type RunWatcher struct {
+	svc          *RunService
+	runID        int64
+	afterLogID   int64
+	lastStatus   bizConsts.RunStatus
+	lastProgress time.Time
+	done         bool
+}
+
+// Watch 从 afterLogID 之后的日志开始跟踪 Run；首次 Poll 返回当前状态与进度。
+func (s *RunService) Watch(runID, afterLogID int64) *RunWatcher {
+	return &RunWatcher{svc: s, runID: runID, afterLogID: afterLogID}
+}
+
+// Subscribe 本副本内 Run 有变化时唤醒。
+func (s *RunService) Subscribe(runID int64) (<-chan struct{}, func()) {
+	return s.events.subscribe(runID)
+}
+
+// watchLogBatch 单次查询的日志条数；一次 Poll 内取完积压的日志。
+const watchLogBatch = 500
+
+// Poll 读取 Run 最新状态、进度与新日志；Run 结束后追加 end 事件，此后 Done 为 true。
+// 日志在读取状态之后查询，结束前上报的日志都会在 end 之前送达。
+func (w *RunWatcher) Poll(ctx context.Context) ([]RunEvent, error) {
+	if w.done {
+		return nil, nil
+	}
+	run, err := w.svc.Get(ctx, w.runID)
+	if err != nil {
+		return nil, err
+	}
+	var events []RunEvent
+	if run.Status != w.lastStatus {
+		w.lastStatus = run.Status
+		events = append(events, RunEvent{Type: "status", Data: runStatusEvent(run)})
+	}
+	if p := ProgressOf(run); p != nil && !p.Updated.Equal(w.lastProgress) {
+		w.lastProgress = p.Updated
+		events = append(events, RunEvent{Type: "progress", Data: p})
+	}
+	for {
+		logs, err := w.svc.ListLogs(ctx, w.runID, w.afterLogID, watchLogBatch)
+		if err != nil {
+			return events, err
+		}
+		for _, l := range logs {
+			w.afterLogID = l.ID
+			events = append(events, RunEvent{ID: strconv.FormatInt(l.ID, 10), Type: "log", Data: l})
+		}
+		if len(logs) < watchLogBatch {
+			break
+		}
+	}
+	if run.Status.Finished() {
+		w.done = true
+		events = append(events, RunEvent{Type: "end", Data: map[string]any{"run_id": run.ID, "status": run.Status}})
+	}
+	return events, nil
+}
+
+// Done Run 已结束且 end 事件已返回。
+func (w *RunWatcher) Done() bool { return w.done }
+
+func runStatusEvent(run *model.TaskRun) map[string]any {
+	return map[string]any{
+		"run_id":        run.ID,
+		"task_id":       run.TaskID,
+		"status":        run.Status,
+		"attempt":       run.Attempt,
+		"start_time":    run.StartTime,
+		"end_time":      run.EndTime,
+		"response_code": run.ResponseCode,
+		"error_message": run.ErrorMessage,
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// eventsRunDao 单个 Run 的状态、进度与日志。
+type eventsRunDao struct {
+	dao.RunDao
+	run  model.TaskRun
+	logs []*model.RunLog
+}
+
+func (d *eventsRunDao) Get(_ context.Context, _ int64) (*model.TaskRun, error) {
+	cp := d.run
+	return &cp, nil
+}
+func (d *eventsRunDao) MarkSuccess(_ context.Context, _ int64, code int, _ string) error {
+	d.run.Status, d.run.ResponseCode = bizConsts.Success, &code
+	return nil
+}
+func (d *eventsRunDao) UpdateProgress(_ context.Context, _ int64, current, total int64, msg string, at time.Time) error {
+	d.run.ProgressCurrent, d.run.ProgressTotal, d.run.ProgressMessage, d.run.ProgressUpdatedAt = current, total, msg, &at
+	return nil
+}
+func (d *eventsRunDao) AppendLogs(_ context.Context, logs []*model.RunLog) error {
+	for _, l := range logs {
+		l.ID = int64(len(d.logs) + 1)
+		d.logs = append(d.logs, l)
+	}
+	return nil
+}
+func (d *eventsRunDao) ListLogs(_ context.Context, _ int64, afterID int64, limit int) ([]*model.RunLog, error) {
+	var out []*model.RunLog
+	for _, l := range d.logs {
+		if l.ID > afterID && len(out) < limit {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func eventTypes(events []RunEvent) []string {
+	out := make([]string, len(events))
+	for i, ev := range events {
+		out[i] = ev.Type
+	}
+	return out
+}
+
+func TestRunWatcher(t *testing.T) {
+	ctx := context.Background()
+	d := &eventsRunDao{run: model.TaskRun{ID: 3, Status: bizConsts.Running}}
+	rs := NewRunService()
+	rs.RunDao = d
+	pm := NewRunProgressManager(time.Minute)
+	pm.RunSvc = rs
+
+	wake, unsubscribe := rs.Subscribe(3)
+	defer unsubscribe()
+	w := rs.Watch(3, 0)
+	if evs, _ := w.Poll(ctx); len(evs) != 1 || evs[0].Type != "status" {
+		t.Fatalf("initial poll: %v", eventTypes(evs))
+	}
+	if evs, _ := w.Poll(ctx); len(evs) != 0 {
+		t.Fatalf("no change expected: %v", eventTypes(evs))
+	}
+
+	_ = rs.AppendLogs(ctx, 3, []*model.RunLog{{TaskRunID: 3, Line: "a"}, {TaskRunID: 3, Line: "b"}})
+	select {
+	case <-wake:
+	default:
+		t.Fatal("subscriber not woken by log append")
+	}
+	// 快速结束的 Run：最终进度、日志与状态在同一次轮询中送达，end 最后
+	if _, err := pm.Set(ctx, 3, 150, 100, "done"); err != nil {
+		t.Fatal(err)
+	}
+	_ = rs.MarkSuccess(ctx, 3, 200, "")
+	evs, _ := w.Poll(ctx)
+	if got := eventTypes(evs); len(got) != 5 || got[0] != "status" || got[1] != "progress" || got[2] != "log" || got[4] != "end" {
+		t.Fatalf("unexpected events %v", got)
+	}
+	if p := evs[1].Data.(*RunProgress); p.Current != 100 || p.Percent != 100 {
+		t.Fatalf("progress not clamped: %+v", p)
+	}
+	if evs[3].ID != "2" || !w.Done() {
+		t.Fatalf("last log id %q, done %v", evs[3].ID, w.Done())
+	}
+
+	// 断线重连：从 Last-Event-ID 之后继续
+	evs, _ = rs.Watch(3, 1).Poll(ctx)
+	if got := eventTypes(evs); len(got) != 4 || got[2] != "log" || evs[2].ID != "2" {
+		t.Fatalf("resume events %v", got)
+	}
+}
@@ -2,19 +2,19 @@ package service
 
 import (
 	"context"
-	"sort"
-	"sync"
 	"time"
 
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
 )
 
-// RunProgressManager stores ephemeral progress info per run.
+// RunProgressManager 读写 Run 的进度快照。进度保存在 task_runs 上，
+// 重启后仍在，任意副本上报、任意副本可读。
 type RunProgressManager struct {
 	*core.BaseComponent
-	mu   sync.RWMutex
-	data map[int64]*RunProgress
+	RunSvc *RunService   `infra:"dep:run_service"`
+	grace  time.Duration // List 中保留已结束 Run 进度的时长
 }
 
 type RunProgress struct {
@@ -26,14 +26,16 @@ type RunProgress struct {
 	Updated time.Time `json:"updated_at"`
 }
 
-func NewRunProgressManager() *RunProgressManager {
-	return &RunProgressManager{BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_RUN_PROGRESS), data: make(map[int64]*RunProgress)}
+// NewRunProgressManager grace 为已结束 Run 的进度在 List 中保留的时长（<=0 时结束即不再列出）。
+func NewRunProgressManager(grace time.Duration) *RunProgressManager {
+	return &RunProgressManager{BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_RUN_PROGRESS), grace: grace}
 }
 
 func (rpm *RunProgressManager) Start(ctx context.Context) error { return rpm.BaseComponent.Start(ctx) }
 func (rpm *RunProgressManager) Stop(ctx context.Context) error  { return rpm.BaseComponent.Stop(ctx) }
 
-func (rpm *RunProgressManager) Set(runID int64, current, total int64, msg string) {
+// Set 规整并持久化一次进度上报。
+func (rpm *RunProgressManager) Set(ctx context.Context, runID int64, current, total int64, msg string) (*RunProgress, error) {
 	if total < 0 {
 		total = 0
 	}
@@ -43,36 +45,49 @@ func (rpm *RunProgressManager) Set(runID int64, current, total int64, msg string
 	if total > 0 && current > total {
 		current = total
 	}
-	percent := 0
-	if total > 0 {
-		percent = int((current * 100) / total)
+	p := &RunProgress{RunID: runID, Current: current, Total: total, Percent: percent(current, total), Message: msg, Updated: time.Now()}
+	if err := rpm.RunSvc.UpdateProgress(ctx, runID, current, total, msg, p.Updated); err != nil {
+		return nil, err
 	}
-	rpm.mu.Lock()
-	rpm.data[runID] = &RunProgress{RunID: runID, Current: current, Total: total, Percent: percent, Message: msg, Updated: time.Now()}
-	rpm.mu.Unlock()
+	return p, nil
 }
 
-func (rpm *RunProgressManager) Get(runID int64) *RunProgress {
-	rpm.mu.RLock()
-	defer rpm.mu.RUnlock()
-	return rpm.data[runID]
+// Get 返回 Run 最近一次上报的进度；从未上报时返回 nil。
+func (rpm *RunProgressManager) Get(ctx context.Context, runID int64) (*RunProgress, error) {
+	run, err := rpm.RunSvc.Get(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	return ProgressOf(run), nil
+}
+
+// List 返回未结束以及 grace 内结束的 Run 的进度，按更新时间倒序。
+func (rpm *RunProgressManager) List(ctx context.Context, limit int) ([]*RunProgress, error) {
+	runs, err := rpm.RunSvc.ListProgress(ctx, time.Now().Add(-rpm.grace), limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*RunProgress, 0, len(runs))
+	for _, run := range runs {
+		if p := ProgressOf(run); p != nil {
+			out = append(out, p)
+		}
+	}
+	return out, nil
 }
 
-// Clear removes progress for a finished run (should be called after terminal state)
-func (rpm *RunProgressManager) Clear(runID int64) {
-	rpm.mu.Lock()
-	delete(rpm.data, runID)
-	rpm.mu.Unlock()
+// ProgressOf 从 Run 的进度快照构建 RunProgress；从未上报时返回 nil。
+func ProgressOf(run *model.TaskRun) *RunProgress {
+	if run.ProgressUpdatedAt == nil {
+		return nil
+	}
+	return &RunProgress{RunID: run.ID, Current: run.ProgressCurrent, Total: run.ProgressTotal,
+		Percent: percent(run.ProgressCurrent, run.ProgressTotal), Message: run.ProgressMessage, Updated: *run.ProgressUpdatedAt}
 }
 
-// List returns all current progress entries sorted by most recently updated.
-func (rpm *RunProgressManager) List() []*RunProgress {
-	rpm.mu.RLock()
-	defer rpm.mu.RUnlock()
-	out := make([]*RunProgress, 0, len(rpm.data))
-	for _, p := range rpm.data {
-		out = append(out, p)
+func percent(current, total int64) int {
+	if total <= 0 {
+		return 0
 	}
-	sort.Slice(out, func(i, j int) bool { return out[i].Updated.After(out[j].Updated) })
-	return out
+	return int((current * 100) / total)
 }
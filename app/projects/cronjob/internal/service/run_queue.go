@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// ErrQueueFull QUEUE 策略下队列已满且溢出策略为 DROP_NEW，本次触发已记录为 CONCURRENT_SKIP。
+var ErrQueueFull = errors.New("run queue is full")
+
+// 持久化运行队列（QUEUE 并发策略）：
+//   - 提交：Run 先以 QUEUED 落库，再尝试提升；即使有空闲槽位也经过队列，保证 FIFO
+//   - 提升：按任务加锁计算槽位（SCHEDULED+RUNNING 占用 max_concurrency），FIFO 提升为 SCHEDULED 后入内存队列执行
+//   - 触发提升的时机：提交后、每个 Run 执行结束后、扫描器每个 tick、执行器启动时
+//   - Run 结束后的提升交给 dispatchLoop：worker 自己向内存队列阻塞入队时，队列满会让所有 worker 互相等待
+//
+// 队列状态完全在数据库中，部署重启不会丢失排队的 Run。
+
+// UsesQueue 任务是否按 QUEUE 策略排队。
+func UsesQueue(task *model.Task) bool {
+	return task.MaxConcurrency > 0 && task.ConcurrencyPolicy == bizConsts.ConcurrencyQueue
+}
+
+// SubmitQueued 将 Run 以 QUEUED 状态加入任务队列并尝试提升。
+// 队列达到 queue_max_depth 时按 queue_overflow 处理：DROP_NEW 记录 CONCURRENT_SKIP 并返回 ErrQueueFull；
+// DROP_OLDEST 丢弃队首后入队。
+func (e *Executor) SubmitQueued(ctx context.Context, task *model.Task, run *model.TaskRun) error {
+	depth := task.QueueMaxDepth
+	if depth <= 0 {
+		depth = bizConsts.DEFAULT_QUEUE_MAX_DEPTH
+	}
+	queued, err := e.RunSvc.CountByStatus(ctx, task.ID, []bizConsts.RunStatus{bizConsts.Queued})
+	if err != nil {
+		return err
+	}
+	if queued >= int64(depth) {
+		if task.QueueOverflow == bizConsts.QueueOverflowDropOldest {
+			dropped, err := e.RunSvc.DropOldestQueued(ctx, task.ID)
+			if err != nil {
+				return err
+			}
+			logging.Warn(ctx, fmt.Sprintf("task %d queue full (%d); dropped oldest queued run %d", task.ID, depth, dropped))
+		} else {
+			if err := e.RunSvc.CreateSkipped(ctx, run, bizConsts.ConcurrentSkip); err != nil {
+				return err
+			}
+			logging.Warn(ctx, fmt.Sprintf("task %d queue full (%d); run %d skipped", task.ID, depth, run.ID))
+			return ErrQueueFull
+		}
+	}
+	run.Status = bizConsts.Queued
+	if err := e.RunSvc.CreateScheduled(ctx, run); err != nil {
+		return err
+	}
+	e.PromoteQueued(ctx, task)
+	return nil
+}
+
+// PromoteQueued 在并发槽位内按 FIFO 提升任务的 QUEUED Run 并入队执行。
+func (e *Executor) PromoteQueued(ctx context.Context, task *model.Task) {
+	runs, err := e.RunSvc.PromoteQueued(ctx, task.ID, task.MaxConcurrency)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("promote queued runs for task %d failed: %v", task.ID, err))
+		return
+	}
+	for _, run := range runs {
+		run.CallbackTimeoutSec = task.CallbackTimeoutSec
+		e.Enqueue(run)
+	}
+}
+
+// PromoteAllQueued 对所有存在排队 Run 的启用任务执行提升（扫描器与启动时兜底）。
+func (e *Executor) PromoteAllQueued(ctx context.Context) {
+	ids, err := e.RunSvc.ListQueuedTaskIDs(ctx)
+	if err != nil {
+		logging.Error(ctx, fmt.Sprintf("list queued tasks failed: %v", err))
+		return
+	}
+	for _, id := range ids {
+		task, err := e.TaskSvc.Get(ctx, id)
+		if err != nil || task == nil || task.Status != bizConsts.ENABLED {
+			continue // 任务停用期间保持排队
+		}
+		e.PromoteQueued(ctx, task)
+	}
+}
+
+// restorePageSize 恢复遗留 SCHEDULED Run 时每页读取的条数。
+const restorePageSize = 500
+
+// RestoreQueue 请求 dispatchLoop 重新入队遗留的 SCHEDULED Run 并提升排队中的 Run；不阻塞。
+// 执行器启动与调度器接管 leader 时调用；多次请求在同一轮中合并。
+func (e *Executor) RestoreQueue() {
+	e.mu.Lock()
+	e.restore = true
+	e.mu.Unlock()
+	e.kick()
+}
+
+// restoreQueue 分页读取全部 SCHEDULED Run，按创建顺序入队，再提升排队中的 Run。
+// 已在本进程内存队列中的 Run 由 Enqueue 去重；遗留 Run 可能同时被其他副本入队，TransitionToRunning 的 CAS 保证只执行一次。
+func (e *Executor) restoreQueue(ctx context.Context) {
+	var runs []*model.TaskRun
+	for offset := 0; ctx.Err() == nil; offset += restorePageSize {
+		page, err := e.RunSvc.ListActiveFiltered(ctx, []bizConsts.RunStatus{bizConsts.Scheduled}, nil, nil, restorePageSize, offset, "")
+		if err != nil {
+			logging.Error(ctx, fmt.Sprintf("restore scheduled runs failed: %v", err))
+			return
+		}
+		runs = append(runs, page...)
+		if len(page) < restorePageSize {
+			break
+		}
+	}
+	for i := len(runs) - 1; i >= 0 && ctx.Err() == nil; i-- { // 列表按 id 倒序，按创建顺序入队
+		run := runs[i]
+		if task, err := e.TaskSvc.Get(ctx, run.TaskID); err == nil && task != nil {
+			run.CallbackTimeoutSec = task.CallbackTimeoutSec
+		}
+		e.Enqueue(run)
+	}
+	if len(runs) > 0 {
+		logging.Info(ctx, fmt.Sprintf("executor restored %d scheduled runs", len(runs)))
+	}
+	e.PromoteAllQueued(ctx)
+}
+
+// afterRun Run 执行结束后释放槽位，请求 dispatchLoop 提升同任务的下一个排队 Run、
+// 重试同空间暂缓的 Run；在 worker 上调用，不阻塞。
+func (e *Executor) afterRun(ctx context.Context, run *model.TaskRun) {
+	e.mu.Lock()
+	waiting := len(e.deferred[run.Namespace]) > 0
+	e.mu.Unlock()
+	if waiting {
+		e.kick()
+	}
+	task, err := e.TaskSvc.Get(ctx, run.TaskID)
+	if err != nil || task == nil || !UsesQueue(task) || task.Status != bizConsts.ENABLED {
+		return
+	}
+	e.mu.Lock()
+	e.promote[task.ID] = struct{}{}
+	e.mu.Unlock()
+	e.kick()
+}
+
+// kick 唤醒 dispatchLoop；已有未处理的唤醒时直接返回。
+func (e *Executor) kick() {
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchLoop 在独立协程中处理需要向内存队列入队的后台工作（遗留 Run 恢复、Run 结束后的队列提升、
+// 空间配额暂缓的 Run 重新准入），队列满时在这里等待 worker 消费，而不是占住 worker 或调度器 tick。
+func (e *Executor) dispatchLoop(ctx context.Context) {
+	ticker := time.NewTicker(namespaceQuotaRetry)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.wake:
+		case <-ticker.C: // 其他副本释放的空间配额不会唤醒本副本，定期重试暂缓的队首
+		}
+		e.dispatch(ctx)
+	}
+}
+
+// dispatch 先处理恢复请求，再按任务 id 顺序提升待处理任务的排队 Run。
+func (e *Executor) dispatch(ctx context.Context) {
+	e.mu.Lock()
+	restore := e.restore
+	e.restore = false
+	ids := make([]int64, 0, len(e.promote))
+	for id := range e.promote {
+		ids = append(ids, id)
+	}
+	e.promote = make(map[int64]struct{})
+	e.mu.Unlock()
+	if restore {
+		e.restoreQueue(ctx)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		task, err := e.TaskSvc.Get(ctx, id)
+		if err != nil || task == nil || task.Status != bizConsts.ENABLED {
+			continue
+		}
+		e.PromoteQueued(ctx, task)
+	}
+	for _, run := range e.deferredHeads() {
+		e.Enqueue(run)
+	}
+}
+
+// namespaceQuotaRetry 空间并发配额已满时重试暂缓队首的间隔。
+const namespaceQuotaRetry = time.Second
+
+// deferRun 空间并发配额已满：Run 保持 SCHEDULED，按 id 顺序放入该空间的暂缓列表。
+// 每个空间同一时刻只有队首一个 Run 重新尝试准入（本副本有 Run 结束或每 namespaceQuotaRetry 一次），
+// 不会让整批积压反复经过内存队列与空间锁；进程退出后由启动时的恢复重新入队。
+func (e *Executor) deferRun(ctx context.Context, run *model.TaskRun) {
+	if !run.QuotaDeferred {
+		run.QuotaDeferred = true
+		logging.Info(ctx, fmt.Sprintf("run %d deferred: namespace %s concurrent run quota reached", run.ID, run.Namespace))
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	list := e.deferred[run.Namespace]
+	i := sort.Search(len(list), func(i int) bool { return list[i].ID >= run.ID })
+	if i < len(list) && list[i].ID == run.ID {
+		return
+	}
+	list = append(list, nil)
+	copy(list[i+1:], list[i:])
+	list[i] = run
+	e.deferred[run.Namespace] = list
+}
+
+// takeDeferTurn 判断 Run 能否尝试空间准入：behind 表示同空间已有更早暂缓的 Run，本 Run 已排到其后；
+// head 表示本 Run 是 dispatch 放出的暂缓队首。
+func (e *Executor) takeDeferTurn(run *model.TaskRun) (behind, head bool) {
+	e.mu.Lock()
+	if e.deferHead[run.Namespace] == run.ID && run.ID != 0 {
+		delete(e.deferHead, run.Namespace)
+		e.mu.Unlock()
+		return false, true
+	}
+	waiting := e.deferHead[run.Namespace] != 0 || len(e.deferred[run.Namespace]) > 0
+	e.mu.Unlock()
+	if waiting {
+		e.deferRun(context.Background(), run)
+	}
+	return waiting, false
+}
+
+// deferredHeads 为没有在途队首的空间取出暂缓列表的队首，按空间名排序。
+func (e *Executor) deferredHeads() []*model.TaskRun {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var heads []*model.TaskRun
+	for ns, list := range e.deferred {
+		if e.deferHead[ns] != 0 || len(list) == 0 {
+			continue
+		}
+		heads = append(heads, list[0])
+		e.deferHead[ns] = list[0].ID
+		if len(list) == 1 {
+			delete(e.deferred, ns)
+		} else {
+			e.deferred[ns] = list[1:]
+		}
+	}
+	sort.Slice(heads, func(i, j int) bool { return heads[i].Namespace < heads[j].Namespace })
+	return heads
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+func (r *stubRunDao) CountByStatus(_ context.Context, taskID int64, statuses []bizConsts.RunStatus) (int64, error) {
+	var n int64
+	for _, ru := range r.runs {
+		for _, st := range statuses {
+			if ru.TaskID == taskID && ru.Status == st {
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+func (r *stubRunDao) PromoteQueued(ctx context.Context, taskID int64, maxActive int) ([]*model.TaskRun, error) {
+	active, _ := r.CountByStatus(ctx, taskID, []bizConsts.RunStatus{bizConsts.Scheduled, bizConsts.Running})
+	queued := r.queued(taskID)
+	slots := maxActive - int(active)
+	if slots < 0 {
+		slots = 0
+	}
+	if slots < len(queued) {
+		queued = queued[:slots]
+	}
+	for _, ru := range queued {
+		ru.Status = bizConsts.Scheduled
+	}
+	return queued, nil
+}
+func (r *stubRunDao) DropOldestQueued(_ context.Context, taskID int64) (int64, error) {
+	queued := r.queued(taskID)
+	if len(queued) == 0 {
+		return 0, nil
+	}
+	queued[0].Status = bizConsts.ConcurrentSkip
+	return queued[0].ID, nil
+}
+func (r *stubRunDao) ListQueuedTaskIDs(_ context.Context) ([]int64, error) {
+	seen := map[int64]bool{}
+	var ids []int64
+	for _, ru := range r.runs {
+		if ru.Status == bizConsts.Queued && !seen[ru.TaskID] {
+			seen[ru.TaskID] = true
+			ids = append(ids, ru.TaskID)
+		}
+	}
+	return ids, nil
+}
+
+// queued 按 (scheduled_time, id) FIFO 返回任务的排队 Run。
+func (r *stubRunDao) queued(taskID int64) []*model.TaskRun {
+	var out []*model.TaskRun
+	for _, ru := range r.runs {
+		if ru.TaskID == taskID && ru.Status == bizConsts.Queued {
+			out = append(out, ru)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].ScheduledTime.Equal(out[j].ScheduledTime) {
+			return out[i].ScheduledTime.Before(out[j].ScheduledTime)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+func TestRunQueueFIFOAndOverflow(t *testing.T) {
+	ctx := context.Background()
+	task := &model.Task{ID: 9, TargetService: "artemis", Status: bizConsts.ENABLED, MaxConcurrency: 1,
+		ConcurrencyPolicy: bizConsts.ConcurrencyQueue, QueueMaxDepth: 2, QueueOverflow: bizConsts.QueueOverflowDropNew}
+	ts := NewTaskService()
+	ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{9: task}}
+	if err := ts.Start(ctx); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	runDao := &stubRunDao{}
+	exec := NewExecutor(config.ExecutorConfig{})
+	exec.RunSvc, exec.TaskSvc = &RunService{RunDao: runDao}, ts
+
+	base := time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+	submit := func(i int) (*model.TaskRun, error) {
+		run := ts.CreateTaskRun(task, base.Add(time.Duration(i)*time.Minute), 1)
+		return run, exec.SubmitQueued(ctx, task, run)
+	}
+	r1, _ := submit(1)
+	r2, _ := submit(2)
+	r3, _ := submit(3)
+	if r1.Status != bizConsts.Scheduled || r2.Status != bizConsts.Queued || r3.Status != bizConsts.Queued {
+		t.Fatalf("unexpected statuses %s %s %s", r1.Status, r2.Status, r3.Status)
+	}
+	if got := <-exec.ch; got != r1 || len(exec.ch) != 0 {
+		t.Fatal("only the first run should be enqueued")
+	}
+
+	// 队列已满：DROP_NEW 记录 CONCURRENT_SKIP
+	r4, err := submit(4)
+	if !errors.Is(err, ErrQueueFull) || r4.Status != bizConsts.ConcurrentSkip {
+		t.Fatalf("expected queue full skip, err=%v status=%s", err, r4.Status)
+	}
+
+	// 槽位释放后按 FIFO 提升
+	r1.Status = bizConsts.Success
+	exec.afterRun(ctx, r1)
+	exec.dispatch(ctx)
+	if got := <-exec.ch; got != r2 || r3.Status != bizConsts.Queued {
+		t.Fatalf("expected run %d promoted first", r2.ID)
+	}
+
+	// DROP_OLDEST：丢弃队首，新 Run 入队
+	task.QueueOverflow = bizConsts.QueueOverflowDropOldest
+	task.QueueMaxDepth = 1
+	r5, err := submit(5)
+	if err != nil || r3.Status != bizConsts.ConcurrentSkip || r5.Status != bizConsts.Queued {
+		t.Fatalf("expected oldest dropped, err=%v r3=%s r5=%s", err, r3.Status, r5.Status)
+	}
+
+	// 扫描兜底：回调完成等执行器外释放的槽位
+	r2.Status = bizConsts.Success
+	exec.PromoteAllQueued(ctx)
+	if got := <-exec.ch; got != r5 {
+		t.Fatal("sweep should promote remaining queued run")
+	}
+}
+
+// Test a worker finishing a QUEUE run never blocks on a full in-memory queue
+func TestRunQueueAfterRunDoesNotBlockWorker(t *testing.T) {
+	ctx := context.Background()
+	task := &model.Task{ID: 10, TargetService: "artemis", Status: bizConsts.ENABLED, MaxConcurrency: 1,
+		ConcurrencyPolicy: bizConsts.ConcurrencyQueue, QueueMaxDepth: 5}
+	ts := NewTaskService()
+	ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{10: task}}
+	if err := ts.Start(ctx); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	runDao := &stubRunDao{}
+	exec := NewExecutor(config.ExecutorConfig{})
+	exec.RunSvc, exec.TaskSvc = &RunService{RunDao: runDao}, ts
+
+	done := &model.TaskRun{TaskID: 10, Status: bizConsts.Success}
+	next := ts.CreateTaskRun(task, time.Now(), 1)
+	next.Status = bizConsts.Queued
+	_ = runDao.CreateScheduled(ctx, next)
+	for len(exec.ch) < cap(exec.ch) { // 启动时恢复的遗留 Run 已占满内存队列
+		exec.ch <- &model.TaskRun{}
+	}
+
+	returned := make(chan struct{})
+	go func() {
+		exec.afterRun(ctx, done)
+		close(returned)
+	}()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("afterRun blocked on a full queue")
+	}
+	if next.Status != bizConsts.Queued {
+		t.Fatalf("promotion must be left to dispatchLoop, status=%s", next.Status)
+	}
+	<-exec.ch // 一个 worker 腾出位置后，dispatch 完成提升
+	exec.dispatch(ctx)
+	if next.Status != bizConsts.Scheduled {
+		t.Fatalf("expected run promoted by dispatch, status=%s", next.Status)
+	}
+}
+
+// Test restoreQueue pages through every leftover SCHEDULED run in creation order
+func TestRestoreQueuePagesAllScheduled(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTaskService()
+	ts.TaskDao = &stubDao{tasks: map[int64]*model.Task{}}
+	if err := ts.Start(ctx); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	runDao := &stubRunDao{}
+	for i := 0; i < restorePageSize+100; i++ {
+		_ = runDao.CreateScheduled(ctx, &model.TaskRun{TaskID: 1, Status: bizConsts.Scheduled})
+	}
+	exec := NewExecutor(config.ExecutorConfig{})
+	exec.RunSvc, exec.TaskSvc = &RunService{RunDao: runDao}, ts
+
+	exec.RestoreQueue()
+	exec.dispatch(ctx)
+	got := drain(exec.ch)
+	if len(got) != restorePageSize+100 {
+		t.Fatalf("expected %d runs restored, got %d", restorePageSize+100, len(got))
+	}
+	for i, r := range got {
+		if r.ID != int64(i+1) {
+			t.Fatalf("run %d restored at position %d", r.ID, i)
+		}
+	}
+}
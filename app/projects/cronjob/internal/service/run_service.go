@@ -11,13 +11,15 @@ import (
 )
 
 // RunService: thin layer delegating to RunDao (no caching) for consistency with TaskService.
+// 状态、进度与日志的写入会唤醒本副本内该 Run 的事件订阅方（见 run_events.go）。
 type RunService struct {
 	*core.BaseComponent
 	RunDao dao.RunDao `infra:"dep:run_dao"`
+	events *runEvents
 }
 
 func NewRunService() *RunService {
-	return &RunService{BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_RUN)}
+	return &RunService{BaseComponent: core.NewBaseComponent(bizConsts.COMP_SVC_RUN), events: newRunEvents()}
 }
 
 func (s *RunService) Start(ctx context.Context) error { return s.BaseComponent.Start(ctx) }
@@ -27,21 +29,44 @@ func (s *RunService) CreateScheduled(ctx context.Context, run *model.TaskRun) er
 	return s.RunDao.CreateScheduled(ctx, run)
 }
 func (s *RunService) TransitionToRunning(ctx context.Context, runID int64) (bool, error) {
+	defer s.events.notify(runID)
 	return s.RunDao.TransitionToRunning(ctx, runID)
 }
+func (s *RunService) TransitionToRunningWithin(ctx context.Context, runID int64, namespace string, maxActive int) (bool, error) {
+	defer s.events.notify(runID)
+	return s.RunDao.TransitionToRunningWithin(ctx, runID, namespace, maxActive)
+}
 func (s *RunService) MarkSuccess(ctx context.Context, runID int64, code int, body string) error {
+	defer s.events.notify(runID)
 	return s.RunDao.MarkSuccess(ctx, runID, code, body)
 }
 func (s *RunService) MarkFailed(ctx context.Context, runID int64, errMsg string) error {
+	defer s.events.notify(runID)
 	return s.RunDao.MarkFailed(ctx, runID, errMsg)
 }
-func (s *RunService) MarkCanceled(ctx context.Context, runID int64) error {
-	return s.RunDao.MarkCanceled(ctx, runID)
+func (s *RunService) MarkCanceled(ctx context.Context, runID int64, reason bizConsts.CancelReason) error {
+	defer s.events.notify(runID)
+	return s.RunDao.MarkCanceled(ctx, runID, reason)
+}
+func (s *RunService) RequestCancel(ctx context.Context, runID int64, reason bizConsts.CancelReason) (bool, error) {
+	return s.RunDao.RequestCancel(ctx, runID, reason)
+}
+func (s *RunService) EndCanceled(ctx context.Context, runID int64, status bizConsts.RunStatus, errMsg string) (bool, error) {
+	defer s.events.notify(runID)
+	return s.RunDao.EndCanceled(ctx, runID, status, errMsg)
+}
+func (s *RunService) ListCancelRequested(ctx context.Context, statuses []bizConsts.RunStatus, before time.Time, limit int) ([]*model.TaskRun, error) {
+	return s.RunDao.ListCancelRequested(ctx, statuses, before, limit)
+}
+func (s *RunService) ListExecutionExpired(ctx context.Context, now time.Time, limit int) ([]*model.TaskRun, error) {
+	return s.RunDao.ListExecutionExpired(ctx, now, limit)
 }
 func (s *RunService) MarkSkipped(ctx context.Context, runID int64, skipType bizConsts.RunStatus) error {
+	defer s.events.notify(runID)
 	return s.RunDao.MarkSkipped(ctx, runID, skipType)
 }
 func (s *RunService) MarkTimeout(ctx context.Context, runID int64, errMsg string) error {
+	defer s.events.notify(runID)
 	return s.RunDao.MarkTimeout(ctx, runID, errMsg)
 }
 func (s *RunService) Get(ctx context.Context, id int64) (*model.TaskRun, error) {
@@ -51,21 +76,25 @@ func (s *RunService) ListByTask(ctx context.Context, taskID int64, limit int) ([
 	return s.RunDao.ListByTask(ctx, taskID, limit)
 }
 func (s *RunService) MarkCallbackPending(ctx context.Context, runID int64) error {
+	defer s.events.notify(runID)
 	return s.RunDao.MarkCallbackPending(ctx, runID)
 }
 func (s *RunService) MarkFailedTimeout(ctx context.Context, runID int64, errMsg string) error {
+	defer s.events.notify(runID)
 	return s.RunDao.MarkFailedTimeout(ctx, runID, errMsg)
 }
 func (s *RunService) ListActive(ctx context.Context, limit int) ([]*model.TaskRun, error) {
 	return s.RunDao.ListActive(ctx, limit)
 }
 func (s *RunService) MarkCallbackFailed(ctx context.Context, runID int64, errMsg string) error {
+	defer s.events.notify(runID)
 	return s.RunDao.MarkCallbackFailed(ctx, runID, errMsg)
 }
 func (s *RunService) ListCallbackPendingExpired(ctx context.Context, limit int) ([]*model.TaskRun, error) {
 	return s.RunDao.ListCallbackPendingExpired(ctx, limit)
 }
 func (s *RunService) MarkCallbackPendingWithDeadline(ctx context.Context, runID int64, deadline time.Time) error {
+	defer s.events.notify(runID)
 	return s.RunDao.MarkCallbackPendingWithDeadline(ctx, runID, deadline)
 }
 func (s *RunService) ListByTaskFiltered(ctx context.Context, taskID int64, statuses []bizConsts.RunStatus, from, to *time.Time, limit, offset int, timeField string) ([]*model.TaskRun, error) {
@@ -89,3 +118,78 @@ func (s *RunService) UpdateRequestSnapshot(ctx context.Context, runID int64, hea
 func (s *RunService) UpdateResponseSnapshot(ctx context.Context, runID int64, code *int, body string, errMsg string) error {
 	return s.RunDao.UpdateResponseSnapshot(ctx, runID, code, body, errMsg)
 }
+func (s *RunService) UpdateStderr(ctx context.Context, runID int64, stderr string) error {
+	return s.RunDao.UpdateStderr(ctx, runID, stderr)
+}
+func (s *RunService) CreateRetry(ctx context.Context, run *model.TaskRun) error {
+	return s.RunDao.CreateRetry(ctx, run)
+}
+func (s *RunService) SetNextRetryTime(ctx context.Context, runID int64, at *time.Time) error {
+	return s.RunDao.SetNextRetryTime(ctx, runID, at)
+}
+func (s *RunService) ListRetryDue(ctx context.Context, now time.Time, limit int) ([]*model.TaskRun, error) {
+	return s.RunDao.ListRetryDue(ctx, now, limit)
+}
+func (s *RunService) CreateSkipped(ctx context.Context, run *model.TaskRun, skipType bizConsts.RunStatus) error {
+	return s.RunDao.CreateSkipped(ctx, run, skipType)
+}
+func (s *RunService) CountByStatus(ctx context.Context, taskID int64, statuses []bizConsts.RunStatus) (int64, error) {
+	return s.RunDao.CountByStatus(ctx, taskID, statuses)
+}
+func (s *RunService) PromoteQueued(ctx context.Context, taskID int64, maxActive int) ([]*model.TaskRun, error) {
+	runs, err := s.RunDao.PromoteQueued(ctx, taskID, maxActive)
+	for _, run := range runs {
+		s.events.notify(run.ID)
+	}
+	return runs, err
+}
+func (s *RunService) DropOldestQueued(ctx context.Context, taskID int64) (int64, error) {
+	return s.RunDao.DropOldestQueued(ctx, taskID)
+}
+func (s *RunService) ListQueuedTaskIDs(ctx context.Context) ([]int64, error) {
+	return s.RunDao.ListQueuedTaskIDs(ctx)
+}
+func (s *RunService) ListDagPending(ctx context.Context, limit int) ([]*model.TaskRun, error) {
+	return s.RunDao.ListDagPending(ctx, limit)
+}
+func (s *RunService) MarkDagResolved(ctx context.Context, ids []int64) error {
+	return s.RunDao.MarkDagResolved(ctx, ids)
+}
+func (s *RunService) LatestByLogicalDate(ctx context.Context, taskIDs []int64, date time.Time) (map[int64]*model.TaskRun, error) {
+	return s.RunDao.LatestByLogicalDate(ctx, taskIDs, date)
+}
+func (s *RunService) MaxDagGeneration(ctx context.Context, date time.Time) (int, error) {
+	return s.RunDao.MaxDagGeneration(ctx, date)
+}
+func (s *RunService) AddLineage(ctx context.Context, runID int64, upstreamRunIDs []int64) error {
+	return s.RunDao.AddLineage(ctx, runID, upstreamRunIDs)
+}
+func (s *RunService) ListLineage(ctx context.Context, runID int64) ([]*model.TaskRun, []*model.TaskRun, error) {
+	return s.RunDao.ListLineage(ctx, runID)
+}
+func (s *RunService) ListBackfillActive(ctx context.Context, backfillID int64) ([]*model.TaskRun, error) {
+	return s.RunDao.ListBackfillActive(ctx, backfillID)
+}
+func (s *RunService) ListByBackfill(ctx context.Context, backfillID int64, limit, offset int) ([]*model.TaskRun, error) {
+	return s.RunDao.ListByBackfill(ctx, backfillID, limit, offset)
+}
+func (s *RunService) CountStatusByBackfill(ctx context.Context, backfillID int64) (map[bizConsts.RunStatus]int64, error) {
+	return s.RunDao.CountStatusByBackfill(ctx, backfillID)
+}
+func (s *RunService) ListSucceededTimes(ctx context.Context, taskID int64, from, to time.Time) ([]time.Time, error) {
+	return s.RunDao.ListSucceededTimes(ctx, taskID, from, to)
+}
+func (s *RunService) UpdateProgress(ctx context.Context, runID int64, current, total int64, msg string, at time.Time) error {
+	defer s.events.notify(runID)
+	return s.RunDao.UpdateProgress(ctx, runID, current, total, msg, at)
+}
+func (s *RunService) ListProgress(ctx context.Context, endedAfter time.Time, limit int) ([]*model.TaskRun, error) {
+	return s.RunDao.ListProgress(ctx, endedAfter, limit)
+}
+func (s *RunService) AppendLogs(ctx context.Context, runID int64, logs []*model.RunLog) error {
+	defer s.events.notify(runID)
+	return s.RunDao.AppendLogs(ctx, logs)
+}
+func (s *RunService) ListLogs(ctx context.Context, runID, afterID int64, limit int) ([]*model.RunLog, error) {
+	return s.RunDao.ListLogs(ctx, runID, afterID, limit)
+}
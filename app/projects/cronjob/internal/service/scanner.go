@@ -9,19 +9,25 @@ import (
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/config"
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
 )
 
 // RunScanner: unified periodic scanner
 // Responsibilities:
 // 1. Callback timeout: mark CALLBACK_PENDING runs whose callback_deadline passed as FAILED_TIMEOUT.
 // 2. Stuck sync runs: mark RUNNING SYNC runs whose start_time exceeds configured stuck timeout & updated_at also old.
-// 3. Progress cleanup: clear in-memory progress for terminal runs after grace period.
+// 3. Retries: dispatch failed runs whose next_retry_time has passed as new attempts (see retry.go);
+//    held while the scheduler is paused and dispatched after resume.
+// 4. Queues: promote queued runs whose slots were freed outside the executor.
+// Progress is persisted on task_runs (see run_progress.go) and needs no cleanup.
 // Avoid heavy DB pressure by batching operations.
 
 type RunScanner struct {
 	*core.BaseComponent
-	RunSvc     *RunService         `infra:"dep:run_service"`
-	Progress   *RunProgressManager `infra:"dep:run_progress_mgr"`
+	RunSvc     *RunService            `infra:"dep:run_service"`
+	TaskSvc    *TaskService           `infra:"dep:task_service"`
+	Exec       *Executor              `infra:"dep:executor"`
+	Pause      *SchedulerPauseService `infra:"dep:scheduler_pause"`
 	interval   time.Duration
 	batchLimit int
 	cancel     context.CancelFunc
@@ -79,7 +85,22 @@ func (s *RunScanner) loop(ctx context.Context) {
 func (s *RunScanner) tick(ctx context.Context) {
 	s.scanCallbackTimeouts(ctx)
 	s.scanStuckSync(ctx)
-	s.cleanupProgress(ctx)
+	s.scanRetries(ctx)
+	s.scanQueues(ctx)
+	s.scanCancels(ctx)
+}
+
+// scanQueues promotes queued runs whose slots were freed outside the executor (callbacks, timeouts, cancels).
+func (s *RunScanner) scanQueues(ctx context.Context) {
+	s.Exec.PromoteAllQueued(ctx)
+}
+
+// scanCancels 执行超时与取消：对等待回调超过 execution_timeout_sec 的 Run 发起取消，
+// 把其他副本发起的取消传播到本副本的执行上下文，并将宽限期内未确认的取消标记为 KILLED。
+func (s *RunScanner) scanCancels(ctx context.Context) {
+	s.Exec.CancelExpiredExecutions(ctx, s.batchLimit)
+	s.Exec.PropagateCancels(ctx, s.batchLimit)
+	s.Exec.KillUnconfirmed(ctx, s.batchLimit)
 }
 
 // scanCallbackTimeouts marks expired callback deadlines.
@@ -95,6 +116,28 @@ func (s *RunScanner) scanCallbackTimeouts(ctx context.Context) {
 			continue
 		}
 		logging.Info(ctx, fmt.Sprintf("callback deadline exceeded run_id=%d", run.ID))
+		run.Status = bizConsts.FailedTimeout
+		s.Exec.ObserveAsyncResult(ctx, run, time.Now(), false)
+		if _, policy := s.TaskSvc.RetryPolicy(ctx, run.TaskID); policy != nil {
+			s.RunSvc.PlanRetry(ctx, run, policy, model.RetryOutcome{Kind: model.OutcomeTimeout})
+		}
+	}
+}
+
+// scanRetries dispatches due retries; runs postponed by the concurrency limit or a scheduler pause stay due for a later tick.
+func (s *RunScanner) scanRetries(ctx context.Context) {
+	if s.Pause.Paused() {
+		return
+	}
+	due, err := s.RunSvc.ListRetryDue(ctx, time.Now().UTC(), s.batchLimit)
+	if err != nil {
+		logging.Error(ctx, "run_scanner list retry due failed: "+err.Error())
+		return
+	}
+	for _, run := range due {
+		if !dispatchRetry(ctx, s.TaskSvc, s.RunSvc, s.Exec, run) {
+			logging.Debug(ctx, fmt.Sprintf("retry for run %d postponed", run.ID))
+		}
 	}
 }
 
@@ -120,47 +163,3 @@ func (s *RunScanner) scanStuckSync(ctx context.Context) {
 		logging.Warn(ctx, fmt.Sprintf("sync run stuck marked timeout id=%d", run.ID))
 	}
 }
-
-// cleanupProgress clears progress entries for terminal runs after grace.
-func (s *RunScanner) cleanupProgress(ctx context.Context) {
-	if s.Progress == nil {
-		return
-	}
-	graceSec := s.cfg.ProgressCleanupGraceSeconds
-	grace := time.Duration(graceSec) * time.Second
-	entries := s.Progress.List()
-	if len(entries) == 0 {
-		return
-	}
-	// Collect run IDs
-	ids := make([]int64, 0, len(entries))
-	for _, p := range entries {
-		ids = append(ids, p.RunID)
-	}
-	// Batch fetch statuses (limit batches to avoid huge IN clauses)
-	batchSize := 500
-	terminal := map[bizConsts.RunStatus]struct{}{bizConsts.Success: {}, bizConsts.Failed: {}, bizConsts.FailedTimeout: {}, bizConsts.Timeout: {}, bizConsts.Canceled: {}, bizConsts.Skipped: {}, bizConsts.FailureSkip: {}, bizConsts.ConcurrentSkip: {}, bizConsts.OverlapSkip: {}, bizConsts.CallbackFailed: {}}
-	now := time.Now()
-	for i := 0; i < len(ids); i += batchSize {
-		end := i + batchSize
-		if end > len(ids) {
-			end = len(ids)
-		}
-		chunk := ids[i:end]
-		runs, err := s.RunSvc.ListByIDs(ctx, chunk)
-		if err != nil {
-			logging.Error(ctx, "listByIDs failed: "+err.Error())
-			continue
-		}
-		for _, r := range runs {
-			if _, ok := terminal[r.Status]; !ok {
-				continue
-			}
-			if graceSec > 0 && r.EndTime != nil && now.Sub(*r.EndTime) < grace {
-				continue
-			}
-			s.Progress.Clear(r.ID)
-			logging.Debug(ctx, fmt.Sprintf("progress cleared run_id=%d status=%s", r.ID, r.Status))
-		}
-	}
-}
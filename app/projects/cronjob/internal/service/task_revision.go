@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// systemActor 非请求发起的变更（如补记的 BASELINE 版本）的操作人。
+const systemActor = "system"
+
+// ErrRollbackDeleted 已删除的任务不能回滚。
+var ErrRollbackDeleted = errors.New("task deleted")
+
+type actorKey struct{}
+
+// WithActor 在 ctx 中记录发起变更的主体，TaskService 写版本历史时读取。
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom 取 ctx 中的变更主体；未设置时为 system。
+func ActorFrom(ctx context.Context) string {
+	if v, ok := ctx.Value(actorKey{}).(string); ok && v != "" {
+		return v
+	}
+	return systemActor
+}
+
+// recordRevision 写入一条版本记录；任务尚无历史时先以变更前的定义补记 BASELINE。
+// 版本历史写入失败只记日志，不回滚已生效的变更。
+func (s *TaskService) recordRevision(ctx context.Context, before, after *model.Task, action bizConsts.TaskRevisionAction, note string) {
+	if s.Revisions == nil || after == nil {
+		return
+	}
+	if before != nil {
+		has, err := s.Revisions.HasAny(ctx, after.ID)
+		if err != nil {
+			logging.Error(ctx, fmt.Sprintf("task %d revision lookup failed: %v", after.ID, err))
+			return
+		}
+		if !has {
+			base := &model.TaskRevision{TaskID: before.ID, Version: before.Version, Action: bizConsts.RevisionBaseline,
+				Actor: systemActor, Snapshot: revisionSnapshot(before), Diff: []model.FieldChange{}}
+			if err := s.Revisions.Create(ctx, base); err != nil {
+				logging.Error(ctx, fmt.Sprintf("task %d baseline revision failed: %v", before.ID, err))
+			}
+		}
+	}
+	rev := &model.TaskRevision{TaskID: after.ID, Version: after.Version, Action: action, Actor: ActorFrom(ctx), Note: note,
+		Snapshot: revisionSnapshot(after), Diff: model.DiffTasks(before, after), CreatedAt: time.Now()}
+	if err := s.Revisions.Create(ctx, rev); err != nil {
+		logging.Error(ctx, fmt.Sprintf("task %d revision v%d (%s) failed: %v", after.ID, after.Version, action, err))
+		return
+	}
+	logging.Info(ctx, fmt.Sprintf("task %d revision v%d %s by %s changes=%d", after.ID, after.Version, action, rev.Actor, len(rev.Diff)))
+}
+
+// revisionSnapshot 任务定义的副本，去掉调度游标等运行期字段。
+func revisionSnapshot(t *model.Task) *model.Task {
+	cp := *t
+	cp.LastEvaluatedAt = nil
+	return &cp
+}
+
+// History 任务的版本历史，最新在前；任务删除后仍可查询。
+func (s *TaskService) History(ctx context.Context, id int64, limit, offset int) ([]*model.TaskRevision, error) {
+	return s.Revisions.List(ctx, id, limit, offset)
+}
+
+// RollbackTarget 以当前任务为基础、套用历史版本 version 的定义，返回待校验的任务（不落库）。
+// 名称、状态、版本号、同步托管标记与归属（空间 / owners / labels）保持当前值；任务依赖不在版本历史中，不随回滚变化。
+func (s *TaskService) RollbackTarget(ctx context.Context, id int64, version int) (*model.Task, *model.TaskRevision, error) {
+	rev, err := s.Revisions.Get(ctx, id, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rev.Snapshot == nil {
+		return nil, nil, fmt.Errorf("%w: v%d has no snapshot", dao.ErrRevisionNotFound, version)
+	}
+	cur, err := s.TaskDao.Get(ctx, id)
+	if err != nil {
+		if dao.IsNotFound(err) {
+			return nil, nil, ErrRollbackDeleted
+		}
+		return nil, nil, err
+	}
+	t := *rev.Snapshot
+	t.ID, t.Name, t.Status, t.Version, t.Deleted = cur.ID, cur.Name, cur.Status, cur.Version, cur.Deleted
+	t.CreatedAt, t.UpdatedAt, t.LastEvaluatedAt = cur.CreatedAt, cur.UpdatedAt, cur.LastEvaluatedAt
+	t.Managed, t.SyncSource = cur.Managed, cur.SyncSource
+	t.Namespace, t.Owners, t.Labels = cur.Namespace, cur.Owners, cur.Labels
+	return &t, rev, nil
+}
+
+// Rollback 保存 RollbackTarget 生成（并已校验）的任务，记录为 ROLLBACK 版本。
+func (s *TaskService) Rollback(ctx context.Context, t *model.Task, fromVersion int) error {
+	return s.update(ctx, t, bizConsts.RevisionRollback, fmt.Sprintf("rollback to v%d", fromVersion))
+}
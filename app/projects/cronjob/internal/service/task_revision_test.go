@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// stubRevisionDao 内存中的版本历史。
+type stubRevisionDao struct {
+	dao.TaskRevisionDao
+	revs []*model.TaskRevision
+}
+
+func (s *stubRevisionDao) Create(ctx context.Context, rev *model.TaskRevision) error {
+	rev.ID = int64(len(s.revs) + 1)
+	s.revs = append(s.revs, rev)
+	return nil
+}
+
+func (s *stubRevisionDao) List(ctx context.Context, taskID int64, limit, offset int) ([]*model.TaskRevision, error) {
+	var out []*model.TaskRevision
+	for i := len(s.revs) - 1; i >= 0; i-- {
+		if s.revs[i].TaskID == taskID {
+			out = append(out, s.revs[i])
+		}
+	}
+	return out, nil
+}
+
+func (s *stubRevisionDao) Get(ctx context.Context, taskID int64, version int) (*model.TaskRevision, error) {
+	for i := len(s.revs) - 1; i >= 0; i-- {
+		if r := s.revs[i]; r.TaskID == taskID && r.Version == version {
+			return r, nil
+		}
+	}
+	return nil, dao.ErrRevisionNotFound
+}
+
+func (s *stubRevisionDao) HasAny(ctx context.Context, taskID int64) (bool, error) {
+	for _, r := range s.revs {
+		if r.TaskID == taskID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func fieldChange(diff []model.FieldChange, field string) *model.FieldChange {
+	for i := range diff {
+		if diff[i].Field == field {
+			return &diff[i]
+		}
+	}
+	return nil
+}
+
+func TestTaskRevisionLifecycle(t *testing.T) {
+	da := &stubDao{tasks: map[int64]*model.Task{}}
+	revs := &stubRevisionDao{}
+	ts := NewTaskService()
+	ts.TaskDao, ts.Revisions = da, revs
+	if err := ts.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithActor(context.Background(), "alice")
+
+	task := &model.Task{ID: 1, Name: "daily_bars", CronExpr: "0 0 9 * * *", BodyTemplate: `{"v":1}`, Status: bizConsts.DISABLED, Version: 1}
+	if err := ts.Create(ctx, task); err != nil {
+		t.Fatal(err)
+	}
+	upd := *task
+	upd.BodyTemplate = `{"v":2}`
+	if err := ts.UpdateCronAndMeta(WithActor(context.Background(), "bob"), &upd); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.UpdateStatus(ctx, 1, bizConsts.ENABLED); err != nil {
+		t.Fatal(err)
+	}
+
+	hist, _ := ts.History(ctx, 1, 50, 0)
+	if len(hist) != 3 {
+		t.Fatalf("expected 3 revisions, got %d", len(hist))
+	}
+	create, update, enable := hist[2], hist[1], hist[0]
+	if create.Action != bizConsts.RevisionCreate || create.Version != 1 || create.Actor != "alice" || fieldChange(create.Diff, "name") == nil {
+		t.Fatalf("unexpected create revision: %+v", create)
+	}
+	c := fieldChange(update.Diff, "body_template")
+	if update.Action != bizConsts.RevisionUpdate || update.Version != 2 || update.Actor != "bob" || len(update.Diff) != 1 ||
+		c == nil || c.Old != `{"v":1}` || c.New != `{"v":2}` {
+		t.Fatalf("unexpected update revision: %+v diff=%+v", update, update.Diff)
+	}
+	if s := fieldChange(enable.Diff, "status"); enable.Action != bizConsts.RevisionEnable || enable.Version != 3 || s == nil || len(enable.Diff) != 1 {
+		t.Fatalf("unexpected enable revision: %+v diff=%+v", enable, enable.Diff)
+	}
+
+	// 回滚到 v1：定义恢复，名称 / 状态 / 版本号沿用当前值，并记录为新版本
+	target, rev, err := ts.RollbackTarget(ctx, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.BodyTemplate != `{"v":1}` || target.Status != bizConsts.ENABLED || target.Version != 3 || rev.Version != 1 {
+		t.Fatalf("unexpected rollback target: %+v", target)
+	}
+	if err := ts.Rollback(ctx, target, rev.Version); err != nil {
+		t.Fatal(err)
+	}
+	hist, _ = ts.History(ctx, 1, 50, 0)
+	rb := hist[0]
+	if c := fieldChange(rb.Diff, "body_template"); rb.Action != bizConsts.RevisionRollback || rb.Version != 4 || rb.Note != "rollback to v1" ||
+		c == nil || c.New != `{"v":1}` {
+		t.Fatalf("unexpected rollback revision: %+v diff=%+v", rb, rb.Diff)
+	}
+	if _, _, err := ts.RollbackTarget(ctx, 1, 9); !errors.Is(err, dao.ErrRevisionNotFound) {
+		t.Fatalf("expected revision not found, got %v", err)
+	}
+}
+
+func TestTaskRevisionBaselineForExistingTask(t *testing.T) {
+	da := &stubDao{tasks: map[int64]*model.Task{7: {ID: 7, Name: "legacy", TargetPath: "/a", Status: bizConsts.ENABLED, Version: 5}}}
+	revs := &stubRevisionDao{}
+	ts := NewTaskService()
+	ts.TaskDao, ts.Revisions = da, revs
+	if err := ts.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.SoftDelete(context.Background(), 7); err != nil {
+		t.Fatal(err)
+	}
+	if len(revs.revs) != 2 {
+		t.Fatalf("expected baseline + delete, got %d", len(revs.revs))
+	}
+	base, del := revs.revs[0], revs.revs[1]
+	if base.Action != bizConsts.RevisionBaseline || base.Version != 5 || base.Actor != "system" || base.Snapshot.TargetPath != "/a" {
+		t.Fatalf("unexpected baseline: %+v", base)
+	}
+	if c := fieldChange(del.Diff, "deleted"); del.Action != bizConsts.RevisionDelete || del.Version != 6 || c == nil || c.New != 1 {
+		t.Fatalf("unexpected delete revision: %+v diff=%+v", del, del.Diff)
+	}
+}
+
+func TestCreateTaskRunRecordsTaskVersion(t *testing.T) {
+	ts := NewTaskService()
+	run := ts.CreateTaskRun(&model.Task{ID: 3, Timezone: "UTC", Version: 12}, time.Date(2026, 10, 8, 9, 0, 0, 0, time.UTC), 1)
+	if run.TaskVersion != 12 {
+		t.Fatalf("expected task_version 12, got %d", run.TaskVersion)
+	}
+}
@@ -18,10 +18,12 @@ import (
 // 并发安全：使用 RWMutex；ListEnabled 返回副本避免外部修改。
 type TaskService struct {
 	*core.BaseComponent
-	TaskDao dao.TaskDao `infra:"dep:task_dao"`
+	TaskDao   dao.TaskDao         `infra:"dep:task_dao"`
+	Revisions dao.TaskRevisionDao `infra:"dep:task_revision_dao"` // 每次变更写入一条版本记录
 
 	mu      sync.RWMutex
 	enabled map[int64]*model.Task // 缓存所有 ENABLED && 未删除 的任务
+	marker  dao.TaskMarker        // 最近一次 Refresh 时的任务表变更指纹
 }
 
 // TaskDaoImpl 提供对底层 DAO 的访问，用于控制器直接调用
@@ -68,9 +70,25 @@ func (s *TaskService) Create(ctx context.Context, t *model.Task) error {
 		s.enabled[t.ID] = t
 		s.mu.Unlock()
 	}
+	s.recordRevision(ctx, nil, t, bizConsts.RevisionCreate, "")
 	return nil
 }
 
+// Reactivate 以新定义恢复同名的已软删除任务（导入时使用），返回是否找到可恢复的任务。
+func (s *TaskService) Reactivate(ctx context.Context, name string, t *model.Task) (bool, error) {
+	_, ok, err := s.TaskDao.ReactivateByName(ctx, name, t)
+	if err != nil || !ok {
+		return ok, err
+	}
+	if t.Status == bizConsts.ENABLED {
+		s.mu.Lock()
+		s.enabled[t.ID] = t
+		s.mu.Unlock()
+	}
+	s.recordRevision(ctx, nil, t, bizConsts.RevisionCreate, "reactivated")
+	return true, nil
+}
+
 // Get 返回任务：若缓存中存在（仅 enabled）优先返回，否则查 DB。
 func (s *TaskService) Get(ctx context.Context, id int64) (*model.Task, error) {
 	s.mu.RLock()
@@ -95,6 +113,16 @@ func (s *TaskService) ListEnabled(ctx context.Context) ([]*model.Task, error) {
 
 // UpdateCronAndMeta 更新任务元数据（乐观锁），若任务仍处于 ENABLED 则更新缓存副本。
 func (s *TaskService) UpdateCronAndMeta(ctx context.Context, t *model.Task) error {
+	return s.update(ctx, t, bizConsts.RevisionUpdate, "")
+}
+
+func (s *TaskService) update(ctx context.Context, t *model.Task, action bizConsts.TaskRevisionAction, note string) error {
+	// 调用方可能直接修改了缓存中的对象，变更前的定义从数据库读取
+	before, _ := s.TaskDao.Get(ctx, t.ID)
+	if before != nil {
+		cp := *before
+		before = &cp
+	}
 	if err := s.TaskDao.UpdateCronAndMeta(ctx, t); err != nil {
 		return err
 	}
@@ -103,40 +131,89 @@ func (s *TaskService) UpdateCronAndMeta(ctx context.Context, t *model.Task) erro
 		s.enabled[t.ID] = t
 		s.mu.Unlock()
 	}
+	s.recordRevision(ctx, before, t, action, note)
 	return nil
 }
 
 // UpdateStatus 更新任务状态并同步缓存。
 func (s *TaskService) UpdateStatus(ctx context.Context, id int64, status bizConsts.TaskStatus) error {
+	before, _ := s.TaskDao.Get(ctx, id)
+	if before != nil {
+		cp := *before
+		before = &cp
+	}
 	if err := s.TaskDao.UpdateStatus(ctx, id, status); err != nil {
 		return err
 	}
+	// 重新读取最新任务（包含版本等字段）
+	after, err := s.TaskDao.Get(ctx, id)
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	if status == bizConsts.ENABLED {
-		// 重新读取最新任务（包含版本等字段）
-		if t, err := s.TaskDao.Get(ctx, id); err == nil && t.Status == bizConsts.ENABLED {
-			s.enabled[id] = t
+		if err == nil && after.Status == bizConsts.ENABLED {
+			s.enabled[id] = after
 		}
 	} else {
 		delete(s.enabled, id)
 	}
+	s.mu.Unlock()
+	if err == nil && before != nil {
+		action := bizConsts.RevisionDisable
+		if status == bizConsts.ENABLED {
+			action = bizConsts.RevisionEnable
+		}
+		s.recordRevision(ctx, before, after, action, "")
+	}
 	return nil
 }
 
 // SoftDelete 软删除并移出缓存。
 func (s *TaskService) SoftDelete(ctx context.Context, id int64) error {
+	before, _ := s.TaskDao.Get(ctx, id)
+	if before != nil {
+		cp := *before
+		before = &cp
+	}
 	if err := s.TaskDao.SoftDelete(ctx, id); err != nil {
 		return err
 	}
 	s.mu.Lock()
 	delete(s.enabled, id)
 	s.mu.Unlock()
+	if before != nil {
+		after := *before
+		after.Deleted, after.Version = 1, before.Version+1
+		s.recordRevision(ctx, before, &after, bizConsts.RevisionDelete, "")
+	}
 	return nil
 }
 
 // Refresh 强制全量重载（可用于运维手动调用）
 func (s *TaskService) Refresh(ctx context.Context) error {
+	marker, err := s.TaskDao.ChangeMarker(ctx)
+	if err != nil {
+		return err
+	}
+	return s.reload(ctx, marker)
+}
+
+// RefreshIfChanged 仅在任务表变更指纹变化时重载缓存，返回是否重载；
+// 供 HA 模式下的 leader 每个 tick 调用，未变更时只有一条聚合查询。
+func (s *TaskService) RefreshIfChanged(ctx context.Context) (bool, error) {
+	marker, err := s.TaskDao.ChangeMarker(ctx)
+	if err != nil {
+		return false, err
+	}
+	s.mu.RLock()
+	same := s.marker == marker
+	s.mu.RUnlock()
+	if same {
+		return false, nil
+	}
+	return true, s.reload(ctx, marker)
+}
+
+// reload 重建 ENABLED 任务缓存；指纹在查询任务之前取得，期间的并发写入会在下一次检查时再次触发重载。
+func (s *TaskService) reload(ctx context.Context, marker dao.TaskMarker) error {
 	list, err := s.TaskDao.ListEnabled(ctx)
 	if err != nil {
 		return err
@@ -146,10 +223,16 @@ func (s *TaskService) Refresh(ctx context.Context) error {
 	for _, t := range list {
 		s.enabled[t.ID] = t
 	}
+	s.marker = marker
 	s.mu.Unlock()
 	return nil
 }
 
+// MarkEvaluated 持久化调度器的评估游标（用于重启后补偿错过的触发）。
+func (s *TaskService) MarkEvaluated(ctx context.Context, ids []int64, at time.Time) error {
+	return s.TaskDao.UpdateLastEvaluated(ctx, ids, at)
+}
+
 func (s *TaskService) ListFiltered(ctx context.Context, f *model.TaskListFilters, limit, offset int) ([]*model.Task, error) {
 	return s.TaskDao.ListFiltered(ctx, f, limit, offset)
 }
@@ -158,19 +241,27 @@ func (s *TaskService) CountFiltered(ctx context.Context, f *model.TaskListFilter
 	return s.TaskDao.CountFiltered(ctx, f)
 }
 
-// CreateTaskRun 从 Task 创建 TaskRun，统一初始化逻辑
+// CreateTaskRun 从 Task 创建 TaskRun，统一初始化逻辑；业务日期取 scheduledTime 在任务时区下的日期
 func (s *TaskService) CreateTaskRun(task *model.Task, scheduledTime time.Time, attempt int) *model.TaskRun {
+	date := LogicalDate(task, scheduledTime)
 	return &model.TaskRun{
-		TaskID:             task.ID,
-		ScheduledTime:      scheduledTime,
-		Status:             bizConsts.Scheduled,
-		Attempt:            attempt,
-		TargetService:      task.TargetService,
-		TargetPath:         task.TargetPath,
-		Method:             task.HTTPMethod,
-		ExecType:           task.ExecType,
-		CallbackTimeoutSec: task.CallbackTimeoutSec,
-		RequestHeaders:     task.HeadersJSON,
-		RequestBody:        task.BodyTemplate,
+		TaskID:              task.ID,
+		ScheduledTime:       scheduledTime,
+		Status:              bizConsts.Scheduled,
+		Attempt:             attempt,
+		TargetService:       task.TargetService,
+		TargetPath:          task.TargetPath,
+		Method:              task.HTTPMethod,
+		ExecType:            task.ExecType,
+		Executor:            task.Executor,
+		ExecutorConfig:      task.ExecutorConfig,
+		CallbackTimeoutSec:  task.CallbackTimeoutSec,
+		ExecutionTimeoutSec: task.ExecutionTimeoutSec,
+		RequestHeaders:      task.HeadersJSON,
+		RequestBody:         task.BodyTemplate,
+		LogicalDate:         &date,
+		TriggerType:         bizConsts.TriggerCron,
+		TaskVersion:         task.Version,
+		Namespace:           task.Namespace,
 	}
 }
@@ -3,17 +3,24 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/dao"
 	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
 )
 
 // stubDao implements TaskDao for TaskService tests
-type stubDao struct{ tasks map[int64]*model.Task }
+type stubDao struct {
+	tasks map[int64]*model.Task
+	deps  []*model.TaskDependency
+	lists int // ListEnabled 调用次数
+}
 
 func (s *stubDao) Create(ctx context.Context, t *model.Task) error        { s.tasks[t.ID] = t; return nil }
 func (s *stubDao) Get(ctx context.Context, id int64) (*model.Task, error) { return s.tasks[id], nil }
 func (s *stubDao) ListEnabled(ctx context.Context) ([]*model.Task, error) {
+	s.lists++
 	var out []*model.Task
 	for _, t := range s.tasks {
 		if t.Status == bizConsts.ENABLED {
@@ -37,6 +44,64 @@ func (s *stubDao) UpdateStatus(ctx context.Context, id int64, status bizConsts.T
 func (s *stubDao) SoftDelete(ctx context.Context, id int64) error {
 	if t := s.tasks[id]; t != nil {
 		t.Deleted = 1
+		t.Version++
+	}
+	return nil
+}
+
+func (s *stubDao) ExistsByName(ctx context.Context, name string) bool {
+	for _, t := range s.tasks {
+		if t.Name == name && t.Deleted == 0 {
+			return true
+		}
+	}
+	return false
+}
+func (s *stubDao) ReactivateByName(ctx context.Context, name string, t *model.Task) (int64, bool, error) {
+	return 0, false, nil
+}
+func (s *stubDao) ListFiltered(ctx context.Context, f *model.TaskListFilters, limit, offset int) ([]*model.Task, error) {
+	var out []*model.Task
+	for _, t := range s.tasks {
+		out = append(out, t)
+	}
+	return out, nil
+}
+func (s *stubDao) CountFiltered(ctx context.Context, f *model.TaskListFilters) (int64, error) {
+	return int64(len(s.tasks)), nil
+}
+
+func (s *stubDao) UpdateLastEvaluated(ctx context.Context, ids []int64, at time.Time) error {
+	for _, id := range ids {
+		if t := s.tasks[id]; t != nil {
+			at := at
+			t.LastEvaluatedAt = &at
+		}
+	}
+	return nil
+}
+
+func (s *stubDao) ChangeMarker(ctx context.Context) (dao.TaskMarker, error) {
+	m := dao.TaskMarker{RowCount: int64(len(s.tasks))}
+	for _, t := range s.tasks {
+		m.VersionSum += int64(t.Version)
+	}
+	return m, nil
+}
+
+func (s *stubDao) ListDependencies(ctx context.Context) ([]*model.TaskDependency, error) {
+	return s.deps, nil
+}
+func (s *stubDao) ReplaceUpstreams(ctx context.Context, taskID int64, upstreamIDs []int64) error {
+	kept := s.deps[:0]
+	for _, d := range s.deps {
+		if d.TaskID != taskID {
+			kept = append(kept, d)
+		}
+	}
+	s.deps = kept
+	for _, u := range upstreamIDs {
+		s.deps = append(s.deps, &model.TaskDependency{TaskID: taskID, UpstreamTaskID: u})
 	}
 	return nil
 }
@@ -0,0 +1,228 @@
+package tasksync
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// Action 同步计划中对单个任务的动作。
+type Action string
+
+const (
+	ActionCreate    Action = "CREATE"
+	ActionUpdate    Action = "UPDATE"
+	ActionDelete    Action = "DELETE"
+	ActionUnchanged Action = "UNCHANGED"
+)
+
+// UpstreamField 依赖变化在 diff 中的字段名（依赖不是任务表的列）。
+const UpstreamField = "upstream_tasks"
+
+// planIgnoredFields 不由声明决定、不参与比对的字段。
+var planIgnoredFields = []string{"deleted"}
+
+// Item 计划中的一项。
+type Item struct {
+	Name    string              `json:"name"`
+	Action  Action              `json:"action"`
+	TaskID  int64               `json:"task_id,omitempty"` // 已存在任务的 ID
+	Source  string              `json:"source,omitempty"`  // 声明所在文件；DELETE 时为任务原来的来源
+	Adopt   bool                `json:"adopt,omitempty"`   // 已存在的非托管任务将转为托管
+	Diff    []model.FieldChange `json:"diff,omitempty"`
+	Error   string              `json:"error,omitempty"` // 声明无效；计划含错误时不可应用
+	Desired *model.Task         `json:"-"`               // CREATE / UPDATE / UNCHANGED 时的目标定义
+	Current *model.Task         `json:"-"`               // 库中的当前定义
+
+	Upstreams        []string `json:"-"` // 目标上游任务名称（已排序去重）
+	UpstreamsChanged bool     `json:"-"`
+}
+
+// Plan 同步计划：按任务名排序。
+type Plan struct {
+	Items     []*Item        `json:"items"`
+	Summary   map[Action]int `json:"summary"`
+	Unmanaged []string       `json:"unmanaged,omitempty"` // 未声明的非托管任务，prune 时删除，否则保留
+	Prune     bool           `json:"prune"`
+	Valid     bool           `json:"valid"`
+}
+
+// Changed 计划是否包含变更。
+func (p *Plan) Changed() bool {
+	return p.Summary[ActionCreate]+p.Summary[ActionUpdate]+p.Summary[ActionDelete] > 0
+}
+
+// Compute 以声明为目标、库中未删除的任务为现状，按任务名生成同步计划。
+// upstreams 为现有任务 ID -> 上游任务名称。声明中不存在的托管任务删除；非托管任务仅在 prune 时删除；
+// 声明了与非托管任务同名的任务时接管该任务（UPDATE 且 adopt）。
+func Compute(specs []*Spec, current []*model.Task, upstreams map[int64][]string, prune bool) *Plan {
+	plan := &Plan{Summary: map[Action]int{}, Prune: prune}
+	byName := make(map[string]*model.Task, len(current))
+	for _, t := range current {
+		byName[t.Name] = t
+	}
+	declared := make(map[string]bool, len(specs))
+	for _, s := range specs {
+		declared[s.Name] = true
+		item := &Item{Name: s.Name, Source: s.Source, Upstreams: uniqueSorted(s.UpstreamTasks)}
+		cur := byName[s.Name]
+		if cur != nil {
+			item.TaskID, item.Current, item.Adopt = cur.ID, cur, !cur.Managed
+		}
+		desired, err := s.Task()
+		switch {
+		case err != nil:
+			item.Action, item.Error = ActionCreate, err.Error()
+			if cur != nil {
+				item.Action = ActionUpdate
+			}
+		case cur == nil:
+			item.Action, item.Desired = ActionCreate, desired
+			item.Diff = model.DiffTasks(nil, desired, planIgnoredFields...)
+			if len(item.Upstreams) > 0 {
+				item.UpstreamsChanged = true
+				item.Diff = append(item.Diff, model.FieldChange{Field: UpstreamField, New: item.Upstreams})
+			}
+		default:
+			desired.ID, desired.Version, desired.CreatedAt, desired.UpdatedAt = cur.ID, cur.Version, cur.CreatedAt, cur.UpdatedAt
+			desired.LastEvaluatedAt = cur.LastEvaluatedAt
+			item.Desired = desired
+			item.Diff = model.DiffTasks(normalized(cur), desired, planIgnoredFields...)
+			if old := uniqueSorted(upstreams[cur.ID]); !slices.Equal(old, item.Upstreams) {
+				item.UpstreamsChanged = true
+				item.Diff = append(item.Diff, model.FieldChange{Field: UpstreamField, Old: old, New: item.Upstreams})
+			}
+			item.Action = ActionUpdate
+			if len(item.Diff) == 0 {
+				item.Action = ActionUnchanged
+			}
+		}
+		plan.Items = append(plan.Items, item)
+	}
+	kept := map[string][]string{}
+	for _, t := range current {
+		if declared[t.Name] {
+			continue
+		}
+		if !t.Managed && !prune {
+			plan.Unmanaged = append(plan.Unmanaged, t.Name)
+			kept[t.Name] = upstreams[t.ID]
+			continue
+		}
+		plan.Items = append(plan.Items, &Item{Name: t.Name, Action: ActionDelete, TaskID: t.ID, Source: t.SyncSource, Current: t})
+	}
+	checkUpstreams(plan, kept)
+	sort.Slice(plan.Items, func(i, j int) bool { return plan.Items[i].Name < plan.Items[j].Name })
+	sort.Strings(plan.Unmanaged)
+	plan.Valid = true
+	for _, it := range plan.Items {
+		plan.Summary[it.Action]++
+		if it.Error != "" {
+			plan.Valid = false
+		}
+	}
+	return plan
+}
+
+// checkUpstreams 校验同步后的依赖图：上游须是同步后仍存在的任务，且不成环。
+// kept 为保留的非托管任务 -> 现有上游名称，参与成环检测。
+func checkUpstreams(plan *Plan, kept map[string][]string) {
+	graph := map[string][]string{}
+	items := map[string]*Item{}
+	for _, it := range plan.Items {
+		if it.Action == ActionDelete {
+			continue
+		}
+		items[it.Name] = it
+		graph[it.Name] = it.Upstreams
+	}
+	for name, ups := range kept {
+		graph[name] = ups
+	}
+	for _, it := range plan.Items {
+		if it.Error != "" || it.Action == ActionDelete {
+			continue
+		}
+		for _, up := range it.Upstreams {
+			if up == it.Name {
+				it.Error = "task cannot depend on itself"
+				break
+			}
+			if _, ok := graph[up]; !ok {
+				it.Error = fmt.Sprintf("unknown upstream task %q", up)
+				break
+			}
+		}
+	}
+	// 成环检测（DFS 三色标记）
+	const (
+		white = iota
+		grey
+		black
+	)
+	color := map[string]int{}
+	var stack []string
+	var visit func(n string)
+	visit = func(n string) {
+		color[n] = grey
+		stack = append(stack, n)
+		for _, up := range graph[n] {
+			switch color[up] {
+			case white:
+				if _, ok := graph[up]; ok {
+					visit(up)
+				}
+			case grey:
+				start := slices.Index(stack, up)
+				cycle := append(slices.Clone(stack[start:]), up)
+				msg := "dependency cycle: " + strings.Join(cycle, " -> ")
+				for _, name := range cycle {
+					if it := items[name]; it != nil && it.Error == "" {
+						it.Error = msg
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		color[n] = black
+	}
+	names := make([]string, 0, len(graph))
+	for n := range graph {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if color[n] == white {
+			visit(n)
+		}
+	}
+}
+
+// normalized 库中任务的副本，JSON 字段与空的 owners / labels 规范化后用于比对。
+func normalized(t *model.Task) *model.Task {
+	cp := *t
+	cp.HeadersJSON = canonicalJSON(cp.HeadersJSON)
+	cp.RetryPolicyJSON = canonicalJSON(cp.RetryPolicyJSON)
+	cp.ExecutorConfig = canonicalJSON(cp.ExecutorConfig)
+	if cp.Owners == nil {
+		cp.Owners = []string{}
+	}
+	if cp.Labels == nil {
+		cp.Labels = map[string]string{}
+	}
+	return &cp
+}
+
+func uniqueSorted(names []string) []string {
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		if n = strings.TrimSpace(n); n != "" {
+			out = append(out, n)
+		}
+	}
+	sort.Strings(out)
+	return slices.Compact(out)
+}
@@ -0,0 +1,325 @@
+// Package tasksync 声明式任务同步：从 YAML 文件读取任务声明，与数据库中的任务按名称比对生成同步计划。
+//
+// 一个文件可包含多个 YAML 文档（以 --- 分隔），每个文档声明一个任务；任务名在整个目录内唯一。
+// 本包只做解析与比对，计划的校验（日历、执行器后端、模板）与落库由调用方完成。
+package tasksync
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+// Spec 一个任务的声明。字段与任务导入格式一致，headers / retry_policy / executor_config 可直接写 YAML 对象。
+// 未填写的字段取与 API 创建任务相同的默认值；status 默认 ENABLED。
+type Spec struct {
+	Name                string            `yaml:"name"`
+	Description         string            `yaml:"description"`
+	Namespace           string            `yaml:"namespace"` // 默认 default
+	Owners              []string          `yaml:"owners"`
+	Labels              map[string]string `yaml:"labels"`
+	CronExpr            string            `yaml:"cron_expr"`
+	Timezone            string            `yaml:"timezone"`
+	Status              string            `yaml:"status"`
+	ExecType            string            `yaml:"exec_type"`
+	Executor            string            `yaml:"executor"`
+	ExecutorConfig      any               `yaml:"executor_config"`
+	Method              string            `yaml:"method"`
+	TargetService       string            `yaml:"target_service"`
+	TargetPath          string            `yaml:"target_path"`
+	Headers             any               `yaml:"headers"`
+	BodyTemplate        string            `yaml:"body_template"`
+	RetryPolicy         any               `yaml:"retry_policy"`
+	MaxConcurrency      int               `yaml:"max_concurrency"`
+	ConcurrencyPolicy   string            `yaml:"concurrency_policy"`
+	QueueMaxDepth       int               `yaml:"queue_max_depth"`
+	QueueOverflow       string            `yaml:"queue_overflow"`
+	CallbackMethod      string            `yaml:"callback_method"`
+	CallbackTimeoutSec  int               `yaml:"callback_timeout_sec"`
+	ExecutionTimeoutSec int               `yaml:"execution_timeout_sec"` // 0 不限制
+	OverlapAction       string            `yaml:"overlap_action"`
+	FailureAction       string            `yaml:"failure_action"`
+	MisfirePolicy       string            `yaml:"misfire_policy"`
+	MisfireGraceSec     int               `yaml:"misfire_grace_sec"`
+	ScheduleMode        string            `yaml:"schedule_mode"`
+	TriggerRule         string            `yaml:"trigger_rule"`
+	Calendar            string            `yaml:"calendar"`
+	CalendarMode        string            `yaml:"calendar_mode"`
+	UpstreamTasks       []string          `yaml:"upstream_tasks"` // 上游任务名称
+
+	Source string `yaml:"-"` // 声明所在文件（相对目录）
+}
+
+// LoadDir 读取 dir 下（含子目录）所有 *.yaml / *.yml 文件。
+func LoadDir(dir string) ([]*Spec, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return Load(files)
+}
+
+// Load 解析一组文件（文件名 -> 内容），按文件名顺序返回声明；同名任务重复声明时报错。
+func Load(files map[string][]byte) ([]*Spec, error) {
+	names := make([]string, 0, len(files))
+	for n := range files {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var specs []*Spec
+	seen := map[string]string{}
+	for _, n := range names {
+		list, err := Parse(n, files[n])
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range list {
+			if prev, ok := seen[s.Name]; ok {
+				return nil, fmt.Errorf("task %q declared in both %s and %s", s.Name, prev, s.Source)
+			}
+			seen[s.Name] = s.Source
+			specs = append(specs, s)
+		}
+	}
+	return specs, nil
+}
+
+// Parse 解析一个文件中的全部文档；未知字段视为错误（多半是拼写错误），空文档跳过。
+func Parse(source string, data []byte) ([]*Spec, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var specs []*Spec
+	for i := 1; ; i++ {
+		var s Spec
+		err := dec.Decode(&s)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: document %d: %w", source, i, err)
+		}
+		if reflect.ValueOf(s).IsZero() {
+			continue
+		}
+		s.Name = strings.TrimSpace(s.Name)
+		if s.Name == "" {
+			return nil, fmt.Errorf("%s: document %d: name is required", source, i)
+		}
+		s.Source = source
+		specs = append(specs, &s)
+	}
+	return specs, nil
+}
+
+// Task 按声明生成任务定义（托管、来源为 Source），默认值与 API 创建任务一致。
+func (s *Spec) Task() (*model.Task, error) {
+	headers, err := jsonField("headers", s.Headers)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := jsonField("retry_policy", s.RetryPolicy)
+	if err != nil {
+		return nil, err
+	}
+	execCfg, err := jsonField("executor_config", s.ExecutorConfig)
+	if err != nil {
+		return nil, err
+	}
+	status := consts.TaskStatus(strings.ToUpper(defaultOr(s.Status, string(consts.ENABLED))))
+	if status != consts.ENABLED && status != consts.DISABLED {
+		return nil, fmt.Errorf("invalid status %q", s.Status)
+	}
+	t := &model.Task{
+		Name:                s.Name,
+		Description:         s.Description,
+		Namespace:           defaultOr(strings.TrimSpace(s.Namespace), consts.DEFAULT_NAMESPACE),
+		Owners:              uniqueSorted(s.Owners),
+		Labels:              s.Labels,
+		CronExpr:            model.NormalizeCron(strings.TrimSpace(s.CronExpr)),
+		Timezone:            defaultOr(strings.TrimSpace(s.Timezone), "UTC"),
+		ExecType:            consts.ExecType(strings.ToUpper(defaultOr(s.ExecType, string(consts.ExecTypeSync)))),
+		Executor:            consts.ExecutorKind(strings.ToUpper(defaultOr(s.Executor, string(consts.DEFAULT_EXECUTOR)))),
+		ExecutorConfig:      execCfg,
+		HTTPMethod:          strings.ToUpper(s.Method),
+		TargetService:       s.TargetService,
+		TargetPath:          s.TargetPath,
+		HeadersJSON:         headers,
+		BodyTemplate:        s.BodyTemplate,
+		RetryPolicyJSON:     retry,
+		MaxConcurrency:      defaultInt(s.MaxConcurrency, 1),
+		ConcurrencyPolicy:   consts.ConcurrencyPolicy(strings.ToUpper(defaultOr(s.ConcurrencyPolicy, string(consts.DEFAULT_CONCURRENCY_POLICY)))),
+		QueueMaxDepth:       defaultInt(s.QueueMaxDepth, consts.DEFAULT_QUEUE_MAX_DEPTH),
+		QueueOverflow:       consts.QueueOverflow(strings.ToUpper(defaultOr(s.QueueOverflow, string(consts.DEFAULT_QUEUE_OVERFLOW)))),
+		CallbackMethod:      strings.ToUpper(defaultOr(s.CallbackMethod, "POST")),
+		CallbackTimeoutSec:  defaultInt(s.CallbackTimeoutSec, 300),
+		ExecutionTimeoutSec: s.ExecutionTimeoutSec,
+		OverlapAction:       consts.OverlapAction(strings.ToUpper(defaultOr(s.OverlapAction, string(consts.DEFAULT_OVERLAP_ACTION)))),
+		FailureAction:       consts.FailureAction(strings.ToUpper(defaultOr(s.FailureAction, string(consts.DEFAULT_FAILURE_ACTION)))),
+		MisfirePolicy:       consts.MisfirePolicy(strings.ToUpper(defaultOr(s.MisfirePolicy, string(consts.DEFAULT_MISFIRE_POLICY)))),
+		MisfireGraceSec:     s.MisfireGraceSec,
+		ScheduleMode:        consts.ScheduleMode(strings.ToUpper(defaultOr(s.ScheduleMode, string(consts.DEFAULT_SCHEDULE_MODE)))),
+		TriggerRule:         consts.TriggerRule(strings.ToUpper(defaultOr(s.TriggerRule, string(consts.DEFAULT_TRIGGER_RULE)))),
+		Calendar:            strings.TrimSpace(s.Calendar),
+		CalendarMode:        consts.CalendarMode(strings.ToUpper(defaultOr(s.CalendarMode, string(consts.DEFAULT_CALENDAR_MODE)))),
+		Status:              status,
+		Managed:             true,
+		SyncSource:          s.Source,
+		Version:             1,
+	}
+	if t.Labels == nil {
+		t.Labels = map[string]string{}
+	}
+	if err := model.ValidateLabels(t.Labels); err != nil {
+		return nil, err
+	}
+	if t.Executor == consts.ExecutorHTTP {
+		t.TargetService = defaultOr(t.TargetService, "artemis")
+		t.HTTPMethod = defaultOr(t.HTTPMethod, "POST")
+	}
+	if (t.CronExpr == "" && t.UsesCron()) || t.TargetPath == "" {
+		return nil, fmt.Errorf("cron_expr/target_path cannot be empty")
+	}
+	if t.UsesDependencies() && len(s.UpstreamTasks) == 0 {
+		return nil, fmt.Errorf("schedule_mode %s requires upstream_tasks", t.ScheduleMode)
+	}
+	return t, nil
+}
+
+// FromTask 由已有任务生成声明（如 cronctl tasks edit 的编辑稿），upstreams 为上游任务名称；
+// JSON 字段展开为 YAML 对象，经 Task 转换回任务定义时与原任务一致。
+func FromTask(t *model.Task, upstreams []string) *Spec {
+	return &Spec{
+		Name:                t.Name,
+		Description:         t.Description,
+		Namespace:           t.Namespace,
+		Owners:              t.Owners,
+		Labels:              t.Labels,
+		CronExpr:            t.CronExpr,
+		Timezone:            t.Timezone,
+		Status:              string(t.Status),
+		ExecType:            string(t.ExecType),
+		Executor:            string(t.Executor),
+		ExecutorConfig:      jsonValue(t.ExecutorConfig),
+		Method:              t.HTTPMethod,
+		TargetService:       t.TargetService,
+		TargetPath:          t.TargetPath,
+		Headers:             jsonValue(t.HeadersJSON),
+		BodyTemplate:        t.BodyTemplate,
+		RetryPolicy:         jsonValue(t.RetryPolicyJSON),
+		MaxConcurrency:      t.MaxConcurrency,
+		ConcurrencyPolicy:   string(t.ConcurrencyPolicy),
+		QueueMaxDepth:       t.QueueMaxDepth,
+		QueueOverflow:       string(t.QueueOverflow),
+		CallbackMethod:      t.CallbackMethod,
+		CallbackTimeoutSec:  t.CallbackTimeoutSec,
+		ExecutionTimeoutSec: t.ExecutionTimeoutSec,
+		OverlapAction:       string(t.OverlapAction),
+		FailureAction:       string(t.FailureAction),
+		MisfirePolicy:       string(t.MisfirePolicy),
+		MisfireGraceSec:     t.MisfireGraceSec,
+		ScheduleMode:        string(t.ScheduleMode),
+		TriggerRule:         string(t.TriggerRule),
+		Calendar:            t.Calendar,
+		CalendarMode:        string(t.CalendarMode),
+		UpstreamTasks:       upstreams,
+		Source:              t.SyncSource,
+	}
+}
+
+// jsonValue 解析库中的 JSON 字段；为空或 {} 时返回 nil，非法 JSON 原样返回字符串。
+func jsonValue(s string) any {
+	if c := canonicalJSON(s); c == consts.DEFAULT_JSON_STR {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	return v
+}
+
+// jsonField 把 YAML 对象或 JSON 字符串转为规范化的 JSON 字符串；为空时 {}。
+func jsonField(field string, v any) (string, error) {
+	switch x := v.(type) {
+	case nil:
+		return consts.DEFAULT_JSON_STR, nil
+	case string:
+		if strings.TrimSpace(x) == "" {
+			return consts.DEFAULT_JSON_STR, nil
+		}
+		var parsed any
+		if err := json.Unmarshal([]byte(x), &parsed); err != nil {
+			return "", fmt.Errorf("%s: invalid JSON: %w", field, err)
+		}
+		v = parsed
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", field, err)
+	}
+	return string(b), nil
+}
+
+// canonicalJSON 规范化库中的 JSON 字段（键排序、去空白），使格式差异不计入 diff；非法 JSON 原样返回。
+func canonicalJSON(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return consts.DEFAULT_JSON_STR
+	}
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return s
+	}
+	return string(b)
+}
+
+func defaultOr(s, def string) string {
+	if strings.TrimSpace(s) != "" {
+		return s
+	}
+	return def
+}
+
+func defaultInt(i, def int) int {
+	if i != 0 {
+		return i
+	}
+	return def
+}
@@ -0,0 +1,243 @@
+package tasksync
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/consts"
+	"github.com/grand-thief-cash/chaos/app/projects/cronjob/internal/model"
+)
+
+const marketFile = `
+name: daily_bars
+cron_expr: "30 9 * * 1-5"
+timezone: Asia/Shanghai
+target_path: /api/v1/bars
+headers:
+  X-Source: cronjob
+  Content-Type: application/json
+retry_policy: {max_attempts: 3, backoff: 30s}
+---
+name: daily_report
+schedule_mode: dependency
+target_path: /api/v1/report
+upstream_tasks: [daily_bars]
+status: disabled
+`
+
+func TestParseAndDefaults(t *testing.T) {
+	specs, err := Load(map[string][]byte{"market.yaml": []byte(marketFile)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 2 || specs[0].Source != "market.yaml" {
+		t.Fatalf("unexpected specs: %+v", specs)
+	}
+	bars, err := specs[0].Task()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bars.CronExpr != "0 30 9 * * 1-5" || bars.Status != consts.ENABLED || !bars.Managed || bars.SyncSource != "market.yaml" ||
+		bars.HTTPMethod != "POST" || bars.TargetService != "artemis" || bars.ExecType != consts.ExecTypeSync ||
+		bars.QueueMaxDepth != consts.DEFAULT_QUEUE_MAX_DEPTH || bars.MaxConcurrency != 1 {
+		t.Fatalf("unexpected defaults: %+v", bars)
+	}
+	if bars.HeadersJSON != `{"Content-Type":"application/json","X-Source":"cronjob"}` || bars.ExecutorConfig != "{}" {
+		t.Fatalf("unexpected json fields: headers=%s executor_config=%s", bars.HeadersJSON, bars.ExecutorConfig)
+	}
+	report, err := specs[1].Task()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ScheduleMode != consts.ScheduleModeDependency || report.Status != consts.DISABLED {
+		t.Fatalf("unexpected report task: %+v", report)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		"unknown field": "name: a\ncron_expr: '@daily'\ntarget_path: /a\ncron: oops\n",
+		"missing name":  "cron_expr: '@daily'\ntarget_path: /a\n",
+	}
+	for name, doc := range cases {
+		if _, err := Parse("a.yaml", []byte(doc)); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+	_, err := Load(map[string][]byte{
+		"a.yaml": []byte("name: dup\ncron_expr: '@daily'\ntarget_path: /a\n"),
+		"b.yaml": []byte("name: dup\ncron_expr: '@daily'\ntarget_path: /b\n"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "a.yaml and b.yaml") {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+	specs, _ := Parse("c.yaml", []byte("name: c\nschedule_mode: DEPENDENCY\ntarget_path: /c\n"))
+	if _, err := specs[0].Task(); err == nil {
+		t.Fatal("expected dependency task without upstreams to fail")
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "market"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"market/bars.yaml": "name: bars\ncron_expr: '@daily'\ntarget_path: /a\n",
+		"ops.yml":          "---\nname: ops\ncron_expr: '@hourly'\ntarget_path: /b\n---\n",
+		"README.md":        "not a task",
+	}
+	for n, c := range files {
+		if err := os.WriteFile(filepath.Join(dir, n), []byte(c), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	specs, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 2 || specs[0].Source != "market/bars.yaml" || specs[1].Name != "ops" {
+		t.Fatalf("unexpected specs: %+v", specs)
+	}
+}
+
+func TestFromTaskRoundTrip(t *testing.T) {
+	specs, err := Parse("market.yaml", []byte(marketFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bars, err := specs[0].Task()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := yaml.Marshal(FromTask(bars, []string{"calendar_sync"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "X-Source: cronjob") {
+		t.Fatalf("headers should be expanded to a YAML object:\n%s", out)
+	}
+	again, err := Parse("edit.yaml", out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := again[0].Task()
+	if err != nil {
+		t.Fatal(err)
+	}
+	back.SyncSource = bars.SyncSource
+	if !reflect.DeepEqual(back, bars) {
+		t.Fatalf("round trip changed the task:\nwant %+v\ngot  %+v", bars, back)
+	}
+	if len(again[0].UpstreamTasks) != 1 || again[0].UpstreamTasks[0] != "calendar_sync" {
+		t.Fatalf("unexpected upstreams: %v", again[0].UpstreamTasks)
+	}
+}
+
+func itemByName(p *Plan, name string) *Item {
+	for _, it := range p.Items {
+		if it.Name == name {
+			return it
+		}
+	}
+	return nil
+}
+
+func TestComputePlan(t *testing.T) {
+	specs, err := Load(map[string][]byte{"market.yaml": []byte(marketFile)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bars, _ := specs[0].Task()
+	cur := *bars
+	cur.ID, cur.Version = 1, 4
+	cur.HeadersJSON = `{ "X-Source": "cronjob", "Content-Type": "application/json" }` // 仅格式不同
+	current := []*model.Task{
+		&cur,
+		{ID: 2, Name: "daily_report", ScheduleMode: consts.ScheduleModeDependency, TargetPath: "/old", Status: consts.ENABLED},
+		{ID: 3, Name: "retired", Managed: true, SyncSource: "old.yaml"},
+		{ID: 4, Name: "manual"},
+	}
+
+	plan := Compute(specs, current, map[int64][]string{}, false)
+	if !plan.Valid || !plan.Changed() {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if it := itemByName(plan, "daily_bars"); it.Action != ActionUnchanged || it.TaskID != 1 {
+		t.Fatalf("daily_bars should be unchanged: %+v diff=%+v", it, it.Diff)
+	}
+	report := itemByName(plan, "daily_report")
+	if report.Action != ActionUpdate || !report.Adopt || !report.UpstreamsChanged {
+		t.Fatalf("unexpected daily_report item: %+v", report)
+	}
+	fields := map[string]bool{}
+	for _, c := range report.Diff {
+		fields[c.Field] = true
+	}
+	for _, f := range []string{"target_path", "status", "managed", "sync_source", UpstreamField} {
+		if !fields[f] {
+			t.Fatalf("daily_report diff missing %s: %+v", f, report.Diff)
+		}
+	}
+	if it := itemByName(plan, "retired"); it == nil || it.Action != ActionDelete {
+		t.Fatalf("managed task missing from files should be deleted: %+v", it)
+	}
+	if itemByName(plan, "manual") != nil || len(plan.Unmanaged) != 1 || plan.Unmanaged[0] != "manual" {
+		t.Fatalf("unmanaged task should be kept without prune: %+v", plan)
+	}
+	if plan.Summary[ActionUpdate] != 1 || plan.Summary[ActionDelete] != 1 || plan.Summary[ActionUnchanged] != 1 {
+		t.Fatalf("unexpected summary: %+v", plan.Summary)
+	}
+
+	pruned := Compute(specs, current, map[int64][]string{}, true)
+	if it := itemByName(pruned, "manual"); it == nil || it.Action != ActionDelete || len(pruned.Unmanaged) != 0 {
+		t.Fatalf("unmanaged task should be deleted with prune: %+v", pruned)
+	}
+}
+
+func TestComputeUpstreamErrors(t *testing.T) {
+	specs, err := Load(map[string][]byte{"dag.yaml": []byte(`
+name: a
+schedule_mode: BOTH
+cron_expr: "@daily"
+target_path: /a
+upstream_tasks: [b]
+---
+name: b
+schedule_mode: DEPENDENCY
+target_path: /b
+upstream_tasks: [a]
+---
+name: c
+schedule_mode: DEPENDENCY
+target_path: /c
+upstream_tasks: [gone]
+---
+name: d
+schedule_mode: DEPENDENCY
+target_path: /d
+upstream_tasks: [manual]
+`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := []*model.Task{{ID: 9, Name: "gone", Managed: true}, {ID: 10, Name: "manual"}}
+	plan := Compute(specs, current, nil, false)
+	if plan.Valid {
+		t.Fatal("expected invalid plan")
+	}
+	if it := itemByName(plan, "a"); !strings.Contains(it.Error, "dependency cycle") {
+		t.Fatalf("expected cycle error on a, got %q", it.Error)
+	}
+	if it := itemByName(plan, "c"); !strings.Contains(it.Error, `unknown upstream task "gone"`) {
+		t.Fatalf("expected unknown upstream on c, got %q", it.Error)
+	}
+	if it := itemByName(plan, "d"); it.Error != "" {
+		t.Fatalf("kept unmanaged upstream should be allowed, got %q", it.Error)
+	}
+}
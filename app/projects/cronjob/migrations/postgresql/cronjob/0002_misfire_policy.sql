@@ -0,0 +1,23 @@
+-- Migration: per-task misfire policy + persisted scheduler evaluation cursor
+
+CREATE TYPE misfire_policy_enum AS ENUM ('SKIP', 'FIRE_ONCE', 'FIRE_ALL');
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS misfire_policy misfire_policy_enum NOT NULL DEFAULT 'SKIP';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS misfire_grace_sec INTEGER NOT NULL DEFAULT 0;
+-- 调度器已评估到的时间点（UTC）；NULL 表示从未评估，启动时从当前时间开始
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS last_evaluated_at TIMESTAMP;
+
+-- 仅推进评估游标的更新不应刷新 tasks.updated_at（调度器每个 tick 都会写游标）
+CREATE OR REPLACE FUNCTION update_tasks_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (to_jsonb(NEW) - 'last_evaluated_at' - 'updated_at') IS DISTINCT FROM (to_jsonb(OLD) - 'last_evaluated_at' - 'updated_at') THEN
+        NEW.updated_at = CURRENT_TIMESTAMP;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS update_tasks_updated_at ON tasks;
+CREATE TRIGGER update_tasks_updated_at BEFORE UPDATE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION update_tasks_updated_at_column();
@@ -0,0 +1,16 @@
+-- 多副本选主：scheduler_locks 增加任期与取得时间；确保 task_runs 的 (task_id, scheduled_time) 唯一约束存在，
+-- 使同一触发点的 Run 创建幂等（脑裂期间两个副本同时 tick 也只会落一条）。
+
+ALTER TABLE scheduler_locks ADD COLUMN IF NOT EXISTS term BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE scheduler_locks ADD COLUMN IF NOT EXISTS acquired_at TIMESTAMP NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'uniq_task_schedule' AND conrelid = 'task_runs'::regclass
+    ) THEN
+        ALTER TABLE task_runs ADD CONSTRAINT uniq_task_schedule UNIQUE (task_id, scheduled_time);
+    END IF;
+END;
+$$;
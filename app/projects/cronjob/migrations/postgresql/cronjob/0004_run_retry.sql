@@ -0,0 +1,14 @@
+-- 任务级重试：重试 Run 通过 retry_of 关联链路中的首个 Run，retry_index 表示第几次重试。
+-- 重试 Run 沿用原触发点的 scheduled_time，因此 (task_id, scheduled_time) 唯一约束只约束非重试 Run；
+-- 重试 Run 以 (retry_of, retry_index) 唯一，多个副本的扫描器同时派发也只会落一条。
+
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS retry_of BIGINT NULL;
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS retry_index INT NOT NULL DEFAULT 0;
+
+ALTER TABLE task_runs DROP CONSTRAINT IF EXISTS uniq_task_schedule;
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_task_schedule ON task_runs(task_id, scheduled_time) WHERE retry_of IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_retry_attempt ON task_runs(retry_of, retry_index) WHERE retry_of IS NOT NULL;
+
+-- 扫描器按到期时间查找待重试 Run
+DROP INDEX IF EXISTS idx_next_retry;
+CREATE INDEX IF NOT EXISTS idx_next_retry ON task_runs(next_retry_time) WHERE next_retry_time IS NOT NULL;
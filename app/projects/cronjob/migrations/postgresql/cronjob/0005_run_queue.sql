@@ -0,0 +1,18 @@
+-- QUEUE 并发策略：超过 max_concurrency 的 Run 以 QUEUED 状态持久化，槽位空出后 FIFO 启动，重启不丢失。
+-- 注意：新增的枚举值在同一事务内不能使用，本文件不引用 'QUEUED'。
+
+ALTER TYPE run_status_enum ADD VALUE IF NOT EXISTS 'QUEUED' BEFORE 'RUNNING';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'queue_overflow_enum') THEN
+        CREATE TYPE queue_overflow_enum AS ENUM ('DROP_NEW', 'DROP_OLDEST');
+    END IF;
+END;
+$$;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS queue_max_depth INTEGER NOT NULL DEFAULT 100;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS queue_overflow queue_overflow_enum NOT NULL DEFAULT 'DROP_NEW';
+
+-- 按任务查找排队 / 占用槽位的 Run
+CREATE INDEX IF NOT EXISTS idx_task_status_sched ON task_runs(task_id, status, scheduled_time);
@@ -0,0 +1,54 @@
+-- 任务依赖（DAG）：任务可声明上游任务与触发规则，在上游完成同一 logical_date 的运行后触发。
+-- logical_date：Run 所属的业务日期（cron 触发取 scheduled_time 在任务时区下的日期，依赖触发沿用上游的日期）。
+-- dag_generation：同一 logical_date 的第几轮；"从该节点向下游重跑" 会开启新一轮，下游按轮次去重。
+-- dag_resolved：Run 结束后由依赖解析器处理（评估下游）后置为 TRUE；存量 Run 直接视为已处理。
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'schedule_mode_enum') THEN
+        CREATE TYPE schedule_mode_enum AS ENUM ('CRON', 'DEPENDENCY', 'BOTH');
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'trigger_rule_enum') THEN
+        CREATE TYPE trigger_rule_enum AS ENUM ('ALL_SUCCESS', 'ALL_DONE', 'ONE_SUCCESS');
+    END IF;
+END;
+$$;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS schedule_mode schedule_mode_enum NOT NULL DEFAULT 'CRON';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS trigger_rule trigger_rule_enum NOT NULL DEFAULT 'ALL_SUCCESS';
+
+CREATE TABLE IF NOT EXISTS task_dependencies (
+  task_id BIGINT NOT NULL,
+  upstream_task_id BIGINT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (task_id, upstream_task_id),
+  CONSTRAINT chk_dep_not_self CHECK (task_id <> upstream_task_id),
+  CONSTRAINT fk_dep_task FOREIGN KEY (task_id) REFERENCES tasks(id),
+  CONSTRAINT fk_dep_upstream FOREIGN KEY (upstream_task_id) REFERENCES tasks(id)
+);
+CREATE INDEX IF NOT EXISTS idx_dep_upstream ON task_dependencies(upstream_task_id);
+
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS logical_date DATE NULL;
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS trigger_type VARCHAR(16) NOT NULL DEFAULT 'CRON';
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS dag_generation INT NOT NULL DEFAULT 0;
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS dag_resolved BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE task_runs ALTER COLUMN dag_resolved SET DEFAULT FALSE;
+
+-- 依赖触发的 Run 的 scheduled_time 取触发时刻，不参与 (task_id, scheduled_time) 去重，改按 (task_id, logical_date, dag_generation) 去重
+DROP INDEX IF EXISTS uniq_task_schedule;
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_task_schedule ON task_runs(task_id, scheduled_time)
+    WHERE retry_of IS NULL AND trigger_type NOT IN ('DEPENDENCY', 'RERUN');
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_dag_run ON task_runs(task_id, logical_date, dag_generation)
+    WHERE retry_of IS NULL AND trigger_type IN ('DEPENDENCY', 'RERUN');
+CREATE INDEX IF NOT EXISTS idx_task_logical_date ON task_runs(task_id, logical_date);
+CREATE INDEX IF NOT EXISTS idx_dag_unresolved ON task_runs(id) WHERE NOT dag_resolved;
+
+-- Run 级血缘：下游 Run 由哪些上游 Run 触发
+CREATE TABLE IF NOT EXISTS run_lineage (
+  run_id BIGINT NOT NULL,
+  upstream_run_id BIGINT NOT NULL,
+  PRIMARY KEY (run_id, upstream_run_id),
+  CONSTRAINT fk_lineage_run FOREIGN KEY (run_id) REFERENCES task_runs(id) ON DELETE CASCADE,
+  CONSTRAINT fk_lineage_upstream FOREIGN KEY (upstream_run_id) REFERENCES task_runs(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_lineage_upstream ON run_lineage(upstream_run_id);
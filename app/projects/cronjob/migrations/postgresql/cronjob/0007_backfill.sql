@@ -0,0 +1,40 @@
+-- 历史区间补跑：按批次（backfills）对区间内每个触发点派发 Run，Run 通过 backfill_id 归属批次，可整体取消。
+-- 补跑 Run 的 scheduled_time 为历史触发点，与原调度 Run 相同，因此不参与 uniq_task_schedule，改按 (backfill_id, scheduled_time) 去重。
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'backfill_status_enum') THEN
+        CREATE TYPE backfill_status_enum AS ENUM ('RUNNING', 'COMPLETED', 'CANCELED');
+    END IF;
+END;
+$$;
+
+CREATE TABLE IF NOT EXISTS backfills (
+  id BIGSERIAL PRIMARY KEY,
+  task_id BIGINT NOT NULL,
+  range_start TIMESTAMP NOT NULL,
+  range_end TIMESTAMP NOT NULL,
+  max_parallel INT NOT NULL DEFAULT 1,
+  skip_succeeded BOOLEAN NOT NULL DEFAULT FALSE,
+  status backfill_status_enum NOT NULL DEFAULT 'RUNNING',
+  last_fire_time TIMESTAMP NULL,
+  total INT NOT NULL DEFAULT 0,
+  dispatched INT NOT NULL DEFAULT 0,
+  skipped INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  finished_at TIMESTAMP NULL,
+  CONSTRAINT chk_backfill_range CHECK (range_start <= range_end),
+  CONSTRAINT fk_backfill_task FOREIGN KEY (task_id) REFERENCES tasks(id)
+);
+CREATE INDEX IF NOT EXISTS idx_backfill_task ON backfills(task_id, id);
+CREATE INDEX IF NOT EXISTS idx_backfill_running ON backfills(id) WHERE status = 'RUNNING';
+
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS backfill_id BIGINT NULL;
+
+DROP INDEX IF EXISTS uniq_task_schedule;
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_task_schedule ON task_runs(task_id, scheduled_time)
+    WHERE retry_of IS NULL AND trigger_type NOT IN ('DEPENDENCY', 'RERUN', 'BACKFILL');
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_backfill_run ON task_runs(backfill_id, scheduled_time)
+    WHERE retry_of IS NULL AND backfill_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_run_backfill ON task_runs(backfill_id, status) WHERE backfill_id IS NOT NULL;
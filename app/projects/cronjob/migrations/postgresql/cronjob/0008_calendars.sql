@@ -0,0 +1,48 @@
+-- 营业日历与停摆窗口：任务可引用命名日历（如交易所交易日历），按日历决定是否触发或顺延；
+-- 停摆窗口内 cron 触发被跳过。日历可由本地文件导入，也可通过 API 维护（无需发版即可更新来年节假日）。
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'calendar_kind_enum') THEN
+        CREATE TYPE calendar_kind_enum AS ENUM ('HOLIDAYS', 'TRADING_DAYS');
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'calendar_mode_enum') THEN
+        CREATE TYPE calendar_mode_enum AS ENUM ('BUSINESS_DAYS', 'NON_BUSINESS_DAYS', 'NEXT_BUSINESS_DAY');
+    END IF;
+END;
+$$;
+
+CREATE TABLE IF NOT EXISTS calendars (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(64) NOT NULL,
+  description VARCHAR(512) NOT NULL DEFAULT '',
+  kind calendar_kind_enum NOT NULL DEFAULT 'HOLIDAYS',
+  source VARCHAR(8) NOT NULL DEFAULT 'API',
+  version INT NOT NULL DEFAULT 1,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uniq_calendar_name UNIQUE (name)
+);
+
+CREATE TABLE IF NOT EXISTS calendar_dates (
+  calendar_id BIGINT NOT NULL,
+  date DATE NOT NULL,
+  PRIMARY KEY (calendar_id, date),
+  CONSTRAINT fk_calendar_date FOREIGN KEY (calendar_id) REFERENCES calendars(id) ON DELETE CASCADE
+);
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS calendar VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS calendar_mode calendar_mode_enum NOT NULL DEFAULT 'BUSINESS_DAYS';
+
+CREATE TABLE IF NOT EXISTS blackout_windows (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(128) NOT NULL,
+  task_id BIGINT NULL,
+  start_time TIMESTAMP NOT NULL,
+  end_time TIMESTAMP NOT NULL,
+  reason VARCHAR(512) NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT chk_blackout_range CHECK (start_time < end_time),
+  CONSTRAINT fk_blackout_task FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_blackout_end ON blackout_windows(end_time);
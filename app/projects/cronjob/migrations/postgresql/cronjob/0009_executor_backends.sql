@@ -0,0 +1,18 @@
+-- 可插拔执行器后端：除 HTTP 外支持 gRPC unary 调用、本机命令与 Redis Stream 发布。
+-- executor_config 保存后端专属参数（命令参数、环境变量、超时、Stream 长度上限等），Run 创建时随任务一起快照。
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'executor_kind_enum') THEN
+        CREATE TYPE executor_kind_enum AS ENUM ('HTTP', 'GRPC', 'COMMAND', 'REDIS_STREAM');
+    END IF;
+END;
+$$;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS executor executor_kind_enum NOT NULL DEFAULT 'HTTP';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS executor_config JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS executor executor_kind_enum NOT NULL DEFAULT 'HTTP';
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS executor_config JSONB NOT NULL DEFAULT '{}'::jsonb;
+-- 命令执行的 stderr（stdout 记入 response_body，退出码记入 response_code）
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS stderr TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+-- 异步回调鉴权与审计：每个 Run 创建时生成随机 callback_token，随 meta 下发，回调须携带；
+-- 每次回调（含被拒绝与重复的）记入 async_callbacks，记录来源 IP 与处理结果。
+
+ALTER TABLE async_callbacks ADD COLUMN IF NOT EXISTS source_ip VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE async_callbacks ADD COLUMN IF NOT EXISTS result VARCHAR(32) NOT NULL DEFAULT '';
+-- accepted / duplicate / conflict / not_pending / invalid_token / invalid_payload / error
+ALTER TABLE async_callbacks ADD COLUMN IF NOT EXISTS outcome VARCHAR(32) NOT NULL DEFAULT '';
+
+-- Run 清理时一并删除回调记录
+ALTER TABLE async_callbacks DROP CONSTRAINT IF EXISTS fk_callbacks_run;
+ALTER TABLE async_callbacks ADD CONSTRAINT fk_callbacks_run FOREIGN KEY (task_run_id) REFERENCES task_runs(id) ON DELETE CASCADE;
@@ -0,0 +1,17 @@
+-- 持久化 Run 进度与日志：进度快照写在 task_runs 上（重启与其他副本可见），
+-- 日志为只追加的 run_logs，由执行方经 POST /api/v1/runs/{id}/logs 上报，随 Run 清理级联删除。
+
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS progress_current BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS progress_total BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS progress_message TEXT NOT NULL DEFAULT '';
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS progress_updated_at TIMESTAMP NULL;
+CREATE INDEX IF NOT EXISTS idx_task_runs_progress_updated ON task_runs(progress_updated_at) WHERE progress_updated_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS run_logs (
+  id BIGSERIAL PRIMARY KEY,
+  task_run_id BIGINT NOT NULL REFERENCES task_runs(id) ON DELETE CASCADE,
+  ts TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  level VARCHAR(16) NOT NULL DEFAULT 'INFO',
+  line TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_run_logs_run ON run_logs(task_run_id, id);
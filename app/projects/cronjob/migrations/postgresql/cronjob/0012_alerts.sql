@@ -0,0 +1,42 @@
+-- Run 告警：任务级规则（alert_rules）由 leader 周期性评估，结果经配置的通知渠道发送并记入 alert_history。
+-- alert_evaluated：Run 结束后经告警规则评估后置为 TRUE；存量 Run 直接视为已评估，避免上线时补发历史告警。
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+  id BIGSERIAL PRIMARY KEY,
+  task_id BIGINT NOT NULL REFERENCES tasks(id),
+  -- FAILURE / CONSECUTIVE_FAILURES / SLOW_RUN / SLA_MISSED / RECOVERY
+  kind VARCHAR(32) NOT NULL,
+  threshold INT NOT NULL DEFAULT 0,
+  factor DOUBLE PRECISION NOT NULL DEFAULT 0,
+  min_samples INT NOT NULL DEFAULT 0,
+  sla_sec INT NOT NULL DEFAULT 0,
+  channels TEXT NOT NULL DEFAULT '[]',
+  suppress_sec INT NOT NULL DEFAULT 0,
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  description VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_task ON alert_rules(task_id);
+
+CREATE TABLE IF NOT EXISTS alert_history (
+  id BIGSERIAL PRIMARY KEY,
+  rule_id BIGINT NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+  task_id BIGINT NOT NULL,
+  run_id BIGINT NULL,
+  kind VARCHAR(32) NOT NULL,
+  dedup_key VARCHAR(128) NOT NULL DEFAULT '',
+  channel VARCHAR(64) NOT NULL DEFAULT '',
+  -- SENT / SUPPRESSED / FAILED
+  status VARCHAR(16) NOT NULL,
+  title VARCHAR(512) NOT NULL DEFAULT '',
+  message TEXT NOT NULL DEFAULT '',
+  error TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_alert_history_task ON alert_history(task_id, id);
+CREATE INDEX IF NOT EXISTS idx_alert_history_dedup ON alert_history(rule_id, dedup_key, created_at);
+
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS alert_evaluated BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE task_runs ALTER COLUMN alert_evaluated SET DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_alert_unevaluated ON task_runs(id) WHERE NOT alert_evaluated;
@@ -0,0 +1,19 @@
+-- 任务版本历史：每次创建、修改、启停、删除与回滚写入一条不可变记录（完整快照 + 字段级 diff）。
+-- 存量任务在升级后首次变更时补记一条 BASELINE 版本。
+-- task_runs.task_version：Run 创建时任务的版本；升级前创建的 Run 为 0。
+
+CREATE TABLE IF NOT EXISTS task_revisions (
+  id BIGSERIAL PRIMARY KEY,
+  task_id BIGINT NOT NULL,
+  version INT NOT NULL,
+  -- BASELINE / CREATE / UPDATE / ENABLE / DISABLE / DELETE / ROLLBACK
+  action VARCHAR(16) NOT NULL,
+  actor VARCHAR(128) NOT NULL DEFAULT '',
+  note VARCHAR(255) NOT NULL DEFAULT '',
+  snapshot TEXT NOT NULL,
+  diff TEXT NOT NULL DEFAULT '[]',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_task_revisions_task ON task_revisions(task_id, version);
+
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS task_version INT NOT NULL DEFAULT 0;
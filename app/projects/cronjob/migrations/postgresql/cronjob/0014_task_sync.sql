@@ -0,0 +1,5 @@
+-- 声明式任务同步：由 task_sync.dir 下 YAML 文件管理的任务标记为 managed，API 侧只读。
+-- sync_source：声明该任务的文件（相对目录）；非托管任务为空。
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS managed BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS sync_source VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+-- 任务级执行超时与取消升级：
+--   - tasks.execution_timeout_sec：单次执行超时（从开始执行计，ASYNC 含等待回调），Run 创建时快照到 task_runs
+--   - 取消先记录原因与时间（cancel_reason / cancel_requested_at）并请求下游协作取消，
+--     宽限期（executor.kill_grace）内未结束的 Run 标记为 KILLED
+
+ALTER TYPE run_status_enum ADD VALUE IF NOT EXISTS 'KILLED';
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS execution_timeout_sec INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS execution_timeout_sec INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS cancel_reason VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS cancel_requested_at TIMESTAMP;
+
+-- 扫描待升级的取消请求
+CREATE INDEX IF NOT EXISTS idx_task_runs_cancel_requested ON task_runs(cancel_requested_at) WHERE cancel_requested_at IS NOT NULL;
@@ -0,0 +1,29 @@
+-- 命名空间与任务归属：任务归属于命名空间（团队 / 项目），空间可限定任务数、并发 Run 数与可调用的 target_service；
+-- 任务可声明 owners（可管理该任务的操作人）与 labels（键值标签，供列表 / 导出 / 批量操作按选择器筛选）。
+-- 存量任务归入 default 空间；task_runs.namespace 为创建时快照，用于按空间统计并发。
+
+CREATE TABLE IF NOT EXISTS namespaces (
+  name VARCHAR(64) PRIMARY KEY,
+  description VARCHAR(512) NOT NULL DEFAULT '',
+  owners TEXT NOT NULL DEFAULT '[]',
+  max_tasks INT NOT NULL DEFAULT 0,
+  max_concurrent_runs INT NOT NULL DEFAULT 0,
+  allowed_target_services TEXT NOT NULL DEFAULT '[]',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO namespaces (name, description) VALUES ('default', 'default namespace') ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS namespace VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS owners JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_namespace ON tasks(namespace) WHERE deleted = 0;
+CREATE INDEX IF NOT EXISTS idx_tasks_labels ON tasks USING GIN (labels);
+CREATE INDEX IF NOT EXISTS idx_tasks_owners ON tasks USING GIN (owners);
+
+ALTER TABLE task_runs ADD COLUMN IF NOT EXISTS namespace VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- 按空间统计执行中的 Run（并发配额）
+CREATE INDEX IF NOT EXISTS idx_task_runs_namespace_active ON task_runs(namespace) WHERE status IN ('RUNNING', 'CALLBACK_PENDING');
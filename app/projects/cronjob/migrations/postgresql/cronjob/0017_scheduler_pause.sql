@@ -0,0 +1,16 @@
+-- 调度器全局暂停：单行状态表，所有副本共享。暂停期间 leader 不再产生 cron / 依赖 / 补跑 / 重试触发，
+-- 已创建的 Run 照常执行；paused_until 非空时到期自动恢复（resumed_by = 'auto'）。
+
+CREATE TABLE IF NOT EXISTS scheduler_pause (
+  id INT PRIMARY KEY CHECK (id = 1),
+  paused BOOLEAN NOT NULL DEFAULT FALSE,
+  paused_until TIMESTAMP NULL,
+  reason VARCHAR(512) NOT NULL DEFAULT '',
+  paused_by VARCHAR(128) NOT NULL DEFAULT '',
+  paused_at TIMESTAMP NULL,
+  resumed_by VARCHAR(128) NOT NULL DEFAULT '',
+  resumed_at TIMESTAMP NULL,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO scheduler_pause (id) VALUES (1) ON CONFLICT (id) DO NOTHING;
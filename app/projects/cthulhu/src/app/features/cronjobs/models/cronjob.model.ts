@@ -55,7 +55,6 @@ export interface TaskRun {
   response_body: string;
   error_message: string;
   next_retry_time?: string | null;
-  callback_token: string;
   callback_deadline?: string | null;
   trace_id: string;
   created_at: string;
@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNotFound is returned by a loader to signal "no value"; GetOrLoad caches it
+// for LoadOptions.NegativeTTL and returns it to subsequent callers without hitting the source.
+var ErrNotFound = errors.New("cache: not found")
+
+// LoadOptions configures a single GetOrLoad call.
+type LoadOptions struct {
+	TTL         time.Duration // lifetime of a positive entry
+	NegativeTTL time.Duration // lifetime of an ErrNotFound entry; 0 disables negative caching
+	Tags        []string      // tags the entry is registered under for InvalidateTags
+	Bypass      bool          // skip the read path (still refreshes the cache)
+}
+
+// envelope is the stored representation: the value plus what XFetch needs.
+type envelope struct {
+	Value     json.RawMessage `json:"v,omitempty"`
+	Negative  bool            `json:"n,omitempty"`
+	ExpiresAt int64           `json:"e"` // logical expiry, unix millis
+	DeltaMS   int64           `json:"d"` // observed load cost, millis
+}
+
+func (e envelope) expiresAt() time.Time { return time.UnixMilli(e.ExpiresAt) }
+
+// errInvalidEnvelope marks a payload that is not an envelope, e.g. a bare value written
+// before GetOrLoad existed; readers treat it as a miss and reload.
+var errInvalidEnvelope = errors.New("cache: invalid envelope")
+
+func decodeEnvelope(payload []byte) (envelope, error) {
+	var env envelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		return env, err
+	}
+	if env.ExpiresAt <= 0 || (!env.Negative && len(env.Value) == 0) {
+		return env, errInvalidEnvelope
+	}
+	return env, nil
+}
+
+// shouldRefreshEarly implements XFetch (Vattani et al.): the closer to expiry and the more
+// expensive the load, the more likely a single caller recomputes ahead of time, so a hot key
+// never expires under all readers at once.
+func shouldRefreshEarly(env envelope, beta float64, now time.Time) bool {
+	if beta <= 0 || env.DeltaMS <= 0 {
+		return false
+	}
+	gap := float64(env.DeltaMS) * beta * -math.Log(rand.Float64())
+	return now.UnixMilli()+int64(gap) >= env.ExpiresAt
+}
+
+// GetOrLoad returns the cached value for key, or calls load on a miss and caches the result.
+// Concurrent misses for the same key on one replica share a single load (singleflight).
+// A nil Store (or one without Redis) simply calls load.
+func GetOrLoad[T any](ctx context.Context, s *Store, key string, opts LoadOptions, load func(context.Context) (T, error)) (T, error) {
+	var zero T
+	if s == nil || key == "" || opts.TTL <= 0 || (s.Client() == nil && s.l1 == nil) {
+		return load(ctx)
+	}
+
+	var stale *envelope
+	if !opts.Bypass {
+		payload, hit, err := s.readRaw(ctx, key)
+		if err == nil && hit {
+			// Anything that does not decode (legacy payloads, a changed value type) is a miss.
+			if env, err := decodeEnvelope(payload); err == nil {
+				if v, err := decodeValue[T](env); err == nil || errors.Is(err, ErrNotFound) {
+					if !shouldRefreshEarly(env, s.cfg.EarlyRefreshBeta, time.Now()) {
+						return v, err
+					}
+					stale = &env
+				}
+			}
+		}
+	}
+
+	// The shared load runs detached from the first caller's context, so one caller
+	// giving up does not fail every waiter; each caller still stops waiting on its own ctx.
+	ch := s.group.DoChan(key, func() (interface{}, error) {
+		return s.loadAndStore(context.WithoutCancel(ctx), key, opts, func(ctx context.Context) (any, error) { return load(ctx) })
+	})
+	var res singleflight.Result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+	if err := res.Err; err != nil {
+		// Early refresh failed: keep serving the value we already have.
+		if stale != nil && !errors.Is(err, ErrNotFound) {
+			return decodeValue[T](*stale)
+		}
+		return zero, err
+	}
+	env, err := decodeEnvelope(res.Val.([]byte))
+	if err != nil {
+		return zero, err
+	}
+	return decodeValue[T](env)
+}
+
+func (s *Store) loadAndStore(ctx context.Context, key string, opts LoadOptions, load func(context.Context) (any, error)) ([]byte, error) {
+	start := time.Now()
+	value, err := load(ctx)
+	delta := time.Since(start)
+
+	env := envelope{DeltaMS: delta.Milliseconds()}
+	ttl := opts.TTL
+	switch {
+	case errors.Is(err, ErrNotFound):
+		if opts.NegativeTTL <= 0 {
+			return nil, err
+		}
+		env.Negative = true
+		ttl = opts.NegativeTTL
+	case err != nil:
+		return nil, err
+	default:
+		raw, mErr := json.Marshal(value)
+		if mErr != nil {
+			return nil, mErr
+		}
+		env.Value = raw
+	}
+	env.ExpiresAt = time.Now().Add(ttl).UnixMilli()
+
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+	// A cache write failure must not fail the read path; the value is still returned.
+	_ = s.writeRaw(ctx, key, payload, ttl, opts.Tags)
+	if env.Negative {
+		return nil, ErrNotFound
+	}
+	return payload, nil
+}
+
+func decodeValue[T any](env envelope) (T, error) {
+	var out T
+	if env.Negative {
+		return out, ErrNotFound
+	}
+	if err := json.Unmarshal(env.Value, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newLocalOnlyStore() *Store {
+	cfg := DefaultConfig()
+	cfg.L1Enabled = true
+	cfg.EarlyRefreshBeta = 0
+	return NewStore(cfg)
+}
+
+func TestGetOrLoadCoalescesConcurrentMisses(t *testing.T) {
+	s := newLocalOnlyStore()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := GetOrLoad(context.Background(), s, "k", LoadOptions{TTL: time.Minute}, load)
+			if err != nil || v != 42 {
+				t.Errorf("GetOrLoad = %d, %v", v, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected 1 load, got %d", n)
+	}
+	if v, err := GetOrLoad(context.Background(), s, "k", LoadOptions{TTL: time.Minute}, load); err != nil || v != 42 {
+		t.Fatalf("cached GetOrLoad = %d, %v", v, err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected cached hit, loader called %d times", n)
+	}
+}
+
+func TestGetOrLoadNegativeCaching(t *testing.T) {
+	s := newLocalOnlyStore()
+	var calls atomic.Int32
+	load := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return "", ErrNotFound
+	}
+	opts := LoadOptions{TTL: time.Minute, NegativeTTL: time.Minute}
+	for i := 0; i < 3; i++ {
+		if _, err := GetOrLoad(context.Background(), s, "missing", opts, load); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected negative entry to be cached, loader called %d times", n)
+	}
+}
+
+func TestGetOrLoadServesStaleOnFailedEarlyRefresh(t *testing.T) {
+	s := newLocalOnlyStore()
+	opts := LoadOptions{TTL: time.Minute}
+	if _, err := GetOrLoad(context.Background(), s, "k", opts, func(context.Context) (int, error) { return 7, nil }); err != nil {
+		t.Fatal(err)
+	}
+	// Force every read to trigger an early refresh.
+	s.cfg.EarlyRefreshBeta = 1e12
+	payload, _, _ := s.readRaw(context.Background(), "k")
+	env, _ := decodeEnvelope(payload)
+	env.DeltaMS = 1
+	raw, _ := json.Marshal(env)
+	s.l1.set("k", raw, time.Now().Add(time.Minute))
+
+	v, err := GetOrLoad(context.Background(), s, "k", opts, func(context.Context) (int, error) { return 0, errors.New("db down") })
+	if err != nil || v != 7 {
+		t.Fatalf("expected stale value 7, got %d, %v", v, err)
+	}
+}
+
+func TestGetOrLoadTreatsLegacyPayloadAsMiss(t *testing.T) {
+	s := newLocalOnlyStore()
+	// A bare value as written by the old SetJSON, without the envelope.
+	s.l1.set("k", []byte(`{"domain":"financial_statement","fields":["a"]}`), time.Now().Add(time.Minute))
+
+	type fields struct {
+		Domain string   `json:"domain"`
+		Fields []string `json:"fields"`
+	}
+	var calls atomic.Int32
+	v, err := GetOrLoad(context.Background(), s, "k", LoadOptions{TTL: time.Minute}, func(context.Context) (fields, error) {
+		calls.Add(1)
+		return fields{Domain: "financial_statement", Fields: []string{"a", "b"}}, nil
+	})
+	if err != nil || len(v.Fields) != 2 || calls.Load() != 1 {
+		t.Fatalf("expected a reload, got %+v, %v (loads=%d)", v, err, calls.Load())
+	}
+}
+
+func TestGetOrLoadCancelledCallerDoesNotFailWaiters(t *testing.T) {
+	s := newLocalOnlyStore()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func(ctx context.Context) (int, error) {
+		close(started)
+		<-release
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		return 42, nil
+	}
+
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := GetOrLoad(firstCtx, s, "k", LoadOptions{TTL: time.Minute}, load)
+		firstErr <- err
+	}()
+	<-started
+
+	type result struct {
+		v   int
+		err error
+	}
+	second := make(chan result, 1)
+	go func() {
+		v, err := GetOrLoad(context.Background(), s, "k", LoadOptions{TTL: time.Minute}, load)
+		second <- result{v, err}
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled caller: expected context.Canceled, got %v", err)
+	}
+	close(release)
+	if r := <-second; r.err != nil || r.v != 42 {
+		t.Fatalf("waiter: expected 42, got %d, %v", r.v, r.err)
+	}
+}
+
+func TestLocalLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLocalLRU(2)
+	now := time.Now()
+	exp := now.Add(time.Minute)
+	c.set("a", []byte("1"), exp)
+	c.set("b", []byte("2"), exp)
+	c.get("a", now)
+	c.set("c", []byte("3"), exp)
+	if _, ok := c.get("b", now); ok {
+		t.Fatalf("expected b to be evicted")
+	}
+	if _, ok := c.get("a", now); !ok {
+		t.Fatalf("expected a to survive")
+	}
+	if c.len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", c.len())
+	}
+}
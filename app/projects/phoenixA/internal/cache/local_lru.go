@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// localLRU is a size-bounded in-process L1 tier in front of Redis.
+// Entries carry their own expiry so L1 never outlives the Redis copy it mirrors.
+type localLRU struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type localEntry struct {
+	key       string
+	payload   []byte
+	expiresAt time.Time
+}
+
+func newLocalLRU(capacity int) *localLRU {
+	if capacity <= 0 {
+		capacity = 1024
+	}
+	return &localLRU{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element, capacity),
+	}
+}
+
+func (c *localLRU) get(key string, now time.Time) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*localEntry)
+	if !now.Before(entry.expiresAt) {
+		c.removeElement(el)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return entry.payload, true
+}
+
+func (c *localLRU) set(key string, payload []byte, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*localEntry)
+		entry.payload = payload
+		entry.expiresAt = expiresAt
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&localEntry{key: key, payload: payload, expiresAt: expiresAt})
+	for c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *localLRU) delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.removeElement(el)
+		}
+	}
+}
+
+func (c *localLRU) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *localLRU) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*localEntry).key)
+}
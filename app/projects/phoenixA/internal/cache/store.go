@@ -0,0 +1,238 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	infraRedis "github.com/grand-thief-cash/chaos/app/infra/go/application/components/redis"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/consts"
+	redislib "github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// Config controls the cache-aside Store.
+type Config struct {
+	L1Enabled        bool          // enable the in-process LRU tier
+	L1MaxEntries     int           // LRU capacity (entries)
+	L1TTL            time.Duration // upper bound for an L1 entry; the Redis expiry still applies
+	EarlyRefreshBeta float64       // XFetch beta; 0 disables probabilistic early refresh
+	TagTTL           time.Duration // lifetime of tag sets; must exceed the longest entry TTL
+}
+
+// DefaultConfig returns sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		L1Enabled:        false,
+		L1MaxEntries:     4096,
+		L1TTL:            30 * time.Second,
+		EarlyRefreshBeta: 1.0,
+		TagTTL:           31 * 24 * time.Hour,
+	}
+}
+
+// Store is the cache-aside entry point: singleflight-coalesced loads, probabilistic
+// early refresh, negative caching, tag-based invalidation and an optional L1 tier
+// kept coherent across replicas through Redis pub/sub.
+//
+// A Store without Redis (redis component disabled) degrades to calling the loader directly.
+type Store struct {
+	*core.BaseComponent
+	RedisComp *infraRedis.RedisComponent `infra:"dep:redis?"`
+
+	cfg    Config
+	nodeID string
+	l1     *localLRU
+	group  singleflight.Group
+
+	pubsub *redislib.PubSub
+	wg     sync.WaitGroup
+}
+
+// invalidationMessage is published on bizConsts.RedisCacheInvalidationChannel.
+type invalidationMessage struct {
+	Node string   `json:"node"`
+	Keys []string `json:"keys"`
+}
+
+func NewStore(cfg Config) *Store {
+	s := &Store{
+		BaseComponent: core.NewBaseComponent(bizConsts.COMP_CACHE_STORE, consts.COMPONENT_LOGGING),
+		cfg:           cfg,
+		nodeID:        randomNodeID(),
+	}
+	if cfg.L1Enabled {
+		s.l1 = newLocalLRU(cfg.L1MaxEntries)
+	}
+	if s.cfg.TagTTL <= 0 {
+		s.cfg.TagTTL = DefaultConfig().TagTTL
+	}
+	return s
+}
+
+func (s *Store) Start(ctx context.Context) error {
+	if err := s.BaseComponent.Start(ctx); err != nil {
+		return err
+	}
+	client := s.Client()
+	if client == nil || s.l1 == nil {
+		return nil
+	}
+	s.pubsub = client.Subscribe(context.Background(), bizConsts.RedisCacheInvalidationChannel)
+	s.wg.Add(1)
+	go s.listenInvalidations(s.pubsub.Channel())
+	logging.Infof(ctx, "cache store L1 enabled (max_entries=%d ttl=%s)", s.cfg.L1MaxEntries, s.cfg.L1TTL)
+	return nil
+}
+
+func (s *Store) Stop(ctx context.Context) error {
+	defer s.BaseComponent.Stop(ctx)
+	if s.pubsub != nil {
+		_ = s.pubsub.Close()
+		s.wg.Wait()
+	}
+	return nil
+}
+
+// Client returns the underlying Redis client, or nil when Redis is disabled.
+func (s *Store) Client() redislib.UniversalClient {
+	if s == nil || s.RedisComp == nil {
+		return nil
+	}
+	return s.RedisComp.Client()
+}
+
+func (s *Store) listenInvalidations(ch <-chan *redislib.Message) {
+	defer s.wg.Done()
+	for msg := range ch {
+		var m invalidationMessage
+		if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil {
+			continue
+		}
+		if m.Node == s.nodeID {
+			continue // already evicted locally before publishing
+		}
+		s.l1.delete(m.Keys...)
+	}
+}
+
+// Delete removes keys from Redis and every replica's L1.
+func (s *Store) Delete(ctx context.Context, keys ...string) error {
+	if s == nil {
+		return nil
+	}
+	filtered := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key != "" {
+			filtered = append(filtered, key)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil
+	}
+	if s.l1 != nil {
+		s.l1.delete(filtered...)
+	}
+	client := s.Client()
+	if client == nil {
+		return nil
+	}
+	// One DEL per key keeps the pipeline valid in cluster mode (no CROSSSLOT).
+	pipe := client.Pipeline()
+	for _, key := range filtered {
+		pipe.Del(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	return s.publishInvalidation(ctx, filtered)
+}
+
+func (s *Store) publishInvalidation(ctx context.Context, keys []string) error {
+	client := s.Client()
+	if s.l1 == nil || client == nil || len(keys) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(invalidationMessage{Node: s.nodeID, Keys: keys})
+	if err != nil {
+		return err
+	}
+	return client.Publish(ctx, bizConsts.RedisCacheInvalidationChannel, payload).Err()
+}
+
+// readRaw returns the stored envelope bytes from L1, falling back to Redis (and back-filling L1).
+func (s *Store) readRaw(ctx context.Context, key string) ([]byte, bool, error) {
+	now := time.Now()
+	if s.l1 != nil {
+		if payload, ok := s.l1.get(key, now); ok {
+			return payload, true, nil
+		}
+	}
+	client := s.Client()
+	if client == nil {
+		return nil, false, nil
+	}
+	payload, err := client.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redislib.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if s.l1 != nil {
+		if env, err := decodeEnvelope(payload); err == nil {
+			s.l1.set(key, payload, s.l1Expiry(now, env.expiresAt()))
+		}
+	}
+	return payload, true, nil
+}
+
+// writeRaw stores the envelope in Redis (registering tag membership) and in L1.
+func (s *Store) writeRaw(ctx context.Context, key string, payload []byte, ttl time.Duration, tags []string) error {
+	now := time.Now()
+	if s.l1 != nil {
+		s.l1.set(key, payload, s.l1Expiry(now, now.Add(ttl)))
+	}
+	client := s.Client()
+	if client == nil {
+		return nil
+	}
+	pipe := client.Pipeline()
+	pipe.Set(ctx, key, payload, ttl)
+	for _, tag := range tags {
+		if tag == "" {
+			continue
+		}
+		tagKey := bizConsts.BuildCacheTagKey(tag)
+		pipe.SAdd(ctx, tagKey, key)
+		pipe.Expire(ctx, tagKey, s.cfg.TagTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	// Other replicas may hold an older L1 copy of this key.
+	return s.publishInvalidation(ctx, []string{key})
+}
+
+func (s *Store) l1Expiry(now, redisExpiry time.Time) time.Time {
+	exp := now.Add(s.cfg.L1TTL)
+	if s.cfg.L1TTL <= 0 || redisExpiry.Before(exp) {
+		return redisExpiry
+	}
+	return exp
+}
+
+func randomNodeID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/consts"
+	redislib "github.com/redis/go-redis/v9"
+)
+
+// InvalidateTags deletes every entry registered under the given tags. Cost is
+// O(tags + members) — no keyspace SCAN.
+func (s *Store) InvalidateTags(ctx context.Context, tags ...string) error {
+	client := s.Client()
+	if client == nil {
+		return nil
+	}
+	read := make(map[string][]string, len(tags))
+	for _, tag := range tags {
+		if tag == "" {
+			continue
+		}
+		tagKey := bizConsts.BuildCacheTagKey(tag)
+		members, err := client.SMembers(ctx, tagKey).Result()
+		if err != nil && !errors.Is(err, redislib.Nil) {
+			return err
+		}
+		if len(members) > 0 {
+			read[tagKey] = members
+		}
+	}
+	return s.untagAndDelete(ctx, read)
+}
+
+// untagAndDelete removes exactly the members read from each tag set, then deletes those
+// entries. Entries tagged after the read keep their membership, so a concurrent write is
+// still covered by the next invalidation; Redis drops a tag set once it is empty.
+//
+// SREM goes first: a writer re-registering a key in between leaves an extra member
+// (harmless) rather than a live entry missing from its tag set.
+func (s *Store) untagAndDelete(ctx context.Context, read map[string][]string) error {
+	if len(read) == 0 {
+		return nil
+	}
+	var keys []string
+	pipe := s.Client().Pipeline()
+	for tagKey, members := range read {
+		args := make([]interface{}, len(members))
+		for i, m := range members {
+			args[i] = m
+		}
+		pipe.SRem(ctx, tagKey, args...)
+		keys = append(keys, members...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	return s.Delete(ctx, keys...)
+}
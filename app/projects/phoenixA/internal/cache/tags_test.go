@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	infraRedis "github.com/grand-thief-cash/chaos/app/infra/go/application/components/redis"
+	bizConsts "github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/consts"
+)
+
+func newRedisStore(t *testing.T) (*miniredis.Miniredis, *Store) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rc := infraRedis.NewRedisComponent(&infraRedis.Config{Mode: "single", Addresses: []string{mr.Addr()}})
+	if err := rc.Start(context.Background()); err != nil {
+		t.Fatalf("start redis: %v", err)
+	}
+	t.Cleanup(func() { _ = rc.Stop(context.Background()) })
+	s := NewStore(DefaultConfig())
+	s.RedisComp = rc
+	return mr, s
+}
+
+func TestInvalidateTagsKeepsEntriesTaggedAfterRead(t *testing.T) {
+	ctx := context.Background()
+	mr, s := newRedisStore(t)
+	tagKey := bizConsts.BuildCacheTagKey("t")
+	for _, key := range []string{"a", "b"} {
+		if err := s.writeRaw(ctx, key, []byte("1"), time.Minute, []string{"t"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	members, err := s.Client().SMembers(ctx, tagKey).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A concurrent write registers c after InvalidateTags read the tag set.
+	if err := s.writeRaw(ctx, "c", []byte("1"), time.Minute, []string{"t"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.untagAndDelete(ctx, map[string][]string{tagKey: members}); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("a") || mr.Exists("b") {
+		t.Fatal("expected members read from the tag to be deleted")
+	}
+	if got, _ := mr.SMembers(tagKey); !mr.Exists("c") || len(got) != 1 || got[0] != "c" {
+		t.Fatalf("expected c to stay cached and tagged, tag set = %v", got)
+	}
+
+	if err := s.InvalidateTags(ctx, "t"); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("c") || mr.Exists(tagKey) {
+		t.Fatal("expected c and the emptied tag set to be gone")
+	}
+}
@@ -35,13 +35,35 @@ func DefaultWriteBufferConfig() WriteBufferConfig {
 	}
 }
 
+// CacheConfig controls the cache-aside store layered over the redis component.
+type CacheConfig struct {
+	L1Enabled        bool          `yaml:"l1_enabled"`
+	L1MaxEntries     int           `yaml:"l1_max_entries"`
+	L1TTL            time.Duration `yaml:"l1_ttl"`
+	EarlyRefreshBeta float64       `yaml:"early_refresh_beta"`
+	TagTTL           time.Duration `yaml:"tag_ttl"`
+}
+
+// DefaultCacheConfig returns sensible defaults.
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		L1Enabled:        false,
+		L1MaxEntries:     4096,
+		L1TTL:            30 * time.Second,
+		EarlyRefreshBeta: 1.0,
+		TagTTL:           31 * 24 * time.Hour,
+	}
+}
+
 type BizConfig struct {
 	WriteBuffer WriteBufferConfig `yaml:"write_buffer"`
+	Cache       CacheConfig       `yaml:"cache"`
 }
 
 func init() {
 	bizConfig = &BizConfig{
 		WriteBuffer: DefaultWriteBufferConfig(),
+		Cache:       DefaultCacheConfig(),
 	}
 	app := application.GetApp()
 	app.SetBizConfig(bizConfig)
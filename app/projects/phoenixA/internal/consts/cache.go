@@ -6,16 +6,20 @@ import (
 )
 
 const (
-	RedisCacheKeyPrefixSecurityList                 = "phoenixa:cache:v1:security:list"
-	RedisCacheKeyPrefixSecurityCount                = "phoenixa:cache:v1:security:count"
-	RedisCacheKeyPrefixSchemaFields                 = "phoenixa:cache:v1:schema:fields"
-	RedisCacheKeyPrefixJSONBKeys                    = "phoenixa:cache:v1:schema:jsonb_keys"
-	RedisCacheKeyPrefixTaxonomyCategoryList         = "phoenixa:cache:v1:taxonomy:category:list"
-	RedisCacheKeyPrefixTaxonomyCategoryGet          = "phoenixa:cache:v1:taxonomy:category:get"
-	RedisCacheKeyPrefixTaxonomyMappingBySymbol      = "phoenixa:cache:v1:taxonomy:mapping:by_symbol"
-	RedisCacheKeyPrefixTaxonomyMappingByCategory    = "phoenixa:cache:v1:taxonomy:mapping:by_category"
-	RedisCacheKeyPrefixTaxonomyConstituentsByIndex  = "phoenixa:cache:v1:taxonomy:constituents:by_index"
-	RedisCacheKeyPrefixTaxonomyConstituentsBySymbol = "phoenixa:cache:v1:taxonomy:constituents:by_symbol"
+	RedisCacheKeyPrefixSecurityList                 = "phoenixa:cache:v2:security:list"
+	RedisCacheKeyPrefixSecurityCount                = "phoenixa:cache:v2:security:count"
+	RedisCacheKeyPrefixSchemaFields                 = "phoenixa:cache:v2:schema:fields"
+	RedisCacheKeyPrefixJSONBKeys                    = "phoenixa:cache:v2:schema:jsonb_keys"
+	RedisCacheKeyPrefixTaxonomyCategoryList         = "phoenixa:cache:v2:taxonomy:category:list"
+	RedisCacheKeyPrefixTaxonomyCategoryGet          = "phoenixa:cache:v2:taxonomy:category:get"
+	RedisCacheKeyPrefixTaxonomyMappingBySymbol      = "phoenixa:cache:v2:taxonomy:mapping:by_symbol"
+	RedisCacheKeyPrefixTaxonomyMappingByCategory    = "phoenixa:cache:v2:taxonomy:mapping:by_category"
+	RedisCacheKeyPrefixTaxonomyConstituentsByIndex  = "phoenixa:cache:v2:taxonomy:constituents:by_index"
+	RedisCacheKeyPrefixTaxonomyConstituentsBySymbol = "phoenixa:cache:v2:taxonomy:constituents:by_symbol"
+	RedisCacheKeyPrefixTag                          = "phoenixa:cache:v2:tag"
+
+	// RedisCacheInvalidationChannel carries L1 eviction messages between replicas.
+	RedisCacheInvalidationChannel = "phoenixa:cache:v2:invalidate"
 
 	RedisCacheTTLSecondsSecurityList                 = 6 * 60 * 60
 	RedisCacheTTLSecondsSecurityCount                = 30 * 60
@@ -27,6 +31,7 @@ const (
 	RedisCacheTTLSecondsTaxonomyMappingByCategory    = 14 * 24 * 60 * 60
 	RedisCacheTTLSecondsTaxonomyConstituentsByIndex  = 14 * 24 * 60 * 60
 	RedisCacheTTLSecondsTaxonomyConstituentsBySymbol = 14 * 24 * 60 * 60
+	RedisCacheTTLSecondsNegative                     = 60
 )
 
 func BuildSecurityListCacheKey(assetType, market string) string {
@@ -37,14 +42,6 @@ func BuildSecurityCountCacheKey(assetType, market string) string {
 	return fmt.Sprintf("%s:%s:%s", RedisCacheKeyPrefixSecurityCount, normalizeCachePart(assetType), normalizeCachePart(market))
 }
 
-func BuildSecurityListCachePattern(assetType, market string) string {
-	return fmt.Sprintf("%s:%s:%s", RedisCacheKeyPrefixSecurityList, normalizeCachePatternPart(assetType), normalizeCachePatternPart(market))
-}
-
-func BuildSecurityCountCachePattern(assetType, market string) string {
-	return fmt.Sprintf("%s:%s:%s", RedisCacheKeyPrefixSecurityCount, normalizeCachePatternPart(assetType), normalizeCachePatternPart(market))
-}
-
 func BuildSchemaFieldsCacheKey(domain, dataType string, sampleSize int) string {
 	return fmt.Sprintf("%s:%s:%s:%d", RedisCacheKeyPrefixSchemaFields, normalizeCachePart(domain), normalizeCachePart(dataType), sampleSize)
 }
@@ -57,48 +54,105 @@ func BuildTaxonomyCategoryListCacheKey(source, taxonomy, market, filterToken str
 	return fmt.Sprintf("%s:%s:%s:%s:%s", RedisCacheKeyPrefixTaxonomyCategoryList, normalizeCachePart(source), normalizeCachePart(taxonomy), normalizeCachePart(market), normalizeCachePart(filterToken))
 }
 
-func BuildTaxonomyCategoryListCachePattern(source, taxonomy, market string) string {
-	return fmt.Sprintf("%s:%s:%s:%s:*", RedisCacheKeyPrefixTaxonomyCategoryList, normalizeCachePatternPart(source), normalizeCachePatternPart(taxonomy), normalizeCachePatternPart(market))
-}
-
 func BuildTaxonomyCategoryGetCacheKey(source, taxonomy, market, code string) string {
 	return fmt.Sprintf("%s:%s:%s:%s:%s", RedisCacheKeyPrefixTaxonomyCategoryGet, normalizeCachePart(source), normalizeCachePart(taxonomy), normalizeCachePart(market), normalizeCachePart(code))
 }
 
-func BuildTaxonomyCategoryGetCachePattern(source, taxonomy, market string) string {
-	return fmt.Sprintf("%s:%s:%s:%s:*", RedisCacheKeyPrefixTaxonomyCategoryGet, normalizeCachePatternPart(source), normalizeCachePatternPart(taxonomy), normalizeCachePatternPart(market))
-}
-
 func BuildTaxonomyMappingBySymbolCacheKey(symbol string) string {
 	return fmt.Sprintf("%s:%s", RedisCacheKeyPrefixTaxonomyMappingBySymbol, normalizeCachePart(symbol))
 }
 
-func BuildTaxonomyMappingBySymbolCachePattern() string {
-	return RedisCacheKeyPrefixTaxonomyMappingBySymbol + ":*"
-}
-
 func BuildTaxonomyMappingByCategoryCacheKey(source, taxonomy, categoryCode string) string {
 	return fmt.Sprintf("%s:%s:%s:%s", RedisCacheKeyPrefixTaxonomyMappingByCategory, normalizeCachePart(source), normalizeCachePart(taxonomy), normalizeCachePart(categoryCode))
 }
 
-func BuildTaxonomyMappingByCategoryCachePattern(source, taxonomy, categoryCode string) string {
-	return fmt.Sprintf("%s:%s:%s:%s", RedisCacheKeyPrefixTaxonomyMappingByCategory, normalizeCachePatternPart(source), normalizeCachePatternPart(taxonomy), normalizeCachePatternPart(categoryCode))
-}
-
 func BuildTaxonomyConstituentsByIndexCacheKey(source, taxonomy, market, indexCode string) string {
 	return fmt.Sprintf("%s:%s:%s:%s:%s", RedisCacheKeyPrefixTaxonomyConstituentsByIndex, normalizeCachePart(source), normalizeCachePart(taxonomy), normalizeCachePart(market), normalizeCachePart(indexCode))
 }
 
-func BuildTaxonomyConstituentsByIndexCachePattern(source, taxonomy, market, indexCode string) string {
-	return fmt.Sprintf("%s:%s:%s:%s:%s", RedisCacheKeyPrefixTaxonomyConstituentsByIndex, normalizeCachePatternPart(source), normalizeCachePatternPart(taxonomy), normalizeCachePatternPart(market), normalizeCachePatternPart(indexCode))
-}
-
 func BuildTaxonomyConstituentsBySymbolCacheKey(source, taxonomy, market, symbol string) string {
 	return fmt.Sprintf("%s:%s:%s:%s:%s", RedisCacheKeyPrefixTaxonomyConstituentsBySymbol, normalizeCachePart(source), normalizeCachePart(taxonomy), normalizeCachePart(market), normalizeCachePart(symbol))
 }
 
-func BuildTaxonomyConstituentsBySymbolCachePattern(source, taxonomy, market, symbol string) string {
-	return fmt.Sprintf("%s:%s:%s:%s:%s", RedisCacheKeyPrefixTaxonomyConstituentsBySymbol, normalizeCachePatternPart(source), normalizeCachePatternPart(taxonomy), normalizeCachePatternPart(market), normalizeCachePatternPart(symbol))
+// BuildCacheTagKey returns the Redis set key holding the members of a cache tag.
+func BuildCacheTagKey(tag string) string {
+	return RedisCacheKeyPrefixTag + ":" + tag
+}
+
+// BuildTaxonomyCategoryCacheTags returns the tags a category list/get entry is registered under:
+// its exact scope and the same source/taxonomy with any market.
+func BuildTaxonomyCategoryCacheTags(source, taxonomy, market string) []string {
+	return []string{
+		BuildTaxonomyCategoryCacheTag(source, taxonomy, market),
+		BuildTaxonomyCategoryCacheTag(source, taxonomy, ""),
+	}
+}
+
+// BuildTaxonomyCategoryCacheTag returns the tag for a category scope; an empty market means any.
+func BuildTaxonomyCategoryCacheTag(source, taxonomy, market string) string {
+	return fmt.Sprintf("taxonomy:category:%s:%s:%s", normalizeCachePart(source), normalizeCachePart(taxonomy), normalizeCacheTagPart(market))
+}
+
+// BuildTaxonomyMappingBySymbolCacheTag tags every mapping-by-symbol entry; category edits
+// change the enriched payload of any symbol, so they are only ever dropped together.
+func BuildTaxonomyMappingBySymbolCacheTag() string {
+	return "taxonomy:mapping:by_symbol"
+}
+
+// BuildTaxonomyMappingByCategoryCacheTag tags every mapping-by-category entry of a source/taxonomy.
+func BuildTaxonomyMappingByCategoryCacheTag(source, taxonomy string) string {
+	return fmt.Sprintf("taxonomy:mapping:by_category:%s:%s", normalizeCachePart(source), normalizeCachePart(taxonomy))
+}
+
+// BuildTaxonomyConstituentsByIndexCacheTags returns the tags a constituents-by-index entry is
+// registered under: its exact scope and the same index in any market.
+func BuildTaxonomyConstituentsByIndexCacheTags(source, taxonomy, market, indexCode string) []string {
+	return []string{
+		BuildTaxonomyConstituentsByIndexCacheTag(source, taxonomy, market, indexCode),
+		BuildTaxonomyConstituentsByIndexCacheTag(source, taxonomy, "", indexCode),
+	}
+}
+
+// BuildTaxonomyConstituentsByIndexCacheTag returns the tag for one index; an empty market means any.
+func BuildTaxonomyConstituentsByIndexCacheTag(source, taxonomy, market, indexCode string) string {
+	return fmt.Sprintf("taxonomy:constituents:by_index:%s:%s:%s:%s", normalizeCachePart(source), normalizeCachePart(taxonomy), normalizeCacheTagPart(market), normalizeCachePart(indexCode))
+}
+
+// BuildSchemaTableCacheTag tags every schema-discovery entry derived from one table
+// (fields and JSONB keys for any type / sample size).
+func BuildSchemaTableCacheTag(schema, table string) string {
+	if schema == "" {
+		if i := strings.Index(table, "."); i >= 0 {
+			schema, table = table[:i], table[i+1:]
+		}
+	}
+	if schema == "" {
+		schema = "public"
+	}
+	return fmt.Sprintf("schema:table:%s.%s", normalizeCachePart(schema), normalizeCachePart(table))
+}
+
+// BuildSecurityScopeCacheTags returns the tags a security aggregate entry is registered under,
+// so invalidation works for a full scope, a single dimension, or everything.
+func BuildSecurityScopeCacheTags(assetType, market string) []string {
+	return []string{
+		BuildSecurityScopeCacheTag(assetType, market),
+		BuildSecurityScopeCacheTag(assetType, ""),
+		BuildSecurityScopeCacheTag("", market),
+		BuildSecurityScopeCacheTag("", ""),
+	}
+}
+
+// BuildSecurityScopeCacheTag returns the tag for a (possibly partial) security scope; empty means any.
+func BuildSecurityScopeCacheTag(assetType, market string) string {
+	return fmt.Sprintf("security:scope:%s:%s", normalizeCacheTagPart(assetType), normalizeCacheTagPart(market))
+}
+
+func normalizeCacheTagPart(v string) string {
+	if strings.TrimSpace(v) == "" {
+		return "*"
+	}
+	return normalizeCachePart(v)
 }
 
 func normalizeCachePart(v string) string {
@@ -111,11 +165,3 @@ func normalizeCachePart(v string) string {
 	v = strings.ReplaceAll(v, ":", "_")
 	return v
 }
-
-func normalizeCachePatternPart(v string) string {
-	v = strings.TrimSpace(v)
-	if v == "" {
-		return "*"
-	}
-	return normalizeCachePart(v)
-}
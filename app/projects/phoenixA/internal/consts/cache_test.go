@@ -29,3 +29,43 @@ func TestPaginatedTaxonomyCacheKeysDoNotVaryByPage(t *testing.T) {
 		t.Fatalf("constituents-by-index cache key should be stable across page/page_size changes")
 	}
 }
+
+func TestSecurityScopeCacheTagsCoverPartialScopes(t *testing.T) {
+	tags := BuildSecurityScopeCacheTags("stock", "zh_a")
+	for _, want := range []string{
+		BuildSecurityScopeCacheTag("stock", "zh_a"),
+		BuildSecurityScopeCacheTag("stock", ""),
+		BuildSecurityScopeCacheTag("", "zh_a"),
+		BuildSecurityScopeCacheTag("", ""),
+	} {
+		found := false
+		for _, tag := range tags {
+			if tag == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("expected tag %q in %v", want, tags)
+		}
+	}
+}
+
+func TestSchemaTableCacheTagSplitsQualifiedName(t *testing.T) {
+	if BuildSchemaTableCacheTag("", "ods.financial_statement") != BuildSchemaTableCacheTag("ods", "financial_statement") {
+		t.Fatalf("qualified and split table names should yield the same tag")
+	}
+}
+
+func TestTaxonomyCacheTagsCoverAnyMarket(t *testing.T) {
+	tags := BuildTaxonomyCategoryCacheTags("amazing_data", "sw_l1", "zh_a")
+	if tags[1] != BuildTaxonomyCategoryCacheTag("amazing_data", "sw_l1", "") {
+		t.Fatalf("category entries should also be registered under the any-market tag: %v", tags)
+	}
+	byIndex := BuildTaxonomyConstituentsByIndexCacheTags("amazing_data", "sw_l1", "zh_a", "801010.SI")
+	if byIndex[1] != BuildTaxonomyConstituentsByIndexCacheTag("amazing_data", "sw_l1", "", "801010.SI") {
+		t.Fatalf("constituent entries should also be registered under the any-market tag: %v", byIndex)
+	}
+	if BuildTaxonomyConstituentsByIndexCacheTag("amazing_data", "sw_l1", "zh_a", "801010.SI") == BuildTaxonomyConstituentsByIndexCacheTag("amazing_data", "sw_l1", "zh_a", "801020.SI") {
+		t.Fatalf("constituents-by-index tag should vary by index")
+	}
+}
@@ -40,6 +40,9 @@ const (
 	// Buffer components
 	COMP_WRITE_BUFFER = "write_buffer_mgr"
 
+	// Cache components
+	COMP_CACHE_STORE = "cache_store"
+
 	// Controller components
 	COMP_CTRL_SECURITY         = "ctrl_security"
 	COMP_CTRL_BARS             = "ctrl_bars"
@@ -104,17 +104,17 @@ func (c *GraphController) MergeNodeBatch(w http.ResponseWriter, r *http.Request)
 		writeJSON(w, http.StatusBadRequest, apiError{Error: "invalid json"})
 		return
 	}
-	var total int64
+	nodes := make([]service.NodeMerge, 0, len(reqs))
 	for _, req := range reqs {
 		if req.Label == "" || req.MergeKey == "" || req.MergeValue == "" {
 			continue
 		}
-		affected, err := c.Svc.MergeNode(r.Context(), req.Label, req.MergeKey, req.MergeValue, req.Props)
-		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
-			return
-		}
-		total += affected
+		nodes = append(nodes, service.NodeMerge{Label: req.Label, MergeKey: req.MergeKey, MergeValue: req.MergeValue, Props: req.Props})
+	}
+	total, err := c.Svc.MergeNodesBatch(r.Context(), nodes)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+		return
 	}
 	writeJSON(w, http.StatusOK, apiResponse[any]{Data: map[string]any{"total_affected": total, "count": len(reqs)}})
 }
@@ -159,20 +159,21 @@ func (c *GraphController) MergeEdgeBatch(w http.ResponseWriter, r *http.Request)
 		writeJSON(w, http.StatusBadRequest, apiError{Error: "invalid json"})
 		return
 	}
-	var total int64
+	edges := make([]service.EdgeMerge, 0, len(reqs))
 	for _, req := range reqs {
 		if req.FromLabel == "" || req.ToLabel == "" || req.RelType == "" {
 			continue
 		}
-		affected, err := c.Svc.MergeEdge(r.Context(),
-			req.FromLabel, req.FromKey, req.FromValue,
-			req.ToLabel, req.ToKey, req.ToValue,
-			req.RelType, req.Attrs)
-		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
-			return
-		}
-		total += affected
+		edges = append(edges, service.EdgeMerge{
+			FromLabel: req.FromLabel, FromKey: req.FromKey, FromValue: req.FromValue,
+			ToLabel: req.ToLabel, ToKey: req.ToKey, ToValue: req.ToValue,
+			RelType: req.RelType, Attrs: req.Attrs,
+		})
+	}
+	total, err := c.Svc.MergeEdgesBatch(r.Context(), edges)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+		return
 	}
 	writeJSON(w, http.StatusOK, apiResponse[any]{Data: map[string]any{"total_affected": total, "count": len(reqs)}})
 }
@@ -272,10 +273,11 @@ func (c *GraphController) GetGraphStats(w http.ResponseWriter, r *http.Request)
 
 // POST /api/v1/graph/schema/ensure
 func (c *GraphController) EnsureSchema(w http.ResponseWriter, r *http.Request) {
-	if err := c.Svc.EnsureSchema(r.Context()); err != nil {
+	res, err := c.Svc.EnsureSchema(r.Context())
+	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
 		return
 	}
-	writeJSON(w, http.StatusOK, apiResponse[any]{Data: map[string]string{"status": "ok"}})
+	writeJSON(w, http.StatusOK, apiResponse[any]{Data: map[string]any{"status": "ok", "applied": res.Applied, "skipped": res.Skipped}})
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/migration"
 	neo4jcomp "github.com/grand-thief-cash/chaos/app/infra/go/application/components/neo4j"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/consts"
@@ -54,6 +55,23 @@ func (d *GraphDao) MergeEdge(ctx context.Context, fromLabel, fromKey, fromVal, t
 	return d.Neo4j.RunCypherWrite(ctx, cypher, params)
 }
 
+// MergeNodesBatch merges nodes sharing label and merge key with batched UNWIND writes.
+// Each row must carry "key" (merge value) and "props".
+func (d *GraphDao) MergeNodesBatch(ctx context.Context, label, mergeKey string, rows []map[string]any) (neo4jcomp.BatchResult, error) {
+	cypher := fmt.Sprintf("UNWIND $rows AS row MERGE (n:%s {%s: row.key}) SET n += row.props", label, mergeKey)
+	return d.Neo4j.WriteBatched(ctx, cypher, rows, neo4jcomp.BatchOptions{})
+}
+
+// MergeEdgesBatch merges relationships of one type between two labels with batched UNWIND writes.
+// Each row must carry "from", "to" (merge values) and "attrs".
+func (d *GraphDao) MergeEdgesBatch(ctx context.Context, fromLabel, fromKey, toLabel, toKey, relType string, rows []map[string]any) (neo4jcomp.BatchResult, error) {
+	cypher := fmt.Sprintf(
+		"UNWIND $rows AS row MATCH (a:%s {%s: row.from}) MATCH (b:%s {%s: row.to}) MERGE (a)-[r:%s]->(b) SET r += row.attrs",
+		fromLabel, fromKey, toLabel, toKey, relType,
+	)
+	return d.Neo4j.WriteBatched(ctx, cypher, rows, neo4jcomp.BatchOptions{})
+}
+
 // SearchNodes performs full-text search across all node names.
 func (d *GraphDao) SearchNodes(ctx context.Context, query string, limit int) ([]map[string]any, error) {
 	if limit <= 0 {
@@ -226,28 +244,7 @@ func (d *GraphDao) GetRelTypeCounts(ctx context.Context) (map[string]int, error)
 	return result, nil
 }
 
-// EnsureSchema creates Neo4j constraints and indexes.
-func (d *GraphDao) EnsureSchema(ctx context.Context) error {
-	constraints := []string{
-		"CREATE CONSTRAINT IF NOT EXISTS FOR (c:Company) REQUIRE c.normalized_name IS UNIQUE",
-		"CREATE CONSTRAINT IF NOT EXISTS FOR (p:Product) REQUIRE p.name IS UNIQUE",
-		"CREATE CONSTRAINT IF NOT EXISTS FOR (i:Industry) REQUIRE i.name IS UNIQUE",
-		"CREATE CONSTRAINT IF NOT EXISTS FOR (r:Resource) REQUIRE r.name IS UNIQUE",
-		"CREATE CONSTRAINT IF NOT EXISTS FOR (t:Technology) REQUIRE t.name IS UNIQUE",
-		"CREATE CONSTRAINT IF NOT EXISTS FOR (e:Event) REQUIRE e.name IS UNIQUE",
-		"CREATE CONSTRAINT IF NOT EXISTS FOR (p:Policy) REQUIRE p.name IS UNIQUE",
-		"CREATE CONSTRAINT IF NOT EXISTS FOR (m:Market) REQUIRE m.name IS UNIQUE",
-		"CREATE CONSTRAINT IF NOT EXISTS FOR (a:Asset) REQUIRE a.name IS UNIQUE",
-	}
-	indexes := []string{
-		"CREATE INDEX IF NOT EXISTS FOR (c:Company) ON (c.name)",
-		"CREATE INDEX IF NOT EXISTS FOR (c:Company) ON (c.ticker)",
-		"CREATE INDEX IF NOT EXISTS FOR (e:Event) ON (e.time)",
-	}
-	for _, stmt := range append(constraints, indexes...) {
-		if _, err := d.Neo4j.RunCypherWrite(ctx, stmt, nil); err != nil {
-			return fmt.Errorf("ensure schema failed on: %s: %w", stmt, err)
-		}
-	}
-	return nil
+// EnsureSchema applies pending KG constraint/index migrations (migrations/neo4j/kg).
+func (d *GraphDao) EnsureSchema(ctx context.Context) (*migration.Result, error) {
+	return d.Neo4j.Migrate(ctx, "")
 }
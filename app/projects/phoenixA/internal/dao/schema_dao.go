@@ -2,16 +2,15 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"time"
 
 	pg "github.com/grand-thief-cash/chaos/app/infra/go/application/components/postgresgorm"
-	infraRedis "github.com/grand-thief-cash/chaos/app/infra/go/application/components/redis"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 	"github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/cache"
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/consts"
-	redislib "github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
@@ -32,10 +31,10 @@ var domainAllowList = map[string]domainSpec{
 // SchemaDao discovers fields stored in JSONB columns.
 type SchemaDao struct {
 	*core.BaseComponent
-	GormComp  *pg.PostgresGormComponent  `infra:"dep:postgres_gorm"`
-	RedisComp *infraRedis.RedisComponent `infra:"dep:redis?"`
-	db        *gorm.DB
-	dsName    string
+	GormComp   *pg.PostgresGormComponent `infra:"dep:postgres_gorm"`
+	CacheStore *cache.Store              `infra:"dep:cache_store"`
+	db         *gorm.DB
+	dsName     string
 }
 
 func NewSchemaDao(dsName string) *SchemaDao {
@@ -59,11 +58,10 @@ func (d *SchemaDao) Start(ctx context.Context) error {
 
 func (d *SchemaDao) Stop(ctx context.Context) error { return d.BaseComponent.Stop(ctx) }
 
-func (d *SchemaDao) redisClient() redislib.UniversalClient {
-	if d.RedisComp == nil {
-		return nil
-	}
-	return d.RedisComp.Client()
+// InvalidateTableCache drops every cached discovery result (fields and JSONB keys,
+// any type or sample size) derived from schema.table.
+func (d *SchemaDao) InvalidateTableCache(ctx context.Context, schema, table string) error {
+	return d.CacheStore.InvalidateTags(ctx, bizConsts.BuildSchemaTableCacheTag(schema, table))
 }
 
 func WithSchemaCacheBypass(ctx context.Context) context.Context {
@@ -102,12 +100,23 @@ func (d *SchemaDao) DiscoverFields(ctx context.Context, domain, dataType string,
 	}
 
 	cacheKey := bizConsts.BuildSchemaFieldsCacheKey(domain, dataType, sampleSize)
-	if !shouldBypassSchemaCache(ctx) {
-		if cached, hit, err := cache.GetJSON[FieldsResult](ctx, d.redisClient(), cacheKey); err == nil && hit {
-			return &cached, nil
-		}
+	opts := cache.LoadOptions{
+		TTL:         time.Duration(bizConsts.RedisCacheTTLSecondsSchemaFields) * time.Second,
+		NegativeTTL: time.Duration(bizConsts.RedisCacheTTLSecondsNegative) * time.Second,
+		Tags:        []string{bizConsts.BuildSchemaTableCacheTag("", spec.Table)},
+		Bypass:      shouldBypassSchemaCache(ctx),
+	}
+	result, err := cache.GetOrLoad(ctx, d.CacheStore, cacheKey, opts, func(ctx context.Context) (*FieldsResult, error) {
+		return d.discoverFields(ctx, spec, domain, dataType, sampleSize)
+	})
+	if errors.Is(err, cache.ErrNotFound) {
+		return &FieldsResult{Domain: domain, DataType: dataType, Fields: []string{}, SampleCount: 0}, nil
 	}
+	return result, err
+}
 
+// discoverFields runs the uncached discovery; returns cache.ErrNotFound when the type has no rows.
+func (d *SchemaDao) discoverFields(ctx context.Context, spec domainSpec, domain, dataType string, sampleSize int) (*FieldsResult, error) {
 	// Count total rows for this type
 	var totalCount int64
 	countSQL := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = ?", spec.Table, spec.TypeColumn)
@@ -116,7 +125,7 @@ func (d *SchemaDao) DiscoverFields(ctx context.Context, domain, dataType string,
 	}
 
 	if totalCount == 0 {
-		return &FieldsResult{Domain: domain, DataType: dataType, Fields: []string{}, SampleCount: 0}, nil
+		return nil, cache.ErrNotFound
 	}
 
 	// Extract distinct JSONB keys using PostgreSQL jsonb_object_keys()
@@ -154,7 +163,6 @@ func (d *SchemaDao) DiscoverFields(ctx context.Context, domain, dataType string,
 		Fields:      fields,
 		SampleCount: actualSampled,
 	}
-	_ = cache.SetJSON(ctx, d.redisClient(), cacheKey, time.Duration(bizConsts.RedisCacheTTLSecondsSchemaFields)*time.Second, result)
 	return result, nil
 }
 
@@ -252,12 +260,18 @@ func (d *SchemaDao) DiscoverJSONBKeysGeneric(ctx context.Context, schema, table,
 	}
 
 	cacheKey := bizConsts.BuildJSONBKeysCacheKey(schema, table, column, sampleSize)
-	if !shouldBypassSchemaCache(ctx) {
-		if cached, hit, err := cache.GetJSON[[]JSONBKeyInfo](ctx, d.redisClient(), cacheKey); err == nil && hit {
-			return cached, nil
-		}
+	opts := cache.LoadOptions{
+		TTL:    time.Duration(bizConsts.RedisCacheTTLSecondsJSONBKeys) * time.Second,
+		Tags:   []string{bizConsts.BuildSchemaTableCacheTag(schema, table)},
+		Bypass: shouldBypassSchemaCache(ctx),
 	}
+	return cache.GetOrLoad(ctx, d.CacheStore, cacheKey, opts, func(ctx context.Context) ([]JSONBKeyInfo, error) {
+		return d.discoverJSONBKeys(ctx, schema, table, column, sampleSize)
+	})
+}
 
+// discoverJSONBKeys runs the uncached generic JSONB key discovery.
+func (d *SchemaDao) discoverJSONBKeys(ctx context.Context, schema, table, column string, sampleSize int) ([]JSONBKeyInfo, error) {
 	fullTable := table
 	if schema != "" && schema != "public" {
 		fullTable = schema + "." + table
@@ -340,6 +354,5 @@ func (d *SchemaDao) DiscoverJSONBKeysGeneric(ctx context.Context, schema, table,
 
 		result = append(result, info)
 	}
-	_ = cache.SetJSON(ctx, d.redisClient(), cacheKey, time.Duration(bizConsts.RedisCacheTTLSecondsJSONBKeys)*time.Second, result)
 	return result, nil
 }
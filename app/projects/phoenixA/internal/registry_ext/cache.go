@@ -0,0 +1,24 @@
+package registry_ext
+
+import (
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/config"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/registry"
+	"github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/cache"
+	bizConfig "github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/config"
+)
+
+func init() {
+	// Cache store (cache-aside over redis; degrades to pass-through when redis is disabled)
+	registry.RegisterAuto(func(cfg *config.AppConfig, c *core.Container) (bool, core.Component, error) {
+		bc := bizConfig.GetBizConfig()
+		cacheCfg := cache.Config{
+			L1Enabled:        bc.Cache.L1Enabled,
+			L1MaxEntries:     bc.Cache.L1MaxEntries,
+			L1TTL:            bc.Cache.L1TTL,
+			EarlyRefreshBeta: bc.Cache.EarlyRefreshBeta,
+			TagTTL:           bc.Cache.TagTTL,
+		}
+		return true, cache.NewStore(cacheCfg), nil
+	})
+}
@@ -15,7 +15,8 @@ import (
 
 type CorporateActionService struct {
 	*core.BaseComponent
-	Dao *dao.CorporateActionDao `infra:"dep:dao_corp_action"`
+	Dao       *dao.CorporateActionDao `infra:"dep:dao_corp_action"`
+	SchemaDao *dao.SchemaDao          `infra:"dep:dao_schema"`
 }
 
 func NewCorporateActionService() *CorporateActionService {
@@ -38,7 +39,16 @@ func (s *CorporateActionService) BatchUpsert(ctx context.Context, list []*model.
 		return nil
 	}
 	logging.Infof(ctx, "CorporateActionService BatchUpsert count=%d", len(list))
-	return s.Dao.BatchUpsert(ctx, list)
+	if err := s.Dao.BatchUpsert(ctx, list); err != nil {
+		return err
+	}
+	// New rows may carry data_json keys the cached field discovery has not seen yet.
+	if s.SchemaDao != nil {
+		if err := s.SchemaDao.InvalidateTableCache(ctx, "ods", "corporate_action"); err != nil {
+			logging.Warnf(ctx, "corporate_action schema cache invalidation failed: %v", err)
+		}
+	}
+	return nil
 }
 
 // Query is the legacy full-struct query path. New callers should use
@@ -16,7 +16,8 @@ import (
 // FinancialStatementService handles business logic for financial statement data.
 type FinancialStatementService struct {
 	*core.BaseComponent
-	Dao *dao.FinancialStatementDao `infra:"dep:dao_financial_stmt"`
+	Dao       *dao.FinancialStatementDao `infra:"dep:dao_financial_stmt"`
+	SchemaDao *dao.SchemaDao             `infra:"dep:dao_schema"`
 }
 
 func NewFinancialStatementService() *FinancialStatementService {
@@ -42,7 +43,16 @@ func (s *FinancialStatementService) BatchUpsert(ctx context.Context, list []*mod
 		return nil
 	}
 	logging.Infof(ctx, "FinancialStatementService BatchUpsert count=%d", len(list))
-	return s.Dao.BatchUpsert(ctx, list)
+	if err := s.Dao.BatchUpsert(ctx, list); err != nil {
+		return err
+	}
+	// New rows may carry data_json keys the cached field discovery has not seen yet.
+	if s.SchemaDao != nil {
+		if err := s.SchemaDao.InvalidateTableCache(ctx, "ods", "financial_statement"); err != nil {
+			logging.Warnf(ctx, "financial_statement schema cache invalidation failed: %v", err)
+		}
+	}
+	return nil
 }
 
 // Query returns financial statements matching the given filters (legacy path,
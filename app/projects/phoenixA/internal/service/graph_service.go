@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
+	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/migration"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/consts"
@@ -49,6 +50,78 @@ func (s *GraphService) MergeEdge(ctx context.Context, fromLabel, fromKey, fromVa
 	return s.Dao.MergeEdge(ctx, fromLabel, fromKey, fromVal, toLabel, toKey, toVal, relType, attrs)
 }
 
+// NodeMerge is one node upsert in a batch.
+type NodeMerge struct {
+	Label      string
+	MergeKey   string
+	MergeValue string
+	Props      map[string]any
+}
+
+// EdgeMerge is one relationship upsert in a batch.
+type EdgeMerge struct {
+	FromLabel, FromKey, FromValue string
+	ToLabel, ToKey, ToValue       string
+	RelType                       string
+	Attrs                         map[string]any
+}
+
+// MergeNodesBatch groups nodes by (label, merge key) and writes each group with batched UNWIND.
+func (s *GraphService) MergeNodesBatch(ctx context.Context, nodes []NodeMerge) (int64, error) {
+	type group struct{ label, key string }
+	var order []group
+	rows := make(map[group][]map[string]any)
+	for _, n := range nodes {
+		g := group{n.Label, n.MergeKey}
+		if _, ok := rows[g]; !ok {
+			order = append(order, g)
+		}
+		props := n.Props
+		if props == nil {
+			props = map[string]any{}
+		}
+		rows[g] = append(rows[g], map[string]any{"key": n.MergeValue, "props": props})
+	}
+	var total int64
+	for _, g := range order {
+		res, err := s.Dao.MergeNodesBatch(ctx, g.label, g.key, rows[g])
+		total += res.Affected
+		if err != nil {
+			return total, err
+		}
+	}
+	logging.Infof(ctx, "GraphService MergeNodesBatch nodes=%d groups=%d affected=%d", len(nodes), len(order), total)
+	return total, nil
+}
+
+// MergeEdgesBatch groups edges by endpoint labels/keys and type and writes each group with batched UNWIND.
+func (s *GraphService) MergeEdgesBatch(ctx context.Context, edges []EdgeMerge) (int64, error) {
+	type group struct{ fromLabel, fromKey, toLabel, toKey, relType string }
+	var order []group
+	rows := make(map[group][]map[string]any)
+	for _, e := range edges {
+		g := group{e.FromLabel, e.FromKey, e.ToLabel, e.ToKey, e.RelType}
+		if _, ok := rows[g]; !ok {
+			order = append(order, g)
+		}
+		attrs := e.Attrs
+		if attrs == nil {
+			attrs = map[string]any{}
+		}
+		rows[g] = append(rows[g], map[string]any{"from": e.FromValue, "to": e.ToValue, "attrs": attrs})
+	}
+	var total int64
+	for _, g := range order {
+		res, err := s.Dao.MergeEdgesBatch(ctx, g.fromLabel, g.fromKey, g.toLabel, g.toKey, g.relType, rows[g])
+		total += res.Affected
+		if err != nil {
+			return total, err
+		}
+	}
+	logging.Infof(ctx, "GraphService MergeEdgesBatch edges=%d groups=%d affected=%d", len(edges), len(order), total)
+	return total, nil
+}
+
 func (s *GraphService) SearchNodes(ctx context.Context, query string, limit int) ([]map[string]any, error) {
 	return s.Dao.SearchNodes(ctx, query, limit)
 }
@@ -81,7 +154,7 @@ func (s *GraphService) GetGraphStats(ctx context.Context) (map[string]any, error
 	return s.Dao.GetGraphStats(ctx)
 }
 
-func (s *GraphService) EnsureSchema(ctx context.Context) error {
+func (s *GraphService) EnsureSchema(ctx context.Context) (*migration.Result, error) {
 	logging.Infof(ctx, "GraphService EnsureSchema")
 	return s.Dao.EnsureSchema(ctx)
 }
@@ -7,21 +7,19 @@ import (
 	"time"
 
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
-	infraRedis "github.com/grand-thief-cash/chaos/app/infra/go/application/components/redis"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 	"github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/cache"
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/consts"
 	"github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/dao"
 	"github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/model"
-	redislib "github.com/redis/go-redis/v9"
 )
 
 // SecurityService handles business logic for the unified security registry.
 type SecurityService struct {
 	*core.BaseComponent
-	Dao       *dao.SecurityRegistryDao   `infra:"dep:dao_security_registry"`
-	RedisComp *infraRedis.RedisComponent `infra:"dep:redis?"`
+	Dao        *dao.SecurityRegistryDao `infra:"dep:dao_security_registry"`
+	CacheStore *cache.Store             `infra:"dep:cache_store"`
 }
 
 func NewSecurityService() *SecurityService {
@@ -62,21 +60,13 @@ func (s *SecurityService) Get(ctx context.Context, symbol, assetType, market str
 func (s *SecurityService) ListFiltered(ctx context.Context, f *model.SecurityFilters, limit, offset int) ([]*model.SecurityRegistry, error) {
 	assetType, market, cacheable := securityAggregateCacheScope(f, limit, offset)
 	if cacheable {
-		key := bizConsts.BuildSecurityListCacheKey(assetType, market)
-		if cached, hit, err := cache.GetJSON[[]*model.SecurityRegistry](ctx, s.redisClient(), key); err == nil && hit {
-			return cached, nil
-		} else if err != nil {
-			logging.Warnf(ctx, "security list redis cache get failed: %v", err)
+		opts := cache.LoadOptions{
+			TTL:  time.Duration(bizConsts.RedisCacheTTLSecondsSecurityList) * time.Second,
+			Tags: bizConsts.BuildSecurityScopeCacheTags(assetType, market),
 		}
-
-		list, err := s.Dao.ListFiltered(ctx, f, limit, offset)
-		if err != nil {
-			return nil, err
-		}
-		if err := cache.SetJSON(ctx, s.redisClient(), key, time.Duration(bizConsts.RedisCacheTTLSecondsSecurityList)*time.Second, list); err != nil {
-			logging.Warnf(ctx, "security list redis cache set failed: %v", err)
-		}
-		return list, nil
+		return cache.GetOrLoad(ctx, s.CacheStore, bizConsts.BuildSecurityListCacheKey(assetType, market), opts, func(ctx context.Context) ([]*model.SecurityRegistry, error) {
+			return s.Dao.ListFiltered(ctx, f, limit, offset)
+		})
 	}
 	return s.Dao.ListFiltered(ctx, f, limit, offset)
 }
@@ -84,21 +74,13 @@ func (s *SecurityService) ListFiltered(ctx context.Context, f *model.SecurityFil
 func (s *SecurityService) CountFiltered(ctx context.Context, f *model.SecurityFilters) (int64, error) {
 	assetType, market, cacheable := securityAggregateCacheScope(f, 0, 0)
 	if cacheable {
-		key := bizConsts.BuildSecurityCountCacheKey(assetType, market)
-		if cached, hit, err := cache.GetJSON[int64](ctx, s.redisClient(), key); err == nil && hit {
-			return cached, nil
-		} else if err != nil {
-			logging.Warnf(ctx, "security count redis cache get failed: %v", err)
-		}
-
-		count, err := s.Dao.CountFiltered(ctx, f)
-		if err != nil {
-			return 0, err
+		opts := cache.LoadOptions{
+			TTL:  time.Duration(bizConsts.RedisCacheTTLSecondsSecurityCount) * time.Second,
+			Tags: bizConsts.BuildSecurityScopeCacheTags(assetType, market),
 		}
-		if err := cache.SetJSON(ctx, s.redisClient(), key, time.Duration(bizConsts.RedisCacheTTLSecondsSecurityCount)*time.Second, count); err != nil {
-			logging.Warnf(ctx, "security count redis cache set failed: %v", err)
-		}
-		return count, nil
+		return cache.GetOrLoad(ctx, s.CacheStore, bizConsts.BuildSecurityCountCacheKey(assetType, market), opts, func(ctx context.Context) (int64, error) {
+			return s.Dao.CountFiltered(ctx, f)
+		})
 	}
 	return s.Dao.CountFiltered(ctx, f)
 }
@@ -116,19 +98,12 @@ func (s *SecurityService) DeleteAll(ctx context.Context, assetType, market strin
 		return affected, nil
 	}
 	resolvedAssetType, resolvedMarket := normalizeSecurityAggregateScope(assetType, market)
-	if delErr := cache.DeleteKeys(ctx, s.redisClient(), bizConsts.BuildSecurityListCacheKey(resolvedAssetType, resolvedMarket), bizConsts.BuildSecurityCountCacheKey(resolvedAssetType, resolvedMarket)); delErr != nil {
+	if delErr := s.CacheStore.Delete(ctx, bizConsts.BuildSecurityListCacheKey(resolvedAssetType, resolvedMarket), bizConsts.BuildSecurityCountCacheKey(resolvedAssetType, resolvedMarket)); delErr != nil {
 		logging.Warnf(ctx, "security cache invalidation after delete_all failed: %v", delErr)
 	}
 	return affected, nil
 }
 
-func (s *SecurityService) redisClient() redislib.UniversalClient {
-	if s.RedisComp == nil {
-		return nil
-	}
-	return s.RedisComp.Client()
-}
-
 func (s *SecurityService) invalidateAggregateCaches(ctx context.Context, list []*model.SecurityRegistry) {
 	if len(list) == 0 {
 		return
@@ -150,18 +125,15 @@ func (s *SecurityService) invalidateAggregateCaches(ctx context.Context, list []
 			bizConsts.BuildSecurityCountCacheKey(assetType, market),
 		)
 	}
-	if err := cache.DeleteKeys(ctx, s.redisClient(), keys...); err != nil {
+	if err := s.CacheStore.Delete(ctx, keys...); err != nil {
 		logging.Warnf(ctx, "security cache invalidation after batch_upsert failed: %v", err)
 	}
 }
 
+// invalidateAggregateCachesByScope drops list/count entries for a (possibly partial) scope
+// via its tag; an empty asset type or market matches any.
 func (s *SecurityService) invalidateAggregateCachesByScope(ctx context.Context, assetType, market string) error {
-	listPattern := bizConsts.BuildSecurityListCachePattern(assetType, market)
-	countPattern := bizConsts.BuildSecurityCountCachePattern(assetType, market)
-	if err := cache.DeleteByPattern(ctx, s.redisClient(), listPattern); err != nil {
-		return err
-	}
-	return cache.DeleteByPattern(ctx, s.redisClient(), countPattern)
+	return s.CacheStore.InvalidateTags(ctx, bizConsts.BuildSecurityScopeCacheTag(assetType, market))
 }
 
 func securityAggregateCacheScope(f *model.SecurityFilters, limit, offset int) (string, string, bool) {
@@ -9,21 +9,19 @@ import (
 	"time"
 
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/components/logging"
-	infraRedis "github.com/grand-thief-cash/chaos/app/infra/go/application/components/redis"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/consts"
 	"github.com/grand-thief-cash/chaos/app/infra/go/application/core"
 	"github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/cache"
 	bizConsts "github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/consts"
 	"github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/dao"
 	"github.com/grand-thief-cash/chaos/app/projects/phoenixA/internal/model"
-	redislib "github.com/redis/go-redis/v9"
 )
 
 // TaxonomyService is the unified service for taxonomy categories and mappings.
 type TaxonomyService struct {
 	*core.BaseComponent
-	Dao       *dao.TaxonomyDao           `infra:"dep:dao_taxonomy"`
-	RedisComp *infraRedis.RedisComponent `infra:"dep:redis?"`
+	Dao        *dao.TaxonomyDao `infra:"dep:dao_taxonomy"`
+	CacheStore *cache.Store     `infra:"dep:cache_store"`
 }
 
 func NewTaxonomyService() *TaxonomyService {
@@ -41,13 +39,6 @@ func (s *TaxonomyService) Start(ctx context.Context) error {
 
 func (s *TaxonomyService) Stop(ctx context.Context) error { return s.BaseComponent.Stop(ctx) }
 
-func (s *TaxonomyService) redisClient() redislib.UniversalClient {
-	if s.RedisComp == nil {
-		return nil
-	}
-	return s.RedisComp.Client()
-}
-
 // BatchUpsertCategories upserts taxonomy categories.
 func (s *TaxonomyService) BatchUpsertCategories(ctx context.Context, source, taxonomy, market string, list []*model.TaxonomyCategory) error {
 	if source == "" || taxonomy == "" {
@@ -70,39 +61,33 @@ func (s *TaxonomyService) ListCategories(ctx context.Context, source, taxonomy,
 	if pageSize < 1 {
 		pageSize = 100
 	}
-	cacheKey := bizConsts.BuildTaxonomyCategoryListCacheKey(source, taxonomy, market, taxonomyCategoryFilterToken(f))
-	if cached, hit, err := cache.GetJSON[taxonomyCategoryListCachePayload](ctx, s.redisClient(), cacheKey); err == nil && hit {
-		return paginateItems(cached.List, page, pageSize), cached.Total, nil
-	} else if err != nil {
-		logging.Warnf(ctx, "taxonomy categories redis cache get failed: %v", err)
+	opts := cache.LoadOptions{
+		TTL:  time.Duration(bizConsts.RedisCacheTTLSecondsTaxonomyCategoryList) * time.Second,
+		Tags: bizConsts.BuildTaxonomyCategoryCacheTags(source, taxonomy, market),
 	}
-	list, err := s.Dao.ListCategories(ctx, source, taxonomy, market, f, 0, 0)
+	cacheKey := bizConsts.BuildTaxonomyCategoryListCacheKey(source, taxonomy, market, taxonomyCategoryFilterToken(f))
+	cached, err := cache.GetOrLoad(ctx, s.CacheStore, cacheKey, opts, func(ctx context.Context) (taxonomyCategoryListCachePayload, error) {
+		list, err := s.Dao.ListCategories(ctx, source, taxonomy, market, f, 0, 0)
+		if err != nil {
+			return taxonomyCategoryListCachePayload{}, err
+		}
+		return taxonomyCategoryListCachePayload{List: list, Total: int64(len(list))}, nil
+	})
 	if err != nil {
 		return nil, 0, err
 	}
-	count := int64(len(list))
-	if err := cache.SetJSON(ctx, s.redisClient(), cacheKey, time.Duration(bizConsts.RedisCacheTTLSecondsTaxonomyCategoryList)*time.Second, taxonomyCategoryListCachePayload{List: list, Total: count}); err != nil {
-		logging.Warnf(ctx, "taxonomy categories redis cache set failed: %v", err)
-	}
-	return paginateItems(list, page, pageSize), count, nil
+	return paginateItems(cached.List, page, pageSize), cached.Total, nil
 }
 
 // GetCategory retrieves a single category.
 func (s *TaxonomyService) GetCategory(ctx context.Context, source, taxonomy, market, code string) (*model.TaxonomyCategory, error) {
-	cacheKey := bizConsts.BuildTaxonomyCategoryGetCacheKey(source, taxonomy, market, code)
-	if cached, hit, err := cache.GetJSON[model.TaxonomyCategory](ctx, s.redisClient(), cacheKey); err == nil && hit {
-		return &cached, nil
-	} else if err != nil {
-		logging.Warnf(ctx, "taxonomy category redis cache get failed: %v", err)
-	}
-	cat, err := s.Dao.GetCategory(ctx, source, taxonomy, market, code)
-	if err != nil {
-		return nil, err
+	opts := cache.LoadOptions{
+		TTL:  time.Duration(bizConsts.RedisCacheTTLSecondsTaxonomyCategoryGet) * time.Second,
+		Tags: bizConsts.BuildTaxonomyCategoryCacheTags(source, taxonomy, market),
 	}
-	if err := cache.SetJSON(ctx, s.redisClient(), cacheKey, time.Duration(bizConsts.RedisCacheTTLSecondsTaxonomyCategoryGet)*time.Second, cat); err != nil {
-		logging.Warnf(ctx, "taxonomy category redis cache set failed: %v", err)
-	}
-	return cat, nil
+	return cache.GetOrLoad(ctx, s.CacheStore, bizConsts.BuildTaxonomyCategoryGetCacheKey(source, taxonomy, market, code), opts, func(ctx context.Context) (*model.TaxonomyCategory, error) {
+		return s.Dao.GetCategory(ctx, source, taxonomy, market, code)
+	})
 }
 
 // DeleteCategory deletes a category.
@@ -153,38 +138,28 @@ func (s *TaxonomyService) ListMappingsByCategory(ctx context.Context, source, ta
 	if pageSize < 1 {
 		pageSize = 100
 	}
-	cacheKey := bizConsts.BuildTaxonomyMappingByCategoryCacheKey(source, taxonomy, categoryCode)
-	if cached, hit, err := cache.GetJSON[[]*model.TaxonomySecurityMap](ctx, s.redisClient(), cacheKey); err == nil && hit {
-		return paginateItems(cached, page, pageSize), nil
-	} else if err != nil {
-		logging.Warnf(ctx, "taxonomy mapping-by-category redis cache get failed: %v", err)
+	opts := cache.LoadOptions{
+		TTL:  time.Duration(bizConsts.RedisCacheTTLSecondsTaxonomyMappingByCategory) * time.Second,
+		Tags: []string{bizConsts.BuildTaxonomyMappingByCategoryCacheTag(source, taxonomy)},
 	}
-	list, err := s.Dao.ListMappingsByCategory(ctx, source, taxonomy, categoryCode, 0, 0)
+	list, err := cache.GetOrLoad(ctx, s.CacheStore, bizConsts.BuildTaxonomyMappingByCategoryCacheKey(source, taxonomy, categoryCode), opts, func(ctx context.Context) ([]*model.TaxonomySecurityMap, error) {
+		return s.Dao.ListMappingsByCategory(ctx, source, taxonomy, categoryCode, 0, 0)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if err := cache.SetJSON(ctx, s.redisClient(), cacheKey, time.Duration(bizConsts.RedisCacheTTLSecondsTaxonomyMappingByCategory)*time.Second, list); err != nil {
-		logging.Warnf(ctx, "taxonomy mapping-by-category redis cache set failed: %v", err)
-	}
 	return paginateItems(list, page, pageSize), nil
 }
 
 // ListMappingsBySymbol returns all taxonomy mappings for a given symbol.
 func (s *TaxonomyService) ListMappingsBySymbol(ctx context.Context, symbol string) ([]*model.TaxonomySecurityMapWithDetail, error) {
-	cacheKey := bizConsts.BuildTaxonomyMappingBySymbolCacheKey(symbol)
-	if cached, hit, err := cache.GetJSON[[]*model.TaxonomySecurityMapWithDetail](ctx, s.redisClient(), cacheKey); err == nil && hit {
-		return cached, nil
-	} else if err != nil {
-		logging.Warnf(ctx, "taxonomy mapping-by-symbol redis cache get failed: %v", err)
-	}
-	list, err := s.Dao.ListMappingsBySymbol(ctx, symbol)
-	if err != nil {
-		return nil, err
-	}
-	if err := cache.SetJSON(ctx, s.redisClient(), cacheKey, time.Duration(bizConsts.RedisCacheTTLSecondsTaxonomyMappingBySymbol)*time.Second, list); err != nil {
-		logging.Warnf(ctx, "taxonomy mapping-by-symbol redis cache set failed: %v", err)
+	opts := cache.LoadOptions{
+		TTL:  time.Duration(bizConsts.RedisCacheTTLSecondsTaxonomyMappingBySymbol) * time.Second,
+		Tags: []string{bizConsts.BuildTaxonomyMappingBySymbolCacheTag()},
 	}
-	return list, nil
+	return cache.GetOrLoad(ctx, s.CacheStore, bizConsts.BuildTaxonomyMappingBySymbolCacheKey(symbol), opts, func(ctx context.Context) ([]*model.TaxonomySecurityMapWithDetail, error) {
+		return s.Dao.ListMappingsBySymbol(ctx, symbol)
+	})
 }
 
 // DeleteMapping deletes a single mapping.
@@ -233,38 +208,27 @@ func (s *TaxonomyService) ListConstituentsByIndex(ctx context.Context, source, t
 	if pageSize < 1 {
 		pageSize = 100
 	}
-	cacheKey := bizConsts.BuildTaxonomyConstituentsByIndexCacheKey(source, taxonomy, market, indexCode)
-	if cached, hit, err := cache.GetJSON[[]*model.IndustryConstituent](ctx, s.redisClient(), cacheKey); err == nil && hit {
-		return paginateItems(cached, page, pageSize), nil
-	} else if err != nil {
-		logging.Warnf(ctx, "taxonomy constituents-by-index redis cache get failed: %v", err)
+	opts := cache.LoadOptions{
+		TTL:  time.Duration(bizConsts.RedisCacheTTLSecondsTaxonomyConstituentsByIndex) * time.Second,
+		Tags: bizConsts.BuildTaxonomyConstituentsByIndexCacheTags(source, taxonomy, market, indexCode),
 	}
-	list, err := s.Dao.ListConstituentsByIndex(ctx, source, taxonomy, market, indexCode, 0, 0)
+	list, err := cache.GetOrLoad(ctx, s.CacheStore, bizConsts.BuildTaxonomyConstituentsByIndexCacheKey(source, taxonomy, market, indexCode), opts, func(ctx context.Context) ([]*model.IndustryConstituent, error) {
+		return s.Dao.ListConstituentsByIndex(ctx, source, taxonomy, market, indexCode, 0, 0)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if err := cache.SetJSON(ctx, s.redisClient(), cacheKey, time.Duration(bizConsts.RedisCacheTTLSecondsTaxonomyConstituentsByIndex)*time.Second, list); err != nil {
-		logging.Warnf(ctx, "taxonomy constituents-by-index redis cache set failed: %v", err)
-	}
 	return paginateItems(list, page, pageSize), nil
 }
 
 // ListConstituentsBySymbol returns all index memberships for a constituent stock.
 func (s *TaxonomyService) ListConstituentsBySymbol(ctx context.Context, source, taxonomy, market, symbol string) ([]*model.IndustryConstituent, error) {
-	cacheKey := bizConsts.BuildTaxonomyConstituentsBySymbolCacheKey(source, taxonomy, market, symbol)
-	if cached, hit, err := cache.GetJSON[[]*model.IndustryConstituent](ctx, s.redisClient(), cacheKey); err == nil && hit {
-		return cached, nil
-	} else if err != nil {
-		logging.Warnf(ctx, "taxonomy constituents-by-symbol redis cache get failed: %v", err)
-	}
-	list, err := s.Dao.ListConstituentsBySymbol(ctx, source, taxonomy, market, symbol)
-	if err != nil {
-		return nil, err
+	opts := cache.LoadOptions{
+		TTL: time.Duration(bizConsts.RedisCacheTTLSecondsTaxonomyConstituentsBySymbol) * time.Second,
 	}
-	if err := cache.SetJSON(ctx, s.redisClient(), cacheKey, time.Duration(bizConsts.RedisCacheTTLSecondsTaxonomyConstituentsBySymbol)*time.Second, list); err != nil {
-		logging.Warnf(ctx, "taxonomy constituents-by-symbol redis cache set failed: %v", err)
-	}
-	return list, nil
+	return cache.GetOrLoad(ctx, s.CacheStore, bizConsts.BuildTaxonomyConstituentsBySymbolCacheKey(source, taxonomy, market, symbol), opts, func(ctx context.Context) ([]*model.IndustryConstituent, error) {
+		return s.Dao.ListConstituentsBySymbol(ctx, source, taxonomy, market, symbol)
+	})
 }
 
 // ──────────── Industry Weights ────────────
@@ -361,44 +325,36 @@ func paginateItems[T any](items []T, page, pageSize int) []T {
 	return items[start:end]
 }
 
+// invalidateCategoryCaches drops list/get entries of a category scope; an empty market matches any.
 func (s *TaxonomyService) invalidateCategoryCaches(ctx context.Context, source, taxonomy, market string) {
-	client := s.redisClient()
-	patterns := []string{
-		bizConsts.BuildTaxonomyCategoryListCachePattern(source, taxonomy, market),
-		bizConsts.BuildTaxonomyCategoryGetCachePattern(source, taxonomy, market),
-	}
-	for _, pattern := range patterns {
-		if err := cache.DeleteByPattern(ctx, client, pattern); err != nil {
-			logging.Warnf(ctx, "taxonomy category cache invalidation failed: %v", err)
-		}
+	if err := s.CacheStore.InvalidateTags(ctx, bizConsts.BuildTaxonomyCategoryCacheTag(source, taxonomy, market)); err != nil {
+		logging.Warnf(ctx, "taxonomy category cache invalidation failed: %v", err)
 	}
 }
 
 func (s *TaxonomyService) invalidateMappingCaches(ctx context.Context, source, taxonomy, categoryCode, symbol string) {
-	client := s.redisClient()
+	var keys []string
 	if symbol != "" {
-		if err := cache.DeleteKeys(ctx, client, bizConsts.BuildTaxonomyMappingBySymbolCacheKey(symbol)); err != nil {
-			logging.Warnf(ctx, "taxonomy mapping-by-symbol cache invalidation failed: %v", err)
-		}
+		keys = append(keys, bizConsts.BuildTaxonomyMappingBySymbolCacheKey(symbol))
 	}
 	if categoryCode != "" {
-		if err := cache.DeleteByPattern(ctx, client, bizConsts.BuildTaxonomyMappingByCategoryCachePattern(source, taxonomy, categoryCode)); err != nil {
-			logging.Warnf(ctx, "taxonomy mapping-by-category cache invalidation failed: %v", err)
-		}
+		keys = append(keys, bizConsts.BuildTaxonomyMappingByCategoryCacheKey(source, taxonomy, categoryCode))
+	}
+	if err := s.CacheStore.Delete(ctx, keys...); err != nil {
+		logging.Warnf(ctx, "taxonomy mapping cache invalidation failed: %v", err)
 	}
 }
 
 func (s *TaxonomyService) invalidateMappingCachesForScope(ctx context.Context, source, taxonomy string) {
-	client := s.redisClient()
-	if err := cache.DeleteByPattern(ctx, client, bizConsts.BuildTaxonomyMappingByCategoryCachePattern(source, taxonomy, "")); err != nil {
+	if err := s.CacheStore.InvalidateTags(ctx, bizConsts.BuildTaxonomyMappingByCategoryCacheTag(source, taxonomy)); err != nil {
 		logging.Warnf(ctx, "taxonomy mapping-by-category scope invalidation failed: %v", err)
 	}
 	s.invalidateAllMappingBySymbolCaches(ctx)
 }
 
 func (s *TaxonomyService) invalidateAllMappingBySymbolCaches(ctx context.Context) {
-	if err := cache.DeleteByPattern(ctx, s.redisClient(), bizConsts.BuildTaxonomyMappingBySymbolCachePattern()); err != nil {
-		logging.Warnf(ctx, "taxonomy mapping-by-symbol wildcard invalidation failed: %v", err)
+	if err := s.CacheStore.InvalidateTags(ctx, bizConsts.BuildTaxonomyMappingBySymbolCacheTag()); err != nil {
+		logging.Warnf(ctx, "taxonomy mapping-by-symbol invalidation failed: %v", err)
 	}
 }
 
@@ -413,26 +369,18 @@ func (s *TaxonomyService) invalidateMappingCachesForList(ctx context.Context, so
 
 func (s *TaxonomyService) invalidateMappingCachesForCategoryPayload(ctx context.Context, source, taxonomy string, payload map[string][]string) {
 	for categoryCode, symbols := range payload {
-		if err := cache.DeleteByPattern(ctx, s.redisClient(), bizConsts.BuildTaxonomyMappingByCategoryCachePattern(source, taxonomy, categoryCode)); err != nil {
-			logging.Warnf(ctx, "taxonomy mapping-by-category payload invalidation failed: %v", err)
-		}
+		s.invalidateMappingCaches(ctx, source, taxonomy, categoryCode, "")
 		for _, symbol := range symbols {
-			if err := cache.DeleteKeys(ctx, s.redisClient(), bizConsts.BuildTaxonomyMappingBySymbolCacheKey(symbol)); err != nil {
-				logging.Warnf(ctx, "taxonomy mapping-by-symbol payload invalidation failed: %v", err)
-			}
+			s.invalidateMappingCaches(ctx, source, taxonomy, "", symbol)
 		}
 	}
 }
 
 func (s *TaxonomyService) invalidateMappingCachesForSymbolPayload(ctx context.Context, source, taxonomy string, payload map[string][]string) {
 	for symbol, categories := range payload {
-		if err := cache.DeleteKeys(ctx, s.redisClient(), bizConsts.BuildTaxonomyMappingBySymbolCacheKey(symbol)); err != nil {
-			logging.Warnf(ctx, "taxonomy mapping-by-symbol payload invalidation failed: %v", err)
-		}
+		s.invalidateMappingCaches(ctx, source, taxonomy, "", symbol)
 		for _, categoryCode := range categories {
-			if err := cache.DeleteByPattern(ctx, s.redisClient(), bizConsts.BuildTaxonomyMappingByCategoryCachePattern(source, taxonomy, categoryCode)); err != nil {
-				logging.Warnf(ctx, "taxonomy mapping-by-category payload invalidation failed: %v", err)
-			}
+			s.invalidateMappingCaches(ctx, source, taxonomy, categoryCode, "")
 		}
 	}
 }
@@ -451,14 +399,18 @@ func (s *TaxonomyService) invalidateConstituentCachesForConstituents(ctx context
 			seenSymbols[item.Symbol] = struct{}{}
 		}
 	}
+	tags := make([]string, 0, len(seenIndexes))
 	for indexCode := range seenIndexes {
-		if err := cache.DeleteByPattern(ctx, s.redisClient(), bizConsts.BuildTaxonomyConstituentsByIndexCachePattern(source, taxonomy, market, indexCode)); err != nil {
-			logging.Warnf(ctx, "taxonomy constituents-by-index invalidation failed: %v", err)
-		}
+		tags = append(tags, bizConsts.BuildTaxonomyConstituentsByIndexCacheTag(source, taxonomy, market, indexCode))
 	}
+	if err := s.CacheStore.InvalidateTags(ctx, tags...); err != nil {
+		logging.Warnf(ctx, "taxonomy constituents-by-index invalidation failed: %v", err)
+	}
+	keys := make([]string, 0, len(seenSymbols))
 	for symbol := range seenSymbols {
-		if err := cache.DeleteKeys(ctx, s.redisClient(), bizConsts.BuildTaxonomyConstituentsBySymbolCacheKey(source, taxonomy, market, symbol)); err != nil {
-			logging.Warnf(ctx, "taxonomy constituents-by-symbol invalidation failed: %v", err)
-		}
+		keys = append(keys, bizConsts.BuildTaxonomyConstituentsBySymbolCacheKey(source, taxonomy, market, symbol))
+	}
+	if err := s.CacheStore.Delete(ctx, keys...); err != nil {
+		logging.Warnf(ctx, "taxonomy constituents-by-symbol invalidation failed: %v", err)
 	}
 }
@@ -0,0 +1,15 @@
+// KG node uniqueness constraints (formerly created by GraphDao.EnsureSchema).
+CREATE CONSTRAINT IF NOT EXISTS FOR (c:Company) REQUIRE c.normalized_name IS UNIQUE;
+CREATE CONSTRAINT IF NOT EXISTS FOR (p:Product) REQUIRE p.name IS UNIQUE;
+CREATE CONSTRAINT IF NOT EXISTS FOR (i:Industry) REQUIRE i.name IS UNIQUE;
+CREATE CONSTRAINT IF NOT EXISTS FOR (r:Resource) REQUIRE r.name IS UNIQUE;
+CREATE CONSTRAINT IF NOT EXISTS FOR (t:Technology) REQUIRE t.name IS UNIQUE;
+CREATE CONSTRAINT IF NOT EXISTS FOR (e:Event) REQUIRE e.name IS UNIQUE;
+CREATE CONSTRAINT IF NOT EXISTS FOR (p:Policy) REQUIRE p.name IS UNIQUE;
+CREATE CONSTRAINT IF NOT EXISTS FOR (m:Market) REQUIRE m.name IS UNIQUE;
+CREATE CONSTRAINT IF NOT EXISTS FOR (a:Asset) REQUIRE a.name IS UNIQUE;
+
+// Lookup indexes.
+CREATE INDEX IF NOT EXISTS FOR (c:Company) ON (c.name);
+CREATE INDEX IF NOT EXISTS FOR (c:Company) ON (c.ticker);
+CREATE INDEX IF NOT EXISTS FOR (e:Event) ON (e.time);